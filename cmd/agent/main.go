@@ -4,20 +4,26 @@ package main
 
 import (
 	"context"
+	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/reflective-memory-kernel/internal/agent"
+	"github.com/reflective-memory-kernel/internal/logging"
 	"github.com/reflective-memory-kernel/internal/server"
 )
 
 func main() {
 	// Initialize logger
-	logger, _ := zap.NewProduction()
+	logger, err := logging.New(logging.FromEnv())
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
 	defer logger.Sync()
 
 	logger.Info("Starting Front-End Agent (gnet-based)")
@@ -32,6 +38,12 @@ func main() {
 		AIServicesURL:   getEnv("AI_SERVICES_URL", "http://localhost:8000"),
 		RedisAddress:    getEnv("REDIS_ADDRESS", "127.0.0.1:6479"),
 		ResponseTimeout: 60 * time.Second,
+		UploadDir:       getEnv("UPLOAD_DIR", agent.DefaultUploadDir()),
+		Crystallize:     agent.CrystallizeConfigFromEnv(),
+		Retention:       agent.RetentionConfigFromEnv(),
+	}
+	if size, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE"), 10, 64); err == nil && size > 0 {
+		cfg.MaxUploadSize = size
 	}
 
 	// Create and start the agent
@@ -64,7 +76,7 @@ func startGnetServer(a *agent.Agent, logger *zap.Logger) {
 	engine := server.New(addr, opts)
 
 	// Create gnet server
-	gnetServer := agent.NewGnetServer(a, logger.Named("server"))
+	gnetServer := agent.NewGnetServer(a, logger.Named("server"), agent.AllowedOriginsFromEnv()...)
 
 	// Setup routes
 	if err := gnetServer.SetupGnetRoutes(engine); err != nil {
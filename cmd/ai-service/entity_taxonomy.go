@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxAllowedTypes  = 50 // entity types one /extract request may allow
+	maxEntityTypeLen = 64 // characters in one allowed type
+
+	// otherEntityType replaces a type outside a request's allowed list
+	otherEntityType = "Other"
+)
+
+// defaultExtractTypes are the entity types /extract asks for when a request
+// allows none
+var defaultExtractTypes = []string{"Person", "Organization", "Concept", "Metric", "Location"}
+
+// defaultExtractFocus steers extraction with the default types
+const defaultExtractFocus = `- Named entities (people, organizations, locations)
+- Concepts and topics
+- Metrics and measurements
+- Relationships mentioned`
+
+// parseAllowedTypes checks a request's allowed entity types, trimming them
+// and dropping duplicates. Types go into the prompt, so they are limited to
+// letters, digits, spaces, '_' and '-'. Returns nil when none are given.
+func parseAllowedTypes(raw []string) ([]string, error) {
+	if len(raw) > maxAllowedTypes {
+		return nil, fmt.Errorf("allowed_types may name at most %d types", maxAllowedTypes)
+	}
+	var types []string
+	seen := make(map[string]bool, len(raw))
+	for _, t := range raw {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if len(t) > maxEntityTypeLen || strings.IndexFunc(t, invalidTypeRune) >= 0 {
+			return nil, fmt.Errorf("invalid entity type %q", t)
+		}
+		if key := strings.ToLower(t); !seen[key] {
+			seen[key] = true
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+func invalidTypeRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '_' && r != '-'
+}
+
+// extractTaxonomy returns the type list and focus lines of the extraction
+// prompt for a request's allowed types
+func extractTaxonomy(allowed []string) (typeList, focus string) {
+	if len(allowed) == 0 {
+		return strings.Join(defaultExtractTypes, "|"), defaultExtractFocus
+	}
+	return strings.Join(allowed, "|"), fmt.Sprintf(`- Only entities of these types: %s
+- Relationships mentioned`, strings.Join(allowed, ", "))
+}
+
+// coerceEntityType maps an extracted type onto allowed, ignoring case and a
+// plural "s", and returns otherEntityType for anything else
+func coerceEntityType(raw string, allowed []string) string {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	for _, k := range []string{key, strings.TrimSuffix(key, "s")} {
+		for _, t := range allowed {
+			if k != "" && k == strings.ToLower(t) {
+				return t
+			}
+		}
+	}
+	return otherEntityType
+}
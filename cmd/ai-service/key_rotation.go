@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/server"
+)
+
+const (
+	// keyRotationSignatureHeader carries "sha256=<hex HMAC of timestamp.body>"
+	// keyed by AI_SERVICE_KEY_ROTATION_SECRET
+	keyRotationSignatureHeader = "X-RMK-Signature"
+	// keyRotationTimestampHeader carries the Unix time the request was signed at
+	keyRotationTimestampHeader = "X-RMK-Timestamp"
+	// keyRotationMaxSkew is how far a signed timestamp may be from now; older
+	// requests are rejected so a captured one can't be replayed later
+	keyRotationMaxSkew = 5 * time.Minute
+)
+
+// RotateKeysRequest maps provider names to their new API key; an empty key
+// removes the provider and providers left out keep their key
+type RotateKeysRequest struct {
+	Keys map[string]string `json:"keys"`
+}
+
+// signKeyRotation returns the HMAC of timestamp, a dot and body keyed by secret
+func signKeyRotation(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// validKeyRotationSignature checks header against the HMAC of timestamp and
+// body keyed by secret, and that timestamp is within keyRotationMaxSkew of now
+func validKeyRotationSignature(secret string, body []byte, header, timestamp string, now time.Time) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(signedAt, 0)); skew > keyRotationMaxSkew || skew < -keyRotationMaxSkew {
+		return false
+	}
+	return hmac.Equal(got, signKeyRotation(secret, timestamp, body))
+}
+
+// rotateKeys swaps the router's provider keys, for a secret manager's webhook.
+// Requests already generating finish with the keys they started with.
+func (s *AIService) rotateKeys(req *server.Request, secret string) *server.Response {
+	if !validKeyRotationSignature(secret, req.Body, req.Header(keyRotationSignatureHeader), req.Header(keyRotationTimestampHeader), time.Now()) {
+		s.logger.Warn("Rejected key rotation with a bad or stale signature")
+		return server.JSON(map[string]string{"error": "invalid signature"}, 401)
+	}
+
+	var r RotateKeysRequest
+	if err := server.ParseJSON(req, &r); err != nil {
+		return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+	}
+	if len(r.Keys) == 0 {
+		return server.JSON(map[string]string{"error": "invalid request", "details": "keys is required"}, 400)
+	}
+
+	providers, err := s.llmRouter.UpdateKeys(r.Keys)
+	if err != nil {
+		return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+	}
+	s.logger.Info("Provider keys rotated by webhook", zap.Int("keys", len(r.Keys)))
+
+	return server.JSON(map[string]any{
+		"status":           "rotated",
+		"providers":        providers,
+		"default_provider": s.llmRouter.GetDefaultProvider(),
+	}, 200)
+}
@@ -6,16 +6,25 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/reflective-memory-kernel/internal/ai/curation"
+	"github.com/reflective-memory-kernel/internal/ai/guardrail"
 	"github.com/reflective-memory-kernel/internal/ai/router"
 	"github.com/reflective-memory-kernel/internal/ai/synthesis"
+	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/ingester"
+	"github.com/reflective-memory-kernel/internal/logging"
+	"github.com/reflective-memory-kernel/internal/policy"
 	"github.com/reflective-memory-kernel/internal/server"
 	"github.com/reflective-memory-kernel/internal/validation"
 	"github.com/reflective-memory-kernel/internal/vectorindex"
@@ -29,7 +38,13 @@ type AIService struct {
 	synthesis   *synthesis.Service
 	ingester    *ingester.Service
 	vectorIndex *vectorindex.IndexBuilder
+	guard       *guardrail.Guard // Validates /generate responses
 	logger      *zap.Logger
+
+	// holdStream sends /generate-stream replies only once the guardrail
+	// passed them whole; set when the guard filters content, which must
+	// never reach the client unfiltered
+	holdStream bool
 }
 
 // Config holds the server configuration
@@ -41,11 +56,37 @@ type Config struct {
 	OpenAIKey    string
 	AnthropicKey string
 	OllamaURL    string
+
+	// ShutdownTimeout bounds how long in-flight requests may drain on SIGTERM
+	ShutdownTimeout time.Duration
+
+	// RequestTimeout is the default per-request deadline for upstream LLM calls;
+	// EndpointTimeouts overrides it per path (AI_SERVICE_ENDPOINT_TIMEOUTS="/generate=60s,/ingest=5m")
+	RequestTimeout   time.Duration
+	EndpointTimeouts map[string]time.Duration
+
+	// MaxBodyBytes bounds request bodies; EndpointBodyLimits overrides it per
+	// path, starting from defaultEndpointBodyLimits
+	// (AI_SERVICE_ENDPOINT_BODY_LIMITS="/extract-vision=33554432")
+	MaxBodyBytes       int64
+	EndpointBodyLimits map[string]int64
+
+	// KeyRotationSecret signs POST /admin/provider-keys; unset, the endpoint
+	// is not served
+	KeyRotationSecret string
+}
+
+// defaultEndpointBodyLimits lets through the large bodies some endpoints
+// legitimately take: base64 images and whole documents
+var defaultEndpointBodyLimits = map[string]int64{
+	"/extract-vision": 20 << 20,
+	"/ingest":         16 << 20,
+	"/ingest-batch":   16 << 20,
 }
 
 func main() {
 	// Initialize logger
-	logger, err := zap.NewDevelopment()
+	logger, err := logging.New(logging.FromEnv())
 	if err != nil {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
@@ -61,6 +102,7 @@ func main() {
 		OpenAIKey:     cfg.OpenAIKey,
 		AnthropicKey:  cfg.AnthropicKey,
 		OllamaURL:     cfg.OllamaURL,
+		PromptTokenBudget: router.PromptTokenBudgetFromEnv(),
 		// Don't set DefaultProvider - let router auto-detect based on available keys
 		// Priority: GLM > NVIDIA > OpenAI > Anthropic > Ollama
 	}
@@ -70,35 +112,59 @@ func main() {
 	// Initialize AI services
 	aiSvc := &AIService{
 		llmRouter:   llmRouter,
-		curation:    curation.New(llmRouter, logger),
-		synthesis:   synthesis.New(llmRouter, logger),
+		curation:    newCurationService(llmRouter, logger),
+		synthesis:   newSynthesisService(llmRouter, logger),
 		ingester:    ingester.New(nil, llmRouter, logger),
 		vectorIndex: vectorindex.NewIndexBuilder(10, 1536, logger),
+		guard:       newResponseGuard(logger),
 		logger:      logger,
+		holdStream:  contentFilterEnabled(),
 	}
 
 	// Create gnet engine
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 	opts := &server.Options{
-		Network:   "tcp",
-		Multicore: true,
-		Logger:    logger,
+		Network:            "tcp",
+		Multicore:          true,
+		Logger:             logger,
+		CancelOnDisconnect: true, // abandoned requests cancel their upstream LLM calls
+		MaxBodyBytes:       cfg.MaxBodyBytes,
+		BodyLimits:         cfg.EndpointBodyLimits,
 	}
 	engine := server.New(addr, opts)
 
 	// Setup routes
-	setupRoutes(engine, aiSvc)
+	setupRoutes(engine, aiSvc, cfg)
 
 	logger.Info("AI Services server starting",
 		zap.String("address", addr),
 		zap.Bool("nvidia_key", cfg.NVIDIAKey != ""),
 		zap.Bool("glm_key", cfg.GLMKey != ""),
+		zap.Bool("synthesis_available", aiSvc.synthesis.Available()),
 	)
 
-	// Start server
-	if err := engine.Start(); err != nil {
-		logger.Fatal("Server failed", zap.Error(err))
+	// Start server in background
+	go func() {
+		if err := engine.Start(); err != nil {
+			logger.Fatal("Server failed", zap.Error(err))
+		}
+	}()
+
+	// Wait for shutdown signal
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	logger.Info("Shutting down...")
+
+	// Graceful shutdown: drain in-flight extraction/synthesis requests before stopping
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := engine.Shutdown(ctx); err != nil {
+		logger.Warn("Shutdown did not drain cleanly", zap.Error(err))
 	}
+
+	logger.Info("Shutdown complete")
 }
 
 func loadConfig() *Config {
@@ -110,7 +176,64 @@ func loadConfig() *Config {
 		OpenAIKey:    getEnv("OPENAI_API_KEY", ""),
 		AnthropicKey: getEnv("ANTHROPIC_API_KEY", ""),
 		OllamaURL:    getEnv("OLLAMA_URL", "http://localhost:11434"),
+
+		ShutdownTimeout: time.Duration(getEnvInt("AI_SERVICE_SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second,
+
+		RequestTimeout:   time.Duration(getEnvInt("AI_SERVICE_REQUEST_TIMEOUT_SECONDS", 180)) * time.Second,
+		EndpointTimeouts: parseEndpointTimeouts(getEnv("AI_SERVICE_ENDPOINT_TIMEOUTS", "")),
+
+		MaxBodyBytes:       int64(getEnvInt("AI_SERVICE_MAX_BODY_BYTES", server.DefaultMaxBodyBytes)),
+		EndpointBodyLimits: parseEndpointBodyLimits(getEnv("AI_SERVICE_ENDPOINT_BODY_LIMITS", "")),
+
+		KeyRotationSecret: getEnv("AI_SERVICE_KEY_ROTATION_SECRET", ""),
+	}
+}
+
+// parseEndpointBodyLimits parses "/path=bytes" pairs separated by commas on
+// top of defaultEndpointBodyLimits. Invalid entries are skipped.
+func parseEndpointBodyLimits(spec string) map[string]int64 {
+	limits := make(map[string]int64, len(defaultEndpointBodyLimits))
+	for path, limit := range defaultEndpointBodyLimits {
+		limits[path] = limit
 	}
+	for _, pair := range strings.Split(spec, ",") {
+		path, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || n <= 0 {
+			continue
+		}
+		path = strings.TrimSpace(path)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		limits[path] = n
+	}
+	return limits
+}
+
+// parseEndpointTimeouts parses "/path=duration" pairs separated by commas.
+// Invalid entries are skipped.
+func parseEndpointTimeouts(spec string) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, pair := range strings.Split(spec, ",") {
+		path, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			continue
+		}
+		path = strings.TrimSpace(path)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		timeouts[path] = d
+	}
+	return timeouts
 }
 
 func getEnv(key, defaultValue string) string {
@@ -130,7 +253,20 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
-func setupRoutes(engine *server.Engine, svc *AIService) {
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if floatVal, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
+func setupRoutes(engine *server.Engine, svc *AIService, cfg *Config) {
+	// Per-request deadlines propagate to router/curation/synthesis via
+	// req.Context(); the engine refuses bodies over the path's limit with 413
+	engine.Use(server.PathTimeout(cfg.RequestTimeout, cfg.EndpointTimeouts))
+
 	// Health check
 	engine.GET("/health", func(req *server.Request) *server.Response {
 		return server.JSON(map[string]string{"status": "healthy", "service": "ai-service"}, 200)
@@ -148,8 +284,13 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/extract", func(req *server.Request) *server.Response {
 		var r ExtractRequest
 		if err := server.ParseJSON(req, &r); err != nil {
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+		}
+		allowed, err := parseAllowedTypes(r.AllowedTypes)
+		if err != nil {
 			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
 		}
+		r.AllowedTypes = allowed
 		return svc.extractEntities(req, r)
 	})
 
@@ -157,7 +298,7 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/curate", func(req *server.Request) *server.Response {
 		var r CurationRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.curateFacts(req, r)
 	})
@@ -166,7 +307,13 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/synthesize", func(req *server.Request) *server.Response {
 		var r SynthesisRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+		}
+		if !r.Style.Valid() {
+			return server.JSON(map[string]string{"error": "invalid request", "details": fmt.Sprintf("unknown style %q", r.Style)}, 400)
+		}
+		if r.MaxWords < 0 {
+			return server.JSON(map[string]string{"error": "invalid request", "details": "max_words must not be negative"}, 400)
 		}
 		return svc.synthesizeBrief(req, r)
 	})
@@ -175,7 +322,7 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/synthesize-insight", func(req *server.Request) *server.Response {
 		var r InsightRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.synthesizeInsight(req, r)
 	})
@@ -184,16 +331,34 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/generate", func(req *server.Request) *server.Response {
 		var r GenerateRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.generateResponse(req, r)
 	})
 
+	// Generate response, streamed as server-sent events
+	engine.POST("/generate-stream", func(req *server.Request) *server.Response {
+		var r GenerateRequest
+		if err := server.ParseJSON(req, &r); err != nil {
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+		}
+		return svc.streamGenerate(req, r)
+	})
+
+	// Embed texts (remote fallback for kernels without a local Ollama)
+	engine.POST("/embed", func(req *server.Request) *server.Response {
+		var r EmbedRequest
+		if err := server.ParseJSON(req, &r); err != nil {
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+		}
+		return svc.embedTexts(req, r)
+	})
+
 	// Expand query
 	engine.POST("/expand-query", func(req *server.Request) *server.Response {
 		var r ExpandQueryRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.expandQuery(req, r)
 	})
@@ -202,7 +367,7 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/extract-vision", func(req *server.Request) *server.Response {
 		var r VisionExtractRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.extractVision(req, r)
 	})
@@ -211,34 +376,61 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/ingest", func(req *server.Request) *server.Response {
 		var r IngestRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.ingestDocument(req, r)
 	})
 
+	// Multi-document ingestion with cross-document entity dedup
+	engine.POST("/ingest-batch", func(req *server.Request) *server.Response {
+		var r IngestBatchRequest
+		if err := server.ParseJSON(req, &r); err != nil {
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+		}
+		return svc.ingestBatch(req, r)
+	})
+
 	// Entity resolution
 	engine.POST("/resolve-entity", func(req *server.Request) *server.Response {
 		var r ResolveEntityRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.resolveEntity(req, r)
 	})
 
+	// Batch entity resolution (one LLM call per chunk of entities)
+	engine.POST("/resolve-entity-batch", func(req *server.Request) *server.Response {
+		var r ResolveEntityBatchRequest
+		if err := server.ParseJSON(req, &r); err != nil {
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+		}
+		return svc.resolveEntityBatch(req, r)
+	})
+
 	// Classify intent
 	engine.POST("/classify-intent", func(req *server.Request) *server.Response {
 		var r map[string]any
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.classifyIntent(req, r)
 	})
 
+	// Relevance scores for reranking retrieved facts
+	engine.POST("/rerank", func(req *server.Request) *server.Response {
+		var r RerankRequest
+		if err := server.ParseJSON(req, &r); err != nil {
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
+		}
+		return svc.rerank(req, r)
+	})
+
 	// Semantic search
 	engine.POST("/semantic-search", func(req *server.Request) *server.Response {
 		var r SemanticSearchRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.semanticSearch(req, r)
 	})
@@ -247,7 +439,7 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/cognify-batch", func(req *server.Request) *server.Response {
 		var r CognifyBatchRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.cognifyBatch(req, r)
 	})
@@ -256,10 +448,17 @@ func setupRoutes(engine *server.Engine, svc *AIService) {
 	engine.POST("/summarize_batch", func(req *server.Request) *server.Response {
 		var r SummarizeBatchRequest
 		if err := server.ParseJSON(req, &r); err != nil {
-			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+			return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, server.ParseJSONStatus(err))
 		}
 		return svc.summarizeBatch(req, r)
 	})
+
+	// Provider key rotation (secret manager webhook, HMAC-signed)
+	if cfg.KeyRotationSecret != "" {
+		engine.POST("/admin/provider-keys", func(req *server.Request) *server.Response {
+			return svc.rotateKeys(req, cfg.KeyRotationSecret)
+		})
+	}
 }
 
 // Request/Response types
@@ -268,6 +467,10 @@ type ExtractRequest struct {
 	UserQuery  string `json:"user_query"`
 	AIResponse string `json:"ai_response"`
 	Context    string `json:"context,omitempty"`
+
+	// AllowedTypes constrains the entity types extracted; others come back as
+	// "Other". Empty, the default types are asked for and returned as given.
+	AllowedTypes []string `json:"allowed_types,omitempty"`
 }
 
 type ExtractedEntity struct {
@@ -280,6 +483,8 @@ type ExtractedEntity struct {
 	Relations   []interface{}          `json:"relations,omitempty"`
 	Confidence  float64                `json:"confidence,omitempty"`
 	Source      string                 `json:"source,omitempty"`
+	ValidFrom   string                 `json:"valid_from,omitempty"`  // RFC3339, inclusive
+	ValidUntil  string                 `json:"valid_until,omitempty"` // RFC3339, exclusive
 }
 
 type CurationRequest struct {
@@ -289,11 +494,16 @@ type CurationRequest struct {
 	Node2Name        string `json:"node2_name"`
 	Node2Description string `json:"node2_description"`
 	Node2CreatedAt   string `json:"node2_created_at"`
+	// Mode is "llm", "heuristic" or "auto"; empty uses CURATION_MODE
+	Mode string `json:"mode,omitempty"`
 }
 
 type CurationResponse struct {
-	WinnerIndex int    `json:"winner_index"` // 1 or 2
-	Reason      string `json:"reason"`
+	Decision    string  `json:"decision"`     // CONTRADICTION or BOTH_VALID
+	WinnerIndex int     `json:"winner_index"` // 1 or 2 (0 when BOTH_VALID)
+	Confidence  float64 `json:"confidence"`
+	Reason      string  `json:"reason"`
+	Method      string  `json:"method,omitempty"` // "llm" or "heuristic"
 }
 
 type SynthesisRequest struct {
@@ -302,11 +512,17 @@ type SynthesisRequest struct {
 	Facts           []synthesis.Fact   `json:"facts,omitempty"`
 	Insights        []synthesis.Insight `json:"insights,omitempty"`
 	ProactiveAlerts []string           `json:"proactive_alerts,omitempty"`
+	Style           synthesis.Style    `json:"style,omitempty"`     // concise, detailed, bullet or narrative
+	MaxWords        int                `json:"max_words,omitempty"` // Word cap on the brief; 0 means none
 }
 
 type SynthesisResponse struct {
 	Brief      string  `json:"brief"`
 	Confidence float64 `json:"confidence"`
+
+	// SynthesisAvailable is false when no LLM provider is configured and the
+	// brief lists the facts by template
+	SynthesisAvailable bool `json:"synthesis_available"`
 }
 
 type InsightRequest struct {
@@ -333,10 +549,30 @@ type GenerateRequest struct {
 	Context         string            `json:"context,omitempty"`
 	ProactiveAlerts []string          `json:"proactive_alerts,omitempty"`
 	UserAPIKeys     map[string]string `json:"user_api_keys,omitempty"` // Per-user API keys
+	Provider        string            `json:"provider,omitempty"`      // Pins the provider (empty = auto-detect)
+	Model           string            `json:"model,omitempty"`         // Pins one of the provider's models
 }
 
 type GenerateResponse struct {
-	Response string `json:"response"`
+	Response  string `json:"response"`
+	Provider  string `json:"provider,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"` // The prompt was cut to fit the model
+	Filtered  bool   `json:"filtered,omitempty"`  // The response was masked or replaced by the guardrail
+	Retried   bool   `json:"retried,omitempty"`   // The first response was rejected and generated again
+}
+
+type EmbedRequest struct {
+	Texts      []string `json:"texts"`
+	Model      string   `json:"model,omitempty"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type EmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+	Model      string      `json:"model"`
+	Provider   string      `json:"provider"`
+	Dimension  int         `json:"dimension"`
 }
 
 type ExpandQueryRequest struct {
@@ -364,6 +600,7 @@ type VisionExtractResponse struct {
 type IngestRequest struct {
 	Text          string `json:"text,omitempty"`
 	ContentBase64 string `json:"content_base64,omitempty"`
+	FilePath      string `json:"file_path,omitempty"` // Staged upload inside UPLOAD_DIR
 	DocumentType  string `json:"document_type,omitempty"`
 	Filename      string `json:"filename,omitempty"`
 }
@@ -377,6 +614,27 @@ type IngestResponse struct {
 	VectorTree    map[string]*vectorindex.VectorNode `json:"vector_tree,omitempty"`
 }
 
+type IngestBatchRequest struct {
+	Documents   []IngestRequest `json:"documents"`
+	Concurrency int             `json:"concurrency,omitempty"` // Parallel documents (default 4, max 8)
+}
+
+// IngestBatchItem is the outcome of one document in a batch
+type IngestBatchItem struct {
+	Index    int             `json:"index"`
+	Filename string          `json:"filename,omitempty"`
+	Status   string          `json:"status"` // "ok" or "error"
+	Error    string          `json:"error,omitempty"`
+	Result   *IngestResponse `json:"result,omitempty"`
+}
+
+type IngestBatchResponse struct {
+	Results []IngestBatchItem `json:"results"`
+	// Entities are deduplicated across the batch; "sources" lists the documents each came from
+	Entities []map[string]interface{} `json:"entities"`
+	Stats    map[string]interface{}   `json:"stats"`
+}
+
 type ResolveEntityRequest struct {
 	Entity     string   `json:"entity"`
 	Candidates []string `json:"candidates"`
@@ -386,6 +644,15 @@ type ResolveEntityResponse struct {
 	Match string `json:"match"`
 }
 
+type ResolveEntityBatchRequest struct {
+	Items []ResolveEntityRequest `json:"items"`
+}
+
+type ResolveEntityBatchResponse struct {
+	// Matches maps each input entity to its matched candidate ("" = no match)
+	Matches map[string]string `json:"matches"`
+}
+
 type SemanticSearchRequest struct {
 	Query      string                 `json:"query"`
 	Candidates []map[string]interface{} `json:"candidates"`
@@ -405,13 +672,22 @@ type CognifyItem struct {
 }
 
 type CognifyBatchRequest struct {
-	Items []CognifyItem `json:"items"`
+	Items          []CognifyItem `json:"items"`
+	Concurrency    int           `json:"concurrency,omitempty"`     // Parallel extractions (default 4, max 16)
+	TimeoutSeconds int           `json:"timeout_seconds,omitempty"` // Per-item extraction timeout (default 30, max 120)
 }
 
+// CognifyResult is one item's outcome. Status is "ok" when entities were
+// extracted, "fallback" when extraction found none and source_id stands in,
+// "error" when extraction failed (source_id still stands in) and "cancelled"
+// when the request ended before the item was processed.
 type CognifyResult struct {
 	SourceID   string            `json:"source_id"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
 	Entities   []ExtractedEntity `json:"entities,omitempty"`
 	Relations  []interface{}     `json:"relations,omitempty"`
+	Truncated  bool              `json:"truncated,omitempty"` // Content past maxPromptChunks was not extracted
 }
 
 // SummarizeBatchRequest is the request for wisdom layer summarization
@@ -422,8 +698,11 @@ type SummarizeBatchRequest struct {
 
 // SummarizeBatchResponse is the response for wisdom layer summarization
 type SummarizeBatchResponse struct {
-	Summary  string            `json:"summary"`
-	Entities []ExtractedEntity `json:"entities"`
+	Summary       string                        `json:"summary"`
+	Entities      []ExtractedEntity             `json:"entities"`
+	Relationships []graph.ExtractedRelationship `json:"relationships"`
+	Chunks        int                           `json:"chunks,omitempty"`    // Pieces the text was split into to fit the model, when more than one
+	Truncated     bool                          `json:"truncated,omitempty"` // Part of the text was dropped or failed to summarize
 }
 
 // Handler implementations
@@ -563,34 +842,49 @@ func classifyEntity(name, description string) []string {
   return tags
 }
 
-func (s *AIService) extractEntities(req *server.Request, r ExtractRequest) *server.Response {
-	start := time.Now()
-	ctx := context.Background()
+// extractPrompt takes the entity types, the user query, the AI response, the
+// context and the focus lines
+const extractPrompt = `Extract entities from this conversation. Return JSON array:
+[{"name": "...", "type": "%s", "description": "...", "valid_from": "...", "valid_until": "..."}]
 
-	prompt := fmt.Sprintf(`Extract entities from this conversation. Return JSON array:
-[{"name": "...", "type": "Person|Organization|Concept|Metric|Location", "description": "..."}]
+Only set valid_from/valid_until when the conversation states when a fact held
+(e.g. "I lived in Berlin 2018-2020" -> "2018", "2020"). Use YYYY, YYYY-MM or YYYY-MM-DD.
+Omit them for facts with no stated time scope.
 
 User Query: %s
 AI Response: %s
 Context: %s
 
 Focus on:
-- Named entities (people, organizations, locations)
-- Concepts and topics
-- Metrics and measurements
-- Relationships mentioned
+%s
+
+JSON:`
 
-JSON:`, r.UserQuery, r.AIResponse, orDefault(r.Context, "None"))
+// truncatedHeader is set on responses whose input was cut to fit the model
+// and that have no body field to report it in
+const truncatedHeader = "X-Prompt-Truncated"
+
+func (s *AIService) extractEntities(req *server.Request, r ExtractRequest) *server.Response {
+	start := time.Now()
+	ctx := req.Context()
+
+	// Whichever field is longest gives way first
+	userQuery, aiResponse, convContext := r.UserQuery, r.AIResponse, orDefault(r.Context, "None")
+	typeList, focus := extractTaxonomy(r.AllowedTypes)
+	truncated := fitFields(s.inputRoom("", extractPrompt+typeList+focus), &convContext, &aiResponse, &userQuery)
+	if truncated {
+		s.logger.Warn("extraction input exceeds the prompt budget, truncated")
+	}
+	prompt := fmt.Sprintf(extractPrompt, typeList, userQuery, aiResponse, convContext, focus)
 
 	// Use default provider (auto-detects based on available API keys)
 	result, err := s.llmRouter.ExtractJSON(ctx, prompt, "", "")
 	if err != nil {
 		s.logger.Warn("extraction failed", zap.Error(err))
-		return server.JSON([]ExtractedEntity{}, 200)
+		return withTruncated(server.JSON([]ExtractedEntity{}, 200), truncated)
 	}
 
-	// Debug: log the result map
-	s.logger.Info("extraction result", zap.Any("result", result))
+	s.logger.Debug("extraction result", zap.Any("result", result))
 
 	entities := []ExtractedEntity{}
 
@@ -620,13 +914,20 @@ JSON:`, r.UserQuery, r.AIResponse, orDefault(r.Context, "None"))
 					tags = classifyEntity(name, description)
 				}
 
+				entityType := getString(entityMap, "type")
+				if len(r.AllowedTypes) > 0 {
+					entityType = coerceEntityType(entityType, r.AllowedTypes)
+				}
+
 				entities = append(entities, ExtractedEntity{
 					Name:        name,
-					Type:        getString(entityMap, "type"),
+					Type:        entityType,
 					Description: description,
 					Tags:        tags,
 					Source:      "llm",
 					Confidence:  getFloat(entityMap, "confidence"),
+					ValidFrom:   normalizeValidityBound(entityMap["valid_from"], false),
+					ValidUntil:  normalizeValidityBound(entityMap["valid_until"], true),
 				})
 			}
 		}
@@ -636,10 +937,57 @@ JSON:`, r.UserQuery, r.AIResponse, orDefault(r.Context, "None"))
 
 	s.logger.Info("extracted entities with classification",
 		zap.Int("count", len(entities)),
-		zap.Any("sample", getSampleEntities(entities)),
 		zap.Duration("duration", time.Since(start)))
+	s.logger.Debug("extracted entities sample", zap.Any("sample", getSampleEntities(entities)))
 
-	return server.JSON(entities, 200)
+	return withTruncated(server.JSON(entities, 200), truncated)
+}
+
+// withTruncated marks resp with truncatedHeader when its input was truncated
+func withTruncated(resp *server.Response, truncated bool) *server.Response {
+	if truncated {
+		resp.Headers[truncatedHeader] = "true"
+	}
+	return resp
+}
+
+// normalizeValidityBound converts an LLM date ("2018", "2018-05", "2018-05-03" or RFC3339)
+// to RFC3339. End bounds are exclusive, so "2020" as an end becomes 2021-01-01.
+// Bare years emitted as JSON numbers are accepted. Returns "" for missing or unparseable values.
+func normalizeValidityBound(raw interface{}, end bool) string {
+	var v string
+	switch t := raw.(type) {
+	case string:
+		v = strings.TrimSpace(t)
+	case float64:
+		v = strconv.Itoa(int(t))
+	default:
+		return ""
+	}
+	if v == "" {
+		return ""
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	for _, layout := range []struct {
+		format string
+		years  int
+		months int
+		days   int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	} {
+		if t, err := time.Parse(layout.format, v); err == nil {
+			if end {
+				t = t.AddDate(layout.years, layout.months, layout.days)
+			}
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
 }
 
 // getTagsFromArray safely extracts a string array from a map
@@ -676,8 +1024,73 @@ func getSampleEntities(entities []ExtractedEntity) []map[string]interface{} {
 	return sample
 }
 
+// newCurationService builds the curation service with its default mode taken
+// from CURATION_MODE (llm, heuristic or auto; default llm) and the confidence
+// below which it keeps both facts from CURATION_MIN_CONFIDENCE (default 0.6).
+// Verdicts without a confidence count as 0.5, so they only archive a fact when
+// the threshold is 0.5 or lower.
+func newCurationService(llmRouter *router.Router, logger *zap.Logger) *curation.Service {
+	svc := curation.New(llmRouter, logger)
+	if mode, err := curation.ParseMode(getEnv("CURATION_MODE", string(curation.ModeLLM))); err != nil {
+		logger.Warn("Ignoring CURATION_MODE", zap.Error(err))
+	} else {
+		svc.SetMode(mode)
+	}
+	if threshold := getEnvFloat("CURATION_MIN_CONFIDENCE", curation.DefaultMinConfidence); threshold >= 0 && threshold <= 1 {
+		svc.SetMinConfidence(threshold)
+	} else {
+		logger.Warn("Ignoring CURATION_MIN_CONFIDENCE outside 0-1", zap.Float64("value", threshold))
+	}
+	return svc
+}
+
+// newSynthesisService configures how many facts a brief lists
+// (SYNTHESIS_MAX_FACTS) and whether the rest are summarized into one line
+// (SYNTHESIS_SUMMARIZE_OVERFLOW=true) rather than counted
+func newSynthesisService(llmRouter *router.Router, logger *zap.Logger) *synthesis.Service {
+	svc := synthesis.New(llmRouter, logger)
+	svc.SetFactLimit(
+		getEnvInt("SYNTHESIS_MAX_FACTS", synthesis.DefaultMaxFacts),
+		getEnv("SYNTHESIS_SUMMARIZE_OVERFLOW", "false") == "true",
+	)
+	return svc
+}
+
+// newResponseGuard builds the /generate guardrail: the default checks, plus
+// the policy content filter when GUARDRAIL_CONTENT_FILTER=true, blocking any
+// of the comma-separated GUARDRAIL_BLOCKED_WORDS
+func newResponseGuard(logger *zap.Logger) *guardrail.Guard {
+	guard := guardrail.Default()
+	if !contentFilterEnabled() {
+		return guard
+	}
+	filter := policy.NewContentFilter(logger, nil, true)
+	var blocked []string
+	for _, w := range strings.Split(getEnv("GUARDRAIL_BLOCKED_WORDS", ""), ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			blocked = append(blocked, w)
+		}
+	}
+	if len(blocked) > 0 {
+		filter.AddBlockedWords(blocked...)
+		filter.SetAction(policy.FilterTypeCustom, policy.ActionBlock)
+	}
+	guard.Use(guardrail.ContentFilter(filter))
+	return guard
+}
+
+// contentFilterEnabled reports whether GUARDRAIL_CONTENT_FILTER is on
+func contentFilterEnabled() bool {
+	return getEnv("GUARDRAIL_CONTENT_FILTER", "false") == "true"
+}
+
 func (s *AIService) curateFacts(req *server.Request, r CurationRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
+
+	mode, err := curation.ParseMode(r.Mode)
+	if err != nil {
+		return server.JSON(map[string]string{"error": "invalid request", "details": err.Error()}, 400)
+	}
 
 	// Parse timestamps or use current time if invalid
 	time1 := parseTime(r.Node1CreatedAt)
@@ -695,21 +1108,27 @@ func (s *AIService) curateFacts(req *server.Request, r CurationRequest) *server.
 		CreatedAt:   time2,
 	}
 
-	result, err := s.curation.Resolve(ctx, node1, node2)
+	result, err := s.curation.ResolveWithMode(ctx, mode, node1, node2)
 	if err != nil {
 		s.logger.Warn("curation failed", zap.Error(err))
 		// Return default - favor more recent
 		if time1.After(time2) {
-			return server.JSON(CurationResponse{WinnerIndex: 1, Reason: "More recent"}, 200)
+			return server.JSON(CurationResponse{Decision: curation.DecisionContradiction, WinnerIndex: 1, Confidence: 0.5, Reason: "More recent"}, 200)
 		}
-		return server.JSON(CurationResponse{WinnerIndex: 2, Reason: "More recent"}, 200)
+		return server.JSON(CurationResponse{Decision: curation.DecisionContradiction, WinnerIndex: 2, Confidence: 0.5, Reason: "More recent"}, 200)
 	}
 
-	return server.JSON(CurationResponse{WinnerIndex: result.WinnerIndex, Reason: result.Reason}, 200)
+	return server.JSON(CurationResponse{
+		Decision:    result.Decision,
+		WinnerIndex: result.WinnerIndex,
+		Confidence:  result.Confidence,
+		Reason:      result.Reason,
+		Method:      result.Method,
+	}, 200)
 }
 
 func (s *AIService) synthesizeBrief(req *server.Request, r SynthesisRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	synthesizeReq := &synthesis.SynthesisRequest{
 		Query:    r.Query,
@@ -717,25 +1136,29 @@ func (s *AIService) synthesizeBrief(req *server.Request, r SynthesisRequest) *se
 		Facts:    r.Facts,
 		Insights: r.Insights,
 		Alerts:   r.ProactiveAlerts,
+		Style:    r.Style,
+		MaxWords: r.MaxWords,
 	}
 
 	result, err := s.synthesis.Synthesize(ctx, synthesizeReq)
 	if err != nil {
 		s.logger.Warn("synthesis failed", zap.Error(err))
 		return server.JSON(SynthesisResponse{
-			Brief:      "I can help with that, but I don't have specific information.",
-			Confidence: 0.3,
+			Brief:              "I can help with that, but I don't have specific information.",
+			Confidence:         0.3,
+			SynthesisAvailable: s.synthesis.Available(),
 		}, 200)
 	}
 
 	return server.JSON(SynthesisResponse{
-		Brief:      result.Brief,
-		Confidence: result.Confidence,
+		Brief:              result.Brief,
+		Confidence:         result.Confidence,
+		SynthesisAvailable: result.SynthesisAvailable,
 	}, 200)
 }
 
 func (s *AIService) synthesizeInsight(req *server.Request, r InsightRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	node1 := map[string]interface{}{
 		"name":        r.Node1Name,
@@ -765,8 +1188,171 @@ func (s *AIService) synthesizeInsight(req *server.Request, r InsightRequest) *se
 }
 
 func (s *AIService) generateResponse(req *server.Request, r GenerateRequest) *server.Response {
-	ctx := context.Background()
+	resp, err := s.guardedGenerate(req.Context(), r)
+	if err != nil {
+		return s.generateFailed(err)
+	}
+	return server.JSON(resp, 200)
+}
+
+// guardedGenerate generates a reply and runs it through the guardrail,
+// retrying once with a stricter prompt when a check allows it
+func (s *AIService) guardedGenerate(ctx context.Context, r GenerateRequest) (GenerateResponse, error) {
+	genReq := routerGenerateRequest(r)
+
+	guard := s.guard
+	if guard == nil {
+		guard = guardrail.Default()
+	}
+	var result *router.GenerateResponse
+	guarded, err := guard.Generate(ctx, r.Query, func(ctx context.Context, strict bool) (string, error) {
+		genReq.Strict = strict
+		var err error
+		result, err = s.llmRouter.Generate(ctx, genReq)
+		if err != nil {
+			return "", err
+		}
+		return result.Content, nil
+	})
+	if err != nil {
+		return GenerateResponse{}, err
+	}
+	if guarded.Retried || guarded.Filtered {
+		s.logger.Info("Generated response failed guardrail checks",
+			zap.Bool("retried", guarded.Retried),
+			zap.Bool("filtered", guarded.Filtered),
+			zap.String("reason", guarded.Reason))
+	}
+
+	return GenerateResponse{
+		Response:  guarded.Response,
+		Provider:  string(result.Provider),
+		Model:     result.Model,
+		Truncated: result.Truncated,
+		Filtered:  guarded.Filtered,
+		Retried:   guarded.Retried,
+	}, nil
+}
 
+// generateFailed reports a generation error before any of the reply was sent
+func (s *AIService) generateFailed(err error) *server.Response {
+	if errors.Is(err, router.ErrInvalidProvider) {
+		return server.JSON(map[string]string{"error": "invalid provider", "details": err.Error()}, 400)
+	}
+	if errors.Is(err, router.ErrUnknownModel) {
+		return server.JSON(map[string]string{"error": "invalid model", "details": err.Error()}, 400)
+	}
+	s.logger.Warn("generation failed", zap.Error(err))
+	return server.JSON(GenerateResponse{Response: "I apologize, but I'm having trouble generating a response right now."}, 500)
+}
+
+// streamGenerate is generateResponse sent as server-sent events: "token"
+// events carry the reply as the provider produces it, then a "done" event
+// carries the GenerateResponse. Sent tokens can't be taken back, so the
+// guardrail checks the complete reply without retrying; when it rejects or
+// rewrites the reply, done is filtered and its response replaces the tokens.
+// A failure after the first token ends the stream with an "error" event.
+// With the content filter on, nothing is sent before the guardrail ran: the
+// checked reply goes out as a single token.
+func (s *AIService) streamGenerate(req *server.Request, r GenerateRequest) *server.Response {
+	if s.holdStream {
+		return s.streamGuarded(req, r)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	genReq := routerGenerateRequest(r)
+
+	tokens := make(chan string, 16)
+	var result *router.GenerateResponse
+	var err error
+	generated := make(chan struct{})
+	go func() {
+		defer close(generated)
+		defer close(tokens)
+		result, err = s.llmRouter.GenerateStream(ctx, genReq, tokens)
+	}()
+
+	var stream *server.StreamWriter
+	start := func() {
+		if stream == nil {
+			stream = req.Stream(200, eventStreamHeaders())
+		}
+	}
+	for token := range tokens {
+		start()
+		if writeEvent(stream, "token", map[string]string{"text": token}) != nil {
+			// The client went away; stop generating
+			cancel()
+		}
+	}
+	<-generated
+
+	if err != nil {
+		if stream == nil {
+			return s.generateFailed(err)
+		}
+		s.logger.Warn("streamed generation failed", zap.Error(err))
+		writeEvent(stream, "error", map[string]string{"error": "generation failed"})
+		return stream.Close()
+	}
+	start()
+
+	guard := s.guard
+	if guard == nil {
+		guard = guardrail.Default()
+	}
+	done := GenerateResponse{
+		Response:  result.Content,
+		Provider:  string(result.Provider),
+		Model:     result.Model,
+		Truncated: result.Truncated,
+	}
+	if v := guard.Validate(ctx, r.Query, result.Content); !v.Passed {
+		done.Response, done.Filtered = guardrail.FallbackResponse, true
+		s.logger.Info("Streamed response failed guardrail checks",
+			zap.String("reason", v.Check+": "+v.Reason))
+	} else if v.Rewrite != "" {
+		done.Response, done.Filtered = v.Rewrite, true
+	}
+	writeEvent(stream, "done", done)
+	return stream.Close()
+}
+
+// streamGuarded answers /generate-stream with a reply the guardrail has
+// already passed, as one token event and the done event
+func (s *AIService) streamGuarded(req *server.Request, r GenerateRequest) *server.Response {
+	done, err := s.guardedGenerate(req.Context(), r)
+	if err != nil {
+		return s.generateFailed(err)
+	}
+	stream := req.Stream(200, eventStreamHeaders())
+	if writeEvent(stream, "token", map[string]string{"text": done.Response}) == nil {
+		writeEvent(stream, "done", done)
+	}
+	return stream.Close()
+}
+
+// eventStreamHeaders are the headers of a server-sent event stream
+func eventStreamHeaders() map[string]string {
+	return map[string]string{
+		"Content-Type":  "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+}
+
+// writeEvent writes one server-sent event with v as its JSON data
+func writeEvent(stream *server.StreamWriter, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = stream.WriteString("event: " + event + "\ndata: " + string(data) + "\n\n")
+	return err
+}
+
+// routerGenerateRequest builds the router request for a generation, folding
+// the proactive alerts into the context
+func routerGenerateRequest(r GenerateRequest) *router.GenerateRequest {
 	// Build context string
 	var contextBuilder strings.Builder
 	if r.Context != "" {
@@ -784,26 +1370,43 @@ func (s *AIService) generateResponse(req *server.Request, r GenerateRequest) *se
 		}
 	}
 
-	genReq := &router.GenerateRequest{
+	return &router.GenerateRequest{
 		Query:       r.Query,
 		Context:     contextBuilder.String(),
 		Alerts:      r.ProactiveAlerts,
 		UserAPIKeys: r.UserAPIKeys,
+		Provider:    router.Provider(r.Provider),
+		Model:       r.Model,
 		// Don't set SystemInstruction - let the router build it using buildSystemPrompt
 		// which properly includes the memory context in the prompt
 	}
+}
 
-	result, err := s.llmRouter.Generate(ctx, genReq)
+func (s *AIService) embedTexts(req *server.Request, r EmbedRequest) *server.Response {
+	if len(r.Texts) == 0 {
+		return server.JSON(map[string]string{"error": "texts is required"}, 400)
+	}
+
+	result, err := s.llmRouter.Embed(req.Context(), &router.EmbedRequest{
+		Texts:      r.Texts,
+		Model:      r.Model,
+		Dimensions: r.Dimensions,
+	})
 	if err != nil {
-		s.logger.Warn("generation failed", zap.Error(err))
-		return server.JSON(GenerateResponse{Response: "I apologize, but I'm having trouble generating a response right now."}, 500)
+		s.logger.Warn("embedding failed", zap.Error(err))
+		return server.JSON(map[string]string{"error": "embedding failed", "details": err.Error()}, 502)
 	}
 
-	return server.JSON(GenerateResponse{Response: result.Content}, 200)
+	return server.JSON(EmbedResponse{
+		Embeddings: result.Embeddings,
+		Model:      result.Model,
+		Provider:   string(result.Provider),
+		Dimension:  result.Dimension,
+	}, 200)
 }
 
 func (s *AIService) expandQuery(req *server.Request, r ExpandQueryRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	prompt := fmt.Sprintf(`Extract entity names and search terms from this query.
 Return JSON: {"search_terms": ["term1", "term2"], "entity_names": ["Name1", "Name2"]}
@@ -864,7 +1467,7 @@ JSON:`, r.Query)
 }
 
 func (s *AIService) extractVision(req *server.Request, r VisionExtractRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	prompt := r.Prompt
 	if prompt == "" {
@@ -943,47 +1546,72 @@ Return as JSON:
 }
 
 func (s *AIService) ingestDocument(req *server.Request, r IngestRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	// Validate file if provided
-	if r.ContentBase64 != "" && r.Filename != "" {
-		validator := validation.DefaultConfig()
-		validationResult := validator.ValidateBase64Content(r.ContentBase64, r.Filename)
-		if !validationResult.Valid {
-			s.logger.Warn("file validation failed",
-				zap.String("error", validationResult.ErrorMessage))
-			return server.JSON(map[string]any{
-				"error":   "File validation failed",
-				"details": validationResult.ErrorMessage,
-			}, 400)
-		}
+	if msg := validateIngestRequest(r); msg != "" {
+		s.logger.Warn("file validation failed",
+			zap.String("error", msg))
+		return server.JSON(map[string]any{
+			"error":   "File validation failed",
+			"details": msg,
+		}, 400)
 	}
-
-	var result *ingester.IngestionResult
-	var err error
-
-	if r.Text != "" {
-		result, err = s.ingester.IngestText(ctx, r.Text, r.Filename)
-	} else if r.ContentBase64 != "" {
-		docType := r.DocumentType
-		if docType == "" {
-			// Infer from filename
-			if strings.HasSuffix(strings.ToLower(r.Filename), ".pdf") {
-				docType = "pdf"
-			} else {
-				docType = "text"
-			}
-		}
-		result, err = s.ingester.IngestBase64Content(ctx, r.ContentBase64, docType, r.Filename)
-	} else {
-		return server.JSON(map[string]any{"error": "Either text or content_base64 is required"}, 400)
+	if r.Text == "" && r.FilePath == "" && r.ContentBase64 == "" {
+		return server.JSON(map[string]any{"error": "One of text, file_path or content_base64 is required"}, 400)
 	}
 
+	result, err := s.runIngest(ctx, r)
+	if errors.Is(err, ingester.ErrFileUnavailable) {
+		// The caller can resend the content inline
+		return server.JSON(map[string]any{"error": err.Error()}, 422)
+	}
 	if err != nil {
 		s.logger.Warn("ingestion failed", zap.Error(err))
 		return server.JSON(map[string]any{"error": err.Error()}, 500)
 	}
 
+	return server.JSON(toIngestResponse(result), 200)
+}
+
+// validateIngestRequest checks inline base64 content, returning a message when invalid
+func validateIngestRequest(r IngestRequest) string {
+	if r.ContentBase64 == "" || r.Filename == "" {
+		return ""
+	}
+	validator := validation.DefaultConfig()
+	if result := validator.ValidateBase64Content(r.ContentBase64, r.Filename); !result.Valid {
+		return result.ErrorMessage
+	}
+	return ""
+}
+
+// runIngest dispatches a single document to the ingester by payload kind
+func (s *AIService) runIngest(ctx context.Context, r IngestRequest) (*ingester.IngestionResult, error) {
+	docType := r.DocumentType
+	if docType == "" {
+		// Infer from filename
+		if strings.HasSuffix(strings.ToLower(r.Filename), ".pdf") {
+			docType = "pdf"
+		} else {
+			docType = "text"
+		}
+	}
+
+	switch {
+	case r.Text != "":
+		return s.ingester.IngestText(ctx, r.Text, r.Filename)
+	case r.FilePath != "":
+		return s.ingester.IngestFile(ctx, r.FilePath, docType, r.Filename)
+	case r.ContentBase64 != "":
+		return s.ingester.IngestBase64Content(ctx, r.ContentBase64, docType, r.Filename)
+	default:
+		return nil, fmt.Errorf("one of text, file_path or content_base64 is required")
+	}
+}
+
+// toIngestResponse converts an ingestion result to the wire format
+func toIngestResponse(result *ingester.IngestionResult) IngestResponse {
 	// Convert entities
 	entities := []map[string]interface{}{}
 	for _, e := range result.Entities {
@@ -1010,7 +1638,7 @@ func (s *AIService) ingestDocument(req *server.Request, r IngestRequest) *server
 		})
 	}
 
-	return server.JSON(IngestResponse{
+	return IngestResponse{
 		Entities: entities,
 		Chunks:   chunks,
 		Stats: map[string]interface{}{
@@ -1027,11 +1655,151 @@ func (s *AIService) ingestDocument(req *server.Request, r IngestRequest) *server
 		},
 		Summary:    result.Summary,
 		VectorTree: result.VectorTree,
+	}
+}
+
+const (
+	maxBatchDocuments       = 50
+	defaultBatchConcurrency = 4
+	maxBatchConcurrency     = 8
+)
+
+// ingestBatch ingests several documents with bounded concurrency. A failing
+// document is reported in its own result and never aborts the rest.
+func (s *AIService) ingestBatch(req *server.Request, r IngestBatchRequest) *server.Response {
+	ctx := req.Context()
+	start := time.Now()
+
+	if len(r.Documents) == 0 {
+		return server.JSON(map[string]any{"error": "documents is required"}, 400)
+	}
+	if len(r.Documents) > maxBatchDocuments {
+		return server.JSON(map[string]any{"error": fmt.Sprintf("at most %d documents per batch", maxBatchDocuments)}, 400)
+	}
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	if concurrency > maxBatchConcurrency {
+		concurrency = maxBatchConcurrency
+	}
+
+	items := make([]IngestBatchItem, len(r.Documents))
+	results := make([]*ingester.IngestionResult, len(r.Documents))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, doc := range r.Documents {
+		items[i] = IngestBatchItem{Index: i, Filename: doc.Filename}
+		if msg := validateIngestRequest(doc); msg != "" {
+			items[i].Status, items[i].Error = "error", "file validation failed: "+msg
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, doc IngestRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := s.runIngest(ctx, doc)
+			if err != nil {
+				s.logger.Warn("batch document ingestion failed",
+					zap.Int("index", i),
+					zap.String("filename", doc.Filename),
+					zap.Error(err))
+				items[i].Status, items[i].Error = "error", err.Error()
+				return
+			}
+			resp := toIngestResponse(result)
+			results[i] = result
+			items[i].Status, items[i].Result = "ok", &resp
+		}(i, doc)
+	}
+	wg.Wait()
+
+	// Aggregate stats and collapse entities shared between documents
+	var succeeded, failed, totalEntities, totalChunks int
+	merged := make(map[string]map[string]interface{})
+	var order []string
+	for i, result := range results {
+		if result == nil {
+			failed++
+			continue
+		}
+		succeeded++
+		totalChunks += len(result.Chunks)
+		totalEntities += len(result.Entities)
+
+		source := batchSourceName(r.Documents[i], i)
+		for _, e := range result.Entities {
+			key := strings.ToLower(strings.TrimSpace(e.Name))
+			if key == "" {
+				continue
+			}
+			entity, ok := merged[key]
+			if !ok {
+				merged[key] = map[string]interface{}{
+					"name":        e.Name,
+					"type":        e.EntityType,
+					"description": e.Description,
+					"confidence":  e.Confidence,
+					"count":       e.Count,
+					"sources":     []string{source},
+				}
+				order = append(order, key)
+				continue
+			}
+			if e.Confidence > entity["confidence"].(float64) {
+				entity["confidence"] = e.Confidence
+			}
+			if len(e.Description) > len(entity["description"].(string)) {
+				entity["description"] = e.Description
+			}
+			entity["count"] = entity["count"].(int) + e.Count
+			sources := entity["sources"].([]string)
+			if sources[len(sources)-1] != source {
+				entity["sources"] = append(sources, source)
+			}
+		}
+	}
+
+	entities := make([]map[string]interface{}, 0, len(order))
+	for _, key := range order {
+		entities = append(entities, merged[key])
+	}
+
+	s.logger.Info("batch ingestion completed",
+		zap.Int("documents", len(r.Documents)),
+		zap.Int("succeeded", succeeded),
+		zap.Int("failed", failed),
+		zap.Int("unique_entities", len(entities)))
+
+	return server.JSON(IngestBatchResponse{
+		Results:  items,
+		Entities: entities,
+		Stats: map[string]interface{}{
+			"documents":          len(r.Documents),
+			"succeeded":          succeeded,
+			"failed":             failed,
+			"total_entities":     totalEntities,
+			"unique_entities":    len(entities),
+			"chunks":             totalChunks,
+			"processing_time_ms": time.Since(start).Milliseconds(),
+		},
 	}, 200)
 }
 
+// batchSourceName identifies a batch document in entity "sources"
+func batchSourceName(doc IngestRequest, index int) string {
+	if doc.Filename != "" {
+		return doc.Filename
+	}
+	return fmt.Sprintf("document_%d", index)
+}
+
 func (s *AIService) resolveEntity(req *server.Request, r ResolveEntityRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	if len(r.Candidates) == 0 {
 		return server.JSON(ResolveEntityResponse{Match: ""}, 200)
@@ -1087,8 +1855,113 @@ JSON:`, r.Entity, candidatesBuilder.String())
 	return server.JSON(ResolveEntityResponse{Match: match}, 200)
 }
 
+const (
+	resolveBatchChunkSize   = 20 // entities per LLM call
+	resolveBatchConcurrency = 4  // concurrent LLM calls per batch request
+)
+
+func (s *AIService) resolveEntityBatch(req *server.Request, r ResolveEntityBatchRequest) *server.Response {
+	ctx := req.Context()
+	matches := make(map[string]string, len(r.Items))
+
+	// Only items with candidates need the LLM
+	pending := make([]ResolveEntityRequest, 0, len(r.Items))
+	for _, item := range r.Items {
+		matches[item.Entity] = ""
+		if item.Entity != "" && len(item.Candidates) > 0 {
+			pending = append(pending, item)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, resolveBatchConcurrency)
+
+	for start := 0; start < len(pending); start += resolveBatchChunkSize {
+		end := min(start+resolveBatchChunkSize, len(pending))
+		chunk := pending[start:end]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resolved := s.resolveEntityChunk(ctx, chunk)
+			mu.Lock()
+			for entity, match := range resolved {
+				matches[entity] = match
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return server.JSON(ResolveEntityBatchResponse{Matches: matches}, 200)
+}
+
+// resolveEntityChunk resolves a chunk of entities in a single LLM call.
+// Failures resolve to no match so callers fall back to creating new entities.
+func (s *AIService) resolveEntityChunk(ctx context.Context, items []ResolveEntityRequest) map[string]string {
+	var itemsBuilder strings.Builder
+	for i, item := range items {
+		itemsBuilder.WriteString(fmt.Sprintf("%d. New entity: %q\n   Candidates:\n", i+1, item.Entity))
+		for _, c := range item.Candidates {
+			itemsBuilder.WriteString("   - ")
+			itemsBuilder.WriteString(c)
+			itemsBuilder.WriteString("\n")
+		}
+	}
+
+	prompt := fmt.Sprintf(`You are a semantic entity judge.
+For EACH numbered new entity below, decide whether it refers to the exact same real-world concept as any of ITS OWN candidates.
+
+%s
+Rules:
+1. "Pizza" and "pizza" -> MATCH
+2. "The Big Apple" and "New York City" -> MATCH
+3. "Apple" (Fruit) and "Apple Inc" (Company) -> NO MATCH
+4. If strict semantic match found, return the EXACT candidate name from that entity's list.
+5. If no match or unsure, return empty string.
+6. Return JSON keyed by the new entity name: {"matches": {"New Entity": "Matching Candidate Name", "Other Entity": ""}}
+
+JSON:`, itemsBuilder.String())
+
+	resolved := make(map[string]string, len(items))
+	result, err := s.llmRouter.ExtractJSON(ctx, prompt, router.ProviderNVIDIA, "")
+	if err != nil {
+		s.logger.Warn("batch entity resolution failed", zap.Int("entities", len(items)), zap.Error(err))
+		return resolved
+	}
+
+	raw, _ := result["matches"].(map[string]interface{})
+	for _, item := range items {
+		match, _ := raw[item.Entity].(string)
+		if match == "" {
+			continue
+		}
+
+		// Verify match is in THIS entity's candidates (hallucination check)
+		found := false
+		for _, c := range item.Candidates {
+			if c == match {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.logger.Warn("LLM hallucinated match not in candidates",
+				zap.String("entity", item.Entity),
+				zap.String("match", match))
+			continue
+		}
+		resolved[item.Entity] = match
+	}
+	return resolved
+}
+
 func (s *AIService) classifyIntent(req *server.Request, r map[string]any) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
 
 	query := getString(r, "query")
 	if query == "" {
@@ -1143,50 +2016,221 @@ func (s *AIService) semanticSearch(req *server.Request, r SemanticSearchRequest)
 	return server.JSON(SemanticSearchResponse{Results: []map[string]interface{}{}}, 200)
 }
 
+const (
+	defaultCognifyConcurrency = 4
+	maxCognifyConcurrency     = 16
+	defaultCognifyItemTimeout = 30 * time.Second
+	maxCognifyItemTimeout     = 120 * time.Second
+)
+
+// cognifyBatch extracts entities for each item with bounded concurrency. Every
+// item gets its own timeout and its own result, in input order, so one slow or
+// failing item never stalls or fails the batch. If the request ends mid-batch,
+// the items already processed are returned and the rest marked cancelled.
 func (s *AIService) cognifyBatch(req *server.Request, r CognifyBatchRequest) *server.Response {
-	ctx := context.Background()
+	ctx := req.Context()
+	start := time.Now()
+
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultCognifyConcurrency
+	}
+	if concurrency > maxCognifyConcurrency {
+		concurrency = maxCognifyConcurrency
+	}
+	itemTimeout := defaultCognifyItemTimeout
+	if r.TimeoutSeconds > 0 {
+		itemTimeout = time.Duration(r.TimeoutSeconds) * time.Second
+	}
+	if itemTimeout > maxCognifyItemTimeout {
+		itemTimeout = maxCognifyItemTimeout
+	}
 
-	results := []CognifyResult{}
+	results := make([]CognifyResult, len(r.Items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
 
-	for _, item := range r.Items {
-		// Extract entities from content
-		entities := s.extractEntitiesFromContent(ctx, item.Content, item.SourceTable)
-
-		extractedEntities := []ExtractedEntity{}
-		for _, e := range entities {
-			extractedEntities = append(extractedEntities, ExtractedEntity{
-				Name:        e["name"],
-				Type:        e["type"],
-				Description: e["description"],
-				Tags:        []string{item.SourceTable, "imported"},
-			})
-		}
+	for i, item := range r.Items {
+		results[i] = CognifyResult{SourceID: item.SourceID, Status: "cancelled"}
 
-		if len(extractedEntities) == 0 {
-			// Use source_id as fallback
-			extractedEntities = append(extractedEntities, ExtractedEntity{
-				Name: item.SourceID,
-				Type: "Entity",
-				Tags: []string{item.SourceTable, "imported"},
-			})
+		// Stop burning tokens once the client is gone or the deadline passed
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+		if ctx.Err() != nil {
+			<-sem
+			continue
 		}
 
-		results = append(results, CognifyResult{
-			SourceID: item.SourceID,
-			Entities: extractedEntities,
-		})
+		wg.Add(1)
+		go func(i int, item CognifyItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.cognifyItem(ctx, item, itemTimeout)
+		}(i, item)
 	}
+	wg.Wait()
+
+	counts := make(map[string]int)
+	for _, res := range results {
+		counts[res.Status]++
+	}
+	if err := ctx.Err(); err != nil {
+		s.logger.Warn("Cognify batch cancelled, returning partial results",
+			zap.Int("cancelled", counts["cancelled"]),
+			zap.Int("total", len(r.Items)),
+			zap.Error(err))
+	} else {
+		s.collapseDuplicateEntities(ctx, results)
+	}
+
+	s.logger.Info("Cognify batch completed",
+		zap.Int("items", len(r.Items)),
+		zap.Int("ok", counts["ok"]),
+		zap.Int("fallback", counts["fallback"]),
+		zap.Int("failed", counts["error"]),
+		zap.Int("concurrency", concurrency),
+		zap.Duration("duration", time.Since(start)))
 
 	return server.JSON(results, 200)
 }
 
+// cognifyItem extracts one item's entities within timeout. When extraction
+// yields nothing, or fails, the item's source_id is used as its entity.
+func (s *AIService) cognifyItem(ctx context.Context, item CognifyItem, timeout time.Duration) CognifyResult {
+	itemCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := CognifyResult{SourceID: item.SourceID, Status: "ok"}
+	entities, truncated, err := s.extractEntitiesFromContent(itemCtx, item.Content, item.SourceTable)
+	result.Truncated = truncated
+	if err != nil {
+		s.logger.Warn("batch extraction failed",
+			zap.String("source_id", item.SourceID),
+			zap.Error(err))
+		result.Status, result.Error = "error", err.Error()
+	}
+
+	for _, e := range entities {
+		result.Entities = append(result.Entities, ExtractedEntity{
+			Name:        e["name"],
+			Type:        e["type"],
+			Description: e["description"],
+			Tags:        []string{item.SourceTable, "imported"},
+		})
+	}
+
+	if len(result.Entities) == 0 {
+		// Use source_id as fallback
+		if result.Status == "ok" {
+			result.Status = "fallback"
+		}
+		result.Entities = append(result.Entities, ExtractedEntity{
+			Name: item.SourceID,
+			Type: "Entity",
+			Tags: []string{item.SourceTable, "imported"},
+		})
+	}
+	return result
+}
+
+// maxCollapseNames bounds semantic collapsing; each name is judged against all
+// earlier names, so prompt size grows quadratically
+const maxCollapseNames = 30
+
+// collapseDuplicateEntities renames entities across a cognify batch that refer to
+// the same concept ("NYC" vs "New York City") to a single canonical name, so the
+// migration creates one node instead of several. Exact case-insensitive duplicates
+// are folded first; the remaining names are resolved in one batched LLM call.
+func (s *AIService) collapseDuplicateEntities(ctx context.Context, results []CognifyResult) {
+	canonical := make(map[string]string) // lowercased name -> first-seen spelling
+	var names []string
+	for _, res := range results {
+		for _, e := range res.Entities {
+			key := strings.ToLower(strings.TrimSpace(e.Name))
+			if key == "" {
+				continue
+			}
+			if _, ok := canonical[key]; !ok {
+				canonical[key] = e.Name
+				names = append(names, e.Name)
+			}
+		}
+	}
+
+	// Each name may only resolve to an EARLIER name so matches can't form cycles
+	rename := make(map[string]string)
+	if len(names) > 1 && len(names) <= maxCollapseNames {
+		items := make([]ResolveEntityRequest, 0, len(names)-1)
+		for i := 1; i < len(names); i++ {
+			items = append(items, ResolveEntityRequest{Entity: names[i], Candidates: names[:i]})
+		}
+		for start := 0; start < len(items); start += resolveBatchChunkSize {
+			end := min(start+resolveBatchChunkSize, len(items))
+			for entity, match := range s.resolveEntityChunk(ctx, items[start:end]) {
+				rename[entity] = match
+			}
+		}
+	}
+
+	for i := range results {
+		for j := range results[i].Entities {
+			name := canonical[strings.ToLower(strings.TrimSpace(results[i].Entities[j].Name))]
+			// Follow chains (C -> B -> A) to the earliest canonical name
+			for hops := 0; hops < len(names); hops++ {
+				next, ok := rename[name]
+				if !ok {
+					break
+				}
+				name = next
+			}
+			if name != "" {
+				results[i].Entities[j].Name = name
+			}
+		}
+	}
+}
+
 // summarizeBatch handles wisdom layer crystallization - extracts entities from conversation
 func (s *AIService) summarizeBatch(req *server.Request, r SummarizeBatchRequest) *server.Response {
 	start := time.Now()
-	ctx := context.Background()
 
-	// Build extraction prompt for conversation
-	prompt := fmt.Sprintf(`Analyze this conversation and extract meaningful entities and facts. Return JSON.
+	resp, err := s.crystallize(req.Context(), r.Text)
+	if err != nil {
+		s.logger.Warn("summarize_batch extraction failed", zap.Error(err))
+		return server.JSON(SummarizeBatchResponse{
+			Summary:       "Failed to extract summary",
+			Entities:      []ExtractedEntity{},
+			Relationships: []graph.ExtractedRelationship{},
+		}, 200)
+	}
+
+	s.logger.Info("summarize_batch completed",
+		zap.Int("entity_count", len(resp.Entities)),
+		zap.Int("relationship_count", len(resp.Relationships)),
+		zap.Int("chunks", max(resp.Chunks, 1)),
+		zap.Bool("truncated", resp.Truncated),
+		zap.String("summary_preview", resp.Summary[:min(50, len(resp.Summary))]),
+		zap.Duration("duration", time.Since(start)))
+
+	return server.JSON(resp, 200)
+}
+
+// crystallizePrompt asks for a summary plus typed entities and the
+// relationships between them, using the shared entity and edge type enums
+var crystallizePrompt = func() string {
+	entityTypes := make([]string, len(graph.EntityTypes))
+	for i, t := range graph.EntityTypes {
+		entityTypes[i] = string(t)
+	}
+	edgeTypes := make([]string, len(graph.RelationEdgeTypes))
+	for i, t := range graph.RelationEdgeTypes {
+		edgeTypes[i] = strings.ToLower(string(t))
+	}
+
+	return `Analyze this conversation and extract meaningful entities, the relationships between them, and facts. Return JSON.
 
 Conversation:
 %s
@@ -1195,7 +2239,9 @@ INSTRUCTIONS:
 1. Extract entities that represent important information shared by the user
 2. Focus on: preferences, relationships, facts about the user, important events, locations, organizations
 3. Each entity should have a clear name, type, description, and the EXACT source sentence from the user
-4. Also provide a brief summary of the conversation
+4. Extract relationships between entities. Refer to the person speaking as "User"
+   (e.g. "my manager Bob" -> {"from": "User", "to": "Bob", "type": "has_manager"})
+5. Also provide a brief summary of the conversation
 
 Return JSON:
 {
@@ -1203,10 +2249,17 @@ Return JSON:
   "entities": [
     {
       "name": "Entity Name",
-      "type": "Person|Preference|Location|Organization|Event|Fact|Concept",
+      "type": "` + strings.Join(entityTypes, "|") + `",
       "description": "What we learned about this entity",
       "source_text": "The exact sentence from the user where this information was mentioned"
     }
+  ],
+  "relationships": [
+    {
+      "from": "Entity Name or User",
+      "to": "Entity Name or User",
+      "type": "` + strings.Join(edgeTypes, "|") + `"
+    }
   ]
 }
 
@@ -1215,27 +2268,79 @@ IMPORTANT:
 - Only extract meaningful facts and preferences
 - Be specific in descriptions
 - The source_text should be a direct quote from the user's message
+- Only use the relationship types listed above
+
+JSON:`
+}()
+
+// crystallize extracts the wisdom layer's summary, typed entities and
+// relationships from a conversation. Entity types are normalized onto
+// graph.EntityTypes; relationships with an unknown type are dropped.
+// A conversation too long for one prompt is summarized in chunks whose
+// results are merged; it fails only if every chunk does.
+func (s *AIService) crystallize(ctx context.Context, text string) (SummarizeBatchResponse, error) {
+	chunks, truncated := splitInput(text, s.inputRoom("", crystallizePrompt))
+
+	resp := SummarizeBatchResponse{
+		Entities:      []ExtractedEntity{},
+		Relationships: []graph.ExtractedRelationship{},
+		Truncated:     truncated,
+	}
+	if len(chunks) > 1 {
+		resp.Chunks = len(chunks)
+	}
 
-JSON:`, r.Text)
+	var summaries []string
+	seenEntities := make(map[string]bool)
+	seenRelationships := make(map[graph.ExtractedRelationship]bool)
+	var lastErr error
+	succeeded := 0
+	for _, chunk := range chunks {
+		part, err := s.crystallizeChunk(ctx, chunk)
+		if err != nil {
+			lastErr = err
+			resp.Truncated = true
+			continue
+		}
+		succeeded++
+		if part.Summary != "" {
+			summaries = append(summaries, part.Summary)
+		}
+		for _, e := range part.Entities {
+			if key := strings.ToLower(e.Name); !seenEntities[key] {
+				seenEntities[key] = true
+				resp.Entities = append(resp.Entities, e)
+			}
+		}
+		for _, rel := range part.Relationships {
+			if !seenRelationships[rel] {
+				seenRelationships[rel] = true
+				resp.Relationships = append(resp.Relationships, rel)
+			}
+		}
+	}
+	if succeeded == 0 {
+		return SummarizeBatchResponse{}, lastErr
+	}
 
-	// Use LLM to extract
-	result, err := s.llmRouter.ExtractJSON(ctx, prompt, "", "")
+	resp.Summary = strings.Join(summaries, " ")
+	if resp.Summary == "" {
+		resp.Summary = "Conversation processed"
+	}
+	return resp, nil
+}
+
+// crystallizeChunk runs crystallizePrompt over text that fits one prompt
+func (s *AIService) crystallizeChunk(ctx context.Context, text string) (SummarizeBatchResponse, error) {
+	result, err := s.llmRouter.ExtractJSON(ctx, fmt.Sprintf(crystallizePrompt, text), "", "")
 	if err != nil {
-		s.logger.Warn("summarize_batch extraction failed", zap.Error(err))
-		return server.JSON(SummarizeBatchResponse{
-			Summary:  "Failed to extract summary",
-			Entities: []ExtractedEntity{},
-		}, 200)
+		return SummarizeBatchResponse{}, err
 	}
 
-	// Parse summary
-	summary := getString(result, "summary")
-	if summary == "" {
-		summary = "Conversation processed"
+	resp := SummarizeBatchResponse{
+		Summary: getString(result, "summary"),
 	}
 
-	// Parse entities
-	entities := []ExtractedEntity{}
 	if entityArray, ok := result["entities"].([]interface{}); ok {
 		for _, item := range entityArray {
 			if entityMap, ok := item.(map[string]interface{}); ok {
@@ -1247,9 +2352,9 @@ JSON:`, r.Text)
 				// Classify entity and add tags
 				tags := classifyEntity(name, description)
 
-				entities = append(entities, ExtractedEntity{
+				resp.Entities = append(resp.Entities, ExtractedEntity{
 					Name:        name,
-					Type:        getString(entityMap, "type"),
+					Type:        string(graph.NormalizeEntityType(getString(entityMap, "type"))),
 					Description: description,
 					SourceText:  getString(entityMap, "source_text"),
 					Tags:        tags,
@@ -1260,31 +2365,88 @@ JSON:`, r.Text)
 		}
 	}
 
-	s.logger.Info("summarize_batch completed",
-		zap.Int("entity_count", len(entities)),
-		zap.String("summary_preview", summary[:min(50, len(summary))]),
-		zap.Duration("duration", time.Since(start)))
+	if relArray, ok := result["relationships"].([]interface{}); ok {
+		for _, item := range relArray {
+			relMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			from, to := getString(relMap, "from"), getString(relMap, "to")
+			if from == "" || to == "" {
+				continue
+			}
+			match := graph.InferEdgeType(getString(relMap, "type"))
+			if match.NeedsReview() {
+				s.logger.Info("Low-confidence relationship type mapping",
+					zap.String("from", from),
+					zap.String("to", to),
+					zap.String("type", getString(relMap, "type")),
+					zap.String("mapped_to", string(match.Type)),
+					zap.Float64("confidence", match.Confidence))
+			}
+			if match.Reversed {
+				from, to = to, from
+			}
+			resp.Relationships = append(resp.Relationships, graph.ExtractedRelationship{
+				From:        from,
+				To:          to,
+				Type:        match.Type,
+				NeedsReview: match.NeedsReview(),
+			})
+		}
+	}
 
-	return server.JSON(SummarizeBatchResponse{
-		Summary:  summary,
-		Entities: entities,
-	}, 200)
+	return resp, nil
 }
 
-func (s *AIService) extractEntitiesFromContent(ctx context.Context, content, sourceTable string) []map[string]string {
-	prompt := fmt.Sprintf(`Extract entities from this text. Return JSON array:
+// contentExtractPrompt takes the text and the table it was imported from
+const contentExtractPrompt = `Extract entities from this text. Return JSON array:
 [{"name": "...", "type": "Person|Organization|Concept|Metric", "description": "..."}]
 
 Text: %s
 
 Source: %s
 
-JSON:`, content, sourceTable)
+JSON:`
+
+// extractEntitiesFromContent extracts entities from imported content. Content
+// too long for one prompt is extracted in chunks and the entities merged by
+// name; truncated reports content that was dropped or failed to extract.
+func (s *AIService) extractEntitiesFromContent(ctx context.Context, content, sourceTable string) (entities []map[string]string, truncated bool, err error) {
+	room := s.inputRoom(router.ProviderNVIDIA, contentExtractPrompt) - router.EstimateTokens(sourceTable)
+	chunks, truncated := splitInput(content, room)
+
+	entities = []map[string]string{}
+	seen := make(map[string]bool)
+	succeeded := 0
+	for _, chunk := range chunks {
+		part, chunkErr := s.extractChunkEntities(ctx, chunk, sourceTable)
+		if chunkErr != nil {
+			err = chunkErr
+			truncated = true
+			continue
+		}
+		succeeded++
+		for _, e := range part {
+			if key := strings.ToLower(e["name"]); !seen[key] {
+				seen[key] = true
+				entities = append(entities, e)
+			}
+		}
+	}
+	if succeeded == 0 {
+		return nil, truncated, err
+	}
+	return entities, truncated, nil
+}
+
+// extractChunkEntities runs contentExtractPrompt over content that fits one prompt
+func (s *AIService) extractChunkEntities(ctx context.Context, content, sourceTable string) ([]map[string]string, error) {
+	prompt := fmt.Sprintf(contentExtractPrompt, content, sourceTable)
 
 	result, err := s.llmRouter.ExtractJSON(ctx, prompt, router.ProviderNVIDIA, "")
 	if err != nil {
-		s.logger.Warn("batch extraction failed", zap.Error(err))
-		return []map[string]string{}
+		return nil, err
 	}
 
 	entities := []map[string]string{}
@@ -1300,7 +2462,7 @@ JSON:`, content, sourceTable)
 		}
 	}
 
-	return entities
+	return entities, nil
 }
 
 // Helper functions
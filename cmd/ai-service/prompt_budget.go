@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/reflective-memory-kernel/internal/ai/router"
+)
+
+// maxPromptChunks bounds how many model calls one oversized input may fan out
+// into; text past the last chunk is dropped and reported as truncated
+const maxPromptChunks = 8
+
+// promptOverhead covers the system instruction ExtractJSON adds and the
+// message framing around a prompt
+const promptOverhead = 64
+
+// inputRoom returns how many tokens of caller input fit into template (with
+// its placeholders still in it) for provider's default model; "" is the
+// router's default provider
+func (s *AIService) inputRoom(provider router.Provider, template string) int {
+	room := s.llmRouter.PromptBudget(provider, "") - router.EstimateTokens(template) - promptOverhead
+	return max(room, 1)
+}
+
+// splitInput splits text into chunks that each fit room, keeping at most
+// maxPromptChunks of them. It reports whether text had to be dropped.
+func splitInput(text string, room int) ([]string, bool) {
+	chunks := router.SplitToBudget(text, room)
+	if len(chunks) > maxPromptChunks {
+		return chunks[:maxPromptChunks], true
+	}
+	return chunks, false
+}
+
+// fitFields truncates the longest of fields, repeatedly, until their
+// estimated total fits room. It reports whether any field was cut.
+func fitFields(room int, fields ...*string) bool {
+	truncated := false
+	for range fields {
+		total, longest, longestTokens := 0, fields[0], -1
+		for _, field := range fields {
+			tokens := router.EstimateTokens(*field)
+			total += tokens
+			if tokens > longestTokens {
+				longest, longestTokens = field, tokens
+			}
+		}
+		excess := total - room
+		if excess <= 0 {
+			break
+		}
+		*longest, _ = router.Truncate(*longest, max(longestTokens-excess, 0))
+		truncated = true
+	}
+	return truncated
+}
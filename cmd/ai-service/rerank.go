@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/server"
+)
+
+const (
+	rerankChunkSize     = 10  // candidates scored per LLM call
+	rerankConcurrency   = 4   // concurrent LLM calls per rerank request
+	rerankMaxCandidates = 100 // candidates one request may send
+	rerankMaxPassageLen = 600 // characters of each candidate shown to the LLM
+	rerankMaxRelevance  = 10  // top of the scale the LLM rates on
+)
+
+// RerankCandidate is a passage to score, identified by the caller's ID
+type RerankCandidate struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// RerankRequest asks how relevant each candidate is to Query
+type RerankRequest struct {
+	Query      string            `json:"query"`
+	Candidates []RerankCandidate `json:"candidates"`
+}
+
+// RerankResponse maps candidate IDs to relevance in [0, 1]. Candidates whose
+// batch failed are left out.
+type RerankResponse struct {
+	Scores map[string]float64 `json:"scores"`
+}
+
+// rerank scores candidates against the query the way a cross-encoder would,
+// reading query and passage together, a batch of candidates per LLM call
+func (s *AIService) rerank(req *server.Request, r RerankRequest) *server.Response {
+	if strings.TrimSpace(r.Query) == "" {
+		return server.JSON(map[string]string{"error": "query is required"}, 400)
+	}
+	if len(r.Candidates) > rerankMaxCandidates {
+		return server.JSON(map[string]string{
+			"error": fmt.Sprintf("at most %d candidates per request", rerankMaxCandidates),
+		}, 400)
+	}
+	ctx := req.Context()
+	scores := make(map[string]float64, len(r.Candidates))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, rerankConcurrency)
+
+	for start := 0; start < len(r.Candidates); start += rerankChunkSize {
+		end := min(start+rerankChunkSize, len(r.Candidates))
+		chunk := r.Candidates[start:end]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			scored := s.rerankChunk(ctx, r.Query, chunk)
+			mu.Lock()
+			for id, score := range scored {
+				scores[id] = score
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(r.Candidates) > 0 && len(scores) == 0 {
+		return server.JSON(map[string]string{"error": "reranking failed"}, 502)
+	}
+	return server.JSON(RerankResponse{Scores: scores}, 200)
+}
+
+// rerankChunk scores a chunk of candidates in a single LLM call. A failed
+// call scores none of them.
+func (s *AIService) rerankChunk(ctx context.Context, query string, chunk []RerankCandidate) map[string]float64 {
+	var passages strings.Builder
+	for i, c := range chunk {
+		text := c.Text
+		if runes := []rune(text); len(runes) > rerankMaxPassageLen {
+			text = string(runes[:rerankMaxPassageLen]) + "..."
+		}
+		passages.WriteString(fmt.Sprintf("%d. %s\n", i+1, strings.ReplaceAll(text, "\n", " ")))
+	}
+
+	prompt := fmt.Sprintf(`You are a relevance judge for a memory search.
+Rate how useful EACH numbered passage is for answering the query, from 0 (unrelated) to %d (answers it directly).
+Judge each passage against the query on its own; do not rank them against each other.
+
+Query: %s
+
+Passages:
+%s
+Return JSON keyed by passage number: {"scores": {"1": 7, "2": 0}}
+
+JSON:`, rerankMaxRelevance, query, passages.String())
+
+	scored := make(map[string]float64, len(chunk))
+	result, err := s.llmRouter.ExtractJSON(ctx, prompt, "", "")
+	if err != nil {
+		s.logger.Warn("rerank batch failed", zap.Int("candidates", len(chunk)), zap.Error(err))
+		return scored
+	}
+
+	raw, _ := result["scores"].(map[string]interface{})
+	for i, c := range chunk {
+		var score float64
+		switch v := raw[strconv.Itoa(i+1)].(type) {
+		case float64:
+			score = v
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				continue
+			}
+			score = parsed
+		default:
+			continue
+		}
+		score /= rerankMaxRelevance
+		if score < 0 {
+			score = 0
+		} else if score > 1 {
+			score = 1
+		}
+		scored[c.ID] = score
+	}
+	return scored
+}
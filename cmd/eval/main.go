@@ -0,0 +1,83 @@
+// Command eval scores retrieval quality against a running kernel. It loads a
+// labeled set of cases (user or namespace, query, expected fact UIDs) for
+// namespaces seeded beforehand, consults the kernel for each and reports
+// precision@k, recall@k and MRR. Any failed case, or a mean below a -min-*
+// threshold, exits nonzero so the run can gate CI.
+//
+//	eval -cases cases.jsonl -kernel http://localhost:9000 -k 5
+//	eval -cases cases.json -min-recall 0.8 -json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/agent"
+	"github.com/reflective-memory-kernel/internal/eval"
+)
+
+func main() {
+	kernelURL := flag.String("kernel", "http://localhost:9000", "Memory kernel base URL")
+	casesPath := flag.String("cases", "", "File of labeled cases: a JSON array or one JSON object per line (required)")
+	k := flag.Int("k", eval.DefaultK, "Rank cutoff for precision and recall")
+	timeout := flag.Duration("timeout", 5*time.Minute, "Timeout for the whole run")
+	minPrecision := flag.Float64("min-precision", 0, "Fail if mean precision@k is below this")
+	minRecall := flag.Float64("min-recall", 0, "Fail if mean recall@k is below this")
+	minMRR := flag.Float64("min-mrr", 0, "Fail if MRR is below this")
+	asJSON := flag.Bool("json", false, "Print the report as JSON")
+	flag.Parse()
+
+	if *casesPath == "" {
+		fmt.Fprintln(os.Stderr, "-cases is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	cases, err := eval.LoadCases(*casesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load cases: %v\n", err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client := agent.NewMKClient(strings.TrimRight(*kernelURL, "/"), zap.NewNop())
+	report := eval.Run(ctx, client, cases, *k)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var failures []string
+	if report.Failed > 0 {
+		failures = append(failures, fmt.Sprintf("%d/%d cases failed", report.Failed, len(report.Cases)))
+	}
+	if report.MeanPrecision < *minPrecision {
+		failures = append(failures, fmt.Sprintf("precision@%d %.3f < %.3f", report.K, report.MeanPrecision, *minPrecision))
+	}
+	if report.MeanRecall < *minRecall {
+		failures = append(failures, fmt.Sprintf("recall@%d %.3f < %.3f", report.K, report.MeanRecall, *minRecall))
+	}
+	if report.MRR < *minMRR {
+		failures = append(failures, fmt.Sprintf("MRR %.3f < %.3f", report.MRR, *minMRR))
+	}
+	if len(failures) > 0 {
+		fmt.Fprintln(os.Stderr, "FAIL: "+strings.Join(failures, "; "))
+		os.Exit(1)
+	}
+}
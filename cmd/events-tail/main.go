@@ -0,0 +1,46 @@
+// Command events-tail is a minimal subscriber example for kernel memory events.
+// It prints every event published on rmk.events.> (run the kernel with EVENTS_ENABLED=true).
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/nats-io/nats.go"
+	"github.com/reflective-memory-kernel/internal/kernel/events"
+)
+
+func main() {
+	natsURL := os.Getenv("NATS_URL")
+	if natsURL == "" {
+		natsURL = "nats://localhost:4322"
+	}
+
+	nc, err := nats.Connect(natsURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	sub, err := nc.Subscribe(events.SubjectPrefix+">", func(msg *nats.Msg) {
+		var evt events.Event
+		if err := json.Unmarshal(msg.Data, &evt); err != nil {
+			log.Printf("Skipping malformed event on %s: %v", msg.Subject, err)
+			return
+		}
+		log.Printf("[%s] namespace=%s uid=%s data=%v", evt.Type, evt.Namespace, evt.UID, evt.Data)
+	})
+	if err != nil {
+		log.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	log.Printf("Listening for memory events on %s> (%s)", events.SubjectPrefix, natsURL)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+}
@@ -5,21 +5,29 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/reflective-memory-kernel/internal/ai/local"
 	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/kernel"
+	"github.com/reflective-memory-kernel/internal/logging"
 	"github.com/reflective-memory-kernel/internal/server"
 )
 
 func main() {
 	// Initialize logger
-	logger, _ := zap.NewProduction()
+	logger, err := logging.New(logging.FromEnv())
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
 	defer logger.Sync()
 
 	logger.Info("Starting Reflective Memory Kernel (gnet-based)")
@@ -36,6 +44,55 @@ func main() {
 		MaxReflectionBatch:     100,
 		IngestionBatchSize:     50,
 		IngestionFlushInterval: 10 * time.Second,
+		EventsEnabled:          getEnv("EVENTS_ENABLED", "false") == "true",
+		RequiredDependencies:   kernel.ParseDependencies(os.Getenv("REQUIRED_DEPENDENCIES")),
+		OllamaURL:              getEnv("OLLAMA_URL", local.DefaultOllamaURL),
+		EmbeddingModel:         getEnv("OLLAMA_EMBED_MODEL", local.DefaultEmbeddingModel),
+	}
+	if size, err := strconv.Atoi(os.Getenv("DGRAPH_POOL_SIZE")); err == nil && size > 0 {
+		cfg.DGraphPoolSize = size
+	}
+	// CONSULTATION_CACHE_TTL=0 disables the consultation response cache
+	if ttl, err := time.ParseDuration(os.Getenv("CONSULTATION_CACHE_TTL")); err == nil {
+		if ttl <= 0 {
+			ttl = -1
+		}
+		cfg.ConsultationCacheTTL = ttl
+	}
+	// RECENCY_BOOST_WINDOW=0 disables the ranking bonus for fresh memories
+	if window, err := time.ParseDuration(os.Getenv("RECENCY_BOOST_WINDOW")); err == nil {
+		if window <= 0 {
+			window = -1
+		}
+		cfg.RecencyBoostWindow = window
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("AI_SERVICE_TIMEOUT")); err == nil {
+		cfg.AIServiceTimeout = timeout
+	}
+	// CONSULTATION_BUDGET=0 lets consultations run without a time budget
+	if budget, err := time.ParseDuration(os.Getenv("CONSULTATION_BUDGET")); err == nil {
+		if budget <= 0 {
+			budget = -1
+		}
+		cfg.ConsultationBudget = budget
+	}
+	if n, err := strconv.Atoi(os.Getenv("RERANK_MAX_CANDIDATES")); err == nil && n > 0 {
+		cfg.RerankMaxCandidates = n
+	}
+	// The AI service caps its briefs with the same variable
+	if n, err := strconv.Atoi(os.Getenv("SYNTHESIS_MAX_FACTS")); err == nil && n > 0 {
+		cfg.BriefMaxFacts = n
+	}
+	// RERANK_CANDIDATES_PER_MINUTE=0 turns LLM reranking off
+	if n, err := strconv.Atoi(os.Getenv("RERANK_CANDIDATES_PER_MINUTE")); err == nil {
+		if n <= 0 {
+			n = -1
+		}
+		cfg.RerankCandidatesPerMinute = n
+	}
+	// SEARCH_STOP_WORDS adds comma-separated words keyword searches ignore
+	if words := os.Getenv("SEARCH_STOP_WORDS"); words != "" {
+		cfg.SearchStopWords = strings.Split(words, ",")
 	}
 
 	// Create and start the kernel
@@ -10,9 +10,9 @@ import (
 	"syscall"
 
 	"github.com/reflective-memory-kernel/internal/agent"
+	"github.com/reflective-memory-kernel/internal/logging"
 	"github.com/reflective-memory-kernel/internal/mcp"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -26,7 +26,8 @@ var (
 	mkURL       = flag.String("mk-url", "http://127.0.0.1:9000", "Memory Kernel URL")
 	aiURL       = flag.String("ai-url", "http://localhost:8000", "AI Services URL")
 	redisAddr   = flag.String("redis", "127.0.0.1:6379", "Redis address")
-	logLevel    = flag.String("log-level", "info", "Log level: debug, info, warn, error")
+	logLevel    = flag.String("log-level", "", "Log level: debug, info, warn, error (default: LOG_LEVEL env var, or info)")
+	scopes      = flag.String("scopes", "read,write", "Tool scopes (read, write, admin) for stdio clients and unauthenticated HTTP clients")
 	showVersion = flag.Bool("version", false, "Show version and exit")
 )
 
@@ -57,12 +58,18 @@ func main() {
 		logger.Fatal("Failed to initialize agent", zap.Error(err))
 	}
 
+	serverScopes, err := mcp.ParseScopes(*scopes)
+	if err != nil {
+		logger.Fatal("Invalid -scopes", zap.Error(err))
+	}
+
 	// Create MCP server
 	server := mcp.NewServer(mcp.ServerConfig{
 		Logger:        logger,
 		Agent:         agt,
 		Name:          "reflective-memory-kernel",
 		Version:       version,
+		Scopes:        serverScopes,
 	})
 
 	logger.Info("MCP server initialized",
@@ -74,7 +81,17 @@ func main() {
 	case "stdio":
 		transport = mcp.NewStdioTransport(logger)
 	case "http":
-		transport = mcp.NewHTTPTransport(*addr, logger)
+		httpTransport := mcp.NewHTTPTransport(*addr, logger)
+		// MCP_API_KEYS="key1=read,write;key2=read" authenticates HTTP clients and scopes each key
+		if raw := os.Getenv("MCP_API_KEYS"); raw != "" {
+			keys, err := mcp.ParseAPIKeys(raw)
+			if err != nil {
+				logger.Fatal("Invalid MCP_API_KEYS", zap.Error(err))
+			}
+			httpTransport.SetAPIKeys(keys)
+			logger.Info("MCP HTTP API keys configured", zap.Int("keys", len(keys)))
+		}
+		transport = httpTransport
 	default:
 		logger.Fatal("Unknown transport mode", zap.String("mode", *mode))
 	}
@@ -134,42 +151,22 @@ func initializeAgent(logger *zap.Logger) (*agent.Agent, error) {
 	return agt, nil
 }
 
-// setupLogger creates a configured zap logger
+// setupLogger creates the logger from the LOG_* environment variables, with
+// -log-level taking precedence over LOG_LEVEL
 func setupLogger(level string) *zap.Logger {
-	var zapLevel zapcore.Level
-	switch level {
-	case "debug":
-		zapLevel = zapcore.DebugLevel
-	case "info":
-		zapLevel = zapcore.InfoLevel
-	case "warn":
-		zapLevel = zapcore.WarnLevel
-	case "error":
-		zapLevel = zapcore.ErrorLevel
-	default:
-		zapLevel = zapcore.InfoLevel
-	}
-
-	config := zap.Config{
-		Level:            zap.NewAtomicLevelAt(zapLevel),
-		Development:      false,
-		Encoding:         "json",
-		EncoderConfig:    zap.NewProductionEncoderConfig(),
-		OutputPaths:      []string{"stderr"},
-		ErrorOutputPaths: []string{"stderr"},
+	config := logging.FromEnv()
+	if level != "" {
+		config.Level = level
 	}
-
 	if *mode == "stdio" {
 		// Use console encoding for stdio mode (for Claude Desktop)
-		config.Encoding = "console"
-		config.EncoderConfig = zap.NewDevelopmentEncoderConfig()
-		config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		config.Format = "console"
 	}
 
-	logger, err := config.Build()
+	logger, err := logging.New(config)
 	if err != nil {
-		// Fallback to default logger
-		return zap.NewExample()
+		fmt.Fprintf(os.Stderr, "Failed to create logger: %v\n", err)
+		os.Exit(1)
 	}
 
 	return logger
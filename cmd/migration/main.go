@@ -14,6 +14,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/logging"
 	"github.com/reflective-memory-kernel/internal/migration"
 )
 
@@ -39,13 +40,11 @@ func main() {
 	}
 
 	// Setup logger
-	var logger *zap.Logger
-	var err error
+	logConfig := logging.FromEnv()
 	if *verbose {
-		logger, err = zap.NewDevelopment()
-	} else {
-		logger, err = zap.NewProduction()
+		logConfig.Level, logConfig.Format = "debug", "console"
 	}
+	logger, err := logging.New(logConfig)
 	if err != nil {
 		fmt.Printf("Failed to create logger: %v\n", err)
 		os.Exit(1)
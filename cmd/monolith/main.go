@@ -3,14 +3,15 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/kernel"
 	"github.com/reflective-memory-kernel/internal/kernel/cache"
+	"github.com/reflective-memory-kernel/internal/logging"
 	"github.com/reflective-memory-kernel/internal/precortex"
 )
 
@@ -51,22 +53,25 @@ func (h *spaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	http.FileServer(h.staticDir).ServeHTTP(w, r)
 }
 
-// ollamaEmbedderAdapter wraps local.OllamaEmbedder to implement precortex.Embedder
-type ollamaEmbedderAdapter struct {
-	embedder *local.OllamaEmbedder
+// embedderAdapter wraps local.LocalEmbedder to implement precortex.Embedder
+type embedderAdapter struct {
+	embedder local.LocalEmbedder
 }
 
-func (a *ollamaEmbedderAdapter) Embed(text string) ([]float32, error) {
+func (a *embedderAdapter) Embed(text string) ([]float32, error) {
 	return a.embedder.Embed(text)
 }
 
-func (a *ollamaEmbedderAdapter) Close() {
+func (a *embedderAdapter) Close() {
 	a.embedder.Close()
 }
 
 func main() {
 	// Initialize Logger
-	logger, _ := zap.NewDevelopment()
+	logger, err := logging.New(logging.FromEnv())
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
 	defer logger.Sync()
 
 	// Global Panic Recovery
@@ -94,6 +99,51 @@ func main() {
 		if dgraph := os.Getenv("DGRAPH_ADDRESS"); dgraph != "" {
 			kernelCfg.DGraphAddress = dgraph
 		}
+		if size, err := strconv.Atoi(os.Getenv("DGRAPH_POOL_SIZE")); err == nil && size > 0 {
+			kernelCfg.DGraphPoolSize = size
+		}
+		// CONSULTATION_CACHE_TTL=0 disables the consultation response cache
+		if ttl, err := time.ParseDuration(os.Getenv("CONSULTATION_CACHE_TTL")); err == nil {
+			if ttl <= 0 {
+				ttl = -1
+			}
+			kernelCfg.ConsultationCacheTTL = ttl
+		}
+		// RECENCY_BOOST_WINDOW=0 disables the ranking bonus for fresh memories
+		if window, err := time.ParseDuration(os.Getenv("RECENCY_BOOST_WINDOW")); err == nil {
+			if window <= 0 {
+				window = -1
+			}
+			kernelCfg.RecencyBoostWindow = window
+		}
+		if timeout, err := time.ParseDuration(os.Getenv("AI_SERVICE_TIMEOUT")); err == nil {
+			kernelCfg.AIServiceTimeout = timeout
+		}
+		// CONSULTATION_BUDGET=0 lets consultations run without a time budget
+		if budget, err := time.ParseDuration(os.Getenv("CONSULTATION_BUDGET")); err == nil {
+			if budget <= 0 {
+				budget = -1
+			}
+			kernelCfg.ConsultationBudget = budget
+		}
+		if n, err := strconv.Atoi(os.Getenv("RERANK_MAX_CANDIDATES")); err == nil && n > 0 {
+			kernelCfg.RerankMaxCandidates = n
+		}
+		// The AI service caps its briefs with the same variable
+		if n, err := strconv.Atoi(os.Getenv("SYNTHESIS_MAX_FACTS")); err == nil && n > 0 {
+			kernelCfg.BriefMaxFacts = n
+		}
+		// RERANK_CANDIDATES_PER_MINUTE=0 turns LLM reranking off
+		if n, err := strconv.Atoi(os.Getenv("RERANK_CANDIDATES_PER_MINUTE")); err == nil {
+			if n <= 0 {
+				n = -1
+			}
+			kernelCfg.RerankCandidatesPerMinute = n
+		}
+		// SEARCH_STOP_WORDS adds comma-separated words keyword searches ignore
+		if words := os.Getenv("SEARCH_STOP_WORDS"); words != "" {
+			kernelCfg.SearchStopWords = strings.Split(words, ",")
+		}
 		// Railway uses REDIS_URL or REDIS_PRIVATE_URL
 		if redis := os.Getenv("REDIS_ADDRESS"); redis != "" {
 			kernelCfg.RedisAddress = redis
@@ -111,6 +161,16 @@ func main() {
 		if qdrant := os.Getenv("QDRANT_URL"); qdrant != "" {
 			kernelCfg.QdrantURL = qdrant
 		}
+		if ollama := os.Getenv("OLLAMA_URL"); ollama != "" {
+			kernelCfg.OllamaURL = ollama
+		}
+		if model := os.Getenv("OLLAMA_EMBED_MODEL"); model != "" {
+			kernelCfg.EmbeddingModel = model
+		}
+		if os.Getenv("EVENTS_ENABLED") == "true" {
+			kernelCfg.EventsEnabled = true
+		}
+		kernelCfg.RequiredDependencies = kernel.ParseDependencies(os.Getenv("REQUIRED_DEPENDENCIES"))
 
 		var err error
 		k, err = kernel.New(kernelCfg, logger.Named("kernel"))
@@ -127,6 +187,17 @@ func main() {
 		if redisAddr := os.Getenv("REDIS_ADDRESS"); redisAddr != "" {
 			agentCfg.RedisAddress = redisAddr
 		}
+		if size, err := strconv.Atoi(os.Getenv("INGEST_BUFFER_SIZE")); err == nil && size > 0 {
+			agentCfg.IngestBufferSize = size
+		}
+		if size, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE"), 10, 64); err == nil && size > 0 {
+			agentCfg.MaxUploadSize = size
+		}
+		if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+			agentCfg.UploadDir = dir
+		}
+		agentCfg.Crystallize = agent.CrystallizeConfigFromEnv()
+		agentCfg.Retention = agent.RetentionConfigFromEnv()
 
 		a, err = agent.New(agentCfg, logger.Named("agent"))
 		if err != nil {
@@ -138,8 +209,8 @@ func main() {
 		logger.Info("About to check kernel and agent", zap.Bool("k_is_nil", k == nil), zap.Bool("a_is_nil", a == nil))
 		if k != nil && a != nil {
 			// 3. Unification: Zero-Copy Bridge
-			// Create buffered channel for transcripts
-			ingestChan := make(chan *graph.TranscriptEvent, 1000)
+			// Create buffered channel for transcripts (INGEST_BUFFER_SIZE, default 1000)
+			ingestChan := make(chan *graph.TranscriptEvent, agentCfg.IngestBufferSize)
 
 			// Configure Agent to use this channel
 			a.SetIngestChannel(ingestChan)
@@ -235,33 +306,24 @@ func main() {
 			logger.Warn("Failed to initialize Pre-Cortex, LLM will be used for all requests", zap.Error(err))
 		} else if a != nil {
 			a.SetPreCortex(pc)
-
-			// Wire up Ollama embedder for semantic similarity cache
-			ollamaEmbedder := local.NewOllamaEmbedder("", "")
-			pc.SetEmbedder(&ollamaEmbedderAdapter{ollamaEmbedder})
-			logger.Info("Pre-Cortex semantic cache enabled with Ollama embeddings")
+			// Forgotten nodes must not resurface through cached responses
+			k.OnForget(pc.ForgetNode)
+
+			// Share the kernel's embedder (Ollama with remote fallback) so cached
+			// vectors always match the cache index dimension
+			if embedder := k.GetEmbedder(); embedder != nil {
+				pc.SetEmbedder(&embedderAdapter{embedder})
+				logger.Info("Pre-Cortex semantic cache enabled with kernel embeddings")
+			}
 		}
 	} else {
 		logger.Info("Skipping Pre-Cortex initialization - no kernel available")
 	}
 
 	// Configure allowed origins for WebSocket and CORS (from ALLOWED_ORIGINS env var)
-	allowedOriginsStr := os.Getenv("ALLOWED_ORIGINS")
-	var allowedOrigins []string
-	if allowedOriginsStr == "" {
-		// Default to localhost for development
-		allowedOrigins = []string{"http://localhost:5173", "http://localhost:3000"}
-		logger.Info("Using default CORS origins (development mode)",
-			zap.Strings("origins", allowedOrigins))
-	} else {
-		allowedOrigins = strings.Split(allowedOriginsStr, ",")
-		// Trim whitespace from each origin
-		for i, origin := range allowedOrigins {
-			allowedOrigins[i] = strings.TrimSpace(origin)
-		}
-		logger.Info("Using configured CORS origins",
-			zap.Strings("origins", allowedOrigins))
-	}
+	allowedOrigins := agent.AllowedOriginsFromEnv()
+	logger.Info("Using CORS and WebSocket origins",
+		zap.Strings("origins", allowedOrigins))
 
 	// Start API Server
 	router := mux.NewRouter()
@@ -325,12 +387,7 @@ func main() {
 		}
 	}).Methods("GET")
 
-	corsObj := handlers.CORS(
-		handlers.AllowedOrigins(allowedOrigins),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	corsObj := agent.CORS(allowedOrigins)
 
 	// Default port 9090 for local dev (vite proxies to this)
 	// Docker sets PORT=8080 via environment
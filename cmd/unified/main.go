@@ -5,9 +5,12 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,13 +18,18 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/reflective-memory-kernel/internal/agent"
+	"github.com/reflective-memory-kernel/internal/ai/local"
 	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/kernel"
+	"github.com/reflective-memory-kernel/internal/logging"
 )
 
 func main() {
 	// Initialize logger
-	logger, _ := zap.NewProduction()
+	logger, err := logging.New(logging.FromEnv())
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
 	defer logger.Sync()
 
 	logger.Info("Starting Unified System (Agent + Kernel)...")
@@ -43,6 +51,55 @@ func main() {
 		WisdomBatchSize:        5,
 		WisdomFlushInterval:    5 * time.Second,
 		QdrantURL:              getEnv("QDRANT_URL", "http://localhost:6333"),
+		EventsEnabled:          getEnv("EVENTS_ENABLED", "false") == "true",
+		RequiredDependencies:   kernel.ParseDependencies(os.Getenv("REQUIRED_DEPENDENCIES")),
+		OllamaURL:              getEnv("OLLAMA_URL", local.DefaultOllamaURL),
+		EmbeddingModel:         getEnv("OLLAMA_EMBED_MODEL", local.DefaultEmbeddingModel),
+	}
+	if size, err := strconv.Atoi(os.Getenv("DGRAPH_POOL_SIZE")); err == nil && size > 0 {
+		kernelCfg.DGraphPoolSize = size
+	}
+	// CONSULTATION_CACHE_TTL=0 disables the consultation response cache
+	if ttl, err := time.ParseDuration(os.Getenv("CONSULTATION_CACHE_TTL")); err == nil {
+		if ttl <= 0 {
+			ttl = -1
+		}
+		kernelCfg.ConsultationCacheTTL = ttl
+	}
+	// RECENCY_BOOST_WINDOW=0 disables the ranking bonus for fresh memories
+	if window, err := time.ParseDuration(os.Getenv("RECENCY_BOOST_WINDOW")); err == nil {
+		if window <= 0 {
+			window = -1
+		}
+		kernelCfg.RecencyBoostWindow = window
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("AI_SERVICE_TIMEOUT")); err == nil {
+		kernelCfg.AIServiceTimeout = timeout
+	}
+	// CONSULTATION_BUDGET=0 lets consultations run without a time budget
+	if budget, err := time.ParseDuration(os.Getenv("CONSULTATION_BUDGET")); err == nil {
+		if budget <= 0 {
+			budget = -1
+		}
+		kernelCfg.ConsultationBudget = budget
+	}
+	if n, err := strconv.Atoi(os.Getenv("RERANK_MAX_CANDIDATES")); err == nil && n > 0 {
+		kernelCfg.RerankMaxCandidates = n
+	}
+	// The AI service caps its briefs with the same variable
+	if n, err := strconv.Atoi(os.Getenv("SYNTHESIS_MAX_FACTS")); err == nil && n > 0 {
+		kernelCfg.BriefMaxFacts = n
+	}
+	// RERANK_CANDIDATES_PER_MINUTE=0 turns LLM reranking off
+	if n, err := strconv.Atoi(os.Getenv("RERANK_CANDIDATES_PER_MINUTE")); err == nil {
+		if n <= 0 {
+			n = -1
+		}
+		kernelCfg.RerankCandidatesPerMinute = n
+	}
+	// SEARCH_STOP_WORDS adds comma-separated words keyword searches ignore
+	if words := os.Getenv("SEARCH_STOP_WORDS"); words != "" {
+		kernelCfg.SearchStopWords = strings.Split(words, ",")
 	}
 
 	k, err := kernel.New(kernelCfg, logger)
@@ -64,6 +121,12 @@ func main() {
 		AIServicesURL:   getEnv("AI_SERVICES_URL", "http://localhost:8000"),
 		RedisAddress:    getEnv("REDIS_ADDRESS", "127.0.0.1:6479"),
 		ResponseTimeout: 60 * time.Second,
+		UploadDir:       getEnv("UPLOAD_DIR", agent.DefaultUploadDir()),
+		Crystallize:     agent.CrystallizeConfigFromEnv(),
+		Retention:       agent.RetentionConfigFromEnv(),
+	}
+	if size, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE"), 10, 64); err == nil && size > 0 {
+		agentCfg.MaxUploadSize = size
 	}
 
 	a, err := agent.New(agentCfg, logger)
@@ -86,14 +149,15 @@ func main() {
 
 	// Agent Server (Port 3000)
 	agentRouter := mux.NewRouter()
-	agentServer := agent.NewServer(a, logger)
+	allowedOrigins := agent.AllowedOriginsFromEnv()
+	agentServer := agent.NewServer(a, logger, allowedOrigins...)
 	agentServer.SetupRoutes(agentRouter)
 	agentRouter.PathPrefix("/").Handler(http.FileServer(http.Dir("./static")))
 
 	portAgent := getEnv("PORT", "3000")
 	httpServerAgent := &http.Server{
 		Addr:         ":" + portAgent,
-		Handler:      agentRouter,
+		Handler:      agent.CORS(allowedOrigins)(agentRouter),
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240"
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+// config holds the endpoints and fixture settings from the command line
+type config struct {
+	DGraphAddr string
+	KernelURL  string
+	AgentURL   string
+	QdrantURL  string
+	Collection string
+	UserID     string
+	BoostWait  time.Duration
+	Timeout    time.Duration
+}
+
+// errSkip marks a check that could not run because its endpoint is not configured
+var errSkip = errors.New("skipped")
+
+func skipf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errSkip, fmt.Sprintf(format, args...))
+}
+
+func isSkip(err error) bool { return errors.Is(err, errSkip) }
+
+// fixture is the small weighted graph the DGraph checks run against
+type fixture struct {
+	AliceUID  string
+	AliceName string
+	BobUID    string
+	BobName   string
+	UserUID   string
+}
+
+// env holds the connections shared by checks. The DGraph connection and the
+// fixture are created on first use and removed by Close.
+type env struct {
+	cfg   config
+	http  *http.Client
+	runID string
+
+	dgOnce sync.Once
+	conn   *grpc.ClientConn
+	dg     *dgo.Dgraph
+	dgErr  error
+
+	fixOnce sync.Once
+	fix     *fixture
+	fixErr  error
+}
+
+func newEnv(cfg config) *env {
+	return &env{
+		cfg:   cfg,
+		http:  &http.Client{Timeout: cfg.Timeout},
+		runID: fmt.Sprintf("%d", time.Now().UnixNano()),
+	}
+}
+
+func (e *env) namespace() string { return nsutil.ForUser(e.cfg.UserID) }
+
+// dgraph connects to DGraph on first use
+func (e *env) dgraph() (*dgo.Dgraph, error) {
+	if e.cfg.DGraphAddr == "" {
+		return nil, skipf("no -dgraph address")
+	}
+	e.dgOnce.Do(func() {
+		e.conn, e.dgErr = grpc.NewClient(e.cfg.DGraphAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if e.dgErr == nil {
+			e.dg = dgo.NewDgraphClient(api.NewDgraphClient(e.conn))
+		}
+	})
+	if e.dgErr != nil {
+		return nil, fmt.Errorf("connect to DGraph: %w", e.dgErr)
+	}
+	return e.dg, nil
+}
+
+// fixture seeds two entities linked to the user with weighted edges. Names
+// carry the run ID so concurrent or repeated runs never collide.
+func (e *env) fixture(ctx context.Context) (*fixture, error) {
+	dg, err := e.dgraph()
+	if err != nil {
+		return nil, err
+	}
+	e.fixOnce.Do(func() {
+		f := &fixture{
+			AliceName: "Verify Alice " + e.runID,
+			BobName:   "Verify Bob " + e.runID,
+		}
+		ns := e.namespace()
+		nquads := fmt.Sprintf(`
+			_:alice <name> %q .
+			_:alice <namespace> %q .
+			_:alice <dgraph.type> "Entity" .
+			_:alice <activation> "0.5"^^<xs:double> .
+			_:bob <name> %q .
+			_:bob <namespace> %q .
+			_:bob <dgraph.type> "Entity" .
+			_:bob <activation> "0.5"^^<xs:double> .
+			_:user <name> %q .
+			_:user <namespace> %q .
+			_:user <dgraph.type> "User" .
+			_:alice <family_member> _:user (weight=0.95) .
+			_:bob <has_manager> _:user (weight=0.8) .
+		`, f.AliceName, ns, f.BobName, ns, e.cfg.UserID+"_"+e.runID, ns)
+
+		resp, err := dg.NewTxn().Mutate(ctx, &api.Mutation{CommitNow: true, SetNquads: []byte(nquads)})
+		if err != nil {
+			e.fixErr = fmt.Errorf("seed fixture: %w", err)
+			return
+		}
+		f.AliceUID, f.BobUID, f.UserUID = resp.Uids["alice"], resp.Uids["bob"], resp.Uids["user"]
+		e.fix = f
+	})
+	return e.fix, e.fixErr
+}
+
+// Close deletes the fixture nodes and closes the DGraph connection
+func (e *env) Close() {
+	if e.fix != nil && e.dg != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		var del strings.Builder
+		for _, uid := range []string{e.fix.AliceUID, e.fix.BobUID, e.fix.UserUID} {
+			fmt.Fprintf(&del, "<%s> * * .\n", uid)
+		}
+		e.dg.NewTxn().Mutate(ctx, &api.Mutation{CommitNow: true, DelNquads: []byte(del.String())})
+		cancel()
+		e.fix = nil
+	}
+	if e.conn != nil {
+		e.conn.Close()
+		e.conn = nil
+	}
+}
+
+// postJSON posts body to url and decodes a 200 response into out
+func (e *env) postJSON(ctx context.Context, url string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", url, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// checkSchema verifies the predicates every kernel query relies on are present
+func checkSchema(ctx context.Context, e *env) error {
+	dg, err := e.dgraph()
+	if err != nil {
+		return err
+	}
+
+	required := []string{"name", "namespace", "activation", "created_at", "description"}
+	resp, err := dg.NewReadOnlyTxn().Query(ctx, fmt.Sprintf("schema(pred: [%s]) { predicate }", strings.Join(required, ", ")))
+	if err != nil {
+		return fmt.Errorf("schema query: %w", err)
+	}
+
+	var result struct {
+		Schema []struct {
+			Predicate string `json:"predicate"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return fmt.Errorf("decode schema: %w", err)
+	}
+
+	present := make(map[string]bool, len(result.Schema))
+	for _, s := range result.Schema {
+		present[s.Predicate] = true
+	}
+	var missing []string
+	for _, pred := range required {
+		if !present[pred] {
+			missing = append(missing, pred)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing predicates: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkWeights verifies edge weight facets survive a write/read round-trip
+func checkWeights(ctx context.Context, e *env) error {
+	f, err := e.fixture(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := fmt.Sprintf(`{
+		data(func: uid(%s, %s)) {
+			uid
+			family_member @facets(weight) { uid }
+			has_manager @facets(weight) { uid }
+		}
+	}`, f.AliceUID, f.BobUID)
+	resp, err := e.dg.NewReadOnlyTxn().Query(ctx, q)
+	if err != nil {
+		return fmt.Errorf("query weights: %w", err)
+	}
+
+	var result struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return fmt.Errorf("decode weights: %w", err)
+	}
+
+	want := map[string]struct {
+		predicate string
+		min       float64
+	}{
+		f.AliceUID: {"family_member", 0.9},
+		f.BobUID:   {"has_manager", 0.75},
+	}
+	for _, node := range result.Data {
+		uid, _ := node["uid"].(string)
+		w, ok := want[uid]
+		if !ok {
+			continue
+		}
+		weight := edgeWeight(node, w.predicate)
+		if weight < w.min {
+			return fmt.Errorf("%s weight on %s = %.2f, want >= %.2f", w.predicate, uid, weight, w.min)
+		}
+		delete(want, uid)
+	}
+	if len(want) > 0 {
+		return fmt.Errorf("fixture nodes missing from query result")
+	}
+	return nil
+}
+
+// edgeWeight reads the weight facet of the first edge of predicate on node
+func edgeWeight(node map[string]interface{}, predicate string) float64 {
+	edges, ok := node[predicate].([]interface{})
+	if !ok || len(edges) == 0 {
+		return 0
+	}
+	edge, _ := edges[0].(map[string]interface{})
+	for _, key := range []string{predicate + "|weight", "weight"} {
+		if w, ok := edge[key].(float64); ok {
+			return w
+		}
+	}
+	return 0
+}
+
+// consult asks the kernel about the fixture
+func (e *env) consult(ctx context.Context, query string) (map[string]interface{}, error) {
+	if e.cfg.KernelURL == "" {
+		return nil, skipf("no -kernel URL")
+	}
+	var resp map[string]interface{}
+	err := e.postJSON(ctx, strings.TrimRight(e.cfg.KernelURL, "/")+"/api/consult", map[string]interface{}{
+		"user_id":   e.cfg.UserID,
+		"namespace": e.namespace(),
+		"query":     query,
+	}, &resp)
+	return resp, err
+}
+
+// checkConsult verifies the consultation endpoint answers with a brief
+func checkConsult(ctx context.Context, e *env) error {
+	resp, err := e.consult(ctx, "What do you know about me?")
+	if err != nil {
+		return err
+	}
+	if _, ok := resp["synthesized_brief"]; !ok {
+		if _, ok := resp["request_id"]; !ok {
+			return fmt.Errorf("response has neither synthesized_brief nor request_id")
+		}
+	}
+	return nil
+}
+
+// checkBoost verifies a consultation about a node raises its activation
+func checkBoost(ctx context.Context, e *env) error {
+	if e.cfg.KernelURL == "" {
+		return skipf("no -kernel URL")
+	}
+	f, err := e.fixture(ctx)
+	if err != nil {
+		return err
+	}
+
+	before, err := e.activation(ctx, f.BobUID)
+	if err != nil {
+		return err
+	}
+	if _, err := e.consult(ctx, "Who is "+f.BobName+"?"); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(e.cfg.BoostWait):
+	}
+
+	after, err := e.activation(ctx, f.BobUID)
+	if err != nil {
+		return err
+	}
+	if after <= before {
+		return fmt.Errorf("activation did not increase (%.3f -> %.3f) after %s", before, after, e.cfg.BoostWait)
+	}
+	return nil
+}
+
+func (e *env) activation(ctx context.Context, uid string) (float64, error) {
+	resp, err := e.dg.NewReadOnlyTxn().Query(ctx, fmt.Sprintf(`{ data(func: uid(%s)) { activation } }`, uid))
+	if err != nil {
+		return 0, fmt.Errorf("query activation: %w", err)
+	}
+	var result struct {
+		Data []struct {
+			Activation float64 `json:"activation"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, fmt.Errorf("decode activation: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return 0, fmt.Errorf("node %s not found", uid)
+	}
+	return result.Data[0].Activation, nil
+}
+
+// checkVector takes any stored point and verifies searching with its own
+// vector returns it, so no embedder is needed
+func checkVector(ctx context.Context, e *env) error {
+	if e.cfg.QdrantURL == "" {
+		return skipf("no -qdrant URL")
+	}
+	base := strings.TrimRight(e.cfg.QdrantURL, "/") + "/collections/" + e.cfg.Collection
+
+	var scroll struct {
+		Result struct {
+			Points []struct {
+				ID     interface{} `json:"id"`
+				Vector []float32   `json:"vector"`
+			} `json:"points"`
+		} `json:"result"`
+	}
+	if err := e.postJSON(ctx, base+"/points/scroll", map[string]interface{}{
+		"limit":       1,
+		"with_vector": true,
+	}, &scroll); err != nil {
+		return err
+	}
+	if len(scroll.Result.Points) == 0 {
+		return fmt.Errorf("collection %s has no points", e.cfg.Collection)
+	}
+	point := scroll.Result.Points[0]
+
+	var search struct {
+		Result []struct {
+			ID interface{} `json:"id"`
+		} `json:"result"`
+	}
+	if err := e.postJSON(ctx, base+"/points/search", map[string]interface{}{
+		"vector": point.Vector,
+		"limit":  5,
+	}, &search); err != nil {
+		return err
+	}
+	for _, hit := range search.Result {
+		if fmt.Sprint(hit.ID) == fmt.Sprint(point.ID) {
+			return nil
+		}
+	}
+	return fmt.Errorf("search with the vector of point %v did not return it", point.ID)
+}
+
+// checkPolicy verifies the admin policy API rejects a request with no token
+func checkPolicy(ctx context.Context, e *env) error {
+	if e.cfg.AgentURL == "" {
+		return skipf("no -agent URL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(e.cfg.AgentURL, "/")+"/api/admin/policies", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := e.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return fmt.Errorf("unauthenticated request got %d, want 401 or 403", resp.StatusCode)
+	}
+	return nil
+}
@@ -1,205 +1,115 @@
+// Command verify runs smoke checks against a running deployment: DGraph schema,
+// weighted edges, consultation round-trip, activation boost, vector search and
+// policy denial. Each check prints PASS/FAIL/SKIP and any failure exits nonzero.
+//
+//	verify -checks schema,consult -dgraph localhost:9080 -kernel http://localhost:9000
+//	verify -list
 package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
-	"log"
-	"net/http"
+	"os"
 	"strings"
 	"time"
-
-	"github.com/dgraph-io/dgo/v2"
-	"github.com/dgraph-io/dgo/v2/protos/api"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-)
-
-const (
-	MonolithURL = "http://localhost:9090"
-	DGraphAddr  = "localhost:19080" // Mapped port
-	UserID      = "e2e_tester_go"
-	Namespace   = "user_" + UserID
 )
 
-func main() {
-	log.Println("=== Starting E2E Verification (Go) ===")
-
-	// 1. Connect to DGraph
-	conn, err := grpc.Dial(DGraphAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("Failed to connect to DGraph: %v", err)
-	}
-	defer conn.Close()
-	dg := dgo.NewDgraphClient(api.NewDgraphClient(conn))
-
-	ctx := context.Background()
-
-	// 2. Setup Data (Weighted Edges)
-	// 2. Setup Data (Weighted Edges)
-	log.Println("STEP 1: Ingesting Weighted Relationships...")
-	// Skip name indexing to bypass environment conflict
-	op := &api.Operation{
-		Schema: `
-			activation: float .
-		`,
-	}
-	if err := dg.Alter(ctx, op); err != nil {
-		log.Fatalf("Failed to alter schema: %v", err)
-	}
+// check is one named smoke test. run returns errSkip (wrapped) when the
+// endpoint it needs was not configured.
+type check struct {
+	name        string
+	description string
+	run         func(ctx context.Context, e *env) error
+}
 
-	mu := &api.Mutation{
-		CommitNow: true,
-		SetNquads: []byte(fmt.Sprintf(`
-			_:alice <name> "Alice" .
-			_:alice <namespace> "%s" .
-			_:alice <dgraph.type> "Entity" .
-			_:alice <activation> "0.5" .
-			_:bob <name> "Bob" .
-			_:bob <namespace> "%s" .
-			_:bob <dgraph.type> "Entity" .
-			_:bob <activation> "0.5" .
-			_:user <name> "%s" .
-			_:user <namespace> "%s" .
-			_:user <dgraph.type> "User" .
-			
-			_:alice <family_member> _:user (weight=0.95) .
-			_:bob <has_manager> _:user (weight=0.8) .
-		`, Namespace, Namespace, UserID, Namespace)),
-	}
+var checks = []check{
+	{"schema", "DGraph schema has the core predicates", checkSchema},
+	{"weights", "weighted edges round-trip through DGraph", checkWeights},
+	{"consult", "kernel /api/consult answers a query", checkConsult},
+	{"boost", "consultation boosts the activation of retrieved nodes", checkBoost},
+	{"vector", "Qdrant search returns a stored point for its own vector", checkVector},
+	{"policy", "admin policy API denies unauthenticated requests", checkPolicy},
+}
 
-	resp, err := dg.NewTxn().Mutate(ctx, mu)
-	if err != nil {
-		log.Fatalf("Mutation failed: %v", err)
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.DGraphAddr, "dgraph", "localhost:9080", "DGraph Alpha gRPC address (empty skips DGraph checks)")
+	flag.StringVar(&cfg.KernelURL, "kernel", "http://localhost:9000", "Memory kernel base URL (empty skips consult/boost)")
+	flag.StringVar(&cfg.AgentURL, "agent", "http://localhost:9090", "Agent/monolith base URL (empty skips policy)")
+	flag.StringVar(&cfg.QdrantURL, "qdrant", "http://localhost:6333", "Qdrant base URL (empty skips vector)")
+	flag.StringVar(&cfg.Collection, "collection", "rmk_nodes", "Qdrant collection to search")
+	flag.StringVar(&cfg.UserID, "user", "verify_smoke", "User the fixture nodes are created for")
+	flag.DurationVar(&cfg.BoostWait, "boost-wait", 3*time.Second, "How long to wait for the async activation boost")
+	flag.DurationVar(&cfg.Timeout, "timeout", 30*time.Second, "Timeout per check")
+	selected := flag.String("checks", "", "Comma-separated checks to run (default: all)")
+	list := flag.Bool("list", false, "List the available checks and exit")
+	flag.Parse()
+
+	if *list {
+		for _, c := range checks {
+			fmt.Printf("%-8s %s\n", c.name, c.description)
+		}
+		return
 	}
-	log.Println("Ingestion successful.")
-
-	aliceUID := resp.Uids["alice"]
-	bobUID := resp.Uids["bob"]
-	log.Printf("Created Alice (%s) and Bob (%s)", aliceUID, bobUID)
-
-	// 3. Verify Weights
-	verifyWeights(ctx, dg, aliceUID, bobUID)
 
-	// 4. Test Access Boost
-	log.Println("\nSTEP 2: Testing Access Boost...")
-	initialAct := getActivation(ctx, dg, bobUID)
-	log.Printf("Bob Initial Activation: %.2f", initialAct)
-
-	// Trigger consult - asking for "Bob" might rely on Qdrant/Name, but let's try.
-	// We really want to hit the node.
-	log.Println("Triggering consultation...")
-	consultPayload := fmt.Sprintf(`{"user_id":"%s","query":"Who is Bob?","namespace":"%s"}`, UserID, Namespace)
-	httpResp, err := http.Post(MonolithURL+"/consult", "application/json", strings.NewReader(consultPayload))
+	toRun, err := selectChecks(*selected)
 	if err != nil {
-		log.Fatalf("Consultation request failed: %v", err)
-	}
-	defer httpResp.Body.Close()
-	if httpResp.StatusCode != 200 {
-		log.Printf("Warning: Consultation failed (Status %d). Monolith likely down. Skipping Boost check.", httpResp.StatusCode)
-		// If Monolith is down, we can't test boost, but we verified Weights.
-	} else {
-		log.Println("Waiting for async boost...")
-		time.Sleep(3 * time.Second)
-
-		finalAct := getActivation(ctx, dg, bobUID)
-		log.Printf("Bob Final Activation: %.2f", finalAct)
-
-		if finalAct > initialAct {
-			log.Println("SUCCESS: Activation boosted!")
-		} else {
-			log.Println("WARNING: Activation did not increase (Async worker might be down or Qdrant sync lag).")
-		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	log.Println("\n=== WEIGHTED TRAVERSAL VERIFIED ===")
-}
-
-func verifyWeights(ctx context.Context, dg *dgo.Dgraph, aliceUID, bobUID string) {
-	q := fmt.Sprintf(`{
-		data(func: uid(%s, %s)) {
-			name
-			family_member @facets(weight) { uid }
-			has_manager @facets(weight) { uid }
+	e := newEnv(cfg)
+	defer e.Close()
+
+	failed := 0
+	for _, c := range toRun {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		start := time.Now()
+		err := c.run(ctx, e)
+		cancel()
+
+		elapsed := time.Since(start).Round(time.Millisecond)
+		switch {
+		case err == nil:
+			fmt.Printf("PASS  %-8s (%s)\n", c.name, elapsed)
+		case isSkip(err):
+			fmt.Printf("SKIP  %-8s %v\n", c.name, err)
+		default:
+			failed++
+			fmt.Printf("FAIL  %-8s %v (%s)\n", c.name, err, elapsed)
 		}
-	}`, aliceUID, bobUID)
-
-	resp, err := dg.NewTxn().Query(ctx, q)
-	if err != nil {
-		log.Fatalf("Query failed: %v", err)
 	}
 
-	var result map[string]interface{}
-
-	if err := json.Unmarshal(resp.Json, &result); err != nil {
-		log.Printf("Raw JSON: %s", string(resp.Json))
-		log.Fatalf("Unmarshal failed: %v", err)
+	if failed > 0 {
+		fmt.Printf("%d/%d checks failed\n", failed, len(toRun))
+		e.Close()
+		os.Exit(1)
 	}
+}
 
-	data, ok := result["data"].([]interface{})
-	if !ok {
-		log.Fatalf("Invalid response structure: data is not an array")
+// selectChecks resolves the -checks flag to checks in registry order
+func selectChecks(names string) ([]check, error) {
+	if strings.TrimSpace(names) == "" {
+		return checks, nil
 	}
 
-	for _, item := range data {
-		node := item.(map[string]interface{})
-		name := node["name"].(string)
-
-		if name == "Alice" {
-			if fm, ok := node["family_member"].([]interface{}); ok && len(fm) > 0 {
-				edge := fm[0].(map[string]interface{})
-				// Check for weight with or without pipe (robustness)
-				var w float64
-				if val, ok := edge["family_member|weight"]; ok {
-					w = val.(float64)
-				} else if val, ok := edge["weight"]; ok {
-					w = val.(float64)
-				}
-
-				log.Printf("Alice (Family) Weight: %.2f", w)
-				if w < 0.9 {
-					log.Fatalf("Alice weight mismatch. Expected ~0.95, got %.2f", w)
-				}
-			}
-		} else if name == "Bob" {
-			if hm, ok := node["has_manager"].([]interface{}); ok && len(hm) > 0 {
-				edge := hm[0].(map[string]interface{})
-				var w float64
-				if val, ok := edge["has_manager|weight"]; ok {
-					w = val.(float64)
-				} else if val, ok := edge["weight"]; ok {
-					w = val.(float64)
-				}
-
-				log.Printf("Bob (Manager) Weight: %.2f", w)
-				if w < 0.75 {
-					log.Fatalf("Bob weight mismatch. Expected ~0.8, got %.2f", w)
-				}
-			}
+	want := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			want[name] = true
 		}
 	}
-}
 
-func getActivation(ctx context.Context, dg *dgo.Dgraph, uid string) float64 {
-	q := fmt.Sprintf(`{
-		data(func: uid(%s)) {
-			activation
+	var selected []check
+	for _, c := range checks {
+		if want[c.name] {
+			selected = append(selected, c)
+			delete(want, c.name)
 		}
-	}`, uid)
-
-	resp, err := dg.NewTxn().Query(ctx, q)
-	if err != nil {
-		log.Fatalf("Get activation query failed: %v", err)
-	}
-
-	var result struct {
-		Data []struct {
-			Activation float64 `json:"activation"`
-		} `json:"data"`
 	}
-	json.Unmarshal(resp.Json, &result)
-	if len(result.Data) == 0 {
-		return 0
+	for name := range want {
+		return nil, fmt.Errorf("unknown check %q (see -list)", name)
 	}
-	return result.Data[0].Activation
+	return selected, nil
 }
@@ -14,20 +14,21 @@ import (
 	"time"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/reflective-memory-kernel/internal/ai/local"
 	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/kernel"
+	"github.com/reflective-memory-kernel/internal/logging"
 )
 
 var (
 	logger     *zap.Logger
-	logLevel   = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	logLevel   = flag.String("log-level", "", "Log level (debug, info, warn, error) (default: LOG_LEVEL env var, or info)")
 	addr       = flag.String("addr", "", "Address to listen on for Inngest events (default: :8080, or ADDR env var)")
 	appID      = flag.String("app-id", "rmk-workflows", "Inngest App ID")
 	dgraphAddr = flag.String("dgraph", "localhost:9080", "DGraph address")
-	ollamaURL  = flag.String("ollama", "http://localhost:11434", "Ollama URL for embeddings")
+	ollamaURL  = flag.String("ollama", "", "Ollama URL for embeddings (default: OLLAMA_URL env var, or "+local.DefaultOllamaURL+")")
+	embedModel = flag.String("embed-model", "", "Ollama embedding model (default: OLLAMA_EMBED_MODEL env var, or "+local.DefaultEmbeddingModel+")")
 )
 
 func main() {
@@ -73,7 +74,10 @@ func main() {
 
 	// Initialize local embedder
 	logger.Info("About to initialize embedder...")
-	embedder := initEmbedder(*ollamaURL)
+	embedder, err := initEmbedder(*ollamaURL, *embedModel)
+	if err != nil {
+		logger.Fatal("Failed to initialize embedder", zap.Error(err))
+	}
 	logger.Info("Embedder initialized")
 
 	// Configure workflows
@@ -82,6 +86,7 @@ func main() {
 		EventKey:      os.Getenv("INNGEST_EVENT_KEY"),
 		AppID:         *appID,
 		Logger:        logger,
+		QdrantURL:     os.Getenv("QDRANT_URL"),
 	}
 
 	// Create and start workflow service
@@ -176,26 +181,16 @@ func waitForDGraph(addr string, logger *zap.Logger) error {
 }
 
 func initLogger(level string) {
-	var zapLevel zap.AtomicLevel
-	switch level {
-	case "debug":
-		zapLevel = zap.NewAtomicLevelAt(zap.DebugLevel)
-	case "info":
-		zapLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
-	case "warn":
-		zapLevel = zap.NewAtomicLevelAt(zap.WarnLevel)
-	case "error":
-		zapLevel = zap.NewAtomicLevelAt(zap.ErrorLevel)
-	default:
-		zapLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+	config := logging.FromEnv()
+	if level != "" {
+		config.Level = level
 	}
 
-	config := zap.NewDevelopmentConfig()
-	config.Level = zapLevel
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-
-	logger, _ = config.Build()
+	var err error
+	if logger, err = logging.New(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create logger: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 func initGraphClient(ctx context.Context, addr string) (*graph.Client, error) {
@@ -205,12 +200,16 @@ func initGraphClient(ctx context.Context, addr string) (*graph.Client, error) {
 	return graph.NewClient(ctx, cfg, logger)
 }
 
-func initEmbedder(ollamaURL string) local.LocalEmbedder {
-	embedder := local.NewOllamaEmbedder(ollamaURL, "nomic-embed-text")
+func initEmbedder(ollamaURL, model string) (local.LocalEmbedder, error) {
+	embedder := local.NewOllamaEmbedder(ollamaURL, model)
+	if err := embedder.Init(); err != nil {
+		return nil, err
+	}
 
 	logger.Info("Initialized Ollama embedder",
-		zap.String("url", ollamaURL),
-		zap.String("model", "nomic-embed-text"))
+		zap.String("url", embedder.BaseURL()),
+		zap.String("model", embedder.Model()),
+		zap.Int("dimension", embedder.Dimension()))
 
-	return embedder
+	return embedder, nil
 }
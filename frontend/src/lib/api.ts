@@ -28,6 +28,9 @@ export interface GraphNode {
     size?: number;
     activation?: number;
     source_text?: string; // Original quote from user
+    type?: string;
+    color_key?: string; // Stable key for picking a palette color
+    cluster?: string; // Community id (when requested with clusters)
 }
 
 export interface GraphEdge {
@@ -35,11 +38,22 @@ export interface GraphEdge {
     target: string;
     id?: string;
     label?: string;
+    predicate?: string;
+    weight?: number; // For edge thickness
 }
 
 export interface GraphData {
     nodes: GraphNode[];
     edges: GraphEdge[];
+    focus_uid?: string;
+    truncated?: boolean;
+}
+
+export interface GraphOptions {
+    focusUid?: string; // Return the subgraph around this node
+    depth?: number; // Hops from focusUid (max 3)
+    limit?: number; // Max nodes (max 500)
+    clusters?: boolean; // Annotate nodes with community ids
 }
 
 export interface IngestionStats {
@@ -116,10 +130,16 @@ export const api = {
         }
     },
 
-    getGraph: async (): Promise<GraphData> => {
+    getGraph: async (opts: GraphOptions = {}): Promise<GraphData> => {
         try {
             const headers = getAuthHeaders() as Record<string, string>;
-            const res = await fetch(`${API_BASE_URL}/api/dashboard/graph`, { headers });
+            const params = new URLSearchParams();
+            if (opts.focusUid) params.set("focus_uid", opts.focusUid);
+            if (opts.depth) params.set("depth", String(opts.depth));
+            if (opts.limit) params.set("limit", String(opts.limit));
+            if (opts.clusters) params.set("clusters", "true");
+            const query = params.toString();
+            const res = await fetch(`${API_BASE_URL}/api/dashboard/graph${query ? `?${query}` : ""}`, { headers });
             if (!res.ok) throw new Error("Failed to fetch graph");
             return await res.json();
         } catch (e) {
@@ -0,0 +1,240 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// ActivationConfigRequest updates a namespace's activation parameters; fields
+// left out keep their current value
+type ActivationConfigRequest struct {
+	Namespace              string   `json:"namespace"`
+	BoostPerAccess         *float64 `json:"boost_per_access,omitempty"`
+	MaxActivation          *float64 `json:"max_activation,omitempty"`
+	DecayRate              *float64 `json:"decay_rate,omitempty"`
+	MinActivation          *float64 `json:"min_activation,omitempty"`
+	CoreIdentityThreshold  *float64 `json:"core_identity_threshold,omitempty"`
+	TraversalMinActivation *float64 `json:"traversal_min_activation,omitempty"`
+}
+
+// apply overlays the request's fields on config
+func (req ActivationConfigRequest) apply(config graph.ActivationConfig) graph.ActivationConfig {
+	if req.BoostPerAccess != nil {
+		config.BoostPerAccess = *req.BoostPerAccess
+	}
+	if req.MaxActivation != nil {
+		config.MaxActivation = *req.MaxActivation
+	}
+	if req.DecayRate != nil {
+		config.DecayRate = *req.DecayRate
+	}
+	if req.MinActivation != nil {
+		config.MinActivation = *req.MinActivation
+	}
+	if req.CoreIdentityThreshold != nil {
+		config.CoreIdentityThreshold = *req.CoreIdentityThreshold
+	}
+	if req.TraversalMinActivation != nil {
+		config.TraversalMinActivation = *req.TraversalMinActivation
+	}
+	return config
+}
+
+// activationConfigResponse is returned by both activation config endpoints
+type activationConfigResponse struct {
+	Namespace string                 `json:"namespace"`
+	Config    graph.ActivationConfig `json:"config"`
+}
+
+// handleGetActivationConfig returns the activation parameters in effect for a namespace
+// GET /api/admin/activation-config?namespace=...
+func (s *Server) handleGetActivationConfig(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		writeJSONError(w, http.StatusBadRequest, "namespace is required", nil)
+		return
+	}
+	if s.agent.mkClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activationConfigResponse{
+		Namespace: namespace,
+		Config:    s.agent.mkClient.GetActivationConfig(r.Context(), namespace),
+	})
+}
+
+// handleUpdateActivationConfig tunes how strongly a namespace's memory boosts
+// accessed knowledge and how fast it decays, without a redeploy
+// PUT /api/admin/activation-config
+func (s *Server) handleUpdateActivationConfig(w http.ResponseWriter, r *http.Request) {
+	adminUser := GetUserID(r.Context())
+
+	var req ActivationConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	if req.Namespace == "" {
+		writeJSONError(w, http.StatusBadRequest, "namespace is required", nil)
+		return
+	}
+	if s.agent.mkClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
+		return
+	}
+
+	config := req.apply(s.agent.mkClient.GetActivationConfig(r.Context(), req.Namespace))
+	if err := config.Validate(); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if err := s.agent.mkClient.SetActivationConfig(r.Context(), req.Namespace, config); err != nil {
+		s.logger.Error("Failed to update activation config", zap.String("namespace", req.Namespace), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to update activation config", nil)
+		return
+	}
+
+	s.logActivity(r.Context(), adminUser, "activation_config_update",
+		fmt.Sprintf("Updated activation config for %s: boost=%g max=%g decay=%g",
+			req.Namespace, config.BoostPerAccess, config.MaxActivation, config.DecayRate))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activationConfigResponse{Namespace: req.Namespace, Config: config})
+}
+
+// ActivationResetRequest sets activation to Activation and clears access
+// counts, on one node or, for the bulk endpoint, every node Filter selects
+type ActivationResetRequest struct {
+	Activation *float64 `json:"activation"`
+	graph.ActivationResetFilter
+}
+
+// handleGetNodeActivation shows a node's activation, access count and last
+// access, for working out why it keeps surfacing
+// GET /api/admin/nodes/{uid}/activation
+func (s *Server) handleGetNodeActivation(w http.ResponseWriter, r *http.Request) {
+	if s.agent.mkClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
+		return
+	}
+
+	state, err := s.agent.mkClient.GetNodeActivation(r.Context(), mux.Vars(r)["uid"])
+	if err != nil {
+		s.logger.Debug("Node activation lookup failed", zap.String("uid", mux.Vars(r)["uid"]), zap.Error(err))
+		writeJSONError(w, http.StatusNotFound, "Node not found", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// handleResetNodeActivation sets a node's activation and clears its access
+// count, correcting a memory whose activation dynamics went wrong
+// POST /api/admin/nodes/{uid}/activation
+func (s *Server) handleResetNodeActivation(w http.ResponseWriter, r *http.Request) {
+	adminUser := GetUserID(r.Context())
+	uid := mux.Vars(r)["uid"]
+
+	var req ActivationResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	if req.Activation == nil {
+		writeJSONError(w, http.StatusBadRequest, "activation is required", nil)
+		return
+	}
+	if s.agent.mkClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
+		return
+	}
+
+	before, err := s.agent.mkClient.GetNodeActivation(r.Context(), uid)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "Node not found", nil)
+		return
+	}
+	if err := s.agent.mkClient.ResetActivation(r.Context(), uid, *req.Activation); err != nil {
+		if errors.Is(err, graph.ErrInvalidActivationReset) {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		s.logger.Error("Failed to reset node activation", zap.String("uid", uid), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to reset activation", nil)
+		return
+	}
+	after, err := s.agent.mkClient.GetNodeActivation(r.Context(), uid)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read node after reset", nil)
+		return
+	}
+
+	s.logActivity(r.Context(), adminUser, "activation_reset",
+		fmt.Sprintf("Reset activation of %s (%s) from %g (%d accesses) to %g",
+			uid, before.Name, before.Activation, before.AccessCount, *req.Activation))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"previous": before,
+		"node":     after,
+	})
+}
+
+// handleResetActivations resets every node in a namespace matching a filter,
+// e.g. all conversation metadata (name_prefix "Conversation_")
+// POST /api/admin/activation/reset
+func (s *Server) handleResetActivations(w http.ResponseWriter, r *http.Request) {
+	adminUser := GetUserID(r.Context())
+
+	var req ActivationResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	if req.Namespace == "" {
+		writeJSONError(w, http.StatusBadRequest, "namespace is required", nil)
+		return
+	}
+	if req.Activation == nil {
+		writeJSONError(w, http.StatusBadRequest, "activation is required", nil)
+		return
+	}
+	if s.agent.mkClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
+		return
+	}
+
+	count, err := s.agent.mkClient.ResetActivations(r.Context(), req.ActivationResetFilter, *req.Activation)
+	if errors.Is(err, graph.ErrInvalidActivationReset) {
+		writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		// Pages before the failure stay reset; report how many
+		s.logger.Error("Failed to reset activations",
+			zap.String("namespace", req.Namespace), zap.Int("reset", count), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to reset activations", map[string]int{"reset": count})
+		return
+	}
+
+	s.logActivity(r.Context(), adminUser, "activation_reset",
+		fmt.Sprintf("Reset activation of %d nodes in %s (type=%q name_prefix=%q min_activation=%g) to %g",
+			count, req.Namespace, req.Type, req.NamePrefix, req.MinActivation, *req.Activation))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"namespace": req.Namespace,
+		"reset":     count,
+	})
+}
@@ -25,7 +25,7 @@ func (s *Server) handleListAffiliates(w http.ResponseWriter, r *http.Request) {
 	val, err := s.agent.RedisClient.HGetAll(ctx, "affiliate:partners").Result()
 	if err != nil {
 		s.logger.Error("Failed to fetch affiliates", zap.Error(err))
-		http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch data", nil)
 		return
 	}
 
@@ -62,25 +62,25 @@ func (s *Server) handleCreateAffiliate(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var da AffiliateDTO
 	if err := json.NewDecoder(r.Body).Decode(&da); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if da.Code == "" || da.User == "" {
-		http.Error(w, "Code and User are required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Code and User are required", nil)
 		return
 	}
 
 	// Persist to Redis
 	data, err := json.Marshal(da)
 	if err != nil {
-		http.Error(w, "Failed to marshal data", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to marshal data", nil)
 		return
 	}
 
 	if err := s.agent.RedisClient.HSet(ctx, "affiliate:partners", da.Code, data).Err(); err != nil {
 		s.logger.Error("Failed to create affiliate", zap.Error(err))
-		http.Error(w, "Failed to create affiliate", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to create affiliate", nil)
 		return
 	}
 
@@ -98,7 +98,7 @@ func (s *Server) handleDeleteAffiliate(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.agent.RedisClient.HDel(ctx, "affiliate:partners", code).Err(); err != nil {
 		s.logger.Error("Failed to delete affiliate", zap.Error(err))
-		http.Error(w, "Failed to delete affiliate", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete affiliate", nil)
 		return
 	}
 
@@ -31,7 +31,7 @@ func (s *Server) handleListEmergencyRequests(w http.ResponseWriter, r *http.Requ
 	val, err := s.agent.RedisClient.HGetAll(ctx, "emergency:requests").Result()
 	if err != nil {
 		s.logger.Error("Failed to fetch emergency requests", zap.Error(err))
-		http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch data", nil)
 		return
 	}
 
@@ -72,14 +72,14 @@ func (s *Server) handleApproveEmergencyRequest(w http.ResponseWriter, r *http.Re
 	// 1. Get existing request
 	val, err := s.agent.RedisClient.HGet(ctx, "emergency:requests", requestID).Result()
 	if err != nil {
-		http.Error(w, "Request not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "Request not found", nil)
 		return
 	}
 
 	// 2. Update Status
 	var req EmergencyRequestDTO
 	if err := json.Unmarshal([]byte(val), &req); err != nil {
-		http.Error(w, "Data corruption", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Data corruption", nil)
 		return
 	}
 	req.Status = "approved"
@@ -104,14 +104,14 @@ func (s *Server) handleDenyEmergencyRequest(w http.ResponseWriter, r *http.Reque
 	// 1. Get existing request
 	val, err := s.agent.RedisClient.HGet(ctx, "emergency:requests", requestID).Result()
 	if err != nil {
-		http.Error(w, "Request not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "Request not found", nil)
 		return
 	}
 
 	// 2. Update Status
 	var req EmergencyRequestDTO
 	if err := json.Unmarshal([]byte(val), &req); err != nil {
-		http.Error(w, "Data corruption", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Data corruption", nil)
 		return
 	}
 	req.Status = "denied"
@@ -34,7 +34,7 @@ func (s *Server) handleGetRevenue(w http.ResponseWriter, r *http.Request) {
 	plans, err := s.agent.RedisClient.HGetAll(ctx, "user_plan").Result()
 	if err != nil {
 		s.logger.Error("Failed to fetch user plans", zap.Error(err))
-		http.Error(w, "Failed to fetch revenue data", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch revenue data", nil)
 		return
 	}
 
@@ -100,14 +100,14 @@ func (s *Server) handleUpdateSubscription(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 	var req SubscriptionUpdate
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	// Update Redis
 	if err := s.agent.RedisClient.HSet(ctx, "user_plan", req.Username, req.Plan).Err(); err != nil {
 		s.logger.Error("Failed to update subscription", zap.Error(err))
-		http.Error(w, "Persistence failed", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Persistence failed", nil)
 		return
 	}
 
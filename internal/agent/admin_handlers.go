@@ -4,6 +4,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"runtime"
 	"strings"
@@ -58,7 +59,7 @@ func (arl *AdminRateLimiter) Middleware(next http.Handler) http.Handler {
 			arl.logger.Warn("Admin rate limit exceeded",
 				zap.String("identifier", identifier),
 				zap.String("path", r.URL.Path))
-			http.Error(w, "Rate limit exceeded. Please slow down.", http.StatusTooManyRequests)
+			writeJSONError(w, http.StatusTooManyRequests, "Rate limit exceeded. Please slow down.", nil)
 			return
 		}
 
@@ -248,6 +249,13 @@ func (s *Server) SetupAdminRoutes(r *mux.Router, jwtMiddleware *JWTMiddleware) {
 	adminRouter.HandleFunc("/system/stats", s.handleAdminSystemStats).Methods("GET", "OPTIONS")
 	adminRouter.HandleFunc("/system/reflection", s.handleAdminTriggerReflection).Methods("POST", "OPTIONS")
 
+	// Activation tuning
+	adminRouter.HandleFunc("/activation-config", s.handleGetActivationConfig).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/activation-config", s.handleUpdateActivationConfig).Methods("PUT", "OPTIONS")
+	adminRouter.HandleFunc("/nodes/{uid}/activation", s.handleGetNodeActivation).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/nodes/{uid}/activation", s.handleResetNodeActivation).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/activation/reset", s.handleResetActivations).Methods("POST", "OPTIONS")
+
 	// Group management
 	adminRouter.HandleFunc("/groups", s.handleAdminListAllGroups).Methods("GET", "OPTIONS")
 	adminRouter.HandleFunc("/groups/{id}", s.handleAdminDeleteGroup).Methods("DELETE", "OPTIONS")
@@ -309,23 +317,23 @@ func (s *Server) handleAdminCreateUser(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		s.logger.Warn("Invalid body in create user", zap.Error(err))
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	// Validate username
 	if body.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Username is required", nil)
 		return
 	}
 
 	// SECURITY: Validate username format and length
 	if len(body.Username) < 3 {
-		http.Error(w, "Username must be at least 3 characters", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Username must be at least 3 characters", nil)
 		return
 	}
 	if len(body.Username) > 100 {
-		http.Error(w, "Username exceeds maximum length", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Username exceeds maximum length", nil)
 		return
 	}
 
@@ -333,34 +341,34 @@ func (s *Server) handleAdminCreateUser(w http.ResponseWriter, r *http.Request) {
 	var hashedPassword string
 	switch {
 	case body.Password != "" && body.PasswordHash != "":
-		http.Error(w, "Provide either password or password_hash, not both", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Provide either password or password_hash, not both", nil)
 		return
 	case body.Password != "":
 		// Plaintext password provided - validate and hash
 		if len(body.Password) < 8 {
-			http.Error(w, "Password must be at least 8 characters", http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "Password must be at least 8 characters", nil)
 			return
 		}
 		var err error
 		hashedPassword, err = HashPassword(body.Password)
 		if err != nil {
 			s.logger.Error("Failed to hash password", zap.Error(err))
-			http.Error(w, "Failed to process password", http.StatusInternalServerError)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to process password", nil)
 			return
 		}
 	case body.PasswordHash != "":
 		// Pre-hashed password provided - validate format and require force flag
 		if !body.Force {
-			http.Error(w, "Pre-hashed passwords require force=true", http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "Pre-hashed passwords require force=true", nil)
 			return
 		}
 		if !isBcryptHash(body.PasswordHash) {
-			http.Error(w, "Invalid password hash format (must be bcrypt)", http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "Invalid password hash format (must be bcrypt)", nil)
 			return
 		}
 		hashedPassword = body.PasswordHash
 	default:
-		http.Error(w, "Password or password_hash is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Password or password_hash is required", nil)
 		return
 	}
 
@@ -372,18 +380,18 @@ func (s *Server) handleAdminCreateUser(w http.ResponseWriter, r *http.Request) {
 	// Check if user exists
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+body.Username).Result()
 	if err != nil {
-		http.Error(w, "Database error checking user", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Database error checking user", nil)
 		return
 	}
 	if exists > 0 {
-		http.Error(w, "User already exists", http.StatusConflict)
+		writeJSONError(w, http.StatusConflict, "User already exists", nil)
 		return
 	}
 
 	// Store hashed password
 	err = s.agent.RedisClient.Set(ctx, "user:"+body.Username, hashedPassword, 0).Err()
 	if err != nil {
-		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to create user", nil)
 		return
 	}
 
@@ -425,7 +433,7 @@ func (s *Server) handleAdminListUsers(w http.ResponseWriter, r *http.Request) {
 	keys, err := s.agent.RedisClient.Keys(ctx, "user:*").Result()
 	if err != nil {
 		s.logger.Error("Failed to list users", zap.Error(err))
-		http.Error(w, "Failed to list users", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to list users", nil)
 		return
 	}
 
@@ -472,7 +480,7 @@ func (s *Server) handleAdminGetUser(w http.ResponseWriter, r *http.Request) {
 	// Check if user exists
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+username).Result()
 	if err != nil || exists == 0 {
-		http.Error(w, "User not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "User not found", nil)
 		return
 	}
 
@@ -498,33 +506,33 @@ func (s *Server) handleAdminUpdateUserRole(w http.ResponseWriter, r *http.Reques
 
 	// Prevent admin from demoting themselves
 	if username == adminUser {
-		http.Error(w, "Cannot modify your own role", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Cannot modify your own role", nil)
 		return
 	}
 
 	var req UpdateRoleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	// Validate role
 	if req.Role != "admin" && req.Role != "user" {
-		http.Error(w, "Role must be 'admin' or 'user'", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Role must be 'admin' or 'user'", nil)
 		return
 	}
 
 	// Check if user exists
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+username).Result()
 	if err != nil || exists == 0 {
-		http.Error(w, "User not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "User not found", nil)
 		return
 	}
 
 	// Update role
 	if err := s.agent.RedisClient.Set(ctx, "user_role:"+username, req.Role, 0).Err(); err != nil {
 		s.logger.Error("Failed to update user role", zap.Error(err))
-		http.Error(w, "Failed to update role", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to update role", nil)
 		return
 	}
 
@@ -553,14 +561,14 @@ func (s *Server) handleAdminDeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	// Prevent admin from deleting themselves
 	if username == adminUser {
-		http.Error(w, "Cannot delete your own account", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Cannot delete your own account", nil)
 		return
 	}
 
 	// Check if user exists
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+username).Result()
 	if err != nil || exists == 0 {
-		http.Error(w, "User not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "User not found", nil)
 		return
 	}
 
@@ -571,7 +579,7 @@ func (s *Server) handleAdminDeleteUser(w http.ResponseWriter, r *http.Request) {
 	_, err = pipe.Exec(ctx)
 	if err != nil {
 		s.logger.Error("Failed to delete user", zap.Error(err))
-		http.Error(w, "Failed to delete user", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete user", nil)
 		return
 	}
 
@@ -702,19 +710,89 @@ func (s *Server) handleAdminSystemStats(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(stats)
 }
 
+// handleRetryDeadLetters re-ingests every event parked in the ingestion dead-letter queue
+func (s *Server) handleRetryDeadLetters(w http.ResponseWriter, r *http.Request) {
+	adminUser := GetUserID(r.Context())
+	if GetUserRole(r.Context()) != "admin" {
+		writeJSONError(w, http.StatusForbidden, "Forbidden: Admin access required", nil)
+		return
+	}
+
+	if s.agent.mkClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
+		return
+	}
+
+	ingested, pending, err := s.agent.mkClient.RetryDeadLetters(r.Context())
+	if err != nil {
+		s.logger.Error("Failed to retry dead letters", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to retry dead-lettered events", map[string]interface{}{
+			"ingested": ingested,
+		})
+		return
+	}
+
+	s.logger.Info("Dead-letter retry triggered by admin",
+		zap.String("admin", adminUser),
+		zap.Int("ingested", ingested),
+		zap.Int64("pending", pending))
+	s.logActivity(r.Context(), adminUser, "dlq_retry", fmt.Sprintf("Retried ingestion dead letters: %d ingested, %d pending", ingested, pending))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ingested": ingested,
+		"pending":  pending,
+	})
+}
+
+// handleReindexPending re-indexes every node whose vector upsert failed during ingestion
+func (s *Server) handleReindexPending(w http.ResponseWriter, r *http.Request) {
+	adminUser := GetUserID(r.Context())
+	if GetUserRole(r.Context()) != "admin" {
+		writeJSONError(w, http.StatusForbidden, "Forbidden: Admin access required", nil)
+		return
+	}
+
+	if s.agent.mkClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
+		return
+	}
+
+	indexed, pending, err := s.agent.mkClient.ReindexPending(r.Context())
+	if err != nil {
+		s.logger.Error("Failed to reindex pending nodes", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to reindex pending nodes", map[string]interface{}{
+			"indexed": indexed,
+		})
+		return
+	}
+
+	s.logger.Info("Reindex triggered by admin",
+		zap.String("admin", adminUser),
+		zap.Int("indexed", indexed),
+		zap.Int64("pending", pending))
+	s.logActivity(r.Context(), adminUser, "reindex", fmt.Sprintf("Reindexed pending vectors: %d indexed, %d pending", indexed, pending))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"indexed": indexed,
+		"pending": pending,
+	})
+}
+
 // handleAdminTriggerReflection manually triggers a reflection cycle
 func (s *Server) handleAdminTriggerReflection(w http.ResponseWriter, r *http.Request) {
 	adminUser := GetUserID(r.Context())
 
 	if s.agent.mkClient == nil {
-		http.Error(w, "Memory kernel not available", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory kernel not available", nil)
 		return
 	}
 
 	err := s.agent.mkClient.TriggerReflection(r.Context())
 	if err != nil {
 		s.logger.Error("Failed to trigger reflection", zap.Error(err))
-		http.Error(w, "Failed to trigger reflection", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to trigger reflection", nil)
 		return
 	}
 
@@ -747,7 +825,7 @@ func (s *Server) handleAdminListAllGroups(w http.ResponseWriter, r *http.Request
 	groupKeys, err := s.agent.RedisClient.Keys(ctx, "group:*").Result()
 	if err != nil {
 		s.logger.Error("Failed to list groups", zap.Error(err))
-		http.Error(w, "Failed to list groups", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to list groups", nil)
 		return
 	}
 
@@ -802,7 +880,7 @@ func (s *Server) handleAdminDeleteGroup(w http.ResponseWriter, r *http.Request)
 	// Check if group exists
 	exists, err := s.agent.RedisClient.Exists(ctx, "group:"+groupID).Result()
 	if err != nil || exists == 0 {
-		http.Error(w, "Group not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "Group not found", nil)
 		return
 	}
 
@@ -815,7 +893,7 @@ func (s *Server) handleAdminDeleteGroup(w http.ResponseWriter, r *http.Request)
 
 	if err != nil {
 		s.logger.Error("Failed to delete group", zap.Error(err), zap.String("group_id", groupID))
-		http.Error(w, "Failed to delete group", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete group", nil)
 		return
 	}
 
@@ -929,7 +1007,7 @@ func (s *Server) handleAdminSearchUsers(w http.ResponseWriter, r *http.Request)
 	keys, err := s.agent.RedisClient.Keys(ctx, "user:*").Result()
 	if err != nil {
 		s.logger.Error("Failed to search users", zap.Error(err))
-		http.Error(w, "Failed to search users", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to search users", nil)
 		return
 	}
 
@@ -994,7 +1072,7 @@ func (s *Server) handleAdminGetUserDetails(w http.ResponseWriter, r *http.Reques
 	// Check if user exists
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+username).Result()
 	if err != nil || exists == 0 {
-		http.Error(w, "User not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "User not found", nil)
 		return
 	}
 
@@ -1056,20 +1134,20 @@ func (s *Server) handleAdminExtendTrial(w http.ResponseWriter, r *http.Request)
 	// Parse request
 	var req ExtendTrialRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	// Validate days
 	if req.Days < 1 || req.Days > 365 {
-		http.Error(w, "Days must be between 1 and 365", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Days must be between 1 and 365", nil)
 		return
 	}
 
 	// Check if user exists
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+username).Result()
 	if err != nil || exists == 0 {
-		http.Error(w, "User not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "User not found", nil)
 		return
 	}
 
@@ -1090,7 +1168,7 @@ func (s *Server) handleAdminExtendTrial(w http.ResponseWriter, r *http.Request)
 	err = s.agent.RedisClient.Set(ctx, "user_trial:"+username, newExpiry.Format(time.RFC3339), 0).Err()
 	if err != nil {
 		s.logger.Error("Failed to extend trial", zap.Error(err))
-		http.Error(w, "Failed to extend trial", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to extend trial", nil)
 		return
 	}
 
@@ -1255,7 +1333,7 @@ func (s *Server) handleAdminExportUsers(w http.ResponseWriter, r *http.Request)
 	keys, err := s.agent.RedisClient.Keys(ctx, "user:*").Result()
 	if err != nil {
 		s.logger.Error("Failed to export users", zap.Error(err))
-		http.Error(w, "Failed to export users", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to export users", nil)
 		return
 	}
 
@@ -1358,22 +1436,22 @@ func (s *Server) handleAdminBatchUpdateRole(w http.ResponseWriter, r *http.Reque
 
 	var req BatchRoleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if req.Role != "admin" && req.Role != "user" {
-		http.Error(w, "Role must be 'admin' or 'user'", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Role must be 'admin' or 'user'", nil)
 		return
 	}
 
 	if len(req.Usernames) == 0 {
-		http.Error(w, "No usernames provided", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "No usernames provided", nil)
 		return
 	}
 
 	if len(req.Usernames) > 50 {
-		http.Error(w, "Maximum 50 users per batch", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Maximum 50 users per batch", nil)
 		return
 	}
 
@@ -1432,17 +1510,17 @@ func (s *Server) handleAdminBatchDelete(w http.ResponseWriter, r *http.Request)
 
 	var req BatchDeleteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if len(req.Usernames) == 0 {
-		http.Error(w, "No usernames provided", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "No usernames provided", nil)
 		return
 	}
 
 	if len(req.Usernames) > 50 {
-		http.Error(w, "Maximum 50 users per batch", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Maximum 50 users per batch", nil)
 		return
 	}
 
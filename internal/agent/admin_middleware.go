@@ -31,7 +31,7 @@ func (m *AdminMiddleware) Middleware(next http.Handler) http.Handler {
 				zap.String("user_id", userID),
 				zap.String("role", role),
 				zap.String("path", r.URL.Path))
-			http.Error(w, "Forbidden: Admin access required", http.StatusForbidden)
+			writeJSONError(w, http.StatusForbidden, "Forbidden: Admin access required", nil)
 			return
 		}
 
@@ -28,7 +28,7 @@ func (s *Server) handleListCampaigns(w http.ResponseWriter, r *http.Request) {
 	val, err := s.agent.RedisClient.HGetAll(ctx, "operations:campaigns").Result()
 	if err != nil {
 		s.logger.Error("Failed to fetch campaigns", zap.Error(err))
-		http.Error(w, "Failed to fetch data", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch data", nil)
 		return
 	}
 
@@ -65,25 +65,25 @@ func (s *Server) handleCreateCampaign(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var dc CampaignDTO
 	if err := json.NewDecoder(r.Body).Decode(&dc); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if dc.ID == "" || dc.Name == "" {
-		http.Error(w, "ID and Name are required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "ID and Name are required", nil)
 		return
 	}
 
 	// Persist to Redis
 	data, err := json.Marshal(dc)
 	if err != nil {
-		http.Error(w, "Failed to marshal data", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to marshal data", nil)
 		return
 	}
 
 	if err := s.agent.RedisClient.HSet(ctx, "operations:campaigns", dc.ID, data).Err(); err != nil {
 		s.logger.Error("Failed to create campaign", zap.Error(err))
-		http.Error(w, "Failed to create campaign", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to create campaign", nil)
 		return
 	}
 
@@ -101,7 +101,7 @@ func (s *Server) handleDeleteCampaign(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.agent.RedisClient.HDel(ctx, "operations:campaigns", id).Err(); err != nil {
 		s.logger.Error("Failed to delete campaign", zap.Error(err))
-		http.Error(w, "Failed to delete campaign", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete campaign", nil)
 		return
 	}
 
@@ -29,7 +29,7 @@ func (s *Server) handleListTickets(w http.ResponseWriter, r *http.Request) {
 	val, err := s.agent.RedisClient.HGetAll(ctx, "support:tickets").Result()
 	if err != nil {
 		s.logger.Error("Failed to fetch tickets from Redis", zap.Error(err))
-		http.Error(w, "Failed to fetch tickets", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch tickets", nil)
 		return
 	}
 
@@ -70,14 +70,14 @@ func (s *Server) handleResolveTicket(w http.ResponseWriter, r *http.Request) {
 	// 1. Get existing ticket
 	val, err := s.agent.RedisClient.HGet(ctx, "support:tickets", ticketID).Result()
 	if err != nil {
-		http.Error(w, "Ticket not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "Ticket not found", nil)
 		return
 	}
 
 	// 2. Update Status
 	var t TicketDTO
 	if err := json.Unmarshal([]byte(val), &t); err != nil {
-		http.Error(w, "Data corruption", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Data corruption", nil)
 		return
 	}
 	t.Status = "closed"
@@ -85,7 +85,7 @@ func (s *Server) handleResolveTicket(w http.ResponseWriter, r *http.Request) {
 	// 3. Save back to Redis
 	newData, _ := json.Marshal(t)
 	if err := s.agent.RedisClient.HSet(ctx, "support:tickets", ticketID, newData).Err(); err != nil {
-		http.Error(w, "Failed to update ticket", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to update ticket", nil)
 		return
 	}
 
@@ -59,7 +59,7 @@ func (s *Server) handleToggleFlag(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var req ToggleFlagRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -71,7 +71,7 @@ func (s *Server) handleToggleFlag(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.agent.RedisClient.HSet(ctx, "system:flags", req.Key, val).Err(); err != nil {
 		s.logger.Error("Failed to persist flag toggle", zap.Error(err))
-		http.Error(w, "Failed to update flag", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to update flag", nil)
 		return
 	}
 
@@ -3,12 +3,14 @@
 package agent
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +19,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"github.com/reflective-memory-kernel/internal/policy"
 	"github.com/reflective-memory-kernel/internal/precortex"
 )
@@ -28,6 +31,20 @@ type Config struct {
 	AIServicesURL   string
 	RedisAddress    string
 	ResponseTimeout time.Duration
+
+	// IngestBufferSize is the capacity of the zero-copy ingest channel
+	IngestBufferSize int
+
+	// MaxUploadSize caps a single document upload in bytes; larger uploads get 413
+	MaxUploadSize int64
+	// UploadDir stages uploads for ingestion and must be readable by the AI service
+	UploadDir string
+
+	// Crystallize decides when conversations are summarized into durable memory
+	Crystallize CrystallizeConfig
+
+	// Retention bounds how many conversations are held in memory, and for how long
+	Retention RetentionConfig
 }
 
 // DefaultConfig returns sensible defaults
@@ -38,6 +55,10 @@ func DefaultConfig() Config {
 		AIServicesURL:   "http://localhost:8000",
 		RedisAddress:    "127.0.0.1:6379",
 		ResponseTimeout: 10 * time.Second,
+
+		IngestBufferSize: DefaultIngestBufferSize,
+		MaxUploadSize:    DefaultMaxUploadSize,
+		UploadDir:        DefaultUploadDir(),
 	}
 }
 
@@ -54,12 +75,16 @@ type Agent struct {
 	preCortex     *precortex.PreCortex  // Cognitive firewall for cost reduction
 	PolicyManager *policy.PolicyManager // Policy enforcement
 
-	// Active conversations
+	// Active conversations, most recently used at the front of convLRU
 	conversations map[string]*Conversation
+	convLRU       *list.List
 	convMu        sync.RWMutex
+	convStore     ConversationStore // nil = the kernel's graph client
+	convEvicted   atomic.Int64
 
 	// Direct Ingestion (Zero-Copy)
-	ingestChan chan *graph.TranscriptEvent
+	ingestChan  chan *graph.TranscriptEvent
+	ingestStats ingestStats
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -72,6 +97,15 @@ type Conversation struct {
 	StartedAt time.Time
 	Turns     []Turn
 	mu        sync.Mutex
+
+	// Turns up to crystallizedThrough are already in durable memory
+	crystallizedThrough time.Time
+	crystallizing       bool
+	crystallizeAttempt  time.Time
+
+	// Retention bookkeeping, guarded by Agent.convMu
+	lastUsed time.Time
+	lruElem  *list.Element
 }
 
 // Turn represents one conversational turn
@@ -90,6 +124,7 @@ func New(cfg Config, logger *zap.Logger) (*Agent, error) {
 		config:        cfg,
 		logger:        logger,
 		conversations: make(map[string]*Conversation),
+		convLRU:       list.New(),
 		ctx:           ctx,
 		cancel:        cancel,
 	}
@@ -144,6 +179,11 @@ func (a *Agent) Start() error {
 
 	a.logger.Info("Front-End Agent started successfully")
 
+	// Crystallize idle conversations into durable memory
+	go a.runCrystallizer()
+	// Evict them from memory once they have stayed idle
+	go a.runRetention()
+
 	// Initialize Policy Manager
 	a.InitPolicyManager()
 
@@ -234,14 +274,17 @@ func (a *Agent) Chat(ctx context.Context, userID, conversationID, namespace, mes
 
 			// Record turn and stream transcript
 			conv := a.getOrCreateConversation(userID, conversationID)
-			conv.mu.Lock()
-			conv.Turns = append(conv.Turns, Turn{
+			turn := Turn{
 				Timestamp: time.Now(),
 				UserQuery: message,
 				Response:  pcResponse.Text,
 				Latency:   latency,
-			})
+			}
+			conv.mu.Lock()
+			conv.Turns = append(conv.Turns, turn)
 			conv.mu.Unlock()
+			go a.persistTurn(userID, conversationID, namespace, turn)
+			a.maybeCrystallize(conv)
 
 			// Stream transcript (still learn from Pre-Cortex interactions)
 			go a.streamTranscript(userID, conversationID, namespace, message, pcResponse.Text)
@@ -262,6 +305,7 @@ func (a *Agent) Chat(ctx context.Context, userID, conversationID, namespace, mes
 		Query:           message,
 		MaxResults:      5,
 		IncludeInsights: true,
+		BriefStyle:      "detailed", // Chat wants the full picture; dashboards ask for concise
 	}
 
 	var mkResponse *graph.ConsultationResponse
@@ -312,8 +356,8 @@ func (a *Agent) Chat(ctx context.Context, userID, conversationID, namespace, mes
 		}
 		proactiveAlerts = mkResponse.ProactiveAlerts
 		a.logger.Info("Context brief from MK",
-			zap.String("brief", contextBrief),
 			zap.Int("facts_count", len(mkResponse.RelevantFacts)))
+		a.logger.Debug("Context brief", zap.String("brief", contextBrief))
 	}
 
 	// Get user's API keys for this request (for per-user billing)
@@ -337,15 +381,18 @@ func (a *Agent) Chat(ctx context.Context, userID, conversationID, namespace, mes
 
 	latency := time.Since(startTime)
 
-	// Step 3: Record this turn
-	conv.mu.Lock()
-	conv.Turns = append(conv.Turns, Turn{
+	// Step 3: Record this turn (in memory, and persisted so it survives a restart)
+	turn := Turn{
 		Timestamp: time.Now(),
 		UserQuery: message,
 		Response:  response,
 		Latency:   latency,
-	})
+	}
+	conv.mu.Lock()
+	conv.Turns = append(conv.Turns, turn)
 	conv.mu.Unlock()
+	go a.persistTurn(userID, conversationID, namespace, turn)
+	a.maybeCrystallize(conv)
 
 	// Step 4: Stream transcript to Memory Kernel (async, non-blocking)
 	go a.streamTranscript(userID, conversationID, namespace, message, response)
@@ -397,13 +444,11 @@ func (a *Agent) streamTranscript(userID, conversationID, namespace, userQuery, a
 	// ... rest of function (unchanged usually)
 	// Zero-Copy Path: Send directly to Kernel via channel if configured
 	if a.ingestChan != nil {
-		select {
-		case a.ingestChan <- &event:
-			a.logger.Debug("Transcript sent via direct channel")
+		if a.enqueueIngest(&event) {
+			a.logger.Debug("Transcript handled by direct channel")
 			return
-		default:
-			a.logger.Warn("Ingest channel full, falling back to NATS/Dropping")
 		}
+		a.logger.Debug("Ingest channel full, spilling transcript to NATS")
 	}
 
 	// Legacy Path: NATS
@@ -424,23 +469,19 @@ func (a *Agent) streamTranscript(userID, conversationID, namespace, userQuery, a
 	}
 }
 
-// getOrCreateConversation gets or creates a conversation
+// getOrCreateConversation gets a conversation, reloading it if it was
+// evicted from memory, or creates it
 func (a *Agent) getOrCreateConversation(userID, conversationID string) *Conversation {
-	a.convMu.Lock()
-	defer a.convMu.Unlock()
-
-	if conv, ok := a.conversations[conversationID]; ok {
+	if conv := a.GetConversation(conversationID); conv != nil {
 		return conv
 	}
 
-	conv := &Conversation{
+	return a.cacheConversation(&Conversation{
 		ID:        conversationID,
 		UserID:    userID,
 		StartedAt: time.Now(),
 		Turns:     make([]Turn, 0),
-	}
-	a.conversations[conversationID] = conv
-	return conv
+	})
 }
 
 // GetStats returns agent statistics
@@ -464,11 +505,16 @@ func (a *Agent) GetStats() map[string]interface{} {
 		avgLatency = totalLatency / time.Duration(totalTurns)
 	}
 
-	return map[string]interface{}{
-		"active_conversations": len(a.conversations),
-		"total_turns":          totalTurns,
-		"average_latency_ms":   avgLatency.Milliseconds(),
+	stats := map[string]interface{}{
+		"active_conversations":  len(a.conversations),
+		"evicted_conversations": a.convEvicted.Load(),
+		"total_turns":           totalTurns,
+		"average_latency_ms":    avgLatency.Milliseconds(),
 	}
+	if a.ingestChan != nil {
+		stats["ingest_channel"] = a.ingestChannelStats()
+	}
+	return stats
 }
 
 // MarshalJSON for Turn
@@ -550,7 +596,7 @@ func (a *Agent) getUserGroupsForPolicy(ctx context.Context, userID string) []str
 				for _, g := range groups {
 					// Policy engine expects group UID without "group_" prefix
 					// Namespace format is "group_<UUID>", so extract the UUID part
-					groupUID := strings.TrimPrefix(g.Namespace, "group_")
+					_, groupUID := nsutil.Parse(g.Namespace)
 					groupUIDs = append(groupUIDs, groupUID)
 				}
 				return groupUIDs
@@ -646,9 +692,14 @@ func (a *Agent) GetGraphClient() *graph.Client {
 	return nil
 }
 
-// GetConversation returns a conversation by ID (for MCP handlers)
+// GetConversation returns a conversation by ID (for MCP handlers), reloading
+// it from the conversation store if it was evicted from memory
 func (a *Agent) GetConversation(conversationID string) *Conversation {
-	a.convMu.RLock()
-	defer a.convMu.RUnlock()
-	return a.conversations[conversationID]
+	conv, err := a.LoadConversation(context.Background(), conversationID)
+	if err != nil {
+		a.logger.Warn("Failed to reload conversation",
+			zap.String("conversation_id", conversationID),
+			zap.Error(err))
+	}
+	return conv
 }
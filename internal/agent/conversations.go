@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// conversationStoreTimeout bounds a single turn write or conversation load
+const conversationStoreTimeout = 5 * time.Second
+
+// ConversationStore persists conversation turns so a conversation outlives the
+// agent process. *graph.Client implements it.
+type ConversationStore interface {
+	AppendConversationTurn(ctx context.Context, turn *graph.ConversationTurn) error
+	GetConversationTurns(ctx context.Context, conversationID string) ([]graph.ConversationTurn, error)
+	DeleteConversationTurns(ctx context.Context, namespace, conversationID string) (int, error)
+}
+
+// SetConversationStore overrides where turns are persisted and reloaded from.
+// By default the kernel's graph client is used when the agent runs in-process.
+func (a *Agent) SetConversationStore(store ConversationStore) {
+	a.convStore = store
+}
+
+// conversationStore returns the configured store, or nil when turns can't be persisted
+func (a *Agent) conversationStore() ConversationStore {
+	if a.convStore != nil {
+		return a.convStore
+	}
+	if graphClient := a.GetGraphClient(); graphClient != nil {
+		return graphClient
+	}
+	return nil
+}
+
+// persistTurn writes a turn to the conversation store; failures are logged, the
+// in-memory conversation is still authoritative for this process
+func (a *Agent) persistTurn(userID, conversationID, namespace string, turn Turn) {
+	store := a.conversationStore()
+	if store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), conversationStoreTimeout)
+	defer cancel()
+
+	err := store.AppendConversationTurn(ctx, &graph.ConversationTurn{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Namespace:      namespace,
+		Timestamp:      turn.Timestamp,
+		UserQuery:      turn.UserQuery,
+		Response:       turn.Response,
+		LatencyMs:      turn.Latency.Milliseconds(),
+	})
+	if err != nil {
+		a.logger.Warn("Failed to persist conversation turn",
+			zap.String("conversation_id", conversationID),
+			zap.Error(err))
+	}
+}
+
+// LoadConversation returns a conversation from memory or, when this process
+// doesn't have it (e.g. after a restart or an eviction), from the conversation
+// store, holding it in memory again. It returns nil without error when the
+// conversation is unknown to both.
+func (a *Agent) LoadConversation(ctx context.Context, conversationID string) (*Conversation, error) {
+	if conv := a.cachedConversation(conversationID); conv != nil {
+		return conv, nil
+	}
+	store := a.conversationStore()
+	if store == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, conversationStoreTimeout)
+	defer cancel()
+	stored, err := store.GetConversationTurns(ctx, conversationID)
+	if err != nil || len(stored) == 0 {
+		return nil, err
+	}
+
+	conv := &Conversation{
+		ID:        conversationID,
+		UserID:    stored[0].UserID,
+		StartedAt: stored[0].Timestamp,
+		Turns:     make([]Turn, 0, len(stored)),
+	}
+	for _, t := range stored {
+		conv.Turns = append(conv.Turns, Turn{
+			Timestamp: t.Timestamp,
+			UserQuery: t.UserQuery,
+			Response:  t.Response,
+			Latency:   time.Duration(t.LatencyMs) * time.Millisecond,
+		})
+	}
+	return a.cacheConversation(conv), nil
+}
+
+// DeleteConversation drops a conversation from memory and deletes its turns
+// in namespace from the conversation store, returning how many turns were
+// deleted. Callers check that the conversation is the caller's first.
+func (a *Agent) DeleteConversation(ctx context.Context, namespace, conversationID string) (int, error) {
+	a.convMu.Lock()
+	if conv, ok := a.conversations[conversationID]; ok {
+		delete(a.conversations, conversationID)
+		if conv.lruElem != nil {
+			a.convLRU.Remove(conv.lruElem)
+			conv.lruElem = nil
+		}
+	}
+	a.convMu.Unlock()
+
+	store := a.conversationStore()
+	if store == nil {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, conversationStoreTimeout)
+	defer cancel()
+	return store.DeleteConversationTurns(ctx, namespace, conversationID)
+}
@@ -0,0 +1,319 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+// Crystallization defaults: a conversation is summarized into memory once it
+// has this many uncrystallized turns, or once it has been idle this long
+const (
+	DefaultCrystallizeTurnThreshold = 20
+	DefaultCrystallizeIdleTimeout   = 15 * time.Minute
+)
+
+const (
+	// crystallizeTimeout bounds one summarize-and-ingest run
+	crystallizeTimeout = 2 * time.Minute
+	// crystallizeSweepInterval is how often idle conversations are looked for
+	crystallizeSweepInterval = time.Minute
+	// crystallizeRetryDelay keeps a conversation over the turn threshold from
+	// retrying a failed run on every turn
+	crystallizeRetryDelay = time.Minute
+)
+
+// ErrCrystallizeInProgress is returned when a conversation is already being crystallized
+var ErrCrystallizeInProgress = errors.New("conversation is already being crystallized")
+
+// CrystallizeConfig decides when a conversation is crystallized into durable
+// memory. Zero values use the defaults; a negative value disables that trigger.
+type CrystallizeConfig struct {
+	TurnThreshold int           // Uncrystallized turns that trigger a run
+	IdleTimeout   time.Duration // Inactivity after which pending turns are crystallized
+}
+
+// CrystallizeConfigFromEnv reads CRYSTALLIZE_TURN_THRESHOLD (a count) and
+// CRYSTALLIZE_IDLE_TIMEOUT (a duration such as "10m"); unset or invalid values
+// keep the defaults
+func CrystallizeConfigFromEnv() CrystallizeConfig {
+	var cfg CrystallizeConfig
+	if n, err := strconv.Atoi(os.Getenv("CRYSTALLIZE_TURN_THRESHOLD")); err == nil {
+		cfg.TurnThreshold = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("CRYSTALLIZE_IDLE_TIMEOUT")); err == nil {
+		cfg.IdleTimeout = d
+	}
+	return cfg
+}
+
+// withDefaults fills in unset thresholds
+func (c CrystallizeConfig) withDefaults() CrystallizeConfig {
+	if c.TurnThreshold == 0 {
+		c.TurnThreshold = DefaultCrystallizeTurnThreshold
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = DefaultCrystallizeIdleTimeout
+	}
+	return c
+}
+
+// CrystallizeResult reports one crystallization of a conversation
+type CrystallizeResult struct {
+	ConversationID      string     `json:"conversation_id"`
+	SummaryUID          string     `json:"summary_uid,omitempty"`
+	Turns               int        `json:"turns"`                          // Turns crystallized by this run; 0 if none were pending
+	CrystallizedThrough *time.Time `json:"crystallized_through,omitempty"` // Timestamp of the last crystallized turn
+}
+
+// crystallizeWatermarkKey stores the timestamp of the last crystallized turn,
+// so a conversation reloaded after a restart isn't crystallized again
+func crystallizeWatermarkKey(conversationID string) string {
+	return fmt.Sprintf("conv_crystallized:%s", conversationID)
+}
+
+// pendingTurns returns the turns after the watermark
+func pendingTurns(turns []Turn, through time.Time) []Turn {
+	for i, turn := range turns {
+		if turn.Timestamp.After(through) {
+			return turns[i:]
+		}
+	}
+	return nil
+}
+
+// crystallizeTranscript renders turns the way the wisdom layer summarizes them
+func crystallizeTranscript(turns []Turn) string {
+	var b strings.Builder
+	for _, turn := range turns {
+		fmt.Fprintf(&b, "User: %s\nAI: %s\n", turn.UserQuery, turn.Response)
+	}
+	return b.String()
+}
+
+// canCrystallize reports whether crystallized memory can be ingested; it is
+// written through the in-process kernel
+func (a *Agent) canCrystallize() bool {
+	return a.mkClient != nil && a.mkClient.directKernel != nil && a.aiClient != nil
+}
+
+// loadWatermark restores a conversation's watermark from Redis when this
+// process hasn't crystallized it yet. Callers hold conv.mu.
+func (a *Agent) loadWatermark(ctx context.Context, conv *Conversation) {
+	if !conv.crystallizedThrough.IsZero() || a.RedisClient == nil {
+		return
+	}
+	raw, err := a.RedisClient.Get(ctx, crystallizeWatermarkKey(conv.ID)).Result()
+	if err != nil {
+		return
+	}
+	if through, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		conv.crystallizedThrough = through
+	}
+}
+
+// Crystallize summarizes the conversation's uncrystallized turns through the
+// AI service and ingests the summary and its entities into the user's
+// namespace, linked to the conversation. Turns are only ever crystallized once.
+func (a *Agent) Crystallize(ctx context.Context, conv *Conversation) (*CrystallizeResult, error) {
+	if !a.canCrystallize() {
+		return nil, fmt.Errorf("crystallization needs an in-process memory kernel")
+	}
+
+	conv.mu.Lock()
+	if conv.crystallizing {
+		conv.mu.Unlock()
+		return nil, ErrCrystallizeInProgress
+	}
+	a.loadWatermark(ctx, conv)
+	turns := append([]Turn(nil), pendingTurns(conv.Turns, conv.crystallizedThrough)...)
+	result := &CrystallizeResult{ConversationID: conv.ID}
+	if len(turns) == 0 {
+		if !conv.crystallizedThrough.IsZero() {
+			through := conv.crystallizedThrough
+			result.CrystallizedThrough = &through
+		}
+		conv.mu.Unlock()
+		return result, nil
+	}
+	conv.crystallizing = true
+	conv.crystallizeAttempt = time.Now()
+	userID := conv.UserID
+	conv.mu.Unlock()
+
+	summaryUID, err := a.crystallizeTurns(ctx, userID, conv.ID, turns)
+
+	through := turns[len(turns)-1].Timestamp
+	conv.mu.Lock()
+	conv.crystallizing = false
+	if err == nil {
+		conv.crystallizedThrough = through
+	}
+	conv.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if a.RedisClient != nil {
+		if err := a.RedisClient.Set(ctx, crystallizeWatermarkKey(conv.ID), through.Format(time.RFC3339Nano), 0).Err(); err != nil {
+			a.logger.Warn("Failed to persist crystallization watermark",
+				zap.String("conversation_id", conv.ID),
+				zap.Error(err))
+		}
+	}
+
+	a.logger.Info("Conversation crystallized",
+		zap.String("conversation_id", conv.ID),
+		zap.String("summary_uid", summaryUID),
+		zap.Int("turns", len(turns)))
+
+	result.SummaryUID = summaryUID
+	result.Turns = len(turns)
+	result.CrystallizedThrough = &through
+	return result, nil
+}
+
+// crystallizeTurns summarizes turns and ingests the result into the user's namespace
+func (a *Agent) crystallizeTurns(ctx context.Context, userID, conversationID string, turns []Turn) (string, error) {
+	batch, err := a.aiClient.SummarizeBatch(ctx, crystallizeTranscript(turns))
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+	summaryUID, err := a.mkClient.IngestConversationSummary(ctx, nsutil.ForUser(userID), userID, conversationID, batch)
+	if err != nil {
+		return "", fmt.Errorf("failed to ingest conversation summary: %w", err)
+	}
+	return summaryUID, nil
+}
+
+// crystallizeInBackground runs Crystallize detached from any request
+func (a *Agent) crystallizeInBackground(conv *Conversation) {
+	ctx, cancel := context.WithTimeout(a.ctx, crystallizeTimeout)
+	defer cancel()
+	if _, err := a.Crystallize(ctx, conv); err != nil && !errors.Is(err, ErrCrystallizeInProgress) {
+		a.logger.Warn("Failed to crystallize conversation",
+			zap.String("conversation_id", conv.ID),
+			zap.Error(err))
+	}
+}
+
+// maybeCrystallize starts a crystallization once a conversation has
+// accumulated enough uncrystallized turns
+func (a *Agent) maybeCrystallize(conv *Conversation) {
+	threshold := a.config.Crystallize.withDefaults().TurnThreshold
+	if threshold < 0 || !a.canCrystallize() {
+		return
+	}
+	conv.mu.Lock()
+	due := !conv.crystallizing && time.Since(conv.crystallizeAttempt) >= crystallizeRetryDelay &&
+		len(pendingTurns(conv.Turns, conv.crystallizedThrough)) >= threshold
+	conv.mu.Unlock()
+	if due {
+		go a.crystallizeInBackground(conv)
+	}
+}
+
+// runCrystallizer periodically crystallizes conversations that went idle with
+// uncrystallized turns, until the agent stops
+func (a *Agent) runCrystallizer() {
+	idle := a.config.Crystallize.withDefaults().IdleTimeout
+	if idle < 0 {
+		return
+	}
+	interval := crystallizeSweepInterval
+	if idle < interval {
+		interval = idle
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if a.canCrystallize() {
+				a.crystallizeIdle(idle)
+			}
+		}
+	}
+}
+
+// crystallizeIdle crystallizes, one at a time, every conversation idle for at
+// least idle with pending turns. A failed conversation is retried once it has
+// been another idle period since the attempt.
+func (a *Agent) crystallizeIdle(idle time.Duration) {
+	a.convMu.RLock()
+	conversations := make([]*Conversation, 0, len(a.conversations))
+	for _, conv := range a.conversations {
+		conversations = append(conversations, conv)
+	}
+	a.convMu.RUnlock()
+
+	cutoff := time.Now().Add(-idle)
+	for _, conv := range conversations {
+		if a.ctx.Err() != nil {
+			return
+		}
+		conv.mu.Lock()
+		pending := pendingTurns(conv.Turns, conv.crystallizedThrough)
+		due := len(pending) > 0 && !conv.crystallizing &&
+			pending[len(pending)-1].Timestamp.Before(cutoff) &&
+			conv.crystallizeAttempt.Before(cutoff)
+		conv.mu.Unlock()
+		if due {
+			a.crystallizeInBackground(conv)
+		}
+	}
+}
+
+// handleCrystallizeConversation crystallizes a conversation's pending turns now,
+// regardless of the turn and idle thresholds.
+// POST /api/conversations/{id}/crystallize
+func (s *Server) handleCrystallizeConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+	conversationID := mux.Vars(r)["id"]
+	if !s.agent.canCrystallize() {
+		writeJSONError(w, http.StatusServiceUnavailable, "Crystallization is not available", nil)
+		return
+	}
+
+	conv, err := s.agent.LoadConversation(ctx, conversationID)
+	if err != nil {
+		s.logger.Error("Failed to load conversation", zap.String("conversation_id", conversationID), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to load conversation", nil)
+		return
+	}
+	if conv == nil || conv.UserID != userID {
+		writeJSONError(w, http.StatusNotFound, "Conversation not found", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, crystallizeTimeout)
+	defer cancel()
+	result, err := s.agent.Crystallize(ctx, conv)
+	if errors.Is(err, ErrCrystallizeInProgress) {
+		writeJSONError(w, http.StatusConflict, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to crystallize conversation", zap.String("conversation_id", conversationID), zap.Error(err))
+		writeJSONError(w, http.StatusBadGateway, "Failed to crystallize conversation", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
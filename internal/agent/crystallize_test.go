@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// summaryKernel records crystallized summaries; other kernel methods are unused
+type summaryKernel struct {
+	MemoryKernel
+	namespaces []string
+	batches    []*graph.BatchSummary
+}
+
+func (k *summaryKernel) IngestConversationSummary(ctx context.Context, namespace, userID, conversationID string, batch *graph.BatchSummary) (string, error) {
+	k.namespaces = append(k.namespaces, namespace)
+	k.batches = append(k.batches, batch)
+	return "0x1", nil
+}
+
+func (k *summaryKernel) GetGraphClient() *graph.Client { return nil }
+
+func TestCrystallizeOnlyPendingTurns(t *testing.T) {
+	var transcripts []string
+	ai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Text string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		transcripts = append(transcripts, req.Text)
+		json.NewEncoder(w).Encode(graph.BatchSummary{Summary: "talked about pizza"})
+	}))
+	defer ai.Close()
+
+	logger := zap.NewNop()
+	a, _ := New(DefaultConfig(), logger)
+	kernel := &summaryKernel{}
+	a.mkClient = NewMKClient("", logger)
+	a.mkClient.SetDirectKernel(kernel)
+	a.aiClient = NewAIClient(ai.URL, logger)
+
+	start := time.Now()
+	conv := a.getOrCreateConversation("alice", "conv-1")
+	conv.Turns = []Turn{
+		{Timestamp: start, UserQuery: "I like pizza", Response: "Noted"},
+		{Timestamp: start.Add(time.Second), UserQuery: "Margherita", Response: "Classic"},
+	}
+
+	ctx := context.Background()
+	result, err := a.Crystallize(ctx, conv)
+	if err != nil {
+		t.Fatalf("Crystallize: %v", err)
+	}
+	if result.Turns != 2 || result.SummaryUID != "0x1" || !result.CrystallizedThrough.Equal(start.Add(time.Second)) {
+		t.Errorf("first run = %+v", result)
+	}
+	if len(kernel.namespaces) != 1 || kernel.namespaces[0] != "user_alice" {
+		t.Errorf("ingested into %v, want user_alice", kernel.namespaces)
+	}
+
+	// Nothing new: no summarization
+	if result, err := a.Crystallize(ctx, conv); err != nil || result.Turns != 0 {
+		t.Errorf("second run = %+v, %v; want no turns", result, err)
+	}
+
+	conv.Turns = append(conv.Turns, Turn{Timestamp: start.Add(2 * time.Second), UserQuery: "And pineapple?", Response: "Divisive"})
+	if result, err := a.Crystallize(ctx, conv); err != nil || result.Turns != 1 {
+		t.Errorf("third run = %+v, %v; want one turn", result, err)
+	}
+
+	if len(transcripts) != 2 || strings.Contains(transcripts[1], "pizza") || !strings.Contains(transcripts[1], "pineapple") {
+		t.Errorf("transcripts = %q, want the new turn alone in the second", transcripts)
+	}
+}
@@ -202,7 +202,7 @@ func (m *CSRFMiddleware) Middleware(next http.Handler) http.Handler {
 				// Allow same-origin requests (browsers don't send Origin for same-origin POST)
 				// But we should still require CSRF token
 			} else if !m.isValidOrigin(r, origin, referer) {
-				http.Error(w, "Invalid origin", http.StatusForbidden)
+				writeJSONError(w, http.StatusForbidden, "Invalid origin", nil)
 				return
 			}
 		}
@@ -217,7 +217,7 @@ func (m *CSRFMiddleware) Middleware(next http.Handler) http.Handler {
 			m.logger.Warn("CSRF: Invalid or missing token",
 				zap.String("method", r.Method),
 				zap.String("path", r.URL.Path))
-			http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+			writeJSONError(w, http.StatusForbidden, "Invalid CSRF token", nil)
 			return
 		}
 
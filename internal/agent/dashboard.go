@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"go.uber.org/zap"
 )
 
@@ -23,26 +27,41 @@ type DashboardStats struct {
 
 // GraphData represented in a format suitable for reagraph
 type GraphData struct {
-	Nodes []GraphNode `json:"nodes"`
-	Edges []GraphEdge `json:"edges"`
+	Nodes     []GraphNode `json:"nodes"`
+	Edges     []GraphEdge `json:"edges"`
+	FocusUID  string      `json:"focus_uid,omitempty"` // Set when the graph is a subgraph around one node
+	Truncated bool        `json:"truncated"`           // More nodes exist than the limit allowed
 }
 
 type GraphNode struct {
 	ID         string  `json:"id"`
 	Label      string  `json:"label,omitempty"`
 	Group      string  `json:"group,omitempty"` // Person, Skill, Location, Department
+	Type       string  `json:"type,omitempty"`
+	ColorKey   string  `json:"color_key,omitempty"` // Stable key for the frontend palette
+	Cluster    string  `json:"cluster,omitempty"`   // Community id when clusters are requested
 	Size       int     `json:"size,omitempty"`
-	Activation float64 `json:"activation,omitempty"` // For frontend sizing
+	Activation float64 `json:"activation,omitempty"`  // For frontend sizing
 	SourceText string  `json:"source_text,omitempty"` // Original quote from user
 }
 
 type GraphEdge struct {
-	ID     string `json:"id"`
-	Source string `json:"source"`
-	Target string `json:"target"`
-	Label  string `json:"label,omitempty"`
+	ID        string  `json:"id"`
+	Source    string  `json:"source"`
+	Target    string  `json:"target"`
+	Label     string  `json:"label,omitempty"`
+	Predicate string  `json:"predicate,omitempty"`
+	Weight    float64 `json:"weight,omitempty"` // For edge thickness
 }
 
+const (
+	// defaultGraphLimit / maxGraphLimit bound the nodes returned to keep the graph renderable
+	defaultGraphLimit = 50
+	maxGraphLimit     = 500
+	// maxGraphDepth bounds focus_uid expansion
+	maxGraphDepth = 3
+)
+
 // IngestionStats represents the status of the ingestion pipeline
 type IngestionStats struct {
 	TotalProcessed int64   `json:"total_processed"`
@@ -127,13 +146,32 @@ func (s *Server) GetDashboardStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(dStats)
 }
 
-// GetVisualGraph returns the node/edge structure for the frontend graph
+// GetVisualGraph returns the node/edge structure for the frontend graph.
+// Query parameters:
+//   - focus_uid: return the subgraph around this node instead of the overview
+//   - depth: hops to expand from focus_uid (default 1, max 3)
+//   - limit: maximum nodes (default 50, max 500)
+//   - clusters=true: annotate nodes with their community id
 func (s *Server) GetVisualGraph(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	nodes := []GraphNode{}
-	edges := []GraphEdge{}
+	query := r.URL.Query()
+	limit := defaultGraphLimit
+	if v, err := strconv.Atoi(query.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if limit > maxGraphLimit {
+		limit = maxGraphLimit
+	}
+	depth := 1
+	if v, err := strconv.Atoi(query.Get("depth")); err == nil && v > 0 {
+		depth = v
+	}
+	if depth > maxGraphDepth {
+		depth = maxGraphDepth
+	}
+	withClusters := query.Get("clusters") == "true"
 
 	// Get the logged-in user from JWT context
 	userID := GetUserID(r.Context())
@@ -142,52 +180,77 @@ func (s *Server) GetVisualGraph(w http.ResponseWriter, r *http.Request) {
 		zap.Bool("userID_empty", userID == ""))
 
 	// PRIMARY: Always fetch nodes from user's namespace
-	namespace := fmt.Sprintf("user_%s", userID)
+	namespace := nsutil.ForUser(userID)
 	if userID == "" {
 		namespace = "user_test" // Fallback for testing
 	}
 
-	s.logger.Info("Fetching sample nodes for graph",
-		zap.String("namespace", namespace))
+	var data GraphData
+	if focusUID := query.Get("focus_uid"); focusUID != "" {
+		res, err := s.agent.mkClient.ExpandFromNode(ctx, graph.ExpandOpts{
+			StartUID:   focusUID,
+			Namespace:  namespace, // Never expand into another tenant's graph
+			MaxHops:    depth,
+			MaxResults: limit - 1, // The focus node itself takes one slot
+			Timeout:    graph.DefaultExpandTimeout,
+		})
+		if err != nil {
+			s.logger.Debug("Focus expansion failed", zap.String("focus_uid", focusUID), zap.Error(err))
+			writeJSONError(w, http.StatusNotFound, "Node not found", nil)
+			return
+		}
+		data = focusGraph(res, limit, withClusters)
+	} else {
+		data = s.overviewGraph(ctx, namespace, userID, limit, withClusters)
+	}
 
-	sampleNodes, err := s.agent.mkClient.GetSampleNodes(ctx, namespace, 50)
-	s.logger.Info("GetSampleNodes result",
-		zap.Int("count", len(sampleNodes)),
-		zap.Error(err))
+	// FALLBACK: If still nothing, show placeholder
+	if len(data.Nodes) == 0 {
+		data.Nodes = []GraphNode{
+			{ID: "1", Label: userID, Group: "User", Type: "User", ColorKey: "user", Size: 20},
+		}
+	}
+	if data.Edges == nil {
+		data.Edges = []GraphEdge{}
+	}
 
-	if err == nil && len(sampleNodes) > 0 {
-		seen := make(map[string]bool)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
 
-		for i, n := range sampleNodes {
-			if seen[n.UID] {
-				continue
-			}
-			seen[n.UID] = true
+// focusGraph lays out an expansion result, hop by hop from the focus node
+func focusGraph(res *graph.ExpandResult, limit int, withClusters bool) GraphData {
+	data := GraphData{FocusUID: res.StartNode.UID}
 
-			group := "Entity"
-			if len(n.DType) > 0 {
-				group = n.DType[0]
-			}
-			nodes = append(nodes, GraphNode{
-				ID:         n.UID,
-				Label:      n.Name,
-				Group:      group,
-				Size:       int(10 + (n.Activation * 20)), // Scale size by activation (10-30)
-				Activation: n.Activation,                  // Pass to frontend for additional scaling
-				SourceText: n.SourceText,                  // Original quote from user
-			})
-
-			// Create edges between consecutive nodes for visualization
-			if i > 0 && i < len(sampleNodes) {
-				prevNode := sampleNodes[i-1]
-				edges = append(edges, GraphEdge{
-					ID:     fmt.Sprintf("e-%s-%s", prevNode.UID, n.UID),
-					Source: prevNode.UID,
-					Target: n.UID,
-					Label:  "related",
-				})
-			}
+	hops := make([]int, 0, len(res.ByHop))
+	for hop := range res.ByHop {
+		hops = append(hops, hop)
+	}
+	sort.Ints(hops)
+	for _, hop := range hops {
+		for _, n := range res.ByHop[hop] {
+			data.Nodes = append(data.Nodes, visualNode(n, withClusters))
+		}
+	}
+	data.Truncated = len(data.Nodes) >= limit || res.Truncated
+
+	for _, e := range res.Edges {
+		data.Edges = append(data.Edges, visualEdge(e))
+	}
+	return data
+}
+
+// overviewGraph returns the most active nodes of the namespace anchored on the
+// user node, connected by the real edges between them
+func (s *Server) overviewGraph(ctx context.Context, namespace, userID string, limit int, withClusters bool) GraphData {
+	var data GraphData
+	seen := make(map[string]bool)
+	add := func(n GraphNode) {
+		if seen[n.ID] || len(data.Nodes) >= limit {
+			return
 		}
+		seen[n.ID] = true
+		data.Nodes = append(data.Nodes, n)
 	}
 
 	// CRITICAL: Always ensure the User node is present as the "Anchor"
@@ -202,75 +265,89 @@ func (s *Server) GetVisualGraph(w http.ResponseWriter, r *http.Request) {
 		// Try finding as generic entity if User type fails
 		seedNode, err = s.agent.mkClient.FindNodeByName(ctx, namespace, seedName, graph.NodeTypeEntity)
 	}
+	if err != nil {
+		seedNode = nil
+	}
+	if seedNode != nil {
+		anchor := visualNode(*seedNode, withClusters)
+		anchor.Group, anchor.Type, anchor.ColorKey = "User", "User", "user"
+		add(anchor)
+	}
 
-	if err == nil && seedNode != nil {
-		// Only add if not already present in sample nodes
-		alreadyExists := false
-		for _, n := range nodes {
-			if n.ID == seedNode.UID {
-				alreadyExists = true
-				break
-			}
-		}
+	s.logger.Info("Fetching sample nodes for graph",
+		zap.String("namespace", namespace))
 
-		if !alreadyExists {
-			nodes = append(nodes, GraphNode{
-				ID:    seedNode.UID,
-				Label: seedNode.Name,
-				Group: "User",
-				Size:  15,
-			})
-		}
+	sampleNodes, err := s.agent.mkClient.GetSampleNodes(ctx, namespace, limit)
+	s.logger.Info("GetSampleNodes result",
+		zap.Int("count", len(sampleNodes)),
+		zap.Error(err))
+	for _, n := range sampleNodes {
+		add(visualNode(n, withClusters))
+	}
+	// A full page means the namespace likely holds more than we show
+	data.Truncated = len(sampleNodes) >= limit
 
-		// Try expansion from the User Node to show direct connections
-		// This ensures that even if sample nodes are disjoint, we see the user's immediate context
-		expandOpts := graph.ExpandOpts{
+	// The user's direct connections, so even a disjoint sample shows their context
+	if seedNode != nil && len(data.Nodes) < limit {
+		res, err := s.agent.mkClient.ExpandFromNode(ctx, graph.ExpandOpts{
 			StartUID:   seedNode.UID,
+			Namespace:  namespace,
 			MaxHops:    1, // Keep it tight for the dashboard
-			MaxResults: 20,
-		}
-		res, err := s.agent.mkClient.ExpandFromNode(ctx, expandOpts)
+			MaxResults: limit - len(data.Nodes),
+		})
 		if err == nil && res != nil {
-			for _, levelNodes := range res.ByHop {
-				for _, n := range levelNodes {
-					// Add related nodes if not present
-					exists := false
-					for _, existing := range nodes {
-						if existing.ID == n.UID {
-							exists = true
-							break
-						}
-					}
-					if !exists {
-						nodes = append(nodes, GraphNode{
-							ID:    n.UID,
-							Label: n.Name,
-							Group: "Entity",
-							Size:  10,
-						})
-					}
-
-					// Add edge from User to this node
-					edges = append(edges, GraphEdge{
-						ID:     fmt.Sprintf("%s-%s", seedNode.UID, n.UID),
-						Source: seedNode.UID,
-						Target: n.UID,
-						Label:  "related",
-					})
-				}
+			for _, n := range res.ByHop[1] {
+				add(visualNode(n, withClusters))
 			}
 		}
 	}
 
-	// FALLBACK: If still nothing, show placeholder
-	if len(nodes) == 0 {
-		nodes = []GraphNode{
-			{ID: "1", Label: userID, Group: "User", Size: 20},
-		}
+	uids := make([]string, 0, len(data.Nodes))
+	for _, n := range data.Nodes {
+		uids = append(uids, n.ID)
+	}
+	edges, err := s.agent.mkClient.GetEdgesBetween(ctx, uids)
+	if err != nil {
+		s.logger.Warn("Failed to load graph edges", zap.Error(err))
+	}
+	for _, e := range edges {
+		data.Edges = append(data.Edges, visualEdge(e))
+	}
+	return data
+}
+
+// visualNode converts a graph node for the frontend, sized by activation
+func visualNode(n graph.Node, withClusters bool) GraphNode {
+	nodeType := string(graph.NodeTypeEntity)
+	if t := n.GetType(); t != "" {
+		nodeType = string(t)
+	}
+	node := GraphNode{
+		ID:         n.UID,
+		Label:      n.Name,
+		Group:      nodeType,
+		Type:       nodeType,
+		ColorKey:   strings.ToLower(nodeType),
+		Size:       int(10 + (n.Activation * 20)), // Scale size by activation (10-30)
+		Activation: n.Activation,                  // Pass to frontend for additional scaling
+		SourceText: n.SourceText,                  // Original quote from user
+	}
+	if withClusters {
+		node.Cluster = graph.CommunityOf(&n)
 	}
+	return node
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(GraphData{Nodes: nodes, Edges: edges})
+// visualEdge converts a traversed edge for the frontend
+func visualEdge(e graph.ExpandEdge) GraphEdge {
+	return GraphEdge{
+		ID:        fmt.Sprintf("%s-%s-%s", e.FromUID, e.Predicate, e.ToUID),
+		Source:    e.FromUID,
+		Target:    e.ToUID,
+		Label:     e.Predicate,
+		Predicate: e.Predicate,
+		Weight:    e.Weight,
+	}
 }
 
 // GetIngestionStats returns ingestion stats from Redis
@@ -281,7 +358,7 @@ func (s *Server) GetIngestionStats(w http.ResponseWriter, r *http.Request) {
 	val, err := s.agent.RedisClient.HGetAll(ctx, "ingestion:stats").Result()
 	if err != nil {
 		s.logger.Error("Failed to fetch ingestion stats", zap.Error(err))
-		http.Error(w, "Failed to fetch stats", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch stats", nil)
 		return
 	}
 
@@ -0,0 +1,61 @@
+// Package agent provides the JSON error envelope shared by all agent handlers
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorResponse is the JSON body returned for every failed agent request
+type ErrorResponse struct {
+	Error   string      `json:"error"`             // Human-readable message
+	Code    string      `json:"code"`              // Machine-readable code (e.g. "forbidden")
+	Details interface{} `json:"details,omitempty"` // Optional structured context
+}
+
+// errorCode maps an HTTP status to the machine-readable error code clients switch on
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusMethodNotAllowed:
+		return "method_not_allowed"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusRequestEntityTooLarge:
+		return "payload_too_large"
+	case http.StatusUnsupportedMediaType:
+		return "unsupported_media_type"
+	case http.StatusTooManyRequests:
+		return "rate_limited"
+	case http.StatusNotImplemented:
+		return "not_implemented"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	case http.StatusGatewayTimeout:
+		return "timeout"
+	}
+	if status >= 500 {
+		return "internal_error"
+	}
+	return "error"
+}
+
+// writeJSONError writes {"error":..., "code":..., "details":...} with the given status.
+// details may be nil.
+func writeJSONError(w http.ResponseWriter, status int, message string, details interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   message,
+		Code:    errorCode(status),
+		Details: details,
+	})
+}
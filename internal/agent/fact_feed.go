@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// defaultFactFeedLimit is how many facts a feed request reads when it
+	// gives no limit
+	defaultFactFeedLimit = 1000
+
+	// maxFactFeedLimit caps the facts one feed request reads
+	maxFactFeedLimit = 10000
+)
+
+// FactRecord is one fact in a /api/namespaces/{ns}/facts feed. Cursor resumes
+// the feed just after it.
+type FactRecord struct {
+	UID         string            `json:"uid"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Type        graph.NodeType    `json:"type"`
+	ContentType string            `json:"content_type,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Activation  float64           `json:"activation"`
+	AccessCount int64             `json:"access_count"`
+	Confidence  float64           `json:"confidence,omitempty"`
+	Pinned      bool              `json:"pinned,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	ValidFrom   *time.Time        `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time        `json:"valid_until,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Namespace   string            `json:"namespace"`
+	Cursor      string            `json:"cursor"`
+}
+
+// FactFeedEnd is the last line of a fact feed response. NextCursor is empty
+// once the namespace has no more facts.
+type FactFeedEnd struct {
+	End        bool   `json:"end"`
+	Count      int    `json:"count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// factLister fetches one page of a fact feed; graph.Client.ListFacts is the
+// usual one
+type factLister func(ctx context.Context, opts graph.ListFactsOpts) ([]graph.Node, error)
+
+// handleFactFeed streams a page of a namespace's facts as NDJSON, one
+// FactRecord per line in created_at order, ending with a FactFeedEnd line.
+// Facts the caller's policies deny are left out but still move the cursor.
+// GET /api/namespaces/{ns}/facts?cursor=&limit=
+func (s *Server) handleFactFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+	namespace := mux.Vars(r)["ns"]
+
+	if !s.canAccessNamespace(ctx, namespace, userID) {
+		writeJSONError(w, http.StatusForbidden, "Access denied", nil)
+		return
+	}
+
+	after, err := graph.ParseFactCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid cursor", nil)
+		return
+	}
+	limit := defaultFactFeedLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit <= 0 || limit > maxFactFeedLimit {
+			writeJSONError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxFactFeedLimit), nil)
+			return
+		}
+	}
+
+	graphClient := s.agent.mkClient.GetGraphClient()
+	if graphClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Fact feed is not available", nil)
+		return
+	}
+
+	allow := func(facts []graph.Node) []graph.Node {
+		return s.agent.filterFactsByPolicy(ctx, userID, namespace, facts)
+	}
+	if err := writeFactFeed(ctx, w, graphClient.ListFacts, graph.ListFactsOpts{Namespace: namespace, After: after}, limit, allow); err != nil {
+		s.logger.Warn("Fact feed incomplete",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+	}
+}
+
+// writeFactFeed reads up to limit facts from list, a graph page at a time,
+// and writes those allow keeps as NDJSON followed by a FactFeedEnd line. It
+// returns the error that cut the feed short, if any; the client sees a
+// generic error in the end line, whose cursor resumes after the last fact
+// read.
+func writeFactFeed(ctx context.Context, w http.ResponseWriter, list factLister, opts graph.ListFactsOpts, limit int, allow func([]graph.Node) []graph.Node) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	end := FactFeedEnd{End: true}
+	finish := func(err error) error {
+		if err != nil {
+			end.Error = errListingIncomplete.Error()
+		}
+		enc.Encode(end)
+		return err
+	}
+
+	read := 0
+	for read < limit {
+		opts.Limit = min(limit-read, graph.DefaultFactPageSize)
+		page, err := list(ctx, opts)
+		if err != nil {
+			end.NextCursor = opts.After.String()
+			return finish(err)
+		}
+		if len(page) == 0 {
+			// The feed is exhausted; no cursor to resume from
+			return finish(nil)
+		}
+		read += len(page)
+		opts.After = graph.FactCursorOf(page[len(page)-1])
+
+		for _, node := range allow(page) {
+			if err := enc.Encode(factRecord(node)); err != nil {
+				// The client went away
+				return err
+			}
+			end.Count++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	end.NextCursor = opts.After.String()
+	return finish(nil)
+}
+
+// factRecord converts a feed node for the wire
+func factRecord(node graph.Node) FactRecord {
+	return FactRecord{
+		UID:         node.UID,
+		Name:        node.Name,
+		Description: node.Description,
+		Type:        node.GetType(),
+		ContentType: node.ContentType,
+		Tags:        node.Tags,
+		Attributes:  node.Attributes,
+		Activation:  node.Activation,
+		AccessCount: node.AccessCount,
+		Confidence:  node.Confidence,
+		Pinned:      node.Pinned,
+		Status:      node.Status,
+		ValidFrom:   node.ValidFrom,
+		ValidUntil:  node.ValidUntil,
+		CreatedAt:   node.CreatedAt,
+		UpdatedAt:   node.UpdatedAt,
+		Namespace:   node.Namespace,
+		Cursor:      graph.FactCursorOf(node).String(),
+	}
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// sliceFacts lists nodes, already in feed order, as a fact feed; it fails
+// once failAfter nodes have been listed (0 = never)
+func sliceFacts(nodes []graph.Node, failAfter int) factLister {
+	listed := 0
+	return func(ctx context.Context, opts graph.ListFactsOpts) ([]graph.Node, error) {
+		if failAfter > 0 && listed >= failAfter {
+			return nil, errors.New("dgraph unavailable")
+		}
+		var page []graph.Node
+		for _, n := range nodes {
+			if len(page) < opts.Limit && (opts.After.IsZero() || n.CreatedAt.After(opts.After.CreatedAt)) {
+				page = append(page, n)
+			}
+		}
+		listed += len(page)
+		return page, nil
+	}
+}
+
+// readFactFeed splits an NDJSON feed into its records and end line
+func readFactFeed(t *testing.T, body *bufio.Scanner) ([]FactRecord, FactFeedEnd) {
+	t.Helper()
+	var records []FactRecord
+	for body.Scan() {
+		var end FactFeedEnd
+		if err := json.Unmarshal(body.Bytes(), &end); err == nil && end.End {
+			if body.Scan() {
+				t.Fatalf("line after the end line: %s", body.Text())
+			}
+			return records, end
+		}
+		var rec FactRecord
+		if err := json.Unmarshal(body.Bytes(), &rec); err != nil {
+			t.Fatalf("bad line %q: %v", body.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	t.Fatal("feed has no end line")
+	return nil, FactFeedEnd{}
+}
+
+func TestWriteFactFeedResumes(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var nodes []graph.Node
+	for i := 1; i <= 5; i++ {
+		nodes = append(nodes, graph.Node{
+			UID:        fmt.Sprintf("0x%d", i),
+			Name:       fmt.Sprintf("fact %d", i),
+			DType:      []string{"Fact"},
+			CreatedAt:  base.Add(time.Duration(i) * time.Minute),
+			Activation: 0.5,
+			Tags:       []string{"t"},
+			Attributes: map[string]string{"k": "v"},
+		})
+	}
+	// Policy denies fact 2
+	allow := func(facts []graph.Node) []graph.Node {
+		var kept []graph.Node
+		for _, f := range facts {
+			if f.UID != "0x2" {
+				kept = append(kept, f)
+			}
+		}
+		return kept
+	}
+
+	ctx := context.Background()
+	opts := graph.ListFactsOpts{Namespace: "user_a"}
+	rec := httptest.NewRecorder()
+	if err := writeFactFeed(ctx, rec, sliceFacts(nodes, 0), opts, 3, allow); err != nil {
+		t.Fatal(err)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	records, end := readFactFeed(t, bufio.NewScanner(rec.Body))
+	if len(records) != 2 || records[0].UID != "0x1" || records[1].UID != "0x3" || end.Count != 2 {
+		t.Fatalf("first page = %+v, end %+v; want facts 1 and 3", records, end)
+	}
+	if records[0].Attributes["k"] != "v" || records[0].Activation != 0.5 || len(records[0].Tags) != 1 {
+		t.Errorf("record lost activation, tags or attributes: %+v", records[0])
+	}
+	if end.NextCursor == "" || end.NextCursor != records[1].Cursor {
+		t.Fatalf("next cursor %q, want the last record's %q", end.NextCursor, records[1].Cursor)
+	}
+
+	opts.After, _ = graph.ParseFactCursor(end.NextCursor)
+	rec = httptest.NewRecorder()
+	if err := writeFactFeed(ctx, rec, sliceFacts(nodes, 0), opts, 3, allow); err != nil {
+		t.Fatal(err)
+	}
+	records, end = readFactFeed(t, bufio.NewScanner(rec.Body))
+	if len(records) != 2 || records[0].UID != "0x4" || records[1].UID != "0x5" {
+		t.Fatalf("second page = %+v, want facts 4 and 5", records)
+	}
+	if end.NextCursor != "" || end.Error != "" {
+		t.Errorf("end = %+v, want the feed finished", end)
+	}
+}
+
+func TestWriteFactFeedReportsFailure(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var nodes []graph.Node
+	for i := 1; i <= graph.DefaultFactPageSize+1; i++ {
+		nodes = append(nodes, graph.Node{UID: fmt.Sprintf("0x%x", i), CreatedAt: base.Add(time.Duration(i) * time.Second)})
+	}
+	keepAll := func(facts []graph.Node) []graph.Node { return facts }
+
+	rec := httptest.NewRecorder()
+	err := writeFactFeed(context.Background(), rec, sliceFacts(nodes, graph.DefaultFactPageSize), graph.ListFactsOpts{Namespace: "user_a"}, 1000, keepAll)
+	if err == nil {
+		t.Fatal("failure not returned")
+	}
+	records, end := readFactFeed(t, bufio.NewScanner(rec.Body))
+	if len(records) != graph.DefaultFactPageSize || end.Error == "" {
+		t.Fatalf("%d records, end %+v; want the first page and an error", len(records), end)
+	}
+	if end.NextCursor != records[len(records)-1].Cursor {
+		t.Errorf("next cursor %q does not resume after the last record", end.NextCursor)
+	}
+}
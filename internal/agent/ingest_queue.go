@@ -0,0 +1,95 @@
+// Package agent provides backpressure handling for the zero-copy ingest channel.
+package agent
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// DefaultIngestBufferSize is the default capacity of the agent->kernel ingest channel
+const DefaultIngestBufferSize = 1000
+
+// ingestDropLogInterval rate-limits drop-rate warnings under sustained overload
+const ingestDropLogInterval = 10 * time.Second
+
+// ingestStats counts what happened to transcripts offered to the ingest channel
+type ingestStats struct {
+	sent     atomic.Int64 // Delivered to the channel
+	overflow atomic.Int64 // Channel full; spilled to NATS instead
+	dropped  atomic.Int64 // Channel full with no spill path; oldest event evicted
+
+	lastDropLog      atomic.Int64 // Unix nanos of the last drop-rate warning
+	droppedAtLastLog atomic.Int64
+}
+
+// enqueueIngest offers an event to the ingest channel without ever blocking the
+// chat path. When the channel is full the event spills to NATS if connected
+// (returns false so the caller publishes it); otherwise the oldest queued event is
+// evicted so fresh transcripts win. Returns true when the event was handled here.
+func (a *Agent) enqueueIngest(event *graph.TranscriptEvent) bool {
+	select {
+	case a.ingestChan <- event:
+		a.ingestStats.sent.Add(1)
+		return true
+	default:
+	}
+
+	// Overloaded: prefer a lossless spill to JetStream when available
+	if a.natsConn != nil && a.natsConn.IsConnected() {
+		a.ingestStats.overflow.Add(1)
+		return false
+	}
+
+	// No spill path: drop oldest to make room for the newest transcript
+	select {
+	case <-a.ingestChan:
+		a.ingestStats.dropped.Add(1)
+	default:
+	}
+	select {
+	case a.ingestChan <- event:
+		a.ingestStats.sent.Add(1)
+	default:
+		// Lost a race with other producers; drop the new event instead
+		a.ingestStats.dropped.Add(1)
+	}
+	a.logIngestDropRate()
+	return true
+}
+
+// logIngestDropRate warns at most once per interval with the recent drop rate
+func (a *Agent) logIngestDropRate() {
+	now := time.Now().UnixNano()
+	last := a.ingestStats.lastDropLog.Load()
+	if now-last < int64(ingestDropLogInterval) || !a.ingestStats.lastDropLog.CompareAndSwap(last, now) {
+		return
+	}
+
+	dropped := a.ingestStats.dropped.Load()
+	since := dropped - a.ingestStats.droppedAtLastLog.Swap(dropped)
+	elapsed := time.Duration(now - last)
+	if last == 0 || elapsed > time.Minute {
+		elapsed = ingestDropLogInterval
+	}
+
+	a.logger.Warn("Ingest channel overloaded, shedding oldest transcripts",
+		zap.Int64("dropped_since_last_log", since),
+		zap.Float64("drops_per_second", float64(since)/elapsed.Seconds()),
+		zap.Int("depth", len(a.ingestChan)),
+		zap.Int("capacity", cap(a.ingestChan)))
+}
+
+// ingestChannelStats reports channel depth and delivery counters for GetStats
+func (a *Agent) ingestChannelStats() map[string]interface{} {
+	return map[string]interface{}{
+		"depth":    len(a.ingestChan),
+		"capacity": cap(a.ingestChan),
+		"sent":     a.ingestStats.sent.Load(),
+		"overflow": a.ingestStats.overflow.Load(),
+		"dropped":  a.ingestStats.dropped.Load(),
+	}
+}
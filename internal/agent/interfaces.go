@@ -20,7 +20,7 @@ type MemoryKernelClient interface {
 	ListGroups(ctx context.Context, userID string) ([]map[string]interface{}, error)
 	AddGroupMember(ctx context.Context, groupID, username string) error
 	RemoveGroupMember(ctx context.Context, groupID, username string) error
-	ShareToGroup(ctx context.Context, conversationID, groupID string) error
+	ShareToGroup(ctx context.Context, conversationID, groupID, sharedBy string) error
 	IsGroupAdmin(ctx context.Context, groupNamespace, userID string) (bool, error)
 
 	// graph traversal operations
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// jsonArrayStream writes a JSON object whose first field is an array, one
+// element at a time, so a large list never sits in memory as a whole:
+//
+//	{"<field>":[elem,elem,...],"count":N}
+//
+// The status is sent with the first byte, so a failure partway through is
+// reported in an "error" field after the count rather than as an HTTP error.
+type jsonArrayStream struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+	count   int
+}
+
+// jsonStreamFlushEvery is how many elements are written between flushes
+const jsonStreamFlushEvery = 500
+
+// newJSONArrayStream starts the response and opens the array
+func newJSONArrayStream(w http.ResponseWriter, field string) *jsonArrayStream {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	name, _ := json.Marshal(field)
+	fmt.Fprintf(w, "{%s:[", name)
+	flusher, _ := w.(http.Flusher)
+	return &jsonArrayStream{w: w, enc: json.NewEncoder(w), flusher: flusher}
+}
+
+// Write appends one element to the array
+func (s *jsonArrayStream) Write(elem interface{}) error {
+	if s.count > 0 {
+		if _, err := s.w.Write([]byte{','}); err != nil {
+			return err
+		}
+	}
+	if err := s.enc.Encode(elem); err != nil {
+		return err
+	}
+	s.count++
+	if s.flusher != nil && s.count%jsonStreamFlushEvery == 0 {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// Close ends the array and the object, adding "count" and, when err is set,
+// "error"
+func (s *jsonArrayStream) Close(err error) {
+	fmt.Fprintf(s.w, `],"count":%d`, s.count)
+	if err != nil {
+		msg, _ := json.Marshal(err.Error())
+		fmt.Fprintf(s.w, `,"error":%s`, msg)
+	}
+	s.w.Write([]byte("}\n"))
+}
@@ -118,7 +118,7 @@ func (m *JWTMiddleware) Middleware(next http.Handler) http.Handler {
 				return
 			}
 			// All other paths require authentication
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			writeJSONError(w, http.StatusUnauthorized, "Authentication required", nil)
 			return
 		}
 
@@ -135,14 +135,14 @@ func (m *JWTMiddleware) Middleware(next http.Handler) http.Handler {
 
 		if err != nil || !token.Valid {
 			m.logger.Warn("Invalid JWT token", zap.Error(err))
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			writeJSONError(w, http.StatusUnauthorized, "Invalid or expired token", nil)
 			return
 		}
 
 		// Extract user_id from claims
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			writeJSONError(w, http.StatusUnauthorized, "Invalid token claims", nil)
 			return
 		}
 
@@ -152,7 +152,7 @@ func (m *JWTMiddleware) Middleware(next http.Handler) http.Handler {
 			userID, _ = claims["user_id"].(string)
 		}
 		if userID == "" {
-			http.Error(w, "Token missing user identifier", http.StatusUnauthorized)
+			writeJSONError(w, http.StatusUnauthorized, "Token missing user identifier", nil)
 			return
 		}
 
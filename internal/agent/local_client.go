@@ -6,6 +6,7 @@ import (
 
 	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/kernel"
+	"github.com/reflective-memory-kernel/internal/vectorindex"
 )
 
 // LocalKernelClient implements MemoryKernelClient by wrapping a local Kernel instance.
@@ -67,8 +68,8 @@ func (c *LocalKernelClient) RemoveGroupMember(ctx context.Context, groupID, user
 	return c.k.RemoveGroupMember(ctx, groupID, username)
 }
 
-func (c *LocalKernelClient) ShareToGroup(ctx context.Context, conversationID, groupID string) error {
-	return c.k.ShareToGroup(ctx, conversationID, groupID)
+func (c *LocalKernelClient) ShareToGroup(ctx context.Context, conversationID, groupID, sharedBy string) error {
+	return c.k.ShareToGroup(ctx, conversationID, groupID, sharedBy)
 }
 
 func (c *LocalKernelClient) IsGroupAdmin(ctx context.Context, groupNamespace, userID string) (bool, error) {
@@ -104,11 +105,26 @@ func (c *LocalKernelClient) TriggerReflection(ctx context.Context) error {
 	return c.k.TriggerReflection(ctx)
 }
 
+// RetryDeadLetters retries dead-lettered ingestion events on the kernel
+func (c *LocalKernelClient) RetryDeadLetters(ctx context.Context) (int, int64, error) {
+	return c.k.RetryDeadLetters(ctx)
+}
+
+// ReindexPending retries failed vector upserts on the kernel
+func (c *LocalKernelClient) ReindexPending(ctx context.Context) (int, int64, error) {
+	return c.k.ReindexPending(ctx)
+}
+
 // GetSampleNodes returns sample nodes from the graph for visualization
 func (c *LocalKernelClient) GetSampleNodes(ctx context.Context, namespace string, limit int) ([]graph.Node, error) {
 	return c.k.GetGraphClient().GetSampleNodes(ctx, namespace, limit)
 }
 
+// GetEdgesBetween returns the edges connecting the given nodes
+func (c *LocalKernelClient) GetEdgesBetween(ctx context.Context, uids []string) ([]graph.ExpandEdge, error) {
+	return c.k.GetGraphClient().GetEdgesBetween(ctx, uids)
+}
+
 // GetGraphClient returns the underlying graph client
 func (c *LocalKernelClient) GetGraphClient() *graph.Client {
 	return c.k.GetGraphClient()
@@ -170,9 +186,9 @@ func (c *LocalKernelClient) RevokeShareLink(ctx context.Context, token, userID s
 	return c.k.RevokeShareLink(ctx, token, userID)
 }
 
-// GetWorkspaceMembers gets all members of a workspace
-func (c *LocalKernelClient) GetWorkspaceMembers(ctx context.Context, workspaceNS string) ([]graph.WorkspaceMember, error) {
-	return c.k.GetWorkspaceMembers(ctx, workspaceNS)
+// GetWorkspaceMembers gets a page of a workspace's members and the total count
+func (c *LocalKernelClient) GetWorkspaceMembers(ctx context.Context, workspaceNS string, offset, limit int) ([]graph.WorkspaceMember, int, error) {
+	return c.k.GetWorkspaceMembers(ctx, workspaceNS, offset, limit)
 }
 
 // IsWorkspaceMember checks if a user is a member of a workspace
@@ -189,11 +205,21 @@ func (c *LocalKernelClient) PersistEntities(ctx context.Context, namespace, user
 	return c.k.PersistEntities(ctx, namespace, userID, conversationID, entities)
 }
 
+// IngestConversationSummary crystallizes a summarized conversation into a namespace
+func (c *LocalKernelClient) IngestConversationSummary(ctx context.Context, namespace, userID, conversationID string, batch *graph.BatchSummary) (string, error) {
+	return c.k.IngestConversationSummary(ctx, namespace, userID, conversationID, batch)
+}
+
 // PersistChunks persists document chunks to Qdrant
 func (c *LocalKernelClient) PersistChunks(ctx context.Context, namespace, docID string, chunks []graph.DocumentChunk) error {
 	return c.k.PersistChunks(ctx, namespace, docID, chunks)
 }
 
+// PersistVectorTree stores a document's vector tree in Qdrant
+func (c *LocalKernelClient) PersistVectorTree(ctx context.Context, namespace string, tree map[string]*vectorindex.VectorNode) (int, error) {
+	return c.k.PersistVectorTree(ctx, namespace, tree)
+}
+
 // ============================================================================
 // Search Methods
 // ============================================================================
@@ -203,6 +229,36 @@ func (c *LocalKernelClient) SearchNodes(ctx context.Context, namespace, query st
 	return c.k.GetGraphClient().SearchNodes(ctx, query, namespace)
 }
 
+// PruneNamespace archives or deletes low-value nodes in a namespace
+func (c *LocalKernelClient) PruneNamespace(ctx context.Context, namespace string, opts kernel.PruneOpts) (*kernel.PruneResult, error) {
+	return c.k.PruneNamespace(ctx, namespace, opts)
+}
+
+// Forget deletes a node and purges it from the vector index and caches
+func (c *LocalKernelClient) Forget(ctx context.Context, namespace, uid string) (*kernel.ForgetResult, error) {
+	return c.k.Forget(ctx, namespace, uid)
+}
+
+// RecentChanges returns what the memory learned in a namespace since a point in time
+func (c *LocalKernelClient) RecentChanges(ctx context.Context, namespace string, since time.Time) (*kernel.ChangeSummary, error) {
+	return c.k.RecentChanges(ctx, namespace, since)
+}
+
+// GetActivationConfig returns the activation parameters in effect for a namespace
+func (c *LocalKernelClient) GetActivationConfig(ctx context.Context, namespace string) graph.ActivationConfig {
+	return c.k.GetActivationConfig(ctx, namespace)
+}
+
+// SetActivationConfig overrides a namespace's activation parameters
+func (c *LocalKernelClient) SetActivationConfig(ctx context.Context, namespace string, config graph.ActivationConfig) error {
+	return c.k.SetActivationConfig(ctx, namespace, config)
+}
+
+// FindSimilar returns the nodes most similar to uid by vector similarity
+func (c *LocalKernelClient) FindSimilar(ctx context.Context, namespace, uid string, topK int) ([]graph.SimilarNode, error) {
+	return c.k.FindSimilar(ctx, namespace, uid, topK)
+}
+
 // ListUserGroups lists groups the user is a member of
 func (c *LocalKernelClient) ListUserGroups(ctx context.Context, userID string) ([]graph.Group, error) {
 	return c.k.ListUserGroups(ctx, userID)
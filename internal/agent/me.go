@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+	"github.com/reflective-memory-kernel/internal/policy"
+)
+
+// Me is the current user's context, returned by GET /api/me so a client can
+// load it in one round trip instead of one per part
+type Me struct {
+	Profile     MeProfile                   `json:"profile"`
+	Workspaces  []MeWorkspace               `json:"workspaces"`
+	Invitations []graph.WorkspaceInvitation `json:"invitations"`
+	Quota       *MeQuota                    `json:"quota,omitempty"`
+
+	// Unavailable names the parts that failed to load ("workspaces",
+	// "invitations"); they are left empty rather than failing the request
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// MeProfile is the account behind the token, with the profile settings the
+// user saved through the user_profile_update tool
+type MeProfile struct {
+	Username    string                 `json:"username"`
+	Role        string                 `json:"role"`
+	Namespace   string                 `json:"namespace"`
+	CreatedAt   string                 `json:"created_at,omitempty"`
+	DisplayName string                 `json:"display_name,omitempty"`
+	Bio         string                 `json:"bio,omitempty"`
+	Preferences map[string]interface{} `json:"preferences,omitempty"`
+}
+
+// MeWorkspace is a workspace the user belongs to
+type MeWorkspace struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Role        string `json:"role"` // "admin" or "member"
+	MemberCount int    `json:"member_count"`
+}
+
+// MeQuota is the user's request allowance per rate limit window
+type MeQuota struct {
+	Tier    policy.RateLimitTier   `json:"tier"`
+	Windows map[string]QuotaWindow `json:"windows"` // "minute", "hour", "day"
+}
+
+// QuotaWindow is the usage of one rate limit window
+type QuotaWindow struct {
+	Limit     int       `json:"limit"`
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// meSource is the part of the kernel client GET /api/me reads
+type meSource interface {
+	ListGroups(ctx context.Context, userID string) ([]map[string]interface{}, error)
+	GetPendingInvitations(ctx context.Context, userID string) ([]graph.WorkspaceInvitation, error)
+}
+
+// handleMe returns the caller's profile, workspaces, pending invitations and
+// quota; /api/stats, /api/groups and /api/invitations remain for each part.
+// GET /api/me
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+
+	me := assembleMe(ctx, userID, s.agent.mkClient, s.logger)
+	me.Profile = s.userProfile(ctx, userID)
+	me.Quota = s.userQuota(ctx, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(me)
+}
+
+// assembleMe gathers the user's workspaces and invitations, recording the
+// parts that could not be loaded
+func assembleMe(ctx context.Context, userID string, src meSource, logger *zap.Logger) *Me {
+	me := &Me{Workspaces: []MeWorkspace{}, Invitations: []graph.WorkspaceInvitation{}}
+
+	groups, err := src.ListGroups(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to list workspaces for /api/me", zap.String("user", userID), zap.Error(err))
+		me.Unavailable = append(me.Unavailable, "workspaces")
+	}
+	for _, g := range groups {
+		ws := MeWorkspace{
+			Namespace:   stringField(g, "namespace"),
+			Name:        stringField(g, "name"),
+			Description: stringField(g, "description"),
+			Role:        "member",
+			MemberCount: len(userNames(g["group_has_member"])),
+		}
+		for _, admin := range userNames(g["group_has_admin"]) {
+			if admin == userID {
+				ws.Role = "admin"
+			}
+		}
+		me.Workspaces = append(me.Workspaces, ws)
+	}
+	sort.Slice(me.Workspaces, func(i, j int) bool { return me.Workspaces[i].Name < me.Workspaces[j].Name })
+
+	invitations, err := src.GetPendingInvitations(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to list invitations for /api/me", zap.String("user", userID), zap.Error(err))
+		me.Unavailable = append(me.Unavailable, "invitations")
+	}
+	if invitations != nil {
+		me.Invitations = invitations
+	}
+	return me
+}
+
+// userProfile reads the account's role, creation time and profile settings
+// from Redis
+func (s *Server) userProfile(ctx context.Context, userID string) MeProfile {
+	profile := MeProfile{Username: userID, Role: "user", Namespace: nsutil.ForUser(userID)}
+	if s.agent.RedisClient == nil {
+		return profile
+	}
+	if role, err := s.agent.RedisClient.Get(ctx, "user_role:"+userID).Result(); err == nil && role != "" {
+		profile.Role = role
+	}
+	if created, err := s.agent.RedisClient.Get(ctx, "user_created:"+userID).Result(); err == nil {
+		profile.CreatedAt = created
+	}
+	if settings, err := s.agent.RedisClient.Get(ctx, "user_profile:"+userID).Result(); err == nil {
+		if err := applyProfileSettings(&profile, settings); err != nil {
+			s.logger.Warn("Ignoring unreadable profile settings", zap.String("user", userID), zap.Error(err))
+		}
+	}
+	return profile
+}
+
+// applyProfileSettings copies the display name, bio and preferences of a
+// stored user_profile JSON object into profile
+func applyProfileSettings(profile *MeProfile, data string) error {
+	var settings struct {
+		DisplayName string                 `json:"display_name"`
+		Bio         string                 `json:"bio"`
+		Preferences map[string]interface{} `json:"preferences"`
+	}
+	if err := json.Unmarshal([]byte(data), &settings); err != nil {
+		return err
+	}
+	profile.DisplayName = settings.DisplayName
+	profile.Bio = settings.Bio
+	profile.Preferences = settings.Preferences
+	return nil
+}
+
+// userQuota reports the user's rate limit usage, or nil when rate limiting is off
+func (s *Server) userQuota(ctx context.Context, userID string) *MeQuota {
+	if s.agent.PolicyManager == nil || s.agent.PolicyManager.RateLimiter == nil {
+		return nil
+	}
+	tier := s.rateLimitTier(userID)
+	status, err := s.agent.PolicyManager.RateLimiter.GetStatus(ctx, userID, tier)
+	if err != nil || status == nil {
+		return nil
+	}
+
+	quota := &MeQuota{Tier: tier, Windows: make(map[string]QuotaWindow, len(status))}
+	for window, result := range status {
+		if result == nil {
+			continue
+		}
+		quota.Windows[window] = QuotaWindow{
+			Limit:     result.Limit,
+			Used:      result.CurrentCount,
+			Remaining: result.Remaining,
+			ResetAt:   result.ResetAt,
+		}
+	}
+	return quota
+}
+
+// stringField returns m[key] if it is a string
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// userNames returns the names of a decoded list of user nodes
+func userNames(v interface{}) []string {
+	list, _ := v.([]interface{})
+	names := make([]string, 0, len(list))
+	for _, item := range list {
+		if node, ok := item.(map[string]interface{}); ok {
+			names = append(names, stringField(node, "name"))
+		}
+	}
+	return names
+}
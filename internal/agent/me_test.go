@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+// fakeMeSource serves decoded groups as the kernel client returns them
+type fakeMeSource struct {
+	groups         []map[string]interface{}
+	invitationsErr error
+}
+
+func (f *fakeMeSource) ListGroups(ctx context.Context, userID string) ([]map[string]interface{}, error) {
+	return f.groups, nil
+}
+
+func (f *fakeMeSource) GetPendingInvitations(ctx context.Context, userID string) ([]graph.WorkspaceInvitation, error) {
+	if f.invitationsErr != nil {
+		return nil, f.invitationsErr
+	}
+	return []graph.WorkspaceInvitation{{WorkspaceID: "group_ops", Role: "subuser", CreatedBy: "carol"}}, nil
+}
+
+func TestAssembleMe(t *testing.T) {
+	users := func(names ...string) []interface{} {
+		var out []interface{}
+		for _, n := range names {
+			out = append(out, map[string]interface{}{"name": n})
+		}
+		return out
+	}
+	src := &fakeMeSource{groups: []map[string]interface{}{
+		{"namespace": "group_team", "name": "Team", "group_has_member": users("alice", "bob", "carol"), "group_has_admin": users("carol")},
+		{"namespace": "group_home", "name": "Home", "description": "Family", "group_has_member": users("alice"), "group_has_admin": users("alice")},
+	}}
+
+	me := assembleMe(context.Background(), "alice", src, zap.NewNop())
+	want := []MeWorkspace{
+		{Namespace: "group_home", Name: "Home", Description: "Family", Role: "admin", MemberCount: 1},
+		{Namespace: "group_team", Name: "Team", Role: "member", MemberCount: 3},
+	}
+	if !reflect.DeepEqual(me.Workspaces, want) {
+		t.Errorf("workspaces = %+v, want %+v", me.Workspaces, want)
+	}
+	if len(me.Invitations) != 1 || me.Invitations[0].WorkspaceID != "group_ops" || me.Unavailable != nil {
+		t.Errorf("invitations = %+v, unavailable = %v", me.Invitations, me.Unavailable)
+	}
+
+	src.invitationsErr = errors.New("dgraph unavailable")
+	me = assembleMe(context.Background(), "alice", src, zap.NewNop())
+	if len(me.Workspaces) != 2 || me.Invitations == nil || len(me.Invitations) != 0 ||
+		!reflect.DeepEqual(me.Unavailable, []string{"invitations"}) {
+		t.Errorf("with invitations failing: %+v", me)
+	}
+}
+
+func TestUserProfileIncludesSettings(t *testing.T) {
+	ctx := context.Background()
+	client := fakeredis.New(t)
+	s := &Server{agent: &Agent{RedisClient: client}, logger: zap.NewNop()}
+
+	client.Set(ctx, "user_role:alice", "admin", 0)
+	client.Set(ctx, "user_profile:alice", `{"display_name": "Alice L.", "bio": "Runs ops", "preferences": {"theme": "dark"}, "version": 3}`, 0)
+	want := MeProfile{
+		Username: "alice", Role: "admin", Namespace: "user_alice",
+		DisplayName: "Alice L.", Bio: "Runs ops", Preferences: map[string]interface{}{"theme": "dark"},
+	}
+	if got := s.userProfile(ctx, "alice"); !reflect.DeepEqual(got, want) {
+		t.Errorf("profile = %+v, want %+v", got, want)
+	}
+
+	// A corrupt profile leaves the account fields intact
+	client.Set(ctx, "user_profile:bob", "not json", 0)
+	if got := s.userProfile(ctx, "bob"); got.Username != "bob" || got.Role != "user" || got.DisplayName != "" {
+		t.Errorf("profile with corrupt settings = %+v", got)
+	}
+}
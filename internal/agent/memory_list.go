@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+// MemoryItem is one node in a /api/memories listing
+type MemoryItem struct {
+	UID         string         `json:"uid"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Type        graph.NodeType `json:"type"`
+	Activation  float64        `json:"activation,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Namespace   string         `json:"namespace"`
+}
+
+// errListingIncomplete is reported when a streamed listing fails partway
+var errListingIncomplete = errors.New("listing failed partway; the results are incomplete")
+
+// canAccessNamespace reports whether userID may read and write namespace: its
+// own user namespace, or a workspace it is a member of
+func (s *Server) canAccessNamespace(ctx context.Context, namespace, userID string) bool {
+	if nsutil.IsGroup(namespace) {
+		isMember, err := s.agent.mkClient.IsWorkspaceMember(ctx, namespace, userID)
+		return err == nil && isMember
+	}
+	return namespace == nsutil.ForUser(userID)
+}
+
+// handleListMemories streams every node of a namespace, optionally of one
+// type, fetching them from the graph a page at a time.
+// GET /api/memories?namespace=&type=
+func (s *Server) handleListMemories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		namespace = nsutil.ForUser(userID)
+	}
+	if !s.canAccessNamespace(ctx, namespace, userID) {
+		writeJSONError(w, http.StatusForbidden, "Access denied", nil)
+		return
+	}
+
+	opts := graph.ListNodesOpts{Namespace: namespace}
+	if t := r.URL.Query().Get("type"); t != "" {
+		nodeType, err := graph.ParseNodeType(t)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		opts.NodeType = nodeType
+	}
+
+	graphClient := s.agent.mkClient.GetGraphClient()
+	if graphClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Memory listing is not available", nil)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	nodes, errc := graphClient.StreamNodes(ctx, opts)
+	if err := writeMemoryStream(w, nodes, errc); err != nil {
+		s.logger.Warn("Memory listing incomplete",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+	}
+}
+
+// writeMemoryStream writes nodes as a streamed {"memories":[...]} document.
+// It returns the error that cut the listing short, if any; the client sees a
+// generic "error" field in its place.
+func writeMemoryStream(w http.ResponseWriter, nodes <-chan graph.Node, errc <-chan error) error {
+	stream := newJSONArrayStream(w, "memories")
+	for node := range nodes {
+		if err := stream.Write(MemoryItem{
+			UID:         node.UID,
+			Name:        node.Name,
+			Description: node.Description,
+			Type:        node.GetType(),
+			Activation:  node.Activation,
+			Tags:        node.Tags,
+			Namespace:   node.Namespace,
+		}); err != nil {
+			// The client went away; the caller's cancel stops the producer
+			return err
+		}
+	}
+	if err := <-errc; err != nil {
+		stream.Close(errListingIncomplete)
+		return err
+	}
+	stream.Close(nil)
+	return nil
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func TestWriteMemoryStream(t *testing.T) {
+	for _, failure := range []error{nil, errors.New("dgraph unavailable")} {
+		nodes := make(chan graph.Node, 3)
+		errc := make(chan error, 1)
+		for i := 1; i <= 3; i++ {
+			nodes <- graph.Node{UID: fmt.Sprintf("0x%d", i), Name: "n", DType: []string{"Fact"}}
+		}
+		close(nodes)
+		errc <- failure
+		close(errc)
+
+		rec := httptest.NewRecorder()
+		if err := writeMemoryStream(rec, nodes, errc); err != failure {
+			t.Errorf("writeMemoryStream = %v, want %v", err, failure)
+		}
+
+		var body struct {
+			Memories []MemoryItem `json:"memories"`
+			Count    int          `json:"count"`
+			Error    string       `json:"error"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("streamed response is not valid JSON: %v\n%s", err, rec.Body)
+		}
+		if len(body.Memories) != 3 || body.Count != 3 || body.Memories[2].Type != graph.NodeTypeFact {
+			t.Errorf("decoded %+v", body)
+		}
+		if (failure != nil) != (body.Error != "") || strings.Contains(body.Error, "dgraph") {
+			t.Errorf("error field = %q for failure %v", body.Error, failure)
+		}
+	}
+}
+
+// discardResponseWriter drops the body so benchmarks measure the handler alone
+type discardResponseWriter struct{ header http.Header }
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+// fakeNamespace pages through n synthetic nodes
+func fakeNamespace(n int) graph.NodeLister {
+	description := strings.Repeat("remembered detail ", 12)
+	return func(ctx context.Context, opts graph.ListNodesOpts) ([]graph.Node, error) {
+		start := 1
+		if opts.After != "" {
+			fmt.Sscanf(opts.After, "0x%x", &start)
+			start++
+		}
+		page := make([]graph.Node, 0, min(opts.Limit, n))
+		for i := start; i <= n && len(page) < opts.Limit; i++ {
+			page = append(page, graph.Node{
+				UID: fmt.Sprintf("0x%x", i), Name: fmt.Sprintf("memory %d", i),
+				Description: description, DType: []string{"Fact"}, Namespace: "user_bench",
+			})
+		}
+		return page, nil
+	}
+}
+
+// peakHeap samples live heap bytes until stop is called, returning the peak
+func peakHeap() (stop func() uint64) {
+	runtime.GC()
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	var peak uint64
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			metrics.Read(sample)
+			peak = max(peak, sample[0].Value.Uint64())
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+	return func() uint64 {
+		close(done)
+		wg.Wait()
+		return peak
+	}
+}
+
+// BenchmarkMemoryList100k compares peak heap listing a 100k-node namespace by
+// buffering the whole result (the old memory_list approach) against streaming
+// it page by page:
+//
+//	go test ./internal/agent -run '^$' -bench MemoryList100k -benchtime 3x
+func BenchmarkMemoryList100k(b *testing.B) {
+	const n = 100_000
+	list := fakeNamespace(n)
+	ctx := context.Background()
+
+	b.Run("buffered", func(b *testing.B) {
+		var peak uint64
+		for i := 0; i < b.N; i++ {
+			stop := peakHeap()
+			nodes, _ := list(ctx, graph.ListNodesOpts{Limit: n})
+			items := make([]MemoryItem, 0, len(nodes))
+			for _, node := range nodes {
+				items = append(items, MemoryItem{UID: node.UID, Name: node.Name, Description: node.Description,
+					Type: node.GetType(), Namespace: node.Namespace})
+			}
+			json.NewEncoder(&discardResponseWriter{http.Header{}}).Encode(map[string]interface{}{"memories": items, "count": len(items)})
+			peak = max(peak, stop())
+		}
+		b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+	})
+
+	b.Run("streamed", func(b *testing.B) {
+		var peak uint64
+		for i := 0; i < b.N; i++ {
+			stop := peakHeap()
+			nodes, errc := graph.StreamNodePages(ctx, graph.ListNodesOpts{}, list)
+			writeMemoryStream(&discardResponseWriter{http.Header{}}, nodes, errc)
+			peak = max(peak, stop())
+		}
+		b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+	})
+}
@@ -0,0 +1,468 @@
+package agent
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+const (
+	// defaultMemoryShareTTL is how long a share lasts when no expiry is asked for
+	defaultMemoryShareTTL = 7 * 24 * time.Hour
+
+	// maxMemoryShareTTL is the longest a share may last
+	maxMemoryShareTTL = 90 * 24 * time.Hour
+
+	// maxMemoryShareUIDs caps the nodes a share may list
+	maxMemoryShareUIDs = 100
+
+	// maxSharedMemories caps the nodes a tag-scoped share returns
+	maxSharedMemories = 500
+)
+
+// errMemoryShareNotFound is returned for a share token that is malformed,
+// forged, expired or revoked; they all look the same to whoever presents it
+var errMemoryShareNotFound = errors.New("share not found or expired")
+
+// MemoryShare is a read-only view of chosen memories that anyone holding its
+// token can open without logging in. Unlike a workspace ShareLink it grants
+// no membership: its scope is the listed UIDs, or the nodes carrying Tag, in
+// the owner's namespace.
+type MemoryShare struct {
+	ID        string    `json:"id"`
+	OwnerID   string    `json:"owner_id"`
+	Namespace string    `json:"namespace"`
+	UIDs      []string  `json:"uids,omitempty"`
+	Tag       string    `json:"tag,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SharedMemory is one node as a share shows it
+type SharedMemory struct {
+	UID         string         `json:"uid"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Type        graph.NodeType `json:"type"`
+	Tags        []string       `json:"tags,omitempty"`
+	CreatedAt   time.Time      `json:"created_at,omitempty"`
+}
+
+// MemoryShareStore keeps shares in Redis, each expiring with its share, and
+// signs their tokens so forged ones are turned away without a lookup
+type MemoryShareStore struct {
+	redis *redis.Client
+	key   []byte
+}
+
+// NewMemoryShareStore creates a store signing tokens with a key derived from
+// secret
+func NewMemoryShareStore(redisClient *redis.Client, secret string) *MemoryShareStore {
+	return &MemoryShareStore{redis: redisClient, key: memoryShareKey(secret)}
+}
+
+// memoryShareKey derives the token signing key, keeping share tokens apart
+// from anything else signed with the same secret
+func memoryShareKey(secret string) []byte {
+	sum := sha256.Sum256([]byte("memory-share:" + secret))
+	return sum[:]
+}
+
+// signMemoryShareToken returns the token for share id expiring at expiresAt:
+// "<id>.<unix expiry>.<signature>"
+func signMemoryShareToken(key []byte, id string, expiresAt time.Time) string {
+	payload := id + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyMemoryShareToken returns the share ID a token names if its signature
+// holds and it has not expired
+func verifyMemoryShareToken(key []byte, token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errMemoryShareNotFound
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", errMemoryShareNotFound
+	}
+	want := signMemoryShareToken(key, parts[0], time.Unix(expiry, 0))
+	if !hmac.Equal([]byte(token), []byte(want)) {
+		return "", errMemoryShareNotFound
+	}
+	if !now.Before(time.Unix(expiry, 0)) {
+		return "", errMemoryShareNotFound
+	}
+	return parts[0], nil
+}
+
+// Create stores share and returns its token
+func (st *MemoryShareStore) Create(ctx context.Context, share *MemoryShare) (string, error) {
+	data, err := json.Marshal(share)
+	if err != nil {
+		return "", err
+	}
+	pipe := st.redis.TxPipeline()
+	pipe.Set(ctx, "memory_share:"+share.ID, data, time.Until(share.ExpiresAt))
+	pipe.SAdd(ctx, "memory_shares:user:"+share.OwnerID, share.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return signMemoryShareToken(st.key, share.ID, share.ExpiresAt), nil
+}
+
+func (st *MemoryShareStore) load(ctx context.Context, id string) (*MemoryShare, error) {
+	data, err := st.redis.Get(ctx, "memory_share:"+id).Bytes()
+	if err == redis.Nil {
+		return nil, errMemoryShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var share MemoryShare
+	if err := json.Unmarshal(data, &share); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// List returns ownerID's live shares, forgetting those that have expired
+func (st *MemoryShareStore) List(ctx context.Context, ownerID string) ([]MemoryShare, error) {
+	ids, err := st.redis.SMembers(ctx, "memory_shares:user:"+ownerID).Result()
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]MemoryShare, 0, len(ids))
+	for _, id := range ids {
+		share, err := st.load(ctx, id)
+		if err == errMemoryShareNotFound {
+			st.redis.SRem(ctx, "memory_shares:user:"+ownerID, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, *share)
+	}
+	slices.SortFunc(shares, func(a, b MemoryShare) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return shares, nil
+}
+
+// Revoke deletes a share owned by ownerID; its token stops working at once
+func (st *MemoryShareStore) Revoke(ctx context.Context, ownerID, id string) error {
+	share, err := st.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if share.OwnerID != ownerID {
+		return errMemoryShareNotFound
+	}
+	pipe := st.redis.TxPipeline()
+	pipe.Del(ctx, "memory_share:"+id)
+	pipe.SRem(ctx, "memory_shares:user:"+ownerID, id)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Redeem returns the share a token grants
+func (st *MemoryShareStore) Redeem(ctx context.Context, token string) (*MemoryShare, error) {
+	id, err := verifyMemoryShareToken(st.key, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return st.load(ctx, id)
+}
+
+// sharedNodeReader is the part of the graph client a share is read through
+type sharedNodeReader interface {
+	GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]graph.Node, error)
+	ListNodes(ctx context.Context, opts graph.ListNodesOpts) ([]graph.Node, error)
+}
+
+// sharedMemoryFields is what a share reads of each node
+var sharedMemoryFields = []string{"dgraph.type", "name", "description", "tags", "created_at", "namespace"}
+
+// sharedMemoryBatch is how many tagged nodes a share passes to its policy
+// filter at once
+const sharedMemoryBatch = 100
+
+// sharedMemories reads the nodes in share's scope that allow, the owner's
+// policy filter, keeps: a share never shows what its owner could not read.
+// Nodes no longer in the share's namespace or, for a tag share, no longer
+// tagged are left out.
+func sharedMemories(ctx context.Context, g sharedNodeReader, share *MemoryShare, allow func([]graph.Node) []graph.Node) ([]SharedMemory, error) {
+	memories := []SharedMemory{}
+	add := func(nodes []graph.Node) {
+		for _, node := range allow(nodes) {
+			if len(memories) == maxSharedMemories {
+				return
+			}
+			memories = append(memories, SharedMemory{
+				UID:         node.UID,
+				Name:        node.Name,
+				Description: node.Description,
+				Type:        node.GetType(),
+				Tags:        node.Tags,
+				CreatedAt:   node.CreatedAt,
+			})
+		}
+	}
+
+	if len(share.UIDs) > 0 {
+		nodes, err := g.GetNodesByUIDs(ctx, share.UIDs, sharedMemoryFields)
+		if err != nil {
+			return nil, err
+		}
+		inScope := nodes[:0]
+		for _, node := range nodes {
+			if node.Namespace == share.Namespace {
+				inScope = append(inScope, node)
+			}
+		}
+		add(inScope)
+		return memories, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	nodes, errc := graph.StreamNodePages(ctx, graph.ListNodesOpts{
+		Namespace: share.Namespace,
+		Fields:    sharedMemoryFields,
+	}, g.ListNodes)
+	var tagged []graph.Node
+	for node := range nodes {
+		if !slices.Contains(node.Tags, share.Tag) {
+			continue
+		}
+		if tagged = append(tagged, node); len(tagged) == sharedMemoryBatch {
+			if add(tagged); len(memories) == maxSharedMemories {
+				return memories, nil
+			}
+			tagged = tagged[:0]
+		}
+	}
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	add(tagged)
+	return memories, nil
+}
+
+// sharedNodeSource returns what shares are read through, or nil without a graph
+func (s *Server) sharedNodeSource() sharedNodeReader {
+	if s.shareNodes != nil {
+		return s.shareNodes
+	}
+	if graphClient := s.agent.GetGraphClient(); graphClient != nil {
+		return graphClient
+	}
+	return nil
+}
+
+// CreateMemoryShareRequest asks for a share of the listed UIDs or of the
+// nodes carrying Tag
+type CreateMemoryShareRequest struct {
+	Namespace      string   `json:"namespace"` // default: the caller's personal namespace
+	UIDs           []string `json:"uids,omitempty"`
+	Tag            string   `json:"tag,omitempty"`
+	ExpiresInHours int      `json:"expires_in_hours"` // default: 7 days
+}
+
+// MemoryShareResponse is a new share with its token
+type MemoryShareResponse struct {
+	MemoryShare
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// setupMemoryShareRoutes registers the share endpoints; redeeming a share is
+// public, managing shares needs a login
+func (s *Server) setupMemoryShareRoutes(api *mux.Router, protect func(http.HandlerFunc) http.Handler) {
+	api.Handle("/shares", protect(s.handleCreateMemoryShare)).Methods("POST")
+	api.Handle("/shares", protect(s.handleListMemoryShares)).Methods("GET")
+	api.Handle("/shares/{id}", protect(s.handleRevokeMemoryShare)).Methods("DELETE")
+	api.HandleFunc("/shared/{token}", s.handleRedeemMemoryShare).Methods("GET")
+}
+
+// handleCreateMemoryShare mints a read-only share of some of the caller's
+// memories
+// POST /api/shares
+func (s *Server) handleCreateMemoryShare(w http.ResponseWriter, r *http.Request) {
+	if s.shares == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Sharing not available", nil)
+		return
+	}
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+
+	var req CreateMemoryShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	req.Tag = strings.TrimSpace(req.Tag)
+	switch {
+	case len(req.UIDs) == 0 && req.Tag == "":
+		writeJSONError(w, http.StatusBadRequest, "uids or tag is required", nil)
+		return
+	case len(req.UIDs) > 0 && req.Tag != "":
+		writeJSONError(w, http.StatusBadRequest, "give uids or tag, not both", nil)
+		return
+	case len(req.UIDs) > maxMemoryShareUIDs:
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("at most %d uids per share", maxMemoryShareUIDs), nil)
+		return
+	}
+	ttl := defaultMemoryShareTTL
+	if req.ExpiresInHours < 0 || time.Duration(req.ExpiresInHours)*time.Hour > maxMemoryShareTTL {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("expires_in_hours must be between 1 and %d", int(maxMemoryShareTTL.Hours())), nil)
+		return
+	} else if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = nsutil.ForUser(userID)
+	}
+	if !s.canAccessNamespace(ctx, namespace, userID) {
+		writeJSONError(w, http.StatusForbidden, "Access denied", nil)
+		return
+	}
+
+	now := time.Now()
+	share := &MemoryShare{
+		ID:        uuid.New().String(),
+		OwnerID:   userID,
+		Namespace: namespace,
+		UIDs:      slices.Compact(slices.Sorted(slices.Values(req.UIDs))),
+		Tag:       req.Tag,
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+		CreatedAt: now,
+	}
+	token, err := s.shares.Create(ctx, share)
+	if err != nil {
+		s.logger.Error("Failed to create memory share", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to create share", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(MemoryShareResponse{
+		MemoryShare: *share,
+		Token:       token,
+		URL:         "/api/shared/" + token,
+	})
+}
+
+// handleListMemoryShares returns the caller's live shares, without tokens
+// GET /api/shares
+func (s *Server) handleListMemoryShares(w http.ResponseWriter, r *http.Request) {
+	if s.shares == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Sharing not available", nil)
+		return
+	}
+	shares, err := s.shares.List(r.Context(), GetUserID(r.Context()))
+	if err != nil {
+		s.logger.Error("Failed to list memory shares", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to list shares", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"shares": shares,
+		"count":  len(shares),
+	})
+}
+
+// handleRevokeMemoryShare revokes one of the caller's shares
+// DELETE /api/shares/{id}
+func (s *Server) handleRevokeMemoryShare(w http.ResponseWriter, r *http.Request) {
+	if s.shares == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Sharing not available", nil)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if err := s.shares.Revoke(r.Context(), GetUserID(r.Context()), id); err != nil {
+		if err == errMemoryShareNotFound {
+			writeJSONError(w, http.StatusNotFound, "Share not found", nil)
+			return
+		}
+		s.logger.Error("Failed to revoke memory share", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to revoke share", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"id":      id,
+	})
+}
+
+// handleRedeemMemoryShare shows the memories a share token grants. No login
+// is needed; the share stops working if its owner loses access to the
+// namespace.
+// GET /api/shared/{token}
+func (s *Server) handleRedeemMemoryShare(w http.ResponseWriter, r *http.Request) {
+	if s.shares == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Sharing not available", nil)
+		return
+	}
+	ctx := r.Context()
+
+	share, err := s.shares.Redeem(ctx, mux.Vars(r)["token"])
+	if err == nil && !s.canAccessNamespace(ctx, share.Namespace, share.OwnerID) {
+		err = errMemoryShareNotFound
+	}
+	if err != nil {
+		if err != errMemoryShareNotFound {
+			s.logger.Error("Failed to redeem memory share", zap.Error(err))
+		}
+		writeJSONError(w, http.StatusNotFound, errMemoryShareNotFound.Error(), nil)
+		return
+	}
+
+	reader := s.sharedNodeSource()
+	if reader == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Sharing not available", nil)
+		return
+	}
+	// The owner's policies still apply to what the share shows
+	memories, err := sharedMemories(ctx, reader, share, func(nodes []graph.Node) []graph.Node {
+		return s.agent.filterFactsByPolicy(ctx, share.OwnerID, share.Namespace, nodes)
+	})
+	if err != nil {
+		s.logger.Error("Failed to read shared memories", zap.String("share", share.ID), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read shared memories", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"memories":   memories,
+		"count":      len(memories),
+		"expires_at": share.ExpiresAt,
+	})
+}
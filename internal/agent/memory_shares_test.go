@@ -0,0 +1,197 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/policy"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+func TestMemoryShareTokens(t *testing.T) {
+	key := memoryShareKey("a-secret-of-at-least-thirty-two-chars")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	token := signMemoryShareToken(key, "share-1", now.Add(time.Hour))
+
+	if id, err := verifyMemoryShareToken(key, token, now); err != nil || id != "share-1" {
+		t.Fatalf("verify = %q, %v; want share-1", id, err)
+	}
+
+	parts := strings.Split(token, ".")
+	for name, bad := range map[string]string{
+		"expired":        token,
+		"other share":    "share-2." + parts[1] + "." + parts[2],
+		"later expiry":   parts[0] + ".9999999999." + parts[2],
+		"other key":      signMemoryShareToken(memoryShareKey("another-secret-of-thirty-two-chars"), "share-1", now.Add(time.Hour)),
+		"truncated":      parts[0] + "." + parts[1],
+		"garbage expiry": parts[0] + ".soon." + parts[2],
+	} {
+		at := now
+		if name == "expired" {
+			at = now.Add(time.Hour)
+		}
+		if _, err := verifyMemoryShareToken(key, bad, at); err != errMemoryShareNotFound {
+			t.Errorf("%s token: err = %v, want errMemoryShareNotFound", name, err)
+		}
+	}
+}
+
+func TestSharedMemoriesStayInScope(t *testing.T) {
+	ctx := context.Background()
+	store := graph.NewMemStore()
+	create := func(namespace, name string, tags ...string) string {
+		uid, err := store.CreateNode(ctx, &graph.Node{
+			Name: name, Namespace: namespace, DType: []string{"Fact"}, Tags: tags,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return uid
+	}
+	trip := create("user_alice", "Trip to Lisbon", "travel")
+	create("user_alice", "Bank PIN", "private")
+	create("user_alice", "Hotel booked", "travel")
+	foreign := create("user_bob", "Bob's trip", "travel")
+
+	names := func(memories []SharedMemory) string {
+		var out []string
+		for _, m := range memories {
+			out = append(out, m.Name)
+		}
+		return strings.Join(out, ", ")
+	}
+
+	// A UID share never reaches outside its namespace
+	allowAll := func(nodes []graph.Node) []graph.Node { return nodes }
+	got, err := sharedMemories(ctx, store, &MemoryShare{Namespace: "user_alice", UIDs: []string{trip, foreign}}, allowAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names(got) != "Trip to Lisbon" {
+		t.Errorf("UID share = %q, want only Alice's trip", names(got))
+	}
+
+	got, err = sharedMemories(ctx, store, &MemoryShare{Namespace: "user_alice", Tag: "travel"}, allowAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names(got) != "Trip to Lisbon, Hotel booked" {
+		t.Errorf("tag share = %q, want Alice's travel memories", names(got))
+	}
+}
+
+func TestMemoryShareRevoke(t *testing.T) {
+	ctx := context.Background()
+	shares := NewMemoryShareStore(fakeredis.New(t), "a-secret-of-at-least-thirty-two-chars")
+	share := &MemoryShare{ID: "share-1", OwnerID: "alice", Namespace: "user_alice", Tag: "travel",
+		CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	token, err := shares.Create(ctx, share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := shares.Redeem(ctx, token); err != nil || got.Tag != "travel" {
+		t.Fatalf("Redeem = %+v, %v; want the share", got, err)
+	}
+
+	// Only the owner can revoke a share
+	if err := shares.Revoke(ctx, "bob", "share-1"); err != errMemoryShareNotFound {
+		t.Errorf("Revoke by another user = %v, want errMemoryShareNotFound", err)
+	}
+	if _, err := shares.Redeem(ctx, token); err != nil {
+		t.Fatalf("share stopped working after a refused revoke: %v", err)
+	}
+
+	if err := shares.Revoke(ctx, "alice", "share-1"); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := shares.Redeem(ctx, token); err != errMemoryShareNotFound {
+		t.Errorf("Redeem after revoke = %v, want errMemoryShareNotFound", err)
+	}
+	if list, err := shares.List(ctx, "alice"); err != nil || len(list) != 0 {
+		t.Errorf("List after revoke = %v, %v; want none", list, err)
+	}
+	if err := shares.Revoke(ctx, "alice", "share-1"); err != errMemoryShareNotFound {
+		t.Errorf("second Revoke = %v, want errMemoryShareNotFound", err)
+	}
+}
+
+func TestRedeemMemoryShareAppliesOwnerPolicy(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	store := graph.NewMemStore()
+	var pinUID string
+	for _, name := range []string{"Trip to Lisbon", "Bank PIN", "Hotel booked"} {
+		uid, err := store.CreateNode(ctx, &graph.Node{
+			Name: name, Namespace: "user_alice", DType: []string{"Fact"}, Tags: []string{"travel"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "Bank PIN" {
+			pinUID = uid
+		}
+	}
+
+	pm := policy.NewPolicyManager(policy.PolicyManagerConfig{Enabled: true}, nil, nil, nil, logger)
+	pm.Engine.AddPolicy(policy.Policy{
+		ID:        "hide-pin",
+		Subjects:  []string{"user:alice"},
+		Resources: []string{"node:" + pinUID},
+		Actions:   []policy.Action{policy.ActionRead},
+		Effect:    policy.EffectDeny,
+	})
+	s := &Server{
+		agent:      &Agent{logger: logger, PolicyManager: pm},
+		logger:     logger,
+		shares:     NewMemoryShareStore(fakeredis.New(t), "a-secret-of-at-least-thirty-two-chars"),
+		shareNodes: store,
+	}
+	token, err := s.shares.Create(ctx, &MemoryShare{ID: "share-1", OwnerID: "alice", Namespace: "user_alice",
+		Tag: "travel", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redeem := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/shared/"+token, nil)
+		req = mux.SetURLVars(req, map[string]string{"token": token})
+		rec := httptest.NewRecorder()
+		s.handleRedeemMemoryShare(rec, req)
+		return rec
+	}
+
+	rec := redeem()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("redeem = %d %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Memories []SharedMemory `json:"memories"`
+		Count    int            `json:"count"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range body.Memories {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, ", "); got != "Trip to Lisbon, Hotel booked" || body.Count != 2 {
+		t.Errorf("redeemed %q (count %d), want the travel memories the owner's policy allows", got, body.Count)
+	}
+
+	if err := s.shares.Revoke(ctx, "alice", "share-1"); err != nil {
+		t.Fatal(err)
+	}
+	if rec := redeem(); rec.Code != http.StatusNotFound {
+		t.Errorf("redeem of a revoked share = %d, want 404", rec.Code)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/kernel"
+	"github.com/reflective-memory-kernel/internal/vectorindex"
 )
 
 // MemoryKernel defines the interface for direct (zero-copy) usage
@@ -24,7 +26,7 @@ type MemoryKernel interface {
 	AddGroupMember(ctx context.Context, groupID, username string) error
 	RemoveGroupMember(ctx context.Context, groupID, username string) error
 	DeleteGroup(ctx context.Context, groupID, userID string) error
-	ShareToGroup(ctx context.Context, conversationID, groupID string) error
+	ShareToGroup(ctx context.Context, conversationID, groupID, sharedBy string) error
 	EnsureUserNode(ctx context.Context, username, role string) error
 	GetStats(ctx context.Context) (map[string]interface{}, error)
 	Speculate(ctx context.Context, req *graph.ConsultationRequest) error
@@ -38,7 +40,7 @@ type MemoryKernel interface {
 	CreateShareLink(ctx context.Context, workspaceNS, creatorID string, maxUses int, expiresAt *time.Time) (*graph.ShareLink, error)
 	JoinViaShareLink(ctx context.Context, token, userID string) (*graph.ShareLink, error)
 	RevokeShareLink(ctx context.Context, token, userID string) error
-	GetWorkspaceMembers(ctx context.Context, workspaceNS string) ([]graph.WorkspaceMember, error)
+	GetWorkspaceMembers(ctx context.Context, workspaceNS string, offset, limit int) ([]graph.WorkspaceMember, int, error)
 	IsWorkspaceMember(ctx context.Context, workspaceNS, userID string) (bool, error)
 
 	// Graph traversal methods
@@ -47,13 +49,23 @@ type MemoryKernel interface {
 	// Admin methods
 	// Admin methods
 	TriggerReflection(ctx context.Context) error
+	RetryDeadLetters(ctx context.Context) (int, int64, error)
+	ReindexPending(ctx context.Context) (int, int64, error)
+	PruneNamespace(ctx context.Context, namespace string, opts kernel.PruneOpts) (*kernel.PruneResult, error)
+	Forget(ctx context.Context, namespace, uid string) (*kernel.ForgetResult, error)
+	RecentChanges(ctx context.Context, namespace string, since time.Time) (*kernel.ChangeSummary, error)
+	GetActivationConfig(ctx context.Context, namespace string) graph.ActivationConfig
+	SetActivationConfig(ctx context.Context, namespace string, config graph.ActivationConfig) error
 
 	// Ingestion Persistence
 	PersistEntities(ctx context.Context, namespace, userID, conversationID string, entities []graph.ExtractedEntity) error
 	PersistChunks(ctx context.Context, namespace, docID string, chunks []graph.DocumentChunk) error
+	PersistVectorTree(ctx context.Context, namespace string, tree map[string]*vectorindex.VectorNode) (int, error)
+	IngestConversationSummary(ctx context.Context, namespace, userID, conversationID string, batch *graph.BatchSummary) (string, error)
 
 	// Search
 	SearchNodes(ctx context.Context, namespace, query string) ([]graph.Node, error)
+	FindSimilar(ctx context.Context, namespace, uid string, topK int) ([]graph.SimilarNode, error)
 }
 
 // MKClient is a client for consulting the Memory Kernel
@@ -203,6 +215,14 @@ func (c *MKClient) CreateGroup(ctx context.Context, name, description, ownerID s
 	return result.GroupID, nil
 }
 
+// FindOwnedGroupByName returns the owner's group with the given name, or nil if none exists
+func (c *MKClient) FindOwnedGroupByName(ctx context.Context, ownerID, name string) (*graph.Group, error) {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().FindOwnedGroupByName(ctx, ownerID, name)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for FindOwnedGroupByName")
+}
+
 // ListGroups lists groups the user is a member of
 func (c *MKClient) ListGroups(ctx context.Context, userID string) ([]map[string]interface{}, error) {
 	if c.directKernel != nil {
@@ -297,15 +317,16 @@ func (c *MKClient) RemoveGroupMember(ctx context.Context, groupID, username stri
 	return nil
 }
 
-// ShareToGroup shares a conversation with a group
-func (c *MKClient) ShareToGroup(ctx context.Context, conversationID, groupID string) error {
+// ShareToGroup shares a conversation with a group on behalf of sharedBy
+func (c *MKClient) ShareToGroup(ctx context.Context, conversationID, groupID, sharedBy string) error {
 	if c.directKernel != nil {
-		return c.directKernel.ShareToGroup(ctx, conversationID, groupID)
+		return c.directKernel.ShareToGroup(ctx, conversationID, groupID, sharedBy)
 	}
 
 	payload := map[string]string{
 		"conversation_id": conversationID,
 		"group_id":        groupID,
+		"shared_by":       sharedBy,
 	}
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -331,6 +352,22 @@ func (c *MKClient) ShareToGroup(ctx context.Context, conversationID, groupID str
 	return nil
 }
 
+// GetConversationShares lists the workspaces a conversation is shared to
+func (c *MKClient) GetConversationShares(ctx context.Context, conversationID string) ([]graph.ConversationShare, error) {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().GetConversationShares(ctx, conversationID)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for GetConversationShares")
+}
+
+// UnshareFromGroup revokes a conversation share from a workspace
+func (c *MKClient) UnshareFromGroup(ctx context.Context, conversationID, groupNamespace string) error {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().UnshareFromGroup(ctx, conversationID, groupNamespace)
+	}
+	return fmt.Errorf("HTTP mode not supported for UnshareFromGroup")
+}
+
 // DeleteGroup deletes a group
 func (c *MKClient) DeleteGroup(ctx context.Context, groupID, userID string) error {
 	if c.directKernel != nil {
@@ -617,12 +654,12 @@ func (c *MKClient) RevokeShareLink(ctx context.Context, token, userID string) er
 	return fmt.Errorf("HTTP mode not supported for RevokeShareLink")
 }
 
-// GetWorkspaceMembers gets all members of a workspace
-func (c *MKClient) GetWorkspaceMembers(ctx context.Context, workspaceNS string) ([]graph.WorkspaceMember, error) {
+// GetWorkspaceMembers gets a page of a workspace's members and the total count
+func (c *MKClient) GetWorkspaceMembers(ctx context.Context, workspaceNS string, offset, limit int) ([]graph.WorkspaceMember, int, error) {
 	if c.directKernel != nil {
-		return c.directKernel.GetWorkspaceMembers(ctx, workspaceNS)
+		return c.directKernel.GetWorkspaceMembers(ctx, workspaceNS, offset, limit)
 	}
-	return nil, fmt.Errorf("HTTP mode not supported for GetWorkspaceMembers")
+	return nil, 0, fmt.Errorf("HTTP mode not supported for GetWorkspaceMembers")
 }
 
 // IsWorkspaceMember checks if a user is a member of a workspace
@@ -653,6 +690,14 @@ func (c *MKClient) SpreadActivation(ctx context.Context, opts graph.SpreadActiva
 	return nil, fmt.Errorf("HTTP mode not supported for SpreadActivation")
 }
 
+// SpreadActivationPage performs spreading activation and returns one page of results
+func (c *MKClient) SpreadActivationPage(ctx context.Context, opts graph.SpreadActivationOpts) (*graph.ActivationPage, error) {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().SpreadActivationPage(ctx, opts)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for SpreadActivationPage")
+}
+
 // TraverseViaCommunity traverses the graph via community detection
 func (c *MKClient) TraverseViaCommunity(ctx context.Context, opts graph.CommunityTraversalOpts) (*graph.CommunityResult, error) {
 	if c.directKernel != nil {
@@ -685,6 +730,86 @@ func (c *MKClient) GetSampleNodes(ctx context.Context, namespace string, limit i
 	return nil, fmt.Errorf("HTTP mode not supported for GetSampleNodes")
 }
 
+// GetEdgesBetween returns the edges connecting the given nodes
+func (c *MKClient) GetEdgesBetween(ctx context.Context, uids []string) ([]graph.ExpandEdge, error) {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().GetEdgesBetween(ctx, uids)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for GetEdgesBetween")
+}
+
+// PruneNamespace archives or deletes low-value nodes in a namespace
+func (c *MKClient) PruneNamespace(ctx context.Context, namespace string, opts kernel.PruneOpts) (*kernel.PruneResult, error) {
+	if c.directKernel != nil {
+		return c.directKernel.PruneNamespace(ctx, namespace, opts)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for PruneNamespace")
+}
+
+// Forget deletes a node and purges it from the vector index and caches
+func (c *MKClient) Forget(ctx context.Context, namespace, uid string) (*kernel.ForgetResult, error) {
+	if c.directKernel != nil {
+		return c.directKernel.Forget(ctx, namespace, uid)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for Forget")
+}
+
+// RecentChanges returns what the memory learned in a namespace since a point in time
+func (c *MKClient) RecentChanges(ctx context.Context, namespace string, since time.Time) (*kernel.ChangeSummary, error) {
+	if c.directKernel != nil {
+		return c.directKernel.RecentChanges(ctx, namespace, since)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for RecentChanges")
+}
+
+// GetActivationConfig returns the activation parameters in effect for a namespace
+func (c *MKClient) GetActivationConfig(ctx context.Context, namespace string) graph.ActivationConfig {
+	if c.directKernel != nil {
+		return c.directKernel.GetActivationConfig(ctx, namespace)
+	}
+	return graph.DefaultActivationConfig()
+}
+
+// SetActivationConfig overrides a namespace's activation parameters
+func (c *MKClient) SetActivationConfig(ctx context.Context, namespace string, config graph.ActivationConfig) error {
+	if c.directKernel != nil {
+		return c.directKernel.SetActivationConfig(ctx, namespace, config)
+	}
+	return fmt.Errorf("HTTP mode not supported for SetActivationConfig")
+}
+
+// FindSimilar returns the nodes most similar to uid by vector similarity
+func (c *MKClient) FindSimilar(ctx context.Context, namespace, uid string, topK int) ([]graph.SimilarNode, error) {
+	if c.directKernel != nil {
+		return c.directKernel.FindSimilar(ctx, namespace, uid, topK)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for FindSimilar")
+}
+
+// GetNodeActivation returns a node's activation, access count and last access
+func (c *MKClient) GetNodeActivation(ctx context.Context, uid string) (*graph.NodeActivation, error) {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().GetNodeActivation(ctx, uid)
+	}
+	return nil, fmt.Errorf("HTTP mode not supported for GetNodeActivation")
+}
+
+// ResetActivation sets a node's activation and clears its access count
+func (c *MKClient) ResetActivation(ctx context.Context, uid string, value float64) error {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().ResetActivation(ctx, uid, value)
+	}
+	return fmt.Errorf("HTTP mode not supported for ResetActivation")
+}
+
+// ResetActivations resets every node filter selects and returns how many it rewrote
+func (c *MKClient) ResetActivations(ctx context.Context, filter graph.ActivationResetFilter, value float64) (int, error) {
+	if c.directKernel != nil {
+		return c.directKernel.GetGraphClient().ResetActivations(ctx, filter, value)
+	}
+	return 0, fmt.Errorf("HTTP mode not supported for ResetActivations")
+}
+
 // SearchNodes searches for nodes matching a query string
 func (c *MKClient) SearchNodes(ctx context.Context, namespace, query string) ([]graph.Node, error) {
 	if c.directKernel != nil {
@@ -725,6 +850,22 @@ func (c *MKClient) TriggerReflection(ctx context.Context) error {
 	return fmt.Errorf("HTTP mode not supported for TriggerReflection")
 }
 
+// RetryDeadLetters retries dead-lettered ingestion events; returns (ingested, still pending)
+func (c *MKClient) RetryDeadLetters(ctx context.Context) (int, int64, error) {
+	if c.directKernel != nil {
+		return c.directKernel.RetryDeadLetters(ctx)
+	}
+	return 0, 0, fmt.Errorf("HTTP mode not supported for RetryDeadLetters")
+}
+
+// ReindexPending retries vector upserts that failed during ingestion; returns (indexed, still pending)
+func (c *MKClient) ReindexPending(ctx context.Context) (int, int64, error) {
+	if c.directKernel != nil {
+		return c.directKernel.ReindexPending(ctx)
+	}
+	return 0, 0, fmt.Errorf("HTTP mode not supported for ReindexPending")
+}
+
 // PersistEntities persists extracted entities to the graph
 func (c *MKClient) PersistEntities(ctx context.Context, namespace, userID, conversationID string, entities []graph.ExtractedEntity) error {
 	if c.directKernel != nil {
@@ -741,6 +882,22 @@ func (c *MKClient) PersistChunks(ctx context.Context, namespace, docID string, c
 	return fmt.Errorf("HTTP mode not supported for PersistChunks")
 }
 
+// PersistVectorTree stores a document's vector tree in Qdrant
+func (c *MKClient) PersistVectorTree(ctx context.Context, namespace string, tree map[string]*vectorindex.VectorNode) (int, error) {
+	if c.directKernel != nil {
+		return c.directKernel.PersistVectorTree(ctx, namespace, tree)
+	}
+	return 0, fmt.Errorf("HTTP mode not supported for PersistVectorTree")
+}
+
+// IngestConversationSummary crystallizes a summarized conversation into a namespace
+func (c *MKClient) IngestConversationSummary(ctx context.Context, namespace, userID, conversationID string, batch *graph.BatchSummary) (string, error) {
+	if c.directKernel != nil {
+		return c.directKernel.IngestConversationSummary(ctx, namespace, userID, conversationID, batch)
+	}
+	return "", fmt.Errorf("HTTP mode not supported for IngestConversationSummary")
+}
+
 // GetUserSettings retrieves user settings from DGraph
 func (c *MKClient) GetUserSettings(ctx context.Context, userID string) (*graph.UserSettings, error) {
 	graphClient := c.GetGraphClient()
@@ -839,3 +996,46 @@ func (c *AIClient) GenerateResponseWithUserKeys(ctx context.Context, query, cont
 
 	return result.Response, nil
 }
+
+// summarizeFailedSummary is the placeholder summary /summarize_batch returns
+// (with status 200) when extraction fails
+const summarizeFailedSummary = "Failed to extract summary"
+
+// SummarizeBatch asks the AI service to crystallize a transcript into a
+// summary plus the entities and relationships it mentions
+func (c *AIClient) SummarizeBatch(ctx context.Context, transcript string) (*graph.BatchSummary, error) {
+	jsonData, err := json.Marshal(map[string]string{
+		"text": transcript,
+		"type": "crystallize",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		c.baseURL+"/summarize_batch",
+		bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AI service returned status %d", resp.StatusCode)
+	}
+
+	var result graph.BatchSummary
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Summary == "" || result.Summary == summarizeFailedSummary {
+		return nil, fmt.Errorf("AI service could not summarize the transcript")
+	}
+	return &result, nil
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/handlers"
+)
+
+// DefaultAllowedOrigins is the browser origin policy when ALLOWED_ORIGINS is
+// unset: any local development server
+var DefaultAllowedOrigins = []string{"http://localhost:*"}
+
+// AllowedOriginsFromEnv reads the comma-separated ALLOWED_ORIGINS list shared
+// by CORS and WebSocket upgrades; DefaultAllowedOrigins when unset
+func AllowedOriginsFromEnv() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return DefaultAllowedOrigins
+	}
+	return origins
+}
+
+// OriginAllowed reports whether a browser origin matches one of the allowed
+// patterns. "*" allows every origin (use only in development); a "*" inside a
+// pattern stands for a host label or port, e.g. "https://*.example.com".
+func OriginAllowed(origin string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if pattern == "*" || originMatches(origin, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS returns middleware applying the same origin policy as WebSocket
+// upgrades to cross-origin HTTP requests
+func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	return handlers.CORS(
+		handlers.AllowedOriginValidator(func(origin string) bool {
+			return OriginAllowed(origin, allowedOrigins)
+		}),
+		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
+		handlers.AllowCredentials(),
+	)
+}
+
+// originMatches checks if an origin matches a pattern (supports wildcards)
+func originMatches(origin, pattern string) bool {
+	if origin == pattern {
+		return true
+	}
+	if !strings.Contains(pattern, "*") {
+		return false
+	}
+	// A wildcard never spans a scheme or path separator, so
+	// "http://localhost:*" can't match "http://localhost:80@evil.com"
+	patternRegex := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, `[A-Za-z0-9.-]*`)
+	matched, _ := regexp.MatchString("^"+patternRegex+"$", origin)
+	return matched
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+func TestWebSocketOriginPolicy(t *testing.T) {
+	logger := zap.NewNop()
+	a, _ := New(DefaultConfig(), logger)
+	s := NewServer(a, logger, "https://app.example.com", "http://localhost:*")
+	ts := httptest.NewServer(http.HandlerFunc(s.handleWebSocketChat))
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	for _, tc := range []struct {
+		origin string
+		want   int
+	}{
+		{"https://evil.example.org", http.StatusForbidden},
+		{"http://localhost.evil.com", http.StatusForbidden},
+		{"https://app.example.com", http.StatusSwitchingProtocols},
+		{"http://localhost:5173", http.StatusSwitchingProtocols},
+		{"", http.StatusSwitchingProtocols}, // Non-browser client
+	} {
+		header := http.Header{}
+		if tc.origin != "" {
+			header.Set("Origin", tc.origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+		if conn != nil {
+			conn.Close()
+		}
+		if resp == nil {
+			t.Fatalf("origin %q: no response: %v", tc.origin, err)
+		}
+		if resp.StatusCode != tc.want {
+			t.Errorf("origin %q: status %d, want %d", tc.origin, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestOriginAllowedWildcards(t *testing.T) {
+	allowed := []string{"https://*.example.com"}
+	for origin, want := range map[string]bool{
+		"https://app.example.com":          true,
+		"https://a.b.example.com":          true,
+		"https://example.com":              false,
+		"https://evil.com/.example.com":    false,
+		"http://app.example.com":           false,
+		"https://app.example.com.evil.com": false,
+	} {
+		if got := OriginAllowed(origin, allowed); got != want {
+			t.Errorf("OriginAllowed(%q) = %v, want %v", origin, got, want)
+		}
+	}
+	if !OriginAllowed("https://anything.test", []string{"*"}) {
+		t.Error(`"*" should allow every origin`)
+	}
+}
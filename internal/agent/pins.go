@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// ErrPinningUnavailable is returned when there is no in-process graph to pin in
+var ErrPinningUnavailable = errors.New("pinning is not available")
+
+// nodePinner is the part of the graph client pinning uses
+type nodePinner interface {
+	GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]graph.Node, error)
+	SetPinned(ctx context.Context, uid string, pinned bool) error
+}
+
+// PinNode pins or unpins one of namespace's nodes, so it resists decay and
+// pruning. A node outside the namespace is reported as not found.
+func (a *Agent) PinNode(ctx context.Context, namespace, uid string, pinned bool) error {
+	graphClient := a.GetGraphClient()
+	if graphClient == nil {
+		return ErrPinningUnavailable
+	}
+	return pinNode(ctx, graphClient, namespace, uid, pinned)
+}
+
+func pinNode(ctx context.Context, g nodePinner, namespace, uid string, pinned bool) error {
+	if namespace == "" || uid == "" {
+		return fmt.Errorf("namespace and uid are required")
+	}
+	nodes, err := g.GetNodesByUIDs(ctx, []string{uid}, []string{"namespace"})
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 || nodes[0].Namespace != namespace {
+		return fmt.Errorf("node %s not found in namespace %s", uid, namespace)
+	}
+	return g.SetPinned(ctx, uid, pinned)
+}
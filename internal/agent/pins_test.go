@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func (f *fakeTagger) SetPinned(ctx context.Context, uid string, pinned bool) error {
+	f.mutations++
+	f.nodes[uid].Pinned = pinned
+	return nil
+}
+
+func TestPinNodeOnlyWithinNamespace(t *testing.T) {
+	g := newFakeTagger(
+		graph.Node{UID: "0x1", Namespace: "user_a"},
+		graph.Node{UID: "0x2", Namespace: "user_b"},
+	)
+
+	if err := pinNode(context.Background(), g, "user_a", "0x1", true); err != nil {
+		t.Fatalf("pinNode: %v", err)
+	}
+	if !g.nodes["0x1"].Pinned {
+		t.Error("0x1 was not pinned")
+	}
+
+	for _, uid := range []string{"0x2", "0x9"} {
+		if err := pinNode(context.Background(), g, "user_a", uid, true); err == nil {
+			t.Errorf("pinned %s from outside its namespace", uid)
+		}
+	}
+	if g.nodes["0x2"].Pinned || g.mutations != 1 {
+		t.Errorf("0x2 pinned = %v after %d mutations; want untouched", g.nodes["0x2"].Pinned, g.mutations)
+	}
+}
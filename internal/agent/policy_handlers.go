@@ -47,14 +47,14 @@ func (s *Server) SetupPolicyRoutes(r *mux.Router, jwtMiddleware *JWTMiddleware)
 // GET /api/policies
 func (s *Server) handleListPolicies(w http.ResponseWriter, r *http.Request) {
 	if s.agent.PolicyManager == nil || s.agent.PolicyManager.Store == nil {
-		http.Error(w, "Policy store not available", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Policy store not available", nil)
 		return
 	}
 
 	policies, err := s.agent.PolicyManager.Store.LoadAllPolicies(r.Context())
 	if err != nil {
 		s.logger.Error("Failed to load policies", zap.Error(err))
-		http.Error(w, "Failed to load policies", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to load policies", nil)
 		return
 	}
 
@@ -68,13 +68,13 @@ func (s *Server) handleListPolicies(w http.ResponseWriter, r *http.Request) {
 // POST /api/policies
 func (s *Server) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
 	if s.agent.PolicyManager == nil || s.agent.PolicyManager.Store == nil {
-		http.Error(w, "Policy store not available", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Policy store not available", nil)
 		return
 	}
 
 	var p policy.Policy
 	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -85,7 +85,7 @@ func (s *Server) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
 	id, err := s.agent.PolicyManager.Store.SavePolicy(r.Context(), namespace, p, "admin")
 	if err != nil {
 		s.logger.Error("Failed to save policy", zap.Error(err))
-		http.Error(w, "Failed to save policy", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to save policy", nil)
 		return
 	}
 
@@ -100,7 +100,7 @@ func (s *Server) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
 // DELETE /api/policies/{id}
 func (s *Server) handleDeletePolicy(w http.ResponseWriter, r *http.Request) {
 	if s.agent.PolicyManager == nil || s.agent.PolicyManager.Store == nil {
-		http.Error(w, "Policy store not available", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Policy store not available", nil)
 		return
 	}
 
@@ -109,7 +109,7 @@ func (s *Server) handleDeletePolicy(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.agent.PolicyManager.Store.DeletePolicy(r.Context(), id); err != nil {
 		s.logger.Error("Failed to delete policy", zap.Error(err))
-		http.Error(w, "Failed to delete policy", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete policy", nil)
 		return
 	}
 
@@ -121,7 +121,7 @@ func (s *Server) handleDeletePolicy(w http.ResponseWriter, r *http.Request) {
 // GET /api/audit
 func (s *Server) handleGetAuditLogs(w http.ResponseWriter, r *http.Request) {
 	if s.agent.PolicyManager == nil || s.agent.PolicyManager.AuditLogger == nil {
-		http.Error(w, "Audit logger not available", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Audit logger not available", nil)
 		return
 	}
 
@@ -154,7 +154,7 @@ func (s *Server) handleGetAuditLogs(w http.ResponseWriter, r *http.Request) {
 	logs, err := s.agent.PolicyManager.AuditLogger.QueryAuditLogs(r.Context(), requestingUserID, requestingRole, targetUserID, targetNamespace, policy.AuditEventType(eventType), limit)
 	if err != nil {
 		s.logger.Error("Failed to query audit logs", zap.Error(err))
-		http.Error(w, "Failed to query logs", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to query logs", nil)
 		return
 	}
 
@@ -170,13 +170,13 @@ func (s *Server) handleGetAuditLogs(w http.ResponseWriter, r *http.Request) {
 // GET /api/rate-limits
 func (s *Server) handleGetRateLimits(w http.ResponseWriter, r *http.Request) {
 	if s.agent.PolicyManager == nil || s.agent.PolicyManager.RateLimiter == nil {
-		http.Error(w, "Rate limiter not available", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Rate limiter not available", nil)
 		return
 	}
 
 	userID := r.URL.Query().Get("user_id")
 	if userID == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "user_id is required", nil)
 		return
 	}
 
@@ -188,7 +188,7 @@ func (s *Server) handleGetRateLimits(w http.ResponseWriter, r *http.Request) {
 	status, err := s.agent.PolicyManager.RateLimiter.GetStatus(r.Context(), userID, tier)
 	if err != nil {
 		s.logger.Error("Failed to get rate limits", zap.Error(err))
-		http.Error(w, "Failed to get rate limits", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to get rate limits", nil)
 		return
 	}
 
@@ -0,0 +1,197 @@
+package agent
+
+import (
+	"container/list"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Retention defaults: a conversation leaves memory once it has been idle this
+// long, or once this many more recently used ones are held
+const (
+	DefaultConversationIdleTTL = time.Hour
+	DefaultMaxConversations    = 10000
+)
+
+// retentionSweepInterval is how often idle conversations are looked for
+const retentionSweepInterval = time.Minute
+
+// RetentionConfig bounds the conversations held in memory. Evicted
+// conversations are reloaded from the conversation store when asked for.
+// Zero values use the defaults; a negative value disables that limit.
+type RetentionConfig struct {
+	IdleTTL          time.Duration // Inactivity after which a crystallized conversation is evicted
+	MaxConversations int           // Least recently used conversations beyond this are evicted
+}
+
+// RetentionConfigFromEnv reads CONVERSATION_IDLE_TTL (a duration such as "2h")
+// and MAX_CONVERSATIONS (a count); unset or invalid values keep the defaults
+func RetentionConfigFromEnv() RetentionConfig {
+	var cfg RetentionConfig
+	if d, err := time.ParseDuration(os.Getenv("CONVERSATION_IDLE_TTL")); err == nil {
+		cfg.IdleTTL = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("MAX_CONVERSATIONS")); err == nil {
+		cfg.MaxConversations = n
+	}
+	return cfg
+}
+
+// withDefaults fills in unset limits
+func (c RetentionConfig) withDefaults() RetentionConfig {
+	if c.IdleTTL == 0 {
+		c.IdleTTL = DefaultConversationIdleTTL
+	}
+	if c.MaxConversations == 0 {
+		c.MaxConversations = DefaultMaxConversations
+	}
+	return c
+}
+
+// cachedConversation returns the in-memory conversation, marking it most
+// recently used, or nil if it isn't held
+func (a *Agent) cachedConversation(conversationID string) *Conversation {
+	a.convMu.Lock()
+	defer a.convMu.Unlock()
+	conv, ok := a.conversations[conversationID]
+	if !ok {
+		return nil
+	}
+	conv.lastUsed = time.Now()
+	if conv.lruElem != nil {
+		a.convLRU.MoveToFront(conv.lruElem)
+	}
+	return conv
+}
+
+// cacheConversation holds conv in memory, unless another caller added the
+// same conversation first, in which case that one is returned. Least recently
+// used conversations beyond the cap are evicted, their pending turns
+// crystallized in the background.
+func (a *Agent) cacheConversation(conv *Conversation) *Conversation {
+	a.convMu.Lock()
+	if existing, ok := a.conversations[conv.ID]; ok {
+		a.convMu.Unlock()
+		return existing
+	}
+	if a.conversations == nil {
+		a.conversations = make(map[string]*Conversation)
+	}
+	if a.convLRU == nil {
+		a.convLRU = list.New()
+	}
+	conv.lastUsed = time.Now()
+	conv.lruElem = a.convLRU.PushFront(conv)
+	a.conversations[conv.ID] = conv
+
+	var evicted []*Conversation
+	if limit := a.config.Retention.withDefaults().MaxConversations; limit > 0 {
+		for len(a.conversations) > limit {
+			oldest := a.convLRU.Back().Value.(*Conversation)
+			a.removeConversationLocked(oldest)
+			evicted = append(evicted, oldest)
+		}
+	}
+	a.convMu.Unlock()
+
+	for _, old := range evicted {
+		a.logger.Debug("Evicted least recently used conversation",
+			zap.String("conversation_id", old.ID))
+		if a.canCrystallize() && hasPendingTurns(old) {
+			go a.crystallizeInBackground(old)
+		}
+	}
+	return conv
+}
+
+// removeConversationLocked drops conv from memory. Callers hold a.convMu.
+func (a *Agent) removeConversationLocked(conv *Conversation) {
+	if a.conversations[conv.ID] != conv {
+		return
+	}
+	delete(a.conversations, conv.ID)
+	if conv.lruElem != nil {
+		a.convLRU.Remove(conv.lruElem)
+		conv.lruElem = nil
+	}
+	a.convEvicted.Add(1)
+}
+
+// hasPendingTurns reports whether conv has turns not yet crystallized
+func hasPendingTurns(conv *Conversation) bool {
+	conv.mu.Lock()
+	defer conv.mu.Unlock()
+	return len(pendingTurns(conv.Turns, conv.crystallizedThrough)) > 0
+}
+
+// runRetention periodically evicts idle conversations, until the agent stops
+func (a *Agent) runRetention() {
+	ttl := a.config.Retention.withDefaults().IdleTTL
+	if ttl < 0 {
+		return
+	}
+	interval := retentionSweepInterval
+	if ttl < interval {
+		interval = ttl
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.evictIdle(ttl)
+		}
+	}
+}
+
+// evictIdle evicts every conversation unused for at least ttl. Pending turns
+// are crystallized first; a conversation whose crystallization fails stays in
+// memory until a later sweep succeeds or it is pushed out by the cap.
+func (a *Agent) evictIdle(ttl time.Duration) {
+	cutoff := time.Now().Add(-ttl)
+	var idle []*Conversation
+	a.convMu.RLock()
+	if a.convLRU != nil {
+		for e := a.convLRU.Back(); e != nil; e = e.Prev() {
+			conv := e.Value.(*Conversation)
+			if !conv.lastUsed.Before(cutoff) {
+				break
+			}
+			idle = append(idle, conv)
+		}
+	}
+	a.convMu.RUnlock()
+
+	evicted := 0
+	for _, conv := range idle {
+		if a.ctx.Err() != nil {
+			return
+		}
+		if a.canCrystallize() && hasPendingTurns(conv) {
+			a.crystallizeInBackground(conv)
+			if hasPendingTurns(conv) {
+				continue
+			}
+		}
+
+		a.convMu.Lock()
+		// Skip conversations used again while they were being crystallized
+		if conv.lastUsed.Before(cutoff) && a.conversations[conv.ID] == conv {
+			a.removeConversationLocked(conv)
+			evicted++
+		}
+		a.convMu.Unlock()
+	}
+
+	if evicted > 0 {
+		a.logger.Info("Evicted idle conversations",
+			zap.Int("evicted", evicted),
+			zap.Duration("idle_ttl", ttl))
+	}
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// memoryConversationStore keeps persisted turns in memory
+type memoryConversationStore struct {
+	mu    sync.Mutex
+	turns map[string][]graph.ConversationTurn
+}
+
+func (s *memoryConversationStore) AppendConversationTurn(ctx context.Context, turn *graph.ConversationTurn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.turns[turn.ConversationID] = append(s.turns[turn.ConversationID], *turn)
+	return nil
+}
+
+func (s *memoryConversationStore) GetConversationTurns(ctx context.Context, conversationID string) ([]graph.ConversationTurn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.turns[conversationID], nil
+}
+
+func (s *memoryConversationStore) DeleteConversationTurns(ctx context.Context, namespace, conversationID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.turns[conversationID])
+	delete(s.turns, conversationID)
+	return n, nil
+}
+
+func TestLeastRecentlyUsedConversationEvictedAndReloaded(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Retention.MaxConversations = 2
+	a, _ := New(cfg, zap.NewNop())
+	store := &memoryConversationStore{turns: map[string][]graph.ConversationTurn{}}
+	a.SetConversationStore(store)
+
+	for _, id := range []string{"conv-1", "conv-2"} {
+		a.getOrCreateConversation("alice", id)
+		a.persistTurn("alice", id, "user_alice", Turn{Timestamp: time.Now(), UserQuery: "hi from " + id, Response: "hello"})
+	}
+	a.getOrCreateConversation("alice", "conv-1") // conv-2 is now least recently used
+	a.getOrCreateConversation("alice", "conv-3")
+
+	a.convMu.RLock()
+	_, held := a.conversations["conv-2"]
+	inMemory := len(a.conversations)
+	a.convMu.RUnlock()
+	if held || inMemory != 2 {
+		t.Fatalf("conv-2 held = %v with %d in memory; want it evicted, 2 left", held, inMemory)
+	}
+	if stats := a.GetStats(); stats["evicted_conversations"] != int64(1) {
+		t.Errorf("evicted_conversations = %v, want 1", stats["evicted_conversations"])
+	}
+
+	conv := a.GetConversation("conv-2")
+	if conv == nil || conv.UserID != "alice" || len(conv.Turns) != 1 || conv.Turns[0].UserQuery != "hi from conv-2" {
+		t.Fatalf("reloaded conv-2 = %+v, want its stored turn", conv)
+	}
+	if a.GetConversation("conv-2") != conv {
+		t.Error("reloaded conversation was not held in memory again")
+	}
+}
+
+func TestIdleConversationCrystallizedBeforeEviction(t *testing.T) {
+	ai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(graph.BatchSummary{Summary: "talked about tea"})
+	}))
+	defer ai.Close()
+
+	logger := zap.NewNop()
+	a, _ := New(DefaultConfig(), logger)
+	kernel := &summaryKernel{}
+	a.mkClient = NewMKClient("", logger)
+	a.mkClient.SetDirectKernel(kernel)
+	a.aiClient = NewAIClient(ai.URL, logger)
+	a.SetConversationStore(&memoryConversationStore{turns: map[string][]graph.ConversationTurn{}})
+
+	idle := a.getOrCreateConversation("alice", "conv-idle")
+	idle.Turns = []Turn{{Timestamp: time.Now().Add(-2 * time.Hour), UserQuery: "I drink tea", Response: "Noted"}}
+	a.getOrCreateConversation("alice", "conv-active")
+
+	a.convMu.Lock()
+	idle.lastUsed = time.Now().Add(-2 * time.Hour)
+	a.convMu.Unlock()
+
+	a.evictIdle(time.Hour)
+
+	if len(kernel.batches) != 1 {
+		t.Errorf("crystallized %d times before eviction, want once", len(kernel.batches))
+	}
+	a.convMu.RLock()
+	_, idleHeld := a.conversations["conv-idle"]
+	_, activeHeld := a.conversations["conv-active"]
+	a.convMu.RUnlock()
+	if idleHeld || !activeHeld {
+		t.Errorf("idle held = %v, active held = %v; want only the active one kept", idleHeld, activeHeld)
+	}
+}
@@ -0,0 +1,27 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// SchemaTypesResponse lists the types write endpoints accept, so clients can
+// validate before sending
+type SchemaTypesResponse struct {
+	NodeTypes         []string `json:"node_types"`         // memory_store node_type
+	EntityTypes       []string `json:"entity_types"`       // entity_create entity_type
+	RelationshipTypes []string `json:"relationship_types"` // relationship and edge types
+}
+
+// handleSchemaTypes returns the valid node, entity and relationship types.
+// GET /api/schema/types
+func (s *Server) handleSchemaTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SchemaTypesResponse{
+		NodeTypes:         graph.MemoryNodeTypeNames(),
+		EntityTypes:       graph.EntityTypeNames(),
+		RelationshipTypes: graph.EdgeTypeNames(),
+	})
+}
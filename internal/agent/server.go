@@ -2,15 +2,15 @@
 package agent
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +19,8 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/kernel"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"github.com/reflective-memory-kernel/internal/policy"
 	"go.uber.org/zap"
 )
@@ -31,6 +33,9 @@ type Server struct {
 	allowedOrigins []string  // Allowed origins for WebSocket connections
 	groupLock      *GroupLockManager // Distributed lock manager for group operations
 	crypto         *Crypto    // Encryption/decryption for sensitive user data
+	webhooks       *WebhookDispatcher // Delivers memory events to user webhooks (nil without Redis/NATS)
+	shares         *MemoryShareStore  // Read-only memory shares (nil without Redis)
+	shareNodes     sharedNodeReader   // What shares are read through (nil = the agent's graph client)
 }
 
 // NewServer creates a new HTTP server for the agent
@@ -38,7 +43,7 @@ func NewServer(agent *Agent, logger *zap.Logger, allowedOrigins ...string) *Serv
 	// Default to localhost in development if no origins specified
 	origins := allowedOrigins
 	if len(origins) == 0 {
-		origins = []string{"http://localhost:*"}
+		origins = DefaultAllowedOrigins
 	}
 
 	// Initialize group lock manager for distributed locking
@@ -59,12 +64,31 @@ func NewServer(agent *Agent, logger *zap.Logger, allowedOrigins ...string) *Serv
 		crypto = nil
 	}
 
+	// Initialize webhook dispatcher (consumes memory events from NATS)
+	var webhooks *WebhookDispatcher
+	if agent.RedisClient != nil && agent.natsConn != nil {
+		webhooks = NewWebhookDispatcher(agent.RedisClient, func(ctx context.Context, namespace, userID string) (bool, error) {
+			return agent.mkClient.IsWorkspaceMember(ctx, namespace, userID)
+		}, logger.Named("webhooks"))
+		if err := webhooks.Start(agent.ctx, agent.natsConn); err != nil {
+			logger.Warn("Failed to start webhook dispatcher, webhooks will be disabled", zap.Error(err))
+			webhooks = nil
+		}
+	}
+
+	var shares *MemoryShareStore
+	if agent.RedisClient != nil {
+		shares = NewMemoryShareStore(agent.RedisClient, getJWTSecret())
+	}
+
 	return &Server{
 		agent:          agent,
 		logger:         logger,
 		allowedOrigins: origins,
 		groupLock:      groupLock,
 		crypto:         crypto,
+		webhooks:       webhooks,
+		shares:         shares,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				origin := r.Header.Get("Origin")
@@ -73,14 +97,9 @@ func NewServer(agent *Agent, logger *zap.Logger, allowedOrigins ...string) *Serv
 					return true
 				}
 
-				// Check against allowed origins
-				for _, allowed := range origins {
-					if allowed == "*" {
-						return true  // Allow all (use only in development)
-					}
-					if originMatches(origin, allowed) {
-						return true
-					}
+				// Same policy as CORS; the upgrade fails with 403 otherwise
+				if OriginAllowed(origin, origins) {
+					return true
 				}
 
 				// Log rejected origin for security monitoring
@@ -93,21 +112,6 @@ func NewServer(agent *Agent, logger *zap.Logger, allowedOrigins ...string) *Serv
 	}
 }
 
-// originMatches checks if an origin matches a pattern (supports wildcards)
-func originMatches(origin, pattern string) bool {
-	if origin == pattern {
-		return true
-	}
-	// Wildcard matching (e.g., "https://*.example.com")
-	if strings.Contains(pattern, "*") {
-		patternRegex := strings.ReplaceAll(pattern, ".", "\\.")
-		patternRegex = strings.ReplaceAll(patternRegex, "*", ".*")
-		matched, _ := regexp.MatchString("^"+patternRegex+"$", origin)
-		return matched
-	}
-	return false
-}
-
 // SetupRoutes configures the HTTP routes
 func (s *Server) SetupRoutes(r *mux.Router) error {
 	s.logger.Info("Registering routes...")
@@ -157,6 +161,7 @@ func (s *Server) SetupRoutes(r *mux.Router) error {
 	// Public Routes
 	api.HandleFunc("/register", s.handleRegister).Methods("POST")
 	api.HandleFunc("/login", s.handleLogin).Methods("POST")
+	api.HandleFunc("/schema/types", s.handleSchemaTypes).Methods("GET")
 
 	// Protected routes (Wrap with Middleware manually to avoid subrouter conflict)
 	protected := func(h http.HandlerFunc) http.Handler {
@@ -168,7 +173,11 @@ func (s *Server) SetupRoutes(r *mux.Router) error {
 	api.Handle("/chat", protect(s.handleChat)).Methods("POST")
 	api.Handle("/search", protect(s.handleSearch)).Methods("GET")
 	api.Handle("/search/temporal", protect(s.handleTemporalQuery)).Methods("POST")
+	api.Handle("/similar", protect(s.handleSimilar)).Methods("POST")
+	api.Handle("/prune", protect(s.handlePrune)).Methods("POST")
+	api.Handle("/changes", protect(s.handleRecentChanges)).Methods("GET")
 	api.Handle("/stats", protect(s.handleStats)).Methods("GET")
+	api.Handle("/me", protect(s.handleMe)).Methods("GET")
 	api.Handle("/conversations", protect(s.handleConversations)).Methods("GET")
 
 	// Dashboard endpoints
@@ -178,10 +187,14 @@ func (s *Server) SetupRoutes(r *mux.Router) error {
 
 	// Document upload
 	api.Handle("/upload", protect(s.handleUpload)).Methods("POST")
+	api.Handle("/upload/batch", protect(s.handleBatchUpload)).Methods("POST")
 	// Document deletion (by document ID)
 	api.Handle("/documents/{id}", protect(s.handleDeleteDocument)).Methods("DELETE")
 	// List documents
 	api.Handle("/documents", protect(s.handleListDocuments)).Methods("GET")
+	api.Handle("/memories", protect(s.handleListMemories)).Methods("GET")
+	api.Handle("/memories/tags", protect(s.handleRetagMemories)).Methods("POST")
+	api.Handle("/namespaces/{ns}/facts", protect(s.handleFactFeed)).Methods("GET")
 
 	// Groups
 	// SECURITY: Apply rate limiting to group management operations
@@ -194,6 +207,10 @@ func (s *Server) SetupRoutes(r *mux.Router) error {
 	api.Handle("/groups/{id}/members/{username}", protect(s.handleRemoveGroupMember)).Methods("DELETE")
 	api.Handle("/groups/{id}", protect(s.handleDeleteGroup)).Methods("DELETE")
 	api.Handle("/groups/{id}/subusers", protect(s.handleCreateSubuser)).Methods("POST")
+	api.Handle("/conversations/{id}/shares", protect(s.handleShareConversation)).Methods("POST")
+	api.Handle("/conversations/{id}/shares", protect(s.handleGetConversationShares)).Methods("GET")
+	api.Handle("/conversations/{id}/shares/{namespace}", protect(s.handleUnshareConversation)).Methods("DELETE")
+	api.Handle("/conversations/{id}/crystallize", protect(s.handleCrystallizeConversation)).Methods("POST")
 
 	// User listing (for invitation/member selection - available to all authenticated users)
 	api.Handle("/users", protect(s.handleListUsers)).Methods("GET")
@@ -210,6 +227,16 @@ func (s *Server) SetupRoutes(r *mux.Router) error {
 	api.Handle("/invitations/{id}/decline", protect(s.handleDeclineInvitation)).Methods("POST")
 	api.Handle("/join/{token}", protect(s.handleJoinViaShareLink)).Methods("POST")
 
+	// Webhook subscriptions for memory events
+	s.setupWebhookRoutes(api, protect)
+
+	// Read-only memory shares; redeeming one needs no login
+	s.setupMemoryShareRoutes(api, protect)
+
+	// Ingestion dead-letter queue (admin only)
+	api.Handle("/ingest/retry-dlq", protect(s.handleRetryDeadLetters)).Methods("POST")
+	api.Handle("/ingest/reindex", protect(s.handleReindexPending)).Methods("POST")
+
 	// Health check (public, on root router or api?)
 	r.HandleFunc("/health", s.handleHealth).Methods("GET")
 
@@ -277,18 +304,18 @@ type RegisterRequest struct {
 func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	// Check if Redis is available
 	if s.agent.RedisClient == nil {
-		http.Error(w, "Authentication service unavailable", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Authentication service unavailable", nil)
 		return
 	}
 
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if req.Username == "" || req.Password == "" {
-		http.Error(w, "Username and password are required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Username and password are required", nil)
 		return
 	}
 
@@ -297,11 +324,11 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+req.Username).Result()
 	if err != nil {
 		s.logger.Error("Failed to check user existence", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if exists > 0 {
-		http.Error(w, "Username already taken", http.StatusConflict)
+		writeJSONError(w, http.StatusConflict, "Username already taken", nil)
 		return
 	}
 
@@ -309,14 +336,14 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	hashedPassword, err := HashPassword(req.Password)
 	if err != nil {
 		s.logger.Error("Failed to hash password", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 
 	// Store user credentials in Redis
 	if err := s.agent.RedisClient.Set(ctx, "user:"+req.Username, hashedPassword, 0).Err(); err != nil {
 		s.logger.Error("Failed to store user", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 
@@ -343,7 +370,7 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	token, err := GenerateToken(req.Username, role)
 	if err != nil {
 		s.logger.Error("Failed to generate token", zap.Error(err))
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to generate token", nil)
 		return
 	}
 
@@ -372,32 +399,32 @@ func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
 
 	// Check if Redis is available
 	if s.agent.RedisClient == nil {
-		http.Error(w, "Authentication service unavailable", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Authentication service unavailable", nil)
 		return
 	}
 
 	// Check if system is already bootstrapped
 	adminInit, _ := s.agent.RedisClient.Get(ctx, "system:admin_initialized").Result()
 	if adminInit == "true" {
-		http.Error(w, "System already initialized. Use /api/register to create new users.", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "System already initialized. Use /api/register to create new users.", nil)
 		return
 	}
 
 	var req BootstrapRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	// Validate input
 	if req.Username == "" || req.Password == "" {
-		http.Error(w, "Username and password are required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Username and password are required", nil)
 		return
 	}
 
 	// Validate password strength (minimum 8 characters)
 	if len(req.Password) < 8 {
-		http.Error(w, "Password must be at least 8 characters", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Password must be at least 8 characters", nil)
 		return
 	}
 
@@ -405,11 +432,11 @@ func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+req.Username).Result()
 	if err != nil {
 		s.logger.Error("Failed to check user existence", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if exists > 0 {
-		http.Error(w, "Username already taken", http.StatusConflict)
+		writeJSONError(w, http.StatusConflict, "Username already taken", nil)
 		return
 	}
 
@@ -417,7 +444,7 @@ func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
 	hashedPassword, err := HashPassword(req.Password)
 	if err != nil {
 		s.logger.Error("Failed to hash password", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 
@@ -425,7 +452,7 @@ func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
 	role := "admin"
 	if err := s.agent.RedisClient.Set(ctx, "user:"+req.Username, hashedPassword, 0).Err(); err != nil {
 		s.logger.Error("Failed to store user", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 
@@ -446,7 +473,7 @@ func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
 	token, err := GenerateToken(req.Username, role)
 	if err != nil {
 		s.logger.Error("Failed to generate token", zap.Error(err))
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to generate token", nil)
 		return
 	}
 
@@ -465,18 +492,18 @@ func (s *Server) handleBootstrap(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	// Check if Redis is available
 	if s.agent.RedisClient == nil {
-		http.Error(w, "Authentication service unavailable", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Authentication service unavailable", nil)
 		return
 	}
 
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if req.Username == "" || req.Password == "" {
-		http.Error(w, "Username and password are required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Username and password are required", nil)
 		return
 	}
 
@@ -484,13 +511,13 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	hashedPassword, err := s.agent.RedisClient.Get(ctx, "user:"+req.Username).Result()
 	if err != nil {
-		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		writeJSONError(w, http.StatusUnauthorized, "Invalid username or password", nil)
 		return
 	}
 
 	// Verify password
 	if !CheckPassword(hashedPassword, req.Password) {
-		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		writeJSONError(w, http.StatusUnauthorized, "Invalid username or password", nil)
 		return
 	}
 
@@ -504,7 +531,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	token, err := GenerateToken(req.Username, role)
 	if err != nil {
 		s.logger.Error("Failed to generate token", zap.Error(err))
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to generate token", nil)
 		return
 	}
 
@@ -521,7 +548,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -531,34 +558,34 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 
 	// Determine Namespace
 	// Priority: 1. req.Namespace (direct), 2. context_type/context_id (legacy), 3. default user namespace
-	namespace := fmt.Sprintf("user_%s", userID) // Default to private
+	namespace := nsutil.ForUser(userID) // Default to private
 
 	if req.Namespace != "" {
 		// Direct namespace specification (preferred by frontend)
 		// SECURITY: Validate namespace access to prevent cross-namespace access
-		if strings.HasPrefix(req.Namespace, "user_") {
+		if nsutil.IsUser(req.Namespace) {
 			// Users can only access their own namespace
-			expectedNamespace := fmt.Sprintf("user_%s", userID)
+			expectedNamespace := nsutil.ForUser(userID)
 			if req.Namespace != expectedNamespace {
 				s.logger.Warn("Attempted cross-namespace access denied",
 					zap.String("user_id", userID),
 					zap.String("requested_namespace", req.Namespace))
-				http.Error(w, "Access denied: you can only access your own namespace", http.StatusForbidden)
+				writeJSONError(w, http.StatusForbidden, "Access denied: you can only access your own namespace", nil)
 				return
 			}
-		} else if strings.HasPrefix(req.Namespace, "group_") {
+		} else if nsutil.IsGroup(req.Namespace) {
 			// Verify group membership
 			isMember, err := s.agent.mkClient.IsWorkspaceMember(r.Context(), req.Namespace, userID)
 			if err != nil {
 				s.logger.Error("Failed to check workspace membership", zap.Error(err))
-				http.Error(w, "Failed to verify workspace access", http.StatusInternalServerError)
+				writeJSONError(w, http.StatusInternalServerError, "Failed to verify workspace access", nil)
 				return
 			}
 			if !isMember {
 				s.logger.Warn("Attempted group access by non-member",
 					zap.String("user_id", userID),
 					zap.String("requested_namespace", req.Namespace))
-				http.Error(w, "You are not a member of this workspace", http.StatusForbidden)
+				writeJSONError(w, http.StatusForbidden, "You are not a member of this workspace", nil)
 				return
 			}
 		} else {
@@ -566,7 +593,7 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 			s.logger.Warn("Invalid namespace format",
 				zap.String("user_id", userID),
 				zap.String("requested_namespace", req.Namespace))
-			http.Error(w, "Invalid namespace format", http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "Invalid namespace format", nil)
 			return
 		}
 		namespace = req.Namespace
@@ -575,11 +602,11 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 		isMember, err := s.agent.mkClient.IsWorkspaceMember(r.Context(), req.ContextID, userID)
 		if err != nil {
 			s.logger.Error("Failed to check workspace membership", zap.Error(err))
-			http.Error(w, "Failed to verify workspace access", http.StatusInternalServerError)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to verify workspace access", nil)
 			return
 		}
 		if !isMember {
-			http.Error(w, "You are not a member of this workspace", http.StatusForbidden)
+			writeJSONError(w, http.StatusForbidden, "You are not a member of this workspace", nil)
 			return
 		}
 		namespace = req.ContextID
@@ -599,11 +626,11 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			s.logger.Warn("Chat timed out", zap.String("user_id", userID))
-			http.Error(w, "Request timed out, please try again", http.StatusGatewayTimeout)
+			writeJSONError(w, http.StatusGatewayTimeout, "Request timed out, please try again", nil)
 			return
 		}
 		s.logger.Error("Chat failed", zap.Error(err))
-		http.Error(w, "Failed to generate response", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to generate response", nil)
 		return
 	}
 
@@ -619,18 +646,18 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
-		http.Error(w, "Query parameter 'q' is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Query parameter 'q' is required", nil)
 		return
 	}
 
 	// Get namespace from user context
 	userID := GetUserID(r.Context())
-	namespace := fmt.Sprintf("user_%s", userID)
+	namespace := nsutil.ForUser(userID)
 
 	nodes, err := s.agent.mkClient.SearchNodes(r.Context(), namespace, query)
 	if err != nil {
 		s.logger.Error("Search failed", zap.Error(err))
-		http.Error(w, "Search failed", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Search failed", nil)
 		return
 	}
 
@@ -638,6 +665,179 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(nodes)
 }
 
+// SimilarRequest asks for memories related to an existing node
+type SimilarRequest struct {
+	UID       string `json:"uid"`
+	Namespace string `json:"namespace,omitempty"` // Defaults to the user's namespace
+	TopK      int    `json:"top_k,omitempty"`     // Default 10, max 50
+}
+
+// handleSimilar returns the nodes nearest to a given node in vector space
+func (s *Server) handleSimilar(w http.ResponseWriter, r *http.Request) {
+	var req SimilarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	if req.UID == "" {
+		writeJSONError(w, http.StatusBadRequest, "uid is required", nil)
+		return
+	}
+
+	userID := GetUserID(r.Context())
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = nsutil.ForUser(userID)
+	}
+
+	// SECURITY: Only search namespaces the user can read
+	if nsutil.IsGroup(namespace) {
+		isMember, err := s.agent.mkClient.IsWorkspaceMember(r.Context(), namespace, userID)
+		if err != nil || !isMember {
+			writeJSONError(w, http.StatusForbidden, "Access denied", nil)
+			return
+		}
+	} else if namespace != nsutil.ForUser(userID) {
+		writeJSONError(w, http.StatusForbidden, "Access denied: you can only access your own namespace", nil)
+		return
+	}
+
+	similar, err := s.agent.mkClient.FindSimilar(r.Context(), namespace, req.UID, req.TopK)
+	switch {
+	case errors.Is(err, graph.ErrNodeNotFound):
+		writeJSONError(w, http.StatusNotFound, "Node not found", nil)
+		return
+	case errors.Is(err, kernel.ErrSimilarUnavailable):
+		writeJSONError(w, http.StatusServiceUnavailable, "Similar search is not available", nil)
+		return
+	case err != nil:
+		s.logger.Error("Similar search failed", zap.String("uid", req.UID), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Similar search failed", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"uid":     req.UID,
+		"results": similar,
+		"count":   len(similar),
+	})
+}
+
+// PruneRequest asks to retire low-value nodes; unset fields use kernel.DefaultPruneOpts
+type PruneRequest struct {
+	Namespace     string   `json:"namespace,omitempty"` // Defaults to the user's namespace
+	MinActivation *float64 `json:"min_activation,omitempty"`
+	MinAgeDays    *int     `json:"min_age_days,omitempty"`
+	RequireEdges  *bool    `json:"require_edges,omitempty"`
+	HardDelete    bool     `json:"hard_delete,omitempty"` // Explicit opt-in; default archives
+	DryRun        bool     `json:"dry_run,omitempty"`
+	Limit         int      `json:"limit,omitempty"`
+}
+
+// handlePrune archives (or deletes) orphaned, zero-activation nodes in a namespace
+func (s *Server) handlePrune(w http.ResponseWriter, r *http.Request) {
+	var req PruneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	userID := GetUserID(r.Context())
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = nsutil.ForUser(userID)
+	}
+
+	// SECURITY: Only the owner, a group admin or a system admin may prune a namespace
+	if GetUserRole(r.Context()) != "admin" {
+		if nsutil.IsGroup(namespace) {
+			isAdmin, err := s.agent.mkClient.IsGroupAdmin(r.Context(), namespace, userID)
+			if err != nil || !isAdmin {
+				writeJSONError(w, http.StatusForbidden, "Only group admins can prune a group", nil)
+				return
+			}
+		} else if namespace != nsutil.ForUser(userID) {
+			writeJSONError(w, http.StatusForbidden, "Access denied: you can only access your own namespace", nil)
+			return
+		}
+	}
+
+	opts := kernel.DefaultPruneOpts()
+	if req.MinActivation != nil {
+		opts.MinActivation = *req.MinActivation
+	}
+	if req.MinAgeDays != nil {
+		opts.MinAgeDays = *req.MinAgeDays
+	}
+	if req.RequireEdges != nil {
+		opts.RequireEdges = *req.RequireEdges
+	}
+	if req.Limit > 0 {
+		opts.Limit = req.Limit
+	}
+	opts.HardDelete = req.HardDelete
+	opts.DryRun = req.DryRun
+	if opts.MinActivation <= 0 || opts.MinAgeDays <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "min_activation and min_age_days must be positive", nil)
+		return
+	}
+
+	result, err := s.agent.mkClient.PruneNamespace(r.Context(), namespace, opts)
+	if err != nil {
+		s.logger.Error("Prune failed", zap.String("namespace", namespace), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Prune failed", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleRecentChanges reports the nodes and edges the memory added or updated
+// in a namespace since a point in time, grouped by day and type
+func (s *Server) handleRecentChanges(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserID(r.Context())
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		namespace = nsutil.ForUser(userID)
+	}
+
+	// SECURITY: Only the owner, a group member or a system admin may read a namespace's changes
+	if GetUserRole(r.Context()) != "admin" {
+		if nsutil.IsGroup(namespace) {
+			isMember, err := s.agent.mkClient.IsWorkspaceMember(r.Context(), namespace, userID)
+			if err != nil || !isMember {
+				writeJSONError(w, http.StatusForbidden, "Access denied", nil)
+				return
+			}
+		} else if namespace != nsutil.ForUser(userID) {
+			writeJSONError(w, http.StatusForbidden, "Access denied: you can only access your own namespace", nil)
+			return
+		}
+	}
+
+	since := time.Now().Add(-kernel.DefaultChangesWindow)
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := kernel.ParseSince(raw)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "since must be RFC3339 or YYYY-MM-DD", nil)
+			return
+		}
+		since = parsed
+	}
+
+	summary, err := s.agent.mkClient.RecentChanges(r.Context(), namespace, since)
+	if err != nil {
+		s.logger.Error("Recent changes query failed", zap.String("namespace", namespace), zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to load changes", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	stats := s.agent.GetStats()
 	w.Header().Set("Content-Type", "application/json")
@@ -682,7 +882,7 @@ func (s *Server) handleConversations(w http.ResponseWriter, r *http.Request) {
 					conversations = append(conversations, ConversationSummary{
 						ID:           parts,
 						Title:        "Chat",
-						Namespace:    fmt.Sprintf("user_%s", userID),
+						Namespace:    nsutil.ForUser(userID),
 						UpdatedAt:    time.Now().Format(time.RFC3339),
 						MessageCount: 0,
 					})
@@ -706,178 +906,180 @@ type UploadResponse struct {
 	Message  string `json:"message"`
 }
 
-// handleUpload handles document upload for ingestion
+// handleUpload streams a document upload to a temp file and ingests it.
+// Clients sending "Accept: text/event-stream" receive progress events while
+// the file arrives, followed by a "complete" event carrying the result.
 func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 	userID := GetUserID(r.Context())
+	maxSize, uploadDir := s.agent.uploadLimits()
 
-	// Parse multipart form (max 32MB)
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
-		http.Error(w, "Failed to parse multipart form", http.StatusBadRequest)
+	// Reject declared oversized bodies before reading anything
+	if r.ContentLength > maxSize+multipartOverhead {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Upload exceeds maximum size of %d bytes", maxSize), nil)
 		return
 	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
 
-	file, header, err := r.FormFile("file")
+	reader, err := r.MultipartReader()
 	if err != nil {
-		http.Error(w, "Missing file in request", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Failed to parse multipart form", nil)
 		return
 	}
-	defer file.Close()
-
-	filename := header.Filename
 
 	// SECURITY: Comprehensive file validation using FileValidator
-	const maxFileSize = 10 << 20 // 10MB
-	validator := NewFileValidator(maxFileSize, true)
-
-	// 1. Validate filename (path traversal, Unicode homographs, control characters, etc.)
-	if err := validator.ValidateFilename(filename); err != nil {
-		s.logger.Warn("Invalid filename rejected",
-			zap.String("filename", filename),
-			zap.Error(err))
-		http.Error(w, fmt.Sprintf("Invalid filename: %v", err), http.StatusBadRequest)
-		return
-	}
+	validator := NewFileValidator(maxSize, true)
+	progress := newUploadProgress(w, r)
+
+	var (
+		sink        *uploadSink
+		filename    string
+		contextType string
+		contextID   string
+	)
+	defer func() {
+		if sink != nil {
+			sink.Abort()
+		}
+	}()
 
-	// 2. Validate file extension is allowed
-	if !validator.IsAllowedExtension(filename) {
-		ext := strings.ToLower(filepath.Ext(filename))
-		s.logger.Warn("File type not allowed",
-			zap.String("filename", filename),
-			zap.String("extension", ext))
-		http.Error(w, fmt.Sprintf("File type '%s' is not allowed", ext), http.StatusBadRequest)
-		return
-	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			status, msg := uploadError(err)
+			progress.Error(status, msg)
+			return
+		}
 
-	// 3. Validate file size
-	if err := validator.ValidateFileSize(header.Size); err != nil {
-		s.logger.Warn("File size validation failed",
-			zap.String("filename", filename),
-			zap.Int64("size", header.Size),
-			zap.Error(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
+		switch part.FormName() {
+		case "context_type":
+			contextType = readFormValue(part)
+		case "context_id":
+			contextID = readFormValue(part)
+		case "file":
+			if sink != nil {
+				progress.Error(http.StatusBadRequest, "Only one file may be uploaded per request")
+				return
+			}
+			filename = part.FileName()
+
+			// 1. Validate filename (path traversal, Unicode homographs, control characters, etc.)
+			if err := validator.ValidateFilename(filename); err != nil {
+				s.logger.Warn("Invalid filename rejected",
+					zap.String("filename", filename),
+					zap.Error(err))
+				progress.Error(http.StatusBadRequest, fmt.Sprintf("Invalid filename: %v", err))
+				return
+			}
 
-	// 4. Read file content with size limit
-	content, err := io.ReadAll(io.LimitReader(file, maxFileSize))
-	if err != nil {
-		s.logger.Error("Failed to read file", zap.Error(err))
-		http.Error(w, "Failed to read file", http.StatusInternalServerError)
-		return
-	}
+			// 2. Validate file extension is allowed
+			if !validator.IsAllowedExtension(filename) {
+				ext := strings.ToLower(filepath.Ext(filename))
+				s.logger.Warn("File type not allowed",
+					zap.String("filename", filename),
+					zap.String("extension", ext))
+				progress.Error(http.StatusBadRequest, fmt.Sprintf("File type '%s' is not allowed", ext))
+				return
+			}
 
-	// 5. Validate file content matches declared type (magic number check)
-	if err := validator.ValidateFileContent(content, filename); err != nil {
-		s.logger.Warn("File content validation failed",
-			zap.String("filename", filename),
-			zap.Error(err))
-		http.Error(w, fmt.Sprintf("File validation failed: %v", err), http.StatusBadRequest)
-		return
+			// 3. Stream to disk; size cap, magic number and malware scan apply as bytes arrive
+			limit := validator.GetMaxSizeForType(filename)
+			if limit > maxSize {
+				limit = maxSize
+			}
+			sink, err = newUploadSink(uploadDir, filename, validator, limit, progress.Progress)
+			if err != nil {
+				s.logger.Error("Failed to stage upload", zap.Error(err))
+				progress.Error(http.StatusInternalServerError, "Failed to store upload")
+				return
+			}
+			if _, err := io.Copy(sink, part); err == nil {
+				err = sink.Finish()
+			}
+			if err != nil {
+				status, msg := uploadError(err)
+				s.logger.Warn("Upload rejected",
+					zap.String("filename", filename),
+					zap.Int64("received", sink.written),
+					zap.Error(err))
+				progress.Error(status, msg)
+				return
+			}
+		}
+		part.Close()
 	}
 
-	// 6. Scan for malware and suspicious content
-	if err := validator.ScanForMalware(content, filename); err != nil {
-		s.logger.Warn("File rejected by security scan",
-			zap.String("filename", filename),
-			zap.Error(err))
-		http.Error(w, fmt.Sprintf("File rejected by security scan: %v", err), http.StatusBadRequest)
+	if sink == nil {
+		progress.Error(http.StatusBadRequest, "Missing file in request")
 		return
 	}
+	size := sink.written
 
 	s.logger.Info("Document upload validated successfully",
 		zap.String("user", userID),
 		zap.String("filename", filename),
-		zap.Int64("size", header.Size))
+		zap.Int64("size", size))
 
 	// Get namespace for user
-	namespace := fmt.Sprintf("user_%s", userID)
-	if contextType := r.FormValue("context_type"); contextType == "group" {
-		if contextID := r.FormValue("context_id"); contextID != "" {
-			namespace = contextID
-		}
+	namespace := nsutil.ForUser(userID)
+	if contextType == "group" && contextID != "" {
+		namespace = contextID
 	}
 
 	// Process document via AI services - Vector-Native Ingestion
-	entities := 0
-	chunks := 0
-	relationships := 0
-
 	if s.agent.aiClient == nil {
 		s.logger.Warn("aiClient is nil, cannot ingest document")
-	} else {
-		// Call AI service /ingest endpoint for Vector-Native processing
-		type IngestRequest struct {
-			Text         string `json:"text"`
-			DocumentType string `json:"document_type"`
-		}
+		progress.Error(http.StatusServiceUnavailable, "Document processing is not available")
+		return
+	}
+	result, err := s.agent.aiClient.ingestUpload(sink.Path(), filename)
+	if err != nil {
+		s.logger.Warn("AI ingest failed", zap.String("filename", filename), zap.Error(err))
+		progress.Error(http.StatusBadGateway, "Failed to process document")
+		return
+	}
+	entities := len(result.Entities)
+	chunks := len(result.Chunks)
 
-		ingestReq := IngestRequest{
-			Text:         string(content),
-			DocumentType: "text",
-		}
+	s.logger.Info("Document ingested with Vector-Native processing",
+		zap.Int("entities", entities),
+		zap.Int("relationships", len(result.Relationships)),
+		zap.Int("chunks", chunks),
+		zap.String("filename", filename))
 
-		reqBody, _ := json.Marshal(ingestReq)
-		resp, err := s.agent.aiClient.httpClient.Post(
-			s.agent.aiClient.baseURL+"/ingest",
-			"application/json",
-			bytes.NewReader(reqBody),
-		)
-		if err != nil {
-			s.logger.Warn("AI ingest request failed", zap.Error(err))
-		} else if resp.StatusCode == 200 {
-			defer resp.Body.Close()
-
-			// Parse ingest response
-			// Parse ingest response
-			var result struct {
-				Entities      []graph.ExtractedEntity `json:"entities"`
-				Relationships []interface{}           `json:"relationships"`
-				Chunks        []graph.DocumentChunk   `json:"chunks"`
-				Stats         map[string]interface{}  `json:"stats"`
-				Summary       string                  `json:"summary"`
-				VectorTree    interface{}             `json:"vector_tree"`
-			}
-			if decodeErr := json.NewDecoder(resp.Body).Decode(&result); decodeErr == nil {
-				entities = len(result.Entities)
-				relationships = len(result.Relationships)
-				chunks = len(result.Chunks)
-
-				s.logger.Info("Document ingested with Vector-Native processing",
-					zap.Int("entities", entities),
-					zap.Int("relationships", relationships),
-					zap.Int("chunks", chunks),
-					zap.String("filename", filename))
-
-				// Persist Extracted Data
-				ctx := context.Background()
-				// Use filename as "conversation ID" context for now
-				docContextID := fmt.Sprintf("doc_%s", filename)
-
-				// 1. Persist Entities to DGraph
-				if len(result.Entities) > 0 {
-					if err := s.agent.mkClient.PersistEntities(ctx, namespace, userID, docContextID, result.Entities); err != nil {
-						s.logger.Error("Failed to persist entities", zap.Error(err))
-					} else {
-						s.logger.Info("Persisted entities to DGraph", zap.Int("count", len(result.Entities)))
-					}
-				}
+	// Persist Extracted Data
+	ctx := context.Background()
+	// Use filename as "conversation ID" context for now
+	docContextID := fmt.Sprintf("doc_%s", filename)
 
-				// 2. Persist Chunks to Qdrant
-				if len(result.Chunks) > 0 {
-					// Use a unique docID for chunk namespacing
-					docID := fmt.Sprintf("doc_%d_%s", time.Now().Unix(), filename)
-					if err := s.agent.mkClient.PersistChunks(ctx, namespace, docID, result.Chunks); err != nil {
-						s.logger.Error("Failed to persist chunks", zap.Error(err))
-					} else {
-						s.logger.Info("Persisted chunks to Qdrant", zap.Int("count", len(result.Chunks)))
-					}
-				}
+	// 1. Persist Entities to DGraph
+	if len(result.Entities) > 0 {
+		if err := s.agent.mkClient.PersistEntities(ctx, namespace, userID, docContextID, result.Entities); err != nil {
+			s.logger.Error("Failed to persist entities", zap.Error(err))
+		} else {
+			s.logger.Info("Persisted entities to DGraph", zap.Int("count", len(result.Entities)))
+		}
+	}
 
-			} else {
-				s.logger.Warn("Failed to decode ingest response", zap.Error(decodeErr))
-			}
+	// 2. Persist Chunks to Qdrant
+	if len(result.Chunks) > 0 {
+		// Use a unique docID for chunk namespacing
+		docID := fmt.Sprintf("doc_%d_%s", time.Now().Unix(), filename)
+		if err := s.agent.mkClient.PersistChunks(ctx, namespace, docID, result.Chunks); err != nil {
+			s.logger.Error("Failed to persist chunks", zap.Error(err))
 		} else {
-			s.logger.Warn("AI ingest returned non-200", zap.Int("status", resp.StatusCode))
+			s.logger.Info("Persisted chunks to Qdrant", zap.Int("count", len(result.Chunks)))
+		}
+	}
+
+	// 3. Persist the Vector Tree to Qdrant, so it isn't rebuilt or re-embedded
+	if len(result.VectorTree) > 0 {
+		if stored, err := s.agent.mkClient.PersistVectorTree(ctx, namespace, result.VectorTree); err != nil {
+			s.logger.Error("Failed to persist vector tree", zap.Error(err))
+		} else {
+			s.logger.Info("Persisted vector tree to Qdrant", zap.Int("nodes", stored))
 		}
 	}
 
@@ -887,11 +1089,10 @@ func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 		zap.String("namespace", namespace),
 		zap.Int("entities", entities))
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(UploadResponse{
+	progress.Done(UploadResponse{
 		Status:   "success",
 		Filename: filename,
-		Size:     header.Size,
+		Size:     size,
 		Entities: entities,
 		Message:  fmt.Sprintf("Document '%s' uploaded and processed (%d entities, %d chunks)", filename, entities, chunks),
 	})
@@ -922,14 +1123,14 @@ func (s *Server) handleListDocuments(w http.ResponseWriter, r *http.Request) {
 	// Determine namespace (default to user's namespace)
 	namespace := r.URL.Query().Get("namespace")
 	if namespace == "" {
-		namespace = fmt.Sprintf("user_%s", userID)
+		namespace = nsutil.ForUser(userID)
 	}
 
 	// SECURITY: For group namespaces, verify user is a member
-	if strings.HasPrefix(namespace, "group_") {
+	if nsutil.IsGroup(namespace) {
 		isMember, err := s.agent.mkClient.IsWorkspaceMember(ctx, namespace, userID)
 		if err != nil || !isMember {
-			http.Error(w, "Access denied", http.StatusForbidden)
+			writeJSONError(w, http.StatusForbidden, "Access denied", nil)
 			return
 		}
 	}
@@ -948,7 +1149,7 @@ func (s *Server) handleListDocuments(w http.ResponseWriter, r *http.Request) {
 	resp, err := s.agent.mkClient.GetGraphClient().Query(ctx, query, map[string]string{"$namespace": namespace})
 	if err != nil {
 		s.logger.Error("Failed to query documents", zap.Error(err))
-		http.Error(w, "Failed to query documents", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to query documents", nil)
 		return
 	}
 
@@ -963,7 +1164,7 @@ func (s *Server) handleListDocuments(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := json.Unmarshal(resp, &result); err != nil {
 		s.logger.Error("Failed to unmarshal documents", zap.Error(err))
-		http.Error(w, "Failed to parse documents", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to parse documents", nil)
 		return
 	}
 
@@ -1002,7 +1203,7 @@ func (s *Server) handleDeleteDocument(w http.ResponseWriter, r *http.Request) {
 	documentUID := vars["id"]
 
 	if documentUID == "" {
-		http.Error(w, "Document ID is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Document ID is required", nil)
 		return
 	}
 
@@ -1012,36 +1213,34 @@ func (s *Server) handleDeleteDocument(w http.ResponseWriter, r *http.Request) {
 	node, err := s.agent.mkClient.GetGraphClient().GetNode(ctx, documentUID)
 	if err != nil {
 		s.logger.Error("Failed to get document", zap.Error(err))
-		http.Error(w, "Document not found", http.StatusNotFound)
+		writeJSONError(w, http.StatusNotFound, "Document not found", nil)
 		return
 	}
 
 	// SECURITY: Verify the user owns this document (namespace check)
-	expectedNamespace := fmt.Sprintf("user_%s", userID)
+	expectedNamespace := nsutil.ForUser(userID)
 	if node.Namespace != expectedNamespace {
 		// Also check if it's a group namespace where user is a member
-		if strings.HasPrefix(node.Namespace, "group_") {
+		if nsutil.IsGroup(node.Namespace) {
 			isMember, err := s.agent.mkClient.IsWorkspaceMember(ctx, node.Namespace, userID)
 			if err != nil || !isMember {
-				http.Error(w, "Access denied", http.StatusForbidden)
+				writeJSONError(w, http.StatusForbidden, "Access denied", nil)
 				return
 			}
 		} else {
-			http.Error(w, "Access denied", http.StatusForbidden)
+			writeJSONError(w, http.StatusForbidden, "Access denied", nil)
 			return
 		}
 	}
 
-	// Delete the document node (this cascades to delete edges)
-	if err := s.agent.mkClient.GetGraphClient().DeleteNode(ctx, documentUID, node.Namespace); err != nil {
+	// Forget the document node: deletes it (and its edges) from the graph and
+	// purges its vector and any cached text mentioning it
+	if _, err := s.agent.mkClient.Forget(ctx, node.Namespace, documentUID); err != nil {
 		s.logger.Error("Failed to delete document", zap.Error(err))
-		http.Error(w, "Failed to delete document", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete document", nil)
 		return
 	}
 
-	// Note: Chunks in Qdrant would need to be deleted separately in a production system
-	// For now, the graph node deletion removes the document from the knowledge graph
-
 	s.logger.Info("Document deleted",
 		zap.String("document_id", documentUID),
 		zap.String("document_name", node.Name),
@@ -1107,13 +1306,13 @@ func (s *Server) handleWSConnection(conn *websocket.Conn, userID, conversationID
 			}
 
 			// Determine Namespace
-			namespace := fmt.Sprintf("user_%s", userID)
+			namespace := nsutil.ForUser(userID)
 			if payload.ContextType == "group" && payload.ContextID != "" {
 				namespace = payload.ContextID
 			}
 
 			// SECURITY: Verify user has access to group namespace
-			if strings.HasPrefix(namespace, "group_") {
+			if nsutil.IsGroup(namespace) {
 				isMember, err := s.agent.mkClient.IsWorkspaceMember(context.Background(), namespace, userID)
 				if err != nil {
 					s.logger.Error("Failed to verify workspace membership", zap.Error(err))
@@ -1170,13 +1369,13 @@ func (s *Server) handleWSConnection(conn *websocket.Conn, userID, conversationID
 			}
 
 			// Determine Namespace
-			namespace := fmt.Sprintf("user_%s", userID)
+			namespace := nsutil.ForUser(userID)
 			if payload.ContextType == "group" && payload.ContextID != "" {
 				namespace = payload.ContextID
 			}
 
 			// SECURITY: Verify user has access to group namespace
-			if strings.HasPrefix(namespace, "group_") {
+			if nsutil.IsGroup(namespace) {
 				isMember, err := s.agent.mkClient.IsWorkspaceMember(context.Background(), namespace, userID)
 				if err != nil || !isMember {
 					s.logger.Warn("WebSocket typing access denied: user not in workspace",
@@ -1208,52 +1407,85 @@ type ShareRequest struct {
 type CreateGroupRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// Idempotent returns the caller's existing same-named group instead of creating a duplicate
+	Idempotent bool `json:"idempotent"`
 }
 
 // CreateGroupResponse represents the response for group creation
 type CreateGroupResponse struct {
 	GroupID   string `json:"group_id"`
 	Namespace string `json:"namespace"`
+	Existing  bool   `json:"existing,omitempty"` // true when an idempotent request matched an existing group
 }
 
 func (s *Server) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
 	userID := GetUserID(r.Context())
 	if userID == "anonymous" {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeJSONError(w, http.StatusUnauthorized, "Unauthorized", nil)
 		return
 	}
 
 	var req CreateGroupRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	// SECURITY: Validate group name to prevent injection and other attacks
+	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		http.Error(w, "Group name is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Group name is required", nil)
 		return
 	}
 	// Limit name length to prevent abuse
 	if len(req.Name) > 100 {
-		http.Error(w, "Group name must be 100 characters or less", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Group name must be 100 characters or less", nil)
 		return
 	}
 	// Check for suspicious patterns (potential injection)
 	if strings.ContainsAny(req.Name, "\x00\n\r<>\"'`)") {
-		http.Error(w, "Group name contains invalid characters", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Group name contains invalid characters", nil)
 		return
 	}
 	// Validate description
 	if len(req.Description) > 500 {
-		http.Error(w, "Description must be 500 characters or less", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Description must be 500 characters or less", nil)
 		return
 	}
 
+	if req.Idempotent {
+		// Serialize same-name creations so a double-click can't race past the lookup
+		if s.groupLock != nil {
+			lockKey := fmt.Sprintf("%s:%s", userID, strings.ToLower(req.Name))
+			lock, err := s.groupLock.AcquireGroupLock(r.Context(), lockKey, "create")
+			if err != nil {
+				writeJSONError(w, http.StatusConflict, "Group creation already in progress", nil)
+				return
+			}
+			defer lock.Release()
+		}
+
+		existing, err := s.agent.mkClient.FindOwnedGroupByName(r.Context(), userID, req.Name)
+		if err != nil {
+			s.logger.Error("Failed to check for existing group", zap.Error(err))
+			writeJSONError(w, http.StatusInternalServerError, "Failed to create group", nil)
+			return
+		}
+		if existing != nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(CreateGroupResponse{
+				GroupID:   existing.Namespace,
+				Namespace: existing.Namespace,
+				Existing:  true,
+			})
+			return
+		}
+	}
+
 	namespace, err := s.agent.mkClient.CreateGroup(r.Context(), req.Name, req.Description, userID)
 	if err != nil {
 		s.logger.Error("Failed to create group", zap.Error(err))
-		http.Error(w, "Failed to create group", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to create group", nil)
 		return
 	}
 
@@ -1276,7 +1508,7 @@ func (s *Server) handleAddGroupMember(w http.ResponseWriter, r *http.Request) {
 
 	var req AddMemberRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -1300,11 +1532,11 @@ func (s *Server) handleAddGroupMember(w http.ResponseWriter, r *http.Request) {
 	isAdmin, err := s.agent.mkClient.IsGroupAdmin(r.Context(), groupNamespace, userID)
 	if err != nil {
 		s.logger.Error("Failed to check admin status", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if !isAdmin {
-		http.Error(w, "Only admins can add members", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "Only admins can add members", nil)
 		return
 	}
 
@@ -1312,11 +1544,11 @@ func (s *Server) handleAddGroupMember(w http.ResponseWriter, r *http.Request) {
 	exists, err := s.agent.RedisClient.Exists(r.Context(), "user:"+req.Username).Result()
 	if err != nil {
 		s.logger.Error("Failed to check user existence", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if exists == 0 {
-		http.Error(w, "User not found", http.StatusBadRequest) // Generic message to prevent enumeration
+		writeJSONError(w, http.StatusBadRequest, "User not found", nil) // Generic message to prevent enumeration
 		return
 	}
 
@@ -1327,14 +1559,14 @@ func (s *Server) handleAddGroupMember(w http.ResponseWriter, r *http.Request) {
 	}
 	if err := s.agent.mkClient.EnsureUserNode(r.Context(), req.Username, userRole); err != nil {
 		s.logger.Error("Failed to ensure user node", zap.Error(err))
-		http.Error(w, "Failed to prepare user", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to prepare user", nil)
 		return
 	}
 
 	// 4. Add Member
 	if err := s.agent.mkClient.AddGroupMember(r.Context(), groupNamespace, req.Username); err != nil {
 		s.logger.Error("Failed to add member", zap.Error(err))
-		http.Error(w, "Failed to add member", http.StatusInternalServerError) // Generic message
+		writeJSONError(w, http.StatusInternalServerError, "Failed to add member", nil) // Generic message
 		return
 	}
 
@@ -1348,7 +1580,7 @@ func (s *Server) handleListGroups(w http.ResponseWriter, r *http.Request) {
 	groups, err := s.agent.mkClient.ListGroups(r.Context(), userID)
 	if err != nil {
 		s.logger.Error("Failed to list groups", zap.Error(err))
-		http.Error(w, "Failed to list groups", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to list groups", nil)
 		return
 	}
 
@@ -1361,7 +1593,7 @@ func (s *Server) handleListGroups(w http.ResponseWriter, r *http.Request) {
 // handleListUsers returns all registered users (for invitation/member selection)
 func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
 	if s.agent.RedisClient == nil {
-		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		writeJSONError(w, http.StatusServiceUnavailable, "Service unavailable", nil)
 		return
 	}
 
@@ -1371,7 +1603,7 @@ func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
 	keys, err := s.agent.RedisClient.Keys(ctx, "user:*").Result()
 	if err != nil {
 		s.logger.Error("Failed to fetch users", zap.Error(err))
-		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to fetch users", nil)
 		return
 	}
 
@@ -1423,7 +1655,7 @@ func (s *Server) handleRemoveGroupMember(w http.ResponseWriter, r *http.Request)
 	isAdmin, err := s.agent.mkClient.IsGroupAdmin(r.Context(), groupID, userID)
 	if err != nil {
 		s.logger.Error("Failed to check admin status", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 
@@ -1434,7 +1666,7 @@ func (s *Server) handleRemoveGroupMember(w http.ResponseWriter, r *http.Request)
 				zap.String("actor", userID),
 				zap.String("target", targetUser),
 				zap.String("group", groupID))
-			http.Error(w, "Forbidden: Only admins can remove other members", http.StatusForbidden)
+			writeJSONError(w, http.StatusForbidden, "Forbidden: Only admins can remove other members", nil)
 			return
 		}
 		// User is removing themselves (leaving group) - continue
@@ -1451,7 +1683,7 @@ func (s *Server) handleRemoveGroupMember(w http.ResponseWriter, r *http.Request)
 				}
 			}
 			if adminCount <= 1 {
-				http.Error(w, "Cannot leave: you are the last admin", http.StatusBadRequest)
+				writeJSONError(w, http.StatusBadRequest, "Cannot leave: you are the last admin", nil)
 				return
 			}
 		}
@@ -1459,7 +1691,7 @@ func (s *Server) handleRemoveGroupMember(w http.ResponseWriter, r *http.Request)
 
 	if err := s.agent.mkClient.RemoveGroupMember(r.Context(), groupID, targetUser); err != nil {
 		s.logger.Error("Failed to remove member", zap.Error(err))
-		http.Error(w, "Failed to remove member", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to remove member", nil)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -1492,18 +1724,188 @@ func (s *Server) handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
 			zap.String("user", userID),
 			zap.String("role", userRole),
 			zap.String("group", groupID))
-		http.Error(w, "Forbidden: Admin access required", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "Forbidden: Admin access required", nil)
 		return
 	}
 
 	if err := s.agent.mkClient.DeleteGroup(ctx, groupID, userID); err != nil {
 		s.logger.Error("Failed to delete group", zap.Error(err))
-		http.Error(w, "Failed to delete group", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete group", nil)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
+// ownsConversation reports whether userID owns the conversation: either it is
+// tracked under the user's conversation keys or the user created one of its shares
+func (s *Server) ownsConversation(ctx context.Context, userID, conversationID string, shares []graph.ConversationShare) bool {
+	for _, share := range shares {
+		if share.SharedBy != "" && share.SharedBy == userID {
+			return true
+		}
+	}
+	if s.agent.RedisClient != nil {
+		n, err := s.agent.RedisClient.Exists(ctx, fmt.Sprintf("conv:%s:%s", userID, conversationID)).Result()
+		if err == nil && n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// handleGetConversationShares lists the workspaces a conversation is shared to.
+// Owners see every share; workspace admins see shares to workspaces they administer.
+// GET /api/conversations/{id}/shares
+func (s *Server) handleGetConversationShares(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+	conversationID := mux.Vars(r)["id"]
+
+	shares, err := s.agent.mkClient.GetConversationShares(ctx, conversationID)
+	if err != nil {
+		s.logger.Error("Failed to get conversation shares", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to get conversation shares", nil)
+		return
+	}
+
+	visible := shares
+	if !s.ownsConversation(ctx, userID, conversationID, shares) {
+		visible = make([]graph.ConversationShare, 0, len(shares))
+		for _, share := range shares {
+			isAdmin, err := s.agent.mkClient.IsGroupAdmin(ctx, share.GroupNamespace, userID)
+			if err == nil && isAdmin {
+				visible = append(visible, share)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"conversation_id": conversationID,
+		"shares":          visible,
+		"count":           len(visible),
+	})
+}
+
+// handleShareConversation shares a conversation to a workspace, recording the
+// caller as the sharer. Only the conversation owner may share it, and only to
+// a workspace they belong to.
+// POST /api/conversations/{id}/shares
+func (s *Server) handleShareConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+	conversationID := mux.Vars(r)["id"]
+
+	var req struct {
+		Namespace string `json:"namespace"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Namespace == "" {
+		writeJSONError(w, http.StatusBadRequest, "namespace is required", nil)
+		return
+	}
+
+	shares, err := s.agent.mkClient.GetConversationShares(ctx, conversationID)
+	if err != nil {
+		s.logger.Error("Failed to get conversation shares", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to share conversation", nil)
+		return
+	}
+
+	// SECURITY: Conversation owner AND workspace member
+	isAuthorized := s.ownsConversation(ctx, userID, conversationID, shares)
+	if isAuthorized {
+		isMember, err := s.agent.mkClient.IsWorkspaceMember(ctx, req.Namespace, userID)
+		isAuthorized = err == nil && isMember
+	}
+	if !isAuthorized {
+		s.logger.Warn("Unauthorized share attempt",
+			zap.String("user", userID),
+			zap.String("conversation_id", conversationID),
+			zap.String("group", req.Namespace))
+		writeJSONError(w, http.StatusForbidden, "access denied", nil)
+		return
+	}
+
+	for _, share := range shares {
+		if share.GroupNamespace == req.Namespace {
+			writeJSONError(w, http.StatusConflict, "Conversation already shared to this workspace", nil)
+			return
+		}
+	}
+
+	if err := s.agent.mkClient.ShareToGroup(ctx, conversationID, req.Namespace, userID); err != nil {
+		s.logger.Error("Failed to share conversation", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to share conversation", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":         true,
+		"conversation_id": conversationID,
+		"namespace":       req.Namespace,
+	})
+}
+
+// handleUnshareConversation revokes a conversation share from a workspace.
+// Only the conversation owner or an admin of the workspace may unshare.
+// DELETE /api/conversations/{id}/shares/{namespace}
+func (s *Server) handleUnshareConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+	vars := mux.Vars(r)
+	conversationID := vars["id"]
+	groupNamespace := vars["namespace"]
+
+	shares, err := s.agent.mkClient.GetConversationShares(ctx, conversationID)
+	if err != nil {
+		s.logger.Error("Failed to get conversation shares", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to unshare conversation", nil)
+		return
+	}
+
+	// SECURITY: Conversation owner OR workspace admin
+	isAuthorized := s.ownsConversation(ctx, userID, conversationID, shares)
+	if !isAuthorized {
+		isGroupAdmin, err := s.agent.mkClient.IsGroupAdmin(ctx, groupNamespace, userID)
+		isAuthorized = err == nil && isGroupAdmin
+	}
+	if !isAuthorized {
+		s.logger.Warn("Unauthorized unshare attempt",
+			zap.String("user", userID),
+			zap.String("conversation_id", conversationID),
+			zap.String("group", groupNamespace))
+		writeJSONError(w, http.StatusForbidden, "access denied", nil)
+		return
+	}
+
+	isShared := false
+	for _, share := range shares {
+		if share.GroupNamespace == groupNamespace {
+			isShared = true
+			break
+		}
+	}
+	if !isShared {
+		writeJSONError(w, http.StatusNotFound, "Share not found", nil)
+		return
+	}
+
+	if err := s.agent.mkClient.UnshareFromGroup(ctx, conversationID, groupNamespace); err != nil {
+		s.logger.Error("Failed to unshare conversation", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to unshare conversation", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":         true,
+		"conversation_id": conversationID,
+		"namespace":       groupNamespace,
+	})
+}
+
 // handleGetGroupMembers returns the members of a group
 func (s *Server) handleGetGroupMembers(w http.ResponseWriter, r *http.Request) {
 	userID := GetUserID(r.Context())
@@ -1514,7 +1916,7 @@ func (s *Server) handleGetGroupMembers(w http.ResponseWriter, r *http.Request) {
 	groups, err := s.agent.mkClient.ListGroups(r.Context(), userID)
 	if err != nil {
 		s.logger.Error("Failed to check group membership", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 
@@ -1531,7 +1933,7 @@ func (s *Server) handleGetGroupMembers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if !isMember {
-		http.Error(w, "Forbidden: You are not a member of this group", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "Forbidden: You are not a member of this group", nil)
 		return
 	}
 
@@ -1539,7 +1941,7 @@ func (s *Server) handleGetGroupMembers(w http.ResponseWriter, r *http.Request) {
 	members, err := s.agent.mkClient.GetGroupMembers(r.Context(), groupID)
 	if err != nil {
 		s.logger.Error("Failed to get group members", zap.Error(err))
-		http.Error(w, "Failed to get group members", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to get group members", nil)
 		return
 	}
 
@@ -1563,13 +1965,13 @@ func (s *Server) handleCreateSubuser(w http.ResponseWriter, r *http.Request) {
 	// 1. Verify Admin
 	isAdmin, err := s.agent.mkClient.IsGroupAdmin(r.Context(), groupID, userID)
 	if err != nil || !isAdmin {
-		http.Error(w, "Forbidden: Only admins can create subusers", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "Forbidden: Only admins can create subusers", nil)
 		return
 	}
 
 	var req CreateSubuserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request", nil)
 		return
 	}
 
@@ -1578,13 +1980,13 @@ func (s *Server) handleCreateSubuser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	exists, err := s.agent.RedisClient.Exists(ctx, "user:"+req.Username).Result()
 	if exists > 0 {
-		http.Error(w, "Username already taken", http.StatusConflict)
+		writeJSONError(w, http.StatusConflict, "Username already taken", nil)
 		return // Or handle as "Add existing user" if desired, but request implies creation
 	}
 
 	hashedPassword, _ := HashPassword(req.Password)
 	if err := s.agent.RedisClient.Set(ctx, "user:"+req.Username, hashedPassword, 0).Err(); err != nil {
-		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to create user", nil)
 		return
 	}
 
@@ -1600,7 +2002,7 @@ func (s *Server) handleCreateSubuser(w http.ResponseWriter, r *http.Request) {
 	// 4. Add to Group
 	if err := s.agent.mkClient.AddGroupMember(ctx, groupID, req.Username); err != nil {
 		s.logger.Error("Failed to add subuser to group", zap.Error(err))
-		http.Error(w, "User created but failed to join group", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "User created but failed to join group", nil)
 		return
 	}
 
@@ -1634,22 +2036,22 @@ func (s *Server) handleInviteToWorkspace(w http.ResponseWriter, r *http.Request)
 	isAdmin, err := s.agent.mkClient.IsGroupAdmin(r.Context(), workspaceNS, userID)
 	if err != nil {
 		s.logger.Error("Failed to check admin status", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if !isAdmin {
-		http.Error(w, "Only admins can invite users", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "Only admins can invite users", nil)
 		return
 	}
 
 	var req InviteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if req.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Username is required", nil)
 		return
 	}
 
@@ -1662,11 +2064,11 @@ func (s *Server) handleInviteToWorkspace(w http.ResponseWriter, r *http.Request)
 	exists, err := s.agent.RedisClient.Exists(r.Context(), "user:"+req.Username).Result()
 	if err != nil {
 		s.logger.Error("Failed to check user existence in Redis", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if exists == 0 {
-		http.Error(w, fmt.Sprintf("User '%s' not found", req.Username), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("User '%s' not found", req.Username), nil)
 		return
 	}
 
@@ -1679,14 +2081,14 @@ func (s *Server) handleInviteToWorkspace(w http.ResponseWriter, r *http.Request)
 	// Ensure user node exists in DGraph before creating invitation
 	if err := s.agent.mkClient.EnsureUserNode(r.Context(), req.Username, userRole); err != nil {
 		s.logger.Error("Failed to ensure user node", zap.Error(err), zap.String("username", req.Username))
-		http.Error(w, "Failed to prepare user for invitation", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to prepare user for invitation", nil)
 		return
 	}
 
 	invite, err := s.agent.mkClient.InviteToWorkspace(r.Context(), workspaceNS, userID, req.Username, req.Role)
 	if err != nil {
 		s.logger.Error("Failed to create invitation", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
 
@@ -1705,7 +2107,7 @@ func (s *Server) handleGetPendingInvitations(w http.ResponseWriter, r *http.Requ
 	invitations, err := s.agent.mkClient.GetPendingInvitations(r.Context(), userID)
 	if err != nil {
 		s.logger.Error("Failed to get invitations", zap.Error(err))
-		http.Error(w, "Failed to get invitations", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to get invitations", nil)
 		return
 	}
 
@@ -1724,18 +2126,18 @@ func (s *Server) handleGetWorkspaceSentInvitations(w http.ResponseWriter, r *htt
 	isMember, err := s.agent.mkClient.IsWorkspaceMember(r.Context(), workspaceNS, userID)
 	if err != nil {
 		s.logger.Error("Failed to check membership", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if !isMember {
-		http.Error(w, "You are not a member of this workspace", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "You are not a member of this workspace", nil)
 		return
 	}
 
 	invitations, err := s.agent.mkClient.GetWorkspaceSentInvitations(r.Context(), workspaceNS)
 	if err != nil {
 		s.logger.Error("Failed to get workspace invitations", zap.Error(err))
-		http.Error(w, "Failed to get invitations", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to get invitations", nil)
 		return
 	}
 
@@ -1752,7 +2154,7 @@ func (s *Server) handleAcceptInvitation(w http.ResponseWriter, r *http.Request)
 
 	if err := s.agent.mkClient.AcceptInvitation(r.Context(), invitationID, userID); err != nil {
 		s.logger.Error("Failed to accept invitation", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
 
@@ -1770,7 +2172,7 @@ func (s *Server) handleDeclineInvitation(w http.ResponseWriter, r *http.Request)
 
 	if err := s.agent.mkClient.DeclineInvitation(r.Context(), invitationID, userID); err != nil {
 		s.logger.Error("Failed to decline invitation", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
 
@@ -1803,11 +2205,11 @@ func (s *Server) handleCreateShareLink(w http.ResponseWriter, r *http.Request) {
 	isAdmin, err := s.agent.mkClient.IsGroupAdmin(r.Context(), workspaceNS, userID)
 	if err != nil {
 		s.logger.Error("Failed to check admin status", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if !isAdmin {
-		http.Error(w, "Only admins can create share links", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "Only admins can create share links", nil)
 		return
 	}
 
@@ -1826,7 +2228,7 @@ func (s *Server) handleCreateShareLink(w http.ResponseWriter, r *http.Request) {
 	link, err := s.agent.mkClient.CreateShareLink(r.Context(), workspaceNS, userID, req.MaxUses, expiresAt)
 	if err != nil {
 		s.logger.Error("Failed to create share link", zap.Error(err))
-		http.Error(w, "Failed to create share link", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to create share link", nil)
 		return
 	}
 
@@ -1848,7 +2250,7 @@ func (s *Server) handleCreateShareLink(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleJoinViaShareLink(w http.ResponseWriter, r *http.Request) {
 	userID := GetUserID(r.Context())
 	if userID == "anonymous" {
-		http.Error(w, "Authentication required to join via share link", http.StatusUnauthorized)
+		writeJSONError(w, http.StatusUnauthorized, "Authentication required to join via share link", nil)
 		return
 	}
 
@@ -1858,7 +2260,7 @@ func (s *Server) handleJoinViaShareLink(w http.ResponseWriter, r *http.Request)
 	link, err := s.agent.mkClient.JoinViaShareLink(r.Context(), token, userID)
 	if err != nil {
 		s.logger.Error("Failed to join via share link", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
 
@@ -1877,7 +2279,7 @@ func (s *Server) handleRevokeShareLink(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.agent.mkClient.RevokeShareLink(r.Context(), token, userID); err != nil {
 		s.logger.Error("Failed to revoke share link", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
 		return
 	}
 
@@ -1887,6 +2289,12 @@ func (s *Server) handleRevokeShareLink(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+const (
+	// defaultMembersPageSize and maxMembersPageSize bound /workspaces/{id}/members pages
+	defaultMembersPageSize = 50
+	maxMembersPageSize     = 500
+)
+
 func (s *Server) handleGetWorkspaceMembers(w http.ResponseWriter, r *http.Request) {
 	userID := GetUserID(r.Context())
 	vars := mux.Vars(r)
@@ -1896,34 +2304,49 @@ func (s *Server) handleGetWorkspaceMembers(w http.ResponseWriter, r *http.Reques
 	isMember, err := s.agent.mkClient.IsWorkspaceMember(r.Context(), workspaceNS, userID)
 	if err != nil {
 		s.logger.Error("Failed to check membership", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 	if !isMember {
-		http.Error(w, "You are not a member of this workspace", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "You are not a member of this workspace", nil)
 		return
 	}
 
-	members, err := s.agent.mkClient.GetWorkspaceMembers(r.Context(), workspaceNS)
+	offset, limit := 0, defaultMembersPageSize
+	if v, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && v > 0 {
+		offset = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = min(v, maxMembersPageSize)
+	}
+
+	members, total, err := s.agent.mkClient.GetWorkspaceMembers(r.Context(), workspaceNS, offset, limit)
 	if err != nil {
 		s.logger.Error("Failed to get members", zap.Error(err))
-		http.Error(w, "Failed to get members", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to get members", nil)
 		return
 	}
 
-	// Convert to JSON-friendly format
-	var memberList []map[string]interface{}
+	// Convert to JSON-friendly format; joined_at is omitted for memberships
+	// created before join times were recorded
+	memberList := make([]map[string]interface{}, 0, len(members))
 	for _, m := range members {
-		memberList = append(memberList, map[string]interface{}{
-			"username":  m.User.Name,
-			"role":      m.Role,
-			"joined_at": m.JoinedAt,
-		})
+		entry := map[string]interface{}{
+			"username": m.User.Name,
+			"role":     m.Role,
+		}
+		if !m.JoinedAt.IsZero() {
+			entry["joined_at"] = m.JoinedAt
+		}
+		memberList = append(memberList, entry)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"members": memberList,
+		"total":   total,
+		"offset":  offset,
+		"limit":   limit,
 	})
 }
 
@@ -1953,20 +2376,20 @@ func (s *Server) handleRemoveWorkspaceMember(w http.ResponseWriter, r *http.Requ
 	isAdmin, err := s.agent.mkClient.IsGroupAdmin(r.Context(), workspaceNS, userID)
 	if err != nil {
 		s.logger.Error("Failed to check admin status", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Internal server error", nil)
 		return
 	}
 
 	// Allow removing self (leave workspace) or Admin removing others
 	if !isAdmin && userID != targetUser {
-		http.Error(w, "Only admins can remove other members", http.StatusForbidden)
+		writeJSONError(w, http.StatusForbidden, "Only admins can remove other members", nil)
 		return
 	}
 
 	// Prevent admin from removing themselves if they're the only admin
 	// SECURITY: This check is now atomic with removal due to distributed lock
 	if userID == targetUser && isAdmin {
-		members, _ := s.agent.mkClient.GetWorkspaceMembers(r.Context(), workspaceNS)
+		members, _, _ := s.agent.mkClient.GetWorkspaceMembers(r.Context(), workspaceNS, 0, 0)
 		adminCount := 0
 		for _, m := range members {
 			if m.Role == "admin" {
@@ -1974,14 +2397,14 @@ func (s *Server) handleRemoveWorkspaceMember(w http.ResponseWriter, r *http.Requ
 			}
 		}
 		if adminCount <= 1 {
-			http.Error(w, "Cannot leave workspace: you are the only admin", http.StatusBadRequest)
+			writeJSONError(w, http.StatusBadRequest, "Cannot leave workspace: you are the only admin", nil)
 			return
 		}
 	}
 
 	if err := s.agent.mkClient.RemoveGroupMember(r.Context(), workspaceNS, targetUser); err != nil {
 		s.logger.Error("Failed to remove member", zap.Error(err))
-		http.Error(w, "Failed to remove member", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to remove member", nil)
 		return
 	}
 
@@ -1996,6 +2419,13 @@ func (s *Server) handleRemoveWorkspaceMember(w http.ResponseWriter, r *http.Requ
 	})
 }
 
+// rateLimitTier returns the rate limit tier of userID
+func (s *Server) rateLimitTier(userID string) policy.RateLimitTier {
+	// MVP: Default everyone to Pro tier
+	// Future: Look up user tier from Redis/Graph
+	return policy.TierPro
+}
+
 // rateLimitMiddleware applies rate limiting policies
 func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2011,9 +2441,7 @@ func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// MVP: Default everyone to Pro tier
-		// Future: Look up user tier from Redis/Graph
-		tier := policy.TierPro
+		tier := s.rateLimitTier(userID)
 
 		result, err := s.agent.PolicyManager.RateLimiter.Allow(r.Context(), userID, tier, r.URL.Path)
 		if err != nil {
@@ -2025,7 +2453,8 @@ func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
 
 		if !result.Allowed {
 			w.Header().Set("Retry-After", fmt.Sprintf("%.0f", result.RetryAfter.Seconds()))
-			http.Error(w, fmt.Sprintf("Rate limit exceeded. Try again in %s.", result.RetryAfter), 429)
+			writeJSONError(w, http.StatusTooManyRequests, fmt.Sprintf("Rate limit exceeded. Try again in %s.", result.RetryAfter),
+				map[string]interface{}{"retry_after_seconds": int(result.RetryAfter.Seconds())})
 			return
 		}
 
@@ -2075,7 +2504,7 @@ func (s *Server) groupOperationRateLimiter() mux.MiddlewareFunc {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userID := GetUserID(r.Context())
 			if userID == "" {
-				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				writeJSONError(w, http.StatusUnauthorized, "Authentication required", nil)
 				return
 			}
 
@@ -2110,8 +2539,8 @@ func (s *Server) groupOperationRateLimiter() mux.MiddlewareFunc {
 				zap.String("user", userID),
 				zap.Int("count", rl.count))
 
-			http.Error(w, fmt.Sprintf("Too many group operations. Try again in %s.",
-				retryAfter.Round(time.Second)), http.StatusTooManyRequests)
+			writeJSONError(w, http.StatusTooManyRequests, fmt.Sprintf("Too many group operations. Try again in %s.",
+				retryAfter.Round(time.Second)), map[string]interface{}{"retry_after_seconds": int(retryAfter.Seconds())})
 		})
 	}
 }
@@ -2331,7 +2760,7 @@ func (s *Server) handleMCPToolCallInner(ctx context.Context, name string, argume
 		return nil, fmt.Errorf("unauthorized")
 	}
 
-	namespace := fmt.Sprintf("user_%s", userID)
+	namespace := nsutil.ForUser(userID)
 
 	s.logger.Info("MCP tool called",
 		zap.String("tool", name),
@@ -2378,6 +2807,7 @@ func (s *Server) handleMCPToolCallInner(ctx context.Context, name string, argume
 			"facts":     len(response.RelevantFacts),
 			"insights":  len(response.Insights),
 			"confidence": response.Confidence,
+			"degraded_modes": response.DegradedModes,
 		}, nil
 
 	default:
@@ -2402,12 +2832,12 @@ type NIMTestResponse struct {
 func (s *Server) handleTestNIM(w http.ResponseWriter, r *http.Request) {
 	var req NIMTestRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if req.APIKey == "" {
-		http.Error(w, "API key is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "API key is required", nil)
 		return
 	}
 
@@ -2482,7 +2912,7 @@ func (s *Server) handleGetUserSettings(w http.ResponseWriter, r *http.Request) {
 	settings, err := s.agent.mkClient.GetUserSettings(r.Context(), userID)
 	if err != nil {
 		s.logger.Error("Failed to get user settings", zap.Error(err))
-		http.Error(w, "Failed to retrieve settings", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to retrieve settings", nil)
 		return
 	}
 
@@ -2509,7 +2939,7 @@ func (s *Server) handleSaveUserSettings(w http.ResponseWriter, r *http.Request)
 
 	var req UserSettingsRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -2541,13 +2971,13 @@ func (s *Server) handleSaveUserSettings(w http.ResponseWriter, r *http.Request)
 	if req.NimApiKey != "" {
 		if s.crypto == nil {
 			s.logger.Warn("Crypto not initialized, cannot encrypt API key")
-			http.Error(w, "Encryption service unavailable", http.StatusServiceUnavailable)
+			writeJSONError(w, http.StatusServiceUnavailable, "Encryption service unavailable", nil)
 			return
 		}
 		encrypted, err := s.crypto.Encrypt(req.NimApiKey)
 		if err != nil {
 			s.logger.Error("Failed to encrypt NIM API key", zap.Error(err))
-			http.Error(w, "Failed to encrypt API key", http.StatusInternalServerError)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to encrypt API key", nil)
 			return
 		}
 		settings.NimApiKeyEncrypted = encrypted
@@ -2561,13 +2991,13 @@ func (s *Server) handleSaveUserSettings(w http.ResponseWriter, r *http.Request)
 	// Encrypt and store OpenAI API key
 	if req.OpenaiApiKey != "" {
 		if s.crypto == nil {
-			http.Error(w, "Encryption service unavailable", http.StatusServiceUnavailable)
+			writeJSONError(w, http.StatusServiceUnavailable, "Encryption service unavailable", nil)
 			return
 		}
 		encrypted, err := s.crypto.Encrypt(req.OpenaiApiKey)
 		if err != nil {
 			s.logger.Error("Failed to encrypt OpenAI API key", zap.Error(err))
-			http.Error(w, "Failed to encrypt API key", http.StatusInternalServerError)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to encrypt API key", nil)
 			return
 		}
 		settings.OpenaiApiKeyEncrypted = encrypted
@@ -2581,13 +3011,13 @@ func (s *Server) handleSaveUserSettings(w http.ResponseWriter, r *http.Request)
 	// Encrypt and store Anthropic API key
 	if req.AnthropicApiKey != "" {
 		if s.crypto == nil {
-			http.Error(w, "Encryption service unavailable", http.StatusServiceUnavailable)
+			writeJSONError(w, http.StatusServiceUnavailable, "Encryption service unavailable", nil)
 			return
 		}
 		encrypted, err := s.crypto.Encrypt(req.AnthropicApiKey)
 		if err != nil {
 			s.logger.Error("Failed to encrypt Anthropic API key", zap.Error(err))
-			http.Error(w, "Failed to encrypt API key", http.StatusInternalServerError)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to encrypt API key", nil)
 			return
 		}
 		settings.AnthropicApiKeyEncrypted = encrypted
@@ -2601,13 +3031,13 @@ func (s *Server) handleSaveUserSettings(w http.ResponseWriter, r *http.Request)
 	// Encrypt and store GLM API key
 	if req.GlmApiKey != "" {
 		if s.crypto == nil {
-			http.Error(w, "Encryption service unavailable", http.StatusServiceUnavailable)
+			writeJSONError(w, http.StatusServiceUnavailable, "Encryption service unavailable", nil)
 			return
 		}
 		encrypted, err := s.crypto.Encrypt(req.GlmApiKey)
 		if err != nil {
 			s.logger.Error("Failed to encrypt GLM API key", zap.Error(err))
-			http.Error(w, "Failed to encrypt API key", http.StatusInternalServerError)
+			writeJSONError(w, http.StatusInternalServerError, "Failed to encrypt API key", nil)
 			return
 		}
 		settings.GlmApiKeyEncrypted = encrypted
@@ -2621,7 +3051,7 @@ func (s *Server) handleSaveUserSettings(w http.ResponseWriter, r *http.Request)
 	// Save to DGraph
 	if err := s.agent.mkClient.StoreUserSettings(r.Context(), userID, settings); err != nil {
 		s.logger.Error("Failed to store user settings", zap.Error(err))
-		http.Error(w, "Failed to save settings", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to save settings", nil)
 		return
 	}
 
@@ -2655,7 +3085,7 @@ func (s *Server) handleDeleteUserAPIKey(w http.ResponseWriter, r *http.Request)
 		"glm":       true,
 	}
 	if !validProviders[provider] {
-		http.Error(w, "Invalid provider", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid provider", nil)
 		return
 	}
 
@@ -2665,7 +3095,7 @@ func (s *Server) handleDeleteUserAPIKey(w http.ResponseWriter, r *http.Request)
 			zap.Error(err),
 			zap.String("provider", provider),
 			zap.String("user", userID))
-		http.Error(w, "Failed to delete API key", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to delete API key", nil)
 		return
 	}
 
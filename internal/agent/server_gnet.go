@@ -27,7 +27,7 @@ type GnetServer struct {
 // NewGnetServer creates a new gnet-based server for the agent
 func NewGnetServer(agent *Agent, logger *zap.Logger, allowedOrigins ...string) *GnetServer {
 	if len(allowedOrigins) == 0 {
-		allowedOrigins = []string{"http://localhost:*"}
+		allowedOrigins = DefaultAllowedOrigins
 	}
 
 	var groupLock *GroupLockManager
@@ -164,6 +164,15 @@ func (s *GnetServer) handleChat(req *server.Request) *server.Response {
 }
 
 func (s *GnetServer) handleWebSocketChat(req *server.Request) *server.Response {
+	// Same origin policy as the net/http server; requests without an Origin
+	// header come from non-browser clients
+	if origin := req.Header("Origin"); origin != "" && !OriginAllowed(origin, s.allowedOrigins) {
+		s.logger.Warn("WebSocket origin rejected",
+			zap.String("origin", origin),
+			zap.Strings("allowed", s.allowedOrigins))
+		return server.Forbidden("WebSocket origin denied")
+	}
+
 	// Extract user ID and conversation ID from query params
 	userID := req.Query.Get("user_id")
 	conversationID := req.Query.Get("conversation_id")
@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+// retagBatchSize caps the nodes one tag mutation touches
+const retagBatchSize = graph.DefaultNodePageSize
+
+// ErrTaggingUnavailable is returned when there is no in-process graph to retag
+var ErrTaggingUnavailable = errors.New("tagging is not available")
+
+// RetagRequest adds tags to and removes tags from a set of a namespace's
+// nodes: the listed UIDs or, when none are listed, every node matching the
+// type and tag filters
+type RetagRequest struct {
+	Namespace string         `json:"namespace"`
+	UIDs      []string       `json:"uids,omitempty"`
+	Type      graph.NodeType `json:"type,omitempty"` // Only nodes of this type
+	Tag       string         `json:"tag,omitempty"`  // Only nodes already tagged with this
+	Add       []string       `json:"add,omitempty"`
+	Remove    []string       `json:"remove,omitempty"`
+}
+
+// RetagResult reports a retagging
+type RetagResult struct {
+	Namespace string   `json:"namespace"`
+	Retagged  int      `json:"retagged"` // Nodes the tags were applied to
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+}
+
+// nodeTagger is the part of the graph client retagging uses
+type nodeTagger interface {
+	GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]graph.Node, error)
+	ListNodes(ctx context.Context, opts graph.ListNodesOpts) ([]graph.Node, error)
+	AddTagsBulk(ctx context.Context, uids []string, tags []string) error
+	RemoveTagsBulk(ctx context.Context, uids []string, tags []string) error
+}
+
+// retagFields is what retagging reads of a node to apply the filters
+var retagFields = []string{"dgraph.type", "namespace", "tags"}
+
+// RetagNodes applies req. Listed UIDs outside the namespace or not matching
+// the filters are skipped, so a caller can only ever retag its own nodes.
+func (a *Agent) RetagNodes(ctx context.Context, req RetagRequest) (*RetagResult, error) {
+	graphClient := a.GetGraphClient()
+	if graphClient == nil {
+		return nil, ErrTaggingUnavailable
+	}
+	return retagNodes(ctx, graphClient, req)
+}
+
+func retagNodes(ctx context.Context, g nodeTagger, req RetagRequest) (*RetagResult, error) {
+	if req.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		return nil, fmt.Errorf("add or remove is required")
+	}
+
+	result := &RetagResult{Namespace: req.Namespace, Added: req.Add, Removed: req.Remove}
+	matches := func(node graph.Node) bool {
+		return node.Namespace == req.Namespace &&
+			(req.Type == "" || node.HasType(req.Type)) &&
+			(req.Tag == "" || slices.Contains(node.Tags, req.Tag))
+	}
+	apply := func(batch []string) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if len(req.Add) > 0 {
+			if err := g.AddTagsBulk(ctx, batch, req.Add); err != nil {
+				return err
+			}
+		}
+		if len(req.Remove) > 0 {
+			if err := g.RemoveTagsBulk(ctx, batch, req.Remove); err != nil {
+				return err
+			}
+		}
+		result.Retagged += len(batch)
+		return nil
+	}
+
+	if len(req.UIDs) > 0 {
+		for start := 0; start < len(req.UIDs); start += retagBatchSize {
+			nodes, err := g.GetNodesByUIDs(ctx, req.UIDs[start:min(start+retagBatchSize, len(req.UIDs))], retagFields)
+			if err != nil {
+				return nil, err
+			}
+			var batch []string
+			for _, node := range nodes {
+				if matches(node) {
+					batch = append(batch, node.UID)
+				}
+			}
+			if err := apply(batch); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	nodes, errc := graph.StreamNodePages(ctx, graph.ListNodesOpts{
+		Namespace: req.Namespace,
+		NodeType:  req.Type,
+		Fields:    retagFields,
+	}, g.ListNodes)
+
+	batch := make([]string, 0, retagBatchSize)
+	for node := range nodes {
+		if node.Namespace == "" {
+			node.Namespace = req.Namespace // The listing already filters on it
+		}
+		if !matches(node) {
+			continue
+		}
+		if batch = append(batch, node.UID); len(batch) == retagBatchSize {
+			if err := apply(batch); err != nil {
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	if err := apply(batch); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// handleRetagMemories adds and removes tags across a set of the caller's
+// nodes, listed by UID or selected by type and tag.
+// POST /api/memories/tags
+func (s *Server) handleRetagMemories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := GetUserID(ctx)
+
+	var req RetagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+	if req.Namespace == "" {
+		req.Namespace = nsutil.ForUser(userID)
+	}
+	if !s.canAccessNamespace(ctx, req.Namespace, userID) {
+		writeJSONError(w, http.StatusForbidden, "Access denied", nil)
+		return
+	}
+	if req.Type != "" {
+		nodeType, err := graph.ParseNodeType(string(req.Type))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		req.Type = nodeType
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "add or remove is required", nil)
+		return
+	}
+
+	result, err := s.agent.RetagNodes(ctx, req)
+	if errors.Is(err, ErrTaggingUnavailable) {
+		writeJSONError(w, http.StatusServiceUnavailable, "Tagging is not available", nil)
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to retag memories",
+			zap.String("namespace", req.Namespace),
+			zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to retag memories", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// fakeTagger holds nodes in memory and applies tag changes to them
+type fakeTagger struct {
+	nodes     map[string]*graph.Node
+	mutations int
+}
+
+func newFakeTagger(nodes ...graph.Node) *fakeTagger {
+	f := &fakeTagger{nodes: map[string]*graph.Node{}}
+	for i := range nodes {
+		f.nodes[nodes[i].UID] = &nodes[i]
+	}
+	return f
+}
+
+func (f *fakeTagger) GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]graph.Node, error) {
+	var nodes []graph.Node
+	for _, uid := range uids {
+		if node, ok := f.nodes[uid]; ok {
+			nodes = append(nodes, *node)
+		}
+	}
+	return nodes, nil
+}
+
+func (f *fakeTagger) ListNodes(ctx context.Context, opts graph.ListNodesOpts) ([]graph.Node, error) {
+	var nodes []graph.Node
+	for _, node := range f.nodes {
+		if node.Namespace == opts.Namespace && node.UID > opts.After &&
+			(opts.NodeType == "" || node.HasType(opts.NodeType)) {
+			nodes = append(nodes, *node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].UID < nodes[j].UID })
+	return nodes[:min(len(nodes), opts.Limit)], nil
+}
+
+func (f *fakeTagger) AddTagsBulk(ctx context.Context, uids []string, tags []string) error {
+	f.mutations++
+	for _, uid := range uids {
+		for _, tag := range tags {
+			if node := f.nodes[uid]; !slices.Contains(node.Tags, tag) {
+				node.Tags = append(node.Tags, tag)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeTagger) RemoveTagsBulk(ctx context.Context, uids []string, tags []string) error {
+	f.mutations++
+	for _, uid := range uids {
+		node := f.nodes[uid]
+		node.Tags = slices.DeleteFunc(node.Tags, func(tag string) bool { return slices.Contains(tags, tag) })
+	}
+	return nil
+}
+
+func TestRetagNodesByFilterAndUID(t *testing.T) {
+	g := newFakeTagger(
+		graph.Node{UID: "0x1", Namespace: "user_a", DType: []string{"Fact"}, Tags: []string{"draft"}},
+		graph.Node{UID: "0x2", Namespace: "user_a", DType: []string{"Fact"}, Tags: []string{"draft", "work"}},
+		graph.Node{UID: "0x3", Namespace: "user_a", DType: []string{"Entity"}, Tags: []string{"draft"}},
+		graph.Node{UID: "0x4", Namespace: "user_b", DType: []string{"Fact"}, Tags: []string{"draft"}},
+	)
+
+	result, err := retagNodes(context.Background(), g, RetagRequest{
+		Namespace: "user_a", Type: graph.NodeTypeFact, Tag: "draft",
+		Add: []string{"reviewed"}, Remove: []string{"draft"},
+	})
+	if err != nil {
+		t.Fatalf("retagNodes: %v", err)
+	}
+	if result.Retagged != 2 || g.mutations != 2 {
+		t.Errorf("retagged %d nodes in %d mutations, want 2 in 2", result.Retagged, g.mutations)
+	}
+	if got := g.nodes["0x2"].Tags; !slices.Equal(got, []string{"work", "reviewed"}) {
+		t.Errorf("0x2 tags = %v, want [work reviewed]", got)
+	}
+	if got := g.nodes["0x3"].Tags; !slices.Equal(got, []string{"draft"}) {
+		t.Errorf("0x3 (an entity) was retagged: %v", got)
+	}
+
+	// Another namespace's node is skipped even when listed
+	result, err = retagNodes(context.Background(), g, RetagRequest{
+		Namespace: "user_a", UIDs: []string{"0x3", "0x4"}, Add: []string{"pinned"},
+	})
+	if err != nil {
+		t.Fatalf("retagNodes: %v", err)
+	}
+	if result.Retagged != 1 || slices.Contains(g.nodes["0x4"].Tags, "pinned") {
+		t.Errorf("retagged %d nodes, 0x4 tags %v; want only 0x3", result.Retagged, g.nodes["0x4"].Tags)
+	}
+
+	if _, err := retagNodes(context.Background(), g, RetagRequest{Namespace: "user_a"}); err == nil {
+		t.Error("a retag with nothing to add or remove was accepted")
+	}
+}
@@ -3,6 +3,7 @@ package agent
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -22,13 +23,14 @@ type SpreadActivationRequest struct {
 	DecayFactor   float64 `json:"decay_factor"`   // 0.0-1.0, default 0.5
 	MaxHops       int     `json:"max_hops"`       // default 3
 	MinActivation float64 `json:"min_activation"` // default 0.1
-	MaxResults    int     `json:"max_results"`    // default 50
+	MaxResults    int     `json:"max_results"`    // default 50 (page size)
+	Cursor        string  `json:"cursor"`         // next_cursor from a previous page
 }
 
 func (s *Server) handleSpreadActivation(w http.ResponseWriter, r *http.Request) {
 	var req SpreadActivationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -37,13 +39,13 @@ func (s *Server) handleSpreadActivation(w http.ResponseWriter, r *http.Request)
 	if startUID == "" && req.StartName != "" {
 		node, err := s.agent.mkClient.FindNodeByName(r.Context(), req.Namespace, req.StartName, graph.NodeTypeEntity)
 		if err != nil || node == nil {
-			http.Error(w, "Start node not found", http.StatusNotFound)
+			writeJSONError(w, http.StatusNotFound, "Start node not found", nil)
 			return
 		}
 		startUID = node.UID
 	}
 	if startUID == "" {
-		http.Error(w, "start_uid or start_name is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "start_uid or start_name is required", nil)
 		return
 	}
 
@@ -54,19 +56,25 @@ func (s *Server) handleSpreadActivation(w http.ResponseWriter, r *http.Request)
 		MaxHops:       req.MaxHops,
 		MinActivation: req.MinActivation,
 		MaxResults:    req.MaxResults,
+		Cursor:        req.Cursor,
 	}
 
-	result, err := s.agent.mkClient.SpreadActivation(r.Context(), opts)
+	page, err := s.agent.mkClient.SpreadActivationPage(r.Context(), opts)
+	if errors.Is(err, graph.ErrInvalidCursor) {
+		writeJSONError(w, http.StatusBadRequest, "Invalid cursor", nil)
+		return
+	}
 	if err != nil {
 		s.logger.Error("Spread activation failed", zap.Error(err))
-		http.Error(w, "Traversal failed", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Traversal failed", nil)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"nodes":       result,
-		"total_count": len(result),
+		"nodes":       page.Nodes,
+		"total_count": page.TotalCount,
+		"next_cursor": page.NextCursor,
 	})
 }
 
@@ -84,12 +92,12 @@ type CommunityTraversalRequest struct {
 func (s *Server) handleCommunityTraversal(w http.ResponseWriter, r *http.Request) {
 	var req CommunityTraversalRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
 	if req.EntityName == "" {
-		http.Error(w, "entity_name is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "entity_name is required", nil)
 		return
 	}
 
@@ -102,7 +110,7 @@ func (s *Server) handleCommunityTraversal(w http.ResponseWriter, r *http.Request
 	result, err := s.agent.mkClient.TraverseViaCommunity(r.Context(), opts)
 	if err != nil {
 		s.logger.Error("Community traversal failed", zap.Error(err))
-		http.Error(w, "Traversal failed: "+err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Traversal failed: "+err.Error(), nil)
 		return
 	}
 
@@ -126,7 +134,7 @@ type TemporalQueryRequest struct {
 func (s *Server) handleTemporalQuery(w http.ResponseWriter, r *http.Request) {
 	var req TemporalQueryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -147,7 +155,7 @@ func (s *Server) handleTemporalQuery(w http.ResponseWriter, r *http.Request) {
 	result, err := s.agent.mkClient.QueryWithTemporalDecay(r.Context(), opts)
 	if err != nil {
 		s.logger.Error("Temporal query failed", zap.Error(err))
-		http.Error(w, "Query failed", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Query failed", nil)
 		return
 	}
 
@@ -175,7 +183,7 @@ type ExpandNodeRequest struct {
 func (s *Server) handleExpandNode(w http.ResponseWriter, r *http.Request) {
 	var req ExpandNodeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
 		return
 	}
 
@@ -184,13 +192,13 @@ func (s *Server) handleExpandNode(w http.ResponseWriter, r *http.Request) {
 	if startUID == "" && req.StartName != "" {
 		node, err := s.agent.mkClient.FindNodeByName(r.Context(), req.Namespace, req.StartName, graph.NodeTypeEntity)
 		if err != nil || node == nil {
-			http.Error(w, "Start node not found", http.StatusNotFound)
+			writeJSONError(w, http.StatusNotFound, "Start node not found", nil)
 			return
 		}
 		startUID = node.UID
 	}
 	if startUID == "" {
-		http.Error(w, "start_uid or start_name is required", http.StatusBadRequest)
+		writeJSONError(w, http.StatusBadRequest, "start_uid or start_name is required", nil)
 		return
 	}
 
@@ -199,12 +207,13 @@ func (s *Server) handleExpandNode(w http.ResponseWriter, r *http.Request) {
 		EdgeTypes:  req.EdgeTypes,
 		MaxHops:    req.MaxHops,
 		MaxResults: req.MaxResults,
+		Timeout:    graph.DefaultExpandTimeout,
 	}
 
 	result, err := s.agent.mkClient.ExpandFromNode(r.Context(), opts)
 	if err != nil {
 		s.logger.Error("Node expansion failed", zap.Error(err))
-		http.Error(w, "Expansion failed", http.StatusInternalServerError)
+		writeJSONError(w, http.StatusInternalServerError, "Expansion failed", nil)
 		return
 	}
 
@@ -0,0 +1,332 @@
+// Package agent provides streaming document uploads.
+// Uploads are written to a temp file as they arrive instead of being buffered in
+// memory, with the size cap, magic-number check and malware scan applied while
+// streaming. The temp file path is handed to the AI service for ingestion; an
+// AI service that can't read it gets the content inline instead.
+package agent
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/vectorindex"
+)
+
+const (
+	// DefaultMaxUploadSize caps a single uploaded document
+	DefaultMaxUploadSize int64 = 50 << 20 // 50MB
+
+	// uploadSniffLen is how much of an upload is buffered for the magic-number check
+	uploadSniffLen = 512
+	// uploadScanOverlap carries the tail of each chunk into the next malware scan so
+	// patterns (and over-long words) split across chunk boundaries are still caught
+	uploadScanOverlap = 1024
+	// uploadProgressInterval is how many bytes pass between progress events
+	uploadProgressInterval = 256 << 10
+	// multipartOverhead allows for form fields and part headers around the file
+	multipartOverhead = 1 << 20
+)
+
+// errUploadTooLarge is returned once an upload passes its size cap
+var errUploadTooLarge = errors.New("upload exceeds maximum size")
+
+// uploadRejectedError marks an upload refused by content validation
+type uploadRejectedError struct {
+	err error
+}
+
+func (e *uploadRejectedError) Error() string { return e.err.Error() }
+func (e *uploadRejectedError) Unwrap() error { return e.err }
+
+// DefaultUploadDir is where uploads are staged; the AI service must see the same path
+func DefaultUploadDir() string {
+	return filepath.Join(os.TempDir(), "rmk-uploads")
+}
+
+// uploadSink streams an upload into a temp file, enforcing the size limit and
+// validating content as bytes arrive
+type uploadSink struct {
+	file       *os.File
+	filename   string
+	validator  *FileValidator
+	limit      int64
+	written    int64
+	head       []byte
+	validated  bool
+	scanTail   []byte
+	lastReport int64
+	onProgress func(written int64)
+}
+
+// newUploadSink creates a temp file in dir for filename
+func newUploadSink(dir, filename string, validator *FileValidator, limit int64, onProgress func(int64)) (*uploadSink, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create upload dir: %w", err)
+	}
+	file, err := os.CreateTemp(dir, "upload-*"+filepath.Ext(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	return &uploadSink{
+		file:       file,
+		filename:   filename,
+		validator:  validator,
+		limit:      limit,
+		onProgress: onProgress,
+	}, nil
+}
+
+// Write validates and appends p to the temp file
+func (u *uploadSink) Write(p []byte) (int, error) {
+	if u.written+int64(len(p)) > u.limit {
+		return 0, errUploadTooLarge
+	}
+
+	if !u.validated {
+		need := uploadSniffLen - len(u.head)
+		if need > len(p) {
+			need = len(p)
+		}
+		u.head = append(u.head, p[:need]...)
+		if len(u.head) >= uploadSniffLen {
+			if err := u.checkHead(); err != nil {
+				return 0, &uploadRejectedError{err}
+			}
+		}
+	}
+
+	// Scan this chunk together with the tail of the previous one
+	window := append(u.scanTail, p...)
+	if err := u.validator.ScanForMalware(window, u.filename); err != nil {
+		return 0, &uploadRejectedError{fmt.Errorf("rejected by security scan: %w", err)}
+	}
+	if len(window) > uploadScanOverlap {
+		window = window[len(window)-uploadScanOverlap:]
+	}
+	u.scanTail = append(u.scanTail[:0], window...)
+
+	n, err := u.file.Write(p)
+	u.written += int64(n)
+	if err != nil {
+		return n, err
+	}
+
+	if u.onProgress != nil && u.written-u.lastReport >= uploadProgressInterval {
+		u.lastReport = u.written
+		u.onProgress(u.written)
+	}
+	return n, nil
+}
+
+// Finish validates short uploads, reports final progress and closes the file
+func (u *uploadSink) Finish() error {
+	if u.written == 0 {
+		return &uploadRejectedError{fmt.Errorf("file is empty")}
+	}
+	if !u.validated {
+		if err := u.checkHead(); err != nil {
+			return &uploadRejectedError{err}
+		}
+	}
+	if u.onProgress != nil && u.lastReport != u.written {
+		u.onProgress(u.written)
+	}
+	return u.file.Close()
+}
+
+// Abort closes and removes the temp file
+func (u *uploadSink) Abort() {
+	u.file.Close()
+	os.Remove(u.file.Name())
+}
+
+// Path returns the temp file path
+func (u *uploadSink) Path() string {
+	return u.file.Name()
+}
+
+func (u *uploadSink) checkHead() error {
+	if err := u.validator.ValidateFileContent(u.head, u.filename); err != nil {
+		return err
+	}
+	u.validated = true
+	return nil
+}
+
+// uploadProgress reports upload progress as server-sent events when the client
+// asked for them; otherwise it is a no-op and the final response is plain JSON
+type uploadProgress struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	total   int64
+	started bool
+}
+
+// newUploadProgress enables SSE when the request accepts text/event-stream
+func newUploadProgress(w http.ResponseWriter, r *http.Request) *uploadProgress {
+	p := &uploadProgress{w: w, total: r.ContentLength}
+	if r.Header.Get("Accept") == "text/event-stream" {
+		p.flusher, _ = w.(http.Flusher)
+	}
+	return p
+}
+
+// Enabled reports whether progress is streamed to the client
+func (p *uploadProgress) Enabled() bool {
+	return p.flusher != nil
+}
+
+// Progress sends a progress event
+func (p *uploadProgress) Progress(written int64) {
+	payload := map[string]int64{"bytes": written}
+	if p.total > 0 {
+		payload["total"] = p.total
+	}
+	p.send("progress", payload)
+}
+
+// Error ends the stream with an error event, or writes a JSON error when not streaming
+func (p *uploadProgress) Error(status int, msg string) {
+	if !p.started {
+		writeJSONError(p.w, status, msg, nil)
+		return
+	}
+	p.send("error", map[string]interface{}{"status": status, "error": msg})
+}
+
+// Done ends the stream with the final result, or writes it as JSON when not streaming
+func (p *uploadProgress) Done(result interface{}) {
+	if !p.Enabled() {
+		p.w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(p.w).Encode(result)
+		return
+	}
+	p.send("complete", result)
+}
+
+func (p *uploadProgress) send(event string, data interface{}) {
+	if !p.Enabled() {
+		return
+	}
+	if !p.started {
+		p.w.Header().Set("Content-Type", "text/event-stream")
+		p.w.Header().Set("Cache-Control", "no-cache")
+		p.w.WriteHeader(http.StatusOK)
+		p.started = true
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "event: %s\ndata: %s\n\n", event, body)
+	p.w.Write(buf.Bytes())
+	p.flusher.Flush()
+}
+
+// uploadLimits returns the configured upload cap and staging dir, applying defaults
+func (a *Agent) uploadLimits() (int64, string) {
+	maxSize, dir := a.config.MaxUploadSize, a.config.UploadDir
+	if maxSize <= 0 {
+		maxSize = DefaultMaxUploadSize
+	}
+	if dir == "" {
+		dir = DefaultUploadDir()
+	}
+	return maxSize, dir
+}
+
+// uploadError maps a streaming upload failure to an HTTP status and message
+func uploadError(err error) (int, string) {
+	var maxBytesErr *http.MaxBytesError
+	var rejected *uploadRejectedError
+	switch {
+	case errors.Is(err, errUploadTooLarge), errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, "File exceeds maximum upload size"
+	case errors.As(err, &rejected):
+		return http.StatusBadRequest, fmt.Sprintf("File validation failed: %v", rejected.err)
+	default:
+		return http.StatusBadRequest, "Failed to read upload"
+	}
+}
+
+// uploadIngestResult is what the AI service extracted from an upload
+type uploadIngestResult struct {
+	Entities      []graph.ExtractedEntity            `json:"entities"`
+	Relationships []interface{}                      `json:"relationships"`
+	Chunks        []graph.DocumentChunk              `json:"chunks"`
+	Stats         map[string]interface{}             `json:"stats"`
+	Summary       string                             `json:"summary"`
+	VectorTree    map[string]*vectorindex.VectorNode `json:"vector_tree"`
+}
+
+// uploadIngestRequest is the AI service's /ingest body
+type uploadIngestRequest struct {
+	Text          string `json:"text,omitempty"`
+	ContentBase64 string `json:"content_base64,omitempty"`
+	FilePath      string `json:"file_path,omitempty"`
+	Filename      string `json:"filename"`
+	DocumentType  string `json:"document_type"`
+}
+
+// ingestUpload sends a staged upload to the AI service's /ingest. The file
+// goes by path, avoiding base64 inflation in the request; when the AI service
+// can't read the staging dir (422) the content is sent inline, as text or,
+// for PDFs, base64.
+func (c *AIClient) ingestUpload(path, filename string) (*uploadIngestResult, error) {
+	req := uploadIngestRequest{FilePath: path, Filename: filename, DocumentType: "text"}
+	if strings.EqualFold(filepath.Ext(filename), ".pdf") {
+		req.DocumentType = "pdf"
+	}
+	result, status, err := c.ingest(req)
+	if status != http.StatusUnprocessableEntity {
+		return result, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged upload: %w", err)
+	}
+	req.FilePath = ""
+	if req.DocumentType == "pdf" {
+		req.ContentBase64 = base64.StdEncoding.EncodeToString(content)
+	} else {
+		req.Text = string(content)
+	}
+	result, _, err = c.ingest(req)
+	return result, err
+}
+
+// ingest posts one /ingest request, returning the response status alongside
+// any error
+func (c *AIClient) ingest(req uploadIngestRequest) (*uploadIngestResult, int, error) {
+	reqBody, _ := json.Marshal(req)
+	resp, err := c.httpClient.Post(c.baseURL+"/ingest", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, 0, fmt.Errorf("ingest request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("ingest returned status %d", resp.StatusCode)
+	}
+	var result uploadIngestResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to decode ingest response: %w", err)
+	}
+	return &result, resp.StatusCode, nil
+}
+
+// readFormValue reads a small multipart form field
+func readFormValue(r io.Reader) string {
+	value, _ := io.ReadAll(io.LimitReader(r, 1024))
+	return string(value)
+}
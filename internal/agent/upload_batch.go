@@ -0,0 +1,335 @@
+// Package agent provides multi-document batch uploads.
+// Every file in the multipart body is streamed to disk and validated on its own;
+// the accepted files are ingested in one AI-service call so entities shared
+// between documents collapse to a single node linked to each source document.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+// maxBatchUploadFiles caps the number of files in one batch upload
+const maxBatchUploadFiles = 20
+
+// BatchUploadItem reports the outcome for one file of a batch upload
+type BatchUploadItem struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"` // "success" or "error"
+	Error    string `json:"error,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Entities int    `json:"entities_extracted"`
+	Chunks   int    `json:"chunks"`
+}
+
+// BatchUploadResponse is the response of /api/upload/batch
+type BatchUploadResponse struct {
+	Status  string                 `json:"status"`
+	Results []BatchUploadItem      `json:"results"`
+	Stats   map[string]interface{} `json:"stats"`
+}
+
+// batchIngestResult mirrors the AI service /ingest-batch response
+type batchIngestResult struct {
+	Results []struct {
+		Index  int    `json:"index"`
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Result *struct {
+			Entities []json.RawMessage     `json:"entities"`
+			Chunks   []graph.DocumentChunk `json:"chunks"`
+		} `json:"result"`
+	} `json:"results"`
+	Entities []graph.ExtractedEntity `json:"entities"`
+	Stats    map[string]interface{}  `json:"stats"`
+}
+
+// handleBatchUpload ingests several documents from one multipart request.
+// A file that fails validation or ingestion is reported in its own result
+// without aborting the rest of the batch.
+func (s *Server) handleBatchUpload(w http.ResponseWriter, r *http.Request) {
+	userID := GetUserID(r.Context())
+	maxSize, uploadDir := s.agent.uploadLimits()
+	maxBody := maxSize*maxBatchUploadFiles + multipartOverhead
+
+	if r.ContentLength > maxBody {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Batch exceeds maximum size of %d bytes", maxBody), nil)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Failed to parse multipart form", nil)
+		return
+	}
+
+	validator := NewFileValidator(maxSize, true)
+	var (
+		items       []BatchUploadItem
+		sinks       []*uploadSink // Parallel to items; nil when the file was rejected
+		contextType string
+		contextID   string
+	)
+	defer func() {
+		for _, sink := range sinks {
+			if sink != nil {
+				sink.Abort()
+			}
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			status, msg := uploadError(err)
+			writeJSONError(w, status, msg, nil)
+			return
+		}
+
+		switch part.FormName() {
+		case "context_type":
+			contextType = readFormValue(part)
+		case "context_id":
+			contextID = readFormValue(part)
+		case "file", "files":
+			if len(items) >= maxBatchUploadFiles {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("At most %d files per batch", maxBatchUploadFiles), nil)
+				return
+			}
+			item, sink, fatal := s.stageBatchFile(part, validator, maxSize, uploadDir)
+			if fatal != nil {
+				status, msg := uploadError(fatal)
+				writeJSONError(w, status, msg, nil)
+				return
+			}
+			items = append(items, item)
+			sinks = append(sinks, sink)
+		}
+		part.Close()
+	}
+
+	if len(items) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "No files in request", nil)
+		return
+	}
+
+	namespace := nsutil.ForUser(userID)
+	if contextType == "group" && contextID != "" {
+		namespace = contextID
+	}
+	if !s.canAccessNamespace(r.Context(), namespace, userID) {
+		s.logger.Warn("Batch upload to a workspace by non-member",
+			zap.String("user_id", userID),
+			zap.String("namespace", namespace))
+		writeJSONError(w, http.StatusForbidden, "You are not a member of this workspace", nil)
+		return
+	}
+
+	s.ingestBatch(namespace, userID, items, sinks)
+
+	succeeded := 0
+	for _, item := range items {
+		if item.Status == "success" {
+			succeeded++
+		}
+	}
+	status := "success"
+	if succeeded == 0 {
+		status = "error"
+	} else if succeeded < len(items) {
+		status = "partial"
+	}
+
+	s.logger.Info("Batch upload processed",
+		zap.String("user", userID),
+		zap.String("namespace", namespace),
+		zap.Int("files", len(items)),
+		zap.Int("succeeded", succeeded))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BatchUploadResponse{
+		Status:  status,
+		Results: items,
+		Stats: map[string]interface{}{
+			"files":     len(items),
+			"succeeded": succeeded,
+			"failed":    len(items) - succeeded,
+		},
+	})
+}
+
+// stageBatchFile validates and streams one batch file to disk. Validation
+// failures are recorded on the item; only errors that break the request
+// stream (size cap, read failure) are returned as fatal.
+func (s *Server) stageBatchFile(part *multipart.Part, validator *FileValidator, maxSize int64, uploadDir string) (BatchUploadItem, *uploadSink, error) {
+	filename := part.FileName()
+	item := BatchUploadItem{Filename: filename, Status: "error"}
+
+	reject := func(msg string) (BatchUploadItem, *uploadSink, error) {
+		item.Error = msg
+		// Drain the rejected part so the next one can be read
+		if _, err := io.Copy(io.Discard, part); err != nil {
+			return item, nil, err
+		}
+		return item, nil, nil
+	}
+
+	if err := validator.ValidateFilename(filename); err != nil {
+		return reject(fmt.Sprintf("Invalid filename: %v", err))
+	}
+	if !validator.IsAllowedExtension(filename) {
+		return reject(fmt.Sprintf("File type '%s' is not allowed", strings.ToLower(filepath.Ext(filename))))
+	}
+
+	limit := validator.GetMaxSizeForType(filename)
+	if limit > maxSize {
+		limit = maxSize
+	}
+	sink, err := newUploadSink(uploadDir, filename, validator, limit, nil)
+	if err != nil {
+		s.logger.Error("Failed to stage upload", zap.Error(err))
+		return reject("Failed to store upload")
+	}
+
+	if _, err := io.Copy(sink, part); err == nil {
+		err = sink.Finish()
+	}
+	if err != nil {
+		sink.Abort()
+		// Content rejections and per-file size overruns leave the request stream
+		// intact, so only this file fails; anything else breaks the whole batch
+		var rejected *uploadRejectedError
+		if errors.As(err, &rejected) || errors.Is(err, errUploadTooLarge) {
+			_, msg := uploadError(err)
+			return reject(msg)
+		}
+		return item, nil, err
+	}
+
+	item.Status = "staged"
+	item.Size = sink.written
+	return item, sink, nil
+}
+
+// ingestBatch sends the staged files to the AI service in one call and persists
+// the deduplicated entities and per-document chunks. Item statuses are updated in place.
+func (s *Server) ingestBatch(namespace, userID string, items []BatchUploadItem, sinks []*uploadSink) {
+	type ingestDoc struct {
+		FilePath     string `json:"file_path"`
+		Filename     string `json:"filename"`
+		DocumentType string `json:"document_type"`
+	}
+
+	var docs []ingestDoc
+	var docItems []int // docs[i] belongs to items[docItems[i]]
+	for i, sink := range sinks {
+		if sink == nil {
+			continue
+		}
+		docType := "text"
+		if strings.EqualFold(filepath.Ext(items[i].Filename), ".pdf") {
+			docType = "pdf"
+		}
+		docs = append(docs, ingestDoc{FilePath: sink.Path(), Filename: items[i].Filename, DocumentType: docType})
+		docItems = append(docItems, i)
+	}
+	if len(docs) == 0 {
+		return
+	}
+
+	fail := func(msg string) {
+		for _, i := range docItems {
+			items[i].Status, items[i].Error = "error", msg
+		}
+	}
+	if s.agent.aiClient == nil {
+		fail("AI service unavailable")
+		return
+	}
+
+	reqBody, _ := json.Marshal(map[string]interface{}{"documents": docs})
+	resp, err := s.agent.aiClient.httpClient.Post(
+		s.agent.aiClient.baseURL+"/ingest-batch",
+		"application/json",
+		bytes.NewReader(reqBody),
+	)
+	if err != nil {
+		s.logger.Warn("AI batch ingest request failed", zap.Error(err))
+		fail("Ingestion request failed")
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		s.logger.Warn("AI batch ingest returned non-200", zap.Int("status", resp.StatusCode))
+		fail(fmt.Sprintf("Ingestion failed (status %d)", resp.StatusCode))
+		return
+	}
+
+	var result batchIngestResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		s.logger.Warn("Failed to decode batch ingest response", zap.Error(err))
+		fail("Invalid ingestion response")
+		return
+	}
+
+	persistCtx := context.Background()
+	batchID := "batch_" + uuid.New().String()
+	for _, r := range result.Results {
+		if r.Index < 0 || r.Index >= len(docItems) {
+			continue
+		}
+		item := &items[docItems[r.Index]]
+		if r.Status != "ok" || r.Result == nil {
+			item.Status, item.Error = "error", r.Error
+			continue
+		}
+		item.Status = "success"
+		item.Entities = len(r.Result.Entities)
+		item.Chunks = len(r.Result.Chunks)
+
+		if len(r.Result.Chunks) > 0 {
+			docID := "doc_" + uuid.New().String()
+			if err := s.agent.mkClient.PersistChunks(persistCtx, namespace, docID, r.Result.Chunks); err != nil {
+				s.logger.Error("Failed to persist chunks", zap.String("filename", item.Filename), zap.Error(err))
+			}
+		}
+	}
+
+	// Entities arrive already merged across documents; sources become derived_from edges
+	if len(result.Entities) > 0 {
+		for i := range result.Entities {
+			for j, src := range result.Entities[i].Sources {
+				result.Entities[i].Sources[j] = fmt.Sprintf("doc_%s", src)
+			}
+		}
+		if err := s.agent.mkClient.PersistEntities(persistCtx, namespace, userID, batchID, result.Entities); err != nil {
+			s.logger.Error("Failed to persist batch entities", zap.Error(err))
+		} else {
+			s.logger.Info("Persisted batch entities to DGraph", zap.Int("count", len(result.Entities)))
+		}
+	}
+
+	for _, i := range docItems {
+		if items[i].Status == "staged" {
+			items[i].Status, items[i].Error = "error", "No ingestion result returned"
+		}
+	}
+}
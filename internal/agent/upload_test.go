@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeIngest serves /ingest, refusing file paths with 422 when shared is
+// false, and records the requests it gets
+func fakeIngest(t *testing.T, shared bool, status int) (*AIClient, *[]uploadIngestRequest) {
+	var requests []uploadIngestRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req uploadIngestRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		switch {
+		case req.FilePath != "" && !shared:
+			http.Error(w, `{"error":"uploaded file is not available"}`, http.StatusUnprocessableEntity)
+		case status != http.StatusOK:
+			http.Error(w, `{"error":"ingestion failed"}`, status)
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"entities": []map[string]string{{"name": "Acme"}},
+				"chunks":   []map[string]string{{"text": "hello"}},
+			})
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return NewAIClient(ts.URL, zaptest.NewLogger(t)), &requests
+}
+
+func TestIngestUpload(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	os.WriteFile(notes, []byte("Acme hired Alice"), 0o600)
+	report := filepath.Join(dir, "report.pdf")
+	os.WriteFile(report, []byte("%PDF-1.4"), 0o600)
+
+	client, requests := fakeIngest(t, true, http.StatusOK)
+	result, err := client.ingestUpload(notes, "notes.txt")
+	if err != nil || len(result.Entities) != 1 || len(result.Chunks) != 1 {
+		t.Fatalf("ingestUpload = %+v, %v", result, err)
+	}
+	if len(*requests) != 1 || (*requests)[0].FilePath != notes || (*requests)[0].Text != "" {
+		t.Errorf("requests = %+v, want the file by path only", *requests)
+	}
+
+	// An AI service without the staging dir gets the content inline
+	client, requests = fakeIngest(t, false, http.StatusOK)
+	if _, err := client.ingestUpload(notes, "notes.txt"); err != nil {
+		t.Fatalf("ingestUpload with text fallback: %v", err)
+	}
+	if len(*requests) != 2 || (*requests)[1].Text != "Acme hired Alice" || (*requests)[1].FilePath != "" {
+		t.Errorf("requests = %+v, want a text retry", *requests)
+	}
+	if _, err := client.ingestUpload(report, "report.pdf"); err != nil {
+		t.Fatalf("ingestUpload with base64 fallback: %v", err)
+	}
+	pdf := (*requests)[3]
+	if pdf.ContentBase64 != base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")) || pdf.DocumentType != "pdf" {
+		t.Errorf("pdf retry = %+v, want base64 content", pdf)
+	}
+
+	// Any other failure is reported, not retried
+	client, requests = fakeIngest(t, true, http.StatusInternalServerError)
+	if _, err := client.ingestUpload(notes, "notes.txt"); err == nil {
+		t.Error("ingestUpload succeeded although the AI service failed")
+	}
+	if len(*requests) != 1 {
+		t.Errorf("%d requests, want no retry after a 500", len(*requests))
+	}
+}
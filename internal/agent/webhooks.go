@@ -0,0 +1,652 @@
+// Package agent provides user-configurable webhooks for memory events
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"github.com/reflective-memory-kernel/internal/kernel/events"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+	"go.uber.org/zap"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=<hex HMAC of body>" keyed by the webhook secret
+	WebhookSignatureHeader = "X-RMK-Signature"
+	// WebhookEventHeader carries the event type (e.g. "node.created")
+	WebhookEventHeader = "X-RMK-Event"
+	// WebhookDeliveryHeader carries a unique ID per delivery attempt
+	WebhookDeliveryHeader = "X-RMK-Delivery"
+
+	webhookMaxAttempts    = 5
+	webhookBaseBackoff    = time.Second
+	webhookRequestTimeout = 10 * time.Second
+	webhookMaxDeliveryLog = 100
+	webhookRefreshEvery   = 30 * time.Second
+	webhookWorkers        = 4
+	// webhookQueueGroup shares memory events among agent instances so each
+	// event is delivered once, not once per instance
+	webhookQueueGroup = "webhook-dispatchers"
+)
+
+// errWebhookAddress is returned for webhook URLs reaching non-public addresses
+var errWebhookAddress = errors.New("webhook address is not public")
+
+// publicWebhookIP reports whether a webhook may connect to ip: loopback,
+// private, link-local, shared (100.64/10) and unspecified addresses are
+// refused so a webhook can't probe internal services
+func publicWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+		return false
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// newWebhookHTTPClient returns a client that refuses to connect to non-public
+// addresses. The check runs on the resolved address of every connection,
+// redirects included, so a hostname re-resolving to an internal IP after
+// registration is still refused.
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicWebhookIP(ip) {
+				return fmt.Errorf("%w: %s", errWebhookAddress, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: webhookRequestTimeout,
+		// No proxy: the dialer must see the receiver's own address
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+// checkWebhookHost resolves host and rejects it unless every address is public
+func checkWebhookHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !publicWebhookIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", errWebhookAddress, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// Webhook is a user-registered endpoint that receives matching memory events
+type Webhook struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	URL        string    `json:"url"`
+	Namespace  string    `json:"namespace"`             // "*" (admins only) matches every namespace
+	EventTypes []string  `json:"event_types,omitempty"` // Empty = all event types
+	Secret     string    `json:"secret,omitempty"`      // Only returned on creation
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// matches reports whether the webhook subscribes to the event
+func (wh *Webhook) matches(evt *events.Event) bool {
+	if wh.Namespace != "*" && wh.Namespace != evt.Namespace {
+		return false
+	}
+	if len(wh.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range wh.EventTypes {
+		if t == string(evt.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records a single delivery attempt
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	URL        string    `json:"url"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+type webhookJob struct {
+	webhook Webhook
+	event   events.Event
+	payload []byte
+}
+
+// WebhookDispatcher stores webhook registrations in Redis and delivers
+// memory events from NATS to matching endpoints with retry and backoff
+type WebhookDispatcher struct {
+	redis      *redis.Client
+	httpClient *http.Client
+	isMember   func(ctx context.Context, namespace, userID string) (bool, error)
+	logger     *zap.Logger
+
+	mu       sync.RWMutex
+	webhooks map[string]Webhook
+
+	jobs chan webhookJob
+}
+
+// NewWebhookDispatcher creates a dispatcher backed by Redis. isMember checks a
+// webhook owner still belongs to a group namespace before each delivery.
+func NewWebhookDispatcher(redisClient *redis.Client, isMember func(ctx context.Context, namespace, userID string) (bool, error), logger *zap.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		redis:      redisClient,
+		httpClient: newWebhookHTTPClient(),
+		isMember:   isMember,
+		logger:     logger,
+		webhooks:   make(map[string]Webhook),
+		jobs:       make(chan webhookJob, 1000),
+	}
+}
+
+// Start subscribes to memory events and launches delivery workers. Agent
+// instances share the subscription as a queue group, so each event is
+// delivered by one of them. Runs until ctx is cancelled.
+func (d *WebhookDispatcher) Start(ctx context.Context, nc *nats.Conn) error {
+	if err := d.refresh(ctx); err != nil {
+		d.logger.Warn("Failed to load webhooks", zap.Error(err))
+	}
+
+	sub, err := nc.QueueSubscribe(events.SubjectPrefix+">", webhookQueueGroup, func(msg *nats.Msg) {
+		d.dispatch(msg.Data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to memory events: %w", err)
+	}
+
+	for i := 0; i < webhookWorkers; i++ {
+		go d.worker(ctx)
+	}
+
+	go func() {
+		ticker := time.NewTicker(webhookRefreshEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := d.refresh(ctx); err != nil {
+					d.logger.Warn("Failed to refresh webhooks", zap.Error(err))
+				}
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	d.logger.Info("Webhook dispatcher started", zap.String("subject", events.SubjectPrefix+">"))
+	return nil
+}
+
+// refresh reloads all registrations from Redis so multiple agent instances stay in sync
+func (d *WebhookDispatcher) refresh(ctx context.Context) error {
+	ids, err := d.redis.SMembers(ctx, "webhooks:all").Result()
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]Webhook, len(ids))
+	for _, id := range ids {
+		wh, err := d.load(ctx, id)
+		if err != nil {
+			continue
+		}
+		loaded[id] = *wh
+	}
+
+	d.mu.Lock()
+	d.webhooks = loaded
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *WebhookDispatcher) load(ctx context.Context, id string) (*Webhook, error) {
+	data, err := d.redis.Get(ctx, "webhook:"+id).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var wh Webhook
+	if err := json.Unmarshal(data, &wh); err != nil {
+		return nil, err
+	}
+	return &wh, nil
+}
+
+// Register stores a new webhook and returns it (including its secret)
+func (d *WebhookDispatcher) Register(ctx context.Context, wh *Webhook) error {
+	data, err := json.Marshal(wh)
+	if err != nil {
+		return err
+	}
+
+	pipe := d.redis.TxPipeline()
+	pipe.Set(ctx, "webhook:"+wh.ID, data, 0)
+	pipe.SAdd(ctx, "webhooks:user:"+wh.UserID, wh.ID)
+	pipe.SAdd(ctx, "webhooks:all", wh.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.webhooks[wh.ID] = *wh
+	d.mu.Unlock()
+	return nil
+}
+
+// List returns the user's webhooks with secrets redacted
+func (d *WebhookDispatcher) List(ctx context.Context, userID string) ([]Webhook, error) {
+	ids, err := d.redis.SMembers(ctx, "webhooks:user:"+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, 0, len(ids))
+	for _, id := range ids {
+		wh, err := d.load(ctx, id)
+		if err != nil {
+			continue
+		}
+		wh.Secret = ""
+		webhooks = append(webhooks, *wh)
+	}
+	return webhooks, nil
+}
+
+// Delete removes a webhook owned by userID
+func (d *WebhookDispatcher) Delete(ctx context.Context, userID, id string) error {
+	wh, err := d.load(ctx, id)
+	if err != nil || wh.UserID != userID {
+		return fmt.Errorf("webhook not found")
+	}
+
+	pipe := d.redis.TxPipeline()
+	pipe.Del(ctx, "webhook:"+id)
+	pipe.SRem(ctx, "webhooks:user:"+userID, id)
+	pipe.SRem(ctx, "webhooks:all", id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	delete(d.webhooks, id)
+	d.mu.Unlock()
+	return nil
+}
+
+// Deliveries returns the most recent delivery attempts for the user's webhooks
+func (d *WebhookDispatcher) Deliveries(ctx context.Context, userID string, limit int) ([]WebhookDelivery, error) {
+	raw, err := d.redis.LRange(ctx, "webhook_deliveries:"+userID, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, 0, len(raw))
+	for _, item := range raw {
+		var del WebhookDelivery
+		if err := json.Unmarshal([]byte(item), &del); err == nil {
+			deliveries = append(deliveries, del)
+		}
+	}
+	return deliveries, nil
+}
+
+// dispatch queues a delivery for every webhook matching the event
+func (d *WebhookDispatcher) dispatch(payload []byte) {
+	var evt events.Event
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		d.logger.Debug("Skipping malformed memory event", zap.Error(err))
+		return
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, wh := range d.webhooks {
+		if !wh.matches(&evt) {
+			continue
+		}
+		select {
+		case d.jobs <- webhookJob{webhook: wh, event: evt, payload: payload}:
+		default:
+			d.logger.Warn("Webhook queue full, dropping delivery",
+				zap.String("webhook_id", wh.ID),
+				zap.String("event_type", string(evt.Type)))
+		}
+	}
+}
+
+func (d *WebhookDispatcher) worker(ctx context.Context) {
+	for {
+		select {
+		case job := <-d.jobs:
+			d.deliver(ctx, job)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// stillAllowed reports whether the webhook's owner may still read the
+// namespace it subscribes to; group membership can end after registration
+func (d *WebhookDispatcher) stillAllowed(ctx context.Context, wh Webhook) bool {
+	if wh.Namespace == "*" || wh.Namespace == nsutil.ForUser(wh.UserID) {
+		return true
+	}
+	if d.isMember == nil {
+		return false
+	}
+	isMember, err := d.isMember(ctx, wh.Namespace, wh.UserID)
+	return err == nil && isMember
+}
+
+// deliver POSTs the payload, retrying with exponential backoff (1s, 2s, 4s, ...)
+func (d *WebhookDispatcher) deliver(ctx context.Context, job webhookJob) {
+	if !d.stillAllowed(ctx, job.webhook) {
+		d.logger.Info("Skipping webhook delivery, owner lost access to the namespace",
+			zap.String("webhook_id", job.webhook.ID),
+			zap.String("namespace", job.webhook.Namespace))
+		return
+	}
+	signature := "sha256=" + SignWebhookPayload(job.webhook.Secret, job.payload)
+	backoff := webhookBaseBackoff
+
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		del := d.attempt(ctx, job, signature, attempt)
+		d.recordDelivery(ctx, job.webhook.UserID, del)
+		if del.Success {
+			return
+		}
+
+		if attempt == webhookMaxAttempts {
+			d.logger.Warn("Webhook delivery failed after retries",
+				zap.String("webhook_id", job.webhook.ID),
+				zap.String("event_id", job.event.ID),
+				zap.String("error", del.Error))
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, job webhookJob, signature string, attempt int) WebhookDelivery {
+	del := WebhookDelivery{
+		ID:        uuid.New().String(),
+		WebhookID: job.webhook.ID,
+		EventID:   job.event.ID,
+		EventType: string(job.event.Type),
+		URL:       job.webhook.URL,
+		Attempt:   attempt,
+		Timestamp: time.Now(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", job.webhook.URL, bytes.NewReader(job.payload))
+	if err != nil {
+		del.Error = err.Error()
+		return del
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(job.event.Type))
+	req.Header.Set(WebhookDeliveryHeader, del.ID)
+	req.Header.Set(WebhookSignatureHeader, signature)
+
+	start := time.Now()
+	resp, err := d.httpClient.Do(req)
+	del.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		del.Error = err.Error()
+		return del
+	}
+	resp.Body.Close()
+
+	del.StatusCode = resp.StatusCode
+	del.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !del.Success {
+		del.Error = fmt.Sprintf("receiver returned status %d", resp.StatusCode)
+	}
+	return del
+}
+
+func (d *WebhookDispatcher) recordDelivery(ctx context.Context, userID string, del WebhookDelivery) {
+	data, err := json.Marshal(del)
+	if err != nil {
+		return
+	}
+	key := "webhook_deliveries:" + userID
+	pipe := d.redis.Pipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, webhookMaxDeliveryLog-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		d.logger.Debug("Failed to record webhook delivery", zap.Error(err))
+	}
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of payload keyed by secret.
+// Receivers recompute this over the raw request body and compare it to the
+// X-RMK-Signature header (after the "sha256=" prefix) using hmac.Equal.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ============================================================================
+// Webhook API
+// ============================================================================
+
+// CreateWebhookRequest registers a webhook endpoint
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Namespace  string   `json:"namespace"`   // default: the caller's personal namespace
+	EventTypes []string `json:"event_types"` // default: all event types
+	Secret     string   `json:"secret"`      // optional; generated when empty
+}
+
+// setupWebhookRoutes registers the webhook endpoints on the protected API router
+func (s *Server) setupWebhookRoutes(api *mux.Router, protect func(http.HandlerFunc) http.Handler) {
+	api.Handle("/webhooks", protect(s.handleCreateWebhook)).Methods("POST")
+	api.Handle("/webhooks", protect(s.handleListWebhooks)).Methods("GET")
+	api.Handle("/webhooks/deliveries", protect(s.handleWebhookDeliveries)).Methods("GET")
+	api.Handle("/webhooks/{id}", protect(s.handleDeleteWebhook)).Methods("DELETE")
+}
+
+// handleCreateWebhook registers a new webhook for the current user
+// POST /api/webhooks
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.webhooks == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Webhooks not available", nil)
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid request body", nil)
+		return
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		writeJSONError(w, http.StatusBadRequest, "url must be an absolute http(s) URL", nil)
+		return
+	}
+	if err := checkWebhookHost(r.Context(), parsed.Hostname()); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "url must resolve to a public address", nil)
+		return
+	}
+
+	validTypes := map[string]bool{
+		string(events.TypeNodeCreated):         true,
+		string(events.TypeEdgeCreated):         true,
+		string(events.TypeInsightGenerated):    true,
+		string(events.TypeReflectionCompleted): true,
+	}
+	for _, t := range req.EventTypes {
+		if !validTypes[t] {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Unknown event type: %s", t), nil)
+			return
+		}
+	}
+
+	userID := GetUserID(r.Context())
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = nsutil.ForUser(userID)
+	}
+
+	// Only namespaces the caller can read may be subscribed to
+	switch {
+	case namespace == "*":
+		if GetUserRole(r.Context()) != "admin" {
+			writeJSONError(w, http.StatusForbidden, "Only admins can subscribe to all namespaces", nil)
+			return
+		}
+	case namespace == nsutil.ForUser(userID):
+	default:
+		isMember, err := s.agent.mkClient.IsWorkspaceMember(r.Context(), namespace, userID)
+		if err != nil || !isMember {
+			writeJSONError(w, http.StatusForbidden, "access denied", nil)
+			return
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, "Failed to generate secret", nil)
+			return
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	wh := &Webhook{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		URL:        parsed.String(),
+		Namespace:  namespace,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.webhooks.Register(r.Context(), wh); err != nil {
+		s.logger.Error("Failed to register webhook", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to register webhook", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(wh)
+}
+
+// handleListWebhooks returns the current user's webhooks
+// GET /api/webhooks
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if s.webhooks == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Webhooks not available", nil)
+		return
+	}
+
+	webhooks, err := s.webhooks.List(r.Context(), GetUserID(r.Context()))
+	if err != nil {
+		s.logger.Error("Failed to list webhooks", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to list webhooks", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"webhooks": webhooks,
+		"count":    len(webhooks),
+	})
+}
+
+// handleDeleteWebhook removes one of the current user's webhooks
+// DELETE /api/webhooks/{id}
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.webhooks == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Webhooks not available", nil)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := s.webhooks.Delete(r.Context(), GetUserID(r.Context()), id); err != nil {
+		writeJSONError(w, http.StatusNotFound, "Webhook not found", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"id":      id,
+	})
+}
+
+// handleWebhookDeliveries returns recent delivery attempts
+// GET /api/webhooks/deliveries?limit=50
+func (s *Server) handleWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if s.webhooks == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "Webhooks not available", nil)
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= webhookMaxDeliveryLog {
+		limit = l
+	}
+
+	deliveries, err := s.webhooks.Deliveries(r.Context(), GetUserID(r.Context()), limit)
+	if err != nil {
+		s.logger.Error("Failed to load webhook deliveries", zap.Error(err))
+		writeJSONError(w, http.StatusInternalServerError, "Failed to load deliveries", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
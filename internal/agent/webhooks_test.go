@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/kernel/events"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+func TestPublicWebhookIP(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+	} {
+		if got := publicWebhookIP(net.ParseIP(addr)); got != want {
+			t.Errorf("publicWebhookIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestWebhookClientRefusesInternalAddresses(t *testing.T) {
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits.Add(1) }))
+	defer ts.Close()
+
+	_, err := newWebhookHTTPClient().Post(ts.URL, "application/json", strings.NewReader("{}"))
+	if !errors.Is(err, errWebhookAddress) {
+		t.Errorf("POST to loopback = %v, want errWebhookAddress", err)
+	}
+	if hits.Load() != 0 {
+		t.Error("loopback receiver was reached")
+	}
+
+	if err := checkWebhookHost(context.Background(), "127.0.0.1"); !errors.Is(err, errWebhookAddress) {
+		t.Errorf("checkWebhookHost(127.0.0.1) = %v, want errWebhookAddress", err)
+	}
+}
+
+func TestWebhookDeliveryRechecksMembership(t *testing.T) {
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits.Add(1) }))
+	defer ts.Close()
+
+	member := true
+	d := NewWebhookDispatcher(fakeredis.New(t), func(ctx context.Context, namespace, userID string) (bool, error) {
+		return member && namespace == "group_team" && userID == "alice", nil
+	}, zaptest.NewLogger(t))
+	// The receiver is on loopback; the address guard is covered above
+	d.httpClient = ts.Client()
+
+	job := webhookJob{
+		webhook: Webhook{ID: "wh-1", UserID: "alice", URL: ts.URL, Namespace: "group_team"},
+		event:   events.Event{ID: "evt-1", Type: events.TypeNodeCreated, Namespace: "group_team"},
+		payload: []byte(`{}`),
+	}
+	d.deliver(context.Background(), job)
+	if hits.Load() != 1 {
+		t.Fatalf("member's webhook got %d deliveries, want 1", hits.Load())
+	}
+
+	member = false
+	d.deliver(context.Background(), job)
+	if hits.Load() != 1 {
+		t.Error("webhook was delivered after its owner left the group")
+	}
+
+	// A personal namespace needs no membership
+	job.webhook.Namespace, job.event.Namespace = "user_alice", "user_alice"
+	d.deliver(context.Background(), job)
+	if hits.Load() != 2 {
+		t.Error("personal namespace webhook was not delivered")
+	}
+}
@@ -4,6 +4,8 @@ package curation
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,21 +24,70 @@ type Node struct {
 	Specificity float64   `json:"specificity,omitempty"`
 }
 
+// Resolution decisions
+const (
+	// DecisionContradiction means the facts cannot both hold; keep Winner, archive Loser
+	DecisionContradiction = "CONTRADICTION"
+	// DecisionBothValid means the facts are complementary (or the judge abstained); keep both
+	DecisionBothValid = "BOTH_VALID"
+)
+
+// DefaultMinConfidence is the confidence below which the judge abstains from picking a winner
+const DefaultMinConfidence = 0.6
+
+// DefaultConfidence is assumed when the LLM's verdict gives no usable
+// confidence. It sits below DefaultMinConfidence, so such a verdict abstains;
+// a threshold at or below it lets contradictions without a confidence archive
+// the losing fact.
+const DefaultConfidence = 0.5
+
+// Mode selects how Resolve judges a pair of facts
+type Mode string
+
+const (
+	// ModeLLM asks the LLM for every pair, falling back to heuristics on error
+	ModeLLM Mode = "llm"
+	// ModeHeuristic resolves by recency, specificity and weight without calling the LLM
+	ModeHeuristic Mode = "heuristic"
+	// ModeAuto asks the LLM only about ambiguous pairs (same or near-identical
+	// names); the heuristics can't tell that clearly distinct facts contradict,
+	// so those pairs keep both
+	ModeAuto Mode = "auto"
+)
+
+// ambiguousNameSimilarity is the name similarity at or above which ModeAuto
+// treats a pair as ambiguous and asks the LLM
+const ambiguousNameSimilarity = 0.7
+
+// ParseMode converts a mode name to a Mode; an empty name yields the empty
+// Mode, meaning "use the service default"
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case "", ModeLLM, ModeHeuristic, ModeAuto:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown curation mode %q (want llm, heuristic or auto)", name)
+	}
+}
+
 // ResolutionResult represents the result of a contradiction resolution
 type ResolutionResult struct {
-	WinnerIndex int      `json:"winner_index"` // 1 or 2
+	Decision    string   `json:"decision"`     // DecisionContradiction or DecisionBothValid
+	WinnerIndex int      `json:"winner_index"` // 1 or 2 (0 when both are valid)
 	Winner      *Node    `json:"winner,omitempty"`
 	Loser       *Node    `json:"loser,omitempty"`
 	Reason      string   `json:"reason"`
-	Confidence  float64  `json:"confidence,omitempty"`
+	Confidence  float64  `json:"confidence"`     // 0.0-1.0; DefaultConfidence when the LLM gave none
 	Method      string   `json:"method"` // "llm" or "heuristic"
 	Timestamp   time.Time `json:"timestamp"`
 }
 
 // Service provides fact curation and contradiction resolution
 type Service struct {
-	router *router.Router
-	logger *zap.Logger
+	router        *router.Router
+	logger        *zap.Logger
+	minConfidence float64
+	mode          Mode
 }
 
 // New creates a new curation service
@@ -46,13 +97,56 @@ func New(r *router.Router, logger *zap.Logger) *Service {
 	}
 
 	return &Service{
-		router: r,
-		logger: logger,
+		router:        r,
+		logger:        logger,
+		minConfidence: DefaultMinConfidence,
+		mode:          ModeLLM,
 	}
 }
 
-// Resolve determines which of two contradicting facts is more reliable
+// SetMinConfidence sets the confidence below which a contradiction verdict is
+// downgraded to BOTH_VALID (abstention) so neither fact is archived
+func (s *Service) SetMinConfidence(threshold float64) {
+	s.minConfidence = threshold
+}
+
+// MinConfidence returns the confidence below which Resolve abstains
+func (s *Service) MinConfidence() float64 {
+	return s.minConfidence
+}
+
+// SetMode sets the mode Resolve uses when the caller doesn't pick one
+func (s *Service) SetMode(mode Mode) {
+	s.mode = mode
+}
+
+// Mode returns the service's default resolution mode
+func (s *Service) Mode() Mode {
+	return s.mode
+}
+
+// Resolve determines whether two facts contradict and, if so, which is more reliable.
+// Complementary facts, and contradictions judged below the confidence threshold,
+// resolve to DecisionBothValid with no winner.
 func (s *Service) Resolve(ctx context.Context, node1, node2 *Node) (*ResolutionResult, error) {
+	return s.ResolveWithMode(ctx, s.mode, node1, node2)
+}
+
+// ResolveWithMode is Resolve with an explicit mode; an empty mode uses the
+// service default
+func (s *Service) ResolveWithMode(ctx context.Context, mode Mode, node1, node2 *Node) (*ResolutionResult, error) {
+	if mode == "" {
+		mode = s.mode
+	}
+	switch mode {
+	case ModeHeuristic:
+		return s.resolveWithHeuristic(node1, node2), nil
+	case ModeAuto:
+		if !s.ambiguous(node1, node2) {
+			return heuristicAbstention("distinct_facts"), nil
+		}
+	}
+
 	// Try LLM-based resolution first
 	llmResult, err := s.resolveWithLLM(ctx, node1, node2)
 	if err == nil && llmResult != nil {
@@ -60,6 +154,18 @@ func (s *Service) Resolve(ctx context.Context, node1, node2 *Node) (*ResolutionR
 		llmResult.Method = "llm"
 		llmResult.Timestamp = time.Now()
 
+		if llmResult.Decision == DecisionContradiction && llmResult.Confidence < s.minConfidence {
+			// Abstain: not confident enough to archive either fact
+			llmResult.Decision = DecisionBothValid
+			llmResult.Reason = fmt.Sprintf("abstained (confidence %.2f < %.2f): %s",
+				llmResult.Confidence, s.minConfidence, llmResult.Reason)
+		}
+
+		if llmResult.Decision == DecisionBothValid {
+			llmResult.WinnerIndex = 0
+			return llmResult, nil
+		}
+
 		if llmResult.WinnerIndex == 1 {
 			llmResult.Winner = node1
 			llmResult.Loser = node2
@@ -75,9 +181,17 @@ func (s *Service) Resolve(ctx context.Context, node1, node2 *Node) (*ResolutionR
 	return s.resolveWithHeuristic(node1, node2), nil
 }
 
+// ambiguous reports whether a pair needs the LLM in ModeAuto: facts with the
+// same or near-identical names can't be told apart by recency alone
+func (s *Service) ambiguous(node1, node2 *Node) bool {
+	name1 := strings.ToLower(strings.TrimSpace(node1.Name))
+	name2 := strings.ToLower(strings.TrimSpace(node2.Name))
+	return levenshteinSimilarity(name1, name2) >= ambiguousNameSimilarity
+}
+
 // resolveWithLLM uses LLM to resolve contradictions
 func (s *Service) resolveWithLLM(ctx context.Context, node1, node2 *Node) (*ResolutionResult, error) {
-	prompt := fmt.Sprintf(`You are a fact verification expert. Two facts appear to contradict each other.
+	prompt := fmt.Sprintf(`You are a fact verification expert. Two facts may contradict each other.
 
 Fact 1:
 - Name: %s
@@ -89,12 +203,17 @@ Fact 2:
 - Description: %s
 - Created: %s
 
-Determine which fact should be kept as current. Consider:
+First decide whether they truly CONTRADICT (both cannot be true at the same time,
+e.g. "lives in Berlin" vs "lives in Paris") or are COMPLEMENTARY (both can hold,
+e.g. "likes pizza" vs "likes sushi", or one adds detail to the other).
+
+If they contradict, determine which fact should be kept as current. Consider:
 1. More recent information usually supersedes older
 2. More specific information is more reliable
 3. Direct statements override implications
 
-Return JSON: {"winner_index": 1 or 2, "reason": "brief explanation"}`,
+Return JSON:
+{"decision": "CONTRADICTION" or "BOTH_VALID", "winner_index": 1 or 2 (omit for BOTH_VALID), "confidence": 0.0-1.0, "reason": "brief explanation"}`,
 		node1.Name, node1.Description, node1.CreatedAt.Format(time.RFC3339),
 		node2.Name, node2.Description, node2.CreatedAt.Format(time.RFC3339),
 	)
@@ -105,23 +224,56 @@ Return JSON: {"winner_index": 1 or 2, "reason": "brief explanation"}`,
 		return nil, err
 	}
 
-	if winnerIdx, ok := result["winner_index"].(float64); ok {
-		reason := "LLM decision"
-		if r, ok := result["reason"].(string); ok {
-			reason = r
-		}
+	return parseResolution(result)
+}
+
+// parseResolution converts the LLM's JSON verdict into a ResolutionResult
+func parseResolution(result map[string]interface{}) (*ResolutionResult, error) {
+	reason := "LLM decision"
+	if r, ok := result["reason"].(string); ok {
+		reason = r
+	}
 
+	confidence := parseConfidence(result["confidence"])
+
+	decision := strings.ToUpper(strings.TrimSpace(fmt.Sprint(result["decision"])))
+	if decision == DecisionBothValid {
+		return &ResolutionResult{
+			Decision:   DecisionBothValid,
+			Reason:     reason,
+			Confidence: confidence,
+		}, nil
+	}
+
+	// Older prompts/models may omit "decision"; a winner implies contradiction
+	if winnerIdx, ok := result["winner_index"].(float64); ok && (winnerIdx == 1 || winnerIdx == 2) {
 		return &ResolutionResult{
+			Decision:    DecisionContradiction,
 			WinnerIndex: int(winnerIdx),
 			Reason:      reason,
-			Confidence:  0.8,
+			Confidence:  confidence,
 		}, nil
 	}
 
 	return nil, fmt.Errorf("invalid LLM response format")
 }
 
-// resolveWithHeuristic uses rule-based heuristics to resolve contradictions
+// parseConfidence reads the verdict's confidence, a number or numeric string,
+// clamped to 0.0-1.0. Missing or unparseable, it is DefaultConfidence.
+func parseConfidence(raw interface{}) float64 {
+	c, ok := raw.(float64)
+	if s, isString := raw.(string); isString {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		c, ok = parsed, err == nil
+	}
+	if !ok || math.IsNaN(c) {
+		return DefaultConfidence
+	}
+	return math.Max(0, math.Min(1, c))
+}
+
+// resolveWithHeuristic uses rule-based heuristics to resolve contradictions.
+// Nodes the heuristics score alike give no signal, so neither is archived.
 func (s *Service) resolveWithHeuristic(node1, node2 *Node) *ResolutionResult {
 	// Calculate scores
 	score1 := s.calculateNodeScore(node1)
@@ -139,13 +291,23 @@ func (s *Service) resolveWithHeuristic(node1, node2 *Node) *ResolutionResult {
 	} else if node2.UpdatedAt.After(node1.UpdatedAt) {
 		winner = 2
 		reason = "newer_timestamp"
-	} else {
+	} else if node1.UpdatedAt.After(node2.UpdatedAt) {
 		winner = 1
-		reason = "default_older_stable"
+		reason = "newer_timestamp"
+	} else {
+		return heuristicAbstention("no_heuristic_signal")
+	}
+
+	winnerNode, loserNode := node1, node2
+	if winner == 2 {
+		winnerNode, loserNode = node2, node1
 	}
 
 	return &ResolutionResult{
+		Decision:    DecisionContradiction,
 		WinnerIndex: winner,
+		Winner:      winnerNode,
+		Loser:       loserNode,
 		Reason:      reason,
 		Confidence:  0.6,
 		Method:      "heuristic",
@@ -153,6 +315,18 @@ func (s *Service) resolveWithHeuristic(node1, node2 *Node) *ResolutionResult {
 	}
 }
 
+// heuristicAbstention is the heuristic verdict keeping both facts, for pairs
+// the heuristics have no grounds to call a contradiction
+func heuristicAbstention(reason string) *ResolutionResult {
+	return &ResolutionResult{
+		Decision:   DecisionBothValid,
+		Reason:     reason,
+		Confidence: DefaultConfidence,
+		Method:     "heuristic",
+		Timestamp:  time.Now(),
+	}
+}
+
 // calculateNodeScore calculates a reliability score for a node
 func (s *Service) calculateNodeScore(node *Node) float64 {
 	score := 0.0
@@ -458,5 +632,6 @@ func (s *Service) GetStats() map[string]interface{} {
 	return map[string]interface{}{
 		"type": "curation",
 		"methods": []string{"llm", "heuristic"},
+		"mode":    string(s.mode),
 	}
 }
@@ -0,0 +1,255 @@
+package curation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/ai/router"
+)
+
+// newTestService returns a Service whose LLM is a fake Ollama endpoint replying with verdict
+func newTestService(t *testing.T, verdict string) *Service {
+	svc, _ := newCountingTestService(t, verdict)
+	return svc
+}
+
+// newCountingTestService is newTestService that also counts the LLM calls made
+func newCountingTestService(t *testing.T, verdict string) (*Service, *atomic.Int32) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": map[string]string{"role": "assistant", "content": verdict},
+		})
+	}))
+	t.Cleanup(ts.Close)
+
+	logger := zaptest.NewLogger(t)
+	r := router.New(&router.Config{
+		OllamaURL:       ts.URL,
+		DefaultProvider: router.ProviderOllama,
+		RequestTimeout:  5 * time.Second,
+	}, logger)
+	return New(r, logger), &calls
+}
+
+func TestResolveContradiction(t *testing.T) {
+	svc := newTestService(t, `{"decision":"CONTRADICTION","winner_index":2,"confidence":0.9,"reason":"User moved"}`)
+
+	node1 := &Node{UUID: "a", Name: "Lives in Berlin", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	node2 := &Node{UUID: "b", Name: "Lives in Paris", CreatedAt: time.Now()}
+
+	result, err := svc.Resolve(context.Background(), node1, node2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if result.Decision != DecisionContradiction {
+		t.Errorf("Expected decision %s, got %s", DecisionContradiction, result.Decision)
+	}
+	if result.WinnerIndex != 2 {
+		t.Errorf("Expected winner_index 2, got %d", result.WinnerIndex)
+	}
+	if result.Winner != node2 || result.Loser != node1 {
+		t.Errorf("Expected node2 to win over node1")
+	}
+	if result.Confidence != 0.9 {
+		t.Errorf("Expected confidence 0.9, got %v", result.Confidence)
+	}
+}
+
+func TestResolveComplementary(t *testing.T) {
+	svc := newTestService(t, `{"decision":"BOTH_VALID","confidence":0.85,"reason":"A person can like both"}`)
+
+	node1 := &Node{UUID: "a", Name: "Likes pizza", CreatedAt: time.Now().Add(-time.Hour)}
+	node2 := &Node{UUID: "b", Name: "Likes sushi", CreatedAt: time.Now()}
+
+	result, err := svc.Resolve(context.Background(), node1, node2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if result.Decision != DecisionBothValid {
+		t.Errorf("Expected decision %s, got %s", DecisionBothValid, result.Decision)
+	}
+	if result.WinnerIndex != 0 {
+		t.Errorf("Expected winner_index 0 for complementary facts, got %d", result.WinnerIndex)
+	}
+	if result.Winner != nil || result.Loser != nil {
+		t.Errorf("Expected no winner or loser when both facts are valid")
+	}
+}
+
+func TestResolveAbstainsBelowMinConfidence(t *testing.T) {
+	svc := newTestService(t, `{"decision":"CONTRADICTION","winner_index":1,"confidence":0.4,"reason":"Unclear"}`)
+
+	node1 := &Node{UUID: "a", Name: "Works at Acme", CreatedAt: time.Now()}
+	node2 := &Node{UUID: "b", Name: "Works at Globex", CreatedAt: time.Now()}
+
+	result, err := svc.Resolve(context.Background(), node1, node2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if result.Decision != DecisionBothValid {
+		t.Errorf("Expected low-confidence contradiction to abstain as %s, got %s", DecisionBothValid, result.Decision)
+	}
+	if result.Loser != nil {
+		t.Errorf("Expected no node to be archived on abstention")
+	}
+}
+
+func TestResolveHeuristicModeSkipsLLM(t *testing.T) {
+	svc, calls := newCountingTestService(t, `{"decision":"BOTH_VALID","confidence":0.9,"reason":"unused"}`)
+	svc.SetMode(ModeHeuristic)
+
+	node1 := &Node{UUID: "a", Name: "Lives in Berlin", CreatedAt: time.Now().Add(-60 * 24 * time.Hour)}
+	node2 := &Node{UUID: "b", Name: "Lives in Berlin", CreatedAt: time.Now()}
+
+	result, err := svc.Resolve(context.Background(), node1, node2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if n := calls.Load(); n != 0 {
+		t.Errorf("Expected no LLM calls in heuristic mode, got %d", n)
+	}
+	if result.Method != "heuristic" {
+		t.Errorf("Expected method heuristic, got %s", result.Method)
+	}
+	if result.Winner != node2 || result.Loser != node1 {
+		t.Errorf("Expected the more recent node to win")
+	}
+}
+
+func TestResolveAutoModeUsesLLMOnlyForAmbiguousPairs(t *testing.T) {
+	svc, calls := newCountingTestService(t, `{"decision":"CONTRADICTION","winner_index":1,"confidence":0.9,"reason":"Older is right"}`)
+	svc.SetMode(ModeAuto)
+	ctx := context.Background()
+
+	// Clearly distinct names: decided by heuristics
+	distinct1 := &Node{UUID: "a", Name: "Manager is Bob", CreatedAt: time.Now().Add(-60 * 24 * time.Hour)}
+	distinct2 := &Node{UUID: "b", Name: "Lives in Paris", CreatedAt: time.Now()}
+	result, err := svc.Resolve(ctx, distinct1, distinct2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("Expected no LLM call for a distinct pair, got %d", n)
+	}
+	if result.Method != "heuristic" {
+		t.Errorf("Expected method heuristic for a distinct pair, got %s", result.Method)
+	}
+	// Distinct facts show no contradiction, so nothing is archived
+	if result.Decision != DecisionBothValid || result.Loser != nil {
+		t.Errorf("Expected a distinct pair to keep both facts, got %s", result.Decision)
+	}
+
+	// Same name, different descriptions: ambiguous, so the LLM decides
+	same1 := &Node{UUID: "c", Name: "Favourite food", Description: "Pizza", CreatedAt: time.Now().Add(-time.Hour)}
+	same2 := &Node{UUID: "d", Name: "favourite food", Description: "Sushi", CreatedAt: time.Now()}
+	result, err = svc.Resolve(ctx, same1, same2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected one LLM call for an ambiguous pair, got %d", n)
+	}
+	if result.Method != "llm" || result.Winner != same1 {
+		t.Errorf("Expected the LLM verdict (node 1) for an ambiguous pair, got method=%s winner_index=%d", result.Method, result.WinnerIndex)
+	}
+}
+
+func TestResolveHeuristicAbstainsWithoutSignal(t *testing.T) {
+	svc := newTestService(t, `{"decision":"BOTH_VALID","confidence":0.9,"reason":"unused"}`)
+	svc.SetMode(ModeHeuristic)
+
+	created := time.Now().Add(-time.Hour)
+	node1 := &Node{UUID: "a", Name: "Works at Acme", CreatedAt: created}
+	node2 := &Node{UUID: "b", Name: "Works at Globex", CreatedAt: created}
+
+	result, err := svc.Resolve(context.Background(), node1, node2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if result.Decision != DecisionBothValid || result.Winner != nil || result.Loser != nil {
+		t.Errorf("Expected nodes scored alike to keep both, got %s (winner_index %d)", result.Decision, result.WinnerIndex)
+	}
+}
+
+func TestResolveWithModeOverridesDefault(t *testing.T) {
+	svc, calls := newCountingTestService(t, `{"decision":"BOTH_VALID","confidence":0.9,"reason":"Both hold"}`)
+
+	node1 := &Node{UUID: "a", Name: "Likes pizza", CreatedAt: time.Now()}
+	node2 := &Node{UUID: "b", Name: "Likes sushi", CreatedAt: time.Now()}
+
+	if _, err := svc.ResolveWithMode(context.Background(), ModeHeuristic, node1, node2); err != nil {
+		t.Fatalf("ResolveWithMode failed: %v", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("Expected heuristic override to skip the LLM, got %d calls", n)
+	}
+	if svc.Mode() != ModeLLM {
+		t.Errorf("Expected the default mode to stay %s, got %s", ModeLLM, svc.Mode())
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, name := range []string{"", "llm", "heuristic", "auto", " AUTO "} {
+		if _, err := ParseMode(name); err != nil {
+			t.Errorf("ParseMode(%q) failed: %v", name, err)
+		}
+	}
+	if _, err := ParseMode("cheap"); err == nil {
+		t.Errorf("Expected ParseMode to reject an unknown mode")
+	}
+}
+
+func TestResolveDefaultsMissingConfidence(t *testing.T) {
+	svc := newTestService(t, `{"decision":"CONTRADICTION","winner_index":2,"reason":"User moved"}`)
+
+	node1 := &Node{UUID: "a", Name: "Lives in Berlin", CreatedAt: time.Now().Add(-time.Hour)}
+	node2 := &Node{UUID: "b", Name: "Lives in Paris", CreatedAt: time.Now()}
+
+	result, err := svc.Resolve(context.Background(), node1, node2)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	if result.Confidence != DefaultConfidence {
+		t.Errorf("Expected confidence %v when the LLM omits it, got %v", DefaultConfidence, result.Confidence)
+	}
+	// Below the default threshold, so the unrated contradiction archives nothing
+	if result.Decision != DecisionBothValid || result.Loser != nil {
+		t.Errorf("Expected the unrated contradiction to abstain, got %s", result.Decision)
+	}
+}
+
+func TestParseConfidence(t *testing.T) {
+	for _, tc := range []struct {
+		raw  interface{}
+		want float64
+	}{
+		{0.9, 0.9},
+		{" 0.75", 0.75},
+		{1.7, 1},
+		{-0.2, 0},
+		{nil, DefaultConfidence},
+		{"high", DefaultConfidence},
+	} {
+		if got := parseConfidence(tc.raw); got != tc.want {
+			t.Errorf("parseConfidence(%#v) = %v, want %v", tc.raw, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package guardrail
+
+import (
+	"context"
+
+	"github.com/reflective-memory-kernel/internal/policy"
+)
+
+// ContentFilter runs responses through a policy content filter: content the
+// filter blocks is replaced by the fallback, and content it masks (PII,
+// secrets) is returned masked
+func ContentFilter(filter *policy.ContentFilter) Check {
+	return NewCheck("content_filter", func(ctx context.Context, query, response string) Verdict {
+		result, err := filter.Filter(ctx, "", response)
+		if err != nil || result.IsClean {
+			return Pass()
+		}
+		if result.Action == policy.ActionBlock {
+			return Fail("blocked by content filter ("+string(result.FilterType)+")", false)
+		}
+		return Verdict{Passed: true, Rewrite: result.MaskedText}
+	})
+}
@@ -0,0 +1,232 @@
+// Package guardrail validates generated responses before they reach the user.
+// A Guard runs a list of checks over each response; a response failing a
+// retryable check is generated once more with a stricter prompt, and one that
+// still fails is replaced by a fallback message.
+package guardrail
+
+import (
+	"context"
+	"strings"
+)
+
+// FallbackResponse replaces a response no attempt got past the checks
+const FallbackResponse = "I'm sorry, I couldn't put together a reliable answer to that. Could you rephrase the question?"
+
+// Verdict is a check's judgement of one response
+type Verdict struct {
+	Passed bool
+	Check  string // Name of the check that failed
+	Reason string
+
+	// Retry marks a failure a stricter prompt may fix (empty or leaked
+	// output); other failures go straight to the fallback
+	Retry bool
+
+	// Rewrite, on a passing verdict, replaces the response (e.g. masked)
+	Rewrite string
+}
+
+// Pass accepts a response unchanged
+func Pass() Verdict { return Verdict{Passed: true} }
+
+// Fail rejects a response
+func Fail(reason string, retry bool) Verdict {
+	return Verdict{Reason: reason, Retry: retry}
+}
+
+// Check inspects a generated response to query
+type Check interface {
+	Name() string
+	Check(ctx context.Context, query, response string) Verdict
+}
+
+// NewCheck makes a Check of a function, for deployment-specific rules
+func NewCheck(name string, fn func(ctx context.Context, query, response string) Verdict) Check {
+	return funcCheck{name: name, fn: fn}
+}
+
+type funcCheck struct {
+	name string
+	fn   func(ctx context.Context, query, response string) Verdict
+}
+
+func (c funcCheck) Name() string { return c.name }
+
+func (c funcCheck) Check(ctx context.Context, query, response string) Verdict {
+	return c.fn(ctx, query, response)
+}
+
+// Guard runs checks over generated responses
+type Guard struct {
+	checks []Check
+}
+
+// New returns a guard running checks in order
+func New(checks ...Check) *Guard {
+	return &Guard{checks: checks}
+}
+
+// Default returns a guard rejecting empty, degenerate, prompt-leaking and
+// refusing responses
+func Default() *Guard {
+	return New(NonEmpty(), NotDegenerate(), NoPromptLeak(), NoRefusal())
+}
+
+// Use adds a check run after the existing ones
+func (g *Guard) Use(check Check) {
+	g.checks = append(g.checks, check)
+}
+
+// Validate runs the checks in order, stopping at the first failure. Rewrites
+// by passing checks carry over to the checks after them and to the verdict.
+func (g *Guard) Validate(ctx context.Context, query, response string) Verdict {
+	result := Pass()
+	for _, check := range g.checks {
+		v := check.Check(ctx, query, response)
+		if !v.Passed {
+			v.Check = check.Name()
+			return v
+		}
+		if v.Rewrite != "" && v.Rewrite != response {
+			response = v.Rewrite
+			result.Rewrite = response
+		}
+	}
+	return result
+}
+
+// Result is a guarded generation
+type Result struct {
+	Response string
+	Retried  bool   // The first response failed a retryable check
+	Filtered bool   // The response was rewritten by a check or replaced by the fallback
+	Reason   string // Why the last rejected response failed
+}
+
+// Generate calls generate and validates its response. A retryable failure
+// calls generate once more with strict set; a response still failing is
+// replaced by FallbackResponse. Errors from generate are returned as they are.
+func (g *Guard) Generate(ctx context.Context, query string, generate func(ctx context.Context, strict bool) (string, error)) (*Result, error) {
+	result := &Result{}
+	for attempt := 0; attempt < 2; attempt++ {
+		strict := attempt > 0
+		response, err := generate(ctx, strict)
+		if err != nil {
+			return nil, err
+		}
+
+		v := g.Validate(ctx, query, response)
+		if v.Passed {
+			result.Response = response
+			if v.Rewrite != "" {
+				result.Response = v.Rewrite
+				result.Filtered = true
+			}
+			return result, nil
+		}
+
+		result.Reason = v.Check + ": " + v.Reason
+		if !v.Retry {
+			break
+		}
+		result.Retried = true
+	}
+	result.Response = FallbackResponse
+	result.Filtered = true
+	return result, nil
+}
+
+// NonEmpty rejects responses with no text
+func NonEmpty() Check {
+	return NewCheck("non_empty", func(ctx context.Context, query, response string) Verdict {
+		if strings.TrimSpace(response) == "" {
+			return Fail("empty response", true)
+		}
+		return Pass()
+	})
+}
+
+// degenerateMinWords is the length from which repetition is judged
+const degenerateMinWords = 20
+
+// NotDegenerate rejects responses without any letters or digits and long
+// responses made of a few words repeated over and over
+func NotDegenerate() Check {
+	return NewCheck("not_degenerate", func(ctx context.Context, query, response string) Verdict {
+		if !strings.ContainsFunc(response, isAlnum) {
+			return Fail("no words in response", true)
+		}
+		words := strings.Fields(strings.ToLower(response))
+		if len(words) < degenerateMinWords {
+			return Pass()
+		}
+		distinct := make(map[string]bool, len(words))
+		for _, w := range words {
+			distinct[w] = true
+		}
+		if len(distinct)*5 < len(words) {
+			return Fail("repetitive response", true)
+		}
+		return Pass()
+	})
+}
+
+func isAlnum(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r > 0x7f
+}
+
+// promptMarkers are fragments of the router's system prompt that never belong
+// in an answer
+var promptMarkers = []string{
+	"### memory context",
+	"### end memory context",
+	"answer from this!",
+	"you are a helpful ai assistant with access to the user's personal memory database",
+	"the information above is from the user's memory",
+}
+
+// NoPromptLeak rejects responses quoting the system prompt
+func NoPromptLeak() Check {
+	return NewCheck("no_prompt_leak", func(ctx context.Context, query, response string) Verdict {
+		lower := strings.ToLower(response)
+		for _, marker := range promptMarkers {
+			if strings.Contains(lower, marker) {
+				return Fail("response quotes the system prompt", true)
+			}
+		}
+		return Pass()
+	})
+}
+
+// refusalMaxLen is the longest response judged as a bare refusal; longer
+// ones explain themselves
+const refusalMaxLen = 200
+
+// refusalPrefixes open a bare refusal
+var refusalPrefixes = []string{
+	"i'm sorry, but i can't",
+	"i'm sorry, but i cannot",
+	"i cannot help with",
+	"i can't help with",
+	"i can't assist with",
+	"i cannot assist with",
+	"as an ai language model",
+}
+
+// NoRefusal rejects short responses that only decline to answer; memory
+// questions are the user's own data and never call for one
+func NoRefusal() Check {
+	return NewCheck("no_refusal", func(ctx context.Context, query, response string) Verdict {
+		trimmed := strings.ToLower(strings.TrimSpace(response))
+		if len(trimmed) > refusalMaxLen {
+			return Pass()
+		}
+		trimmed = strings.ReplaceAll(trimmed, "’", "'")
+		for _, prefix := range refusalPrefixes {
+			if strings.HasPrefix(trimmed, prefix) {
+				return Fail("unexpected refusal", true)
+			}
+		}
+		return Pass()
+	})
+}
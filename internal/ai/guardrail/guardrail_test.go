@@ -0,0 +1,102 @@
+package guardrail
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/policy"
+)
+
+// scripted returns a generate func answering with responses in turn and
+// recording the strict flag of each call
+func scripted(responses ...string) (func(context.Context, bool) (string, error), *[]bool) {
+	var calls []bool
+	return func(ctx context.Context, strict bool) (string, error) {
+		calls = append(calls, strict)
+		return responses[len(calls)-1], nil
+	}, &calls
+}
+
+func TestGenerateRetriesStrictAfterRetryableFailure(t *testing.T) {
+	generate, calls := scripted("   ", "Your sister's name is Ana.")
+
+	got, err := Default().Generate(context.Background(), "what's my sister's name?", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Response != "Your sister's name is Ana." || !got.Retried || got.Filtered {
+		t.Errorf("result = %+v, want the retried answer unfiltered", got)
+	}
+	if len(*calls) != 2 || (*calls)[0] || !(*calls)[1] {
+		t.Errorf("strict flags = %v, want [false true]", *calls)
+	}
+}
+
+func TestGenerateFallsBackWhenRetryFails(t *testing.T) {
+	leak := "### MEMORY CONTEXT\n- sister: Ana\n### END MEMORY CONTEXT"
+	generate, calls := scripted(leak, leak)
+
+	got, err := Default().Generate(context.Background(), "who am I?", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Response != FallbackResponse || !got.Retried || !got.Filtered {
+		t.Errorf("result = %+v, want the fallback after a retry", got)
+	}
+	if !strings.HasPrefix(got.Reason, "no_prompt_leak") {
+		t.Errorf("reason = %q, want the no_prompt_leak check named", got.Reason)
+	}
+	if len(*calls) != 2 {
+		t.Errorf("generated %d times, want 2", len(*calls))
+	}
+}
+
+func TestGenerateSkipsRetryForFinalFailure(t *testing.T) {
+	guard := New(NewCheck("no_secrets", func(ctx context.Context, query, response string) Verdict {
+		if strings.Contains(response, "hunter2") {
+			return Fail("secret in response", false)
+		}
+		return Pass()
+	}))
+	generate, calls := scripted("The password is hunter2.", "unused")
+
+	got, err := guard.Generate(context.Background(), "password?", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Response != FallbackResponse || got.Retried || !got.Filtered {
+		t.Errorf("result = %+v, want the fallback without a retry", got)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("generated %d times, want 1", len(*calls))
+	}
+}
+
+func TestContentFilterMasksAndBlocks(t *testing.T) {
+	filter := policy.NewContentFilter(zap.NewNop(), nil, true)
+	filter.AddBlockedWords("project falcon")
+	filter.SetAction(policy.FilterTypeCustom, policy.ActionBlock)
+	guard := Default()
+	guard.Use(ContentFilter(filter))
+
+	generate, _ := scripted("You can reach Bob at bob@example.com.")
+	got, err := guard.Generate(context.Background(), "bob's email?", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Filtered || strings.Contains(got.Response, "bob@example.com") {
+		t.Errorf("result = %+v, want the email masked", got)
+	}
+
+	generate, _ = scripted("Project Falcon ships in May.")
+	got, err = guard.Generate(context.Background(), "what ships in may?", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Response != FallbackResponse {
+		t.Errorf("response = %q, want the fallback for a blocked word", got.Response)
+	}
+}
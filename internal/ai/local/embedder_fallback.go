@@ -0,0 +1,159 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// initializer is implemented by embedders that validate themselves at startup
+type initializer interface {
+	Init() error
+	Dimension() int
+}
+
+// modelNamer is implemented by embedders that know their model once initialized
+type modelNamer interface {
+	Model() string
+}
+
+// FallbackEmbedder tries a primary embedder (local Ollama) and falls back to a
+// secondary one (remote) on failure. Every vector it returns comes from the
+// same model at the same dimension: two models can share a dimension yet
+// embed into different spaces, so matching the dimension alone would still
+// mix incompatible vectors in one index.
+type FallbackEmbedder struct {
+	chain     []LocalEmbedder
+	disabled  []bool // Members whose model or dimension is incompatible with the chain
+	model     string
+	dimension int
+	logger    *zap.Logger
+
+	// active is the index of the member that served the last request, for switch logging
+	active atomic.Int32
+}
+
+// NewFallbackEmbedder creates an embedder that tries primary then fallback.
+// model and dimension are the embedding model and vector size the downstream
+// index was built with.
+func NewFallbackEmbedder(primary, fallback LocalEmbedder, model string, dimension int, logger *zap.Logger) *FallbackEmbedder {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &FallbackEmbedder{
+		chain:     []LocalEmbedder{primary, fallback},
+		disabled:  make([]bool, 2),
+		model:     model,
+		dimension: dimension,
+		logger:    logger,
+	}
+}
+
+// Init validates each member. A member whose model or dimension differs from
+// the chain's is disabled; an unreachable one stays in the chain so it can
+// recover later. Returns an error only when no member is usable right now.
+func (f *FallbackEmbedder) Init() error {
+	var errs []error
+	usable := 0
+	for i, e := range f.chain {
+		v, ok := e.(initializer)
+		if !ok {
+			usable++
+			continue
+		}
+		if err := v.Init(); err != nil {
+			f.logger.Warn("Embedder unavailable", zap.Int("position", i), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		if v.Dimension() != f.dimension {
+			f.disabled[i] = true
+			err := fmt.Errorf("embedder %d produces %d dimensions, index expects %d", i, v.Dimension(), f.dimension)
+			f.logger.Error("Disabling embedder with incompatible dimension", zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		if err := f.checkModel(i, e); err != nil {
+			f.disabled[i] = true
+			f.logger.Error("Disabling embedder with incompatible model", zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		usable++
+	}
+
+	if usable == 0 {
+		return fmt.Errorf("no embedder available: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Embed generates an embedding with the first member that succeeds
+func (f *FallbackEmbedder) Embed(text string) ([]float32, error) {
+	vec, _, err := f.EmbedWithModel(text)
+	return vec, err
+}
+
+// EmbedWithModel is Embed, also returning the model of the member that served
+// the request
+func (f *FallbackEmbedder) EmbedWithModel(text string) ([]float32, string, error) {
+	var errs []error
+	for i, e := range f.chain {
+		if f.disabled[i] {
+			continue
+		}
+		vec, model, err := EmbedWithModel(e, text)
+		if err == nil && len(vec) != f.dimension {
+			err = fmt.Errorf("got %d dimensions, expected %d", len(vec), f.dimension)
+		}
+		// A remote member's model can change with the AI service's providers
+		if err == nil && model != "" && !SameEmbeddingModel(model, f.model) {
+			err = fmt.Errorf("embedder %d used model %q, expected %q", i, model, f.model)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if prev := f.active.Swap(int32(i)); prev != int32(i) {
+			f.logger.Warn("Embedder switched",
+				zap.Int("from", int(prev)),
+				zap.Int("to", i),
+				zap.Errors("errors", errs))
+		}
+		return vec, model, nil
+	}
+	return nil, "", fmt.Errorf("all embedders failed: %w", errors.Join(errs...))
+}
+
+// checkModel rejects a member whose model, once known, isn't the chain's
+func (f *FallbackEmbedder) checkModel(i int, e LocalEmbedder) error {
+	named, ok := e.(modelNamer)
+	if !ok || named.Model() == "" || SameEmbeddingModel(named.Model(), f.model) {
+		return nil
+	}
+	return fmt.Errorf("embedder %d serves model %q, index expects %q", i, named.Model(), f.model)
+}
+
+// Close closes every member
+func (f *FallbackEmbedder) Close() error {
+	var errs []error
+	for _, e := range f.chain {
+		if err := e.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Model returns the embedding model shared by the chain
+func (f *FallbackEmbedder) Model() string {
+	return f.model
+}
+
+// Dimension returns the embedding dimension shared by the chain
+func (f *FallbackEmbedder) Dimension() int {
+	return f.dimension
+}
@@ -0,0 +1,90 @@
+package local
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeEmbedder is a chain member serving one model at one dimension
+type fakeEmbedder struct {
+	model string
+	dim   int
+	down  bool
+	calls int
+}
+
+func (e *fakeEmbedder) Init() error {
+	if e.down {
+		return errors.New("unreachable")
+	}
+	return nil
+}
+
+func (e *fakeEmbedder) Dimension() int { return e.dim }
+func (e *fakeEmbedder) Model() string  { return e.model }
+func (e *fakeEmbedder) Close() error   { return nil }
+
+func (e *fakeEmbedder) Embed(text string) ([]float32, error) {
+	vec, _, err := e.EmbedWithModel(text)
+	return vec, err
+}
+
+func (e *fakeEmbedder) EmbedWithModel(text string) ([]float32, string, error) {
+	e.calls++
+	if e.down {
+		return nil, "", errors.New("unreachable")
+	}
+	return make([]float32, e.dim), e.model, nil
+}
+
+func TestFallbackEmbedderRejectsOtherModels(t *testing.T) {
+	primary := &fakeEmbedder{model: "nomic-embed-text", dim: 768, down: true}
+	// Same dimension, different embedding space
+	remote := &fakeEmbedder{model: "text-embedding-3-small", dim: 768}
+	f := NewFallbackEmbedder(primary, remote, "nomic-embed-text", 768, zaptest.NewLogger(t))
+
+	if err := f.Init(); err == nil {
+		t.Fatal("Init succeeded with only a fallback serving another model")
+	}
+	if _, err := f.Embed("hello"); err == nil {
+		t.Error("Embed returned a vector from another model")
+	}
+	if remote.calls != 0 {
+		t.Errorf("disabled fallback was called %d times", remote.calls)
+	}
+}
+
+func TestFallbackEmbedderUsesSameModelFallback(t *testing.T) {
+	primary := &fakeEmbedder{model: "nomic-embed-text", dim: 768, down: true}
+	remote := &fakeEmbedder{model: "nomic-embed-text:latest", dim: 768}
+	f := NewFallbackEmbedder(primary, remote, "nomic-embed-text", 768, zaptest.NewLogger(t))
+
+	if err := f.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	vec, model, err := f.EmbedWithModel("hello")
+	if err != nil || len(vec) != 768 || model != "nomic-embed-text:latest" {
+		t.Fatalf("EmbedWithModel = %d dims, %q, %v", len(vec), model, err)
+	}
+
+	// The AI service switching providers after Init is caught per request
+	remote.model = "text-embedding-3-small"
+	if _, err := f.Embed("hello"); err == nil {
+		t.Error("Embed accepted a vector after the fallback changed model")
+	}
+}
+
+func TestFallbackEmbedderRejectsOtherDimensions(t *testing.T) {
+	primary := &fakeEmbedder{model: "nomic-embed-text", dim: 384}
+	remote := &fakeEmbedder{model: "nomic-embed-text", dim: 768}
+	f := NewFallbackEmbedder(primary, remote, "nomic-embed-text", 768, zaptest.NewLogger(t))
+
+	if err := f.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if _, err := f.Embed("hello"); err != nil || primary.calls != 0 {
+		t.Errorf("Embed = %v after %d primary calls, want the 768-dimension fallback only", err, primary.calls)
+	}
+}
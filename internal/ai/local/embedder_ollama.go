@@ -7,10 +7,19 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
+const (
+	// DefaultOllamaURL is used when neither an explicit URL nor OLLAMA_URL is set
+	DefaultOllamaURL = "http://localhost:11434"
+	// DefaultEmbeddingModel is used when neither an explicit model nor OLLAMA_EMBED_MODEL is set
+	DefaultEmbeddingModel = "nomic-embed-text"
+)
+
 // OllamaEmbedder generates embeddings using Ollama's embedding API
 type OllamaEmbedder struct {
 	baseURL    string
@@ -30,28 +39,73 @@ type OllamaEmbeddingResponse struct {
 	Embedding []float64 `json:"embedding"`
 }
 
-// NewOllamaEmbedder creates a new Ollama-based embedder
+// NewOllamaEmbedder creates a new Ollama-based embedder.
+// Empty arguments fall back to OLLAMA_URL / OLLAMA_EMBED_MODEL, then to the defaults.
+// Call Init before use to validate the configuration and learn the vector dimension.
 func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
 	if baseURL == "" {
 		baseURL = os.Getenv("OLLAMA_URL")
 		if baseURL == "" {
-			baseURL = "http://localhost:11434"
+			baseURL = DefaultOllamaURL
 		}
 	}
 	if model == "" {
-		model = "nomic-embed-text" // Default embedding model
+		model = os.Getenv("OLLAMA_EMBED_MODEL")
+		if model == "" {
+			model = DefaultEmbeddingModel
+		}
 	}
 
 	return &OllamaEmbedder{
-		baseURL: baseURL,
+		baseURL: strings.TrimRight(baseURL, "/"),
 		model:   model,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		dimension: 768, // nomic-embed-text dimension
 	}
 }
 
+// Init validates the URL, checks the model is pulled in Ollama and probes it once
+// to record the vector dimension. It fails fast instead of letting every Embed call
+// error later and silently disable vector search.
+func (e *OllamaEmbedder) Init() error {
+	u, err := url.Parse(e.baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid Ollama URL %q: must be an absolute http(s) URL", e.baseURL)
+	}
+
+	available, err := e.hasModel()
+	if err != nil {
+		return fmt.Errorf("Ollama not reachable at %s: %w", e.baseURL, err)
+	}
+	if !available {
+		return fmt.Errorf("embedding model %q is not pulled in Ollama at %s (run: ollama pull %s)", e.model, e.baseURL, e.model)
+	}
+
+	vec, err := e.Embed("dimension probe")
+	if err != nil {
+		return fmt.Errorf("embedding model %q failed probe: %w", e.model, err)
+	}
+	e.dimension = len(vec)
+	return nil
+}
+
+// Model returns the configured embedding model name
+func (e *OllamaEmbedder) Model() string {
+	return e.model
+}
+
+// BaseURL returns the configured Ollama URL
+func (e *OllamaEmbedder) BaseURL() string {
+	return e.baseURL
+}
+
+// EmbedWithModel is Embed, also returning the configured model
+func (e *OllamaEmbedder) EmbedWithModel(text string) ([]float32, string, error) {
+	vec, err := e.Embed(text)
+	return vec, e.model, err
+}
+
 // Embed generates an embedding vector for the given text
 func (e *OllamaEmbedder) Embed(text string) ([]float32, error) {
 	reqBody := OllamaEmbeddingRequest{
@@ -109,30 +163,44 @@ func (e *OllamaEmbedder) Embed(text string) ([]float32, error) {
 	return embedding, nil
 }
 
-// EnsureModel pulls the embedding model if not already present
-func (e *OllamaEmbedder) EnsureModel() error {
-	// Check if model exists
+// hasModel reports whether the embedding model is already pulled
+func (e *OllamaEmbedder) hasModel() (bool, error) {
 	resp, err := e.httpClient.Get(e.baseURL + "/api/tags")
 	if err != nil {
-		return fmt.Errorf("failed to check models: %w", err)
+		return false, fmt.Errorf("failed to check models: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to check models (status %d)", resp.StatusCode)
+	}
+
 	var tagsResp struct {
 		Models []struct {
 			Name string `json:"name"`
 		} `json:"models"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&tagsResp); err != nil {
-		return fmt.Errorf("failed to decode tags: %w", err)
+		return false, fmt.Errorf("failed to decode tags: %w", err)
 	}
 
-	// Check if our model is already pulled
 	for _, m := range tagsResp.Models {
 		if m.Name == e.model || m.Name == e.model+":latest" {
-			return nil // Model already exists
+			return true, nil
 		}
 	}
+	return false, nil
+}
+
+// EnsureModel pulls the embedding model if not already present
+func (e *OllamaEmbedder) EnsureModel() error {
+	available, err := e.hasModel()
+	if err != nil {
+		return err
+	}
+	if available {
+		return nil
+	}
 
 	// Pull the model
 	pullReq := struct {
@@ -162,7 +230,7 @@ func (e *OllamaEmbedder) Close() error {
 	return nil
 }
 
-// Dimension returns the embedding dimension
+// Dimension returns the embedding dimension (0 until Init succeeds)
 func (e *OllamaEmbedder) Dimension() int {
 	return e.dimension
 }
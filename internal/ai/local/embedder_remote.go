@@ -0,0 +1,117 @@
+package local
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RemoteEmbedder generates embeddings through the AI service's /embed endpoint
+type RemoteEmbedder struct {
+	baseURL    string
+	dimensions int // Requested vector size; 0 lets the provider choose
+	httpClient *http.Client
+	dimension  int
+	model      string // Model the AI service reported at Init
+}
+
+// remoteEmbedRequest is the request payload for the AI service /embed endpoint
+type remoteEmbedRequest struct {
+	Texts      []string `json:"texts"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+// remoteEmbedResponse is the response from the AI service /embed endpoint
+type remoteEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+	Model      string      `json:"model"`
+	Dimension  int         `json:"dimension"`
+}
+
+// NewRemoteEmbedder creates an embedder backed by the AI service at aiServiceURL.
+// dimensions asks the provider for vectors of that size so they match local ones.
+func NewRemoteEmbedder(aiServiceURL string, dimensions int) *RemoteEmbedder {
+	return &RemoteEmbedder{
+		baseURL:    strings.TrimRight(aiServiceURL, "/"),
+		dimensions: dimensions,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Init probes the endpoint once to record the vector dimension and model
+func (e *RemoteEmbedder) Init() error {
+	if e.baseURL == "" {
+		return fmt.Errorf("no AI service URL configured for remote embeddings")
+	}
+
+	vec, model, err := e.EmbedWithModel("dimension probe")
+	if err != nil {
+		return fmt.Errorf("remote embedder at %s failed probe: %w", e.baseURL, err)
+	}
+	if e.dimensions > 0 && len(vec) != e.dimensions {
+		return fmt.Errorf("remote embedder returned %d dimensions, requested %d", len(vec), e.dimensions)
+	}
+	e.dimension = len(vec)
+	e.model = model
+	return nil
+}
+
+// Embed generates an embedding vector for the given text
+func (e *RemoteEmbedder) Embed(text string) ([]float32, error) {
+	vec, _, err := e.EmbedWithModel(text)
+	return vec, err
+}
+
+// EmbedWithModel is Embed, also returning the model the AI service used,
+// which depends on the providers it has configured
+func (e *RemoteEmbedder) EmbedWithModel(text string) ([]float32, string, error) {
+	jsonData, err := json.Marshal(remoteEmbedRequest{
+		Texts:      []string{text},
+		Dimensions: e.dimensions,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := e.httpClient.Post(e.baseURL+"/embed", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to call AI service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("AI service embed error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result remoteEmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(result.Embeddings) == 0 || len(result.Embeddings[0]) == 0 {
+		return nil, "", fmt.Errorf("empty embedding returned")
+	}
+
+	return result.Embeddings[0], result.Model, nil
+}
+
+// Model returns the model the AI service reported at Init ("" before)
+func (e *RemoteEmbedder) Model() string {
+	return e.model
+}
+
+// Close cleans up resources (no-op for HTTP client)
+func (e *RemoteEmbedder) Close() error {
+	return nil
+}
+
+// Dimension returns the embedding dimension (0 until Init succeeds)
+func (e *RemoteEmbedder) Dimension() int {
+	return e.dimension
+}
@@ -1,5 +1,7 @@
 package local
 
+import "strings"
+
 // LocalEmbedder is the interface for local embedding generation
 type LocalEmbedder interface {
 	// Embed generates an embedding vector for the given text
@@ -7,3 +9,33 @@ type LocalEmbedder interface {
 	// Close cleans up resources
 	Close() error
 }
+
+// ModelEmbedder is an embedder that names the model behind each vector.
+// Vectors from different models live in different spaces even at the same
+// dimension, so whatever stores them must keep them apart.
+type ModelEmbedder interface {
+	LocalEmbedder
+	// EmbedWithModel is Embed, also returning the model that produced the vector
+	EmbedWithModel(text string) ([]float32, string, error)
+}
+
+// EmbedWithModel embeds text with e, naming the model when e can; otherwise
+// the model is empty
+func EmbedWithModel(e LocalEmbedder, text string) ([]float32, string, error) {
+	if m, ok := e.(ModelEmbedder); ok {
+		return m.EmbedWithModel(text)
+	}
+	vec, err := e.Embed(text)
+	return vec, "", err
+}
+
+// EmbeddingModelID normalizes a model name: case and Ollama's implicit
+// ":latest" tag don't change the model
+func EmbeddingModelID(model string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(model)), ":latest")
+}
+
+// SameEmbeddingModel reports whether two model names denote the same model
+func SameEmbeddingModel(a, b string) bool {
+	return EmbeddingModelID(a) == EmbeddingModelID(b)
+}
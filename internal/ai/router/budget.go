@@ -0,0 +1,185 @@
+package router
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// Context windows in tokens. Each provider's entry is the window of the model
+// Generate uses by default; modelContextWindows covers other models callers
+// ask for by name.
+var (
+	providerContextWindows = map[Provider]int{
+		ProviderGLM:       128000,
+		ProviderNVIDIA:    128000,
+		ProviderOpenAI:    128000,
+		ProviderAnthropic: 200000,
+		ProviderOllama:    ollamaContextWindow,
+	}
+	modelContextWindows = map[string]int{
+		"glm-4-plus":                   128000,
+		"glm-4-flash":                  128000,
+		"meta/llama-3.1-8b-instruct":   128000,
+		"meta/llama-3.1-405b-instruct": 128000,
+		"gpt-4o":                       128000,
+		"gpt-3.5-turbo":                16385,
+		"llama3.1":                     ollamaContextWindow,
+	}
+)
+
+// ollamaContextWindow is the num_ctx Ollama requests are sent with; Ollama
+// silently drops whatever doesn't fit, so prompts are budgeted against it
+const ollamaContextWindow = 8192
+
+// defaultContextWindow is assumed for models not listed above
+const defaultContextWindow = 8192
+
+// maxReplyReserve caps the tokens held back from a context window for the reply
+const maxReplyReserve = 4096
+
+// truncationMarker replaces the text Truncate cuts out
+const truncationMarker = "\n[... truncated ...]\n"
+
+// EstimateTokens approximates how many tokens text takes. It assumes three
+// bytes per token, which overestimates English (about four) and stays close
+// for multi-byte scripts, so budgets computed from it err on the safe side.
+func EstimateTokens(text string) int {
+	return (len(text) + 2) / 3
+}
+
+// ContextWindow returns the context window of a provider's model in tokens.
+// An empty model means the provider's default.
+func ContextWindow(provider Provider, model string) int {
+	if window, ok := modelContextWindows[model]; ok {
+		return window
+	}
+	if window, ok := providerContextWindows[provider]; ok {
+		return window
+	}
+	return defaultContextWindow
+}
+
+// PromptBudget returns how many tokens of prompt (system instruction plus
+// query) a provider's model accepts while leaving room for the reply. An empty
+// provider means the router's default; Config.PromptTokenBudget, when set,
+// caps the result.
+func (r *Router) PromptBudget(provider Provider, model string) int {
+	if provider == "" {
+		_, provider = r.snapshot()
+	}
+	window := ContextWindow(provider, model)
+	budget := window - min(window/4, maxReplyReserve)
+	if limit := r.config.PromptTokenBudget; limit > 0 && limit < budget {
+		budget = limit
+	}
+	return budget
+}
+
+// PromptTokenBudgetFromEnv reads AI_PROMPT_TOKEN_BUDGET; 0 when unset or invalid
+func PromptTokenBudgetFromEnv() int {
+	budget, err := strconv.Atoi(os.Getenv("AI_PROMPT_TOKEN_BUDGET"))
+	if err != nil || budget < 0 {
+		return 0
+	}
+	return budget
+}
+
+// fitPrompt truncates a prompt that exceeds the model's budget. The query is
+// kept whole where possible: the system instruction (which carries retrieved
+// context) is cut first, down to half the budget, then the query.
+func (r *Router) fitPrompt(provider Provider, model, system, query string) (string, string, bool) {
+	budget := r.PromptBudget(provider, model)
+	systemTokens, queryTokens := EstimateTokens(system), EstimateTokens(query)
+	if systemTokens+queryTokens <= budget {
+		return system, query, false
+	}
+
+	if systemTokens > budget/2 {
+		system, _ = Truncate(system, max(budget/2, budget-queryTokens))
+	}
+	query, _ = Truncate(query, budget-EstimateTokens(system))
+	r.logger.Warn("Prompt exceeds the model's budget, truncated",
+		zap.String("provider", string(provider)),
+		zap.String("model", model),
+		zap.Int("estimated_tokens", systemTokens+queryTokens),
+		zap.Int("budget", budget))
+	return system, query, true
+}
+
+// Truncate shortens text to about maxTokens, keeping its beginning and end
+// (prompts tend to put instructions at both) and marking the cut. It reports
+// whether anything was removed.
+func Truncate(text string, maxTokens int) (string, bool) {
+	if EstimateTokens(text) <= maxTokens {
+		return text, false
+	}
+	keep := maxTokens*3 - len(truncationMarker)
+	if keep <= 0 {
+		return "", true
+	}
+	head := keep * 2 / 3
+	tail := keep - head
+	return trimToRune(text[:head], false) + truncationMarker + trimToRune(text[len(text)-tail:], true), true
+}
+
+// SplitToBudget splits text into chunks of at most maxTokens each, breaking
+// at paragraph, line, sentence or word boundaries where it can
+func SplitToBudget(text string, maxTokens int) []string {
+	if EstimateTokens(text) <= maxTokens {
+		return []string{text}
+	}
+	maxBytes := max(maxTokens*3, 1)
+
+	var chunks []string
+	for len(text) > maxBytes {
+		cut := splitPoint(text[:maxBytes])
+		if cut == 0 {
+			// A budget smaller than one character: take the character anyway
+			_, cut = utf8.DecodeRuneInString(text)
+		}
+		if chunk := strings.TrimSpace(text[:cut]); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		text = text[cut:]
+	}
+	if chunk := strings.TrimSpace(text); chunk != "" {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// splitPoint returns where to end a chunk taken from window: after the last
+// paragraph break, line break, sentence end or space in its second half, or at
+// the window's end (backed off to a rune boundary)
+func splitPoint(window string) int {
+	half := len(window) / 2
+	for _, sep := range []string{"\n\n", "\n", ". ", " "} {
+		if i := strings.LastIndex(window, sep); i >= half {
+			return i + len(sep)
+		}
+	}
+	return len(trimToRune(window, false))
+}
+
+// trimToRune drops a partial UTF-8 sequence left at the end of s (or, with
+// fromStart, at its beginning) by slicing on a byte offset
+func trimToRune(s string, fromStart bool) string {
+	if fromStart {
+		for len(s) > 0 && !utf8.RuneStart(s[0]) {
+			s = s[1:]
+		}
+		return s
+	}
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if r != utf8.RuneError || size > 1 {
+			break
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSplitToBudget(t *testing.T) {
+	paragraph := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	text := strings.Repeat(paragraph+"\n\n", 10) + "Zürich und Köln"
+
+	chunks := SplitToBudget(text, 200)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want the text split", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if EstimateTokens(chunk) > 200 {
+			t.Errorf("chunk %d has %d tokens, over the budget", i, EstimateTokens(chunk))
+		}
+	}
+	if got, want := strings.Join(strings.Fields(strings.Join(chunks, " ")), " "), strings.Join(strings.Fields(text), " "); got != want {
+		t.Errorf("chunks lose or reorder text")
+	}
+
+	if chunks := SplitToBudget("short", 200); len(chunks) != 1 || chunks[0] != "short" {
+		t.Errorf("short text split into %q", chunks)
+	}
+}
+
+func TestTruncateKeepsBothEnds(t *testing.T) {
+	text := "INSTRUCTIONS " + strings.Repeat("filler ", 1000) + " JSON:"
+	got, truncated := Truncate(text, 100)
+	if !truncated || EstimateTokens(got) > 100 {
+		t.Fatalf("Truncate = %d tokens, truncated %v", EstimateTokens(got), truncated)
+	}
+	if !strings.HasPrefix(got, "INSTRUCTIONS") || !strings.HasSuffix(got, "JSON:") || !strings.Contains(got, truncationMarker) {
+		t.Errorf("Truncate lost an end or the marker: %q", got)
+	}
+	if got, truncated := Truncate("short", 100); truncated || got != "short" {
+		t.Errorf("Truncate(short) = %q, %v", got, truncated)
+	}
+}
+
+func TestGenerateTruncatesOversizedPrompt(t *testing.T) {
+	var sent int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		for _, m := range req.Messages {
+			sent += EstimateTokens(m.Content)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": map[string]string{"role": "assistant", "content": "ok"},
+		})
+	}))
+	defer ts.Close()
+
+	r := New(&Config{OllamaURL: ts.URL, DefaultProvider: ProviderOllama, PromptTokenBudget: 500}, zaptest.NewLogger(t))
+	resp, err := r.Generate(context.Background(), &GenerateRequest{
+		Query:   strings.Repeat("a long document ", 2000),
+		Context: strings.Repeat("retrieved memory ", 2000),
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !resp.Truncated || sent > 500 {
+		t.Errorf("truncated = %v, sent %d tokens; want truncated to the 500 token budget", resp.Truncated, sent)
+	}
+}
@@ -0,0 +1,169 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/reflective-memory-kernel/internal/jsonx"
+)
+
+const (
+	// DefaultOpenAIEmbeddingModel supports a "dimensions" parameter, so it can match
+	// the local nomic-embed-text vectors (768) stored in Qdrant
+	DefaultOpenAIEmbeddingModel = "text-embedding-3-small"
+	// DefaultOllamaEmbeddingModel is used when no OpenAI key is configured
+	DefaultOllamaEmbeddingModel = "nomic-embed-text"
+)
+
+// EmbedRequest represents an embedding request
+type EmbedRequest struct {
+	Texts      []string `json:"texts"`
+	Model      string   `json:"model,omitempty"`
+	Dimensions int      `json:"dimensions,omitempty"` // Requested vector size (OpenAI only)
+}
+
+// EmbedResponse represents an embedding response
+type EmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+	Provider   Provider    `json:"provider"`
+	Model      string      `json:"model"`
+	Dimension  int         `json:"dimension"`
+}
+
+// Embed generates embeddings, preferring OpenAI when a key is configured and
+// falling back to the router's Ollama instance otherwise
+func (r *Router) Embed(ctx context.Context, req *EmbedRequest) (*EmbedResponse, error) {
+	if len(req.Texts) == 0 {
+		return nil, fmt.Errorf("no texts to embed")
+	}
+
+	var (
+		embeddings [][]float32
+		provider   Provider
+		model      = req.Model
+		err        error
+	)
+	if keys, _ := r.snapshot(); keys.openai != "" {
+		provider = ProviderOpenAI
+		if model == "" {
+			model = DefaultOpenAIEmbeddingModel
+		}
+		embeddings, err = r.embedOpenAI(ctx, req.Texts, model, req.Dimensions, keys.openai)
+	} else {
+		provider = ProviderOllama
+		if model == "" {
+			model = DefaultOllamaEmbeddingModel
+		}
+		embeddings, err = r.embedOllama(ctx, req.Texts, model)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("provider %s failed: %w", provider, err)
+	}
+	if len(embeddings) != len(req.Texts) {
+		return nil, fmt.Errorf("provider %s returned %d embeddings for %d texts", provider, len(embeddings), len(req.Texts))
+	}
+
+	dimension := len(embeddings[0])
+	for _, e := range embeddings {
+		if len(e) == 0 || len(e) != dimension {
+			return nil, fmt.Errorf("provider %s returned inconsistent embedding dimensions", provider)
+		}
+	}
+
+	return &EmbedResponse{
+		Embeddings: embeddings,
+		Provider:   provider,
+		Model:      model,
+		Dimension:  dimension,
+	}, nil
+}
+
+// embedOpenAI calls the OpenAI embeddings API
+func (r *Router) embedOpenAI(ctx context.Context, texts []string, model string, dimensions int, apiKey string) ([][]float32, error) {
+	reqBody := map[string]interface{}{
+		"model": model,
+		"input": texts,
+	}
+	if dimensions > 0 {
+		reqBody["dimensions"] = dimensions
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := r.postJSON(ctx, r.baseURL(ProviderOpenAI)+"/embeddings", reqBody, map[string]string{
+		"Authorization": "Bearer " + apiKey,
+		"Content-Type":  "application/json",
+	}, &result); err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(result.Data))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(embeddings) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+	return embeddings, nil
+}
+
+// embedOllama calls Ollama's batch embed API
+func (r *Router) embedOllama(ctx context.Context, texts []string, model string) ([][]float32, error) {
+	reqBody := map[string]interface{}{
+		"model": model,
+		"input": texts,
+	}
+
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	url := fmt.Sprintf("%s/api/embed", r.config.OllamaURL)
+	if err := r.postJSON(ctx, url, reqBody, map[string]string{
+		"Content-Type": "application/json",
+	}, &result); err != nil {
+		return nil, err
+	}
+	return result.Embeddings, nil
+}
+
+// postJSON makes an HTTP request and decodes the raw JSON response into out
+func (r *Router) postJSON(ctx context.Context, url string, body map[string]interface{}, headers map[string]string, out interface{}) error {
+	jsonBody, err := jsonx.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := jsonx.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// providerKeys is the set of server credentials one request runs with.
+// UpdateKeys replaces it whole, so a request that took a snapshot keeps
+// using the same keys however many rotations happen while it runs.
+type providerKeys struct {
+	glm       string
+	nvidia    string
+	openai    string
+	anthropic string
+	minimax   string
+}
+
+func providerKeysFromConfig(cfg *Config) providerKeys {
+	return providerKeys{
+		glm:       cfg.GLMKey,
+		nvidia:    cfg.NVIDIAKey,
+		openai:    cfg.OpenAIKey,
+		anthropic: cfg.AnthropicKey,
+		minimax:   cfg.MiniMaxKey,
+	}
+}
+
+// slot returns where provider's key is kept, or nil for a provider without one
+func (k *providerKeys) slot(provider Provider) *string {
+	switch provider {
+	case ProviderGLM:
+		return &k.glm
+	case ProviderNVIDIA:
+		return &k.nvidia
+	case ProviderOpenAI:
+		return &k.openai
+	case ProviderAnthropic:
+		return &k.anthropic
+	case ProviderMiniMax:
+		return &k.minimax
+	}
+	return nil
+}
+
+// availableProviders lists the providers keys can reach; Ollama needs no key
+// and is always available as the local fallback
+func availableProviders(keys providerKeys) map[Provider]bool {
+	providers := map[Provider]bool{ProviderOllama: true}
+	for _, p := range []Provider{ProviderGLM, ProviderNVIDIA, ProviderOpenAI, ProviderAnthropic, ProviderMiniMax} {
+		if *keys.slot(p) != "" {
+			providers[p] = true
+		}
+	}
+	return providers
+}
+
+// snapshot returns the keys and default provider for one request
+func (r *Router) snapshot() (providerKeys, Provider) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys, r.defaultProvider
+}
+
+// UpdateKeys rotates the server's provider keys without a restart. keys maps
+// provider names ("glm", "nvidia", "openai", "anthropic", "minimax") to their
+// new key; an empty key removes the provider and providers left out keep
+// theirs. Requests already running finish with the keys they started with.
+// The update is all or nothing: an unknown provider name changes nothing.
+// It returns the providers available afterwards.
+func (r *Router) UpdateKeys(keys map[string]string) ([]Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := r.keys
+	for name, key := range keys {
+		provider, err := ParseProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		slot := next.slot(provider)
+		if slot == nil {
+			return nil, fmt.Errorf("provider %s does not take an API key", provider)
+		}
+		*slot = key
+	}
+
+	r.keys = next
+	r.providers = availableProviders(next)
+	if !r.providers[r.defaultProvider] {
+		// The default lost its key; pick again as DefaultConfig does
+		switch {
+		case r.providers[ProviderGLM]:
+			r.defaultProvider = ProviderGLM
+		case r.providers[ProviderNVIDIA]:
+			r.defaultProvider = ProviderNVIDIA
+		default:
+			r.defaultProvider = ProviderOllama
+		}
+	}
+
+	available := make([]Provider, 0, len(r.providers))
+	for p := range r.providers {
+		available = append(available, p)
+	}
+	sort.Slice(available, func(i, j int) bool { return available[i] < available[j] })
+
+	r.logger.Info("Provider keys rotated",
+		zap.Int("updated", len(keys)),
+		zap.Any("providers", available),
+		zap.String("default_provider", string(r.defaultProvider)))
+	return available, nil
+}
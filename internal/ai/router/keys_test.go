@@ -0,0 +1,94 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpdateKeysRotatesDuringGeneration(t *testing.T) {
+	r, _ := newKeyTestRouter(t, false)
+
+	issued := sync.Map{}
+	issued.Store("server-key", true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				resp, err := r.Generate(ctx, &GenerateRequest{Query: "hi"})
+				if err != nil {
+					if ctx.Err() == nil {
+						t.Errorf("Generate: %v", err)
+					}
+					return
+				}
+				if _, ok := issued.Load(resp.Content); !ok {
+					t.Errorf("completion used key %q, never issued", resp.Content)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("rotated-%d", i)
+		issued.Store(key, true)
+		if _, err := r.UpdateKeys(map[string]string{"openai": key}); err != nil {
+			t.Fatalf("UpdateKeys: %v", err)
+		}
+		r.GetProviderInfo()
+	}
+	cancel()
+	wg.Wait()
+
+	resp, err := r.Generate(context.Background(), &GenerateRequest{Query: "hi"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if resp.Content != "rotated-49" {
+		t.Errorf("completion used key %q, want the last rotated key", resp.Content)
+	}
+}
+
+func TestUpdateKeysAllOrNothing(t *testing.T) {
+	r, _ := newKeyTestRouter(t, false)
+
+	_, err := r.UpdateKeys(map[string]string{"openai": "new", "bogus": "x"})
+	if !errors.Is(err, ErrInvalidProvider) {
+		t.Fatalf("err = %v, want ErrInvalidProvider", err)
+	}
+	if _, err := r.UpdateKeys(map[string]string{"ollama": "x"}); err == nil {
+		t.Error("UpdateKeys accepted a key for ollama")
+	}
+	if keys, _ := r.snapshot(); keys.openai != "server-key" {
+		t.Errorf("openai key = %q after a rejected update, want it unchanged", keys.openai)
+	}
+}
+
+func TestUpdateKeysRemovingDefaultProvider(t *testing.T) {
+	r, _ := newKeyTestRouter(t, false)
+
+	available, err := r.UpdateKeys(map[string]string{"openai": "", "nvidia": "nv-key"})
+	if err != nil {
+		t.Fatalf("UpdateKeys: %v", err)
+	}
+	if got := fmt.Sprint(available); got != "[nvidia ollama]" {
+		t.Errorf("available = %s, want [nvidia ollama]", got)
+	}
+	if r.IsProviderAvailable(ProviderOpenAI) {
+		t.Error("openai still available after its key was removed")
+	}
+	if p := r.GetDefaultProvider(); p != ProviderNVIDIA {
+		t.Errorf("default provider = %s, want nvidia", p)
+	}
+	if _, err := r.Generate(context.Background(), &GenerateRequest{Query: "hi", Provider: ProviderOpenAI}); err == nil || !strings.Contains(err.Error(), "no OpenAI API key") {
+		t.Errorf("err = %v, want a missing OpenAI key", err)
+	}
+}
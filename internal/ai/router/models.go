@@ -0,0 +1,74 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidProvider is returned for a provider name the router doesn't know
+	ErrInvalidProvider = errors.New("invalid provider")
+	// ErrUnknownModel is returned when a request names a model its provider
+	// doesn't serve
+	ErrUnknownModel = errors.New("unknown model")
+)
+
+// providerModels lists the models each provider is known to serve. The first
+// is the one Generate uses when a request doesn't name a model.
+var providerModels = map[Provider][]string{
+	ProviderGLM: {"glm-4.5", "glm-4-plus", "glm-4-flash"},
+	ProviderNVIDIA: {
+		"meta/llama-3.1-70b-instruct", "meta/llama-3.1-8b-instruct", "meta/llama-3.1-405b-instruct",
+		"moonshotai/kimi-k2-instruct-0905", "minimaxai/minimax-m2",
+	},
+	ProviderOpenAI:    {"gpt-4o-mini", "gpt-4o", "gpt-3.5-turbo"},
+	ProviderAnthropic: {"claude-3-haiku-20240307", "claude-3-sonnet", "claude-3-opus"},
+	ProviderMiniMax:   {"abab6.5-chat", "abab6.5s-chat"},
+	ProviderOllama:    {"llama3.2", "llama3.1", "mistral"},
+}
+
+// Models returns the models a provider is known to serve, its default first
+func Models(provider Provider) []string {
+	return providerModels[provider]
+}
+
+// defaultModel is the model Generate uses for a provider when none is named
+func defaultModel(provider Provider) string {
+	if models := providerModels[provider]; len(models) > 0 {
+		return models[0]
+	}
+	return ""
+}
+
+// ValidateModel checks that provider serves model. Ollama runs whatever has
+// been pulled locally, so any model name is accepted for it.
+func ValidateModel(provider Provider, model string) error {
+	if !IsValidProvider(string(provider)) {
+		return fmt.Errorf("%w: %s", ErrInvalidProvider, provider)
+	}
+	if provider == ProviderOllama {
+		return nil
+	}
+	for _, m := range providerModels[provider] {
+		if m == model {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q for provider %s (known models: %s)",
+		ErrUnknownModel, model, provider, strings.Join(providerModels[provider], ", "))
+}
+
+// ProviderForModel returns the provider serving a model, for requests that
+// name a model but no provider. Only Ollama's listed models are inferred;
+// other local models need the provider named.
+func ProviderForModel(model string) (Provider, error) {
+	for _, provider := range []Provider{ProviderGLM, ProviderNVIDIA, ProviderOpenAI, ProviderAnthropic, ProviderMiniMax, ProviderOllama} {
+		for _, m := range providerModels[provider] {
+			if m == model {
+				return provider, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w %q; name its provider too", ErrUnknownModel, model)
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// newModelTestRouter returns a Router whose OpenAI endpoint replies with the
+// model each completion was requested for
+func newModelTestRouter(t *testing.T) *Router {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": req.Model}},
+			},
+		})
+	}))
+	t.Cleanup(ts.Close)
+
+	r := New(&Config{
+		OpenAIKey:       "server-key",
+		DefaultProvider: ProviderOllama,
+		RequestTimeout:  5 * time.Second,
+	}, zaptest.NewLogger(t))
+	r.baseURLs[ProviderOpenAI] = ts.URL
+	return r
+}
+
+func TestGenerateHonorsExplicitModel(t *testing.T) {
+	r := newModelTestRouter(t)
+
+	for _, req := range []*GenerateRequest{
+		{Query: "hi", Provider: ProviderOpenAI, Model: "gpt-4o"},
+		{Query: "hi", Model: "gpt-4o"}, // The provider follows from the model
+	} {
+		resp, err := r.Generate(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Generate(%q, %q): %v", req.Provider, req.Model, err)
+		}
+		if resp.Content != "gpt-4o" || resp.Provider != ProviderOpenAI || resp.Model != "gpt-4o" {
+			t.Errorf("Generate(%q, %q) = %s/%s answering %q, want openai/gpt-4o",
+				req.Provider, req.Model, resp.Provider, resp.Model, resp.Content)
+		}
+	}
+
+	// Without a model the provider's default is used and reported
+	resp, err := r.Generate(context.Background(), &GenerateRequest{Query: "hi", Provider: ProviderOpenAI})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if resp.Content != "gpt-4o-mini" || resp.Model != "gpt-4o-mini" {
+		t.Errorf("default model = %q (reported %q), want gpt-4o-mini", resp.Content, resp.Model)
+	}
+}
+
+func TestGenerateRejectsUnknownModel(t *testing.T) {
+	r := newModelTestRouter(t)
+
+	for _, tc := range []struct {
+		req  *GenerateRequest
+		want error
+	}{
+		{&GenerateRequest{Query: "hi", Provider: ProviderOpenAI, Model: "gpt-9"}, ErrUnknownModel},
+		{&GenerateRequest{Query: "hi", Provider: ProviderOpenAI, Model: "glm-4.5"}, ErrUnknownModel},
+		{&GenerateRequest{Query: "hi", Model: "gpt-9"}, ErrUnknownModel},
+		{&GenerateRequest{Query: "hi", Provider: "acme", Model: "gpt-4o"}, ErrInvalidProvider},
+		{&GenerateRequest{Query: "hi", Provider: "acme"}, ErrInvalidProvider},
+	} {
+		_, err := r.Generate(context.Background(), tc.req)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("Generate(%q, %q) error = %v, want %v", tc.req.Provider, tc.req.Model, err, tc.want)
+		}
+	}
+
+	_, err := r.Generate(context.Background(), &GenerateRequest{Query: "hi", Provider: ProviderOpenAI, Model: "gpt-9"})
+	if err == nil || !strings.Contains(err.Error(), "gpt-4o-mini") {
+		t.Errorf("error %v should list the provider's models", err)
+	}
+}
+
+func TestValidateModelAcceptsAnyOllamaModel(t *testing.T) {
+	if err := ValidateModel(ProviderOllama, "qwen2.5:7b"); err != nil {
+		t.Errorf("ValidateModel(ollama, qwen2.5:7b) = %v, want nil", err)
+	}
+}
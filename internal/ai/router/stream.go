@@ -0,0 +1,215 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/jsonx"
+)
+
+// maxStreamLine caps one line of a provider's event stream
+const maxStreamLine = 1 << 20
+
+// GenerateStream is Generate with the reply sent to out as the provider
+// produces it. GLM, NVIDIA NIM and OpenAI stream it token by token; other
+// providers send their whole reply as one piece. Thinking tags never reach
+// out. GenerateStream does not close out; the returned response holds the
+// complete reply, as Generate would return it.
+func (r *Router) GenerateStream(ctx context.Context, req *GenerateRequest, out chan<- string) (*GenerateResponse, error) {
+	start := time.Now()
+	call, err := r.resolveGenerate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &thinkFilter{}
+	send := func(piece string) error {
+		if piece == "" {
+			return nil
+		}
+		select {
+		case out <- piece:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var content string
+	switch call.provider {
+	case ProviderGLM, ProviderNVIDIA, ProviderOpenAI:
+		if call.apiKey == "" {
+			err = fmt.Errorf("no %s API key available", call.provider)
+			break
+		}
+		body := chatCompletionBody(call.provider, call.system, call.query, call.model)
+		content, err = r.streamChat(ctx, r.baseURL(call.provider)+"/chat/completions", body, call.apiKey, func(piece string) error {
+			return send(filter.push(piece))
+		})
+	default:
+		content, err = r.callProvider(ctx, call)
+		if err == nil {
+			err = send(filter.push(content))
+		}
+	}
+	if err == nil {
+		err = send(filter.flush())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("provider %s failed: %w", call.provider, err)
+	}
+
+	return &GenerateResponse{
+		Content:   stripThinkingTags(content),
+		Provider:  call.provider,
+		Model:     call.model,
+		Duration:  time.Since(start),
+		Truncated: call.truncated,
+	}, nil
+}
+
+// streamChat makes a streaming chat completions request and passes each
+// content delta to onDelta as it arrives, returning the whole content
+func (r *Router) streamChat(ctx context.Context, url string, body map[string]interface{}, apiKey string, onDelta func(string) error) (string, error) {
+	body["stream"] = true
+	jsonBody, err := jsonx.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			// Blank separators, comments and event names
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := jsonx.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		content.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return "", err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read stream: %w", err)
+	}
+	return content.String(), nil
+}
+
+// thinkFilter drops <think>...</think> spans from a reply arriving in
+// pieces, holding back a piece's tail while it could still open a tag. Like
+// stripThinkingTags, it trims the whitespace the reply starts with.
+type thinkFilter struct {
+	pending  string
+	thinking bool
+	started  bool
+}
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// push adds the next piece of the reply and returns the text now safe to send
+func (f *thinkFilter) push(piece string) string {
+	f.pending += piece
+	var out strings.Builder
+	for {
+		if f.thinking {
+			i := strings.Index(f.pending, thinkClose)
+			if i < 0 {
+				f.pending = f.pending[len(f.pending)-partialTag(f.pending, thinkClose):]
+				break
+			}
+			f.pending = f.pending[i+len(thinkClose):]
+			f.thinking = false
+			continue
+		}
+		if i := strings.Index(f.pending, thinkOpen); i >= 0 {
+			out.WriteString(f.pending[:i])
+			f.pending = f.pending[i+len(thinkOpen):]
+			f.thinking = true
+			continue
+		}
+		keep := partialTag(f.pending, thinkOpen)
+		out.WriteString(f.pending[:len(f.pending)-keep])
+		f.pending = f.pending[len(f.pending)-keep:]
+		break
+	}
+	return f.visible(out.String())
+}
+
+// flush returns what push held back once the reply is complete
+func (f *thinkFilter) flush() string {
+	rest := f.pending
+	f.pending = ""
+	if f.thinking {
+		return ""
+	}
+	return f.visible(rest)
+}
+
+// visible trims the whitespace before the reply's first text
+func (f *thinkFilter) visible(text string) string {
+	if !f.started {
+		text = strings.TrimLeft(text, " \t\r\n")
+		f.started = text != ""
+	}
+	return text
+}
+
+// partialTag returns the length of the longest suffix of s that begins tag
+// without completing it
+func partialTag(s, tag string) int {
+	for n := min(len(s), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGenerateStreamSendsDeltas(t *testing.T) {
+	deltas := []string{"<thi", "nk>plan the ", "answer</th", "ink>\n", "Paris is ", "the capital", "."}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("request did not ask for a stream")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, d := range deltas {
+			chunk, _ := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": d}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	r := New(&Config{GLMKey: "glm-key", DefaultProvider: ProviderGLM, RequestTimeout: 5 * time.Second}, zaptest.NewLogger(t))
+	r.baseURLs[ProviderGLM] = ts.URL
+
+	out := make(chan string, len(deltas))
+	resp, err := r.GenerateStream(context.Background(), &GenerateRequest{Query: "capital of France?"}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(out)
+
+	var pieces []string
+	for p := range out {
+		pieces = append(pieces, p)
+	}
+	if got := strings.Join(pieces, ""); got != "Paris is the capital." {
+		t.Errorf("streamed %q, want the reply without its thinking", pieces)
+	}
+	if len(pieces) < 3 {
+		t.Errorf("streamed %d pieces, want the deltas as they came", len(pieces))
+	}
+	if resp.Content != "Paris is the capital." || resp.Provider != ProviderGLM {
+		t.Errorf("response = %s %q", resp.Provider, resp.Content)
+	}
+}
+
+func TestThinkFilterMatchesStripThinkingTags(t *testing.T) {
+	for _, reply := range []string{
+		"plain answer",
+		"  <think>a</think> answer <think>b</think>done",
+		"a < b and <thin not a tag",
+		"<think>unfinished",
+	} {
+		f := &thinkFilter{}
+		var got strings.Builder
+		for _, r := range reply {
+			got.WriteString(f.push(string(r)))
+		}
+		got.WriteString(f.flush())
+
+		want := stripThinkingTags(reply)
+		if reply == "<think>unfinished" {
+			// Unclosed thinking is never sent
+			want = ""
+		}
+		if strings.TrimSpace(got.String()) != want {
+			t.Errorf("filtered %q to %q, want %q", reply, got.String(), want)
+		}
+	}
+}
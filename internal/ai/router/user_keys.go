@@ -0,0 +1,175 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// keyProbeTimeout bounds the validity probe so a slow provider doesn't stall generation
+	keyProbeTimeout = 5 * time.Second
+	// validKeyTTL / invalidKeyTTL control how long probe results are cached
+	validKeyTTL   = time.Hour
+	invalidKeyTTL = 10 * time.Minute
+	// maxKeyChecks caps the probe cache; a full cache first drops expired
+	// results, then the one closest to expiry
+	maxKeyChecks = 1024
+)
+
+// defaultBaseURLs are the API roots of the hosted providers
+var defaultBaseURLs = map[Provider]string{
+	ProviderGLM:       "https://open.bigmodel.cn/api/paas/v4",
+	ProviderNVIDIA:    "https://integrate.api.nvidia.com/v1",
+	ProviderOpenAI:    "https://api.openai.com/v1",
+	ProviderAnthropic: "https://api.anthropic.com/v1",
+	ProviderMiniMax:   "https://api.minimax.chat/v1",
+}
+
+// userKeyPreference is the order in which user-supplied keys pick a provider
+var userKeyPreference = []struct {
+	name     string
+	provider Provider
+}{
+	{"nim", ProviderNVIDIA},
+	{"openai", ProviderOpenAI},
+	{"anthropic", ProviderAnthropic},
+	{"glm", ProviderGLM},
+}
+
+// keyCheck is a cached key-validity probe result
+type keyCheck struct {
+	valid   bool
+	expires time.Time
+}
+
+// baseURL returns the API root for a hosted provider
+func (r *Router) baseURL(provider Provider) string {
+	if u, ok := r.baseURLs[provider]; ok {
+		return u
+	}
+	return defaultBaseURLs[provider]
+}
+
+// resolveAPIKey returns the user's key for provider when it passes the validity probe.
+// A rejected user key falls back to serverKey, or errors when StrictUserKeys is set.
+// Neither the request's key map nor the router's configuration is modified.
+func (r *Router) resolveAPIKey(ctx context.Context, provider Provider, userKeys map[string]string, keyName, serverKey string) (string, error) {
+	userKey := userKeys[keyName]
+	if userKey == "" {
+		return serverKey, nil
+	}
+	if r.userKeyValid(ctx, provider, userKey) {
+		return userKey, nil
+	}
+	if r.config.StrictUserKeys {
+		return "", fmt.Errorf("user API key for %s was rejected by the provider", provider)
+	}
+
+	r.logger.Warn("User API key rejected, falling back to server key",
+		zap.String("provider", string(provider)),
+		zap.Bool("server_key_available", serverKey != ""))
+	return serverKey, nil
+}
+
+// userKeyValid probes the provider with key, caching the verdict per key hash.
+// Transient probe failures count as valid so a flaky network never discards a good key.
+func (r *Router) userKeyValid(ctx context.Context, provider Provider, key string) bool {
+	sum := sha256.Sum256([]byte(key))
+	cacheKey := string(provider) + ":" + hex.EncodeToString(sum[:])
+
+	if valid, ok := r.cachedKeyCheck(cacheKey); ok {
+		return valid
+	}
+
+	valid, definitive := r.probeKey(ctx, provider, key)
+	if !definitive {
+		return true
+	}
+
+	ttl := validKeyTTL
+	if !valid {
+		ttl = invalidKeyTTL
+	}
+	r.cacheKeyCheck(cacheKey, keyCheck{valid: valid, expires: time.Now().Add(ttl)})
+	return valid
+}
+
+// cachedKeyCheck returns the cached verdict for cacheKey, deleting it once expired
+func (r *Router) cachedKeyCheck(cacheKey string) (valid, ok bool) {
+	r.keyMu.Lock()
+	defer r.keyMu.Unlock()
+	check, ok := r.keyChecks[cacheKey]
+	if !ok {
+		return false, false
+	}
+	if !time.Now().Before(check.expires) {
+		delete(r.keyChecks, cacheKey)
+		return false, false
+	}
+	return check.valid, true
+}
+
+// cacheKeyCheck stores a verdict, evicting entries to stay within maxKeyChecks
+func (r *Router) cacheKeyCheck(cacheKey string, check keyCheck) {
+	r.keyMu.Lock()
+	defer r.keyMu.Unlock()
+	if _, ok := r.keyChecks[cacheKey]; !ok && len(r.keyChecks) >= maxKeyChecks {
+		now := time.Now()
+		oldest := ""
+		for k, c := range r.keyChecks {
+			if !now.Before(c.expires) {
+				delete(r.keyChecks, k)
+			} else if oldest == "" || c.expires.Before(r.keyChecks[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(r.keyChecks) >= maxKeyChecks {
+			delete(r.keyChecks, oldest)
+		}
+	}
+	r.keyChecks[cacheKey] = check
+}
+
+// probeKey lists models with key: 2xx is valid, 401/403 invalid, anything else inconclusive
+func (r *Router) probeKey(ctx context.Context, provider Provider, key string) (valid, definitive bool) {
+	base := r.baseURL(provider)
+	if base == "" {
+		return true, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, keyProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"/models", nil)
+	if err != nil {
+		return true, false
+	}
+	if provider == ProviderAnthropic {
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	} else {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.logger.Debug("API key probe failed", zap.String("provider", string(provider)), zap.Error(err))
+		return true, false
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, true
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return false, true
+	default:
+		return true, false
+	}
+}
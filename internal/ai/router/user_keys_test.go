@@ -0,0 +1,141 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// newKeyTestRouter returns a Router whose OpenAI endpoint accepts only the key "good"
+// and echoes the key each completion was made with
+func newKeyTestRouter(t *testing.T, strict bool) (*Router, *atomic.Int32) {
+	var probes atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch r.URL.Path {
+		case "/models":
+			probes.Add(1)
+			if key != "good" && key != "server-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"data":[]}`))
+		case "/chat/completions":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"choices": []map[string]interface{}{
+					{"message": map[string]string{"role": "assistant", "content": key}},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	r := New(&Config{
+		OpenAIKey:       "server-key",
+		DefaultProvider: ProviderOpenAI,
+		RequestTimeout:  5 * time.Second,
+		StrictUserKeys:  strict,
+	}, zaptest.NewLogger(t))
+	r.baseURLs[ProviderOpenAI] = ts.URL
+	return r, &probes
+}
+
+func TestGenerateUsesValidUserKey(t *testing.T) {
+	r, _ := newKeyTestRouter(t, false)
+
+	resp, err := r.Generate(context.Background(), &GenerateRequest{
+		Query:       "hi",
+		UserAPIKeys: map[string]string{"openai": "good"},
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if resp.Content != "good" {
+		t.Errorf("completion used key %q, want user key", resp.Content)
+	}
+}
+
+func TestGenerateFallsBackOnInvalidUserKey(t *testing.T) {
+	r, probes := newKeyTestRouter(t, false)
+	userKeys := map[string]string{"openai": "bad"}
+
+	for i := 0; i < 2; i++ {
+		resp, err := r.Generate(context.Background(), &GenerateRequest{
+			Query:       "hi",
+			UserAPIKeys: userKeys,
+		})
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if resp.Content != "server-key" {
+			t.Errorf("completion used key %q, want server key", resp.Content)
+		}
+	}
+
+	if got := probes.Load(); got != 1 {
+		t.Errorf("key probed %d times, want 1 (cached)", got)
+	}
+	if userKeys["openai"] != "bad" || r.config.OpenAIKey != "server-key" {
+		t.Error("key resolution mutated request or router state")
+	}
+}
+
+func TestGenerateStrictRejectsInvalidUserKey(t *testing.T) {
+	r, _ := newKeyTestRouter(t, true)
+
+	_, err := r.Generate(context.Background(), &GenerateRequest{
+		Query:       "hi",
+		UserAPIKeys: map[string]string{"openai": "bad"},
+	})
+	if err == nil {
+		t.Fatal("expected error for rejected user key in strict mode")
+	}
+}
+
+func TestKeyChecksEvictExpiredAndStayBounded(t *testing.T) {
+	r, _ := newKeyTestRouter(t, false)
+	now := time.Now()
+
+	r.keyChecks["stale"] = keyCheck{valid: true, expires: now.Add(-time.Second)}
+	if _, ok := r.cachedKeyCheck("stale"); ok {
+		t.Error("expired verdict was served")
+	}
+	if _, ok := r.keyChecks["stale"]; ok {
+		t.Error("expired verdict was not deleted on lookup")
+	}
+
+	// Fill the cache with one expired entry and live ones expiring in order
+	r.keyChecks["expired"] = keyCheck{expires: now.Add(-time.Second)}
+	for i := 1; len(r.keyChecks) < maxKeyChecks; i++ {
+		r.keyChecks[fmt.Sprintf("live%d", i)] = keyCheck{valid: true, expires: now.Add(time.Duration(i) * time.Minute)}
+	}
+
+	r.cacheKeyCheck("new1", keyCheck{valid: true, expires: now.Add(time.Hour)})
+	if _, ok := r.keyChecks["expired"]; ok {
+		t.Error("a full cache kept its expired entry")
+	}
+	if _, ok := r.keyChecks["live1"]; !ok {
+		t.Error("a live entry was evicted while an expired one could go")
+	}
+
+	r.cacheKeyCheck("new2", keyCheck{valid: true, expires: now.Add(time.Hour)})
+	if _, ok := r.keyChecks["live1"]; ok {
+		t.Error("the entry closest to expiry was not evicted")
+	}
+	if len(r.keyChecks) != maxKeyChecks {
+		t.Errorf("cache holds %d entries, want %d", len(r.keyChecks), maxKeyChecks)
+	}
+	if valid, ok := r.cachedKeyCheck("new2"); !ok || !valid {
+		t.Error("newest verdict missing from the cache")
+	}
+}
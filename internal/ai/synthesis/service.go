@@ -4,8 +4,11 @@ package synthesis
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/reflective-memory-kernel/internal/ai/router"
 	"go.uber.org/zap"
@@ -17,6 +20,10 @@ type Service struct {
 	logger  *zap.Logger
 	provider router.Provider
 	model    string
+
+	// Facts a brief lists one by one; the rest overflow (see SetFactLimit)
+	maxFacts          int
+	summarizeOverflow bool
 }
 
 // Fact represents a memory fact
@@ -39,6 +46,34 @@ type Insight struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// Style controls the format and verbosity of a synthesized brief
+type Style string
+
+const (
+	StyleDefault   Style = ""          // Direct answer quoting the facts
+	StyleConcise   Style = "concise"   // One or two sentences
+	StyleDetailed  Style = "detailed"  // Full answer with supporting context
+	StyleBullet    Style = "bullet"    // Bulleted list of key points
+	StyleNarrative Style = "narrative" // Flowing prose
+)
+
+// styleInstructions are the prompt directives for each non-default style
+var styleInstructions = map[Style]string{
+	StyleConcise:   "Answer in one or two short sentences. No preamble.",
+	StyleDetailed:  "Give a thorough answer that covers every relevant fact and explains how they relate.",
+	StyleBullet:    "Format the brief as a bulleted list, one fact or point per line, each line starting with \"- \".",
+	StyleNarrative: "Write the brief as flowing, connected prose in a natural conversational tone.",
+}
+
+// Valid reports whether s is a known style
+func (s Style) Valid() bool {
+	if s == StyleDefault {
+		return true
+	}
+	_, ok := styleInstructions[s]
+	return ok
+}
+
 // SynthesisRequest represents a synthesis request
 type SynthesisRequest struct {
 	Query    string    `json:"query"`
@@ -46,6 +81,8 @@ type SynthesisRequest struct {
 	Facts    []Fact    `json:"facts,omitempty"`
 	Insights []Insight `json:"insights,omitempty"`
 	Alerts   []string  `json:"alerts,omitempty"`
+	Style    Style     `json:"style,omitempty"`
+	MaxWords int       `json:"max_words,omitempty"` // 0 means no cap
 }
 
 // SynthesisResponse represents a synthesis response
@@ -56,6 +93,10 @@ type SynthesisResponse struct {
 	Provider   string   `json:"provider"`
 	Model      string   `json:"model"`
 	Duration   time.Duration `json:"duration"`
+
+	// SynthesisAvailable is false when no LLM provider is configured and the
+	// brief was built from the facts by template
+	SynthesisAvailable bool `json:"synthesis_available"`
 }
 
 // ConnectionEvaluation represents an evaluation of node connections
@@ -78,15 +119,38 @@ func New(r *router.Router, logger *zap.Logger) *Service {
 		logger:  logger,
 		provider: router.ProviderNVIDIA,
 		model:    "moonshotai/kimi-k2-instruct-0905",
+		maxFacts: DefaultMaxFacts,
+	}
+}
+
+// SetFactLimit caps the facts a brief lists one by one (non-positive keeps
+// DefaultMaxFacts). With summarizeOverflow, the facts past the cap are
+// summarized into one line by the LLM instead of only being counted.
+func (s *Service) SetFactLimit(limit int, summarizeOverflow bool) {
+	if limit <= 0 {
+		limit = DefaultMaxFacts
 	}
+	s.maxFacts = limit
+	s.summarizeOverflow = summarizeOverflow
 }
 
-// Synthesize creates a coherent brief from facts and insights
+// Synthesize creates a coherent brief from facts and insights. Without an LLM
+// provider to write it, the brief is a deterministic listing of the facts.
 func (s *Service) Synthesize(ctx context.Context, req *SynthesisRequest) (*SynthesisResponse, error) {
 	start := time.Now()
 
+	if !s.Available() {
+		brief, confidence := s.templateBrief(ctx, req)
+		return &SynthesisResponse{
+			Brief:      brief,
+			Confidence: confidence,
+			Provider:   "template",
+			Duration:   time.Since(start),
+		}, nil
+	}
+
 	// Format facts
-	factsText := s.formatFacts(req.Facts, 10)
+	factsText := s.briefFacts(ctx, req.Query, req.Facts)
 	insightsText := s.formatInsights(req.Insights, 5)
 	alertsText := s.formatAlerts(req.Alerts, 3)
 
@@ -114,23 +178,25 @@ EXAMPLE:
 - Your answer MUST be: "Your manager is Bob."
 
 Now answer the query using the facts above.
-
+%s
 Return JSON: {"brief": "your answer using the facts", "confidence": 0.0-1.0}`,
 		req.Query,
 		factsText,
 		insightsText,
 		alertsText,
+		formatDirectives(req.Style, req.MaxWords),
 	)
 
 	result, err := s.router.ExtractJSON(ctx, prompt, s.provider, s.model)
 	if err != nil {
 		s.logger.Warn("synthesis LLM call failed", zap.Error(err))
 		return &SynthesisResponse{
-			Brief:      "I can help with that, but I don't have specific information.",
-			Confidence: 0.3,
-			Provider:   string(s.provider),
-			Model:      s.model,
-			Duration:   time.Since(start),
+			Brief:              "I can help with that, but I don't have specific information.",
+			Confidence:         0.3,
+			Provider:           string(s.provider),
+			Model:              s.model,
+			Duration:           time.Since(start),
+			SynthesisAvailable: true,
 		}, nil
 	}
 
@@ -138,21 +204,143 @@ Return JSON: {"brief": "your answer using the facts", "confidence": 0.0-1.0}`,
 	confidence := 0.3
 
 	if b, ok := result["brief"].(string); ok {
-		brief = b
+		brief = truncateWords(b, req.MaxWords)
 	}
 	if c, ok := result["confidence"].(float64); ok {
 		confidence = c
 	}
+	// A styled or shortened brief can drop the facts it rests on, so only then
+	// is the model's confidence held to what the sources support
+	if req.Style != StyleDefault || req.MaxWords > 0 {
+		confidence = math.Min(confidence, supportCeiling(req))
+	}
 
 	return &SynthesisResponse{
-		Brief:      brief,
-		Confidence: confidence,
-		Provider:   string(s.provider),
-		Model:      s.model,
-		Duration:   time.Since(start),
+		Brief:              brief,
+		Confidence:         confidence,
+		Provider:           string(s.provider),
+		Model:              s.model,
+		Duration:           time.Since(start),
+		SynthesisAvailable: true,
 	}, nil
 }
 
+// Available reports whether the service's LLM provider is configured
+func (s *Service) Available() bool {
+	return s.router != nil && s.router.IsProviderAvailable(s.provider)
+}
+
+// DefaultMaxFacts is how many facts a brief lists before the rest overflow
+const DefaultMaxFacts = 10
+
+// briefFacts formats the facts a brief is written from: the first maxFacts one
+// per line, then the overflow as a single summarized line when
+// summarizeOverflow is on and an LLM is available, or as a count otherwise
+func (s *Service) briefFacts(ctx context.Context, query string, facts []Fact) string {
+	text := s.formatFacts(facts, s.maxFacts)
+	if len(facts) <= s.maxFacts {
+		return text
+	}
+
+	overflow := facts[s.maxFacts:]
+	if s.summarizeOverflow && s.Available() {
+		summary, err := s.summarizeFacts(ctx, query, overflow)
+		if err == nil && summary != "" {
+			return text + "- Also: " + summary + "\n"
+		}
+		s.logger.Warn("Overflow fact summary failed, counting them instead",
+			zap.Int("overflow", len(overflow)), zap.Error(err))
+	}
+	return text + fmt.Sprintf("... and %d more related facts.\n", len(overflow))
+}
+
+// summarizeFacts condenses facts into one sentence, keeping what bears on query
+func (s *Service) summarizeFacts(ctx context.Context, query string, facts []Fact) (string, error) {
+	prompt := fmt.Sprintf(`Summarize the facts below in ONE sentence. Keep every detail that bears on the query; drop the rest.
+
+Query: %s
+
+%s
+Return JSON: {"summary": "one sentence"}`, query, s.formatFacts(facts, 0))
+
+	result, err := s.router.ExtractJSON(ctx, prompt, s.provider, s.model)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(getString(result, "summary")), " "), nil
+}
+
+// templateBrief lists the facts, insights and first alert, the way the
+// kernel's fallback brief does, for when there is no LLM to synthesize them
+func (s *Service) templateBrief(ctx context.Context, req *SynthesisRequest) (string, float64) {
+	if len(req.Facts) == 0 && len(req.Insights) == 0 {
+		return "I don't have that stored yet.", 0.3
+	}
+
+	var builder strings.Builder
+	if len(req.Facts) > 0 {
+		builder.WriteString("Based on what I know:\n")
+		builder.WriteString(s.briefFacts(ctx, req.Query, req.Facts))
+	}
+	if len(req.Insights) > 0 {
+		builder.WriteString("Connections I've noticed:\n")
+		builder.WriteString(s.formatInsights(req.Insights, 5))
+	}
+	if len(req.Alerts) > 0 {
+		builder.WriteString("\nNote: ")
+		builder.WriteString(req.Alerts[0])
+	}
+
+	brief := truncateWords(strings.TrimRight(builder.String(), "\n"), req.MaxWords)
+	return brief, math.Min(0.5, supportCeiling(req))
+}
+
+// formatDirectives renders the style and length instructions; empty for the default brief
+func formatDirectives(style Style, maxWords int) string {
+	var directives []string
+	if instruction, ok := styleInstructions[style]; ok {
+		directives = append(directives, instruction)
+	}
+	if maxWords > 0 {
+		directives = append(directives, fmt.Sprintf("Keep the brief under %d words.", maxWords))
+	}
+	if len(directives) == 0 {
+		return ""
+	}
+	return "\n=== RESPONSE FORMAT ===\n" + strings.Join(directives, "\n") + "\n"
+}
+
+// supportCeiling caps confidence by how much source material backed the brief:
+// no facts caps it at 0.3 (the "nothing stored" level), three or more facts
+// (insights count half) leave the model's own confidence untouched
+func supportCeiling(req *SynthesisRequest) float64 {
+	support := (float64(len(req.Facts)) + float64(len(req.Insights))/2) / 3
+	return 0.3 + 0.7*math.Min(support, 1)
+}
+
+// truncateWords cuts text after maxWords words, keeping line breaks; maxWords <= 0 disables it
+func truncateWords(text string, maxWords int) string {
+	if maxWords <= 0 {
+		return text
+	}
+	words := 0
+	inWord := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		if !inWord {
+			inWord = true
+			words++
+			if words > maxWords {
+				return strings.TrimRightFunc(text[:i], unicode.IsSpace) + "…"
+			}
+		}
+	}
+	return text
+}
+
 // EvaluateConnection evaluates if two nodes have an emergent insight
 func (s *Service) EvaluateConnection(ctx context.Context, node1, node2 map[string]interface{}, pathExists bool, pathLength int) (*ConnectionEvaluation, error) {
 	name1 := getString(node1, "name")
@@ -263,6 +451,7 @@ func (s *Service) formatFact(f Fact) string {
 			}
 		}
 		if len(attrs) > 0 {
+			sort.Strings(attrs)
 			builder.WriteString(" [")
 			builder.WriteString(strings.Join(attrs, ", "))
 			builder.WriteString("]")
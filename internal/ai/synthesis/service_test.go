@@ -0,0 +1,179 @@
+package synthesis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/reflective-memory-kernel/internal/ai/router"
+)
+
+// TestSynthesizeWithoutProviderListsFacts checks that a service with no LLM
+// key answers from the facts by template instead of apologising
+func TestSynthesizeWithoutProviderListsFacts(t *testing.T) {
+	svc := New(router.New(&router.Config{}, nil), nil)
+	if svc.Available() {
+		t.Fatal("service with no provider keys reports synthesis available")
+	}
+
+	req := &SynthesisRequest{
+		Query: "who is my manager",
+		Facts: []Fact{
+			{Name: "Bob", Description: "user's manager"},
+			{Name: "Acme", Type: "Organization", Attributes: map[string]interface{}{"city": "Oslo", "size": 40}},
+		},
+		Alerts: []string{"1:1 with Bob tomorrow"},
+	}
+	first, err := svc.Synthesize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	if first.SynthesisAvailable {
+		t.Error("template brief reported as synthesized")
+	}
+	for _, want := range []string{"- Bob: user's manager", "- Acme (Organization) [city=Oslo, size=40]", "Note: 1:1 with Bob tomorrow"} {
+		if !strings.Contains(first.Brief, want) {
+			t.Errorf("brief %q lacks %q", first.Brief, want)
+		}
+	}
+	if first.Confidence != 0.5 {
+		t.Errorf("confidence = %v, want 0.5", first.Confidence)
+	}
+
+	for i := 0; i < 5; i++ {
+		again, _ := svc.Synthesize(context.Background(), req)
+		if again.Brief != first.Brief {
+			t.Fatalf("brief changed between calls:\n%s\n---\n%s", first.Brief, again.Brief)
+		}
+	}
+
+	empty, _ := svc.Synthesize(context.Background(), &SynthesisRequest{Query: "who is my manager"})
+	if empty.Brief != "I don't have that stored yet." || empty.Confidence != 0.3 {
+		t.Errorf("no facts = %q at %v, want the nothing-stored answer", empty.Brief, empty.Confidence)
+	}
+}
+
+// newFakeLLMService returns a service on a fake Ollama that answers summary
+// prompts with summary and anything else with a brief, recording each prompt
+func newFakeLLMService(t *testing.T, summary string, prompts *[]string) *Service {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt := req.Messages[len(req.Messages)-1].Content
+		*prompts = append(*prompts, prompt)
+
+		reply := `{"brief": "Here is what I know.", "confidence": 0.9}`
+		if strings.HasPrefix(prompt, "Summarize the facts below") {
+			reply = fmt.Sprintf(`{"summary": %q}`, summary)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": map[string]string{"role": "assistant", "content": reply},
+		})
+	}))
+	t.Cleanup(ts.Close)
+
+	svc := New(router.New(&router.Config{OllamaURL: ts.URL}, nil), nil)
+	svc.SetProvider(router.ProviderOllama, "llama3")
+	return svc
+}
+
+func TestSynthesizeSummarizesOverflowFacts(t *testing.T) {
+	facts := make([]Fact, 25)
+	for i := range facts {
+		facts[i] = Fact{Name: fmt.Sprintf("Fact%02d", i), Description: fmt.Sprintf("detail %d", i)}
+	}
+	req := &SynthesisRequest{Query: "what do you know about me", Facts: facts}
+
+	var prompts []string
+	svc := newFakeLLMService(t, "Facts 10 to 24\nall concern the user's garden.", &prompts)
+	svc.SetFactLimit(10, true)
+	if _, err := svc.Synthesize(context.Background(), req); err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+
+	if len(prompts) != 2 {
+		t.Fatalf("sent %d prompts, want a summary then the brief", len(prompts))
+	}
+	for i := 10; i < 25; i++ {
+		if !strings.Contains(prompts[0], fmt.Sprintf("- Fact%02d: detail %d", i, i)) {
+			t.Errorf("summary prompt lacks overflow fact %d", i)
+		}
+	}
+	if strings.Contains(prompts[0], "Fact09") {
+		t.Error("summary prompt includes a listed fact")
+	}
+	brief := prompts[1]
+	if !strings.Contains(brief, "- Fact09: detail 9") || strings.Contains(brief, "- Fact10") {
+		t.Error("brief prompt doesn't list exactly the first 10 facts")
+	}
+	if !strings.Contains(brief, "- Also: Facts 10 to 24 all concern the user's garden.") {
+		t.Errorf("brief prompt lacks the one-line overflow summary:\n%s", brief)
+	}
+	if strings.Contains(brief, "more related facts") {
+		t.Error("overflow was counted as well as summarized")
+	}
+
+	// Summarizing off, the overflow is counted without an extra call
+	prompts = nil
+	svc.SetFactLimit(10, false)
+	svc.Synthesize(context.Background(), req)
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "... and 15 more related facts.") {
+		t.Errorf("prompts = %d, want one brief prompt counting 15 more facts", len(prompts))
+	}
+}
+
+func TestTemplateBriefTruncatesOverflowWithoutLLM(t *testing.T) {
+	svc := New(router.New(&router.Config{}, nil), nil)
+	svc.SetFactLimit(5, true)
+	facts := make([]Fact, 25)
+	for i := range facts {
+		facts[i] = Fact{Name: fmt.Sprintf("Fact%02d", i)}
+	}
+
+	resp, err := svc.Synthesize(context.Background(), &SynthesisRequest{Query: "q", Facts: facts})
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	if !strings.Contains(resp.Brief, "- Fact04") || strings.Contains(resp.Brief, "- Fact05") ||
+		!strings.Contains(resp.Brief, "... and 20 more related facts.") {
+		t.Errorf("brief = %q, want 5 facts listed and 20 counted", resp.Brief)
+	}
+}
+
+func TestSupportCeilingOnlyWithFormatOptions(t *testing.T) {
+	var prompts []string
+	svc := newFakeLLMService(t, "", &prompts)
+	facts := []Fact{{Name: "Bob", Description: "user's manager"}}
+
+	tests := []struct {
+		name string
+		req  SynthesisRequest
+		want float64
+	}{
+		{"default brief", SynthesisRequest{}, 0.9},
+		{"style", SynthesisRequest{Style: StyleConcise}, supportCeiling(&SynthesisRequest{Facts: facts})},
+		{"max words", SynthesisRequest{MaxWords: 50}, supportCeiling(&SynthesisRequest{Facts: facts})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Query, req.Facts = "who is my manager", facts
+			resp, err := svc.Synthesize(context.Background(), &req)
+			if err != nil {
+				t.Fatalf("Synthesize: %v", err)
+			}
+			if resp.Confidence != tt.want {
+				t.Errorf("confidence = %v, want %v", resp.Confidence, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Package eval measures retrieval quality. It runs a labeled set of
+// consultation queries against a kernel whose namespaces were seeded ahead of
+// time and scores the returned facts against the UIDs each query should find,
+// so changes to ranking and weighting have an objective target.
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// DefaultK is the cutoff used when Run is given k <= 0
+const DefaultK = 10
+
+// Case is one labeled query: consulting Namespace as UserID with Query should
+// retrieve ExpectedUIDs
+type Case struct {
+	Name         string   `json:"name,omitempty"`
+	UserID       string   `json:"user_id"`
+	Namespace    string   `json:"namespace,omitempty"` // Defaults to the user's namespace
+	Query        string   `json:"query"`
+	ExpectedUIDs []string `json:"expected_uids"`
+}
+
+// label names the case in reports
+func (c Case) label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Query
+}
+
+func (c Case) validate() error {
+	switch {
+	case c.UserID == "" && c.Namespace == "":
+		return errors.New("user_id or namespace is required")
+	case strings.TrimSpace(c.Query) == "":
+		return errors.New("query is required")
+	case len(c.ExpectedUIDs) == 0:
+		return errors.New("expected_uids is required")
+	}
+	return nil
+}
+
+// LoadCases reads cases from a file holding either a JSON array or one JSON
+// object per line
+func LoadCases(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCases(data)
+}
+
+// ParseCases decodes cases in the formats LoadCases accepts and validates them
+func ParseCases(data []byte) ([]Case, error) {
+	var cases []Case
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &cases); err != nil {
+			return nil, fmt.Errorf("decode cases: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		for {
+			var c Case
+			if err := dec.Decode(&c); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("decode case %d: %w", len(cases)+1, err)
+			}
+			cases = append(cases, c)
+		}
+	}
+
+	if len(cases) == 0 {
+		return nil, errors.New("no cases")
+	}
+	for i, c := range cases {
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("case %d (%s): %w", i+1, c.label(), err)
+		}
+	}
+	return cases, nil
+}
+
+// Consulter runs a consultation; *agent.MKClient and the kernel itself
+// satisfy it
+type Consulter interface {
+	Consult(ctx context.Context, req *graph.ConsultationRequest) (*graph.ConsultationResponse, error)
+}
+
+// CaseResult is the score of one case. Retrieved holds the returned fact
+// UIDs in rank order.
+type CaseResult struct {
+	Case           Case     `json:"case"`
+	Retrieved      []string `json:"retrieved"`
+	Missed         []string `json:"missed,omitempty"` // Expected UIDs absent from the top k
+	Precision      float64  `json:"precision"`
+	Recall         float64  `json:"recall"`
+	ReciprocalRank float64  `json:"reciprocal_rank"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// Report aggregates a run. Means are taken over the cases that completed;
+// failed cases are counted separately so an unreachable kernel never reads
+// as a recall regression.
+type Report struct {
+	K             int          `json:"k"`
+	Cases         []CaseResult `json:"cases"`
+	Failed        int          `json:"failed"`
+	MeanPrecision float64      `json:"mean_precision"`
+	MeanRecall    float64      `json:"mean_recall"`
+	MRR           float64      `json:"mrr"`
+}
+
+// Run consults every case in order and scores the results at cutoff k
+func Run(ctx context.Context, consulter Consulter, cases []Case, k int) *Report {
+	if k <= 0 {
+		k = DefaultK
+	}
+
+	report := &Report{K: k, Cases: make([]CaseResult, 0, len(cases))}
+	completed := 0
+	for _, c := range cases {
+		result := runCase(ctx, consulter, c, k)
+		report.Cases = append(report.Cases, result)
+		if result.Error != "" {
+			report.Failed++
+			continue
+		}
+		completed++
+		report.MeanPrecision += result.Precision
+		report.MeanRecall += result.Recall
+		report.MRR += result.ReciprocalRank
+	}
+
+	if completed > 0 {
+		report.MeanPrecision /= float64(completed)
+		report.MeanRecall /= float64(completed)
+		report.MRR /= float64(completed)
+	}
+	return report
+}
+
+func runCase(ctx context.Context, consulter Consulter, c Case, k int) CaseResult {
+	result := CaseResult{Case: c}
+	resp, err := consulter.Consult(ctx, &graph.ConsultationRequest{
+		UserID:     c.UserID,
+		Namespace:  c.Namespace,
+		Query:      c.Query,
+		MaxResults: k,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	seen := make(map[string]bool)
+	for _, fact := range resp.RelevantFacts {
+		if fact.UID != "" && !seen[fact.UID] {
+			seen[fact.UID] = true
+			result.Retrieved = append(result.Retrieved, fact.UID)
+		}
+	}
+	result.Precision, result.Recall, result.ReciprocalRank, result.Missed = Score(result.Retrieved, c.ExpectedUIDs, k)
+	return result
+}
+
+// Score computes precision@k and recall@k of retrieved (in rank order)
+// against expected, the reciprocal rank of the first expected UID anywhere in
+// retrieved, and the expected UIDs missing from the top k. Precision divides
+// by k, so returning fewer than k facts is not rewarded.
+func Score(retrieved, expected []string, k int) (precision, recall, reciprocalRank float64, missed []string) {
+	want := make(map[string]bool, len(expected))
+	for _, uid := range expected {
+		want[uid] = true
+	}
+	if len(want) == 0 || k <= 0 {
+		return 0, 0, 0, nil
+	}
+
+	found := make(map[string]bool)
+	for i, uid := range retrieved {
+		if !want[uid] {
+			continue
+		}
+		if reciprocalRank == 0 {
+			reciprocalRank = 1 / float64(i+1)
+		}
+		if i < k {
+			found[uid] = true
+		}
+	}
+
+	for _, uid := range expected {
+		if !found[uid] {
+			missed = append(missed, uid)
+		}
+	}
+	precision = float64(len(found)) / float64(k)
+	recall = float64(len(found)) / float64(len(want))
+	return precision, recall, reciprocalRank, missed
+}
+
+// WriteText prints a per-case table followed by the means
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "CASE\tP@%d\tR@%d\tRR\tMISSED\n", r.K, r.K)
+	for _, c := range r.Cases {
+		if c.Error != "" {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\terror: %s\n", c.Case.label(), c.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%.3f\t%s\n",
+			c.Case.label(), c.Precision, c.Recall, c.ReciprocalRank, strings.Join(c.Missed, ","))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d cases (%d failed)  precision@%d=%.3f  recall@%d=%.3f  MRR=%.3f\n",
+		len(r.Cases), r.Failed, r.K, r.MeanPrecision, r.K, r.MeanRecall, r.MRR)
+	return err
+}
@@ -0,0 +1,78 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// fakeConsulter answers each query with fixed fact UIDs
+type fakeConsulter map[string][]string
+
+func (f fakeConsulter) Consult(ctx context.Context, req *graph.ConsultationRequest) (*graph.ConsultationResponse, error) {
+	uids, ok := f[req.Query]
+	if !ok {
+		return nil, errors.New("kernel unavailable")
+	}
+	resp := &graph.ConsultationResponse{}
+	for _, uid := range uids {
+		resp.RelevantFacts = append(resp.RelevantFacts, graph.Node{UID: uid})
+	}
+	return resp, nil
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestRunScoresCases(t *testing.T) {
+	consulter := fakeConsulter{
+		"who is my manager": {"0x1", "0x2", "0x3"},
+		"what do i eat":     {"0x9", "0x9", "0x4", "0x5"},
+	}
+	cases := []Case{
+		{UserID: "alice", Query: "who is my manager", ExpectedUIDs: []string{"0x1"}},
+		// 0x5 is found, but only past the cutoff; the duplicate 0x9 counts once
+		{UserID: "alice", Query: "what do i eat", ExpectedUIDs: []string{"0x4", "0x5", "0x6"}},
+		{UserID: "alice", Query: "unreachable", ExpectedUIDs: []string{"0x7"}},
+	}
+
+	report := Run(context.Background(), consulter, cases, 2)
+
+	first, second := report.Cases[0], report.Cases[1]
+	if !near(first.Precision, 0.5) || !near(first.Recall, 1) || !near(first.ReciprocalRank, 1) {
+		t.Errorf("first case = %+v, want P=0.5 R=1 RR=1", first)
+	}
+	if !near(second.Precision, 0.5) || !near(second.Recall, 1.0/3) || !near(second.ReciprocalRank, 0.5) {
+		t.Errorf("second case = %+v, want P=0.5 R=1/3 RR=0.5", second)
+	}
+	if len(second.Missed) != 2 || second.Missed[0] != "0x5" || second.Missed[1] != "0x6" {
+		t.Errorf("second case missed = %v, want [0x5 0x6]", second.Missed)
+	}
+
+	if report.Failed != 1 || report.Cases[2].Error == "" {
+		t.Errorf("unreachable case should be counted as failed, got %+v", report.Cases[2])
+	}
+	if !near(report.MeanPrecision, 0.5) || !near(report.MeanRecall, 2.0/3) || !near(report.MRR, 0.75) {
+		t.Errorf("means = P %v R %v MRR %v, want 0.5, 2/3, 0.75 over completed cases",
+			report.MeanPrecision, report.MeanRecall, report.MRR)
+	}
+}
+
+func TestParseCasesFormats(t *testing.T) {
+	array := `[{"user_id": "alice", "query": "q1", "expected_uids": ["0x1"]}]`
+	lines := `{"user_id": "alice", "query": "q1", "expected_uids": ["0x1"]}
+{"namespace": "group_team", "query": "q2", "expected_uids": ["0x2"]}
+`
+	for name, data := range map[string]string{"array": array, "jsonl": lines} {
+		cases, err := ParseCases([]byte(data))
+		if err != nil || len(cases) == 0 || cases[0].ExpectedUIDs[0] != "0x1" {
+			t.Errorf("%s: cases = %+v, err = %v", name, cases, err)
+		}
+	}
+
+	if _, err := ParseCases([]byte(`{"user_id": "alice", "query": "q1"}`)); err == nil {
+		t.Error("case without expected_uids should be rejected")
+	}
+}
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240"
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// maxAccessBoostAttempts bounds how often a batch is retried after losing a
+// race with a concurrent write to one of its nodes
+const maxAccessBoostAttempts = 3
+
+// accessTxn is the part of a DGraph transaction a boost batch uses
+type accessTxn interface {
+	QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error)
+	Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error)
+	Discard(ctx context.Context) error
+}
+
+// IncrementAccessCounts does what IncrementAccessCount does for many nodes in
+// one read and one write: it reads every node's activation and access count in
+// a single query, then boosts them all in a single mutation committed in the
+// same transaction. Should another write touch one of the nodes in between,
+// the commit aborts and the whole batch is retried.
+func (c *Client) IncrementAccessCounts(ctx context.Context, uids []string, config ActivationConfig) error {
+	return incrementAccessCounts(ctx, func() accessTxn { return c.dgraph().NewTxn() }, uids, config)
+}
+
+func incrementAccessCounts(ctx context.Context, newTxn func() accessTxn, uids []string, config ActivationConfig) error {
+	seen := make(map[string]bool, len(uids))
+	var batch []string
+	for _, uid := range uids {
+		if seen[uid] {
+			continue
+		}
+		if !uidPattern.MatchString(uid) {
+			return fmt.Errorf("invalid uid %q", uid)
+		}
+		seen[uid] = true
+		batch = append(batch, uid)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`{
+		nodes(func: uid(%s)) @filter(has(dgraph.type)) {
+			uid
+			activation
+			access_count
+		}
+	}`, strings.Join(batch, ", "))
+
+	for attempt := 0; attempt < maxAccessBoostAttempts; attempt++ {
+		err := boostAccessBatch(ctx, newTxn(), query, config)
+		if !errors.Is(err, dgo.ErrAborted) {
+			return err
+		}
+		// Backoff before retry
+		time.Sleep(time.Millisecond * time.Duration(10*(attempt+1)))
+	}
+	return fmt.Errorf("failed to increment access counts after %d attempts (too many conflicts)", maxAccessBoostAttempts)
+}
+
+// boostAccessBatch reads and boosts one batch inside txn
+func boostAccessBatch(ctx context.Context, txn accessTxn, query string, config ActivationConfig) error {
+	defer txn.Discard(ctx)
+
+	resp, err := txn.QueryWithVars(ctx, query, nil)
+	if err != nil {
+		return fmt.Errorf("failed to read access counts: %w", err)
+	}
+	var result struct {
+		Nodes []struct {
+			UID         string  `json:"uid"`
+			Activation  float64 `json:"activation"`
+			AccessCount int64   `json:"access_count"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal: %w", err)
+	}
+	if len(result.Nodes) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	updates := make([]map[string]interface{}, len(result.Nodes))
+	for i, node := range result.Nodes {
+		activation := node.Activation + config.BoostPerAccess
+		if activation > config.MaxActivation {
+			activation = config.MaxActivation
+		}
+		updates[i] = map[string]interface{}{
+			"uid":           node.UID,
+			"activation":    activation,
+			"access_count":  node.AccessCount + 1,
+			"last_accessed": now,
+		}
+	}
+	updateJSON, err := json.Marshal(updates)
+	if err != nil {
+		return fmt.Errorf("failed to marshal update: %w", err)
+	}
+
+	if _, err := txn.Mutate(ctx, &api.Mutation{SetJson: updateJSON, CommitNow: true}); err != nil {
+		if errors.Is(err, dgo.ErrAborted) {
+			return err
+		}
+		return fmt.Errorf("failed to increment access counts: %w", err)
+	}
+	return nil
+}
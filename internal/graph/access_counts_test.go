@@ -0,0 +1,171 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240"
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// fakeAccessStore holds activation and access counts per node and counts the
+// round trips transactions against it make, each costing rtt
+type fakeAccessStore struct {
+	mu         sync.Mutex
+	activation map[string]float64
+	count      map[string]int64
+	roundTrips int
+	aborts     int // Commits to abort before letting one through
+	rtt        time.Duration
+}
+
+func newFakeAccessStore(n int, rtt time.Duration) *fakeAccessStore {
+	s := &fakeAccessStore{activation: map[string]float64{}, count: map[string]int64{}, rtt: rtt}
+	for i := 1; i <= n; i++ {
+		s.activation[fmt.Sprintf("0x%x", i)] = 0.5
+	}
+	return s
+}
+
+func (s *fakeAccessStore) uids() []string {
+	uids := make([]string, 0, len(s.activation))
+	for uid := range s.activation {
+		uids = append(uids, uid)
+	}
+	return uids
+}
+
+func (s *fakeAccessStore) newTxn() accessTxn { return &fakeAccessTxn{s} }
+
+type fakeAccessTxn struct{ s *fakeAccessStore }
+
+var fakeUIDList = regexp.MustCompile(`uid\(([^)]*)\)`)
+
+func (t *fakeAccessTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	time.Sleep(t.s.rtt)
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	t.s.roundTrips++
+
+	type row struct {
+		UID         string  `json:"uid"`
+		Activation  float64 `json:"activation"`
+		AccessCount int64   `json:"access_count"`
+	}
+	var nodes []row
+	for _, uid := range strings.Split(fakeUIDList.FindStringSubmatch(q)[1], ", ") {
+		if activation, ok := t.s.activation[uid]; ok {
+			nodes = append(nodes, row{uid, activation, t.s.count[uid]})
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"nodes": nodes})
+	return &api.Response{Json: data}, nil
+}
+
+func (t *fakeAccessTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	time.Sleep(t.s.rtt)
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	t.s.roundTrips++
+	if t.s.aborts > 0 {
+		t.s.aborts--
+		return nil, dgo.ErrAborted
+	}
+
+	var updates []struct {
+		UID         string  `json:"uid"`
+		Activation  float64 `json:"activation"`
+		AccessCount int64   `json:"access_count"`
+	}
+	if err := json.Unmarshal(mu.SetJson, &updates); err != nil {
+		return nil, err
+	}
+	for _, u := range updates {
+		t.s.activation[u.UID], t.s.count[u.UID] = u.Activation, u.AccessCount
+	}
+	return &api.Response{}, nil
+}
+
+func (t *fakeAccessTxn) Discard(ctx context.Context) error { return nil }
+
+func TestIncrementAccessCountsOneReadOneWrite(t *testing.T) {
+	store := newFakeAccessStore(20, 0)
+	store.activation["0x1"] = 0.95
+	config := ActivationConfig{BoostPerAccess: 0.1, MaxActivation: 1.0}
+
+	uids := append(store.uids(), "0x2", "0x99") // A repeat and a missing node
+	if err := incrementAccessCounts(context.Background(), store.newTxn, uids, config); err != nil {
+		t.Fatalf("incrementAccessCounts: %v", err)
+	}
+
+	if store.roundTrips != 2 {
+		t.Errorf("round trips = %d, want 2 (one read, one write)", store.roundTrips)
+	}
+	if got := store.activation["0x2"]; got < 0.599 || got > 0.601 || store.count["0x2"] != 1 {
+		t.Errorf("0x2 = activation %v, count %d; want 0.6 and 1", got, store.count["0x2"])
+	}
+	if got := store.activation["0x1"]; got != 1.0 {
+		t.Errorf("0x1 activation = %v, want capped at 1.0", got)
+	}
+	if _, ok := store.activation["0x99"]; ok {
+		t.Error("missing node was created")
+	}
+}
+
+func TestIncrementAccessCountsRetriesAbortedBatch(t *testing.T) {
+	store := newFakeAccessStore(3, 0)
+	store.aborts = 1
+	config := ActivationConfig{BoostPerAccess: 0.1, MaxActivation: 1.0}
+
+	if err := incrementAccessCounts(context.Background(), store.newTxn, store.uids(), config); err != nil {
+		t.Fatalf("incrementAccessCounts: %v", err)
+	}
+	for _, uid := range store.uids() {
+		if store.count[uid] != 1 {
+			t.Errorf("%s access count = %d, want 1 (boosted exactly once)", uid, store.count[uid])
+		}
+	}
+
+	if err := incrementAccessCounts(context.Background(), store.newTxn, []string{"0x1 OR true"}, config); err == nil {
+		t.Error("a malformed uid was interpolated into the query")
+	}
+}
+
+// BenchmarkIncrementAccessCounts boosts the 20 nodes a consultation
+// reconsolidates over a 200µs round trip, one node at a time (as
+// IncrementAccessCount does: a read and a write each) and as one batch
+func BenchmarkIncrementAccessCounts(b *testing.B) {
+	config := DefaultActivationConfig()
+	for _, bc := range []struct {
+		name  string
+		boost func(store *fakeAccessStore) error
+	}{
+		{"per-node", func(store *fakeAccessStore) error {
+			for _, uid := range store.uids() {
+				if err := incrementAccessCounts(context.Background(), store.newTxn, []string{uid}, config); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"batched", func(store *fakeAccessStore) error {
+			return incrementAccessCounts(context.Background(), store.newTxn, store.uids(), config)
+		}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			store := newFakeAccessStore(20, 200*time.Microsecond)
+			for i := 0; i < b.N; i++ {
+				if err := bc.boost(store); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(store.roundTrips)/float64(b.N), "roundtrips/op")
+		})
+	}
+}
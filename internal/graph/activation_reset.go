@@ -0,0 +1,256 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240"
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// activationResetPage is how many nodes ResetActivations reads and rewrites
+// per transaction
+const activationResetPage = 500
+
+// ErrInvalidActivationReset marks a reset rejected before anything was written
+var ErrInvalidActivationReset = errors.New("invalid activation reset")
+
+// typeNamePattern matches a DGraph type name safe to put in a type() filter
+var typeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// NodeActivation is the part of a node that decides how readily retrieval
+// surfaces it
+type NodeActivation struct {
+	UID          string     `json:"uid"`
+	Name         string     `json:"name"`
+	Namespace    string     `json:"namespace"`
+	Type         NodeType   `json:"type,omitempty"`
+	Activation   float64    `json:"activation"`
+	AccessCount  int64      `json:"access_count"`
+	LastAccessed *time.Time `json:"last_accessed,omitempty"`
+}
+
+// ActivationResetFilter selects the nodes ResetActivations rewrites. Only
+// Namespace is required; each other field set narrows the selection.
+type ActivationResetFilter struct {
+	Namespace     string   `json:"namespace"`
+	Type          NodeType `json:"type,omitempty"`
+	NamePrefix    string   `json:"name_prefix,omitempty"`    // e.g. "Conversation_" for conversation metadata
+	MinActivation float64  `json:"min_activation,omitempty"` // Only nodes at or above it
+}
+
+// GetNodeActivation returns a node's activation, access count and last access
+func (c *Client) GetNodeActivation(ctx context.Context, uid string) (*NodeActivation, error) {
+	node, err := c.GetNode(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	state := &NodeActivation{
+		UID:         node.UID,
+		Name:        node.Name,
+		Namespace:   node.Namespace,
+		Type:        node.GetType(),
+		Activation:  node.Activation,
+		AccessCount: node.AccessCount,
+	}
+	if !node.LastAccessed.IsZero() {
+		state.LastAccessed = &node.LastAccessed
+	}
+	return state, nil
+}
+
+// ResetActivation sets a node's activation to value and its access count to
+// zero, so it surfaces as if it had just been created with that activation.
+// last_accessed is left alone for decay to carry on from.
+func (c *Client) ResetActivation(ctx context.Context, uid string, value float64) error {
+	return resetActivation(ctx, func() accessTxn { return c.dgraph().NewTxn() }, uid, value)
+}
+
+// ResetActivations does what ResetActivation does for every node filter
+// selects, a page at a time, and returns how many nodes it rewrote
+func (c *Client) ResetActivations(ctx context.Context, filter ActivationResetFilter, value float64) (int, error) {
+	return resetActivations(ctx, func() accessTxn { return c.dgraph().NewTxn() }, filter, value)
+}
+
+func validResetValue(value float64) error {
+	if value < 0 || value > 1 {
+		return fmt.Errorf("%w: activation must be in [0, 1]", ErrInvalidActivationReset)
+	}
+	return nil
+}
+
+func resetActivation(ctx context.Context, newTxn func() accessTxn, uid string, value float64) error {
+	if !uidPattern.MatchString(uid) {
+		return fmt.Errorf("%w: invalid uid %q", ErrInvalidActivationReset, uid)
+	}
+	if err := validResetValue(value); err != nil {
+		return err
+	}
+
+	txn := newTxn()
+	defer txn.Discard(ctx)
+
+	resp, err := txn.QueryWithVars(ctx, fmt.Sprintf(`{
+		nodes(func: uid(%s)) @filter(has(dgraph.type)) { uid }
+	}`, uid), nil)
+	if err != nil {
+		return fmt.Errorf("failed to query node: %w", err)
+	}
+	var result struct {
+		Nodes []struct {
+			UID string `json:"uid"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal node: %w", err)
+	}
+	if len(result.Nodes) == 0 {
+		return fmt.Errorf("node not found: %s", uid)
+	}
+
+	return commitActivationReset(ctx, txn, []string{uid}, value)
+}
+
+func resetActivations(ctx context.Context, newTxn func() accessTxn, filter ActivationResetFilter, value float64) (int, error) {
+	if filter.Namespace == "" {
+		return 0, fmt.Errorf("%w: namespace is required", ErrInvalidActivationReset)
+	}
+	if filter.Type != "" && !typeNamePattern.MatchString(string(filter.Type)) {
+		return 0, fmt.Errorf("%w: invalid node type %q", ErrInvalidActivationReset, filter.Type)
+	}
+	if err := validResetValue(value); err != nil {
+		return 0, err
+	}
+
+	conditions := []string{"has(dgraph.type)"}
+	if filter.Type != "" {
+		conditions = append(conditions, "type("+string(filter.Type)+")")
+	}
+	if filter.MinActivation > 0 {
+		conditions = append(conditions, "ge(activation, $min)")
+	}
+	vars := map[string]string{
+		"$ns":  filter.Namespace,
+		"$min": strconv.FormatFloat(filter.MinActivation, 'f', -1, 64),
+	}
+
+	reset := 0
+	after := ""
+	for {
+		page := ""
+		if after != "" {
+			page = ", after: " + after
+		}
+		query := fmt.Sprintf(`query Reset($ns: string, $min: float) {
+			nodes(func: eq(namespace, $ns), first: %d%s) @filter(%s) {
+				uid
+				name
+				activation
+				access_count
+			}
+		}`, activationResetPage, page, strings.Join(conditions, " AND "))
+
+		var (
+			count int
+			next  string
+			err   error
+		)
+		for attempt := 0; attempt < maxAccessBoostAttempts; attempt++ {
+			count, next, err = resetActivationPage(ctx, newTxn(), query, vars, filter.NamePrefix, value)
+			if !errors.Is(err, dgo.ErrAborted) {
+				break
+			}
+			// Backoff before retry
+			time.Sleep(time.Millisecond * time.Duration(10*(attempt+1)))
+		}
+		if errors.Is(err, dgo.ErrAborted) {
+			return reset, fmt.Errorf("failed to reset activations after %d attempts (too many conflicts)", maxAccessBoostAttempts)
+		}
+		if err != nil {
+			return reset, err
+		}
+		reset += count
+		if next == "" {
+			return reset, nil
+		}
+		after = next
+	}
+}
+
+// resetActivationPage reads and resets one page inside txn. When the page was
+// full, next is its last uid, for the following page to start after.
+func resetActivationPage(ctx context.Context, txn accessTxn, query string, vars map[string]string, namePrefix string, value float64) (reset int, next string, err error) {
+	defer txn.Discard(ctx)
+
+	resp, err := txn.QueryWithVars(ctx, query, vars)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read activations: %w", err)
+	}
+	var result struct {
+		Nodes []struct {
+			UID         string  `json:"uid"`
+			Name        string  `json:"name"`
+			Activation  float64 `json:"activation"`
+			AccessCount int64   `json:"access_count"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, "", fmt.Errorf("failed to unmarshal: %w", err)
+	}
+	if len(result.Nodes) == 0 {
+		return 0, "", nil
+	}
+
+	var uids []string
+	for _, node := range result.Nodes {
+		if !strings.HasPrefix(node.Name, namePrefix) {
+			continue
+		}
+		// Nodes already at the target are left out of the write
+		if node.Activation == value && node.AccessCount == 0 {
+			continue
+		}
+		uids = append(uids, node.UID)
+	}
+	if len(result.Nodes) == activationResetPage {
+		next = result.Nodes[len(result.Nodes)-1].UID
+	}
+	if len(uids) == 0 {
+		return 0, next, nil
+	}
+	if err := commitActivationReset(ctx, txn, uids, value); err != nil {
+		return 0, "", err
+	}
+	return len(uids), next, nil
+}
+
+// commitActivationReset writes value and a zero access count to uids and
+// commits txn
+func commitActivationReset(ctx context.Context, txn accessTxn, uids []string, value float64) error {
+	updates := make([]map[string]interface{}, len(uids))
+	for i, uid := range uids {
+		updates[i] = map[string]interface{}{
+			"uid":          uid,
+			"activation":   value,
+			"access_count": 0,
+		}
+	}
+	updateJSON, err := json.Marshal(updates)
+	if err != nil {
+		return fmt.Errorf("failed to marshal update: %w", err)
+	}
+
+	if _, err := txn.Mutate(ctx, &api.Mutation{SetJson: updateJSON, CommitNow: true}); err != nil {
+		if errors.Is(err, dgo.ErrAborted) {
+			return err
+		}
+		return fmt.Errorf("failed to reset activation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+type fakeActivationNode struct {
+	UID         string  `json:"uid"`
+	Name        string  `json:"name"`
+	Namespace   string  `json:"-"`
+	Activation  float64 `json:"activation"`
+	AccessCount int64   `json:"access_count"`
+}
+
+// fakeActivationStore answers resetActivation(s) queries from nodes, kept in
+// uid order as DGraph pages them
+type fakeActivationStore struct {
+	nodes  []*fakeActivationNode
+	writes int
+}
+
+var (
+	fakeAfterPattern = regexp.MustCompile(`after: (0x[0-9a-f]+)`)
+	fakeUIDPattern   = regexp.MustCompile(`uid\((0x[0-9a-f]+)\)`)
+)
+
+func (s *fakeActivationStore) newTxn() accessTxn { return &fakeActivationTxn{s: s} }
+
+type fakeActivationTxn struct{ s *fakeActivationStore }
+
+func (t *fakeActivationTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	var out []*fakeActivationNode
+	if m := fakeUIDPattern.FindStringSubmatch(q); m != nil {
+		for _, n := range t.s.nodes {
+			if n.UID == m[1] {
+				out = append(out, n)
+			}
+		}
+	} else {
+		after := ""
+		if m := fakeAfterPattern.FindStringSubmatch(q); m != nil {
+			after = m[1]
+		}
+		threshold, _ := strconv.ParseFloat(vars["$min"], 64)
+		for _, n := range t.s.nodes {
+			if len(out) == activationResetPage {
+				break
+			}
+			if n.Namespace != vars["$ns"] || (after != "" && n.UID <= after) {
+				continue
+			}
+			if strings.Contains(q, "ge(activation") && n.Activation < threshold {
+				continue
+			}
+			out = append(out, n)
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"nodes": out})
+	return &api.Response{Json: data}, nil
+}
+
+func (t *fakeActivationTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	var updates []struct {
+		UID         string  `json:"uid"`
+		Activation  float64 `json:"activation"`
+		AccessCount int64   `json:"access_count"`
+	}
+	if err := json.Unmarshal(mu.SetJson, &updates); err != nil {
+		return nil, err
+	}
+	for _, u := range updates {
+		for _, n := range t.s.nodes {
+			if n.UID == u.UID {
+				n.Activation, n.AccessCount = u.Activation, u.AccessCount
+				t.s.writes++
+			}
+		}
+	}
+	return &api.Response{}, nil
+}
+
+func (t *fakeActivationTxn) Discard(ctx context.Context) error { return nil }
+
+func TestResetActivation(t *testing.T) {
+	store := &fakeActivationStore{nodes: []*fakeActivationNode{
+		{UID: "0x1", Name: "Junk", Namespace: "user_alice", Activation: 0.85, AccessCount: 40},
+	}}
+
+	if err := resetActivation(context.Background(), store.newTxn, "0x1", 0.1); err != nil {
+		t.Fatal(err)
+	}
+	if n := store.nodes[0]; n.Activation != 0.1 || n.AccessCount != 0 {
+		t.Errorf("node = %+v, want activation 0.1 and no accesses", n)
+	}
+
+	for _, tc := range []struct {
+		uid   string
+		value float64
+	}{{"0x2", 0.1}, {"0x1> <x", 0.1}, {"0x1", 1.5}} {
+		if err := resetActivation(context.Background(), store.newTxn, tc.uid, tc.value); err == nil {
+			t.Errorf("reset %q to %g succeeded", tc.uid, tc.value)
+		}
+	}
+	if store.writes != 1 {
+		t.Errorf("%d writes, want only the valid reset", store.writes)
+	}
+}
+
+func TestResetActivationsPagesThroughFilter(t *testing.T) {
+	store := &fakeActivationStore{}
+	for i := 1; i <= activationResetPage+100; i++ {
+		name := fmt.Sprintf("Conversation_%d", i)
+		if i%2 == 0 {
+			name = fmt.Sprintf("Fact %d", i)
+		}
+		store.nodes = append(store.nodes, &fakeActivationNode{
+			UID: fmt.Sprintf("0x%04x", i), Name: name, Namespace: "user_alice", Activation: 0.8, AccessCount: 3,
+		})
+	}
+	store.nodes = append(store.nodes, &fakeActivationNode{
+		UID: "0xffff", Name: "Conversation_other", Namespace: "user_bob", Activation: 0.8, AccessCount: 3,
+	})
+
+	n, err := resetActivations(context.Background(), store.newTxn,
+		ActivationResetFilter{Namespace: "user_alice", NamePrefix: "Conversation_", MinActivation: 0.5}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (activationResetPage + 100) / 2; n != want {
+		t.Errorf("reset %d nodes, want %d", n, want)
+	}
+	for _, node := range store.nodes {
+		isReset := node.Activation == 0 && node.AccessCount == 0
+		want := node.Namespace == "user_alice" && strings.HasPrefix(node.Name, "Conversation_")
+		if isReset != want {
+			t.Errorf("node %s %q in %s reset = %v, want %v", node.UID, node.Name, node.Namespace, isReset, want)
+		}
+	}
+
+	if _, err := resetActivations(context.Background(), store.newTxn, ActivationResetFilter{Namespace: "user_alice", Type: "Fact) OR has(name"}, 0); err == nil {
+		t.Error("reset accepted an injected type")
+	}
+}
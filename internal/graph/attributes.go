@@ -0,0 +1,417 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"go.uber.org/zap"
+)
+
+// Attribute is one key/value fact about a node (role, location, custom fields).
+// Attributes are stored as child nodes linked by has_attribute so both keys
+// and values are indexed and nodes can be filtered by them.
+type Attribute struct {
+	UID   string `json:"uid,omitempty"`
+	Key   string `json:"attr_key"`
+	Value string `json:"attr_value"`
+}
+
+// attributeFields is the query block that loads a node's attributes; Node's
+// JSON decoding folds the result into Node.Attributes
+const attributeFields = `has_attribute { attr_key attr_value }`
+
+// UnmarshalJSON decodes a node, folding DGraph has_attribute children into the
+// Attributes map. Attributes come either as a JSON object or, from nodes not
+// yet migrated, as the legacy "key:value" string list.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	type nodeAlias Node
+	aux := struct {
+		*nodeAlias
+		Attributes   json.RawMessage `json:"attributes"`
+		HasAttribute []Attribute     `json:"has_attribute"`
+	}{nodeAlias: (*nodeAlias)(n)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Attributes) > 0 && string(aux.Attributes) != "null" {
+		attrs, err := decodeAttributes(aux.Attributes)
+		if err != nil {
+			return err
+		}
+		n.Attributes = attrs
+	}
+	if len(aux.HasAttribute) > 0 {
+		if n.Attributes == nil {
+			n.Attributes = make(map[string]string, len(aux.HasAttribute))
+		}
+		for _, a := range aux.HasAttribute {
+			n.Attributes[a.Key] = a.Value
+		}
+	}
+	return nil
+}
+
+// Types embedding Node inherit its UnmarshalJSON, which would decode only the
+// Node part of them. Each decodes its own fields first, with nodeDecodeShadow
+// hiding that method and the attributes Node folds, then the Node part.
+
+// nodeDecodeShadow, embedded beside a type embedding Node, hides Node's
+// UnmarshalJSON and attributes from the default decoder
+type nodeDecodeShadow struct {
+	UnmarshalJSON struct{}        `json:"-"`
+	Attributes    json.RawMessage `json:"attributes"`
+}
+
+// UnmarshalJSON decodes the insight's own fields and its Node
+func (i *Insight) UnmarshalJSON(data []byte) error {
+	type fields Insight
+	aux := struct {
+		*fields
+		nodeDecodeShadow
+	}{fields: (*fields)(i)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &i.Node)
+}
+
+// UnmarshalJSON decodes the pattern's own fields and its Node
+func (p *Pattern) UnmarshalJSON(data []byte) error {
+	type fields Pattern
+	aux := struct {
+		*fields
+		nodeDecodeShadow
+	}{fields: (*fields)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &p.Node)
+}
+
+// UnmarshalJSON decodes occurred_at and the Node
+func (t *TimelineNode) UnmarshalJSON(data []byte) error {
+	type fields TimelineNode
+	aux := struct {
+		*fields
+		nodeDecodeShadow
+	}{fields: (*fields)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &t.Node)
+}
+
+// UnmarshalJSON decodes the edge count and the Node
+func (c *PruneCandidate) UnmarshalJSON(data []byte) error {
+	type fields PruneCandidate
+	aux := struct {
+		*fields
+		nodeDecodeShadow
+	}{fields: (*fields)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.Node)
+}
+
+// decodeAttributes decodes an attributes value given as an object or as a
+// legacy string list
+func decodeAttributes(raw json.RawMessage) (map[string]string, error) {
+	var attrs map[string]string
+	if err := json.Unmarshal(raw, &attrs); err == nil {
+		return attrs, nil
+	}
+	var legacy []string
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return nil, fmt.Errorf("attributes must be an object or a string list: %w", err)
+	}
+	attrs = make(map[string]string, len(legacy))
+	for _, entry := range legacy {
+		if key, value := parseLegacyAttribute(entry); key != "" {
+			attrs[key] = value
+		}
+	}
+	return attrs, nil
+}
+
+// parseLegacyAttribute splits one entry of the legacy attributes list,
+// "key:value" or "key=value". A bare key is a flag with the value "true".
+func parseLegacyAttribute(entry string) (key, value string) {
+	entry = strings.TrimSpace(entry)
+	if i := strings.IndexAny(entry, ":="); i >= 0 {
+		return strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+	}
+	return entry, "true"
+}
+
+// sortedKeys returns the keys of attrs in a stable order
+func sortedKeys(attrs map[string]string) []string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeAttributeNquads writes one Attribute child node per non-empty value,
+// linked from subject (a blank node or <uid>)
+func writeAttributeNquads(nquads *strings.Builder, subject string, attrs map[string]string) {
+	prefix := strings.Trim(subject, "_:<>")
+	for i, key := range sortedKeys(attrs) {
+		value := attrs[key]
+		if key == "" || value == "" {
+			continue
+		}
+		child := fmt.Sprintf("_:attr_%s_%d", prefix, i)
+		nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "Attribute" .
+%s <attr_key> %s .
+%s <attr_value> %s .
+%s <has_attribute> %s .
+`, child, child, escapeRDFString(key), child, escapeRDFString(value), subject, child))
+	}
+}
+
+// attributeUIDs returns the Attribute children of a node keyed by attribute key
+func (c *Client) attributeUIDs(ctx context.Context, uid string) (map[string]string, error) {
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, `query Attrs($uid: string) {
+		node(func: uid($uid)) {
+			has_attribute { uid attr_key }
+		}
+	}`, map[string]string{"$uid": uid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attributes: %w", err)
+	}
+
+	var result struct {
+		Node []struct {
+			HasAttribute []Attribute `json:"has_attribute"`
+		} `json:"node"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+	}
+
+	uids := make(map[string]string)
+	for _, n := range result.Node {
+		for _, a := range n.HasAttribute {
+			uids[a.Key] = a.UID
+		}
+	}
+	return uids, nil
+}
+
+// GetAttributes returns the attributes of a node
+func (c *Client) GetAttributes(ctx context.Context, uid string) (map[string]string, error) {
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, `query Attrs($uid: string) {
+		node(func: uid($uid)) {
+			`+attributeFields+`
+		}
+	}`, map[string]string{"$uid": uid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attributes: %w", err)
+	}
+
+	var result struct {
+		Node []Node `json:"node"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+	}
+	if len(result.Node) == 0 || result.Node[0].Attributes == nil {
+		return map[string]string{}, nil
+	}
+	return result.Node[0].Attributes, nil
+}
+
+// SetAttributes sets the given attributes on a node, replacing existing values
+// for the same keys and leaving other keys untouched. An empty value removes
+// the key.
+func (c *Client) SetAttributes(ctx context.Context, uid string, attrs map[string]string) error {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	existing, err := c.attributeUIDs(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	var del strings.Builder
+	for key := range attrs {
+		if childUID, ok := existing[key]; ok {
+			del.WriteString(fmt.Sprintf("<%s> <has_attribute> <%s> .\n<%s> * * .\n", uid, childUID, childUID))
+		}
+	}
+	var set strings.Builder
+	writeAttributeNquads(&set, "<"+uid+">", attrs)
+
+	mu := &api.Mutation{CommitNow: true}
+	if del.Len() > 0 {
+		mu.DelNquads = []byte(del.String())
+	}
+	if set.Len() > 0 {
+		mu.SetNquads = []byte(set.String())
+	}
+	if mu.DelNquads == nil && mu.SetNquads == nil {
+		return nil
+	}
+
+	if _, err := c.dgraph().NewTxn().Mutate(ctx, mu); err != nil {
+		return fmt.Errorf("failed to set attributes: %w", err)
+	}
+
+	c.logger.Debug("Attributes set",
+		zap.String("uid", uid),
+		zap.Int("count", len(attrs)))
+	return nil
+}
+
+// FindByAttributes returns nodes in namespace whose attributes match every
+// key/value pair in attrs exactly. A non-empty name further keeps nodes whose
+// name shares a word with it; the filter runs before the limit.
+func (c *Client) FindByAttributes(ctx context.Context, namespace string, attrs map[string]string, name string, limit int) ([]Node, error) {
+	if len(attrs) == 0 {
+		return nil, fmt.Errorf("at least one attribute is required")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	vars := map[string]string{"$ns": namespace}
+	params := []string{"$ns: string"}
+	var blocks strings.Builder
+	var filters []string
+	for i, key := range sortedKeys(attrs) {
+		k, v := fmt.Sprintf("$k%d", i), fmt.Sprintf("$v%d", i)
+		vars[k], vars[v] = key, attrs[key]
+		params = append(params, k+": string", v+": string")
+		blocks.WriteString(fmt.Sprintf(`
+		var(func: eq(attr_key, %s)) @filter(eq(attr_value, %s)) {
+			e%d as ~has_attribute
+		}`, k, v, i))
+		filters = append(filters, fmt.Sprintf("uid(e%d)", i))
+	}
+	if name != "" {
+		vars["$name"] = name
+		params = append(params, "$name: string")
+		filters = append(filters, "anyoftext(name, $name)")
+	}
+
+	query := fmt.Sprintf(`query FindByAttributes(%s) {%s
+		nodes(func: eq(namespace, $ns), first: %d) @filter(%s) {
+			uid
+			dgraph.type
+			name
+			description
+			namespace
+			%s
+			tags
+			activation
+			created_at
+		}
+	}`, strings.Join(params, ", "), blocks.String(), limit, strings.Join(filters, " AND "), attributeFields)
+
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query by attributes: %w", err)
+	}
+
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal nodes: %w", err)
+	}
+	return result.Nodes, nil
+}
+
+// legacyAttributePage is how many nodes the legacy attributes backfill reads
+// and rewrites per transaction
+const legacyAttributePage = 500
+
+// backfillLegacyAttributes moves attributes stored in the legacy
+// attributes string list into has_attribute children
+func backfillLegacyAttributes(ctx context.Context, c *Client) error {
+	migrated, err := migrateLegacyAttributes(ctx, func() accessTxn { return c.dgraph().NewTxn() })
+	if err != nil {
+		return err
+	}
+	c.logger.Info("Legacy attributes migrated", zap.Int("nodes", migrated))
+	return nil
+}
+
+// migrateLegacyAttributes rewrites every node that still has the legacy
+// attributes predicate, a page at a time, until none is left. Keys the node
+// already has as has_attribute children keep their current value. It returns
+// how many nodes it rewrote.
+func migrateLegacyAttributes(ctx context.Context, newTxn func() accessTxn) (int, error) {
+	migrated := 0
+	for {
+		count, err := migrateLegacyAttributePage(ctx, newTxn())
+		if err != nil {
+			return migrated, err
+		}
+		migrated += count
+		if count < legacyAttributePage {
+			return migrated, nil
+		}
+	}
+}
+
+// migrateLegacyAttributePage rewrites one page of nodes with legacy
+// attributes inside txn. Each rewritten node loses the legacy predicate, so
+// the next page starts from the nodes still left.
+func migrateLegacyAttributePage(ctx context.Context, txn accessTxn) (int, error) {
+	defer txn.Discard(ctx)
+
+	resp, err := txn.QueryWithVars(ctx, fmt.Sprintf(`{
+		nodes(func: has(attributes), first: %d) {
+			uid
+			attributes
+			has_attribute { attr_key }
+		}
+	}`, legacyAttributePage), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read legacy attributes: %w", err)
+	}
+	var result struct {
+		Nodes []struct {
+			UID          string          `json:"uid"`
+			Attributes   json.RawMessage `json:"attributes"`
+			HasAttribute []Attribute     `json:"has_attribute"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal legacy attributes: %w", err)
+	}
+	if len(result.Nodes) == 0 {
+		return 0, nil
+	}
+
+	var set, del strings.Builder
+	for _, node := range result.Nodes {
+		// A list that cannot be decoded is still dropped, so the page is
+		// not read again
+		attrs, _ := decodeAttributes(node.Attributes)
+		for _, a := range node.HasAttribute {
+			delete(attrs, a.Key)
+		}
+		subject := "<" + node.UID + ">"
+		writeAttributeNquads(&set, subject, attrs)
+		del.WriteString(fmt.Sprintf("%s <attributes> * .\n", subject))
+	}
+
+	mu := &api.Mutation{DelNquads: []byte(del.String()), CommitNow: true}
+	if set.Len() > 0 {
+		mu.SetNquads = []byte(set.String())
+	}
+	if _, err := txn.Mutate(ctx, mu); err != nil {
+		return 0, fmt.Errorf("failed to migrate legacy attributes: %w", err)
+	}
+	return len(result.Nodes), nil
+}
@@ -0,0 +1,251 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+func TestNodeUnmarshalAttributes(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want map[string]string
+	}{
+		{"has_attribute children", `{"uid": "0x1", "has_attribute": [{"attr_key": "role", "attr_value": "cto"}]}`,
+			map[string]string{"role": "cto"}},
+		{"object", `{"uid": "0x1", "attributes": {"team": "core"}}`,
+			map[string]string{"team": "core"}},
+		{"legacy list", `{"uid": "0x1", "attributes": ["role:cto", "city = Paris", "remote"]}`,
+			map[string]string{"role": "cto", "city": "Paris", "remote": "true"}},
+		{"children win over legacy", `{"uid": "0x1", "attributes": ["role:intern"], "has_attribute": [{"attr_key": "role", "attr_value": "cto"}]}`,
+			map[string]string{"role": "cto"}},
+		{"none", `{"uid": "0x1", "attributes": null}`, nil},
+	}
+	for _, tt := range tests {
+		var n Node
+		if err := json.Unmarshal([]byte(tt.json), &n); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if n.UID != "0x1" {
+			t.Errorf("%s: uid = %q, other fields were lost", tt.name, n.UID)
+		}
+		if !reflect.DeepEqual(n.Attributes, tt.want) {
+			t.Errorf("%s: attributes = %v, want %v", tt.name, n.Attributes, tt.want)
+		}
+	}
+
+	var n Node
+	if err := json.Unmarshal([]byte(`{"attributes": 3}`), &n); err == nil {
+		t.Error("a numeric attributes value was accepted")
+	}
+}
+
+func TestNodeEmbeddersUnmarshalOwnFields(t *testing.T) {
+	const node = `"uid": "0x1", "name": "Alice", "attributes": ["role:cto"]`
+
+	var insight Insight
+	if err := json.Unmarshal([]byte(`{`+node+`, "summary": "Teal everywhere", "insight_type": "trend"}`), &insight); err != nil {
+		t.Fatalf("Insight: %v", err)
+	}
+	if insight.Summary != "Teal everywhere" || insight.InsightType != "trend" {
+		t.Errorf("insight fields lost: %+v", insight)
+	}
+
+	var pattern Pattern
+	if err := json.Unmarshal([]byte(`{`+node+`, "pattern_type": "weekly", "frequency": 3, "trigger_nodes": ["0x2"]}`), &pattern); err != nil {
+		t.Fatalf("Pattern: %v", err)
+	}
+	if pattern.PatternType != "weekly" || pattern.Frequency != 3 || len(pattern.TriggerNodes) != 1 {
+		t.Errorf("pattern fields lost: %+v", pattern)
+	}
+
+	var timeline TimelineNode
+	if err := json.Unmarshal([]byte(`{`+node+`, "occurred_at": "2026-03-01T10:00:00Z"}`), &timeline); err != nil {
+		t.Fatalf("TimelineNode: %v", err)
+	}
+	if timeline.OccurredAt == nil || timeline.OccurredAt.Day() != 1 {
+		t.Errorf("occurred_at lost: %+v", timeline)
+	}
+
+	var candidate PruneCandidate
+	if err := json.Unmarshal([]byte(`{`+node+`, "edges": 4}`), &candidate); err != nil {
+		t.Fatalf("PruneCandidate: %v", err)
+	}
+	if candidate.Edges != 4 {
+		t.Errorf("edges lost: %+v", candidate)
+	}
+
+	for name, n := range map[string]Node{"Insight": insight.Node, "Pattern": pattern.Node, "TimelineNode": timeline.Node, "PruneCandidate": candidate.Node} {
+		if n.UID != "0x1" || n.Name != "Alice" || n.Attributes["role"] != "cto" {
+			t.Errorf("%s: node = %+v, want the node fields and folded attributes", name, n)
+		}
+	}
+}
+
+func TestWriteAttributeNquads(t *testing.T) {
+	var nquads strings.Builder
+	writeAttributeNquads(&nquads, "<0x1>", map[string]string{
+		"role":  "cto",
+		"empty": "",
+		"quote": `says "hi"`,
+	})
+	want := `_:attr_0x1_1 <dgraph.type> "Attribute" .
+_:attr_0x1_1 <attr_key> "quote" .
+_:attr_0x1_1 <attr_value> "says \"hi\"" .
+<0x1> <has_attribute> _:attr_0x1_1 .
+_:attr_0x1_2 <dgraph.type> "Attribute" .
+_:attr_0x1_2 <attr_key> "role" .
+_:attr_0x1_2 <attr_value> "cto" .
+<0x1> <has_attribute> _:attr_0x1_2 .
+`
+	if nquads.String() != want {
+		t.Errorf("nquads =\n%s\nwant\n%s", nquads.String(), want)
+	}
+
+	// Blank subjects name their children apart from other nodes in the batch
+	nquads.Reset()
+	writeAttributeNquads(&nquads, "_:node3", map[string]string{"role": "cto"})
+	if !strings.Contains(nquads.String(), "_:node3 <has_attribute> _:attr_node3_0 .") {
+		t.Errorf("blank subject nquads = %s", nquads.String())
+	}
+}
+
+type fakeLegacyNode struct {
+	UID          string      `json:"uid"`
+	Attributes   []string    `json:"attributes,omitempty"`
+	HasAttribute []Attribute `json:"has_attribute,omitempty"`
+}
+
+// fakeLegacyStore answers migrateLegacyAttributes from nodes, applying each
+// mutation so pages shrink as nodes are migrated
+type fakeLegacyStore struct {
+	nodes   []*fakeLegacyNode
+	commits int
+}
+
+func (s *fakeLegacyStore) newTxn() accessTxn { return &fakeLegacyTxn{s} }
+
+type fakeLegacyTxn struct{ s *fakeLegacyStore }
+
+func (t *fakeLegacyTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	var out []*fakeLegacyNode
+	for _, n := range t.s.nodes {
+		if len(out) == legacyAttributePage {
+			break
+		}
+		if len(n.Attributes) > 0 {
+			out = append(out, n)
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"nodes": out})
+	return &api.Response{Json: data}, nil
+}
+
+func (t *fakeLegacyTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	byUID := make(map[string]*fakeLegacyNode, len(t.s.nodes))
+	for _, n := range t.s.nodes {
+		byUID[n.UID] = n
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(mu.DelNquads)), "\n") {
+		var uid string
+		if _, err := fmt.Sscanf(line, "<%s <attributes> * .", &uid); err == nil {
+			byUID[strings.TrimSuffix(uid, ">")].Attributes = nil
+		}
+	}
+	children := map[string]*Attribute{}
+	for _, line := range strings.Split(strings.TrimSpace(string(mu.SetNquads)), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		subject, pred, object := fields[0], fields[1], strings.TrimSuffix(fields[2], " .")
+		switch pred {
+		case "<attr_key>", "<attr_value>":
+			a := children[subject]
+			if a == nil {
+				a = &Attribute{}
+				children[subject] = a
+			}
+			value, _ := unquoteRDF(object)
+			if pred == "<attr_key>" {
+				a.Key = value
+			} else {
+				a.Value = value
+			}
+		case "<has_attribute>":
+			n := byUID[strings.Trim(subject, "<>")]
+			n.HasAttribute = append(n.HasAttribute, Attribute{UID: object})
+		}
+	}
+	for _, n := range t.s.nodes {
+		for i, a := range n.HasAttribute {
+			if child, ok := children[a.UID]; ok {
+				n.HasAttribute[i] = *child
+			}
+		}
+	}
+	t.s.commits++
+	return &api.Response{}, nil
+}
+
+func (t *fakeLegacyTxn) Discard(ctx context.Context) error { return nil }
+
+// unquoteRDF reads back a literal written by escapeRDFString
+func unquoteRDF(literal string) (string, error) {
+	var s string
+	err := json.Unmarshal([]byte(literal), &s)
+	return s, err
+}
+
+func TestMigrateLegacyAttributes(t *testing.T) {
+	store := &fakeLegacyStore{}
+	for i := 1; i <= legacyAttributePage+3; i++ {
+		store.nodes = append(store.nodes, &fakeLegacyNode{UID: fmt.Sprintf("0x%04x", i), Attributes: []string{"team:core"}})
+	}
+	store.nodes = append(store.nodes,
+		&fakeLegacyNode{UID: "0x1000", Attributes: []string{"role:intern", "city=Paris"},
+			HasAttribute: []Attribute{{UID: "0x2000", Key: "role", Value: "cto"}}},
+		&fakeLegacyNode{UID: "0x1001", HasAttribute: []Attribute{{UID: "0x2001", Key: "role", Value: "cto"}}},
+	)
+
+	n, err := migrateLegacyAttributes(context.Background(), store.newTxn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := legacyAttributePage + 4; n != want {
+		t.Errorf("migrated %d nodes, want %d", n, want)
+	}
+	if store.commits != 2 {
+		t.Errorf("%d commits, want one per page", store.commits)
+	}
+
+	attrs := func(n *fakeLegacyNode) map[string]string {
+		out := map[string]string{}
+		for _, a := range n.HasAttribute {
+			out[a.Key] = a.Value
+		}
+		return out
+	}
+	for _, node := range store.nodes {
+		if len(node.Attributes) > 0 {
+			t.Errorf("%s still has legacy attributes %v", node.UID, node.Attributes)
+		}
+	}
+	if got := attrs(store.nodes[legacyAttributePage+1]); !reflect.DeepEqual(got, map[string]string{"team": "core"}) {
+		t.Errorf("second page node attributes = %v", got)
+	}
+	// Keys already migrated keep their value; only missing ones are added
+	if got := attrs(store.nodes[legacyAttributePage+3]); !reflect.DeepEqual(got, map[string]string{"role": "cto", "city": "Paris"}) {
+		t.Errorf("partly migrated node attributes = %v", got)
+	}
+	if got := store.nodes[legacyAttributePage+4].HasAttribute; len(got) != 1 {
+		t.Errorf("node without legacy attributes was rewritten: %v", got)
+	}
+}
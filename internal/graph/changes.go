@@ -0,0 +1,186 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Change kinds reported in NodeChange.Change
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+)
+
+// NodeChange is a node created or updated within a time window
+type NodeChange struct {
+	UID    string    `json:"uid"`
+	Name   string    `json:"name"`
+	Type   string    `json:"type"`
+	Change string    `json:"change"` // ChangeCreated or ChangeUpdated
+	At     time.Time `json:"at"`
+}
+
+// EdgeChange is an edge added within a time window. Only edges written with a
+// created_at facet (all edges since change tracking) are reported.
+type EdgeChange struct {
+	FromUID   string    `json:"from_uid"`
+	FromName  string    `json:"from_name"`
+	Predicate string    `json:"predicate"`
+	ToUID     string    `json:"to_uid"`
+	ToName    string    `json:"to_name"`
+	At        time.Time `json:"at"`
+}
+
+// NodeChangesSince returns nodes in namespace created or updated at or after
+// since, newest first. The bool reports whether limit cut the result short.
+func (c *Client) NodeChangesSince(ctx context.Context, namespace string, since time.Time, limit int) ([]NodeChange, bool, error) {
+	// Both blocks start from the datetime indexes; updated skips nodes already reported as created
+	query := fmt.Sprintf(`query Changes($ns: string, $since: string) {
+		created(func: ge(created_at, $since), orderdesc: created_at, first: %d) @filter(eq(namespace, $ns)) {
+			uid
+			dgraph.type
+			name
+			created_at
+		}
+		updated(func: ge(updated_at, $since), orderdesc: updated_at, first: %d) @filter(eq(namespace, $ns) AND lt(created_at, $since)) {
+			uid
+			dgraph.type
+			name
+			updated_at
+		}
+	}`, limit+1, limit+1)
+
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{
+		"$ns":    namespace,
+		"$since": since.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query node changes: %w", err)
+	}
+
+	type changedNode struct {
+		UID       string    `json:"uid"`
+		DType     []string  `json:"dgraph.type"`
+		Name      string    `json:"name"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	var result struct {
+		Created []changedNode `json:"created"`
+		Updated []changedNode `json:"updated"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal node changes: %w", err)
+	}
+
+	changes := make([]NodeChange, 0, len(result.Created)+len(result.Updated))
+	for _, n := range result.Created {
+		changes = append(changes, NodeChange{UID: n.UID, Name: n.Name, Type: nodeTypeOf(n.DType), Change: ChangeCreated, At: n.CreatedAt})
+	}
+	for _, n := range result.Updated {
+		changes = append(changes, NodeChange{UID: n.UID, Name: n.Name, Type: nodeTypeOf(n.DType), Change: ChangeUpdated, At: n.UpdatedAt})
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At.After(changes[j].At) })
+
+	truncated := len(changes) > limit
+	if truncated {
+		changes = changes[:limit]
+	}
+	return changes, truncated, nil
+}
+
+// EdgeChangesSince returns edges out of namespace nodes whose created_at facet
+// is at or after since, newest first. The bool reports whether limit cut the
+// result short.
+func (c *Client) EdgeChangesSince(ctx context.Context, namespace string, since time.Time, limit int) ([]EdgeChange, bool, error) {
+	// Facet filters take literals; since is formatted here, never user text
+	sinceLit := since.UTC().Format(time.RFC3339)
+
+	var blocks strings.Builder
+	for i, pred := range expandPredicates {
+		if pred == "has_attribute" {
+			continue
+		}
+		blocks.WriteString(fmt.Sprintf(`
+		e%d(func: uid(src)) @filter(has(%s)) {
+			uid
+			name
+			%s @facets(ge(created_at, "%s")) @facets(created_at) { uid name }
+		}`, i, pred, pred, sinceLit))
+	}
+	query := fmt.Sprintf(`query EdgeChanges($ns: string) {
+		src as var(func: eq(namespace, $ns))%s
+	}`, blocks.String())
+
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$ns": namespace})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query edge changes: %w", err)
+	}
+
+	var result map[string][]map[string]interface{}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal edge changes: %w", err)
+	}
+
+	var changes []EdgeChange
+	for i, pred := range expandPredicates {
+		for _, src := range result[fmt.Sprintf("e%d", i)] {
+			fromUID, _ := src["uid"].(string)
+			fromName, _ := src["name"].(string)
+			for _, target := range edgeTargets(src[pred]) {
+				stamp, _ := target[pred+"|created_at"].(string)
+				at, err := time.Parse(time.RFC3339, stamp)
+				if err != nil {
+					continue
+				}
+				toUID, _ := target["uid"].(string)
+				toName, _ := target["name"].(string)
+				changes = append(changes, EdgeChange{
+					FromUID:   fromUID,
+					FromName:  fromName,
+					Predicate: pred,
+					ToUID:     toUID,
+					ToName:    toName,
+					At:        at,
+				})
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At.After(changes[j].At) })
+
+	truncated := len(changes) > limit
+	if truncated {
+		changes = changes[:limit]
+	}
+	return changes, truncated, nil
+}
+
+// edgeTargets normalizes a predicate value, which DGraph returns as an object
+// for single-uid predicates and as a list otherwise
+func edgeTargets(v interface{}) []map[string]interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{t}
+	case []interface{}:
+		targets := make([]map[string]interface{}, 0, len(t))
+		for _, item := range t {
+			if m, ok := item.(map[string]interface{}); ok {
+				targets = append(targets, m)
+			}
+		}
+		return targets
+	}
+	return nil
+}
+
+// nodeTypeOf returns the primary DGraph type of a node, defaulting to Entity
+func nodeTypeOf(dtypes []string) string {
+	if t := primaryType(dtypes); t != "" {
+		return string(t)
+	}
+	return string(NodeTypeEntity)
+}
@@ -6,10 +6,13 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/dgo/v240"
@@ -18,12 +21,21 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+	"github.com/reflective-memory-kernel/internal/textnorm"
 )
 
-// Client wraps the DGraph client with connection pooling and helper methods
+// DefaultPoolSize is the number of gRPC connections opened to DGraph
+const DefaultPoolSize = 4
+
+// Client wraps the DGraph client with connection pooling and helper methods.
+// Each pooled connection has its own dgo client; new transactions are spread
+// across them round-robin so concurrent writers don't share one HTTP/2 stream limit.
 type Client struct {
-	conn   *grpc.ClientConn
-	dg     *dgo.Dgraph
+	conns  []*grpc.ClientConn
+	dgs    []*dgo.Dgraph // Parallel to conns
+	next   atomic.Uint64
 	logger *zap.Logger
 	mu     sync.RWMutex
 }
@@ -34,6 +46,7 @@ type ClientConfig struct {
 	MaxRetries     int
 	RetryInterval  time.Duration
 	RequestTimeout time.Duration
+	PoolSize       int // Connections in the pool (0 = DefaultPoolSize)
 }
 
 // DefaultClientConfig returns sensible defaults
@@ -43,6 +56,7 @@ func DefaultClientConfig() ClientConfig {
 		MaxRetries:     5,
 		RetryInterval:  2 * time.Second,
 		RequestTimeout: 10 * time.Second, // Default 10s timeout for DGraph calls
+		PoolSize:       DefaultPoolSize,
 	}
 }
 
@@ -73,12 +87,11 @@ func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
 	}
 }
 
-// NewClient creates a new DGraph client with connection pooling
-func NewClient(ctx context.Context, cfg ClientConfig, logger *zap.Logger) (*Client, error) {
+// dial opens one gRPC connection to DGraph, retrying with backoff
+func dial(ctx context.Context, cfg ClientConfig, logger *zap.Logger) (*grpc.ClientConn, error) {
 	var conn *grpc.ClientConn
 	var err error
 
-	// Retry connection with backoff
 	for i := 0; i < cfg.MaxRetries; i++ {
 		conn, err = grpc.DialContext(ctx, cfg.Address,
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -86,285 +99,89 @@ func NewClient(ctx context.Context, cfg ClientConfig, logger *zap.Logger) (*Clie
 			grpc.WithUnaryInterceptor(timeoutInterceptor(cfg.RequestTimeout)),
 		)
 		if err == nil {
-			break
+			return conn, nil
 		}
 		logger.Warn("Failed to connect to DGraph, retrying...",
 			zap.Int("attempt", i+1),
 			zap.Error(err))
 		time.Sleep(cfg.RetryInterval)
 	}
+	return nil, fmt.Errorf("failed to connect to DGraph after %d attempts: %w", cfg.MaxRetries, err)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to DGraph after %d attempts: %w", cfg.MaxRetries, err)
+// NewClient creates a new DGraph client with connection pooling
+func NewClient(ctx context.Context, cfg ClientConfig, logger *zap.Logger) (*Client, error) {
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = DefaultPoolSize
 	}
 
-	dg := dgo.NewDgraphClient(api.NewDgraphClient(conn))
-
-	client := &Client{
-		conn:   conn,
-		dg:     dg,
-		logger: logger,
+	client := &Client{logger: logger}
+	for i := 0; i < poolSize; i++ {
+		conn, err := dial(ctx, cfg, logger)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+		client.conns = append(client.conns, conn)
+		client.dgs = append(client.dgs, dgo.NewDgraphClient(api.NewDgraphClient(conn)))
 	}
 
-	// Initialize schema
-	if err := client.initSchema(ctx); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	// Apply pending schema migrations
+	if err := client.migrateSchema(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	logger.Info("DGraph client connected successfully", zap.String("address", cfg.Address))
+	logger.Info("DGraph client connected successfully",
+		zap.String("address", cfg.Address),
+		zap.Int("pool_size", poolSize))
 	return client, nil
 }
 
-// initSchema sets up the DGraph schema for the Knowledge Graph
-func (c *Client) initSchema(ctx context.Context) error {
-	schema := `
-		# Edges
-		group_has_admin: [uid] @reverse .
-		group_has_member: [uid] @reverse .
-
-		# Node types
-		# Group Management (V2)
-
-		type Group {
-			name
-			description
-			namespace
-			created_by
-			created_at
-			updated_at
-			group_has_admin
-			group_has_member
-		}
-
-		type User {
-			name
-			description
-			attributes
-			created_at
-			updated_at
-			last_accessed
-			activation
-			access_count
-		}
-
-		type UserSettings {
-			nim_api_key_encrypted
-			openai_api_key_encrypted
-			anthropic_api_key_encrypted
-			theme
-			notifications_enabled
-			updated_at
-		}
-
-		type Entity {
-			name
-			description
-			attributes
-			created_at
-			updated_at
-			last_accessed
-			activation
-			access_count
-			entity_type
-			tags
-		}
-
-		type Event {
-			name
-			description
-			attributes
-			created_at
-			updated_at
-			occurred_at
-			sentiment
-		}
-
-		type Insight {
-			name
-			description
-			insight_type
-			summary
-			action_suggestion
-			source_nodes
-			created_at
-			confidence
-		}
-
-		type Pattern {
-			name
-			description
-			pattern_type
-			trigger_nodes
-			frequency
-			confidence_score
-			predicted_action
-			created_at
-		}
-
-		type Fact {
-			name
-			description
-			fact_value
-			created_at
-			valid_from
-			valid_until
-			status
-		}
-
-		# Predicates with indexes
-		name: string @index(hash) @index(fulltext) .
-		description: string @index(fulltext) .
-		attributes: [string] .
-		tags: [string] .
-		entity_type: string @index(exact) .
-		namespace: string @index(exact) .
-		created_by: string @index(exact) .
-		
-		# Temporal predicates
-		created_at: datetime .
-		updated_at: datetime .
-		last_accessed: datetime .
-		occurred_at: datetime .
-		valid_from: datetime .
-		valid_until: datetime .
-		
-		# Activation and prioritization (indexed for reordering queries)
-		activation: float @index(float) .
-		access_count: int @index(int) .
-		traversal_cost: float .
-		
-		# Insight/Pattern specific
-		insight_type: string .
-		pattern_type: string .
-		summary: string .
-		action_suggestion: string .
-		predicted_action: string .
-		frequency: int .
-		confidence: float .
-		confidence_score: float .
-		fact_value: string .
-		status: string .
-		sentiment: string .
-		
-		# Source tracking
-		source_conversation_id: string .
-		source_nodes: [uid] .
-		trigger_nodes: [uid] .
-		
-		# Relationship predicates (edges)
-		partner_is: uid @reverse .
-		family_member: [uid] @reverse .
-		friend_of: [uid] @reverse .
-		has_manager: uid @reverse .
-		works_on: [uid] @reverse .
-		works_at: uid @reverse .
-		colleague: [uid] @reverse .
-		likes: [uid] @reverse .
-		dislikes: [uid] @reverse .
-		is_allergic_to: [uid] @reverse .
-		prefers: [uid] @reverse .
-		has_interest: [uid] @reverse .
-		caused_by: [uid] @reverse .
-		blocked_by: [uid] @reverse .
-		results_in: [uid] @reverse .
-		contradicts: [uid] @reverse .
-		occurred_on: uid @reverse .
-		scheduled_at: datetime .
-		derived_from: [uid] @reverse .
-		synthesized_from: [uid] @reverse .
-		supersedes: uid @reverse .
-		knows: [uid] @reverse .
-		
-		# Edge metadata predicates
-		edge_status: string .
-		edge_created_at: datetime .
-		edge_activation: float .
-		edge_confidence: float .
-
-		# Workspace Collaboration Types
-		type WorkspaceInvitation {
-			workspace_id
-			invitee_user_id
-			role
-			status
-			created_at
-			created_by
-		}
-
-		type ShareLink {
-			workspace_id
-			token
-			role
-			max_uses
-			current_uses
-			expires_at
-			is_active
-			created_at
-			created_by
-		}
-
-		# Workspace Collaboration Predicates
-		workspace_id: string @index(exact) .
-		invitee_user_id: string @index(exact) .
-		token: string @index(exact) .
-		max_uses: int .
-		current_uses: int .
-		expires_at: datetime .
-		is_active: bool @index(bool) .
-		role: string @index(exact) .
-
-		# User Settings Predicates
-		nim_api_key_encrypted: string .
-		openai_api_key_encrypted: string .
-		anthropic_api_key_encrypted: string .
-		glm_api_key_encrypted: string .
-		theme: string .
-		notifications_enabled: bool .
-
-	`
-
-	op := &api.Operation{Schema: schema}
-	if err := c.dg.Alter(ctx, op); err != nil {
-		return fmt.Errorf("failed to alter schema: %w", err)
-	}
-
-	// Try to add reverse edge for user_settings if it doesn't exist
-	// This is a best-effort operation - if it fails, we continue anyway
-	reverseEdgeSchema := `user_settings: uid @reverse .`
-	reverseOp := &api.Operation{Schema: reverseEdgeSchema}
-	if err := c.dg.Alter(ctx, reverseOp); err != nil {
-		// Log but don't fail - the predicate might already exist with reverse edge
-		c.logger.Debug("Could not add reverse edge for user_settings (may already exist)", zap.Error(err))
-	}
-
-	c.logger.Info("DGraph schema initialized successfully")
-	return nil
+// dgraph returns the next pooled dgo client in round-robin order. A transaction
+// stays on the connection it was created from.
+func (c *Client) dgraph() *dgo.Dgraph {
+	n := c.next.Add(1) - 1
+	return c.dgs[n%uint64(len(c.dgs))]
 }
 
-// Close closes the DGraph connection
+// Close closes every pooled DGraph connection, returning the first error
 func (c *Client) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if c.conn != nil {
-		return c.conn.Close()
+	var firstErr error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	return nil
+	c.conns = nil
+	return firstErr
 }
 
 // NewTxn creates a new transaction
 func (c *Client) NewTxn() *dgo.Txn {
-	return c.dg.NewTxn()
+	return c.dgraph().NewTxn()
 }
 
-// GetDgraphClient returns the underlying DGraph client for advanced operations
+// GetDgraphClient returns a pooled DGraph client for advanced operations
 func (c *Client) GetDgraphClient() *dgo.Dgraph {
-	return c.dg
+	return c.dgraph()
 }
 
 // NewReadOnlyTxn creates a new read-only transaction
 func (c *Client) NewReadOnlyTxn() *dgo.Txn {
-	return c.dg.NewReadOnlyTxn()
+	return c.dgraph().NewReadOnlyTxn()
+}
+
+// blankNodeSeq numbers blank nodes process-wide so no two mutations ever share
+// a blank-node key, however close together they run
+var blankNodeSeq atomic.Uint64
+
+// newBlankNode returns a unique blank node such as "_:node_42"
+func newBlankNode(prefix string) string {
+	return fmt.Sprintf("_:%s_%d", prefix, blankNodeSeq.Add(1))
 }
 
 // CreateNode creates a new node in the graph using NQuad format for reliability
@@ -378,23 +195,23 @@ func (c *Client) CreateNode(ctx context.Context, node *Node) (string, error) {
 	}
 
 	// Generate a unique blank node ID
-	blankNode := fmt.Sprintf("_:node_%d", time.Now().UnixNano())
+	blankNode := newBlankNode("node")
 
 	// Build NQuads for reliable mutation
 	var nquads strings.Builder
 
 	// Type (required)
-	nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "%s" .
-`, blankNode, node.GetType()))
+	nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> %s .
+`, blankNode, escapeRDFString(string(node.GetType()))))
 
 	// Name (required)
-	nquads.WriteString(fmt.Sprintf(`%s <name> %q .
-`, blankNode, node.Name))
+	nquads.WriteString(fmt.Sprintf(`%s <name> %s .
+`, blankNode, escapeRDFString(node.Name)))
 
 	// Namespace (critical for isolation)
 	if node.Namespace != "" {
-		nquads.WriteString(fmt.Sprintf(`%s <namespace> %q .
-`, blankNode, node.Namespace))
+		nquads.WriteString(fmt.Sprintf(`%s <namespace> %s .
+`, blankNode, escapeRDFString(node.Namespace)))
 	}
 
 	// Activation
@@ -415,25 +232,33 @@ func (c *Client) CreateNode(ctx context.Context, node *Node) (string, error) {
 
 	// Optional fields
 	if node.Description != "" {
-		nquads.WriteString(fmt.Sprintf(`%s <description> %q .
-`, blankNode, node.Description))
+		nquads.WriteString(fmt.Sprintf(`%s <description> %s .
+`, blankNode, escapeRDFString(node.Description)))
+	}
+	if node.ContentType != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <content_type> %s .
+`, blankNode, escapeRDFString(node.ContentType)))
 	}
 	if node.SourceConversationID != "" {
-		nquads.WriteString(fmt.Sprintf(`%s <source_conversation_id> %q .
-`, blankNode, node.SourceConversationID))
+		nquads.WriteString(fmt.Sprintf(`%s <source_conversation_id> %s .
+`, blankNode, escapeRDFString(node.SourceConversationID)))
 	}
 
 	for _, tag := range node.Tags {
-		nquads.WriteString(fmt.Sprintf(`%s <tags> %q .
-`, blankNode, tag))
+		nquads.WriteString(fmt.Sprintf(`%s <tags> %s .
+`, blankNode, escapeRDFString(tag)))
 	}
 
+	writeValidityNquads(&nquads, blankNode, node)
+	writeAttributeNquads(&nquads, blankNode, node.Attributes)
+	writeSearchTermsNquad(&nquads, blankNode, node.Name, node.Description)
+
 	c.logger.Debug("Creating node with NQuads",
 		zap.String("name", node.Name),
 		zap.String("type", string(node.GetType())),
 		zap.String("nquads", nquads.String()))
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -466,6 +291,9 @@ func (c *Client) CreateNode(ctx context.Context, node *Node) (string, error) {
 	return "", fmt.Errorf("no UID returned for node '%s'", node.Name)
 }
 
+// ErrNodeNotFound is returned by GetNode for a uid that holds no node
+var ErrNodeNotFound = errors.New("node not found")
+
 // GetNode retrieves a node by UID
 func (c *Client) GetNode(ctx context.Context, uid string) (*Node, error) {
 	query := `query Node($uid: string) {
@@ -474,8 +302,9 @@ func (c *Client) GetNode(ctx context.Context, uid string) (*Node, error) {
 			dgraph.type
 			name
 			description
+			content_type
 			namespace
-			attributes
+			has_attribute { attr_key attr_value }
 			tags
 			created_at
 			updated_at
@@ -484,11 +313,14 @@ func (c *Client) GetNode(ctx context.Context, uid string) (*Node, error) {
 			access_count
 			source_conversation_id
 			confidence
+			valid_from
+			valid_until
+			status
 		}
 	}`
 
 	vars := map[string]string{"$uid": uid}
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query node: %w", err)
 	}
@@ -501,7 +333,7 @@ func (c *Client) GetNode(ctx context.Context, uid string) (*Node, error) {
 	}
 
 	if len(result.Node) == 0 {
-		return nil, fmt.Errorf("node not found: %s", uid)
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, uid)
 	}
 
 	return &result.Node[0], nil
@@ -509,7 +341,7 @@ func (c *Client) GetNode(ctx context.Context, uid string) (*Node, error) {
 
 // UpdateNodeActivation updates a node's activation level
 func (c *Client) UpdateNodeActivation(ctx context.Context, uid string, activation float64) error {
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	update := map[string]interface{}{
@@ -556,7 +388,7 @@ func (c *Client) IncrementAccessCount(ctx context.Context, uid string, config Ac
 		}
 		newAccessCount := node.AccessCount + 1
 
-		txn := c.dg.NewTxn()
+		txn := c.dgraph().NewTxn()
 		defer txn.Discard(ctx)
 
 		// Use conditional mutation to ensure we're updating the expected version
@@ -604,27 +436,235 @@ func (c *Client) IncrementAccessCount(ctx context.Context, uid string, config Ac
 
 // AddTags appends new tags to an existing node
 func (c *Client) AddTags(ctx context.Context, uid string, tags []string) error {
-	if len(tags) == 0 {
-		return nil
+	return c.AddTagsBulk(ctx, []string{uid}, tags)
+}
+
+// CreateInsight persists a synthesized Insight with its summary, type, action
+// suggestion and source_nodes links (plus synthesized_from edges for traversal)
+func (c *Client) CreateInsight(ctx context.Context, insight *Insight) (string, error) {
+	if insight.Namespace == "" {
+		return "", fmt.Errorf("insight namespace is required")
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	activation := insight.Activation
+	if activation == 0 {
+		activation = 0.8 // New insights start with high activation
 	}
 
+	blankNode := newBlankNode("insight")
 	var nquads strings.Builder
-	for _, tag := range tags {
-		nquads.WriteString(fmt.Sprintf(`<%s> <tags> %q .
-`, uid, tag))
+	nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> %s .
+`, blankNode, escapeRDFString(string(NodeTypeInsight))))
+	nquads.WriteString(fmt.Sprintf(`%s <name> %s .
+`, blankNode, escapeRDFString(insight.Name)))
+	nquads.WriteString(fmt.Sprintf(`%s <namespace> %s .
+`, blankNode, escapeRDFString(insight.Namespace)))
+	nquads.WriteString(fmt.Sprintf(`%s <description> %s .
+`, blankNode, escapeRDFString(insight.Summary)))
+	nquads.WriteString(fmt.Sprintf(`%s <summary> %s .
+`, blankNode, escapeRDFString(insight.Summary)))
+	writeSearchTermsNquad(&nquads, blankNode, insight.Name, insight.Summary)
+	if insight.InsightType != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <insight_type> %s .
+`, blankNode, escapeRDFString(insight.InsightType)))
+	}
+	if insight.ActionSuggestion != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <action_suggestion> %s .
+`, blankNode, escapeRDFString(insight.ActionSuggestion)))
+	}
+	nquads.WriteString(fmt.Sprintf(`%s <activation> "%f"^^<xs:double> .
+`, blankNode, activation))
+	nquads.WriteString(fmt.Sprintf(`%s <confidence> "%f"^^<xs:double> .
+`, blankNode, insight.Confidence))
+	nquads.WriteString(fmt.Sprintf(`%s <created_at> "%s"^^<xs:dateTime> .
+`, blankNode, now))
+	for _, sourceUID := range insight.SourceNodeUIDs {
+		nquads.WriteString(fmt.Sprintf(`%s <source_nodes> <%s> .
+`, blankNode, sourceUID))
+		nquads.WriteString(fmt.Sprintf(`%s <synthesized_from> <%s> .
+`, blankNode, sourceUID))
 	}
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
-	mu := &api.Mutation{
+	resp, err := txn.Mutate(ctx, &api.Mutation{
 		SetNquads: []byte(nquads.String()),
 		CommitNow: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create insight: %w", err)
 	}
 
-	_, err := txn.Mutate(ctx, mu)
+	uid, ok := resp.Uids[blankNode[2:]]
+	if !ok {
+		return "", fmt.Errorf("no UID returned for insight '%s'", insight.Name)
+	}
+	return uid, nil
+}
+
+// UpsertPattern creates a Pattern node, or refreshes frequency, confidence, predicted
+// action and trigger_nodes on the existing pattern with the same name and namespace.
+// Returns the pattern UID.
+func (c *Client) UpsertPattern(ctx context.Context, pattern *Pattern) (string, error) {
+	existing, err := c.FindNodeByName(ctx, pattern.Namespace, pattern.Name, NodeTypePattern)
 	if err != nil {
-		return fmt.Errorf("failed to add tags: %w", err)
+		return "", err
+	}
+	existingUID := ""
+	if existing != nil {
+		existingUID = existing.UID
+	}
+	return writePattern(ctx, c.dgraph().NewTxn(), existingUID, pattern)
+}
+
+// writePattern writes pattern inside txn, as a new node or over existingUID.
+// An existing pattern's trigger_nodes are replaced by the pattern's, so they
+// track the latest mining rather than growing every cycle.
+func writePattern(ctx context.Context, txn accessTxn, existingUID string, pattern *Pattern) (string, error) {
+	defer txn.Discard(ctx)
+
+	subject := newBlankNode("pattern")
+	if existingUID != "" {
+		subject = fmt.Sprintf("<%s>", existingUID)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	var nquads strings.Builder
+	if existingUID == "" {
+		nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> %s .
+`, subject, escapeRDFString(string(NodeTypePattern))))
+		nquads.WriteString(fmt.Sprintf(`%s <name> %s .
+`, subject, escapeRDFString(pattern.Name)))
+		if pattern.Namespace != "" {
+			nquads.WriteString(fmt.Sprintf(`%s <namespace> %s .
+`, subject, escapeRDFString(pattern.Namespace)))
+		}
+		nquads.WriteString(fmt.Sprintf(`%s <created_at> "%s"^^<xs:dateTime> .
+`, subject, now))
+	}
+	nquads.WriteString(fmt.Sprintf(`%s <updated_at> "%s"^^<xs:dateTime> .
+`, subject, now))
+	if pattern.PatternType != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <pattern_type> %s .
+`, subject, escapeRDFString(pattern.PatternType)))
+	}
+	if pattern.PredictedAction != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <predicted_action> %s .
+`, subject, escapeRDFString(pattern.PredictedAction)))
+		nquads.WriteString(fmt.Sprintf(`%s <description> %s .
+`, subject, escapeRDFString(pattern.PredictedAction)))
+		writeSearchTermsNquad(&nquads, subject, pattern.Name, pattern.PredictedAction)
+	}
+	nquads.WriteString(fmt.Sprintf(`%s <frequency> "%d"^^<xs:int> .
+`, subject, pattern.Frequency))
+	nquads.WriteString(fmt.Sprintf(`%s <confidence_score> "%f"^^<xs:double> .
+`, subject, pattern.ConfidenceScore))
+	nquads.WriteString(fmt.Sprintf(`%s <confidence> "%f"^^<xs:double> .
+`, subject, pattern.ConfidenceScore))
+	nquads.WriteString(fmt.Sprintf(`%s <activation> "%f"^^<xs:double> .
+`, subject, pattern.ConfidenceScore))
+	seen := make(map[string]bool, len(pattern.TriggerNodes))
+	for _, triggerUID := range pattern.TriggerNodes {
+		if triggerUID == "" || seen[triggerUID] {
+			continue
+		}
+		seen[triggerUID] = true
+		nquads.WriteString(fmt.Sprintf(`%s <trigger_nodes> <%s> .
+`, subject, triggerUID))
+	}
+
+	if existingUID != "" {
+		if _, err := txn.Mutate(ctx, &api.Mutation{
+			DelNquads: []byte(fmt.Sprintf("%s <trigger_nodes> * .\n", subject)),
+		}); err != nil {
+			return "", fmt.Errorf("failed to clear pattern triggers: %w", err)
+		}
+	}
+	resp, err := txn.Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(nquads.String()),
+		CommitNow: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upsert pattern: %w", err)
+	}
+
+	if existingUID != "" {
+		return existingUID, nil
+	}
+	uid, ok := resp.Uids[subject[2:]]
+	if !ok {
+		return "", fmt.Errorf("no UID returned for pattern '%s'", pattern.Name)
+	}
+	return uid, nil
+}
+
+// writeValidityNquads appends a node's temporal validity predicates to a mutation
+func writeValidityNquads(nquads *strings.Builder, subject string, node *Node) {
+	if node.ValidFrom != nil {
+		nquads.WriteString(fmt.Sprintf(`%s <valid_from> "%s"^^<xs:dateTime> .
+`, subject, node.ValidFrom.UTC().Format(time.RFC3339)))
+	}
+	if node.ValidUntil != nil {
+		nquads.WriteString(fmt.Sprintf(`%s <valid_until> "%s"^^<xs:dateTime> .
+`, subject, node.ValidUntil.UTC().Format(time.RFC3339)))
+	}
+	if node.Status != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <status> %s .
+`, subject, escapeRDFString(node.Status)))
+	}
+}
+
+// writeSearchTermsNquad indexes a node's name and description as the
+// normalized terms SearchByTerms matches keyword queries against
+func writeSearchTermsNquad(nquads *strings.Builder, subject, name, description string) {
+	if terms := textnorm.Default.Normalize(name + " " + description); terms != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <search_terms> %s .
+`, subject, escapeRDFString(terms)))
+	}
+}
+
+// SetFactValidity closes a fact's validity window at validUntil and records its status
+// (e.g. FactStatusSuperseded when curation archives it in favour of a newer fact)
+func (c *Client) SetFactValidity(ctx context.Context, uid string, validUntil time.Time, status string) error {
+	var nquads strings.Builder
+	subject := fmt.Sprintf("<%s>", uid)
+	writeValidityNquads(&nquads, subject, &Node{ValidUntil: &validUntil, Status: status})
+	nquads.WriteString(fmt.Sprintf(`%s <updated_at> "%s"^^<xs:dateTime> .
+`, subject, time.Now().UTC().Format(time.RFC3339)))
+
+	txn := c.dgraph().NewTxn()
+	defer txn.Discard(ctx)
+
+	mu := &api.Mutation{
+		SetNquads: []byte(nquads.String()),
+		CommitNow: true,
+	}
+
+	if _, err := txn.Mutate(ctx, mu); err != nil {
+		return fmt.Errorf("failed to set fact validity: %w", err)
+	}
+	return nil
+}
+
+// ArchiveNode marks a node archived so maintenance can retire it without losing data
+func (c *Client) ArchiveNode(ctx context.Context, uid string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	nquads := fmt.Sprintf(`<%s> <status> %s .
+<%s> <updated_at> "%s"^^<xs:dateTime> .
+`, uid, escapeRDFString(NodeStatusArchived), uid, now)
+
+	txn := c.dgraph().NewTxn()
+	defer txn.Discard(ctx)
+
+	mu := &api.Mutation{
+		SetNquads: []byte(nquads),
+		CommitNow: true,
+	}
+
+	if _, err := txn.Mutate(ctx, mu); err != nil {
+		return fmt.Errorf("failed to archive node: %w", err)
 	}
 	return nil
 }
@@ -635,17 +675,45 @@ func (c *Client) UpdateDescription(ctx context.Context, uid string, description
 		return nil
 	}
 
-	nquad := fmt.Sprintf(`<%s> <description> %q .`, uid, description)
-
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
+	// The search terms cover the name too, so read it in the same transaction
+	resp, err := txn.QueryWithVars(ctx, `query Name($uid: string) {
+		node(func: uid($uid)) { name }
+	}`, map[string]string{"$uid": uid})
+	if err != nil {
+		return fmt.Errorf("failed to read node name: %w", err)
+	}
+	var result struct {
+		Node []struct {
+			Name string `json:"name"`
+		} `json:"node"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return fmt.Errorf("failed to unmarshal: %w", err)
+	}
+	name := ""
+	if len(result.Node) > 0 {
+		name = result.Node[0].Name
+	}
+
+	var nquads strings.Builder
+	nquads.WriteString(fmt.Sprintf(`<%s> <description> %s .
+`, uid, escapeRDFString(description)))
+	before := nquads.Len()
+	writeSearchTermsNquad(&nquads, "<"+uid+">", name, description)
+
 	mu := &api.Mutation{
-		SetNquads: []byte(nquad),
+		SetNquads: []byte(nquads.String()),
 		CommitNow: true,
 	}
+	if nquads.Len() == before {
+		// Nothing left to index, so drop the terms of the old description
+		mu.DelNquads = []byte(fmt.Sprintf("<%s> <search_terms> * .\n", uid))
+	}
 
-	_, err := txn.Mutate(ctx, mu)
+	_, err = txn.Mutate(ctx, mu)
 	if err != nil {
 		return fmt.Errorf("failed to update description: %w", err)
 	}
@@ -660,7 +728,7 @@ func (c *Client) FindNodeByName(ctx context.Context, namespace string, name stri
 			dgraph.type
 			name
 			description
-			attributes
+			has_attribute { attr_key attr_value }
 			created_at
 			updated_at
 			last_accessed
@@ -673,7 +741,7 @@ func (c *Client) FindNodeByName(ctx context.Context, namespace string, name stri
 		"$name":      name,
 		"$namespace": namespace,
 	}
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query node: %w", err)
 	}
@@ -692,16 +760,92 @@ func (c *Client) FindNodeByName(ctx context.Context, namespace string, name stri
 	return &result.Node[0], nil
 }
 
-// SearchNodes searches for nodes matching a query string (fuzzy search)
+// ExistingNames reports which of names are taken by nodes of nodeType in the
+// namespace, looking them all up in one query
+func (c *Client) ExistingNames(ctx context.Context, namespace string, names []string, nodeType NodeType) (map[string]bool, error) {
+	return existingNames(ctx, c.dgraph().NewReadOnlyTxn(), namespace, names, nodeType)
+}
+
+// existingNames is ExistingNames inside txn: one query block per distinct name
+func existingNames(ctx context.Context, txn accessTxn, namespace string, names []string, nodeType NodeType) (map[string]bool, error) {
+	found := make(map[string]bool)
+	vars := map[string]string{"$namespace": namespace}
+	params := []string{"$namespace: string"}
+	var blocks strings.Builder
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		v := fmt.Sprintf("$n%d", len(seen)-1)
+		vars[v] = name
+		params = append(params, v+": string")
+		fmt.Fprintf(&blocks, `
+		n%d(func: eq(name, %s)) @filter(type(%s) AND eq(namespace, $namespace)) {
+			name
+		}`, len(seen)-1, v, nodeType)
+	}
+	if len(seen) == 0 {
+		return found, nil
+	}
+	defer txn.Discard(ctx)
+
+	query := fmt.Sprintf("query ExistingNames(%s) {%s\n\t}", strings.Join(params, ", "), blocks.String())
+	resp, err := txn.QueryWithVars(ctx, query, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query names: %w", err)
+	}
+	var result map[string][]struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal names: %w", err)
+	}
+	for _, nodes := range result {
+		for _, node := range nodes {
+			found[node.Name] = true
+		}
+	}
+	return found, nil
+}
+
+// SearchAllLimit caps the nodes a list-all SearchNodes returns
+const SearchAllLimit = 1000
+
+// nodeQuerier is the part of a DGraph transaction a search uses
+type nodeQuerier interface {
+	QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error)
+}
+
+// isSearchAll reports whether a search query asks for every node rather than
+// a keyword match
+func isSearchAll(queryStr string) bool {
+	queryStr = strings.TrimSpace(queryStr)
+	return queryStr == "" || queryStr == "*"
+}
+
+// SearchNodes searches for nodes matching a query string (fuzzy search). An
+// empty or "*" query lists the namespace's named nodes instead, up to
+// SearchAllLimit.
 // SECURITY: Requires namespace parameter to prevent cross-tenant data access
 func (c *Client) SearchNodes(ctx context.Context, queryStr, namespace string) ([]Node, error) {
+	return searchNodes(ctx, c.dgraph().NewReadOnlyTxn(), queryStr, namespace)
+}
+
+// searchNodes runs SearchNodes in txn
+func searchNodes(ctx context.Context, txn nodeQuerier, queryStr, namespace string) ([]Node, error) {
+	if isSearchAll(queryStr) {
+		return listNamedNodes(ctx, txn, namespace)
+	}
+
 	query := `query SearchNodes($term: string, $namespace: string) {
 		nodes(func: anyoftext(name, $term)) @filter(eq(namespace, $namespace)) {
 			uid
 			dgraph.type
 			name
 			description
-			attributes
+			has_attribute { attr_key attr_value }
 			created_at
 			updated_at
 			activation
@@ -713,7 +857,7 @@ func (c *Client) SearchNodes(ctx context.Context, queryStr, namespace string) ([
 			dgraph.type
 			name
 			description
-			attributes
+			has_attribute { attr_key attr_value }
 			created_at
 			updated_at
 			activation
@@ -726,7 +870,7 @@ func (c *Client) SearchNodes(ctx context.Context, queryStr, namespace string) ([
 		"$term":      queryStr,
 		"$namespace": namespace,
 	}
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	resp, err := txn.QueryWithVars(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search nodes: %w", err)
 	}
@@ -759,6 +903,38 @@ func (c *Client) SearchNodes(ctx context.Context, queryStr, namespace string) ([
 	return merged, nil
 }
 
+// listNamedNodes returns up to SearchAllLimit of a namespace's named nodes,
+// most active first
+func listNamedNodes(ctx context.Context, txn nodeQuerier, namespace string) ([]Node, error) {
+	query := fmt.Sprintf(`query ListNamedNodes($namespace: string) {
+		nodes(func: has(name), orderdesc: activation, first: %d) @filter(eq(namespace, $namespace)) {
+			uid
+			dgraph.type
+			name
+			description
+			has_attribute { attr_key attr_value }
+			created_at
+			updated_at
+			activation
+			namespace
+			entity_type
+		}
+	}`, SearchAllLimit)
+
+	resp, err := txn.QueryWithVars(ctx, query, map[string]string{"$namespace": namespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal nodes: %w", err)
+	}
+	return result.Nodes, nil
+}
+
 // GetNodesByNames fetches multiple nodes by name in a single query, scoped to namespace
 func (c *Client) GetNodesByNames(ctx context.Context, namespace string, names []string) (map[string]*Node, error) {
 	if len(names) == 0 {
@@ -778,13 +954,13 @@ func (c *Client) GetNodesByNames(ctx context.Context, namespace string, names []
 			dgraph.type
 			name
 			description
-			attributes
+			has_attribute { attr_key attr_value }
 			created_at
 			activation
 			namespace
 		}
 	}`, filterStr)
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$namespace": namespace})
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$namespace": namespace})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query nodes batch: %w", err)
 	}
@@ -812,7 +988,7 @@ func (c *Client) GetNodesByNames(ctx context.Context, namespace string, names []
 
 // Query executes a DGraph query with optional variables
 func (c *Client) Query(ctx context.Context, query string, vars map[string]string) ([]byte, error) {
-	txn := c.dg.NewReadOnlyTxn()
+	txn := c.dgraph().NewReadOnlyTxn()
 	var resp *api.Response
 	var err error
 
@@ -830,8 +1006,22 @@ func (c *Client) Query(ctx context.Context, query string, vars map[string]string
 
 // CreateEdge creates a relationship between two nodes
 func (c *Client) CreateEdge(ctx context.Context, fromUID, toUID string, edgeType EdgeType, status EdgeStatus) error {
+	if err := ValidateEdgeType(edgeType); err != nil {
+		return err
+	}
 	predicateName := edgeTypeToPredicateName(edgeType)
 
+	// An edge that already exists is left alone, keeping its facets. This must
+	// run before archiving, which would otherwise drop the edge being re-created.
+	key := edgeKey{fromUID, predicateName, toUID}
+	existing, err := c.existingEdges(ctx, []edgeKey{key})
+	if err != nil {
+		return err
+	}
+	if _, ok := existing[key]; ok {
+		return nil
+	}
+
 	// Check for functional constraint
 	if FunctionalEdges[edgeType] {
 		if err := c.archiveExistingFunctionalEdge(ctx, fromUID, edgeType); err != nil {
@@ -839,18 +1029,17 @@ func (c *Client) CreateEdge(ctx context.Context, fromUID, toUID string, edgeType
 		}
 	}
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
-	nquad := fmt.Sprintf(`<%s> <%s> <%s> .`, fromUID, predicateName, toUID)
+	nquad := fmt.Sprintf(`<%s> %s <%s> (created_at=%s) .`, fromUID, escapeRDFPredicate(predicateName), toUID, edgeTimestamp())
 
 	mu := &api.Mutation{
 		SetNquads: []byte(nquad),
 		CommitNow: true,
 	}
 
-	_, err := txn.Mutate(ctx, mu)
-	if err != nil {
+	if _, err := txn.Mutate(ctx, mu); err != nil {
 		return fmt.Errorf("failed to create edge: %w", err)
 	}
 
@@ -876,7 +1065,7 @@ func (c *Client) archiveExistingFunctionalEdge(ctx context.Context, fromUID stri
 	}`, predicateName)
 
 	vars := map[string]string{"$uid": fromUID}
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
 	if err != nil {
 		return err
 	}
@@ -888,7 +1077,7 @@ func (c *Client) archiveExistingFunctionalEdge(ctx context.Context, fromUID stri
 
 	// Delete existing edges if found
 	if nodes, ok := result["node"]; ok && len(nodes) > 0 {
-		txn := c.dg.NewTxn()
+		txn := c.dgraph().NewTxn()
 		defer txn.Discard(ctx)
 
 		for _, existing := range nodes {
@@ -896,7 +1085,7 @@ func (c *Client) archiveExistingFunctionalEdge(ctx context.Context, fromUID stri
 				for _, edge := range edges {
 					if edgeMap, ok := edge.(map[string]interface{}); ok {
 						if existingUID, ok := edgeMap["uid"].(string); ok {
-							nquad := fmt.Sprintf(`<%s> <%s> <%s> .`, fromUID, predicateName, existingUID)
+							nquad := fmt.Sprintf(`<%s> %s <%s> .`, fromUID, escapeRDFPredicate(predicateName), existingUID)
 							mu := &api.Mutation{
 								DelNquads: []byte(nquad),
 								CommitNow: true,
@@ -927,56 +1116,14 @@ func (c *Client) CreateNodes(ctx context.Context, nodes []*Node) (map[string]str
 	// Map from temporary ID to node Name to resolve UIDs later
 	tempIDToName := make(map[string]string)
 
-	for i, node := range nodes {
-		node.CreatedAt = time.Now()
-		node.UpdatedAt = time.Now()
-		node.LastAccessed = time.Now()
-		if node.Activation == 0 {
-			node.Activation = 0.5
-		}
-
+	for _, node := range nodes {
 		// Use a unique blank node for this batch
-		blankNode := fmt.Sprintf("_:node_%d_%d", time.Now().UnixNano(), i)
+		blankNode := newBlankNode("node")
 		tempIDToName[blankNode[2:]] = node.Name // Store without "_:"
-
-		// Type
-		for _, dtype := range node.DType {
-			nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "%s" .
-`, blankNode, dtype))
-		}
-
-		// Name
-		nquads.WriteString(fmt.Sprintf(`%s <name> %q .
-`, blankNode, node.Name))
-
-		// Namespace
-		if node.Namespace != "" {
-			nquads.WriteString(fmt.Sprintf(`%s <namespace> %q .
-`, blankNode, node.Namespace))
-		}
-
-		// Metadata
-		nquads.WriteString(fmt.Sprintf(`%s <activation> "%f"^^<xs:double> .
-`, blankNode, node.Activation))
-		nquads.WriteString(fmt.Sprintf(`%s <confidence> "%f"^^<xs:double> .
-`, blankNode, node.Confidence))
-		nquads.WriteString(fmt.Sprintf(`%s <created_at> "%s"^^<xs:dateTime> .
-`, blankNode, node.CreatedAt.Format(time.RFC3339)))
-
-		// Description
-		if node.Description != "" {
-			nquads.WriteString(fmt.Sprintf(`%s <description> %q .
-`, blankNode, node.Description))
-		}
-
-		// Tags
-		for _, tag := range node.Tags {
-			nquads.WriteString(fmt.Sprintf(`%s <tags> %q .
-`, blankNode, tag))
-		}
+		writeNodeNquads(&nquads, blankNode, node)
 	}
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -1027,6 +1174,61 @@ func (c *Client) CreateNodes(ctx context.Context, nodes []*Node) (map[string]str
 	return nameToUID, nil
 }
 
+// writeNodeNquads writes the N-Quads creating node under the given blank node,
+// stamping its timestamps and default activation
+func writeNodeNquads(nquads *strings.Builder, blankNode string, node *Node) {
+	node.CreatedAt = time.Now()
+	node.UpdatedAt = time.Now()
+	node.LastAccessed = time.Now()
+	if node.Activation == 0 {
+		node.Activation = 0.5
+	}
+
+	// Type
+	for _, dtype := range node.DType {
+		nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> %s .
+`, blankNode, escapeRDFString(dtype)))
+	}
+
+	// Name
+	nquads.WriteString(fmt.Sprintf(`%s <name> %s .
+`, blankNode, escapeRDFString(node.Name)))
+
+	// Namespace
+	if node.Namespace != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <namespace> %s .
+`, blankNode, escapeRDFString(node.Namespace)))
+	}
+
+	// Metadata
+	nquads.WriteString(fmt.Sprintf(`%s <activation> "%f"^^<xs:double> .
+`, blankNode, node.Activation))
+	nquads.WriteString(fmt.Sprintf(`%s <confidence> "%f"^^<xs:double> .
+`, blankNode, node.Confidence))
+	nquads.WriteString(fmt.Sprintf(`%s <created_at> "%s"^^<xs:dateTime> .
+`, blankNode, node.CreatedAt.Format(time.RFC3339)))
+
+	// Description
+	if node.Description != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <description> %s .
+`, blankNode, escapeRDFString(node.Description)))
+	}
+	if node.ContentType != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <content_type> %s .
+`, blankNode, escapeRDFString(node.ContentType)))
+	}
+
+	// Tags
+	for _, tag := range node.Tags {
+		nquads.WriteString(fmt.Sprintf(`%s <tags> %s .
+`, blankNode, escapeRDFString(tag)))
+	}
+
+	writeValidityNquads(nquads, blankNode, node)
+	writeAttributeNquads(nquads, blankNode, node.Attributes)
+	writeSearchTermsNquad(nquads, blankNode, node.Name, node.Description)
+}
+
 // EdgeInput represents a single edge to be created in a batch
 type EdgeInput struct {
 	FromUID string
@@ -1034,98 +1236,201 @@ type EdgeInput struct {
 	Type    EdgeType
 	Status  EdgeStatus
 	Weight  float64
+	// NeedsReview marks an edge whose type was inferred with low confidence
+	NeedsReview bool
+}
+
+// CreateEdges batch creates multiple edges in a single mutation. Repeats of an
+// edge, within the batch or of one already stored, keep a single edge with the
+// highest weight and the original created_at.
+func (c *Client) CreateEdges(ctx context.Context, edges []EdgeInput) error {
+	if len(edges) == 0 {
+		return nil
+	}
+
+	keys := make([]edgeKey, len(edges))
+	for i, edge := range edges {
+		if err := ValidateEdgeType(edge.Type); err != nil {
+			return err
+		}
+		keys[i] = edgeKey{edge.FromUID, edgeTypeToPredicateName(edge.Type), edge.ToUID}
+	}
+	existing, err := c.existingEdges(ctx, keys)
+	if err != nil {
+		return err
+	}
+	writes := mergeEdgeWrites(edges, existing, edgeTimestamp())
+	if len(writes) == 0 {
+		return nil
+	}
+
+	var nquads strings.Builder
+	for _, w := range writes {
+		nquads.WriteString(fmt.Sprintf(`<%s> %s <%s> (%s) .
+`, w.key.from, escapeRDFPredicate(w.key.predicate), w.key.to, w.facets()))
+	}
+
+	txn := c.dgraph().NewTxn()
+	defer txn.Discard(ctx)
+
+	mu := &api.Mutation{
+		SetNquads: []byte(nquads.String()),
+		CommitNow: true,
+	}
+
+	_, err = txn.Mutate(ctx, mu)
+	if err != nil {
+		return fmt.Errorf("batch create edges failed: %w", err)
+	}
+
+	return nil
 }
 
-// CreateEdges batch creates multiple edges in a single mutation
-func (c *Client) CreateEdges(ctx context.Context, edges []EdgeInput) error {
-	if len(edges) == 0 {
-		return nil
+// IngestEdge is an edge in an IngestGraph batch. Each endpoint is either the
+// UID of an existing node or the Name of a node created in the same batch.
+type IngestEdge struct {
+	FromUID     string
+	FromName    string
+	ToUID       string
+	ToName      string
+	Type        EdgeType
+	Weight      float64
+	NeedsReview bool // Type was inferred with low confidence
+}
+
+// IngestGraph creates nodes and the edges between them in a single committed
+// mutation, so a failure leaves neither behind. Edge endpoints given by name are
+// wired to the batch's blank nodes; nodes sharing a name are created once.
+// Edges between existing nodes merge with stored ones as in CreateEdges.
+// It returns the UIDs of the created nodes by name.
+func (c *Client) IngestGraph(ctx context.Context, nodes []*Node, edges []IngestEdge) (map[string]string, error) {
+	if len(nodes) == 0 && len(edges) == 0 {
+		return nil, nil
+	}
+	for _, edge := range edges {
+		if err := ValidateEdgeType(edge.Type); err != nil {
+			return nil, err
+		}
 	}
 
 	var nquads strings.Builder
-	for _, edge := range edges {
-		predicateName := edgeTypeToPredicateName(edge.Type)
-		// Default weight to 0.5 if not set
-		weight := edge.Weight
-		if weight == 0 {
-			weight = 0.5
+	nameToBlank := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if _, dup := nameToBlank[node.Name]; dup {
+			continue
+		}
+		blankNode := newBlankNode("node")
+		nameToBlank[node.Name] = blankNode
+		writeNodeNquads(&nquads, blankNode, node)
+	}
+
+	// Endpoints are UIDs or the batch's blank nodes; only the former can
+	// already be stored, so only they are looked up
+	endpoint := func(uid, name string) (string, error) {
+		if uid != "" {
+			return uid, nil
+		}
+		if blankNode, ok := nameToBlank[name]; ok {
+			return blankNode, nil
+		}
+		return "", fmt.Errorf("edge endpoint %q is neither a UID nor a node in the batch", name)
+	}
+	inputs := make([]EdgeInput, len(edges))
+	keys := make([]edgeKey, len(edges))
+	for i, edge := range edges {
+		from, err := endpoint(edge.FromUID, edge.FromName)
+		if err != nil {
+			return nil, err
+		}
+		to, err := endpoint(edge.ToUID, edge.ToName)
+		if err != nil {
+			return nil, err
+		}
+		inputs[i] = EdgeInput{FromUID: from, ToUID: to, Type: edge.Type, Weight: edge.Weight, NeedsReview: edge.NeedsReview}
+		keys[i] = edgeKey{from, edgeTypeToPredicateName(edge.Type), to}
+	}
+	existing, err := c.existingEdges(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	term := func(id string) string {
+		if strings.HasPrefix(id, "_:") {
+			return id
 		}
-		nquads.WriteString(fmt.Sprintf(`<%s> <%s> <%s> (weight=%f) .
-`, edge.FromUID, predicateName, edge.ToUID, weight))
+		return "<" + id + ">"
+	}
+	for _, w := range mergeEdgeWrites(inputs, existing, edgeTimestamp()) {
+		nquads.WriteString(fmt.Sprintf(`%s %s %s (%s) .
+`, term(w.key.from), escapeRDFPredicate(w.key.predicate), term(w.key.to), w.facets()))
+	}
+	if nquads.Len() == 0 {
+		return nil, nil
 	}
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
-	mu := &api.Mutation{
+	resp, err := txn.Mutate(ctx, &api.Mutation{
 		SetNquads: []byte(nquads.String()),
 		CommitNow: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ingest graph failed: %w", err)
 	}
 
-	_, err := txn.Mutate(ctx, mu)
-	if err != nil {
-		return fmt.Errorf("batch create edges failed: %w", err)
+	nameToUID := make(map[string]string, len(nameToBlank))
+	for name, blankNode := range nameToBlank {
+		if uid, ok := resp.Uids[blankNode[2:]]; ok {
+			nameToUID[name] = uid
+		}
 	}
+	return nameToUID, nil
+}
 
-	return nil
+// edgeTimestamp formats the created_at edge facet, which RecentChanges filters on
+func edgeTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
 }
 
-// edgeTypeToPredicateName converts EdgeType to DGraph predicate name
+// edgeTypeToPredicateName converts EdgeType to DGraph predicate name. Unknown
+// types pass through unchanged for queries; writers reject them first with
+// ValidateEdgeType.
 func edgeTypeToPredicateName(edgeType EdgeType) string {
-	mapping := map[EdgeType]string{
-		EdgeTypePartnerIs:    "partner_is",
-		EdgeTypeFamilyMember: "family_member",
-		EdgeTypeFriendOf:     "friend_of",
-		EdgeTypeHasManager:   "has_manager",
-		EdgeTypeWorksOn:      "works_on",
-		EdgeTypeWorksAt:      "works_at",
-		EdgeTypeColleague:    "colleague",
-		EdgeTypeLikes:        "likes",
-		EdgeTypeDislikes:     "dislikes",
-		EdgeTypeIsAllergic:   "is_allergic_to",
-		EdgeTypePrefers:      "prefers",
-		EdgeTypeHasInterest:  "has_interest",
-		EdgeTypeCausedBy:     "caused_by",
-		EdgeTypeBlockedBy:    "blocked_by",
-		EdgeTypeResultsIn:    "results_in",
-		EdgeTypeContradicts:  "contradicts",
-		EdgeTypeOccurredOn:   "occurred_on",
-		EdgeTypeDerivedFrom:  "derived_from",
-		EdgeTypeSynthesized:  "synthesized_from",
-		EdgeTypeSupersedes:   "supersedes",
-		EdgeTypeKnows:        "knows",
-	}
-
-	if pred, ok := mapping[edgeType]; ok {
-		return pred
+	if info, ok := lookupEdgeType(edgeType); ok {
+		return info.Predicate
 	}
 	return string(edgeType)
 }
 
-// GetNodesByUIDs fetches multiple nodes by their UIDs in a single query
-// Used by Hybrid RAG to retrieve full node data after vector search
-func (c *Client) GetNodesByUIDs(ctx context.Context, uids []string) ([]Node, error) {
-	if len(uids) == 0 {
+// GetNodesByUIDs fetches multiple nodes by their UIDs in a single query,
+// returning only the selected fields (see the NodeFields projections; nil
+// selects NodeFieldsDefault). Used by Hybrid RAG to hydrate vector search hits.
+func (c *Client) GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]Node, error) {
+	selection, err := nodeFieldSelection(fields)
+	if err != nil {
+		return nil, err
+	}
+	// Non-UID ids (vector chunk ids, synthetic chat ids) can't name a node
+	valid := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if uidPattern.MatchString(uid) {
+			valid = append(valid, uid)
+		}
+	}
+	if len(valid) == 0 {
 		return nil, nil
 	}
 
 	// Build UID list for query
-	uidList := strings.Join(uids, ",")
+	uidList := strings.Join(valid, ",")
 
 	query := fmt.Sprintf(`{
 		nodes(func: uid(%s)) {
-			uid
-			dgraph.type
-			name
-			description
-			tags
-			activation
-			created_at
-			namespace
+			%s
 		}
-	}`, uidList)
+	}`, uidList, selection)
 
-	resp, err := c.dg.NewReadOnlyTxn().Query(ctx, query)
+	resp, err := c.dgraph().NewReadOnlyTxn().Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nodes by UIDs: %w", err)
 	}
@@ -1142,7 +1447,7 @@ func (c *Client) GetNodesByUIDs(ctx context.Context, uids []string) ([]Node, err
 
 // Mutate executes a raw DGraph mutation
 func (c *Client) Mutate(ctx context.Context, mutation *api.Mutation) (*api.Response, error) {
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 	return txn.Mutate(ctx, mutation)
 }
@@ -1151,7 +1456,7 @@ func (c *Client) Mutate(ctx context.Context, mutation *api.Mutation) (*api.Respo
 func (c *Client) EnsureUserNode(ctx context.Context, username, role string) error {
 	// Check if user already exists
 	// User node lives in its own "user_<username>" namespace
-	ns := fmt.Sprintf("user_%s", username)
+	ns := nsutil.ForUser(username)
 	existing, err := c.FindNodeByName(ctx, ns, username, NodeTypeUser)
 	if err != nil {
 		return fmt.Errorf("failed to check user existence: %w", err)
@@ -1164,15 +1469,15 @@ func (c *Client) EnsureUserNode(ctx context.Context, username, role string) erro
 	now := time.Now().Format(time.RFC3339)
 	nquads := fmt.Sprintf(`
 		_:user <dgraph.type> "User" .
-		_:user <name> %q .
-		_:user <namespace> %q .
-		_:user <role> %q .
-		_:user <created_at> %q .
-		_:user <updated_at> %q .
+		_:user <name> %s .
+		_:user <namespace> %s .
+		_:user <role> %s .
+		_:user <created_at> %s .
+		_:user <updated_at> %s .
 		_:user <activation> "%f"^^<xs:double> .
-	`, username, fmt.Sprintf("user_%s", username), role, now, now, 0.5)
+	`, escapeRDFString(username), escapeRDFString(nsutil.ForUser(username)), escapeRDFString(role), escapeRDFString(now), escapeRDFString(now), 0.5)
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -1192,7 +1497,7 @@ func (c *Client) EnsureUserNode(ctx context.Context, username, role string) erro
 // CreateGroup creates a new group (V2) with strict namespace isolation and admin hierarchy
 func (c *Client) CreateGroup(ctx context.Context, name, description, ownerID string) (string, error) {
 	// Find owner user
-	ownerNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", ownerID), ownerID, NodeTypeUser)
+	ownerNode, err := c.FindNodeByName(ctx, nsutil.ForUser(ownerID), ownerID, NodeTypeUser)
 	if err != nil {
 		return "", fmt.Errorf("failed to find owner: %w", err)
 	}
@@ -1201,7 +1506,7 @@ func (c *Client) CreateGroup(ctx context.Context, name, description, ownerID str
 	}
 
 	groupID := uuid.New().String()
-	namespace := fmt.Sprintf("group_%s", groupID)
+	namespace := nsutil.ForGroup(groupID)
 
 	// Create Group Node (It exists within its OWN namespace so it can be found by queries filtering for that group)
 	// WAIT: A group node itself acts as the anchor. If I put it in "group_X", then to find it I need to know "group_X".
@@ -1222,25 +1527,25 @@ func (c *Client) CreateGroup(ctx context.Context, name, description, ownerID str
 
 	nquads := fmt.Sprintf(`
 		%s <dgraph.type> "Group" .
-		%s <name> %q .
-		%s <description> %q .
-		%s <namespace> %q .
-		%s <created_at> %q .
-		%s <updated_at> %q .
+		%s <name> %s .
+		%s <description> %s .
+		%s <namespace> %s .
+		%s <created_at> %s .
+		%s <updated_at> %s .
 		
 		# Hierarchy
-		%s <group_has_admin> <%s> .
-		%s <group_has_member> <%s> .
+		%s <group_has_admin> <%s> (joined_at=%s) .
+		%s <group_has_member> <%s> (joined_at=%s) .
 	`,
-		groupUID, groupUID, name,
-		groupUID, description,
-		groupUID, namespace,
-		groupUID, now,
-		groupUID, now,
-		groupUID, ownerNode.UID,
-		groupUID, ownerNode.UID)
-
-	txn := c.dg.NewTxn()
+		groupUID, groupUID, escapeRDFString(name),
+		groupUID, escapeRDFString(description),
+		groupUID, escapeRDFString(namespace),
+		groupUID, escapeRDFString(now),
+		groupUID, escapeRDFString(now),
+		groupUID, ownerNode.UID, edgeTimestamp(),
+		groupUID, ownerNode.UID, edgeTimestamp())
+
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -1298,14 +1603,14 @@ func (c *Client) AddGroupMember(ctx context.Context, groupNamespace, username st
 	groupUID := res.G[0].UID
 
 	// Find the User
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", username), username, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(username), username, NodeTypeUser)
 	if err != nil || userNode == nil {
 		return fmt.Errorf("user %s not found", username)
 	}
 
-	nquad := fmt.Sprintf(`<%s> <group_has_member> <%s> .`, groupUID, userNode.UID)
+	nquad := fmt.Sprintf(`<%s> <group_has_member> <%s> (joined_at=%s) .`, groupUID, userNode.UID, edgeTimestamp())
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -1355,7 +1660,7 @@ func (c *Client) RemoveGroupMember(ctx context.Context, groupID, username string
 	groupUID := res.G[0].UID
 
 	// Find User
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", username), username, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(username), username, NodeTypeUser)
 	if err != nil || userNode == nil {
 		return fmt.Errorf("user %s not found", username)
 	}
@@ -1363,7 +1668,7 @@ func (c *Client) RemoveGroupMember(ctx context.Context, groupID, username string
 	// Delete Edge: <GroupUID> <group_has_member> <UserUID>
 	nquad := fmt.Sprintf(`<%s> <group_has_member> <%s> .`, groupUID, userNode.UID)
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -1428,7 +1733,7 @@ func (c *Client) DeleteGroup(ctx context.Context, groupID, userID string) error
 		CommitNow:  true,
 	}
 
-	_, err = c.dg.NewTxn().Mutate(ctx, mu)
+	_, err = c.dgraph().NewTxn().Mutate(ctx, mu)
 	if err != nil {
 		return err
 	}
@@ -1444,7 +1749,7 @@ func (c *Client) DeleteGroup(ctx context.Context, groupID, userID string) error
 
 // DeleteNode deletes a node by its UID
 func (c *Client) DeleteNode(ctx context.Context, uid, namespace string) error {
-	if c.dg == nil {
+	if len(c.dgs) == 0 {
 		return fmt.Errorf("graph client not initialized")
 	}
 
@@ -1458,8 +1763,13 @@ func (c *Client) DeleteNode(ctx context.Context, uid, namespace string) error {
 		return fmt.Errorf("namespace mismatch: cannot delete node from different namespace")
 	}
 
-	// Delete the node using DGraph mutation
-	d := map[string]string{"uid": uid}
+	// Delete the node and its attribute children using DGraph mutation
+	d := []map[string]string{{"uid": uid}}
+	if attrs, err := c.attributeUIDs(ctx, uid); err == nil {
+		for _, childUID := range attrs {
+			d = append(d, map[string]string{"uid": childUID})
+		}
+	}
 	db, err := json.Marshal(d)
 	if err != nil {
 		return err
@@ -1470,7 +1780,7 @@ func (c *Client) DeleteNode(ctx context.Context, uid, namespace string) error {
 		CommitNow:  true,
 	}
 
-	_, err = c.dg.NewTxn().Mutate(ctx, mu)
+	_, err = c.dgraph().NewTxn().Mutate(ctx, mu)
 	if err != nil {
 		return fmt.Errorf("failed to delete node: %w", err)
 	}
@@ -1482,8 +1792,9 @@ func (c *Client) DeleteNode(ctx context.Context, uid, namespace string) error {
 	return nil
 }
 
-// ShareToGroup shares a conversation ID with a group
-func (c *Client) ShareToGroup(ctx context.Context, conversationID, groupID string) error {
+// ShareToGroup shares a conversation ID with a group, recording the user ID
+// of the sharer so they can later revoke it via UnshareFromGroup
+func (c *Client) ShareToGroup(ctx context.Context, conversationID, groupID, sharedBy string) error {
 	// 1. Find Group
 	q := `query FindGroup($ns: string) {
 		g(func: eq(namespace, $ns)) @filter(type(Group)) {
@@ -1507,31 +1818,120 @@ func (c *Client) ShareToGroup(ctx context.Context, conversationID, groupID strin
 
 	// 2. Create Shared Record
 	// For "Quantum" speed, we just write a new SharedConversation node linked to the group
-	blankNode := fmt.Sprintf("_:share_%d", time.Now().UnixNano())
+	blankNode := newBlankNode("share")
 	var nquads strings.Builder
 
 	nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "SharedConversation" .
 `, blankNode))
-	nquads.WriteString(fmt.Sprintf(`%s <conversation_id> %q .
-`, blankNode, conversationID))
+	nquads.WriteString(fmt.Sprintf(`%s <conversation_id> %s .
+`, blankNode, escapeRDFString(conversationID)))
 	nquads.WriteString(fmt.Sprintf(`%s <shared_with> <%s> .
 `, blankNode, groupUID))
 	nquads.WriteString(fmt.Sprintf(`%s <shared_at> "%s"^^<xs:dateTime> .
 `, blankNode, time.Now().Format(time.RFC3339)))
+	if sharedBy != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <shared_by> %s .
+`, blankNode, escapeRDFString(sharedBy)))
+	}
 
 	mu := &api.Mutation{
 		SetNquads: []byte(nquads.String()),
 		CommitNow: true,
 	}
 
-	_, err = c.dg.NewTxn().Mutate(ctx, mu)
+	_, err = c.dgraph().NewTxn().Mutate(ctx, mu)
 	return err
 }
 
+// GetConversationShares returns the workspaces a conversation has been shared to
+func (c *Client) GetConversationShares(ctx context.Context, conversationID string) ([]ConversationShare, error) {
+	query := `query Shares($cid: string) {
+		shares(func: eq(conversation_id, $cid)) @filter(type(SharedConversation)) {
+			uid
+			conversation_id
+			shared_by
+			shared_at
+			shared_with {
+				name
+				namespace
+			}
+		}
+	}`
+
+	resp, err := c.Query(ctx, query, map[string]string{"$cid": conversationID})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Shares []struct {
+			UID            string    `json:"uid"`
+			ConversationID string    `json:"conversation_id"`
+			SharedBy       string    `json:"shared_by"`
+			SharedAt       time.Time `json:"shared_at"`
+			SharedWith     *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"shared_with"`
+		} `json:"shares"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, err
+	}
+
+	shares := make([]ConversationShare, 0, len(result.Shares))
+	for _, s := range result.Shares {
+		share := ConversationShare{
+			UID:            s.UID,
+			ConversationID: s.ConversationID,
+			SharedBy:       s.SharedBy,
+			SharedAt:       s.SharedAt,
+		}
+		if s.SharedWith != nil {
+			share.GroupNamespace = s.SharedWith.Namespace
+			share.GroupName = s.SharedWith.Name
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// UnshareFromGroup removes every share record of a conversation to the given group
+func (c *Client) UnshareFromGroup(ctx context.Context, conversationID, groupNamespace string) error {
+	shares, err := c.GetConversationShares(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+
+	var nquads strings.Builder
+	for _, s := range shares {
+		if s.GroupNamespace == groupNamespace {
+			nquads.WriteString(fmt.Sprintf("<%s> * * .\n", s.UID))
+		}
+	}
+	if nquads.Len() == 0 {
+		return fmt.Errorf("conversation %s is not shared with %s", conversationID, groupNamespace)
+	}
+
+	mu := &api.Mutation{
+		DelNquads: []byte(nquads.String()),
+		CommitNow: true,
+	}
+	if _, err := c.dgraph().NewTxn().Mutate(ctx, mu); err != nil {
+		return fmt.Errorf("failed to unshare conversation: %w", err)
+	}
+
+	c.logger.Info("Conversation unshared",
+		zap.String("conversation_id", conversationID),
+		zap.String("namespace", groupNamespace),
+		zap.String("action", "unshare_conversation"))
+	return nil
+}
+
 // ListUserGroups returns groups the user is a member of (V2)
 // NOTE: This intentionally steps OUTSIDE the strict namespace filter for discovery.
 func (c *Client) ListUserGroups(ctx context.Context, userID string) ([]Group, error) {
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", userID), userID, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(userID), userID, NodeTypeUser)
 	if err != nil || userNode == nil {
 		return nil, fmt.Errorf("user not found: %s", userID)
 	}
@@ -1549,6 +1949,10 @@ func (c *Client) ListUserGroups(ctx context.Context, userID string) ([]Group, er
 				uid
 				name
 			}
+			group_has_admin {
+				uid
+				name
+			}
 		}
 	}`
 
@@ -1567,9 +1971,48 @@ func (c *Client) ListUserGroups(ctx context.Context, userID string) ([]Group, er
 	return result.Groups, nil
 }
 
+// FindOwnedGroupByName returns the group named name that ownerID administers, or nil if none exists.
+// Names are compared case-insensitively after trimming whitespace.
+func (c *Client) FindOwnedGroupByName(ctx context.Context, ownerID, name string) (*Group, error) {
+	ownerNode, err := c.FindNodeByName(ctx, nsutil.ForUser(ownerID), ownerID, NodeTypeUser)
+	if err != nil || ownerNode == nil {
+		return nil, fmt.Errorf("user not found: %s", ownerID)
+	}
+
+	query := `query OwnedGroups($owner: string) {
+		groups(func: type(Group)) @filter(uid_in(group_has_admin, $owner)) {
+			uid
+			name
+			description
+			namespace
+			created_at
+		}
+	}`
+
+	resp, err := c.Query(ctx, query, map[string]string{"$owner": ownerNode.UID})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Groups []Group `json:"groups"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, err
+	}
+
+	wanted := strings.TrimSpace(name)
+	for i := range result.Groups {
+		if strings.EqualFold(strings.TrimSpace(result.Groups[i].Name), wanted) {
+			return &result.Groups[i], nil
+		}
+	}
+	return nil, nil
+}
+
 // IsGroupAdmin checks if a user is an admin of the group
 func (c *Client) IsGroupAdmin(ctx context.Context, groupNamespace, userID string) (bool, error) {
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", userID), userID, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(userID), userID, NodeTypeUser)
 	if err != nil || userNode == nil {
 		return false, fmt.Errorf("user not found: %s", userID)
 	}
@@ -1638,7 +2081,7 @@ func (c *Client) FindEntityInNamespace(ctx context.Context, name, namespace stri
 
 	vars := map[string]string{"$name": normalizedName, "$ns": normalizedNamespace}
 
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
 	if err != nil {
 		c.logger.Warn("FindEntityInNamespace exact match query failed", zap.Error(err))
 		return nil, fmt.Errorf("failed to find entity: %w", err)
@@ -1674,7 +2117,7 @@ func (c *Client) FindEntityInNamespace(ctx context.Context, name, namespace stri
 	pattern := fmt.Sprintf("/^%s$/i", regexp.QuoteMeta(normalizedName))
 	caseVars := map[string]string{"$pattern": pattern, "$ns": normalizedNamespace}
 
-	resp, err = c.dg.NewReadOnlyTxn().QueryWithVars(ctx, caseInsensitiveQuery, caseVars)
+	resp, err = c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, caseInsensitiveQuery, caseVars)
 	if err != nil {
 		c.logger.Warn("FindEntityInNamespace case-insensitive query failed", zap.Error(err))
 		return nil, fmt.Errorf("failed to find entity: %w", err)
@@ -1728,7 +2171,7 @@ func (c *Client) findEntityFuzzy(ctx context.Context, name, namespace string) (*
 	}`
 
 	vars := map[string]string{"$ns": namespace}
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
 	if err != nil {
 		return nil, err
 	}
@@ -1880,7 +2323,7 @@ func (c *Client) BoostEntityActivation(ctx context.Context, uid string, boostAmo
 		CommitNow: true,
 	}
 
-	_, err := c.dg.NewTxn().Mutate(ctx, mu)
+	_, err := c.dgraph().NewTxn().Mutate(ctx, mu)
 	return err
 }
 
@@ -1908,7 +2351,7 @@ func (c *Client) BatchFindEntitiesByNames(ctx context.Context, namespace string,
 	}`
 
 	vars := map[string]string{"$ns": namespace}
-	resp, err := c.dg.NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("batch entity query failed: %w", err)
 	}
@@ -1945,27 +2388,47 @@ func (c *Client) BatchFindEntitiesByNames(ctx context.Context, namespace string,
 // 3. Always boost activation of existing entities (memory reconsolidation)
 // 4. Only create truly new entities
 // 5. Eliminates race conditions and is significantly more efficient
-func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summary string, entities []ExtractedEntity) (string, error) {
+//
+// Relationships are written as edges between the batch's entities (new or
+// existing) or user nodes; ones whose ends can't be resolved are dropped.
+func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summary string, entities []ExtractedEntity, relationships []ExtractedRelationship) (string, error) {
+	return c.IngestConversationWisdom(ctx, namespace, "", summary, entities, relationships)
+}
+
+// IngestConversationWisdom is IngestWisdomBatch for a single conversation: the
+// summary node and the entities it creates carry source_conversation_id so the
+// memory can be traced back to the conversation it was crystallized from.
+// An empty conversationID behaves exactly like IngestWisdomBatch.
+func (c *Client) IngestConversationWisdom(ctx context.Context, namespace, conversationID string, summary string, entities []ExtractedEntity, relationships []ExtractedRelationship) (string, error) {
 	var nquads strings.Builder
 
 	// 1. Create Summary Node (Unique logical fact per batch timestamp for now)
-	summaryBlankID := fmt.Sprintf("summary_%d", time.Now().UnixNano())
-	summaryNode := "_:" + summaryBlankID
+	summaryNode := newBlankNode("summary")
+	summaryBlankID := summaryNode[2:]
+	summaryName := "Batch Summary"
+	if conversationID != "" {
+		summaryName = "Conversation Summary"
+	}
 
 	nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "Fact" .
 `, summaryNode))
-	nquads.WriteString(fmt.Sprintf(`%s <name> "Batch Summary" .
-`, summaryNode))
-	nquads.WriteString(fmt.Sprintf(`%s <description> %q .
-`, summaryNode, summary))
-	nquads.WriteString(fmt.Sprintf(`%s <fact_value> %q .
-`, summaryNode, summary))
-	nquads.WriteString(fmt.Sprintf(`%s <namespace> %q .
-`, summaryNode, namespace))
+	nquads.WriteString(fmt.Sprintf(`%s <name> %s .
+`, summaryNode, escapeRDFString(summaryName)))
+	nquads.WriteString(fmt.Sprintf(`%s <description> %s .
+`, summaryNode, escapeRDFString(summary)))
+	nquads.WriteString(fmt.Sprintf(`%s <fact_value> %s .
+`, summaryNode, escapeRDFString(summary)))
+	writeSearchTermsNquad(&nquads, summaryNode, summaryName, summary)
+	nquads.WriteString(fmt.Sprintf(`%s <namespace> %s .
+`, summaryNode, escapeRDFString(namespace)))
 	nquads.WriteString(fmt.Sprintf(`%s <created_at> "%s"^^<xs:dateTime> .
 `, summaryNode, time.Now().Format(time.RFC3339)))
 	nquads.WriteString(fmt.Sprintf(`%s <status> "crystallized" .
 `, summaryNode))
+	if conversationID != "" {
+		nquads.WriteString(fmt.Sprintf(`%s <source_conversation_id> %s .
+`, summaryNode, escapeRDFString(conversationID)))
+	}
 
 	// 2. IMPROVED: Pre-fetch ALL existing entities in a SINGLE query
 	// This is both more efficient and eliminates race conditions
@@ -1997,6 +2460,7 @@ func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summar
 	boostedEntityCount := 0
 	config := DefaultActivationConfig()
 	boostAmount := 0.008 // Very small boost for gradual memory strengthening
+	entityRefs := make(map[string]string, len(entities)) // normalized name -> <uid> or blank node
 
 	for i, e := range entities {
 		// Skip empty entity names
@@ -2034,6 +2498,7 @@ func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summar
 			// Link existing entity to this summary (shows this conversation referenced it)
 			nquads.WriteString(fmt.Sprintf(`<%s> <synthesized_from> %s .
 `, existingEntity.UID, summaryNode))
+			entityRefs[normalizedKey] = "<" + existingEntity.UID + ">"
 		} else {
 			// NEW ENTITY: Create with initial activation
 			// First mention of this entity in the user's knowledge graph
@@ -2041,16 +2506,19 @@ func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summar
 
 			nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "Entity" .
 `, entityNode))
-			nquads.WriteString(fmt.Sprintf(`%s <name> %q .
-`, entityNode, e.Name))
-			nquads.WriteString(fmt.Sprintf(`%s <namespace> %q .
-`, entityNode, namespace))
-			nquads.WriteString(fmt.Sprintf(`%s <description> %q .
-`, entityNode, e.Description))
+			nquads.WriteString(fmt.Sprintf(`%s <name> %s .
+`, entityNode, escapeRDFString(e.Name)))
+			nquads.WriteString(fmt.Sprintf(`%s <namespace> %s .
+`, entityNode, escapeRDFString(namespace)))
+			nquads.WriteString(fmt.Sprintf(`%s <description> %s .
+`, entityNode, escapeRDFString(e.Description)))
+			writeSearchTermsNquad(&nquads, entityNode, e.Name, e.Description)
+			nquads.WriteString(fmt.Sprintf(`%s <entity_type> %s .
+`, entityNode, escapeRDFString(string(NormalizeEntityType(string(e.Type))))))
 			// Store the original source text (what the user actually said)
 			if e.SourceText != "" {
-				nquads.WriteString(fmt.Sprintf(`%s <source_text> %q .
-`, entityNode, e.SourceText))
+				nquads.WriteString(fmt.Sprintf(`%s <source_text> %s .
+`, entityNode, escapeRDFString(e.SourceText)))
 			}
 			// Initial activation for new entities (very low baseline for gradual strengthening)
 			nquads.WriteString(fmt.Sprintf(`%s <activation> "%f"^^<xs:double> .
@@ -2060,13 +2528,19 @@ func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summar
 
 			// Store tags for policy-based access control
 			for _, tag := range e.Tags {
-				nquads.WriteString(fmt.Sprintf(`%s <tags> %q .
-`, entityNode, tag))
+				nquads.WriteString(fmt.Sprintf(`%s <tags> %s .
+`, entityNode, escapeRDFString(tag)))
+			}
+
+			if conversationID != "" {
+				nquads.WriteString(fmt.Sprintf(`%s <source_conversation_id> %s .
+`, entityNode, escapeRDFString(conversationID)))
 			}
 
 			// Link Entity -> Summary (Derived From)
 			nquads.WriteString(fmt.Sprintf(`%s <synthesized_from> %s .
 `, entityNode, summaryNode))
+			entityRefs[normalizedKey] = entityNode
 			newEntityCount++
 			c.logger.Debug("New entity created",
 				zap.String("name", e.Name),
@@ -2074,14 +2548,17 @@ func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summar
 		}
 	}
 
+	relationCount := c.writeWisdomRelationships(ctx, &nquads, namespace, entityRefs, relationships)
+
 	c.logger.Info("Entity processing complete (activation-focused)",
 		zap.Int("new_entities", newEntityCount),
 		zap.Int("boosted_entities", boostedEntityCount),
+		zap.Int("relationships", relationCount),
 		zap.String("strategy", "always_boost_existing"))
 
 	c.logger.Debug("Writing Wisdom Batch", zap.String("namespace", namespace))
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -2103,6 +2580,50 @@ func (c *Client) IngestWisdomBatch(ctx context.Context, namespace string, summar
 	return summaryUID, nil
 }
 
+// wisdomRelationWeight is the weight of relationship edges crystallized from
+// conversation; lower than direct ingestion since they come from a summary
+const wisdomRelationWeight = 0.7
+
+// writeWisdomRelationships appends an edge for each relationship whose ends
+// resolve to a batch entity or a user node of namespace, and returns how many
+// it wrote. Names are never resolved in other namespaces, so a summary cannot
+// link to another tenant's nodes.
+func (c *Client) writeWisdomRelationships(ctx context.Context, nquads *strings.Builder, namespace string, entityRefs map[string]string, relationships []ExtractedRelationship) int {
+	resolve := func(name string) string {
+		if ref, ok := entityRefs[normalizeForMatching(name)]; ok {
+			return ref
+		}
+		user, err := c.FindNodeByName(ctx, namespace, name, NodeTypeUser)
+		if err != nil || user == nil {
+			return ""
+		}
+		ref := "<" + user.UID + ">"
+		entityRefs[normalizeForMatching(name)] = ref
+		return ref
+	}
+
+	written := 0
+	for _, r := range relationships {
+		edgeType, ok := NormalizeEdgeType(string(r.Type))
+		if !ok {
+			continue
+		}
+		from, to := resolve(r.From), resolve(r.To)
+		if from == "" || to == "" || from == to {
+			c.logger.Debug("Dropping wisdom relationship with unresolved end",
+				zap.String("from", r.From),
+				zap.String("to", r.To),
+				zap.String("type", string(edgeType)))
+			continue
+		}
+		w := edgeWrite{weight: wisdomRelationWeight, createdAt: edgeTimestamp(), needsReview: r.NeedsReview}
+		nquads.WriteString(fmt.Sprintf(`%s %s %s (%s) .
+`, from, escapeRDFPredicate(edgeTypeToPredicateName(edgeType)), to, w.facets()))
+		written++
+	}
+	return written
+}
+
 // ============================================================================
 // WORKSPACE COLLABORATION FUNCTIONS
 // ============================================================================
@@ -2115,7 +2636,7 @@ func (c *Client) InviteToWorkspace(ctx context.Context, workspaceNS, inviterID,
 	}
 
 	// Check if invitee exists
-	inviteeNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", inviteeUsername), inviteeUsername, NodeTypeUser)
+	inviteeNode, err := c.FindNodeByName(ctx, nsutil.ForUser(inviteeUsername), inviteeUsername, NodeTypeUser)
 	if err != nil || inviteeNode == nil {
 		return nil, fmt.Errorf("user %s not found", inviteeUsername)
 	}
@@ -2136,25 +2657,25 @@ func (c *Client) InviteToWorkspace(ctx context.Context, workspaceNS, inviterID,
 	}
 
 	// Create invitation
-	blankNode := fmt.Sprintf("_:invite_%d", time.Now().UnixNano())
+	blankNode := newBlankNode("invite")
 	var nquads strings.Builder
 
 	nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "WorkspaceInvitation" .
 `, blankNode))
-	nquads.WriteString(fmt.Sprintf(`%s <workspace_id> %q .
-`, blankNode, workspaceNS))
-	nquads.WriteString(fmt.Sprintf(`%s <invitee_user_id> %q .
-`, blankNode, inviteeUsername))
-	nquads.WriteString(fmt.Sprintf(`%s <role> %q .
-`, blankNode, role))
+	nquads.WriteString(fmt.Sprintf(`%s <workspace_id> %s .
+`, blankNode, escapeRDFString(workspaceNS)))
+	nquads.WriteString(fmt.Sprintf(`%s <invitee_user_id> %s .
+`, blankNode, escapeRDFString(inviteeUsername)))
+	nquads.WriteString(fmt.Sprintf(`%s <role> %s .
+`, blankNode, escapeRDFString(role)))
 	nquads.WriteString(fmt.Sprintf(`%s <status> "pending" .
 `, blankNode))
 	nquads.WriteString(fmt.Sprintf(`%s <created_at> "%s"^^<xs:dateTime> .
 `, blankNode, time.Now().Format(time.RFC3339)))
-	nquads.WriteString(fmt.Sprintf(`%s <created_by> %q .
-`, blankNode, inviterID))
+	nquads.WriteString(fmt.Sprintf(`%s <created_by> %s .
+`, blankNode, escapeRDFString(inviterID)))
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -2227,7 +2748,7 @@ func (c *Client) findPendingInvitation(ctx context.Context, workspaceNS, usernam
 
 // IsWorkspaceMember checks if a user is a member (admin or subuser) of the workspace
 func (c *Client) IsWorkspaceMember(ctx context.Context, workspaceNS, userID string) (bool, error) {
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", userID), userID, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(userID), userID, NodeTypeUser)
 	if err != nil || userNode == nil {
 		return false, nil
 	}
@@ -2258,102 +2779,6 @@ func (c *Client) IsWorkspaceMember(ctx context.Context, workspaceNS, userID stri
 	return len(res.G) > 0, nil
 }
 
-// AcceptInvitation accepts a pending invitation and adds user to workspace
-func (c *Client) AcceptInvitation(ctx context.Context, invitationUID, userID string) error {
-	// Get the invitation
-	invite, err := c.getInvitation(ctx, invitationUID)
-	if err != nil {
-		return err
-	}
-
-	// Verify this invitation is for this user
-	if invite.InviteeUserID != userID {
-		return fmt.Errorf("invitation is not for user %s", userID)
-	}
-
-	if invite.Status != "pending" {
-		return fmt.Errorf("invitation is not pending (status: %s)", invite.Status)
-	}
-
-	// Add user to workspace with appropriate role
-	if invite.Role == "admin" {
-		if err := c.addWorkspaceAdmin(ctx, invite.WorkspaceID, userID); err != nil {
-			return fmt.Errorf("failed to add admin: %w", err)
-		}
-	} else {
-		if err := c.AddGroupMember(ctx, invite.WorkspaceID, userID); err != nil {
-			return fmt.Errorf("failed to add member: %w", err)
-		}
-	}
-
-	// Update invitation status
-	nquad := fmt.Sprintf(`<%s> <status> "accepted" .`, invitationUID)
-	txn := c.dg.NewTxn()
-	defer txn.Discard(ctx)
-
-	mu := &api.Mutation{
-		SetNquads: []byte(nquad),
-		CommitNow: true,
-	}
-
-	if _, err := txn.Mutate(ctx, mu); err != nil {
-		return fmt.Errorf("failed to update invitation status: %w", err)
-	}
-
-	c.logger.Info("Invitation accepted",
-		zap.String("invitation", invitationUID),
-		zap.String("user", userID))
-
-	return nil
-}
-
-// addWorkspaceAdmin adds a user as admin to a workspace
-func (c *Client) addWorkspaceAdmin(ctx context.Context, workspaceNS, userID string) error {
-	// Find group by namespace
-	q := `query FindGroup($ns: string) {
-		g(func: eq(namespace, $ns)) @filter(type(Group)) {
-			uid
-		}
-	}`
-	resp, err := c.Query(ctx, q, map[string]string{"$ns": workspaceNS})
-	if err != nil {
-		return err
-	}
-
-	var res struct {
-		G []struct {
-			UID string `json:"uid"`
-		} `json:"g"`
-	}
-	json.Unmarshal(resp, &res)
-	if len(res.G) == 0 {
-		return fmt.Errorf("group %s not found", workspaceNS)
-	}
-	groupUID := res.G[0].UID
-
-	// Find User
-	namespace := fmt.Sprintf("user_%s", userID)
-	userNode, err := c.FindNodeByName(ctx, namespace, userID, NodeTypeUser)
-	if err != nil || userNode == nil {
-		return fmt.Errorf("user %s not found", userID)
-	}
-
-	nquad := fmt.Sprintf(`<%s> <group_has_admin> <%s> .`, groupUID, userNode.UID)
-
-	txn := c.dg.NewTxn()
-	defer txn.Discard(ctx)
-
-	mu := &api.Mutation{
-		SetNquads: []byte(nquad),
-		CommitNow: true,
-	}
-
-	if _, err := txn.Mutate(ctx, mu); err != nil {
-		return fmt.Errorf("failed to add admin: %w", err)
-	}
-	return nil
-}
-
 // getInvitation retrieves an invitation by UID
 func (c *Client) getInvitation(ctx context.Context, invitationUID string) (*WorkspaceInvitation, error) {
 	query := `query GetInvite($uid: string) {
@@ -2406,7 +2831,7 @@ func (c *Client) DeclineInvitation(ctx context.Context, invitationUID, userID st
 
 	// Update invitation status
 	nquad := fmt.Sprintf(`<%s> <status> "declined" .`, invitationUID)
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -2425,18 +2850,24 @@ func (c *Client) DeclineInvitation(ctx context.Context, invitationUID, userID st
 	return nil
 }
 
-// GetPendingInvitations returns all pending invitations for a user
+// GetPendingInvitations returns a user's pending invitations, newest first,
+// with the workspace names filled in. findPendingInvitation can race with a
+// concurrent invite, so only the newest of several for a workspace is kept.
 func (c *Client) GetPendingInvitations(ctx context.Context, userID string) ([]WorkspaceInvitation, error) {
 	query := `query GetInvites($user: string) {
 		invites(func: type(WorkspaceInvitation)) @filter(eq(invitee_user_id, $user) AND eq(status, "pending")) {
 			uid
-			workspace_id
+			ws as workspace_id
 			invitee_user_id
 			role
 			status
 			created_at
 			created_by
 		}
+		workspaces(func: eq(namespace, val(ws))) @filter(type(Group)) {
+			namespace
+			name
+		}
 	}`
 
 	resp, err := c.Query(ctx, query, map[string]string{"$user": userID})
@@ -2445,13 +2876,44 @@ func (c *Client) GetPendingInvitations(ctx context.Context, userID string) ([]Wo
 	}
 
 	var result struct {
-		Invites []WorkspaceInvitation `json:"invites"`
+		Invites    []WorkspaceInvitation `json:"invites"`
+		Workspaces []Group               `json:"workspaces"`
 	}
 	if err := json.Unmarshal(resp, &result); err != nil {
 		return nil, err
 	}
 
-	return result.Invites, nil
+	names := make(map[string]string, len(result.Workspaces))
+	for _, g := range result.Workspaces {
+		names[g.Namespace] = g.Name
+	}
+	return newestPendingInvitations(result.Invites, names), nil
+}
+
+// newestPendingInvitations orders invitations newest first (by UID on equal
+// times, so the order is stable), keeps the newest per workspace and invitee
+// and names each workspace from names
+func newestPendingInvitations(invites []WorkspaceInvitation, names map[string]string) []WorkspaceInvitation {
+	sort.Slice(invites, func(i, j int) bool {
+		if !invites[i].CreatedAt.Equal(invites[j].CreatedAt) {
+			return invites[i].CreatedAt.After(invites[j].CreatedAt)
+		}
+		return invites[i].UID > invites[j].UID
+	})
+
+	type inviteKey struct{ workspace, invitee string }
+	seen := make(map[inviteKey]bool, len(invites))
+	out := make([]WorkspaceInvitation, 0, len(invites))
+	for _, inv := range invites {
+		key := inviteKey{inv.WorkspaceID, inv.InviteeUserID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		inv.WorkspaceName = names[inv.WorkspaceID]
+		out = append(out, inv)
+	}
+	return out
 }
 
 // GetWorkspaceSentInvitations returns all pending invitations sent FROM a specific workspace
@@ -2488,15 +2950,15 @@ func (c *Client) CreateShareLink(ctx context.Context, workspaceNS, creatorID str
 	// Generate cryptographic token
 	token := generateSecureToken()
 
-	blankNode := fmt.Sprintf("_:sharelink_%d", time.Now().UnixNano())
+	blankNode := newBlankNode("sharelink")
 	var nquads strings.Builder
 
 	nquads.WriteString(fmt.Sprintf(`%s <dgraph.type> "ShareLink" .
 `, blankNode))
-	nquads.WriteString(fmt.Sprintf(`%s <workspace_id> %q .
-`, blankNode, workspaceNS))
-	nquads.WriteString(fmt.Sprintf(`%s <token> %q .
-`, blankNode, token))
+	nquads.WriteString(fmt.Sprintf(`%s <workspace_id> %s .
+`, blankNode, escapeRDFString(workspaceNS)))
+	nquads.WriteString(fmt.Sprintf(`%s <token> %s .
+`, blankNode, escapeRDFString(token)))
 	nquads.WriteString(fmt.Sprintf(`%s <role> "subuser" .
 `, blankNode))
 	nquads.WriteString(fmt.Sprintf(`%s <max_uses> "%d"^^<xs:int> .
@@ -2507,15 +2969,15 @@ func (c *Client) CreateShareLink(ctx context.Context, workspaceNS, creatorID str
 `, blankNode))
 	nquads.WriteString(fmt.Sprintf(`%s <created_at> "%s"^^<xs:dateTime> .
 `, blankNode, time.Now().Format(time.RFC3339)))
-	nquads.WriteString(fmt.Sprintf(`%s <created_by> %q .
-`, blankNode, creatorID))
+	nquads.WriteString(fmt.Sprintf(`%s <created_by> %s .
+`, blankNode, escapeRDFString(creatorID)))
 
 	if expiresAt != nil {
 		nquads.WriteString(fmt.Sprintf(`%s <expires_at> "%s"^^<xs:dateTime> .
 `, blankNode, expiresAt.Format(time.RFC3339)))
 	}
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -2600,7 +3062,7 @@ func (c *Client) JoinViaShareLink(ctx context.Context, token, userID string) (*S
 		// Increment usage count ATOMICALLY before adding member
 		newUses := link.CurrentUses + 1
 		nquad := fmt.Sprintf(`<%s> <current_uses> "%d"^^<xs:int> .`, link.UID, newUses)
-		txn := c.dg.NewTxn()
+		txn := c.dgraph().NewTxn()
 		mu := &api.Mutation{
 			SetNquads: []byte(nquad),
 			CommitNow: true,
@@ -2634,7 +3096,7 @@ func (c *Client) JoinViaShareLink(ctx context.Context, token, userID string) (*S
 // rollbackShareLinkUsage rolls back the usage count on member addition failure
 func (c *Client) rollbackShareLinkUsage(ctx context.Context, linkUID string, targetValue int) error {
 	nquad := fmt.Sprintf(`<%s> <current_uses> "%d"^^<xs:int> .`, linkUID, targetValue)
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	mu := &api.Mutation{
 		SetNquads: []byte(nquad),
 		CommitNow: true,
@@ -2704,7 +3166,7 @@ func (c *Client) RevokeShareLink(ctx context.Context, token, userID string) erro
 
 	// Update is_active to false
 	nquad := fmt.Sprintf(`<%s> <is_active> "false"^^<xs:boolean> .`, link.UID)
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
@@ -2723,18 +3185,20 @@ func (c *Client) RevokeShareLink(ctx context.Context, token, userID string) erro
 	return nil
 }
 
-// GetWorkspaceMembers returns all members of a workspace with their roles
-func (c *Client) GetWorkspaceMembers(ctx context.Context, workspaceNS string) ([]WorkspaceMember, error) {
+// GetWorkspaceMembers returns one page of a workspace's members and the total
+// member count. Members are ordered admins first, then by join time and name, so
+// pages are stable. A limit <= 0 returns every member from offset on.
+func (c *Client) GetWorkspaceMembers(ctx context.Context, workspaceNS string, offset, limit int) ([]WorkspaceMember, int, error) {
 	query := `query GetMembers($ns: string) {
 		group(func: eq(namespace, $ns)) @filter(type(Group)) {
 			uid
 			name
-			group_has_admin {
+			group_has_admin @facets(joined_at) {
 				uid
 				name
 				created_at
 			}
-			group_has_member {
+			group_has_member @facets(joined_at) {
 				uid
 				name
 				created_at
@@ -2744,46 +3208,96 @@ func (c *Client) GetWorkspaceMembers(ctx context.Context, workspaceNS string) ([
 
 	resp, err := c.Query(ctx, query, map[string]string{"$ns": workspaceNS})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
+	// Decoded without Node so the joined_at facets land beside each user
+	type memberEdge struct {
+		UID          string    `json:"uid"`
+		Name         string    `json:"name"`
+		CreatedAt    time.Time `json:"created_at"`
+		AdminSince   time.Time `json:"group_has_admin|joined_at"`
+		JoinedMember time.Time `json:"group_has_member|joined_at"`
+	}
 	var result struct {
 		Group []struct {
-			UID     string `json:"uid"`
-			Name    string `json:"name"`
-			Admins  []Node `json:"group_has_admin"`
-			Members []Node `json:"group_has_member"`
+			UID     string       `json:"uid"`
+			Name    string       `json:"name"`
+			Admins  []memberEdge `json:"group_has_admin"`
+			Members []memberEdge `json:"group_has_member"`
 		} `json:"group"`
 	}
 	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	if len(result.Group) == 0 {
-		return nil, fmt.Errorf("workspace not found: %s", workspaceNS)
+		return nil, 0, fmt.Errorf("workspace not found: %s", workspaceNS)
+	}
+
+	// A user holds both edges when they are an admin (the creator always does);
+	// list them once. The member edge records when they joined; the admin edge
+	// only when they were promoted, so it is the fallback.
+	byUID := make(map[string]*WorkspaceMember)
+	add := func(e memberEdge, role string, joinedAt time.Time) {
+		m, ok := byUID[e.UID]
+		if !ok {
+			m = &WorkspaceMember{
+				User: &Node{UID: e.UID, DType: []string{string(NodeTypeUser)}, Name: e.Name, CreatedAt: e.CreatedAt},
+				Role: role,
+			}
+			byUID[e.UID] = m
+		}
+		if role == "admin" {
+			m.Role = role
+		}
+		if m.JoinedAt.IsZero() || (!joinedAt.IsZero() && role == "subuser") {
+			m.JoinedAt = joinedAt
+		}
 	}
-
-	var members []WorkspaceMember
-
-	// Add admins
 	for _, admin := range result.Group[0].Admins {
-		members = append(members, WorkspaceMember{
-			User:     &admin,
-			Role:     "admin",
-			JoinedAt: admin.CreatedAt,
-		})
+		add(admin, "admin", admin.AdminSince)
 	}
-
-	// Add subusers (members)
 	for _, member := range result.Group[0].Members {
-		members = append(members, WorkspaceMember{
-			User:     &member,
-			Role:     "subuser",
-			JoinedAt: member.CreatedAt,
-		})
+		add(member, "subuser", member.JoinedMember)
+	}
+
+	members := make([]WorkspaceMember, 0, len(byUID))
+	for _, m := range byUID {
+		members = append(members, *m)
+	}
+	sortWorkspaceMembers(members)
+
+	total := len(members)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
 	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return members[offset:end], total, nil
+}
 
-	return members, nil
+// sortWorkspaceMembers orders admins before subusers, then by join time
+// (members without a recorded join time last), then by name
+func sortWorkspaceMembers(members []WorkspaceMember) {
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if (a.Role == "admin") != (b.Role == "admin") {
+			return a.Role == "admin"
+		}
+		if !a.JoinedAt.Equal(b.JoinedAt) {
+			if a.JoinedAt.IsZero() || b.JoinedAt.IsZero() {
+				return b.JoinedAt.IsZero()
+			}
+			return a.JoinedAt.Before(b.JoinedAt)
+		}
+		return a.User.Name < b.User.Name
+	})
 }
 
 // GetShareLinks returns all active share links for a workspace
@@ -2839,7 +3353,7 @@ type UserSettings struct {
 // Uses JSON mutation format for proper string handling
 func (c *Client) StoreUserSettings(ctx context.Context, userID string, settings *UserSettings) error {
 	// Find the User node first
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", userID), userID, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(userID), userID, NodeTypeUser)
 	if err != nil || userNode == nil {
 		return fmt.Errorf("user not found: %s", userID)
 	}
@@ -2874,7 +3388,7 @@ func (c *Client) StoreUserSettings(ctx context.Context, userID string, settings
 
 	now := time.Now().Format(time.RFC3339)
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	if existingSettingsUID != "" {
@@ -2977,7 +3491,7 @@ func (c *Client) StoreUserSettings(ctx context.Context, userID string, settings
 // Returns empty UserSettings if not found (not an error)
 func (c *Client) GetUserSettings(ctx context.Context, userID string) (*UserSettings, error) {
 	// Find the User node first
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", userID), userID, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(userID), userID, NodeTypeUser)
 	if err != nil || userNode == nil {
 		c.logger.Debug("User node not found", zap.String("user", userID))
 		return &UserSettings{UserID: userID}, nil // Return empty settings, not an error
@@ -3062,7 +3576,7 @@ func (c *Client) GetUserSettings(ctx context.Context, userID string) (*UserSetti
 // DeleteUserAPIKey removes an API key from a user's settings
 func (c *Client) DeleteUserAPIKey(ctx context.Context, userID, provider string) error {
 	// Find the User node first
-	userNode, err := c.FindNodeByName(ctx, fmt.Sprintf("user_%s", userID), userID, NodeTypeUser)
+	userNode, err := c.FindNodeByName(ctx, nsutil.ForUser(userID), userID, NodeTypeUser)
 	if err != nil || userNode == nil {
 		return fmt.Errorf("user not found: %s", userID)
 	}
@@ -3117,7 +3631,7 @@ func (c *Client) DeleteUserAPIKey(ctx context.Context, userID, provider string)
 	}
 	jsonData, _ := json.Marshal(deleteData)
 
-	txn := c.dg.NewTxn()
+	txn := c.dgraph().NewTxn()
 	defer txn.Discard(ctx)
 
 	mu := &api.Mutation{
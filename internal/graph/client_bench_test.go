@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// BenchmarkCreateNodesConcurrent measures parallel CreateNodes throughput with a
+// single connection (the old behaviour) against the default pool. It needs a
+// running DGraph at DGRAPH_BENCH_ADDRESS (default localhost:9080):
+//
+//	go test ./internal/graph -run '^$' -bench CreateNodesConcurrent -cpu 16
+func BenchmarkCreateNodesConcurrent(b *testing.B) {
+	addr := os.Getenv("DGRAPH_BENCH_ADDRESS")
+	if addr == "" {
+		addr = "localhost:9080"
+	}
+
+	for _, poolSize := range []int{1, DefaultPoolSize} {
+		b.Run(fmt.Sprintf("pool=%d", poolSize), func(b *testing.B) {
+			cfg := DefaultClientConfig()
+			cfg.Address = addr
+			cfg.MaxRetries = 1
+			cfg.RetryInterval = 0
+			cfg.PoolSize = poolSize
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			client, err := NewClient(ctx, cfg, zap.NewNop())
+			cancel()
+			if err != nil {
+				b.Skipf("DGraph not available: %v", err)
+			}
+			defer client.Close()
+
+			namespace := fmt.Sprintf("bench_%d", time.Now().UnixNano())
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					nodes := make([]*Node, 10)
+					for j := range nodes {
+						nodes[j] = &Node{
+							DType:     []string{string(NodeTypeEntity)},
+							Name:      fmt.Sprintf("bench node %d-%d", i, j),
+							Namespace: namespace,
+						}
+					}
+					if _, err := client.CreateNodes(context.Background(), nodes); err != nil {
+						b.Error(err)
+						return
+					}
+					i++
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"go.uber.org/zap"
+)
+
+// TestNewBlankNodeConcurrent checks that blank nodes handed out concurrently
+// never repeat. Run with -race.
+func TestNewBlankNodeConcurrent(t *testing.T) {
+	const workers, perWorker = 16, 1000
+
+	var mu sync.Mutex
+	seen := make(map[string]bool, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]string, perWorker)
+			for i := range local {
+				local[i] = newBlankNode("node")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, b := range local {
+				if seen[b] {
+					t.Errorf("blank node %s handed out twice", b)
+				}
+				seen[b] = true
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// testClient connects to the DGraph at DGRAPH_TEST_ADDRESS (default
+// localhost:9080), skipping the test when it isn't reachable
+func testClient(t *testing.T) *Client {
+	t.Helper()
+	addr := os.Getenv("DGRAPH_TEST_ADDRESS")
+	if addr == "" {
+		addr = "localhost:9080"
+	}
+
+	cfg := DefaultClientConfig()
+	cfg.Address = addr
+	cfg.MaxRetries = 1
+	cfg.RetryInterval = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	client, err := NewClient(ctx, cfg, zap.NewNop())
+	cancel()
+	if err != nil {
+		t.Skipf("DGraph not available: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// TestCreateNodeConcurrentDistinctUIDs hammers CreateNode from many goroutines
+// and checks every call gets its own UID back. It needs a running DGraph (see
+// testClient) and skips otherwise:
+//
+//	go test -race ./internal/graph -run CreateNodeConcurrent
+func TestCreateNodeConcurrentDistinctUIDs(t *testing.T) {
+	client := testClient(t)
+
+	const workers = 32
+	namespace := fmt.Sprintf("test_concurrent_%d", time.Now().UnixNano())
+	uids := make([]string, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			uid, err := client.CreateNode(context.Background(), &Node{
+				DType:     []string{string(NodeTypeEntity)},
+				Name:      fmt.Sprintf("concurrent node %d", w),
+				Namespace: namespace,
+			})
+			if err != nil {
+				t.Errorf("CreateNode %d: %v", w, err)
+				return
+			}
+			uids[w] = uid
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[string]int, workers)
+	for w, uid := range uids {
+		if uid == "" {
+			continue
+		}
+		if prev, dup := seen[uid]; dup {
+			t.Errorf("CreateNode %d and %d both returned uid %s", prev, w, uid)
+		}
+		seen[uid] = w
+		defer client.DeleteNode(context.Background(), uid, namespace)
+	}
+}
+
+// fakePatternTxn records the mutations a pattern write makes
+type fakePatternTxn struct{ mutations []*api.Mutation }
+
+func (t *fakePatternTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	return &api.Response{Json: []byte(`{}`)}, nil
+}
+
+func (t *fakePatternTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	t.mutations = append(t.mutations, mu)
+	uids := make(map[string]string)
+	for _, line := range strings.Split(string(mu.SetNquads), "\n") {
+		if subject, _, ok := strings.Cut(line, " "); ok && strings.HasPrefix(subject, "_:") {
+			uids[subject[2:]] = "0x99"
+		}
+	}
+	return &api.Response{Uids: uids}, nil
+}
+
+func (t *fakePatternTxn) Discard(ctx context.Context) error { return nil }
+
+func TestWritePatternTriggers(t *testing.T) {
+	pattern := &Pattern{
+		Node:         Node{Name: "Pattern: gym on Tuesdays", Namespace: "user_alice"},
+		PatternType:  "weekly",
+		TriggerNodes: []string{"0x1", "0x2", "0x1", "", "0x2"},
+		Frequency:    3,
+	}
+	triggers := func(mu *api.Mutation) []string {
+		var uids []string
+		for _, line := range strings.Split(string(mu.SetNquads), "\n") {
+			if _, rest, ok := strings.Cut(line, " <trigger_nodes> "); ok {
+				uids = append(uids, strings.TrimSuffix(rest, " ."))
+			}
+		}
+		return uids
+	}
+
+	txn := &fakePatternTxn{}
+	uid, err := writePattern(context.Background(), txn, "", pattern)
+	if err != nil || uid != "0x99" {
+		t.Fatalf("new pattern: uid %q, err %v", uid, err)
+	}
+	if len(txn.mutations) != 1 {
+		t.Fatalf("new pattern made %d mutations, want 1", len(txn.mutations))
+	}
+	if got := triggers(txn.mutations[0]); !reflect.DeepEqual(got, []string{"<0x1>", "<0x2>"}) {
+		t.Errorf("trigger_nodes = %v, want each trigger once", got)
+	}
+
+	// An existing pattern's triggers are cleared before the new ones are set
+	txn = &fakePatternTxn{}
+	if uid, err := writePattern(context.Background(), txn, "0x5", pattern); err != nil || uid != "0x5" {
+		t.Fatalf("existing pattern: uid %q, err %v", uid, err)
+	}
+	if len(txn.mutations) != 2 || string(txn.mutations[0].DelNquads) != "<0x5> <trigger_nodes> * .\n" || txn.mutations[0].CommitNow {
+		t.Fatalf("mutations = %v, want the old triggers deleted first", txn.mutations)
+	}
+	set := txn.mutations[1]
+	if !set.CommitNow || strings.Contains(string(set.SetNquads), "<created_at>") {
+		t.Errorf("refresh should commit and keep created_at:\n%s", set.SetNquads)
+	}
+	if got := triggers(set); !reflect.DeepEqual(got, []string{"<0x1>", "<0x2>"}) {
+		t.Errorf("trigger_nodes = %v, want each trigger once", got)
+	}
+}
+
+// fakeNamesTxn answers ExistingNames blocks from the names it holds
+type fakeNamesTxn struct {
+	names map[string]bool
+	query string
+}
+
+func (t *fakeNamesTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	t.query = q
+	result := make(map[string][]map[string]string)
+	for v, name := range vars {
+		if v != "$namespace" && t.names[name] {
+			result["n"+v[2:]] = []map[string]string{{"name": name}}
+		}
+	}
+	data, _ := json.Marshal(result)
+	return &api.Response{Json: data}, nil
+}
+
+func (t *fakeNamesTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	return &api.Response{}, nil
+}
+
+func (t *fakeNamesTxn) Discard(ctx context.Context) error { return nil }
+
+func TestExistingNames(t *testing.T) {
+	txn := &fakeNamesTxn{names: map[string]bool{"Insight: a <-> b": true}}
+	found, err := existingNames(context.Background(), txn, "user_alice",
+		[]string{"Insight: a <-> b", "Insight: b <-> a", "Insight: a <-> b", "Insight: a <-> c"}, NodeTypeInsight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found, map[string]bool{"Insight: a <-> b": true}) {
+		t.Errorf("found = %v", found)
+	}
+	if n := strings.Count(txn.query, "(func: eq(name, "); n != 3 {
+		t.Errorf("query has %d name blocks, want one per distinct name:\n%s", n, txn.query)
+	}
+	if !strings.Contains(txn.query, "type(Insight) AND eq(namespace, $namespace)") {
+		t.Errorf("names not scoped to type and namespace:\n%s", txn.query)
+	}
+
+	txn = &fakeNamesTxn{}
+	if found, err := existingNames(context.Background(), txn, "user_alice", nil, NodeTypeInsight); err != nil || len(found) != 0 || txn.query != "" {
+		t.Errorf("no names: found %v, err %v, query %q", found, err, txn.query)
+	}
+}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// AppendConversationTurn persists one turn of a conversation
+func (c *Client) AppendConversationTurn(ctx context.Context, turn *ConversationTurn) error {
+	if turn.ConversationID == "" {
+		return fmt.Errorf("conversation turn needs a conversation ID")
+	}
+	timestamp := turn.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	blankNode := newBlankNode("turn")
+	var nquads strings.Builder
+	fmt.Fprintf(&nquads, "%s <dgraph.type> \"ConversationTurn\" .\n", blankNode)
+	fmt.Fprintf(&nquads, "%s <conversation_id> %s .\n", blankNode, escapeRDFString(turn.ConversationID))
+	fmt.Fprintf(&nquads, "%s <turn_at> \"%s\"^^<xs:dateTime> .\n", blankNode, timestamp.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&nquads, "%s <turn_user_query> %s .\n", blankNode, escapeRDFString(turn.UserQuery))
+	fmt.Fprintf(&nquads, "%s <turn_response> %s .\n", blankNode, escapeRDFString(turn.Response))
+	fmt.Fprintf(&nquads, "%s <turn_latency_ms> \"%d\" .\n", blankNode, turn.LatencyMs)
+	if turn.UserID != "" {
+		fmt.Fprintf(&nquads, "%s <turn_user> %s .\n", blankNode, escapeRDFString(turn.UserID))
+	}
+	if turn.Namespace != "" {
+		fmt.Fprintf(&nquads, "%s <namespace> %s .\n", blankNode, escapeRDFString(turn.Namespace))
+	}
+
+	_, err := c.dgraph().NewTxn().Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(nquads.String()),
+		CommitNow: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to persist conversation turn: %w", err)
+	}
+	return nil
+}
+
+// GetConversationTurns returns the persisted turns of a conversation, oldest
+// first, or none if it was never persisted
+func (c *Client) GetConversationTurns(ctx context.Context, conversationID string) ([]ConversationTurn, error) {
+	query := `query Turns($cid: string) {
+		turns(func: eq(conversation_id, $cid), orderasc: turn_at) @filter(type(ConversationTurn)) {
+			uid
+			conversation_id
+			turn_user
+			namespace
+			turn_at
+			turn_user_query
+			turn_response
+			turn_latency_ms
+		}
+	}`
+
+	resp, err := c.Query(ctx, query, map[string]string{"$cid": conversationID})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Turns []ConversationTurn `json:"turns"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, err
+	}
+	return result.Turns, nil
+}
+
+// DeleteConversationTurns deletes the persisted turns of a conversation in
+// namespace and returns how many it deleted
+func (c *Client) DeleteConversationTurns(ctx context.Context, namespace, conversationID string) (int, error) {
+	return deleteConversationTurns(ctx, c.dgraph().NewTxn(), namespace, conversationID)
+}
+
+// deleteConversationTurns reads the turn uids and deletes them inside txn, so
+// a turn appended in between is not left half deleted
+func deleteConversationTurns(ctx context.Context, txn accessTxn, namespace, conversationID string) (int, error) {
+	defer txn.Discard(ctx)
+
+	resp, err := txn.QueryWithVars(ctx, `query Turns($cid: string, $ns: string) {
+		turns(func: eq(conversation_id, $cid)) @filter(type(ConversationTurn) AND eq(namespace, $ns)) {
+			uid
+		}
+	}`, map[string]string{"$cid": conversationID, "$ns": namespace})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query conversation turns: %w", err)
+	}
+	var result struct {
+		Turns []struct {
+			UID string `json:"uid"`
+		} `json:"turns"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal conversation turns: %w", err)
+	}
+	if len(result.Turns) == 0 {
+		return 0, nil
+	}
+
+	var del strings.Builder
+	for _, t := range result.Turns {
+		fmt.Fprintf(&del, "<%s> * * .\n", t.UID)
+	}
+	if _, err := txn.Mutate(ctx, &api.Mutation{DelNquads: []byte(del.String()), CommitNow: true}); err != nil {
+		return 0, fmt.Errorf("failed to delete conversation turns: %w", err)
+	}
+	return len(result.Turns), nil
+}
+
+// BatchSummary is what the AI service's /summarize_batch returns for a
+// transcript: a summary plus the entities and relationships it mentions
+type BatchSummary struct {
+	Summary       string                  `json:"summary"`
+	Entities      []ExtractedEntity       `json:"entities"`
+	Relationships []ExtractedRelationship `json:"relationships"`
+}
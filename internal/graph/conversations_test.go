@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// fakeTurnTxn answers the turn query with reply and records the mutation
+type fakeTurnTxn struct {
+	query   string
+	vars    map[string]string
+	reply   string
+	mutated *api.Mutation
+}
+
+func (t *fakeTurnTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	t.query, t.vars = q, vars
+	return &api.Response{Json: []byte(t.reply)}, nil
+}
+
+func (t *fakeTurnTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	t.mutated = mu
+	return &api.Response{}, nil
+}
+
+func (t *fakeTurnTxn) Discard(ctx context.Context) error { return nil }
+
+func TestDeleteConversationTurns(t *testing.T) {
+	txn := &fakeTurnTxn{reply: `{"turns": [{"uid": "0x1"}, {"uid": "0x2"}]}`}
+	n, err := deleteConversationTurns(context.Background(), txn, "user_alice", "conv-1")
+	if err != nil || n != 2 {
+		t.Fatalf("deleteConversationTurns = %d, %v; want 2", n, err)
+	}
+	if !strings.Contains(txn.query, "eq(namespace, $ns)") || txn.vars["$ns"] != "user_alice" || txn.vars["$cid"] != "conv-1" {
+		t.Errorf("turns not scoped to the namespace: %s %v", txn.query, txn.vars)
+	}
+	if got := string(txn.mutated.DelNquads); got != "<0x1> * * .\n<0x2> * * .\n" {
+		t.Errorf("deleted %q", got)
+	}
+
+	txn = &fakeTurnTxn{reply: `{"turns": []}`}
+	if n, err := deleteConversationTurns(context.Background(), txn, "user_alice", "conv-2"); err != nil || n != 0 || txn.mutated != nil {
+		t.Errorf("conversation without turns: %d, %v, mutation %v", n, err, txn.mutated)
+	}
+}
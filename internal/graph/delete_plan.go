@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// deletePlanEdgeLimit caps the edges listed per planned node
+const deletePlanEdgeLimit = 1000
+
+// DeletePlan is what deleting nodes would remove, so a client can see it
+// before making an irreversible call
+type DeletePlan struct {
+	Namespace string        `json:"namespace"`
+	Nodes     []PlannedNode `json:"nodes"`
+	Edges     []PlannedEdge `json:"edges"`
+	NodeCount int           `json:"node_count"`
+	EdgeCount int           `json:"edge_count"`
+	Truncated bool          `json:"truncated,omitempty"` // Some node had more edges than were listed
+}
+
+// PlannedNode is a node a deletion would remove
+type PlannedNode struct {
+	UID  string   `json:"uid"`
+	Name string   `json:"name,omitempty"`
+	Type []string `json:"type,omitempty"`
+}
+
+// PlannedEdge is an edge a deletion would remove
+type PlannedEdge struct {
+	FromUID   string `json:"from_uid"`
+	FromName  string `json:"from_name,omitempty"`
+	ToUID     string `json:"to_uid"`
+	ToName    string `json:"to_name,omitempty"`
+	Predicate string `json:"predicate"`
+}
+
+// PlanDelete reports what DeleteNode would remove for each of uids without
+// changing anything: the node, its attribute children and its outgoing edges.
+// Like DeleteNode it fails when a node is missing or outside namespace, so
+// the plan also tells the caller whether the deletion would go through.
+func PlanDelete(ctx context.Context, store Store, namespace string, uids ...string) (*DeletePlan, error) {
+	plan := &DeletePlan{Namespace: namespace, Nodes: []PlannedNode{}, Edges: []PlannedEdge{}}
+	for _, uid := range uids {
+		node, err := store.GetNode(ctx, uid)
+		if err != nil {
+			return nil, fmt.Errorf("node not found: %w", err)
+		}
+		if node.Namespace != namespace {
+			return nil, fmt.Errorf("namespace mismatch: cannot delete node from different namespace")
+		}
+		plan.Nodes = append(plan.Nodes, PlannedNode{UID: node.UID, Name: node.Name, Type: node.DType})
+
+		// No namespace filter: edges into other namespaces go with the node too
+		expanded, err := store.ExpandFromNode(ctx, ExpandOpts{
+			StartUID:   uid,
+			MaxHops:    1,
+			MaxResults: deletePlanEdgeLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list edges of %s: %w", uid, err)
+		}
+		names := make(map[string]string, len(expanded.ByHop[1]))
+		for _, n := range expanded.ByHop[1] {
+			names[n.UID] = n.Name
+		}
+		for _, e := range expanded.Edges {
+			if e.Predicate == "has_attribute" {
+				plan.Nodes = append(plan.Nodes, PlannedNode{UID: e.ToUID, Type: []string{"Attribute"}})
+				continue
+			}
+			plan.Edges = append(plan.Edges, PlannedEdge{
+				FromUID:   e.FromUID,
+				FromName:  node.Name,
+				ToUID:     e.ToUID,
+				ToName:    names[e.ToUID],
+				Predicate: e.Predicate,
+			})
+		}
+		plan.Truncated = plan.Truncated || expanded.Truncated || len(expanded.ByHop[1]) >= deletePlanEdgeLimit
+	}
+	plan.NodeCount = len(plan.Nodes)
+	plan.EdgeCount = len(plan.Edges)
+	return plan, nil
+}
@@ -0,0 +1,132 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"go.uber.org/zap"
+)
+
+// Document content types. Documents are all typed Document; what kind of
+// file one came from is its content_type.
+const (
+	ContentTypeText = "text"
+	ContentTypePDF  = "pdf"
+)
+
+// documentTypePage is how many documents the content type backfill reads
+// and rewrites per transaction
+const documentTypePage = 500
+
+// NormalizeContentType returns the stored form of a document's content type:
+// lower case, with "" meaning text
+func NormalizeContentType(contentType string) string {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return ContentTypeText
+	}
+	return contentType
+}
+
+// backfillDocumentContentTypes moves the content type of documents stored
+// with it as an extra dgraph.type (["pdf", "Document"]) into content_type
+func backfillDocumentContentTypes(ctx context.Context, c *Client) error {
+	migrated, err := migrateDocumentTypes(ctx, func() accessTxn { return c.dgraph().NewTxn() })
+	if err != nil {
+		return err
+	}
+	c.logger.Info("Documents retyped by content type", zap.Int("documents", migrated))
+	return nil
+}
+
+// migrateDocumentTypes pages through every Document, leaving Document as
+// its only type and setting content_type, where unset, from the type it
+// drops. Registered node types are kept. It returns how many documents
+// it rewrote.
+func migrateDocumentTypes(ctx context.Context, newTxn func() accessTxn) (int, error) {
+	migrated := 0
+	after := ""
+	for {
+		count, next, err := migrateDocumentTypePage(ctx, newTxn(), after)
+		if err != nil {
+			return migrated, err
+		}
+		migrated += count
+		if next == "" {
+			return migrated, nil
+		}
+		after = next
+	}
+}
+
+// migrateDocumentTypePage rewrites one page of documents inside txn. When
+// the page was full, next is its last uid, for the following page to start
+// after.
+func migrateDocumentTypePage(ctx context.Context, txn accessTxn, after string) (migrated int, next string, err error) {
+	defer txn.Discard(ctx)
+
+	page := ""
+	if after != "" {
+		page = ", after: " + after
+	}
+	resp, err := txn.QueryWithVars(ctx, fmt.Sprintf(`{
+		docs(func: type(Document), first: %d%s) {
+			uid
+			dgraph.type
+			content_type
+		}
+	}`, documentTypePage, page), nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read documents: %w", err)
+	}
+	var result struct {
+		Docs []struct {
+			UID         string   `json:"uid"`
+			DType       []string `json:"dgraph.type"`
+			ContentType string   `json:"content_type"`
+		} `json:"docs"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, "", fmt.Errorf("failed to unmarshal documents: %w", err)
+	}
+	if len(result.Docs) == documentTypePage {
+		next = result.Docs[len(result.Docs)-1].UID
+	}
+
+	var set, del strings.Builder
+	for _, doc := range result.Docs {
+		var extra []string
+		for _, dtype := range doc.DType {
+			if _, registered := nodeTypeRank[NodeType(dtype)]; !registered {
+				extra = append(extra, dtype)
+			}
+		}
+		if len(extra) == 0 {
+			continue
+		}
+		sort.Strings(extra)
+		for _, dtype := range extra {
+			del.WriteString(fmt.Sprintf("<%s> <dgraph.type> %s .\n", doc.UID, escapeRDFString(dtype)))
+		}
+		if doc.ContentType == "" {
+			set.WriteString(fmt.Sprintf("<%s> <content_type> %s .\n", doc.UID, escapeRDFString(NormalizeContentType(extra[0]))))
+		}
+		migrated++
+	}
+	if migrated == 0 {
+		return 0, next, nil
+	}
+
+	mu := &api.Mutation{DelNquads: []byte(del.String()), CommitNow: true}
+	if set.Len() > 0 {
+		mu.SetNquads = []byte(set.String())
+	}
+	if _, err := txn.Mutate(ctx, mu); err != nil {
+		return 0, "", fmt.Errorf("failed to retype documents: %w", err)
+	}
+	return migrated, next, nil
+}
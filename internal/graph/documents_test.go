@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+type fakeDocument struct {
+	UID         string   `json:"uid"`
+	DType       []string `json:"dgraph.type"`
+	ContentType string   `json:"content_type,omitempty"`
+}
+
+// fakeDocumentStore answers migrateDocumentTypes from docs, kept in uid order
+// as DGraph pages them
+type fakeDocumentStore struct {
+	docs    []*fakeDocument
+	commits int
+}
+
+var fakeNquad = regexp.MustCompile(`^<(0x[0-9a-f]+)> <([\w.]+)> "(.*)" \.$`)
+
+func (s *fakeDocumentStore) newTxn() accessTxn { return &fakeDocumentTxn{s: s} }
+
+type fakeDocumentTxn struct{ s *fakeDocumentStore }
+
+func (t *fakeDocumentTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	after := ""
+	if m := fakeAfterPattern.FindStringSubmatch(q); m != nil {
+		after = m[1]
+	}
+	var out []*fakeDocument
+	for _, d := range t.s.docs {
+		if len(out) == documentTypePage {
+			break
+		}
+		if after == "" || d.UID > after {
+			out = append(out, d)
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"docs": out})
+	return &api.Response{Json: data}, nil
+}
+
+func (t *fakeDocumentTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	apply := func(nquads []byte, fn func(d *fakeDocument, pred, value string)) {
+		for _, line := range strings.Split(strings.TrimSpace(string(nquads)), "\n") {
+			m := fakeNquad.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			for _, d := range t.s.docs {
+				if d.UID == m[1] {
+					fn(d, m[2], m[3])
+				}
+			}
+		}
+	}
+	apply(mu.DelNquads, func(d *fakeDocument, pred, value string) {
+		kept := d.DType[:0]
+		for _, dtype := range d.DType {
+			if dtype != value {
+				kept = append(kept, dtype)
+			}
+		}
+		d.DType = kept
+	})
+	apply(mu.SetNquads, func(d *fakeDocument, pred, value string) {
+		if pred == "content_type" {
+			d.ContentType = value
+		}
+	})
+	t.s.commits++
+	return &api.Response{}, nil
+}
+
+func (t *fakeDocumentTxn) Discard(ctx context.Context) error { return nil }
+
+func TestMigrateDocumentTypes(t *testing.T) {
+	store := &fakeDocumentStore{}
+	for i := 1; i <= documentTypePage+20; i++ {
+		store.docs = append(store.docs, &fakeDocument{UID: fmt.Sprintf("0x%04x", i), DType: []string{"pdf", "Document"}})
+	}
+	store.docs = append(store.docs,
+		&fakeDocument{UID: "0x1000", DType: []string{"Document", "Text"}},
+		&fakeDocument{UID: "0x1001", DType: []string{"Document"}, ContentType: "markdown"},
+		&fakeDocument{UID: "0x1002", DType: []string{"Document", "text"}, ContentType: "markdown"},
+		&fakeDocument{UID: "0x1003", DType: []string{"Document", "Fact"}},
+	)
+
+	n, err := migrateDocumentTypes(context.Background(), store.newTxn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := documentTypePage + 22; n != want {
+		t.Errorf("migrated %d documents, want %d", n, want)
+	}
+	if store.commits != 2 {
+		t.Errorf("%d commits, want one per page", store.commits)
+	}
+
+	want := map[string]fakeDocument{
+		"0x0001": {DType: []string{"Document"}, ContentType: "pdf"},
+		"0x0214": {DType: []string{"Document"}, ContentType: "pdf"}, // second page
+		"0x1000": {DType: []string{"Document"}, ContentType: "text"},
+		"0x1001": {DType: []string{"Document"}, ContentType: "markdown"},
+		"0x1002": {DType: []string{"Document"}, ContentType: "markdown"}, // already set, kept
+		"0x1003": {DType: []string{"Document", "Fact"}},                  // registered types stay
+	}
+	for _, d := range store.docs {
+		w, ok := want[d.UID]
+		if !ok {
+			continue
+		}
+		if strings.Join(d.DType, ",") != strings.Join(w.DType, ",") || d.ContentType != w.ContentType {
+			t.Errorf("%s = %v %q, want %v %q", d.UID, d.DType, d.ContentType, w.DType, w.ContentType)
+		}
+	}
+
+	// A second run finds nothing left to do
+	if n, err := migrateDocumentTypes(context.Background(), store.newTxn); err != nil || n != 0 {
+		t.Errorf("rerun migrated %d documents (err %v), want 0", n, err)
+	}
+}
@@ -0,0 +1,230 @@
+package graph
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/embedding"
+)
+
+// Confidence levels of an edge type inference
+const (
+	// edgeMatchExact is a vocabulary name or predicate, or a known alias
+	edgeMatchExact = 1.0
+	// edgeMatchPhrase is a verb phrase that reads as a type's label once
+	// auxiliaries are dropped ("is married to", "manages")
+	edgeMatchPhrase = 0.9
+
+	// EdgeInferenceReviewThreshold is the confidence below which a mapping
+	// should be reviewed by a person
+	EdgeInferenceReviewThreshold = 0.8
+	// edgeInferenceMinSimilarity is the least embedding similarity accepted
+	// as a match; anything further off becomes related_to
+	edgeInferenceMinSimilarity = 0.6
+
+	// edgeLabelRetry is how long labels the embedder failed on wait before
+	// they are embedded again
+	edgeLabelRetry = 30 * time.Second
+)
+
+// EdgeTypeMatch is the edge type a relationship phrase was mapped to
+type EdgeTypeMatch struct {
+	Type EdgeType
+	// Reversed is set when the phrase names the edge's reverse ("Alice
+	// manages Bob" is Bob has_manager Alice): the caller swaps the ends
+	Reversed bool
+	// Confidence is 1 for a vocabulary name or alias, lower for paraphrases
+	// and nearest matches, and 0 for the related_to fallback
+	Confidence float64
+}
+
+// NeedsReview reports whether the mapping is too uncertain to trust unseen
+func (m EdgeTypeMatch) NeedsReview() bool {
+	return m.Confidence < EdgeInferenceReviewThreshold
+}
+
+// PhraseEmbedder embeds short phrases; local.LocalEmbedder is one
+type PhraseEmbedder interface {
+	Embed(text string) ([]float32, error)
+}
+
+// phraseStopWords are dropped from a verb phrase before it is compared with
+// the labels, so "is married to" reads as "married to"
+var phraseStopWords = map[string]bool{
+	"is": true, "are": true, "was": true, "were": true, "be": true, "been": true,
+	"a": true, "an": true, "the": true, "my": true, "his": true, "her": true, "their": true,
+}
+
+// edgePhraseAliases map verb phrases no label spells to relation edge types;
+// a reversed alias names the edge from its other end
+var edgePhraseAliases = map[string]EdgeTypeMatch{
+	"supervises":     {Type: EdgeTypeHasManager, Reversed: true},
+	"boss of":        {Type: EdgeTypeHasManager, Reversed: true},
+	"works under":    {Type: EdgeTypeHasManager},
+	"wife of":        {Type: EdgeTypePartnerIs},
+	"husband of":     {Type: EdgeTypePartnerIs},
+	"dating":         {Type: EdgeTypePartnerIs},
+	"engaged to":     {Type: EdgeTypePartnerIs},
+	"sister of":      {Type: EdgeTypeFamilyMember},
+	"brother of":     {Type: EdgeTypeFamilyMember},
+	"mother of":      {Type: EdgeTypeFamilyMember},
+	"father of":      {Type: EdgeTypeFamilyMember},
+	"parent of":      {Type: EdgeTypeFamilyMember},
+	"child of":       {Type: EdgeTypeFamilyMember},
+	"related by":     {Type: EdgeTypeFamilyMember},
+	"knows":          {Type: EdgeTypeKnows},
+	"friends with":   {Type: EdgeTypeFriendOf},
+	"works with":     {Type: EdgeTypeColleague},
+	"employed at":    {Type: EdgeTypeWorksAt},
+	"member of":      {Type: EdgeTypeWorksAt},
+	"working on":     {Type: EdgeTypeWorksOn},
+	"contributes to": {Type: EdgeTypeWorksOn},
+	"loves":          {Type: EdgeTypeLikes},
+	"enjoys":         {Type: EdgeTypeLikes},
+	"hates":          {Type: EdgeTypeDislikes},
+	"prefers over":   {Type: EdgeTypePrefers},
+	"depends on":     {Type: EdgeTypeBlockedBy},
+	"leads to":       {Type: EdgeTypeResultsIn},
+	"happened on":    {Type: EdgeTypeOccurredOn},
+}
+
+// EdgeTypeInferrer maps free-text relationship types from extractors to the
+// relation edge vocabulary. Names, aliases and label paraphrases are matched
+// directly; other phrases go to the nearest label by embedding when there is
+// an embedder, and to related_to when nothing is near enough.
+type EdgeTypeInferrer struct {
+	embedder PhraseEmbedder
+
+	mu       sync.Mutex
+	labels   []labelVector // Embedded labels of RelationEdgeTypes, both directions
+	complete bool          // Every label is embedded; until then lookups retry the rest
+	retryAt  time.Time     // When the labels that failed may be tried again
+}
+
+// labelVector is an embedded edge label
+type labelVector struct {
+	match EdgeTypeMatch
+	vec   []float32
+}
+
+// NewEdgeTypeInferrer returns an inferrer; a nil embedder limits it to names,
+// aliases and label paraphrases
+func NewEdgeTypeInferrer(embedder PhraseEmbedder) *EdgeTypeInferrer {
+	return &EdgeTypeInferrer{embedder: embedder}
+}
+
+// InferEdgeType maps a relationship phrase without embeddings; see
+// EdgeTypeInferrer
+func InferEdgeType(phrase string) EdgeTypeMatch {
+	return (&EdgeTypeInferrer{}).Infer(phrase)
+}
+
+// Infer maps a relationship phrase to the closest relation edge type; a nil
+// inferrer matches without embeddings
+func (i *EdgeTypeInferrer) Infer(phrase string) EdgeTypeMatch {
+	if t, ok := NormalizeEdgeType(phrase); ok {
+		return EdgeTypeMatch{Type: t, Confidence: edgeMatchExact}
+	}
+	key := normalizeEdgePhrase(phrase)
+	if key == "" {
+		return EdgeTypeMatch{Type: EdgeTypeRelatedTo}
+	}
+	if m, ok := edgePhraseAliases[key]; ok {
+		m.Confidence = edgeMatchExact
+		return m
+	}
+	if t, ok := NormalizeEdgeType(key); ok {
+		return EdgeTypeMatch{Type: t, Confidence: edgeMatchPhrase}
+	}
+	for _, t := range RelationEdgeTypes {
+		info, _ := lookupEdgeType(t)
+		if key == normalizeEdgePhrase(info.Label) {
+			return EdgeTypeMatch{Type: t, Confidence: edgeMatchPhrase}
+		}
+		if key == normalizeEdgePhrase(info.ReverseLabel) && info.ReverseLabel != info.Label {
+			return EdgeTypeMatch{Type: t, Reversed: true, Confidence: edgeMatchPhrase}
+		}
+	}
+	return i.nearest(key)
+}
+
+// nearest returns the relation edge label closest to phrase by embedding, or
+// related_to when there is no embedder or no label is similar enough
+func (i *EdgeTypeInferrer) nearest(phrase string) EdgeTypeMatch {
+	fallback := EdgeTypeMatch{Type: EdgeTypeRelatedTo}
+	if i == nil || i.embedder == nil {
+		return fallback
+	}
+	labels := i.labelVectors()
+	vec, err := i.embedder.Embed(phrase)
+	if err != nil {
+		return fallback
+	}
+
+	best := fallback
+	for _, label := range labels {
+		similarity := float64(embedding.CosineSimilarity(vec, label.vec))
+		if similarity >= edgeInferenceMinSimilarity && similarity > best.Confidence {
+			best = label.match
+			best.Confidence = similarity
+		}
+	}
+	return best
+}
+
+// labelVectors returns the embedded labels of every relation edge type,
+// both directions, embedding those still missing. A label the embedder
+// fails on is tried again by a lookup edgeLabelRetry later rather than left
+// out for good.
+func (i *EdgeTypeInferrer) labelVectors() []labelVector {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.complete || time.Now().Before(i.retryAt) {
+		return i.labels
+	}
+
+	embedded := make(map[EdgeTypeMatch]bool, len(i.labels))
+	for _, label := range i.labels {
+		embedded[label.match] = true
+	}
+	complete := true
+	for _, t := range RelationEdgeTypes {
+		info, _ := lookupEdgeType(t)
+		for _, label := range []struct {
+			text     string
+			reversed bool
+		}{{info.Label, false}, {info.ReverseLabel, true}} {
+			match := EdgeTypeMatch{Type: t, Reversed: label.reversed}
+			if (label.reversed && label.text == info.Label) || embedded[match] {
+				continue
+			}
+			vec, err := i.embedder.Embed(label.text)
+			if err != nil {
+				complete = false
+				continue
+			}
+			i.labels = append(i.labels, labelVector{match: match, vec: vec})
+		}
+	}
+	i.complete = complete
+	if !complete {
+		i.retryAt = time.Now().Add(edgeLabelRetry)
+	}
+	return i.labels
+}
+
+// normalizeEdgePhrase lowercases a phrase, splits it on spaces, underscores
+// and hyphens, and drops auxiliaries and articles
+func normalizeEdgePhrase(phrase string) string {
+	words := strings.FieldsFunc(strings.ToLower(phrase), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	})
+	kept := words[:0]
+	for _, w := range words {
+		if !phraseStopWords[w] {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInferEdgeTypeFromVerbPhrases(t *testing.T) {
+	cases := []struct {
+		phrase   string
+		want     EdgeType
+		reversed bool
+		review   bool
+	}{
+		{"HAS_MANAGER", EdgeTypeHasManager, false, false},
+		{"reports to", EdgeTypeHasManager, false, false},
+		{"manages", EdgeTypeHasManager, true, false},
+		{"is the boss of", EdgeTypeHasManager, true, false},
+		{"is married to", EdgeTypePartnerIs, false, false},
+		{"works for", EdgeTypeWorksAt, false, false},
+		{"employs", EdgeTypeWorksAt, true, false},
+		{"is friends with", EdgeTypeFriendOf, false, false},
+		{"is allergic to", EdgeTypeIsAllergic, false, false},
+		{"loves", EdgeTypeLikes, false, false},
+		{"mentored", EdgeTypeRelatedTo, false, true},
+		{"", EdgeTypeRelatedTo, false, true},
+	}
+	for _, c := range cases {
+		got := InferEdgeType(c.phrase)
+		if got.Type != c.want || got.Reversed != c.reversed || got.NeedsReview() != c.review {
+			t.Errorf("InferEdgeType(%q) = %+v, want %s reversed=%v review=%v",
+				c.phrase, got, c.want, c.reversed, c.review)
+		}
+	}
+}
+
+// phraseVectors embeds the phrases it knows and fails on the rest
+type phraseVectors map[string][]float32
+
+func (p phraseVectors) Embed(text string) ([]float32, error) {
+	if v, ok := p[text]; ok {
+		return v, nil
+	}
+	return nil, errors.New("unknown phrase")
+}
+
+func TestEdgeTypeInferrerNearestLabel(t *testing.T) {
+	inferrer := NewEdgeTypeInferrer(phraseVectors{
+		"manages":  {1, 0, 0},
+		"works on": {0, 1, 0},
+		"oversees": {0.9, 0.1, 0},
+		"builds":   {0.2, 0.9, 0.1},
+		"mentored": {0, 0, 1},
+	})
+
+	got := inferrer.Infer("oversees")
+	if got.Type != EdgeTypeHasManager || !got.Reversed || !(got.Confidence > 0.9 && got.Confidence < 1) {
+		t.Errorf("oversees = %+v, want reversed has_manager near 1", got)
+	}
+	if got := inferrer.Infer("builds"); got.Type != EdgeTypeWorksOn || got.Reversed {
+		t.Errorf("builds = %+v, want works_on", got)
+	}
+	if got := inferrer.Infer("mentored"); got.Type != EdgeTypeRelatedTo || !got.NeedsReview() {
+		t.Errorf("mentored = %+v, want related_to for review", got)
+	}
+}
+
+// flakyPhrases fails every embedding until up is set
+type flakyPhrases struct {
+	phraseVectors
+	up bool
+}
+
+func (f *flakyPhrases) Embed(text string) ([]float32, error) {
+	if !f.up {
+		return nil, errors.New("embedder down")
+	}
+	return f.phraseVectors.Embed(text)
+}
+
+func TestEdgeTypeInferrerRetriesLabels(t *testing.T) {
+	embedder := &flakyPhrases{phraseVectors: phraseVectors{
+		"manages":  {1, 0, 0},
+		"oversees": {0.9, 0.1, 0},
+	}}
+	inferrer := NewEdgeTypeInferrer(embedder)
+
+	if got := inferrer.Infer("oversees"); got.Type != EdgeTypeRelatedTo {
+		t.Fatalf("oversees with the embedder down = %+v, want related_to", got)
+	}
+
+	// Before the retry interval the labels are not asked for again
+	embedder.up = true
+	if got := inferrer.Infer("oversees"); got.Type != EdgeTypeRelatedTo {
+		t.Errorf("oversees before the retry = %+v, want related_to", got)
+	}
+
+	inferrer.retryAt = time.Time{}
+	if got := inferrer.Infer("oversees"); got.Type != EdgeTypeHasManager || !got.Reversed {
+		t.Errorf("oversees once the embedder is back = %+v, want reversed has_manager", got)
+	}
+}
@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EdgeTypeRelatedTo and EdgeTypePartOf are the generic links clients may use
+// when no specific relationship fits
+const (
+	EdgeTypeRelatedTo EdgeType = "RELATED_TO"
+	EdgeTypePartOf    EdgeType = "PART_OF"
+)
+
+// ErrUnknownEdgeType is returned when writing an edge whose type has no
+// predicate in the schema
+var ErrUnknownEdgeType = errors.New("unknown edge type")
+
+// EdgeTypeInfo describes an edge type for clients choosing one. Every edge
+// predicate is declared with @reverse, so Reverse ("~has_manager") walks it
+// backwards; the labels say which way is which ("has manager" vs "manages").
+type EdgeTypeInfo struct {
+	Type         EdgeType `json:"type"`
+	Predicate    string   `json:"predicate"`
+	Reverse      string   `json:"reverse"`
+	Label        string   `json:"label"`
+	ReverseLabel string   `json:"reverse_label"`
+	Functional   bool     `json:"functional"` // At most one current target per node
+}
+
+// edgeTypeRegistry is every edge type with a predicate in the schema.
+// SCHEDULED_AT is absent: scheduled_at is a datetime, not an edge.
+var edgeTypeRegistry = []EdgeTypeInfo{
+	{Type: EdgeTypePartnerIs, Predicate: "partner_is", Label: "partner is", ReverseLabel: "partner of"},
+	{Type: EdgeTypeFamilyMember, Predicate: "family_member", Label: "has family member", ReverseLabel: "family member of"},
+	{Type: EdgeTypeFriendOf, Predicate: "friend_of", Label: "friend of", ReverseLabel: "has friend"},
+	{Type: EdgeTypeHasManager, Predicate: "has_manager", Label: "has manager", ReverseLabel: "manages"},
+	{Type: EdgeTypeWorksOn, Predicate: "works_on", Label: "works on", ReverseLabel: "worked on by"},
+	{Type: EdgeTypeWorksAt, Predicate: "works_at", Label: "works at", ReverseLabel: "employs"},
+	{Type: EdgeTypeColleague, Predicate: "colleague", Label: "colleague of", ReverseLabel: "colleague of"},
+	{Type: EdgeTypeLikes, Predicate: "likes", Label: "likes", ReverseLabel: "liked by"},
+	{Type: EdgeTypeDislikes, Predicate: "dislikes", Label: "dislikes", ReverseLabel: "disliked by"},
+	{Type: EdgeTypeIsAllergic, Predicate: "is_allergic_to", Label: "is allergic to", ReverseLabel: "allergen for"},
+	{Type: EdgeTypePrefers, Predicate: "prefers", Label: "prefers", ReverseLabel: "preferred by"},
+	{Type: EdgeTypeHasInterest, Predicate: "has_interest", Label: "is interested in", ReverseLabel: "interest of"},
+	{Type: EdgeTypeCausedBy, Predicate: "caused_by", Label: "caused by", ReverseLabel: "causes"},
+	{Type: EdgeTypeBlockedBy, Predicate: "blocked_by", Label: "blocked by", ReverseLabel: "blocks"},
+	{Type: EdgeTypeResultsIn, Predicate: "results_in", Label: "results in", ReverseLabel: "results from"},
+	{Type: EdgeTypeContradicts, Predicate: "contradicts", Label: "contradicts", ReverseLabel: "contradicted by"},
+	{Type: EdgeTypeOccurredOn, Predicate: "occurred_on", Label: "occurred on", ReverseLabel: "date of"},
+	{Type: EdgeTypeDerivedFrom, Predicate: "derived_from", Label: "derived from", ReverseLabel: "source of"},
+	{Type: EdgeTypeSynthesized, Predicate: "synthesized_from", Label: "synthesized from", ReverseLabel: "synthesized into"},
+	{Type: EdgeTypeSupersedes, Predicate: "supersedes", Label: "supersedes", ReverseLabel: "superseded by"},
+	{Type: EdgeTypeKnows, Predicate: "knows", Label: "knows", ReverseLabel: "known by"},
+	{Type: EdgeTypeRelatedTo, Predicate: "related_to", Label: "related to", ReverseLabel: "related to"},
+	{Type: EdgeTypePartOf, Predicate: "part_of", Label: "part of", ReverseLabel: "has part"},
+}
+
+// edgeTypesByKey indexes the registry by lowercased type name and by predicate
+var edgeTypesByKey = func() map[string]EdgeTypeInfo {
+	byKey := make(map[string]EdgeTypeInfo, 2*len(edgeTypeRegistry))
+	for i := range edgeTypeRegistry {
+		info := &edgeTypeRegistry[i]
+		info.Reverse = "~" + info.Predicate
+		info.Functional = FunctionalEdges[info.Type]
+		byKey[strings.ToLower(string(info.Type))] = *info
+		byKey[info.Predicate] = *info
+	}
+	return byKey
+}()
+
+// EdgeTypeVocabulary returns every edge type that can be written, in a stable
+// order
+func EdgeTypeVocabulary() []EdgeTypeInfo {
+	return append([]EdgeTypeInfo(nil), edgeTypeRegistry...)
+}
+
+// EdgeTypeNames returns the type names of the vocabulary ("HAS_MANAGER", ...)
+func EdgeTypeNames() []string {
+	names := make([]string, len(edgeTypeRegistry))
+	for i, info := range edgeTypeRegistry {
+		names[i] = string(info.Type)
+	}
+	return names
+}
+
+// lookupEdgeType finds a registered type by its name or predicate, exactly as
+// code spells them ("HAS_MANAGER", "has_manager")
+func lookupEdgeType(t EdgeType) (EdgeTypeInfo, bool) {
+	info, ok := edgeTypesByKey[strings.ToLower(string(t))]
+	if !ok || (t != info.Type && string(t) != info.Predicate) {
+		return EdgeTypeInfo{}, false
+	}
+	return info, true
+}
+
+// ValidateEdgeType rejects edge types without a predicate in the schema.
+// Writers call it so a typo can't silently create an unindexed predicate.
+func ValidateEdgeType(t EdgeType) error {
+	if _, ok := lookupEdgeType(t); !ok {
+		return fmt.Errorf("%w %q", ErrUnknownEdgeType, t)
+	}
+	return nil
+}
+
+// ParseEdgeType resolves an edge type from client or model input: a type
+// name or predicate in any case ("has manager", "Has-Manager") or a common
+// phrasing NormalizeEdgeType understands ("reports_to")
+func ParseEdgeType(raw string) (EdgeType, error) {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+	if info, ok := edgeTypesByKey[key]; ok {
+		return info.Type, nil
+	}
+	if t, ok := NormalizeEdgeType(raw); ok {
+		return t, nil
+	}
+	return "", fmt.Errorf("%w %q (valid types: %s)", ErrUnknownEdgeType, raw, strings.Join(EdgeTypeNames(), ", "))
+}
+
+// ReversePredicate returns the predicate that walks an edge the other way:
+// "has_manager" (who manages me) gives "~has_manager" (who I manage) and vice
+// versa. It reports false for predicates that aren't registered edges.
+func ReversePredicate(predicate string) (string, bool) {
+	if forward, reversed := strings.CutPrefix(predicate, "~"); reversed {
+		info, ok := edgeTypesByKey[forward]
+		return info.Predicate, ok && info.Predicate == forward
+	}
+	info, ok := edgeTypesByKey[predicate]
+	if !ok || info.Predicate != predicate {
+		return "", false
+	}
+	return info.Reverse, true
+}
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseEdgeType(t *testing.T) {
+	for raw, want := range map[string]EdgeType{
+		"HAS_MANAGER":    EdgeTypeHasManager,
+		"has_manager":    EdgeTypeHasManager,
+		"Has Manager":    EdgeTypeHasManager,
+		"reports_to":     EdgeTypeHasManager,
+		"is-allergic-to": EdgeTypeIsAllergic,
+		"RELATED_TO":     EdgeTypeRelatedTo,
+		"derived_from":   EdgeTypeDerivedFrom,
+	} {
+		if got, err := ParseEdgeType(raw); err != nil || got != want {
+			t.Errorf("ParseEdgeType(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+
+	for _, raw := range []string{"", "admires", "SCHEDULED_AT"} {
+		if _, err := ParseEdgeType(raw); !errors.Is(err, ErrUnknownEdgeType) {
+			t.Errorf("ParseEdgeType(%q) error = %v, want ErrUnknownEdgeType", raw, err)
+		}
+	}
+}
+
+func TestValidateEdgeType(t *testing.T) {
+	for _, valid := range []EdgeType{EdgeTypeHasManager, "has_manager", EdgeTypePartOf} {
+		if err := ValidateEdgeType(valid); err != nil {
+			t.Errorf("ValidateEdgeType(%q) = %v", valid, err)
+		}
+	}
+	// Writers get no leniency: only the constant or the predicate itself
+	for _, invalid := range []EdgeType{"Has_Manager", "reports_to", EdgeTypeScheduledAt, "created_by"} {
+		if err := ValidateEdgeType(invalid); !errors.Is(err, ErrUnknownEdgeType) {
+			t.Errorf("ValidateEdgeType(%q) = %v, want ErrUnknownEdgeType", invalid, err)
+		}
+	}
+}
+
+func TestReversePredicate(t *testing.T) {
+	if got, ok := ReversePredicate("has_manager"); !ok || got != "~has_manager" {
+		t.Errorf("ReversePredicate(has_manager) = %q, %v", got, ok)
+	}
+	if got, ok := ReversePredicate("~has_manager"); !ok || got != "has_manager" {
+		t.Errorf("ReversePredicate(~has_manager) = %q, %v", got, ok)
+	}
+	for _, pred := range []string{"HAS_MANAGER", "scheduled_at", "~name", ""} {
+		if got, ok := ReversePredicate(pred); ok {
+			t.Errorf("ReversePredicate(%q) = %q, want not ok", pred, got)
+		}
+	}
+}
+
+func TestEdgeTypeVocabularyMatchesPredicates(t *testing.T) {
+	vocab := EdgeTypeVocabulary()
+	if len(vocab) != len(EdgeTypeNames()) {
+		t.Fatalf("vocabulary has %d entries, names %d", len(vocab), len(EdgeTypeNames()))
+	}
+	for _, info := range vocab {
+		if edgeTypeToPredicateName(info.Type) != info.Predicate || info.Reverse != "~"+info.Predicate {
+			t.Errorf("inconsistent entry %+v", info)
+		}
+		if info.Functional != FunctionalEdges[info.Type] {
+			t.Errorf("%s functional = %v", info.Type, info.Functional)
+		}
+	}
+	for _, rel := range RelationEdgeTypes {
+		if err := ValidateEdgeType(rel); err != nil {
+			t.Errorf("relation edge type %s is not writable: %v", rel, err)
+		}
+	}
+}
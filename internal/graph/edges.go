@@ -0,0 +1,370 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"go.uber.org/zap"
+)
+
+// Rewriting a triple DGraph already holds does not duplicate it, but it does
+// replace its facets: re-ingesting a relationship would reset a reinforced
+// weight and restamp created_at. CreateEdge, CreateEdges and IngestGraph
+// therefore look up the edges they are about to write and only touch what
+// would change.
+// Duplicates that do reach the graph are edges from one node to several
+// copies of the same entity; DedupeEdges collapses those.
+
+// edgeKey identifies one stored edge
+type edgeKey struct {
+	from      string
+	predicate string
+	to        string
+}
+
+// edgeFacets are the facets kept when an edge is written again
+type edgeFacets struct {
+	Weight      float64
+	CreatedAt   string
+	NeedsReview bool
+}
+
+// edgeWrite is an edge CreateEdges will set, with its merged facets
+type edgeWrite struct {
+	key         edgeKey
+	weight      float64
+	createdAt   string
+	needsReview bool
+}
+
+// facets renders the write's facets for an N-Quad
+func (w edgeWrite) facets() string {
+	facets := fmt.Sprintf("weight=%f, created_at=%s", w.weight, w.createdAt)
+	if w.needsReview {
+		facets += ", needs_review=true"
+	}
+	return facets
+}
+
+// existingEdges returns the facets of the edges among keys already stored
+func (c *Client) existingEdges(ctx context.Context, keys []edgeKey) (map[edgeKey]edgeFacets, error) {
+	fromByPredicate := make(map[string]map[string]bool)
+	for _, k := range keys {
+		// Only plain UIDs and predicate names are interpolated into the query;
+		// anything else is written without a lookup
+		if !uidPattern.MatchString(k.from) || !uidPattern.MatchString(k.to) || !predicatePattern.MatchString(k.predicate) {
+			continue
+		}
+		if fromByPredicate[k.predicate] == nil {
+			fromByPredicate[k.predicate] = make(map[string]bool)
+		}
+		fromByPredicate[k.predicate][k.from] = true
+	}
+	if len(fromByPredicate) == 0 {
+		return nil, nil
+	}
+
+	predicates := make([]string, 0, len(fromByPredicate))
+	for pred := range fromByPredicate {
+		predicates = append(predicates, pred)
+	}
+	sort.Strings(predicates)
+
+	var blocks strings.Builder
+	for i, pred := range predicates {
+		froms := make([]string, 0, len(fromByPredicate[pred]))
+		for from := range fromByPredicate[pred] {
+			froms = append(froms, from)
+		}
+		sort.Strings(froms)
+		blocks.WriteString(fmt.Sprintf(`
+		e%d(func: uid(%s)) {
+			uid
+			%s @facets(weight, created_at, needs_review) { uid }
+		}`, i, strings.Join(froms, ", "), pred))
+	}
+
+	resp, err := c.dgraph().NewReadOnlyTxn().Query(ctx, "{"+blocks.String()+"\n}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query existing edges: %w", err)
+	}
+	var result map[string][]map[string]interface{}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal existing edges: %w", err)
+	}
+
+	existing := make(map[edgeKey]edgeFacets)
+	for i, pred := range predicates {
+		for _, src := range result[fmt.Sprintf("e%d", i)] {
+			fromUID, _ := src["uid"].(string)
+			for _, target := range edgeTargets(src[pred]) {
+				toUID, _ := target["uid"].(string)
+				weight, _ := target[pred+"|weight"].(float64)
+				createdAt, _ := target[pred+"|created_at"].(string)
+				needsReview, _ := target[pred+"|needs_review"].(bool)
+				existing[edgeKey{fromUID, pred, toUID}] = edgeFacets{Weight: weight, CreatedAt: createdAt, NeedsReview: needsReview}
+			}
+		}
+	}
+	return existing, nil
+}
+
+// mergeEdgeWrites collapses repeats of an edge within edges and folds in the
+// facets of edges already stored. Weights merge by max: saying the same thing
+// twice confirms a relationship, it doesn't double its strength. An edge
+// stays marked for review only while every write of it was uncertain. Edges
+// whose stored facets already cover the write are dropped.
+func mergeEdgeWrites(edges []EdgeInput, existing map[edgeKey]edgeFacets, now string) []edgeWrite {
+	var writes []edgeWrite
+	index := make(map[edgeKey]int, len(edges))
+	for _, edge := range edges {
+		key := edgeKey{edge.FromUID, edgeTypeToPredicateName(edge.Type), edge.ToUID}
+		// Default weight to 0.5 if not set
+		weight := edge.Weight
+		if weight == 0 {
+			weight = 0.5
+		}
+		if i, ok := index[key]; ok {
+			writes[i].weight = max(writes[i].weight, weight)
+			writes[i].needsReview = writes[i].needsReview && edge.NeedsReview
+			continue
+		}
+		index[key] = len(writes)
+		writes = append(writes, edgeWrite{key: key, weight: weight, createdAt: now, needsReview: edge.NeedsReview})
+	}
+
+	merged := writes[:0]
+	for _, w := range writes {
+		if stored, ok := existing[w.key]; ok {
+			w.needsReview = w.needsReview && stored.NeedsReview
+			if stored.CreatedAt != "" && stored.Weight >= w.weight && stored.NeedsReview == w.needsReview {
+				continue
+			}
+			w.weight = max(w.weight, stored.Weight)
+			if stored.CreatedAt != "" {
+				w.createdAt = stored.CreatedAt
+			}
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
+
+// EdgesNeedingReview returns up to limit edges out of namespace nodes whose
+// type was inferred with low confidence, for a person to confirm or retype
+func (c *Client) EdgesNeedingReview(ctx context.Context, namespace string, limit int) ([]ExpandEdge, error) {
+	var blocks strings.Builder
+	for i, t := range RelationEdgeTypes {
+		pred := edgeTypeToPredicateName(t)
+		blocks.WriteString(fmt.Sprintf(`
+		e%d(func: uid(src)) @filter(has(%s)) {
+			uid
+			%s @facets(eq(needs_review, true)) @facets(weight) { uid }
+		}`, i, pred, pred))
+	}
+	query := fmt.Sprintf(`query EdgesNeedingReview($ns: string) {
+		src as var(func: eq(namespace, $ns))%s
+	}`, blocks.String())
+
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$ns": namespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query edges needing review: %w", err)
+	}
+	var result map[string][]map[string]interface{}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal edges needing review: %w", err)
+	}
+
+	var edges []ExpandEdge
+	for i, t := range RelationEdgeTypes {
+		pred := edgeTypeToPredicateName(t)
+		for _, src := range result[fmt.Sprintf("e%d", i)] {
+			fromUID, _ := src["uid"].(string)
+			for _, target := range edgeTargets(src[pred]) {
+				toUID, _ := target["uid"].(string)
+				weight, _ := target[pred+"|weight"].(float64)
+				edges = append(edges, ExpandEdge{FromUID: fromUID, ToUID: toUID, Predicate: pred, Weight: weight})
+				if limit > 0 && len(edges) == limit {
+					return edges, nil
+				}
+			}
+		}
+	}
+	return edges, nil
+}
+
+// dedupePredicates are the edges DedupeEdges inspects: the relationships
+// extraction writes, where a re-ingested entity can end up as a second node
+var dedupePredicates = func() []string {
+	preds := make([]string, 0, len(RelationEdgeTypes)+1)
+	for _, t := range RelationEdgeTypes {
+		preds = append(preds, edgeTypeToPredicateName(t))
+	}
+	return append(preds, edgeTypeToPredicateName(EdgeTypeKnows))
+}()
+
+// duplicateTarget is one target of a node's edges considered for collapsing
+type duplicateTarget struct {
+	UID       string
+	Name      string
+	Type      string
+	Weight    float64
+	CreatedAt string
+}
+
+// collapseTargets groups the targets of one node's predicate by entity
+// (name and type, case-insensitively). For every group of more than one it
+// returns the target to keep, carrying the highest weight and the earliest
+// created_at, and the targets whose edges should be removed.
+func collapseTargets(targets []duplicateTarget) (keep []duplicateTarget, drop [][]duplicateTarget) {
+	groups := make(map[string][]duplicateTarget)
+	var order []string
+	for _, t := range targets {
+		if strings.TrimSpace(t.Name) == "" {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(t.Name)) + "\x00" + strings.ToLower(t.Type)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], t)
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		// The strongest edge survives; ties go to the oldest, then the lowest UID
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].Weight != group[j].Weight {
+				return group[i].Weight > group[j].Weight
+			}
+			if before, after := earlier(group[i].CreatedAt, group[j].CreatedAt), earlier(group[j].CreatedAt, group[i].CreatedAt); before || after {
+				return before
+			}
+			return group[i].UID < group[j].UID
+		})
+
+		kept := group[0]
+		for _, t := range group[1:] {
+			if earlier(t.CreatedAt, kept.CreatedAt) {
+				kept.CreatedAt = t.CreatedAt
+			}
+		}
+		keep = append(keep, kept)
+		drop = append(drop, group[1:])
+	}
+	return keep, drop
+}
+
+// earlier reports whether timestamp a precedes b; unset or unparseable
+// timestamps sort last
+func earlier(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	switch {
+	case errA != nil:
+		return false
+	case errB != nil:
+		return true
+	}
+	return ta.Before(tb)
+}
+
+// DedupeEdges is a maintenance pass that collapses duplicate relationship
+// edges in a namespace: edges from one node, under one predicate, to several
+// nodes with the same name and type. One edge survives per entity with the
+// group's highest weight and earliest created_at. The duplicate nodes
+// themselves are left in place. It returns the number of edges removed.
+func (c *Client) DedupeEdges(ctx context.Context, namespace string) (int, error) {
+	if namespace == "" {
+		return 0, fmt.Errorf("namespace is required")
+	}
+
+	var blocks strings.Builder
+	for i, pred := range dedupePredicates {
+		blocks.WriteString(fmt.Sprintf(`
+		e%d(func: uid(src)) @filter(has(%s)) {
+			uid
+			%s @facets(weight, created_at) @filter(eq(namespace, $ns)) { uid name dgraph.type }
+		}`, i, pred, pred))
+	}
+	query := fmt.Sprintf(`query DedupeEdges($ns: string) {
+		src as var(func: eq(namespace, $ns))%s
+	}`, blocks.String())
+
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$ns": namespace})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query edges: %w", err)
+	}
+	var result map[string][]map[string]interface{}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal edges: %w", err)
+	}
+
+	var setNquads, delNquads strings.Builder
+	removed := 0
+	for i, pred := range dedupePredicates {
+		for _, src := range result[fmt.Sprintf("e%d", i)] {
+			fromUID, _ := src["uid"].(string)
+
+			var targets []duplicateTarget
+			for _, target := range edgeTargets(src[pred]) {
+				t := duplicateTarget{}
+				t.UID, _ = target["uid"].(string)
+				t.Name, _ = target["name"].(string)
+				if dtypes, ok := target["dgraph.type"].([]interface{}); ok && len(dtypes) > 0 {
+					t.Type, _ = dtypes[0].(string)
+				}
+				t.Weight, _ = target[pred+"|weight"].(float64)
+				t.CreatedAt, _ = target[pred+"|created_at"].(string)
+				targets = append(targets, t)
+			}
+
+			keep, drop := collapseTargets(targets)
+			for j, kept := range keep {
+				var facets []string
+				if kept.Weight > 0 {
+					facets = append(facets, fmt.Sprintf("weight=%f", kept.Weight))
+				}
+				if kept.CreatedAt != "" {
+					facets = append(facets, "created_at="+kept.CreatedAt)
+				}
+				facetList := ""
+				if len(facets) > 0 {
+					facetList = " (" + strings.Join(facets, ", ") + ")"
+				}
+				setNquads.WriteString(fmt.Sprintf("<%s> %s <%s>%s .\n", fromUID, escapeRDFPredicate(pred), kept.UID, facetList))
+				for _, dup := range drop[j] {
+					delNquads.WriteString(fmt.Sprintf("<%s> %s <%s> .\n", fromUID, escapeRDFPredicate(pred), dup.UID))
+					removed++
+				}
+			}
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+
+	txn := c.dgraph().NewTxn()
+	defer txn.Discard(ctx)
+	_, err = txn.Mutate(ctx, &api.Mutation{
+		SetNquads: []byte(setNquads.String()),
+		DelNquads: []byte(delNquads.String()),
+		CommitNow: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to collapse duplicate edges: %w", err)
+	}
+
+	c.logger.Info("Collapsed duplicate edges",
+		zap.String("namespace", namespace),
+		zap.Int("removed", removed))
+	return removed, nil
+}
@@ -0,0 +1,226 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeEdgeWrites(t *testing.T) {
+	const now = "2026-01-02T00:00:00Z"
+	stored := edgeKey{"0x1", "likes", "0x2"}
+	existing := map[edgeKey]edgeFacets{
+		stored:                      {Weight: 0.4, CreatedAt: "2025-06-01T00:00:00Z"},
+		{"0x1", "friend_of", "0x3"}: {Weight: 0.9, CreatedAt: "2025-06-01T00:00:00Z"},
+	}
+
+	writes := mergeEdgeWrites([]EdgeInput{
+		{FromUID: "0x1", ToUID: "0x2", Type: EdgeTypeLikes, Weight: 0.3},
+		{FromUID: "0x1", ToUID: "0x2", Type: EdgeTypeLikes, Weight: 0.7},
+		// Already stored with a higher weight: nothing to write
+		{FromUID: "0x1", ToUID: "0x3", Type: EdgeTypeFriendOf, Weight: 0.6},
+		{FromUID: "0x1", ToUID: "0x4", Type: EdgeTypeWorksAt},
+	}, existing, now)
+
+	want := []edgeWrite{
+		{key: stored, weight: 0.7, createdAt: "2025-06-01T00:00:00Z"},
+		{key: edgeKey{"0x1", "works_at", "0x4"}, weight: 0.5, createdAt: now},
+	}
+	if len(writes) != len(want) {
+		t.Fatalf("writes = %+v, want %+v", writes, want)
+	}
+	for i := range want {
+		if writes[i] != want[i] {
+			t.Errorf("write %d = %+v, want %+v", i, writes[i], want[i])
+		}
+	}
+}
+
+func TestMergeEdgeWritesNeedsReview(t *testing.T) {
+	const now = "2026-01-02T00:00:00Z"
+	const stamp = "2025-06-01T00:00:00Z"
+	confirmed := edgeKey{"0x1", "has_manager", "0x2"}
+	uncertain := edgeKey{"0x1", "works_on", "0x3"}
+	existing := map[edgeKey]edgeFacets{
+		confirmed: {Weight: 0.8, CreatedAt: stamp, NeedsReview: true},
+		uncertain: {Weight: 0.8, CreatedAt: stamp, NeedsReview: true},
+	}
+
+	writes := mergeEdgeWrites([]EdgeInput{
+		// A confident mapping clears the review mark of a stored edge
+		{FromUID: "0x1", ToUID: "0x2", Type: EdgeTypeHasManager, Weight: 0.5},
+		// Another uncertain one changes nothing
+		{FromUID: "0x1", ToUID: "0x3", Type: EdgeTypeWorksOn, Weight: 0.5, NeedsReview: true},
+		// Within a batch, one confident write is enough
+		{FromUID: "0x1", ToUID: "0x4", Type: EdgeTypeLikes, NeedsReview: true},
+		{FromUID: "0x1", ToUID: "0x4", Type: EdgeTypeLikes},
+		{FromUID: "0x1", ToUID: "0x5", Type: EdgeTypeLikes, NeedsReview: true},
+	}, existing, now)
+
+	want := []edgeWrite{
+		{key: confirmed, weight: 0.8, createdAt: stamp},
+		{key: edgeKey{"0x1", "likes", "0x4"}, weight: 0.5, createdAt: now},
+		{key: edgeKey{"0x1", "likes", "0x5"}, weight: 0.5, createdAt: now, needsReview: true},
+	}
+	if len(writes) != len(want) {
+		t.Fatalf("writes = %+v, want %+v", writes, want)
+	}
+	for i := range want {
+		if writes[i] != want[i] {
+			t.Errorf("write %d = %+v, want %+v", i, writes[i], want[i])
+		}
+	}
+	if facets := want[2].facets(); !strings.HasSuffix(facets, ", needs_review=true") {
+		t.Errorf("facets = %q, want the needs_review facet", facets)
+	}
+}
+
+func TestCollapseTargets(t *testing.T) {
+	keep, drop := collapseTargets([]duplicateTarget{
+		{UID: "0x10", Name: "Bob", Type: "Entity", Weight: 0.5, CreatedAt: "2025-03-01T00:00:00Z"},
+		{UID: "0x11", Name: "bob ", Type: "Entity", Weight: 0.8, CreatedAt: "2025-05-01T00:00:00Z"},
+		{UID: "0x12", Name: "Bob", Type: "Event", Weight: 0.2},
+		{UID: "0x13", Name: "Carol", Type: "Entity", Weight: 0.5},
+	})
+
+	if len(keep) != 1 || len(drop) != 1 {
+		t.Fatalf("keep = %+v, drop = %+v, want one collapsed group", keep, drop)
+	}
+	want := duplicateTarget{UID: "0x11", Name: "bob ", Type: "Entity", Weight: 0.8, CreatedAt: "2025-03-01T00:00:00Z"}
+	if keep[0] != want {
+		t.Errorf("kept %+v, want %+v", keep[0], want)
+	}
+	if len(drop[0]) != 1 || drop[0][0].UID != "0x10" {
+		t.Errorf("dropped %+v, want only 0x10", drop[0])
+	}
+}
+
+// TestCreateEdgesTwiceKeepsOneEdge needs a running DGraph (see testClient)
+func TestCreateEdgesTwiceKeepsOneEdge(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+
+	namespace := fmt.Sprintf("test_edges_%d", time.Now().UnixNano())
+	var uids []string
+	for _, name := range []string{"Alice", "Pizza"} {
+		uid, err := client.CreateNode(ctx, &Node{DType: []string{string(NodeTypeEntity)}, Name: name, Namespace: namespace})
+		if err != nil {
+			t.Fatalf("CreateNode %s: %v", name, err)
+		}
+		uids = append(uids, uid)
+		defer client.DeleteNode(ctx, uid, namespace)
+	}
+
+	edge := EdgeInput{FromUID: uids[0], ToUID: uids[1], Type: EdgeTypeLikes, Weight: 0.4}
+	if err := client.CreateEdges(ctx, []EdgeInput{edge}); err != nil {
+		t.Fatalf("first CreateEdges: %v", err)
+	}
+	key := edgeKey{uids[0], "likes", uids[1]}
+	first, err := client.existingEdges(ctx, []edgeKey{key})
+	if err != nil || first[key].CreatedAt == "" {
+		t.Fatalf("edge not stored after first write: %+v, %v", first, err)
+	}
+
+	edge.Weight = 0.9
+	if err := client.CreateEdges(ctx, []EdgeInput{edge, edge}); err != nil {
+		t.Fatalf("second CreateEdges: %v", err)
+	}
+	if err := client.CreateEdge(ctx, edge.FromUID, edge.ToUID, edge.Type, EdgeStatusCurrent); err != nil {
+		t.Fatalf("CreateEdge: %v", err)
+	}
+
+	resp, err := client.Query(ctx, `query Likes($uid: string) {
+		node(func: uid($uid)) { likes @facets(weight, created_at) { uid } }
+	}`, map[string]string{"$uid": uids[0]})
+	if err != nil {
+		t.Fatalf("query edges: %v", err)
+	}
+	var result struct {
+		Node []struct {
+			Likes []struct {
+				UID       string  `json:"uid"`
+				Weight    float64 `json:"likes|weight"`
+				CreatedAt string  `json:"likes|created_at"`
+			} `json:"likes"`
+		} `json:"node"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		t.Fatalf("decode edges: %v", err)
+	}
+	if len(result.Node) != 1 || len(result.Node[0].Likes) != 1 {
+		t.Fatalf("edges = %+v, want exactly one", result.Node)
+	}
+	got := result.Node[0].Likes[0]
+	if got.Weight < 0.89 || got.CreatedAt != first[key].CreatedAt {
+		t.Errorf("merged edge = %+v, want weight 0.9 and created_at %s", got, first[key].CreatedAt)
+	}
+}
+
+// TestIngestGraphKeepsStoredEdgeFacets needs a running DGraph (see testClient)
+func TestIngestGraphKeepsStoredEdgeFacets(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+
+	namespace := fmt.Sprintf("test_ingest_edges_%d", time.Now().UnixNano())
+	uids, err := client.IngestGraph(ctx, []*Node{
+		{DType: []string{string(NodeTypeEntity)}, Name: "Alice", Namespace: namespace},
+		{DType: []string{string(NodeTypeEntity)}, Name: "Pizza", Namespace: namespace},
+	}, []IngestEdge{{FromName: "Alice", ToName: "Pizza", Type: EdgeTypeLikes, Weight: 0.8}})
+	if err != nil {
+		t.Fatalf("first IngestGraph: %v", err)
+	}
+	for _, uid := range uids {
+		defer client.DeleteNode(ctx, uid, namespace)
+	}
+	key := edgeKey{uids["Alice"], "likes", uids["Pizza"]}
+	first, err := client.existingEdges(ctx, []edgeKey{key})
+	if err != nil || first[key].CreatedAt == "" {
+		t.Fatalf("edge not stored after first ingest: %+v, %v", first, err)
+	}
+
+	// Re-ingesting the relationship at a lower weight changes nothing
+	if _, err := client.IngestGraph(ctx, nil, []IngestEdge{
+		{FromUID: uids["Alice"], ToUID: uids["Pizza"], Type: EdgeTypeLikes, Weight: 0.3},
+	}); err != nil {
+		t.Fatalf("second IngestGraph: %v", err)
+	}
+	got, err := client.existingEdges(ctx, []edgeKey{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[key].Weight < 0.79 || got[key].CreatedAt != first[key].CreatedAt {
+		t.Errorf("edge after re-ingest = %+v, want weight 0.8 and created_at %s", got[key], first[key].CreatedAt)
+	}
+}
+
+func TestEdgesNeedingReview(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+
+	namespace := fmt.Sprintf("test_review_edges_%d", time.Now().UnixNano())
+	uids, err := client.IngestGraph(ctx, []*Node{
+		{DType: []string{string(NodeTypeEntity)}, Name: "Alice", Namespace: namespace},
+		{DType: []string{string(NodeTypeEntity)}, Name: "Bob", Namespace: namespace},
+		{DType: []string{string(NodeTypeEntity)}, Name: "Atlas", Namespace: namespace},
+	}, []IngestEdge{
+		{FromName: "Alice", ToName: "Bob", Type: EdgeTypeHasManager, Weight: 0.8},
+		{FromName: "Alice", ToName: "Atlas", Type: EdgeTypeWorksOn, Weight: 0.6, NeedsReview: true},
+	})
+	if err != nil {
+		t.Fatalf("IngestGraph: %v", err)
+	}
+	for _, uid := range uids {
+		defer client.DeleteNode(ctx, uid, namespace)
+	}
+
+	edges, err := client.EdgesNeedingReview(ctx, namespace, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(edges) != 1 || edges[0].ToUID != uids["Atlas"] || edges[0].Predicate != "works_on" {
+		t.Errorf("edges needing review = %+v, want only Alice works_on Atlas", edges)
+	}
+}
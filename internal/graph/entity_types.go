@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EntityType is the normalized kind of an extracted entity, stored in the
+// entity_type predicate. Extractors are prompted with these names, but LLMs
+// drift ("Company", "human", "city"), so NormalizeEntityType maps their output
+// back onto the enum.
+type EntityType string
+
+const (
+	EntityTypePerson       EntityType = "Person"
+	EntityTypeOrganization EntityType = "Organization"
+	EntityTypeLocation     EntityType = "Location"
+	EntityTypeEvent        EntityType = "Event"
+	EntityTypePreference   EntityType = "Preference"
+	EntityTypeFact         EntityType = "Fact"
+	EntityTypeMetric       EntityType = "Metric"
+	EntityTypeConcept      EntityType = "Concept"
+)
+
+// EntityTypes lists the enum in the order extraction prompts present it
+var EntityTypes = []EntityType{
+	EntityTypePerson,
+	EntityTypeOrganization,
+	EntityTypeLocation,
+	EntityTypeEvent,
+	EntityTypePreference,
+	EntityTypeFact,
+	EntityTypeMetric,
+	EntityTypeConcept,
+}
+
+// ErrUnknownEntityType is returned when a client names an entity type that is
+// neither in the enum nor a known alias
+var ErrUnknownEntityType = errors.New("unknown entity type")
+
+// EntityTypeNames returns EntityTypes as strings, for schemas and error messages
+func EntityTypeNames() []string {
+	names := make([]string, len(EntityTypes))
+	for i, t := range EntityTypes {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// entityTypeAliases maps common LLM spellings to the enum
+var entityTypeAliases = map[string]EntityType{
+	"user":        EntityTypePerson,
+	"people":      EntityTypePerson,
+	"human":       EntityTypePerson,
+	"individual":  EntityTypePerson,
+	"contact":     EntityTypePerson,
+	"company":     EntityTypeOrganization,
+	"org":         EntityTypeOrganization,
+	"team":        EntityTypeOrganization,
+	"employer":    EntityTypeOrganization,
+	"institution": EntityTypeOrganization,
+	"place":       EntityTypeLocation,
+	"city":        EntityTypeLocation,
+	"country":     EntityTypeLocation,
+	"address":     EntityTypeLocation,
+	"meeting":     EntityTypeEvent,
+	"date":        EntityTypeEvent,
+	"like":        EntityTypePreference,
+	"dislike":     EntityTypePreference,
+	"interest":    EntityTypePreference,
+	"hobby":       EntityTypePreference,
+	"statement":   EntityTypeFact,
+	"attribute":   EntityTypeFact,
+	"measurement": EntityTypeMetric,
+	"number":      EntityTypeMetric,
+	"topic":       EntityTypeConcept,
+	"entity":      EntityTypeConcept,
+	"thing":       EntityTypeConcept,
+}
+
+// NormalizeEntityType maps an extractor's type to the enum; anything
+// unrecognized is a Concept
+func NormalizeEntityType(raw string) EntityType {
+	if t, ok := lookupEntityType(raw); ok {
+		return t
+	}
+	return EntityTypeConcept
+}
+
+// ParseEntityType resolves a client's entity type to the enum, accepting the
+// same spellings as NormalizeEntityType. Unlike it, unrecognized types are an
+// error listing the valid ones rather than a silent Concept.
+func ParseEntityType(raw string) (EntityType, error) {
+	if t, ok := lookupEntityType(raw); ok {
+		return t, nil
+	}
+	return "", fmt.Errorf("%w %q (valid types: %s)", ErrUnknownEntityType, raw, strings.Join(EntityTypeNames(), ", "))
+}
+
+// lookupEntityType finds raw in the enum or its aliases
+func lookupEntityType(raw string) (EntityType, bool) {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	if key == "" {
+		return "", false
+	}
+	// Try the type as given, then without a plural "s" ("Persons", "Topics")
+	for _, k := range []string{key, strings.TrimSuffix(key, "s")} {
+		for _, t := range EntityTypes {
+			if k == strings.ToLower(string(t)) {
+				return t, true
+			}
+		}
+		if t, ok := entityTypeAliases[k]; ok {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// RelationEdgeTypes are the edge types extractors may emit between entities;
+// meta edges (derived_from, supersedes, ...) are reserved for the kernel
+var RelationEdgeTypes = []EdgeType{
+	EdgeTypePartnerIs, EdgeTypeFamilyMember, EdgeTypeFriendOf,
+	EdgeTypeHasManager, EdgeTypeWorksOn, EdgeTypeWorksAt, EdgeTypeColleague,
+	EdgeTypeLikes, EdgeTypeDislikes, EdgeTypeIsAllergic, EdgeTypePrefers, EdgeTypeHasInterest,
+	EdgeTypeCausedBy, EdgeTypeBlockedBy, EdgeTypeResultsIn,
+	EdgeTypeOccurredOn,
+}
+
+// edgeTypeAliases maps common LLM phrasings to relation edge types
+var edgeTypeAliases = map[string]EdgeType{
+	"manager":       EdgeTypeHasManager,
+	"reports_to":    EdgeTypeHasManager,
+	"managed_by":    EdgeTypeHasManager,
+	"boss":          EdgeTypeHasManager,
+	"partner":       EdgeTypePartnerIs,
+	"married_to":    EdgeTypePartnerIs,
+	"spouse":        EdgeTypePartnerIs,
+	"family":        EdgeTypeFamilyMember,
+	"friend":        EdgeTypeFriendOf,
+	"employed_by":   EdgeTypeWorksAt,
+	"works_for":     EdgeTypeWorksAt,
+	"coworker":      EdgeTypeColleague,
+	"allergic_to":   EdgeTypeIsAllergic,
+	"interested_in": EdgeTypeHasInterest,
+}
+
+// NormalizeEdgeType maps an extractor's relationship type ("has_manager",
+// "HAS_MANAGER", "reports to") to a relation edge type. It reports false for
+// types outside RelationEdgeTypes, which callers should drop.
+func NormalizeEdgeType(raw string) (EdgeType, bool) {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+	if key == "" {
+		return "", false
+	}
+	for _, t := range RelationEdgeTypes {
+		if key == strings.ToLower(string(t)) || key == edgeTypeToPredicateName(t) {
+			return t, true
+		}
+	}
+	t, ok := edgeTypeAliases[key]
+	return t, ok
+}
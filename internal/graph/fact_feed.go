@@ -0,0 +1,188 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultFactPageSize is how many nodes ListFacts returns when no Limit is given
+const DefaultFactPageSize = 500
+
+// factFeedFields is what the fact feed returns for each node
+var factFeedFields = []string{
+	"dgraph.type", "name", "description", "content_type", "tags", "attributes",
+	"created_at", "updated_at", "valid_from", "valid_until", "status",
+	"activation", "access_count", "pinned", "confidence", "namespace",
+}
+
+// factFeedFilter leaves out the metadata nodes kept alongside a namespace's
+// knowledge: its users, groups and conversations
+const factFeedFilter = "NOT type(User) AND NOT type(Group) AND NOT type(Conversation)"
+
+// FactCursor is a place in a namespace's fact feed, which runs in created_at
+// order with ties broken by UID. The zero cursor is the start of the feed.
+type FactCursor struct {
+	CreatedAt time.Time
+	UID       string
+}
+
+// FactCursorOf returns the cursor just past node
+func FactCursorOf(node Node) FactCursor {
+	return FactCursor{CreatedAt: node.CreatedAt, UID: node.UID}
+}
+
+// IsZero reports whether c is the start of the feed
+func (c FactCursor) IsZero() bool {
+	return c.UID == ""
+}
+
+// String encodes c as an opaque token for ParseFactCursor
+func (c FactCursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.UID))
+}
+
+// ParseFactCursor decodes a token from FactCursor.String; "" is the zero cursor
+func ParseFactCursor(token string) (FactCursor, error) {
+	if token == "" {
+		return FactCursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return FactCursor{}, fmt.Errorf("invalid cursor")
+	}
+	created, uid, ok := strings.Cut(string(raw), "|")
+	if !ok || !uidPattern.MatchString(uid) {
+		return FactCursor{}, fmt.Errorf("invalid cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		return FactCursor{}, fmt.Errorf("invalid cursor")
+	}
+	return FactCursor{CreatedAt: createdAt, UID: uid}, nil
+}
+
+// ListFactsOpts selects a page of a namespace's fact feed
+type ListFactsOpts struct {
+	Namespace string
+	After     FactCursor // Nodes after this place in the feed
+	Limit     int        // Most nodes returned (0 = DefaultFactPageSize)
+}
+
+// ListFacts returns the next page of a namespace's non-metadata nodes, in
+// created_at then UID order, with their attributes. Pass the cursor of a
+// page's last node as After to get the next. A page may come back shorter
+// than Limit with more to follow; the feed has ended when a page is empty.
+// Nodes without created_at are not in the feed.
+func (c *Client) ListFacts(ctx context.Context, opts ListFactsOpts) ([]Node, error) {
+	if opts.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if !opts.After.IsZero() && !uidPattern.MatchString(opts.After.UID) {
+		return nil, fmt.Errorf("invalid cursor %q", opts.After.UID)
+	}
+	selection, err := nodeFieldSelection(factFeedFields)
+	if err != nil {
+		return nil, err
+	}
+
+	query := func(root, filter string, createdAt time.Time) ([]Node, error) {
+		q := fmt.Sprintf(`query ListFacts($namespace: string, $created_at: string) {
+		facts(func: eq(namespace, $namespace), %s) @filter(%s AND %s) {
+			%s
+		}
+	}`, root, filter, factFeedFilter, selection)
+		vars := map[string]string{"$namespace": opts.Namespace, "$created_at": createdAt.UTC().Format(time.RFC3339Nano)}
+		resp, err := c.Query(ctx, q, vars)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list facts: %w", err)
+		}
+		var result struct {
+			Facts []Node `json:"facts"`
+		}
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal: %w", err)
+		}
+		return result.Facts, nil
+	}
+
+	return pageFacts(opts,
+		func(createdAt time.Time, afterUID string, n int) ([]Node, error) {
+			return query(fmt.Sprintf("first: %d, after: %s", n, afterUID), "eq(created_at, $created_at)", createdAt)
+		},
+		func(after FactCursor, n int) ([]Node, error) {
+			if after.IsZero() {
+				return query(fmt.Sprintf("orderasc: created_at, first: %d", n), "has(created_at)", time.Time{})
+			}
+			return query(fmt.Sprintf("orderasc: created_at, first: %d", n), "gt(created_at, $created_at)", after.CreatedAt)
+		})
+}
+
+// pageFacts assembles a page of the fact feed from two lookups: factsAt, the
+// first n nodes created at createdAt with a UID after afterUID, in UID order;
+// and factsAfter, the first n nodes created after the cursor's time, in
+// created_at order. factsAfter's order among equal created_at values is not
+// relied on, so a page never ends partway through a run of ties it couldn't
+// see all of.
+func pageFacts(
+	opts ListFactsOpts,
+	factsAt func(createdAt time.Time, afterUID string, n int) ([]Node, error),
+	factsAfter func(after FactCursor, n int) ([]Node, error),
+) ([]Node, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultFactPageSize
+	}
+
+	var page []Node
+	if !opts.After.IsZero() {
+		// The rest of the run of ties the cursor stopped in
+		same, err := factsAt(opts.After.CreatedAt, opts.After.UID, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(same) >= limit {
+			return same[:limit], nil
+		}
+		page = same
+	}
+
+	room := limit - len(page)
+	later, err := factsAfter(opts.After, room)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(later, func(i, j int) bool { return factBefore(later[i], later[j]) })
+	if len(later) < room {
+		return append(page, later...), nil
+	}
+
+	// A full page may have cut the last run of ties short, so it is left
+	// for the next page to start in
+	last := later[len(later)-1].CreatedAt
+	cut := len(later)
+	for cut > 0 && later[cut-1].CreatedAt.Equal(last) {
+		cut--
+	}
+	if cut > 0 || len(page) > 0 {
+		return append(page, later[:cut]...), nil
+	}
+
+	// The whole page is one run of ties; take its first UIDs
+	return factsAt(last, "0x0", room)
+}
+
+// factBefore reports whether a comes before b in the fact feed
+func factBefore(a, b Node) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return uidValue(a.UID) < uidValue(b.UID)
+}
@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// feedLookups answers pageFacts' lookups from nodes, returning ties in
+// reverse UID order from factsAfter as DGraph is free to
+func feedLookups(nodes []Node) (
+	func(time.Time, string, int) ([]Node, error),
+	func(FactCursor, int) ([]Node, error),
+) {
+	factsAt := func(createdAt time.Time, afterUID string, n int) ([]Node, error) {
+		var out []Node
+		for _, node := range nodes {
+			if len(out) < n && node.CreatedAt.Equal(createdAt) && uidValue(node.UID) > uidValue(afterUID) {
+				out = append(out, node)
+			}
+		}
+		return out, nil
+	}
+	factsAfter := func(after FactCursor, n int) ([]Node, error) {
+		var out []Node
+		for i := len(nodes) - 1; i >= 0; i-- {
+			if after.IsZero() || nodes[i].CreatedAt.After(after.CreatedAt) {
+				out = append([]Node{nodes[i]}, out...)
+			}
+		}
+		// Reverse each run of ties, then keep the first n
+		for start := 0; start < len(out); {
+			end := start
+			for end < len(out) && out[end].CreatedAt.Equal(out[start].CreatedAt) {
+				end++
+			}
+			for i, j := start, end-1; i < j; i, j = i+1, j-1 {
+				out[i], out[j] = out[j], out[i]
+			}
+			start = end
+		}
+		if len(out) > n {
+			out = out[:n]
+		}
+		return out, nil
+	}
+	return factsAt, factsAfter
+}
+
+func TestPageFactsVisitsEveryNodeOnce(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Runs of ties shorter than, equal to and longer than a page
+	var nodes []Node
+	for i, run := range []int{1, 3, 4, 1, 9, 2} {
+		for j := 0; j < run; j++ {
+			nodes = append(nodes, Node{
+				UID:       fmt.Sprintf("0x%x", len(nodes)+1),
+				CreatedAt: base.Add(time.Duration(i) * time.Second),
+			})
+		}
+	}
+	factsAt, factsAfter := feedLookups(nodes)
+
+	for _, limit := range []int{1, 2, 4, 5, 100} {
+		var seen []string
+		var after FactCursor
+		for pages := 0; ; pages++ {
+			if pages > len(nodes)*2 {
+				t.Fatalf("limit %d: feed did not end", limit)
+			}
+			page, err := pageFacts(ListFactsOpts{Namespace: "user_a", After: after, Limit: limit}, factsAt, factsAfter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) > limit {
+				t.Fatalf("limit %d: page of %d", limit, len(page))
+			}
+			if len(page) == 0 {
+				break
+			}
+			for _, node := range page {
+				seen = append(seen, node.UID)
+			}
+			// Resume through the token, as a client would
+			if after, err = ParseFactCursor(FactCursorOf(page[len(page)-1]).String()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(seen) != len(nodes) {
+			t.Fatalf("limit %d: visited %v, want all %d nodes", limit, seen, len(nodes))
+		}
+		for i, uid := range seen {
+			if uid != nodes[i].UID {
+				t.Fatalf("limit %d: visited %v, want created_at then UID order", limit, seen)
+			}
+		}
+	}
+}
+
+func TestParseFactCursorRejectsGarbage(t *testing.T) {
+	for _, token := range []string{"not base64!", "bm9waXBl", FactCursor{UID: "0x1; drop"}.String()} {
+		if _, err := ParseFactCursor(token); err == nil {
+			t.Errorf("ParseFactCursor(%q) accepted", token)
+		}
+	}
+}
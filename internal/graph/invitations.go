@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240"
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"go.uber.org/zap"
+
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+// maxAcceptInviteAttempts bounds how often an accept is retried after losing
+// a race with a concurrent accept of the same invitation
+const maxAcceptInviteAttempts = 3
+
+// AcceptInvitation accepts a pending invitation and adds user to workspace.
+// The invitation is read, the membership added and the status set to accepted
+// in one transaction, so a double-click or a retry never adds the user twice
+// and a failure leaves the invitation pending with no member added. Accepting
+// an invitation the user already accepted succeeds without re-adding them.
+func (c *Client) AcceptInvitation(ctx context.Context, invitationUID, userID string) error {
+	accepted, err := acceptInvitation(ctx, func() accessTxn { return c.dgraph().NewTxn() }, invitationUID, userID)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		c.logger.Debug("Invitation already accepted",
+			zap.String("invitation", invitationUID),
+			zap.String("user", userID))
+		return nil
+	}
+
+	c.logger.Info("Invitation accepted",
+		zap.String("invitation", invitationUID),
+		zap.String("user", userID))
+	return nil
+}
+
+// acceptInvitation reports whether this call accepted the invitation (false
+// when it had been accepted already)
+func acceptInvitation(ctx context.Context, newTxn func() accessTxn, invitationUID, userID string) (bool, error) {
+	if !uidPattern.MatchString(invitationUID) {
+		return false, fmt.Errorf("invalid invitation uid %q", invitationUID)
+	}
+
+	for attempt := 0; attempt < maxAcceptInviteAttempts; attempt++ {
+		accepted, err := acceptInvitationTxn(ctx, newTxn(), invitationUID, userID)
+		if !errors.Is(err, dgo.ErrAborted) {
+			return accepted, err
+		}
+		// A concurrent accept committed first; the retry sees it as accepted
+		time.Sleep(time.Millisecond * time.Duration(10*(attempt+1)))
+	}
+	return false, fmt.Errorf("failed to accept invitation after %d attempts (too many conflicts)", maxAcceptInviteAttempts)
+}
+
+// acceptInvitationTxn checks the invitation is pending, adds the membership
+// and marks it accepted, committing all of it together inside txn
+func acceptInvitationTxn(ctx context.Context, txn accessTxn, invitationUID, userID string) (bool, error) {
+	defer txn.Discard(ctx)
+
+	query := fmt.Sprintf(`query AcceptInvite($uid: string, $user: string, $userNS: string) {
+		invite(func: uid($uid)) @filter(type(WorkspaceInvitation)) {
+			workspace_id
+			invitee_user_id
+			role
+			status
+		}
+		user(func: eq(name, $user)) @filter(type(%s) AND eq(namespace, $userNS)) {
+			uid
+		}
+	}`, NodeTypeUser)
+	resp, err := txn.QueryWithVars(ctx, query, map[string]string{
+		"$uid":    invitationUID,
+		"$user":   userID,
+		"$userNS": nsutil.ForUser(userID),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read invitation: %w", err)
+	}
+	var result struct {
+		Invite []WorkspaceInvitation `json:"invite"`
+		User   []struct {
+			UID string `json:"uid"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return false, fmt.Errorf("failed to unmarshal invitation: %w", err)
+	}
+	if len(result.Invite) == 0 {
+		return false, fmt.Errorf("invitation not found: %s", invitationUID)
+	}
+	invite := result.Invite[0]
+
+	// Verify this invitation is for this user
+	if invite.InviteeUserID != userID {
+		return false, fmt.Errorf("invitation is not for user %s", userID)
+	}
+	if invite.Status == "accepted" {
+		return false, nil
+	}
+	if invite.Status != "pending" {
+		return false, fmt.Errorf("invitation is not pending (status: %s)", invite.Status)
+	}
+	if len(result.User) == 0 {
+		return false, fmt.Errorf("user %s not found", userID)
+	}
+
+	resp, err = txn.QueryWithVars(ctx, `query FindGroup($ns: string) {
+		g(func: eq(namespace, $ns)) @filter(type(Group)) {
+			uid
+		}
+	}`, map[string]string{"$ns": invite.WorkspaceID})
+	if err != nil {
+		return false, fmt.Errorf("failed to query group: %w", err)
+	}
+	var group struct {
+		G []struct {
+			UID string `json:"uid"`
+		} `json:"g"`
+	}
+	if err := json.Unmarshal(resp.Json, &group); err != nil {
+		return false, fmt.Errorf("failed to unmarshal group: %w", err)
+	}
+	if len(group.G) == 0 {
+		return false, fmt.Errorf("group %s not found", invite.WorkspaceID)
+	}
+
+	// Add user to workspace with appropriate role
+	predicate := "group_has_member"
+	if invite.Role == "admin" {
+		predicate = "group_has_admin"
+	}
+	nquads := fmt.Sprintf("<%s> <%s> <%s> (joined_at=%s) .\n<%s> <status> \"accepted\" .\n",
+		group.G[0].UID, predicate, result.User[0].UID, edgeTimestamp(), invitationUID)
+
+	if _, err := txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(nquads), CommitNow: true}); err != nil {
+		if errors.Is(err, dgo.ErrAborted) {
+			return false, err
+		}
+		return false, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240"
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// fakeInviteStore holds one invitation and aborts a commit when another
+// commit touched the invitation after the committing transaction read it, as
+// DGraph does for two writes to the same predicate
+type fakeInviteStore struct {
+	mu         sync.Mutex
+	status     string
+	role       string
+	version    int // Commits to the invitation so far
+	memberAdds int
+	txns       int
+	race       bool           // Whether the first two transactions both read before either commits
+	bothRead   sync.WaitGroup // Holds them until they have
+}
+
+func newFakeInviteStore(status, role string) *fakeInviteStore {
+	return &fakeInviteStore{status: status, role: role}
+}
+
+func (s *fakeInviteStore) newTxn() accessTxn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.txns++
+	return &fakeInviteTxn{s: s, racing: s.race && s.txns <= 2}
+}
+
+type fakeInviteTxn struct {
+	s           *fakeInviteStore
+	racing      bool
+	readVersion int
+}
+
+func (t *fakeInviteTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	t.s.mu.Lock()
+	var data []byte
+	if strings.Contains(q, "AcceptInvite") {
+		t.readVersion = t.s.version
+		data, _ = json.Marshal(map[string]interface{}{
+			"invite": []WorkspaceInvitation{{WorkspaceID: "group_team", InviteeUserID: "bob", Role: t.s.role, Status: t.s.status}},
+			"user":   []map[string]string{{"uid": "0xb0b"}},
+		})
+	} else {
+		data, _ = json.Marshal(map[string]interface{}{"g": []map[string]string{{"uid": "0x6"}}})
+	}
+	t.s.mu.Unlock()
+
+	if t.racing && strings.Contains(q, "AcceptInvite") {
+		t.s.bothRead.Done()
+		t.s.bothRead.Wait()
+	}
+	return &api.Response{Json: data}, nil
+}
+
+func (t *fakeInviteTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	if t.s.version != t.readVersion {
+		return nil, dgo.ErrAborted
+	}
+	t.s.version++
+	for _, line := range strings.Split(strings.TrimSpace(string(mu.SetNquads)), "\n") {
+		switch {
+		case strings.Contains(line, "<group_has_member>"), strings.Contains(line, "<group_has_admin>"):
+			t.s.memberAdds++
+		case strings.Contains(line, `<status> "accepted"`):
+			t.s.status = "accepted"
+		}
+	}
+	return &api.Response{}, nil
+}
+
+func (t *fakeInviteTxn) Discard(ctx context.Context) error { return nil }
+
+func TestAcceptInvitationConcurrentAddsMemberOnce(t *testing.T) {
+	store := newFakeInviteStore("pending", "subuser")
+	store.race = true
+	store.bothRead.Add(2)
+
+	accepted := make([]bool, 2)
+	var wg sync.WaitGroup
+	for i := range accepted {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			accepted[i], err = acceptInvitation(context.Background(), store.newTxn, "0x1", "bob")
+			if err != nil {
+				t.Errorf("accept %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if accepted[0] == accepted[1] {
+		t.Errorf("accepted = %v, want exactly one call to have accepted", accepted)
+	}
+	if store.status != "accepted" {
+		t.Errorf("status = %q, want accepted", store.status)
+	}
+	if store.memberAdds != 1 {
+		t.Errorf("membership added %d times, want once", store.memberAdds)
+	}
+}
+
+func TestAcceptInvitationRefusesWithoutWriting(t *testing.T) {
+	for _, tc := range []struct {
+		name, status, uid, user string
+	}{
+		{"declined", "declined", "0x1", "bob"},
+		{"other user", "pending", "0x1", "mallory"},
+		{"malformed uid", "pending", "0x1> <status> \"x", "bob"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeInviteStore(tc.status, "admin")
+			if _, err := acceptInvitation(context.Background(), store.newTxn, tc.uid, tc.user); err == nil {
+				t.Error("accept succeeded")
+			}
+			if store.memberAdds != 0 || store.version != 0 {
+				t.Errorf("invitation written: %d membership adds, %d commits", store.memberAdds, store.version)
+			}
+		})
+	}
+}
+
+func TestNewestPendingInvitationsCollapsesDuplicates(t *testing.T) {
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	invites := []WorkspaceInvitation{
+		{UID: "0x1", WorkspaceID: "group_team", InviteeUserID: "bob", CreatedAt: base, CreatedBy: "alice"},
+		{UID: "0x3", WorkspaceID: "group_home", InviteeUserID: "bob", CreatedAt: base.Add(time.Minute)},
+		// Created by a concurrent invite that findPendingInvitation didn't see
+		{UID: "0x2", WorkspaceID: "group_team", InviteeUserID: "bob", CreatedAt: base.Add(2 * time.Minute), CreatedBy: "carol"},
+	}
+
+	got := newestPendingInvitations(invites, map[string]string{"group_team": "Team", "group_home": "Home"})
+	if len(got) != 2 {
+		t.Fatalf("got %d invitations, want 2: %+v", len(got), got)
+	}
+	if got[0].UID != "0x2" || got[0].CreatedBy != "carol" || got[0].WorkspaceName != "Team" {
+		t.Errorf("first = %+v, want the newer group_team invite named Team", got[0])
+	}
+	if got[1].UID != "0x3" || got[1].WorkspaceName != "Home" {
+		t.Errorf("second = %+v, want the group_home invite named Home", got[1])
+	}
+}
@@ -0,0 +1,428 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MemStore is an in-memory Store for tests of code that would otherwise need a
+// live DGraph. Traversals run the same algorithms as Client over its nodes and
+// edges; searches are case-insensitive substring matches.
+type MemStore struct {
+	// QueryFunc answers Query, which MemStore cannot interpret. Without one,
+	// every query gets "{}", i.e. no results.
+	QueryFunc func(query string, vars map[string]string) ([]byte, error)
+
+	mu      sync.Mutex
+	nextUID uint64
+	nodes   map[string]*Node
+	edges   map[string][]memEdge // From UID -> outgoing edges, in creation order
+	members map[string][]string  // User ID -> workspace namespaces
+}
+
+// memEdge is an outgoing edge held by MemStore
+type memEdge struct {
+	to        string
+	predicate string
+	weight    float64
+}
+
+var _ Store = (*MemStore)(nil)
+
+// NewMemStore returns an empty MemStore
+func NewMemStore() *MemStore {
+	return &MemStore{
+		nodes:   make(map[string]*Node),
+		edges:   make(map[string][]memEdge),
+		members: make(map[string][]string),
+	}
+}
+
+// AddWorkspaceMember makes userID a member of the workspace namespace, as far
+// as IsWorkspaceMember and ListUserGroups are concerned
+func (s *MemStore) AddWorkspaceMember(workspaceNS, userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[userID] = append(s.members[userID], workspaceNS)
+}
+
+// CreateNode stores a copy of node under a new UID, stamped as Client stamps it
+func (s *MemStore) CreateNode(ctx context.Context, node *Node) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	node.CreatedAt, node.UpdatedAt, node.LastAccessed = now, now, now
+	if node.Activation == 0 {
+		node.Activation = 0.5
+	}
+	s.nextUID++
+	uid := "0x" + strconv.FormatUint(s.nextUID, 16)
+	stored := copyNode(*node)
+	stored.UID = uid
+	s.nodes[uid] = &stored
+	return uid, nil
+}
+
+// GetNode returns a copy of the node
+func (s *MemStore) GetNode(ctx context.Context, uid string) (*Node, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.nodes[uid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, uid)
+	}
+	node := copyNode(*n)
+	return &node, nil
+}
+
+// GetNodesByUIDs returns the nodes that exist, whole; fields are ignored
+func (s *MemStore) GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]Node, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var nodes []Node
+	for _, uid := range uids {
+		if n, ok := s.nodes[uid]; ok {
+			nodes = append(nodes, copyNode(*n))
+		}
+	}
+	return nodes, nil
+}
+
+// UpdateDescription replaces the node's description
+func (s *MemStore) UpdateDescription(ctx context.Context, uid string, description string) error {
+	return s.update(uid, func(n *Node) { n.Description = description })
+}
+
+// SetAttributes sets attributes on the node; an empty value removes the key
+func (s *MemStore) SetAttributes(ctx context.Context, uid string, attrs map[string]string) error {
+	return s.update(uid, func(n *Node) {
+		for k, v := range attrs {
+			if v == "" {
+				delete(n.Attributes, k)
+				continue
+			}
+			if n.Attributes == nil {
+				n.Attributes = make(map[string]string)
+			}
+			n.Attributes[k] = v
+		}
+	})
+}
+
+// update applies change to the stored node and bumps its UpdatedAt
+func (s *MemStore) update(uid string, change func(n *Node)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, ok := s.nodes[uid]
+	if !ok {
+		return fmt.Errorf("node not found: %s", uid)
+	}
+	change(n)
+	n.UpdatedAt = time.Now()
+	return nil
+}
+
+// CreateEdge adds an edge with the default weight of 0.5
+func (s *MemStore) CreateEdge(ctx context.Context, fromUID, toUID string, edgeType EdgeType, status EdgeStatus) error {
+	if !uidPattern.MatchString(fromUID) || !uidPattern.MatchString(toUID) {
+		return fmt.Errorf("invalid uid")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.edges[fromUID] = append(s.edges[fromUID], memEdge{
+		to:        toUID,
+		predicate: edgeTypeToPredicateName(edgeType),
+		weight:    0.5,
+	})
+	return nil
+}
+
+// FindNodeByName returns the namespace's node of nodeType named name, or nil
+func (s *MemStore) FindNodeByName(ctx context.Context, namespace string, name string, nodeType NodeType) (*Node, error) {
+	for _, n := range s.matching(namespace, 0, func(n *Node) bool {
+		return n.Name == name && n.HasType(nodeType)
+	}) {
+		return &n, nil
+	}
+	return nil, nil
+}
+
+// FindByAttributes returns nodes whose attributes include every pair in attrs
+// and, when name is set, whose name contains one of its words
+func (s *MemStore) FindByAttributes(ctx context.Context, namespace string, attrs map[string]string, name string, limit int) ([]Node, error) {
+	if len(attrs) == 0 {
+		return nil, fmt.Errorf("at least one attribute is required")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	terms := strings.Fields(strings.ToLower(name))
+	return s.matching(namespace, limit, func(n *Node) bool {
+		for k, v := range attrs {
+			if n.Attributes[k] != v {
+				return false
+			}
+		}
+		if len(terms) == 0 {
+			return true
+		}
+		nodeName := strings.ToLower(n.Name)
+		for _, term := range terms {
+			if strings.Contains(nodeName, term) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// SearchNodes returns nodes whose name or description contains any word of
+// queryStr; an empty or "*" query lists the namespace's named nodes
+func (s *MemStore) SearchNodes(ctx context.Context, queryStr, namespace string) ([]Node, error) {
+	if isSearchAll(queryStr) {
+		return s.matching(namespace, SearchAllLimit, func(n *Node) bool { return n.Name != "" }), nil
+	}
+	terms := strings.Fields(strings.ToLower(queryStr))
+	return s.matching(namespace, 0, func(n *Node) bool {
+		text := strings.ToLower(n.Name + " " + n.Description)
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// ListNodes pages through the namespace's nodes in UID order
+func (s *MemStore) ListNodes(ctx context.Context, opts ListNodesOpts) ([]Node, error) {
+	if opts.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultNodePageSize
+	}
+	after := uint64(0)
+	if opts.After != "" {
+		if !uidPattern.MatchString(opts.After) {
+			return nil, fmt.Errorf("invalid cursor %q", opts.After)
+		}
+		after, _ = strconv.ParseUint(opts.After[2:], 16, 64)
+	}
+	nodes := s.matching(opts.Namespace, 0, func(n *Node) bool {
+		return uidValue(n.UID) > after && (opts.NodeType == "" || n.HasType(opts.NodeType)) &&
+			(opts.ContentType == "" || n.ContentType == opts.ContentType)
+	})
+	if opts.Offset >= len(nodes) {
+		return nil, nil
+	}
+	nodes = nodes[max(opts.Offset, 0):]
+	if len(nodes) > opts.Limit {
+		nodes = nodes[:opts.Limit]
+	}
+	return nodes, nil
+}
+
+// ExpandFromNode runs Client's breadth-first expansion over the stored edges
+func (s *MemStore) ExpandFromNode(ctx context.Context, opts ExpandOpts) (*ExpandResult, error) {
+	return expandFromNode(ctx, opts, s.expandQuery)
+}
+
+// expandQuery answers expansion queries the way Client.expandQuery's DQL does
+func (s *MemStore) expandQuery(ctx context.Context, uids, predicates []string, namespace string) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []json.RawMessage
+	for _, uid := range uids {
+		n, ok := s.nodes[uid]
+		if !ok {
+			continue
+		}
+		node := map[string]interface{}{
+			"uid": n.UID, "dgraph.type": n.DType, "name": n.Name,
+			"description": n.Description, "namespace": n.Namespace, "activation": n.Activation,
+		}
+		for _, pred := range predicates {
+			var children []map[string]interface{}
+			for _, e := range s.edgesAlong(uid, pred) {
+				child, ok := s.nodes[e.to]
+				if !ok || (namespace != "" && child.Namespace != namespace) {
+					continue
+				}
+				children = append(children, map[string]interface{}{
+					"uid": child.UID, "dgraph.type": child.DType, "name": child.Name,
+					"description": child.Description, "namespace": child.Namespace,
+					"activation": child.Activation, pred + "|weight": e.weight,
+				})
+			}
+			if children != nil {
+				node[pred] = children
+			}
+		}
+		raw, err := json.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, raw)
+	}
+	return out, nil
+}
+
+// edgesAlong returns uid's edges along pred. A reverse predicate ("~pred")
+// gives the pred edges into uid, with to set to the node they come from.
+func (s *MemStore) edgesAlong(uid, pred string) []memEdge {
+	var out []memEdge
+	forward := strings.TrimPrefix(pred, "~")
+	if forward == pred {
+		for _, e := range s.edges[uid] {
+			if e.predicate == pred {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+
+	sources := make([]string, 0, len(s.edges))
+	for from := range s.edges {
+		sources = append(sources, from)
+	}
+	sort.Slice(sources, func(i, j int) bool { return uidValue(sources[i]) < uidValue(sources[j]) })
+	for _, from := range sources {
+		for _, e := range s.edges[from] {
+			if e.to == uid && e.predicate == forward {
+				out = append(out, memEdge{to: from, predicate: pred, weight: e.weight})
+			}
+		}
+	}
+	return out
+}
+
+// SpreadActivation runs Client's spreading activation over the stored edges
+func (s *MemStore) SpreadActivation(ctx context.Context, opts SpreadActivationOpts) ([]ActivatedNode, error) {
+	page, err := s.SpreadActivationPage(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return page.Nodes, nil
+}
+
+// SpreadActivationPage returns one page of SpreadActivation's results
+func (s *MemStore) SpreadActivationPage(ctx context.Context, opts SpreadActivationOpts) (*ActivationPage, error) {
+	if opts.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required for activation spreading")
+	}
+	return spreadActivationPage(ctx, opts, s.GetNode, s.neighborUIDs, zap.NewNop())
+}
+
+// neighborUIDs returns the nodes uid has edges to within namespace
+func (s *MemStore) neighborUIDs(ctx context.Context, uid, namespace string) ([]WeightedNeighbor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var neighbors []WeightedNeighbor
+	for _, e := range s.edges[uid] {
+		if n, ok := s.nodes[e.to]; ok && n.Namespace == namespace {
+			neighbors = append(neighbors, WeightedNeighbor{UID: e.to, Weight: e.weight})
+		}
+	}
+	return neighbors, nil
+}
+
+// IncrementAccessCounts boosts each node as Client does
+func (s *MemStore) IncrementAccessCounts(ctx context.Context, uids []string, config ActivationConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		n, ok := s.nodes[uid]
+		if !ok || seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		n.Activation += config.BoostPerAccess
+		if n.Activation > config.MaxActivation {
+			n.Activation = config.MaxActivation
+		}
+		n.AccessCount++
+		n.LastAccessed = time.Now()
+	}
+	return nil
+}
+
+// IsWorkspaceMember reports whether AddWorkspaceMember added userID to workspaceNS
+func (s *MemStore) IsWorkspaceMember(ctx context.Context, workspaceNS, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ns := range s.members[userID] {
+		if ns == workspaceNS {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListUserGroups returns the workspaces AddWorkspaceMember added userID to
+func (s *MemStore) ListUserGroups(ctx context.Context, userID string) ([]Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var groups []Group
+	for _, ns := range s.members[userID] {
+		groups = append(groups, Group{Namespace: ns})
+	}
+	return groups, nil
+}
+
+// Query answers with QueryFunc, or with no results when it is unset
+func (s *MemStore) Query(ctx context.Context, query string, vars map[string]string) ([]byte, error) {
+	if s.QueryFunc != nil {
+		return s.QueryFunc(query, vars)
+	}
+	return []byte("{}"), nil
+}
+
+// matching returns copies of the namespace's nodes that match, in UID order,
+// at most limit of them (0 = all)
+func (s *MemStore) matching(namespace string, limit int, match func(n *Node) bool) []Node {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var nodes []Node
+	for _, n := range s.nodes {
+		if n.Namespace == namespace && match(n) {
+			nodes = append(nodes, copyNode(*n))
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return uidValue(nodes[i].UID) < uidValue(nodes[j].UID) })
+	if limit > 0 && len(nodes) > limit {
+		nodes = nodes[:limit]
+	}
+	return nodes
+}
+
+// uidValue is the number a UID literal names
+func uidValue(uid string) uint64 {
+	v, _ := strconv.ParseUint(strings.TrimPrefix(uid, "0x"), 16, 64)
+	return v
+}
+
+// copyNode copies n deeply enough that callers can't change a stored node
+func copyNode(n Node) Node {
+	n.DType = append([]string(nil), n.DType...)
+	n.Tags = append([]string(nil), n.Tags...)
+	if n.Attributes != nil {
+		attrs := make(map[string]string, len(n.Attributes))
+		for k, v := range n.Attributes {
+			attrs[k] = v
+		}
+		n.Attributes = attrs
+	}
+	return n
+}
@@ -0,0 +1,521 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"go.uber.org/zap"
+)
+
+// Migration is one ordered, idempotent schema change. Migrations may add
+// predicates and types or change indexes; changing the type or list-ness of an
+// existing predicate is refused because DGraph would drop incompatible data.
+type Migration struct {
+	Version     int
+	Description string
+	Schema      string // DGraph schema fragment applied with Alter
+	// Optional migrations log a failed Alter and still advance the version
+	Optional bool
+	// Backfill, when set, rewrites existing data to the new schema after the
+	// Alter; it must be safe to run again if it fails partway
+	Backfill func(ctx context.Context, c *Client) error
+}
+
+// migrations are applied in order; append new ones with the next version and
+// never edit one that has shipped
+var migrations = []Migration{
+	{Version: 1, Description: "baseline knowledge graph schema", Schema: baselineSchema},
+	{Version: 2, Description: "reverse edge for user settings", Schema: `user_settings: uid @reverse .`, Optional: true},
+	{Version: 3, Description: "structured key/value attributes", Schema: attributeSchema},
+	{Version: 4, Description: "index updated_at for change queries", Schema: `updated_at: datetime @index(hour) .`},
+	{Version: 5, Description: "persisted conversation turns", Schema: conversationTurnSchema},
+	{Version: 6, Description: "declare generic related_to and part_of edges", Schema: genericEdgeSchema},
+	{Version: 7, Description: "normalized search terms for keyword search", Schema: `search_terms: string @index(term) .`},
+	{Version: 8, Description: "pinned nodes exempt from decay and pruning", Schema: `pinned: bool @index(bool) .`},
+	{Version: 9, Description: "documents typed by content_type", Schema: `content_type: string @index(exact) .`,
+		Backfill: backfillDocumentContentTypes},
+	{Version: 10, Description: "legacy attributes moved to has_attribute", Schema: `attributes: [string] .`,
+		Backfill: backfillLegacyAttributes},
+	{Version: 11, Description: "search_terms declared on node types", Schema: searchTermsSchema,
+		Backfill: backfillSearchTerms},
+}
+
+// LatestSchemaVersion is the schema version this build migrates to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// versionSchema holds the bookkeeping predicates; it is applied before anything else
+const versionSchema = `
+	schema_version: int .
+	schema_migrated_at: datetime .
+	type SchemaVersion {
+		schema_version
+		schema_migrated_at
+	}
+`
+
+// migrateSchema applies the migrations newer than the deployed schema version
+// and records each one as it succeeds
+func (c *Client) migrateSchema(ctx context.Context) error {
+	if err := c.dgraph().Alter(ctx, &api.Operation{Schema: versionSchema}); err != nil {
+		return fmt.Errorf("failed to create schema version predicates: %w", err)
+	}
+
+	current, err := c.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	latest := LatestSchemaVersion()
+	if current > latest {
+		c.logger.Warn("Deployed DGraph schema is newer than this build",
+			zap.Int("deployed_version", current),
+			zap.Int("build_version", latest))
+		return nil
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := c.checkCompatible(ctx, m); err != nil {
+			return err
+		}
+		if err := c.dgraph().Alter(ctx, &api.Operation{Schema: m.Schema}); err != nil {
+			if !m.Optional {
+				return fmt.Errorf("schema migration %d (%s) failed: %w", m.Version, m.Description, err)
+			}
+			c.logger.Debug("Optional schema migration failed, continuing",
+				zap.Int("version", m.Version),
+				zap.Error(err))
+		}
+		if m.Backfill != nil {
+			if err := m.Backfill(ctx, c); err != nil {
+				return fmt.Errorf("schema migration %d (%s) backfill failed: %w", m.Version, m.Description, err)
+			}
+		}
+		if err := c.setSchemaVersion(ctx, m.Version); err != nil {
+			return err
+		}
+		c.logger.Info("Applied schema migration",
+			zap.Int("version", m.Version),
+			zap.String("description", m.Description))
+	}
+
+	c.logger.Info("DGraph schema up to date", zap.Int("version", latest))
+	return nil
+}
+
+// SchemaVersion returns the deployed schema version (0 when never migrated)
+func (c *Client) SchemaVersion(ctx context.Context) (int, error) {
+	resp, err := c.dgraph().NewReadOnlyTxn().Query(ctx, `{ v(func: has(schema_version)) { schema_version } }`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	var result struct {
+		V []struct {
+			SchemaVersion int `json:"schema_version"`
+		} `json:"v"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, fmt.Errorf("failed to decode schema version: %w", err)
+	}
+	version := 0
+	for _, v := range result.V {
+		if v.SchemaVersion > version {
+			version = v.SchemaVersion
+		}
+	}
+	return version, nil
+}
+
+// setSchemaVersion upserts the single SchemaVersion node
+func (c *Client) setSchemaVersion(ctx context.Context, version int) error {
+	req := &api.Request{
+		Query: `query { v as var(func: has(schema_version)) }`,
+		Mutations: []*api.Mutation{{
+			SetNquads: []byte(fmt.Sprintf(`uid(v) <schema_version> "%d" .
+uid(v) <schema_migrated_at> "%s" .
+uid(v) <dgraph.type> "SchemaVersion" .
+`, version, time.Now().UTC().Format(time.RFC3339))),
+		}},
+		CommitNow: true,
+	}
+	if _, err := c.dgraph().NewTxn().Do(ctx, req); err != nil {
+		return fmt.Errorf("failed to record schema version %d: %w", version, err)
+	}
+	return nil
+}
+
+// predicateDef is a predicate declaration parsed from a schema fragment
+type predicateDef struct {
+	Name string
+	Type string
+	List bool
+}
+
+// predicateLine matches "name: [type] @directives ." declarations
+var predicateLine = regexp.MustCompile(`^\s*([\w.~]+)\s*:\s*(\[?)\s*(\w+)\s*\]?`)
+
+// parsePredicates extracts predicate declarations, skipping type blocks and comments
+func parsePredicates(schema string) []predicateDef {
+	var defs []predicateDef
+	inType := false
+	for _, line := range strings.Split(schema, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "type "):
+			inType = !strings.HasSuffix(line, "}")
+			continue
+		case inType:
+			inType = line != "}"
+			continue
+		}
+		if m := predicateLine.FindStringSubmatch(line); m != nil {
+			defs = append(defs, predicateDef{Name: m[1], Type: m[3], List: m[2] == "["})
+		}
+	}
+	return defs
+}
+
+// checkCompatible refuses a migration that would change the type or list-ness
+// of an existing predicate. Index, tokenizer and @reverse changes are allowed.
+func (c *Client) checkCompatible(ctx context.Context, m Migration) error {
+	defs := parsePredicates(m.Schema)
+	if len(defs) == 0 {
+		return nil
+	}
+	names := make([]string, len(defs))
+	for i, d := range defs {
+		names[i] = d.Name
+	}
+
+	resp, err := c.dgraph().NewReadOnlyTxn().Query(ctx, fmt.Sprintf("schema(pred: [%s]) { type list }", strings.Join(names, ", ")))
+	if err != nil {
+		return fmt.Errorf("failed to read schema for migration %d: %w", m.Version, err)
+	}
+	var result struct {
+		Schema []struct {
+			Predicate string `json:"predicate"`
+			Type      string `json:"type"`
+			List      bool   `json:"list"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return fmt.Errorf("failed to decode schema for migration %d: %w", m.Version, err)
+	}
+
+	existing := make(map[string]predicateDef, len(result.Schema))
+	for _, s := range result.Schema {
+		existing[s.Predicate] = predicateDef{Name: s.Predicate, Type: s.Type, List: s.List}
+	}
+	for _, d := range defs {
+		cur, ok := existing[d.Name]
+		if !ok {
+			continue
+		}
+		if cur.Type != d.Type || cur.List != d.List {
+			return fmt.Errorf("schema migration %d (%s) changes predicate %q from %s to %s; "+
+				"incompatible changes need a data migration", m.Version, m.Description, d.Name, describeType(cur), describeType(d))
+		}
+	}
+	return nil
+}
+
+func describeType(d predicateDef) string {
+	if d.List {
+		return "[" + d.Type + "]"
+	}
+	return d.Type
+}
+
+// genericEdgeSchema declares the catch-all edges clients could already write,
+// which were created untyped and without a reverse index
+const genericEdgeSchema = `
+	related_to: [uid] @reverse .
+	part_of: [uid] @reverse .
+`
+
+// attributeSchema stores attributes as indexed child nodes; User and Entity are
+// redeclared with has_attribute so type-based deletes include the edge
+const attributeSchema = `
+	has_attribute: [uid] @reverse .
+	attr_key: string @index(exact) .
+	attr_value: string @index(exact, term) .
+
+	type Attribute {
+		attr_key
+		attr_value
+	}
+
+	type User {
+		name
+		description
+		attributes
+		has_attribute
+		created_at
+		updated_at
+		last_accessed
+		activation
+		access_count
+	}
+
+	type Entity {
+		name
+		description
+		attributes
+		has_attribute
+		created_at
+		updated_at
+		last_accessed
+		activation
+		access_count
+		entity_type
+		tags
+	}
+`
+
+// conversationTurnSchema stores each chat turn so conversations outlive the
+// agent process; turns are found by the existing conversation_id index
+const conversationTurnSchema = `
+	turn_user: string @index(exact) .
+	turn_at: datetime @index(hour) .
+	turn_user_query: string .
+	turn_response: string .
+	turn_latency_ms: int .
+
+	type ConversationTurn {
+		conversation_id
+		namespace
+		turn_user
+		turn_at
+		turn_user_query
+		turn_response
+		turn_latency_ms
+	}
+`
+
+// baselineSchema is the schema as of the introduction of versioned migrations
+const baselineSchema = `
+		# Edges
+		group_has_admin: [uid] @reverse .
+		group_has_member: [uid] @reverse .
+
+		# Node types
+		# Group Management (V2)
+
+		type Group {
+			name
+			description
+			namespace
+			created_by
+			created_at
+			updated_at
+			group_has_admin
+			group_has_member
+		}
+
+		type User {
+			name
+			description
+			attributes
+			created_at
+			updated_at
+			last_accessed
+			activation
+			access_count
+		}
+
+		type UserSettings {
+			nim_api_key_encrypted
+			openai_api_key_encrypted
+			anthropic_api_key_encrypted
+			theme
+			notifications_enabled
+			updated_at
+		}
+
+		type Entity {
+			name
+			description
+			attributes
+			created_at
+			updated_at
+			last_accessed
+			activation
+			access_count
+			entity_type
+			tags
+		}
+
+		type Event {
+			name
+			description
+			attributes
+			created_at
+			updated_at
+			occurred_at
+			sentiment
+		}
+
+		type Insight {
+			name
+			description
+			insight_type
+			summary
+			action_suggestion
+			source_nodes
+			created_at
+			confidence
+		}
+
+		type Pattern {
+			name
+			description
+			pattern_type
+			trigger_nodes
+			frequency
+			confidence_score
+			predicted_action
+			created_at
+		}
+
+		type Fact {
+			name
+			description
+			fact_value
+			created_at
+			valid_from
+			valid_until
+			status
+		}
+
+		# Predicates with indexes
+		name: string @index(hash) @index(fulltext) .
+		description: string @index(fulltext) .
+		attributes: [string] .
+		tags: [string] .
+		entity_type: string @index(exact) .
+		namespace: string @index(exact) .
+		created_by: string @index(exact) .
+		
+		# Temporal predicates
+		created_at: datetime @index(hour) .
+		updated_at: datetime .
+		last_accessed: datetime @index(hour) .
+		occurred_at: datetime .
+		valid_from: datetime @index(hour) .
+		valid_until: datetime @index(hour) .
+		
+		# Activation and prioritization (indexed for reordering queries)
+		activation: float @index(float) .
+		access_count: int @index(int) .
+		traversal_cost: float .
+		
+		# Insight/Pattern specific
+		insight_type: string .
+		pattern_type: string .
+		summary: string .
+		action_suggestion: string .
+		predicted_action: string .
+		frequency: int .
+		confidence: float .
+		confidence_score: float .
+		fact_value: string .
+		status: string @index(exact) .
+		sentiment: string .
+		
+		# Source tracking
+		source_conversation_id: string .
+		source_nodes: [uid] .
+		trigger_nodes: [uid] .
+		
+		# Relationship predicates (edges)
+		partner_is: uid @reverse .
+		family_member: [uid] @reverse .
+		friend_of: [uid] @reverse .
+		has_manager: uid @reverse .
+		works_on: [uid] @reverse .
+		works_at: uid @reverse .
+		colleague: [uid] @reverse .
+		likes: [uid] @reverse .
+		dislikes: [uid] @reverse .
+		is_allergic_to: [uid] @reverse .
+		prefers: [uid] @reverse .
+		has_interest: [uid] @reverse .
+		caused_by: [uid] @reverse .
+		blocked_by: [uid] @reverse .
+		results_in: [uid] @reverse .
+		contradicts: [uid] @reverse .
+		occurred_on: uid @reverse .
+		scheduled_at: datetime .
+		derived_from: [uid] @reverse .
+		synthesized_from: [uid] @reverse .
+		supersedes: uid @reverse .
+		knows: [uid] @reverse .
+		
+		# Edge metadata predicates
+		edge_status: string .
+		edge_created_at: datetime .
+		edge_activation: float .
+		edge_confidence: float .
+
+		# Workspace Collaboration Types
+		type WorkspaceInvitation {
+			workspace_id
+			invitee_user_id
+			role
+			status
+			created_at
+			created_by
+		}
+
+		type ShareLink {
+			workspace_id
+			token
+			role
+			max_uses
+			current_uses
+			expires_at
+			is_active
+			created_at
+			created_by
+		}
+
+		type SharedConversation {
+			conversation_id
+			shared_with
+			shared_by
+			shared_at
+		}
+
+		# Workspace Collaboration Predicates
+		workspace_id: string @index(exact) .
+		invitee_user_id: string @index(exact) .
+		token: string @index(exact) .
+		max_uses: int .
+		current_uses: int .
+		expires_at: datetime .
+		is_active: bool @index(bool) .
+		role: string @index(exact) .
+		conversation_id: string @index(exact) .
+		shared_with: uid @reverse .
+		shared_by: string @index(exact) .
+		shared_at: datetime .
+
+		# User Settings Predicates
+		nim_api_key_encrypted: string .
+		openai_api_key_encrypted: string .
+		anthropic_api_key_encrypted: string .
+		glm_api_key_encrypted: string .
+		theme: string .
+		notifications_enabled: bool .
+
+	`
@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Neighbor directions: an outgoing edge points from the node to the
+// neighbor, an incoming one from the neighbor to the node
+const (
+	NeighborOut = "out"
+	NeighborIn  = "in"
+)
+
+// Neighbor is a node one edge away from another, with the edge linking them
+type Neighbor struct {
+	Node       Node     `json:"node"`
+	EdgeType   EdgeType `json:"edge_type"`
+	Predicate  string   `json:"predicate"` // "~has_manager" for an incoming has_manager edge
+	Direction  string   `json:"direction"` // NeighborOut or NeighborIn
+	Label      string   `json:"label"`     // How the node relates to the neighbor ("has manager", "manages")
+	Weight     float64  `json:"weight,omitempty"`
+	Confidence float64  `json:"confidence,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+}
+
+// neighborFacets are the edge facets read for each neighbor
+const neighborFacets = "weight, confidence, created_at"
+
+// GetNeighbors returns the nodes of a namespace linked to uid by a registered
+// edge type, in either direction, strongest edges first. A neighbor linked by
+// several edges appears once per edge. limit <= 0 returns them all.
+func (c *Client) GetNeighbors(ctx context.Context, namespace, uid string, limit int) ([]Neighbor, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if !uidPattern.MatchString(uid) {
+		return nil, fmt.Errorf("invalid uid %q", uid)
+	}
+
+	var edges strings.Builder
+	for _, info := range edgeTypeRegistry {
+		for _, pred := range []string{info.Predicate, info.Reverse} {
+			fmt.Fprintf(&edges, `
+			%s @facets(%s) @filter(eq(namespace, $namespace)) { uid name description dgraph.type namespace }`,
+				pred, neighborFacets)
+		}
+	}
+	query := fmt.Sprintf(`query Neighbors($namespace: string) {
+		node(func: uid(%s)) @filter(eq(namespace, $namespace)) {
+			uid%s
+		}
+	}`, uid, edges.String())
+
+	resp, err := c.dgraph().NewReadOnlyTxn().QueryWithVars(ctx, query, map[string]string{"$namespace": namespace})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query neighbors: %w", err)
+	}
+	neighbors, err := parseNeighbors(resp.Json)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(neighbors) > limit {
+		neighbors = neighbors[:limit]
+	}
+	return neighbors, nil
+}
+
+// parseNeighbors reads the edges of a Neighbors query, strongest first
+func parseNeighbors(data []byte) ([]Neighbor, error) {
+	var result struct {
+		Node []map[string]json.RawMessage `json:"node"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal neighbors: %w", err)
+	}
+	if len(result.Node) == 0 {
+		return nil, nil
+	}
+
+	var neighbors []Neighbor
+	for _, info := range edgeTypeRegistry {
+		for _, pred := range []string{info.Predicate, info.Reverse} {
+			raw, ok := result.Node[0][pred]
+			if !ok {
+				continue
+			}
+			var targets []json.RawMessage
+			if err := json.Unmarshal(raw, &targets); err != nil {
+				targets = []json.RawMessage{raw} // A single target may come back unwrapped
+			}
+
+			for _, target := range targets {
+				var n Neighbor
+				if err := json.Unmarshal(target, &n.Node); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal neighbor: %w", err)
+				}
+				var facets map[string]interface{}
+				_ = json.Unmarshal(target, &facets)
+				n.Weight, _ = facets[pred+"|weight"].(float64)
+				n.Confidence, _ = facets[pred+"|confidence"].(float64)
+				n.CreatedAt, _ = facets[pred+"|created_at"].(string)
+
+				n.EdgeType, n.Predicate = info.Type, pred
+				if pred == info.Predicate {
+					n.Direction, n.Label = NeighborOut, info.Label
+				} else {
+					n.Direction, n.Label = NeighborIn, info.ReverseLabel
+				}
+				neighbors = append(neighbors, n)
+			}
+		}
+	}
+
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].Weight > neighbors[j].Weight
+	})
+	return neighbors, nil
+}
@@ -0,0 +1,38 @@
+package graph
+
+import "testing"
+
+func TestParseNeighborsKeepsEdgeTypeAndFacets(t *testing.T) {
+	data := []byte(`{"node": [{
+		"uid": "0x1",
+		"has_manager": [{"uid": "0x2", "name": "Bob", "dgraph.type": ["Entity"], "has_manager|weight": 0.4}],
+		"~works_at": {"uid": "0x3", "name": "Acme", "~works_at|weight": 0.9, "~works_at|confidence": 0.8, "~works_at|created_at": "2026-01-02T00:00:00Z"}
+	}]}`)
+
+	neighbors, err := parseNeighbors(data)
+	if err != nil {
+		t.Fatalf("parseNeighbors: %v", err)
+	}
+	if len(neighbors) != 2 {
+		t.Fatalf("parseNeighbors = %d neighbors, want 2", len(neighbors))
+	}
+
+	employer := neighbors[0] // Strongest first
+	if employer.Node.UID != "0x3" || employer.EdgeType != EdgeTypeWorksAt || employer.Direction != NeighborIn ||
+		employer.Predicate != "~works_at" || employer.Label != "employs" {
+		t.Errorf("incoming neighbor = %+v", employer)
+	}
+	if employer.Weight != 0.9 || employer.Confidence != 0.8 || employer.CreatedAt != "2026-01-02T00:00:00Z" {
+		t.Errorf("incoming neighbor facets = %v, %v, %q", employer.Weight, employer.Confidence, employer.CreatedAt)
+	}
+
+	manager := neighbors[1]
+	if manager.Node.Name != "Bob" || manager.EdgeType != EdgeTypeHasManager || manager.Direction != NeighborOut ||
+		manager.Label != "has manager" || manager.Weight != 0.4 || manager.Node.GetType() != NodeTypeEntity {
+		t.Errorf("outgoing neighbor = %+v", manager)
+	}
+
+	if neighbors, err := parseNeighbors([]byte(`{"node": []}`)); err != nil || neighbors != nil {
+		t.Errorf("missing node = %v, %v; want none", neighbors, err)
+	}
+}
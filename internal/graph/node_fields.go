@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// nodeFieldBlocks maps the node fields callers may select to their query
+// blocks. Each is a predicate declared by a migration (attributes expands to
+// the has_attribute children). Credentials and billing predicates are
+// deliberately absent.
+var nodeFieldBlocks = map[string]string{
+	"dgraph.type":            "dgraph.type",
+	"name":                   "name",
+	"description":            "description",
+	"content_type":           "content_type",
+	"tags":                   "tags",
+	"attributes":             attributeFields,
+	"created_at":             "created_at",
+	"updated_at":             "updated_at",
+	"last_accessed":          "last_accessed",
+	"valid_from":             "valid_from",
+	"valid_until":            "valid_until",
+	"status":                 "status",
+	"activation":             "activation",
+	"access_count":           "access_count",
+	"pinned":                 "pinned",
+	"confidence":             "confidence",
+	"namespace":              "namespace",
+	"source_conversation_id": "source_conversation_id",
+}
+
+// Node projections for GetNodesByUIDs. uid is always returned.
+var (
+	// NodeFieldsDefault is what GetNodesByUIDs returns when no fields are given
+	NodeFieldsDefault = []string{
+		"dgraph.type", "name", "description", "tags", "activation", "created_at",
+		"namespace", "valid_from", "valid_until", "status",
+	}
+
+	// NodeFieldsSummary is enough to render a node in a list
+	NodeFieldsSummary = []string{"dgraph.type", "name", "description", "tags", "activation", "namespace"}
+
+	// NodeFieldsConsultation is what consultation ranks and filters on: the
+	// summary plus the validity window, creation time and pin
+	NodeFieldsConsultation = []string{
+		"dgraph.type", "name", "description", "tags", "activation", "namespace",
+		"created_at", "valid_from", "valid_until", "status", "pinned",
+	}
+
+	// NodeFieldsDetail is everything an entity detail view shows
+	NodeFieldsDetail = []string{
+		"dgraph.type", "name", "description", "tags", "attributes",
+		"created_at", "updated_at", "last_accessed", "valid_from", "valid_until", "status",
+		"activation", "access_count", "pinned", "confidence", "namespace", "source_conversation_id",
+	}
+)
+
+// nodeFieldSelection validates fields and returns the query body selecting
+// them; nil or empty selects NodeFieldsDefault
+func nodeFieldSelection(fields []string) (string, error) {
+	if len(fields) == 0 {
+		fields = NodeFieldsDefault
+	}
+
+	blocks := []string{"uid"}
+	seen := map[string]bool{"uid": true}
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		block, ok := nodeFieldBlocks[field]
+		if !ok {
+			return "", fmt.Errorf("unknown node field %q", field)
+		}
+		seen[field] = true
+		blocks = append(blocks, block)
+	}
+	return strings.Join(blocks, "\n\t\t\t"), nil
+}
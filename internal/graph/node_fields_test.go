@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNodeFieldsDeclared keeps the selectable fields in step with the schema
+func TestNodeFieldsDeclared(t *testing.T) {
+	declared := make(map[string]bool)
+	for _, m := range migrations {
+		for _, def := range parsePredicates(m.Schema) {
+			declared[def.Name] = true
+		}
+	}
+	for field, block := range nodeFieldBlocks {
+		if field == "dgraph.type" || field == "attributes" {
+			continue
+		}
+		if !declared[block] {
+			t.Errorf("node field %q is not declared by any migration", field)
+		}
+	}
+
+	for name, preset := range map[string][]string{
+		"default":      NodeFieldsDefault,
+		"summary":      NodeFieldsSummary,
+		"consultation": NodeFieldsConsultation,
+		"detail":       NodeFieldsDetail,
+	} {
+		if _, err := nodeFieldSelection(preset); err != nil {
+			t.Errorf("%s projection: %v", name, err)
+		}
+	}
+}
+
+func TestNodeFieldSelection(t *testing.T) {
+	selection, err := nodeFieldSelection([]string{"name", "attributes", "name"})
+	if err != nil {
+		t.Fatalf("nodeFieldSelection: %v", err)
+	}
+	if !strings.HasPrefix(selection, "uid") || strings.Count(selection, "name") != 1 || !strings.Contains(selection, attributeFields) {
+		t.Errorf("selection = %q, want uid, name once and the attribute block", selection)
+	}
+
+	for _, bad := range []string{"password_hash", "name } evil { uid", "~knows"} {
+		if _, err := nodeFieldSelection([]string{bad}); err == nil {
+			t.Errorf("field %q should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultNodePageSize is how many nodes ListNodes returns when no Limit is given
+const DefaultNodePageSize = 500
+
+// ListNodesOpts selects a page of a namespace's nodes
+type ListNodesOpts struct {
+	Namespace   string
+	NodeType    NodeType // Only nodes having this type ("" = all)
+	ContentType string   // Only documents of this content type ("" = all)
+	Fields      []string // Projection, as for GetNodesByUIDs (nil = NodeFieldsSummary)
+	After       string   // Keyset cursor: nodes with a UID after this one
+	Offset      int      // Nodes to skip; prefer After for deep pages
+	Limit       int      // Page size (0 = DefaultNodePageSize)
+}
+
+// NodeLister fetches one page of nodes; Client.ListNodes is the usual one
+type NodeLister func(ctx context.Context, opts ListNodesOpts) ([]Node, error)
+
+// ListNodes returns one page of a namespace's nodes in UID order. Pass the last
+// UID of a page as After to get the next; a page shorter than Limit is the last.
+func (c *Client) ListNodes(ctx context.Context, opts ListNodesOpts) ([]Node, error) {
+	if opts.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultNodePageSize
+	}
+	after := "0x0"
+	if opts.After != "" {
+		if !uidPattern.MatchString(opts.After) {
+			return nil, fmt.Errorf("invalid cursor %q", opts.After)
+		}
+		after = opts.After
+	}
+	var filters []string
+	if opts.NodeType != "" {
+		// type() takes a literal, so only registered types are interpolated
+		if _, ok := nodeTypeRank[opts.NodeType]; !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownNodeType, opts.NodeType)
+		}
+		filters = append(filters, fmt.Sprintf("type(%s)", opts.NodeType))
+	}
+	if opts.ContentType != "" {
+		filters = append(filters, "eq(content_type, $content_type)")
+	}
+	filter := ""
+	if len(filters) > 0 {
+		filter = " @filter(" + strings.Join(filters, " AND ") + ")"
+	}
+	fields := opts.Fields
+	if fields == nil {
+		fields = NodeFieldsSummary
+	}
+	selection, err := nodeFieldSelection(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`query ListNodes($namespace: string, $content_type: string) {
+		nodes(func: eq(namespace, $namespace), first: %d, offset: %d, after: %s)%s {
+			%s
+		}
+	}`, opts.Limit, max(opts.Offset, 0), after, filter, selection)
+
+	resp, err := c.Query(ctx, query, map[string]string{"$namespace": opts.Namespace, "$content_type": opts.ContentType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+	return result.Nodes, nil
+}
+
+// StreamNodes sends every node opts selects, fetched a page at a time, so the
+// caller holds one page in memory however large the namespace is
+func (c *Client) StreamNodes(ctx context.Context, opts ListNodesOpts) (<-chan Node, <-chan error) {
+	return StreamNodePages(ctx, opts, c.ListNodes)
+}
+
+// StreamNodePages pages through list from opts.After and sends each node on
+// the first channel, which is closed when the nodes run out, list fails or
+// ctx is done. The error channel then yields the failure, if any.
+func StreamNodePages(ctx context.Context, opts ListNodesOpts, list NodeLister) (<-chan Node, <-chan error) {
+	nodes := make(chan Node)
+	errc := make(chan error, 1)
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultNodePageSize
+	}
+
+	go func() {
+		defer close(errc)
+		defer close(nodes)
+		for {
+			page, err := list(ctx, opts)
+			if err != nil {
+				errc <- err
+				return
+			}
+			for _, node := range page {
+				select {
+				case nodes <- node:
+				case <-ctx.Done():
+					errc <- ctx.Err()
+					return
+				}
+			}
+			if len(page) < opts.Limit {
+				return
+			}
+			// Later pages continue from the cursor, not the offset
+			opts.After, opts.Offset = page[len(page)-1].UID, 0
+		}
+	}()
+	return nodes, errc
+}
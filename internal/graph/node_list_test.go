@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// pagedNamespace lists n nodes with UIDs 0x1..0xn the way ListNodes pages them
+func pagedNamespace(n int, calls *[]ListNodesOpts) NodeLister {
+	return func(ctx context.Context, opts ListNodesOpts) ([]Node, error) {
+		*calls = append(*calls, opts)
+		start := 1
+		if opts.After != "" {
+			fmt.Sscanf(opts.After, "0x%x", &start)
+			start++
+		}
+		start += opts.Offset
+		var page []Node
+		for i := start; i <= n && len(page) < opts.Limit; i++ {
+			page = append(page, Node{UID: fmt.Sprintf("0x%x", i)})
+		}
+		return page, nil
+	}
+}
+
+func TestStreamNodePages(t *testing.T) {
+	var calls []ListNodesOpts
+	nodes, errc := StreamNodePages(context.Background(), ListNodesOpts{Namespace: "user_a", Offset: 2, Limit: 10}, pagedNamespace(25, &calls))
+
+	var got []string
+	for node := range nodes {
+		got = append(got, node.UID)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 23 || got[0] != "0x3" || got[22] != "0x19" {
+		t.Errorf("streamed %d nodes %v, want 0x3..0x19", len(got), got)
+	}
+	if len(calls) != 3 || calls[1].After != "0xc" || calls[1].Offset != 0 {
+		t.Errorf("pages fetched with %+v, want the offset once then cursors", calls)
+	}
+}
+
+func TestStreamNodePagesError(t *testing.T) {
+	failed := errors.New("dgraph unavailable")
+	pages := 0
+	nodes, errc := StreamNodePages(context.Background(), ListNodesOpts{Limit: 2}, func(ctx context.Context, opts ListNodesOpts) ([]Node, error) {
+		if pages++; pages > 1 {
+			return nil, failed
+		}
+		return []Node{{UID: "0x1"}, {UID: "0x2"}}, nil
+	})
+	count := 0
+	for range nodes {
+		count++
+	}
+	if err := <-errc; !errors.Is(err, failed) || count != 2 {
+		t.Errorf("got %d nodes and %v, want the first page then the error", count, err)
+	}
+}
@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownNodeType is returned when a client asks for a node type that isn't
+// in the schema
+var ErrUnknownNodeType = errors.New("unknown node type")
+
+// MemoryNodeTypes are the node types clients may store memories as. User,
+// Group, Conversation and Document nodes are created by their own flows.
+var MemoryNodeTypes = []NodeType{
+	NodeTypeFact,
+	NodeTypeEntity,
+	NodeTypeEvent,
+	NodeTypeInsight,
+	NodeTypePattern,
+	NodeTypePreference,
+	NodeTypeRule,
+}
+
+// MemoryNodeTypeNames returns MemoryNodeTypes as strings, for schemas and
+// error messages
+func MemoryNodeTypeNames() []string {
+	names := make([]string, len(MemoryNodeTypes))
+	for i, t := range MemoryNodeTypes {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// ParseNodeType resolves a client's node type, in any case, to one of
+// MemoryNodeTypes. The error lists the valid types, so a typo like "Fakt"
+// is rejected instead of creating a node no type query finds.
+func ParseNodeType(raw string) (NodeType, error) {
+	key := strings.TrimSpace(raw)
+	for _, t := range MemoryNodeTypes {
+		if strings.EqualFold(key, string(t)) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q (valid types: %s)", ErrUnknownNodeType, raw, strings.Join(MemoryNodeTypeNames(), ", "))
+}
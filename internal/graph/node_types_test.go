@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseNodeType(t *testing.T) {
+	for raw, want := range map[string]NodeType{"Fact": NodeTypeFact, "fact": NodeTypeFact, " Insight ": NodeTypeInsight} {
+		if got, err := ParseNodeType(raw); err != nil || got != want {
+			t.Errorf("ParseNodeType(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"Fakt", "", "User", "Document"} {
+		_, err := ParseNodeType(raw)
+		if !errors.Is(err, ErrUnknownNodeType) {
+			t.Errorf("ParseNodeType(%q) error = %v, want ErrUnknownNodeType", raw, err)
+		} else if !strings.Contains(err.Error(), "Fact, Entity") {
+			t.Errorf("error %q doesn't list the valid types", err)
+		}
+	}
+}
+
+func TestParseEntityType(t *testing.T) {
+	for raw, want := range map[string]EntityType{"Person": EntityTypePerson, "company": EntityTypeOrganization, "Locations": EntityTypeLocation} {
+		if got, err := ParseEntityType(raw); err != nil || got != want {
+			t.Errorf("ParseEntityType(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseEntityType("Persn"); !errors.Is(err, ErrUnknownEntityType) || !strings.Contains(err.Error(), "Person, Organization") {
+		t.Errorf("ParseEntityType(Persn) error = %v", err)
+	}
+	// Extraction still falls back to Concept
+	if got := NormalizeEntityType("Persn"); got != EntityTypeConcept {
+		t.Errorf("NormalizeEntityType(Persn) = %q, want Concept", got)
+	}
+}
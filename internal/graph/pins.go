@@ -0,0 +1,33 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// SetPinned pins or unpins a node. Pinned nodes keep their activation through
+// decay, are never pruned and rank higher in consultations.
+func (c *Client) SetPinned(ctx context.Context, uid string, pinned bool) error {
+	if !uidPattern.MatchString(uid) {
+		return fmt.Errorf("invalid uid %q", uid)
+	}
+	nquads := fmt.Sprintf(`<%s> <pinned> "%t"^^<xs:boolean> .
+<%s> <updated_at> "%s"^^<xs:dateTime> .
+`, uid, pinned, uid, time.Now().UTC().Format(time.RFC3339))
+
+	txn := c.dgraph().NewTxn()
+	defer txn.Discard(ctx)
+
+	mu := &api.Mutation{
+		SetNquads: []byte(nquads),
+		CommitNow: true,
+	}
+
+	if _, err := txn.Mutate(ctx, mu); err != nil {
+		return fmt.Errorf("failed to set pinned: %w", err)
+	}
+	return nil
+}
@@ -5,16 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/reflective-memory-kernel/internal/textnorm"
 )
 
 // QueryBuilder provides fluent interface for building DGraph queries
 type QueryBuilder struct {
-	client *Client
+	client Store
 }
 
 // NewQueryBuilder creates a new query builder
-func NewQueryBuilder(client *Client) *QueryBuilder {
+func NewQueryBuilder(client Store) *QueryBuilder {
 	return &QueryBuilder{client: client}
 }
 
@@ -92,6 +95,207 @@ func (q *QueryBuilder) GetHighActivationNodes(ctx context.Context, namespace str
 	return result.Nodes, nil
 }
 
+// GetInsightCandidates retrieves high-activation knowledge nodes across all namespaces
+// for the reflection synthesis pass. Callers must only pair nodes within one namespace.
+// SYSTEM OPERATION: used by background reflection only, never for user-facing reads.
+func (q *QueryBuilder) GetInsightCandidates(ctx context.Context, threshold float64, limit int) ([]Node, error) {
+	query := `query InsightCandidates($threshold: float, $limit: int) {
+		nodes(func: ge(activation, $threshold), orderdesc: activation, first: $limit)
+			@filter(has(namespace) AND NOT type(Insight) AND NOT type(Pattern) AND NOT type(User) AND NOT type(Group) AND NOT type(Conversation)) {
+			uid
+			dgraph.type
+			name
+			description
+			namespace
+			tags
+			activation
+			created_at
+		}
+	}`
+
+	vars := map[string]string{
+		"$threshold": fmt.Sprintf("%f", threshold),
+		"$limit":     fmt.Sprintf("%d", limit),
+	}
+
+	resp, err := q.client.Query(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Nodes, nil
+}
+
+// TimelineNode is an Event or Fact node with the time it occurred (if recorded)
+type TimelineNode struct {
+	Node
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+}
+
+// When returns occurred_at when set, else created_at
+func (t TimelineNode) When() time.Time {
+	if t.OccurredAt != nil && !t.OccurredAt.IsZero() {
+		return *t.OccurredAt
+	}
+	return t.CreatedAt
+}
+
+// GetTimelineNodes retrieves the newest Event and Fact nodes created since the given
+// time across all namespaces, for pattern mining. Callers must group results by namespace.
+// SYSTEM OPERATION: used by background reflection only, never for user-facing reads.
+func (q *QueryBuilder) GetTimelineNodes(ctx context.Context, since time.Time, limit int) ([]TimelineNode, error) {
+	query := `query TimelineNodes($since: string, $limit: int) {
+		nodes(func: ge(created_at, $since), orderdesc: created_at, first: $limit)
+			@filter((type(Event) OR type(Fact)) AND has(namespace)) {
+			uid
+			dgraph.type
+			name
+			namespace
+			tags
+			created_at
+			occurred_at
+		}
+	}`
+
+	vars := map[string]string{
+		"$since": since.UTC().Format(time.RFC3339),
+		"$limit": fmt.Sprintf("%d", limit),
+	}
+
+	resp, err := q.client.Query(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Nodes []TimelineNode `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Nodes, nil
+}
+
+// prunableTypes are the content node types maintenance may retire; structural
+// nodes (users, groups, conversations, rules) are never pruned
+var prunableTypes = []NodeType{NodeTypeEntity, NodeTypeFact, NodeTypeEvent, NodeTypePreference}
+
+// relationshipPredicates are the @reverse edge predicates counted when deciding
+// whether a node is orphaned: every registered edge type, plus the
+// has_attribute links to a node's attributes
+func relationshipPredicates() []string {
+	preds := make([]string, 0, len(edgeTypeRegistry)+1)
+	for _, info := range edgeTypeRegistry {
+		preds = append(preds, info.Predicate)
+	}
+	return append(preds, "has_attribute")
+}
+
+// PruneCandidate is a low-activation node with its relationship edge count
+type PruneCandidate struct {
+	Node
+	Edges int `json:"edges"`
+}
+
+// GetPruneCandidates returns non-archived, unpinned content nodes in a namespace whose
+// activation is below maxActivation, created before olderThan and not accessed since
+// then. Edges counts incoming plus outgoing relationship edges. With orphansOnly,
+// nodes with any relationship edge are filtered out in the query, so a page of
+// limit candidates isn't cut short by connected nodes.
+func (q *QueryBuilder) GetPruneCandidates(ctx context.Context, namespace string, maxActivation float64, olderThan time.Time, limit int, orphansOnly bool) ([]PruneCandidate, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	query := pruneCandidatesQuery(orphansOnly)
+	vars := map[string]string{
+		"$namespace":     namespace,
+		"$maxActivation": fmt.Sprintf("%f", maxActivation),
+		"$cutoff":        olderThan.UTC().Format(time.RFC3339),
+		"$limit":         fmt.Sprintf("%d", limit),
+	}
+
+	resp, err := q.client.Query(ctx, query, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodePruneCandidates(resp)
+}
+
+// pruneCandidatesQuery builds the GetPruneCandidates query
+func pruneCandidatesQuery(orphansOnly bool) string {
+	typeFilters := make([]string, len(prunableTypes))
+	for i, t := range prunableTypes {
+		typeFilters[i] = fmt.Sprintf("type(%s)", t)
+	}
+
+	var edgeCounts, orphanFilter strings.Builder
+	for i, pred := range relationshipPredicates() {
+		edgeCounts.WriteString(fmt.Sprintf("\t\t\trel_out%d: count(%s)\n\t\t\trel_in%d: count(~%s)\n", i, pred, i, pred))
+		if orphansOnly {
+			orphanFilter.WriteString(fmt.Sprintf("\n\t\t\t\tAND NOT has(%s) AND NOT has(~%s)", pred, pred))
+		}
+	}
+
+	return fmt.Sprintf(`query PruneCandidates($namespace: string, $maxActivation: float, $cutoff: string, $limit: int) {
+		nodes(func: eq(namespace, $namespace), first: $limit)
+			@filter((%s) AND lt(activation, $maxActivation) AND lt(created_at, $cutoff)
+				AND (NOT has(last_accessed) OR lt(last_accessed, $cutoff))
+				AND NOT eq(status, "%s") AND NOT eq(pinned, true)%s) {
+			uid
+			dgraph.type
+			name
+			namespace
+			activation
+			access_count
+			created_at
+			last_accessed
+			status
+			pinned
+%s		}
+	}`, strings.Join(typeFilters, " OR "), NodeStatusArchived, orphanFilter.String(), edgeCounts.String())
+}
+
+// decodePruneCandidates reads the GetPruneCandidates response, summing each
+// node's edge counts
+func decodePruneCandidates(resp []byte) ([]PruneCandidate, error) {
+	var raw struct {
+		Nodes []json.RawMessage `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp, &raw); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]PruneCandidate, 0, len(raw.Nodes))
+	for _, data := range raw.Nodes {
+		var c PruneCandidate
+		if err := json.Unmarshal(data, &c.Node); err != nil {
+			return nil, err
+		}
+		var counts map[string]interface{}
+		if err := json.Unmarshal(data, &counts); err != nil {
+			return nil, err
+		}
+		for key, v := range counts {
+			if n, ok := v.(float64); ok && strings.HasPrefix(key, "rel_") {
+				c.Edges += int(n)
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+}
+
 // GetDecayedNodes retrieves nodes that haven't been accessed recently
 func (q *QueryBuilder) GetDecayedNodes(ctx context.Context, namespace string, staleThreshold time.Duration) ([]Node, error) {
 	cutoffTime := time.Now().Add(-staleThreshold)
@@ -256,7 +460,40 @@ func (q *QueryBuilder) FindPathBetweenNodes(ctx context.Context, fromUID, toUID,
 
 // SearchByText performs full-text search across node names and descriptions, scoped to the namespace
 func (q *QueryBuilder) SearchByText(ctx context.Context, namespace string, searchText string, limit int) ([]Node, error) {
-	query := `query TextSearch($text: string, $limit: int, $namespace: string) {
+	return q.searchText(ctx, namespace, searchText, textnorm.Default.Normalize(searchText), limit)
+}
+
+// SearchByTerms is SearchByText for keywords a textnorm.Normalizer has already
+// stemmed; they are matched against each node's indexed search terms as they
+// are rather than being normalized a second time
+func (q *QueryBuilder) SearchByTerms(ctx context.Context, namespace string, terms string, limit int) ([]Node, error) {
+	return q.searchText(ctx, namespace, terms, terms, limit)
+}
+
+// searchText matches text against names, descriptions and tags, and the
+// normalized terms against search_terms
+func (q *QueryBuilder) searchText(ctx context.Context, namespace, searchText, terms string, limit int) ([]Node, error) {
+	vars := map[string]string{
+		"$text":      searchText,
+		"$limit":     fmt.Sprintf("%d", limit),
+		"$namespace": namespace,
+	}
+	termVar, termQuery := "", ""
+	if terms != "" {
+		vars["$terms"] = terms
+		termVar = ", $terms: string"
+		termQuery = `
+		term_results(func: anyofterms(search_terms, $terms), first: $limit) @filter(eq(namespace, $namespace)) {
+			uid
+			dgraph.type
+			name
+			description
+			tags
+			activation
+			last_accessed
+		}`
+	}
+	query := `query TextSearch($text: string, $limit: int, $namespace: string` + termVar + `) {
 		results(func: anyoftext(name, $text), first: $limit) @filter(eq(namespace, $namespace)) {
 			uid
 			dgraph.type
@@ -285,15 +522,9 @@ func (q *QueryBuilder) SearchByText(ctx context.Context, namespace string, searc
 			tags
 			activation
 			last_accessed
-		}
+		}` + termQuery + `
 	}`
 
-	vars := map[string]string{
-		"$text":      searchText,
-		"$limit":     fmt.Sprintf("%d", limit),
-		"$namespace": namespace,
-	}
-
 	resp, err := q.client.Query(ctx, query, vars)
 	if err != nil {
 		return nil, err
@@ -303,6 +534,7 @@ func (q *QueryBuilder) SearchByText(ctx context.Context, namespace string, searc
 		Results     []Node `json:"results"`
 		DescResults []Node `json:"desc_results"`
 		TagResults  []Node `json:"tag_results"`
+		TermResults []Node `json:"term_results"`
 	}
 	if err := json.Unmarshal(resp, &result); err != nil {
 		return nil, err
@@ -311,7 +543,7 @@ func (q *QueryBuilder) SearchByText(ctx context.Context, namespace string, searc
 	// Merge and deduplicate results
 	seen := make(map[string]bool)
 	var merged []Node
-	for _, node := range append(result.Results, append(result.DescResults, result.TagResults...)...) {
+	for _, node := range append(append(result.Results, result.DescResults...), append(result.TagResults, result.TermResults...)...) {
 		if !seen[node.UID] {
 			seen[node.UID] = true
 			merged = append(merged, node)
@@ -350,14 +582,33 @@ func (q *QueryBuilder) GetInsights(ctx context.Context, namespace string, limit
 		return nil, err
 	}
 
+	// source_nodes is an edge in DGraph; it is decoded separately (Insight's
+	// UnmarshalJSON would skip it in a wrapping struct) and flattened into
+	// SourceNodeUIDs
 	var result struct {
 		Insights []Insight `json:"insights"`
 	}
+	var sources struct {
+		Insights []struct {
+			SourceNodes []struct {
+				UID string `json:"uid"`
+			} `json:"source_nodes"`
+		} `json:"insights"`
+	}
 	if err := json.Unmarshal(resp, &result); err != nil {
 		return nil, err
 	}
+	if err := json.Unmarshal(resp, &sources); err != nil {
+		return nil, err
+	}
 
-	return result.Insights, nil
+	insights := result.Insights
+	for i := range insights {
+		for _, src := range sources.Insights[i].SourceNodes {
+			insights[i].SourceNodeUIDs = append(insights[i].SourceNodeUIDs, src.UID)
+		}
+	}
+	return insights, nil
 }
 
 // GetPatterns retrieves all patterns for a namespace
@@ -498,72 +749,3 @@ func (q *QueryBuilder) CountNodes(ctx context.Context, nodeType NodeType) (int,
 	}
 	return 0, nil
 }
-
-// GetUserRelatedNodes retrieves nodes connected to the user via specific relationship predicates
-func (q *QueryBuilder) GetUserRelatedNodes(ctx context.Context, userID string, limit int) ([]Node, error) {
-	// First, find the User node by name with correct NodeType
-	userNode, err := q.client.FindNodeByName(ctx, fmt.Sprintf("user_%s", userID), userID, NodeTypeUser)
-	if err != nil || userNode == nil {
-		// User node not found - this is expected for new users
-		// Return empty rather than error to allow fallback search
-		return nil, nil
-	}
-
-	// Query to get all nodes connected via KNOWS edge (stores all ingested entities)
-	query := `query UserKnowledge($uid: string, $limit: int) {
-		nodes(func: uid($uid)) {
-			knows (first: $limit) {
-				uid
-				dgraph.type
-				name
-				description
-				tags
-				activation
-				last_accessed
-
-				# Also get the reverse relationship targets (e.g., who this person has_manager to)
-				~has_manager {
-					uid
-					name
-				}
-			}
-			has_manager {
-				uid
-				dgraph.type
-				name
-				description
-				tags
-				activation
-			}
-		}
-	}`
-
-	vars := map[string]string{
-		"$uid":   userNode.UID,
-		"$limit": fmt.Sprintf("%d", limit),
-	}
-
-	resp, err := q.client.Query(ctx, query, vars)
-	if err != nil {
-		return nil, err
-	}
-
-	var result struct {
-		Nodes []struct {
-			Knows      []Node `json:"knows"`
-			HasManager []Node `json:"has_manager"`
-		} `json:"nodes"`
-	}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, err
-	}
-
-	// Combine all related nodes
-	var allNodes []Node
-	if len(result.Nodes) > 0 {
-		allNodes = append(allNodes, result.Nodes[0].Knows...)
-		allNodes = append(allNodes, result.Nodes[0].HasManager...)
-	}
-
-	return allNodes, nil
-}
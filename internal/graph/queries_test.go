@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPruneCandidatesQueryCountsEveryEdge(t *testing.T) {
+	for _, orphansOnly := range []bool{false, true} {
+		query := pruneCandidatesQuery(orphansOnly)
+		for _, pred := range append(registryPredicates(), "has_attribute") {
+			if !strings.Contains(query, "count("+pred+")") || !strings.Contains(query, "count(~"+pred+")") {
+				t.Errorf("query does not count %s edges both ways", pred)
+			}
+			filtered := strings.Contains(query, "NOT has("+pred+") AND NOT has(~"+pred+")")
+			if filtered != orphansOnly {
+				t.Errorf("orphansOnly=%v: %s filtered in the query = %v", orphansOnly, pred, filtered)
+			}
+		}
+		// The orphan filter belongs to the root's @filter, applied before first
+		filter := query[strings.Index(query, "@filter"):strings.Index(query, "uid\n")]
+		if orphansOnly && !strings.Contains(filter, "NOT has(related_to)") {
+			t.Error("orphan filter is not part of the root @filter")
+		}
+	}
+}
+
+// registryPredicates lists the predicates of the edge type registry
+func registryPredicates() []string {
+	var preds []string
+	for _, info := range EdgeTypeVocabulary() {
+		preds = append(preds, info.Predicate)
+	}
+	return preds
+}
+
+func TestDecodePruneCandidatesSumsEdges(t *testing.T) {
+	resp := []byte(`{"nodes": [
+		{"uid": "0x1", "name": "orphan", "activation": 0.001, "rel_out0": 0, "rel_in0": 0},
+		{"uid": "0x2", "name": "linked", "rel_out3": 1, "rel_in22": 2, "access_count": 4}
+	]}`)
+	candidates, err := decodePruneCandidates(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 2 || candidates[0].Edges != 0 || candidates[1].Edges != 3 {
+		t.Fatalf("candidates = %+v, want 0 and 3 edges", candidates)
+	}
+	if candidates[1].AccessCount != 4 {
+		t.Errorf("access_count was counted as an edge: %+v", candidates[1])
+	}
+}
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// langTagPattern is the N-Quads LANGTAG production without the leading '@'
+var langTagPattern = regexp.MustCompile(`^[a-zA-Z]+(-[a-zA-Z0-9]+)*$`)
+
+// escapeRDFString returns s as a quoted N-Quads string literal. Unlike Go's %q,
+// which emits \x and \a escapes DGraph's RDF parser rejects, it only uses the
+// escapes the N-Quads grammar allows: \" \\ \n \r \t \b \f and \uXXXX for any
+// other control character. Invalid UTF-8 is replaced with U+FFFD.
+func escapeRDFString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r) // utf8.RuneError for invalid bytes encodes as U+FFFD
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// escapeRDFLangString returns s as a quoted literal tagged with lang (e.g.
+// "bonjour"@fr). An empty or malformed tag is dropped rather than emitted.
+func escapeRDFLangString(s, lang string) string {
+	if !langTagPattern.MatchString(lang) {
+		return escapeRDFString(s)
+	}
+	return escapeRDFString(s) + "@" + lang
+}
+
+// escapeRDFPredicate returns name as an N-Quads IRI (<name>). Characters the
+// IRIREF production forbids (spaces, control characters, <>"{}|^`\) are
+// written as \uXXXX so a stray edge type can't break out of the IRI.
+func escapeRDFPredicate(name string) string {
+	var b strings.Builder
+	b.Grow(len(name) + 2)
+	b.WriteByte('<')
+	for _, r := range name {
+		if r <= 0x20 || r == 0x7f || strings.ContainsRune("<>\"{}|^`\\", r) {
+			fmt.Fprintf(&b, `\u%04X`, r)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
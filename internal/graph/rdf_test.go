@@ -0,0 +1,160 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// adversarialStrings are values that broke %q-built NQuads: Go escapes like
+// \x00 and \a are not valid N-Quads, and raw newlines end the statement
+var adversarialStrings = []string{
+	`say "hello"`,
+	`back\slash \n literal`,
+	"line one\nline two\r\n",
+	"tab\tand\bbackspace\fform feed",
+	"nul\x00bell\avtab\vdel\x7f",
+	"<script> & </script>",
+	"<http://example.com/> .",
+	`"> <name> "injected" .`,
+	"café 日本語 \U0001F600",
+	"zero​width ‮override",
+	"invalid \xff\xfe utf8",
+}
+
+// nquadLine matches one N-Quads statement with a literal object as DGraph's
+// parser accepts it: no raw control characters and only the escapes the
+// grammar allows inside the quotes
+var nquadLine = regexp.MustCompile(`^(_:\S+|<[^<>"{}|^` + "`" + `\\\x00-\x20]*>) <[^<>"\s]+> ` +
+	`(_:\S+|<[^<>"\s]+>|"(?:[^"\\\x00-\x1f\x7f]|\\[tbnrf"\\]|\\u[0-9A-F]{4})*"(?:\^\^<[^<>\s]+>|@[a-zA-Z]+(?:-[a-zA-Z0-9]+)*)?) \.$`)
+
+func TestEscapeRDFString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", `"plain"`},
+		{`a "quote"`, `"a \"quote\""`},
+		{`back\slash`, `"back\\slash"`},
+		{"two\nlines\r", `"two\nlines\r"`},
+		{"\t\b\f", `"\t\b\f"`},
+		{"nul\x00bell\a", `"nul\u0000bell\u0007"`},
+		{"del\x7f", `"del\u007F"`},
+		{"café \U0001F600", "\"café \U0001F600\""},
+		{"bad\xffbyte", "\"bad�byte\""},
+		{"", `""`},
+	}
+	for _, tt := range tests {
+		if got := escapeRDFString(tt.in); got != tt.want {
+			t.Errorf("escapeRDFString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeRDFLangString(t *testing.T) {
+	tests := []struct {
+		in, lang, want string
+	}{
+		{"bonjour", "fr", `"bonjour"@fr`},
+		{"colour", "en-GB", `"colour"@en-GB`},
+		{"x", "", `"x"`},
+		{"x", "en .\n<a> <b> <c>", `"x"`},
+		{"x", "-en", `"x"`},
+	}
+	for _, tt := range tests {
+		if got := escapeRDFLangString(tt.in, tt.lang); got != tt.want {
+			t.Errorf("escapeRDFLangString(%q, %q) = %s, want %s", tt.in, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeRDFPredicate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"works_at", "<works_at>"},
+		{"dgraph.type", "<dgraph.type>"},
+		{"has space", `<has\u0020space>`},
+		{"a> <b", `<a\u003E\u0020\u003Cb>`},
+		{"new\nline", `<new\u000Aline>`},
+	}
+	for _, tt := range tests {
+		if got := escapeRDFPredicate(tt.in); got != tt.want {
+			t.Errorf("escapeRDFPredicate(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestWriteNodeNquadsAdversarial checks that every statement built for a node
+// full of hostile text is still a single well-formed NQuad
+func TestWriteNodeNquadsAdversarial(t *testing.T) {
+	for i, s := range adversarialStrings {
+		node := &Node{
+			DType:                []string{string(NodeTypeEntity)},
+			Name:                 s,
+			Namespace:            "user_" + s,
+			Description:          s,
+			SourceConversationID: s,
+			Tags:                 []string{s, "plain"},
+			Attributes:           map[string]string{s: s},
+		}
+		var nquads strings.Builder
+		writeNodeNquads(&nquads, newBlankNode("node"), node)
+
+		for _, line := range strings.Split(strings.TrimSuffix(nquads.String(), "\n"), "\n") {
+			if !nquadLine.MatchString(line) {
+				t.Errorf("input %d (%q) produced malformed NQuad: %s", i, s, line)
+			}
+		}
+	}
+}
+
+// TestAdversarialRoundTrip writes hostile names and descriptions through
+// CreateNode and IngestWisdomBatch and reads them back unchanged. It needs a
+// running DGraph (see testClient) and skips otherwise.
+func TestAdversarialRoundTrip(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+	namespace := fmt.Sprintf("test_rdf_%d", time.Now().UnixNano())
+
+	for i, s := range adversarialStrings {
+		uid, err := client.CreateNode(ctx, &Node{
+			DType:       []string{string(NodeTypeEntity)},
+			Name:        s,
+			Namespace:   namespace,
+			Description: s,
+		})
+		if err != nil {
+			t.Errorf("CreateNode input %d (%q): %v", i, s, err)
+			continue
+		}
+		defer client.DeleteNode(ctx, uid, namespace)
+
+		node, err := client.GetNode(ctx, uid)
+		if err != nil {
+			t.Errorf("GetNode input %d: %v", i, err)
+			continue
+		}
+		// Each invalid byte is stored as U+FFFD
+		want := strings.Map(func(r rune) rune { return r }, s)
+		if node.Name != want || node.Description != want {
+			t.Errorf("input %d round-tripped as name=%q description=%q, want %q", i, node.Name, node.Description, want)
+		}
+	}
+
+	entities := make([]ExtractedEntity, len(adversarialStrings))
+	for i, s := range adversarialStrings {
+		entities[i] = ExtractedEntity{
+			Name:        fmt.Sprintf("%s #%d", s, i),
+			Description: s,
+			SourceText:  s,
+			Type:        NodeTypeEntity,
+			Tags:        []string{s},
+		}
+	}
+	if _, err := client.IngestWisdomBatch(ctx, namespace, strings.Join(adversarialStrings, "\n"), entities, nil); err != nil {
+		t.Errorf("IngestWisdomBatch: %v", err)
+	}
+}
@@ -2,7 +2,10 @@
 // This implements the core data structures for the Reflective Memory Kernel.
 package graph
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // NodeType represents the type of a node in the knowledge graph
 type NodeType string
@@ -18,8 +21,59 @@ const (
 	NodeTypeRule         NodeType = "Rule"
 	NodeTypeGroup        NodeType = "Group"
 	NodeTypeConversation NodeType = "Conversation"
+	NodeTypeDocument     NodeType = "Document"
 )
 
+// nodeTypePrecedence orders the node types from most to least specific. A node
+// can carry several dgraph.type values (e.g. ["Fact", "Entity"]); GetType
+// reports the one listed first here.
+var nodeTypePrecedence = []NodeType{
+	NodeTypeUser,
+	NodeTypeGroup,
+	NodeTypeConversation,
+	NodeTypeDocument,
+	NodeTypeInsight,
+	NodeTypePattern,
+	NodeTypeRule,
+	NodeTypePreference,
+	NodeTypeFact,
+	NodeTypeEvent,
+	NodeTypeEntity,
+}
+
+// nodeTypeRank maps each registered type to its position in nodeTypePrecedence
+var nodeTypeRank = func() map[NodeType]int {
+	rank := make(map[NodeType]int, len(nodeTypePrecedence))
+	for i, t := range nodeTypePrecedence {
+		rank[t] = i
+	}
+	return rank
+}()
+
+// NodeTypes returns the registered node types, most specific first
+func NodeTypes() []NodeType {
+	return append([]NodeType(nil), nodeTypePrecedence...)
+}
+
+// primaryType picks the most specific registered type from dtypes. Without
+// one it falls back to the alphabetically first type, so the result doesn't
+// depend on the order DGraph returns them in.
+func primaryType(dtypes []string) NodeType {
+	var primary NodeType
+	best := len(nodeTypePrecedence)
+	for _, dtype := range dtypes {
+		t := NodeType(dtype)
+		if rank, ok := nodeTypeRank[t]; ok {
+			if rank < best {
+				primary, best = t, rank
+			}
+		} else if best == len(nodeTypePrecedence) && (primary == "" || t < primary) {
+			primary = t
+		}
+	}
+	return primary
+}
+
 // EdgeType represents relationship types between nodes
 type EdgeType string
 
@@ -146,7 +200,8 @@ type Node struct {
 	DType       []string          `json:"dgraph.type,omitempty"`
 	Name        string            `json:"name,omitempty"`
 	Description string            `json:"description,omitempty"`
-	SourceText  string            `json:"source_text,omitempty"` // Original quote from user conversation
+	ContentType string            `json:"content_type,omitempty"` // Documents only: "text", "pdf", ...
+	SourceText  string            `json:"source_text,omitempty"`  // Original quote from user conversation
 	Tags        []string          `json:"tags,omitempty"`
 	Attributes  map[string]string `json:"attributes,omitempty"`
 
@@ -155,6 +210,12 @@ type Node struct {
 	UpdatedAt    time.Time `json:"updated_at,omitempty"`
 	LastAccessed time.Time `json:"last_accessed,omitempty"`
 
+	// Temporal validity for time-bounded facts ("lived in Berlin 2018-2020").
+	// nil bounds are open; ValidUntil is exclusive.
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	Status     string     `json:"status,omitempty"` // FactStatus* for facts
+
 	// User Metadata
 	Role string `json:"role,omitempty"` // "admin" or "user"
 
@@ -176,6 +237,7 @@ type Node struct {
 	// Activation for dynamic prioritization
 	Activation  float64 `json:"activation,omitempty"`
 	AccessCount int64   `json:"access_count,omitempty"`
+	Pinned      bool    `json:"pinned,omitempty"` // Never decays or gets pruned; ranked up in consultations
 
 	// Source tracking
 	SourceConversationID string  `json:"source_conversation_id,omitempty"`
@@ -186,12 +248,44 @@ type Node struct {
 	Embedding []float32 `json:"embedding,omitempty"`
 }
 
-// GetType returns the primary type of the node
+// Fact status values stored in Node.Status
+const (
+	FactStatusCurrent    = "current"
+	FactStatusHistorical = "historical" // valid_until has passed
+	FactStatusSuperseded = "superseded" // replaced by a contradicting newer fact
+
+	// NodeStatusArchived marks a node retired by maintenance pruning
+	NodeStatusArchived = "archived"
+)
+
+// IsValidAt reports whether the node's fact held at time t
+func (n *Node) IsValidAt(t time.Time) bool {
+	if n.Status == FactStatusSuperseded && n.ValidUntil == nil {
+		return false
+	}
+	if n.ValidFrom != nil && t.Before(*n.ValidFrom) {
+		return false
+	}
+	if n.ValidUntil != nil && !t.Before(*n.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// GetType returns the primary type of the node (see primaryType); "" when it
+// has no type. Use HasType to test for one of several types.
 func (n *Node) GetType() NodeType {
-	if len(n.DType) > 0 {
-		return NodeType(n.DType[0])
+	return primaryType(n.DType)
+}
+
+// HasType reports whether t is any of the node's types
+func (n *Node) HasType(t NodeType) bool {
+	for _, dtype := range n.DType {
+		if NodeType(dtype) == t {
+			return true
+		}
 	}
-	return ""
+	return false
 }
 
 // SetType sets the primary type of the node
@@ -282,6 +376,7 @@ type WorkspaceInvitation struct {
 	UID           string    `json:"uid,omitempty"`
 	DType         []string  `json:"dgraph.type,omitempty"`
 	WorkspaceID   string    `json:"workspace_id,omitempty"`    // Group namespace (e.g., "group_<UUID>")
+	WorkspaceName string    `json:"workspace_name,omitempty"`  // Group name, filled in by GetPendingInvitations
 	InviteeUserID string    `json:"invitee_user_id,omitempty"` // Username of invitee
 	Role          string    `json:"role,omitempty"`            // "admin" or "subuser"
 	Status        string    `json:"status,omitempty"`          // "pending", "accepted", "declined"
@@ -304,6 +399,28 @@ type ShareLink struct {
 	CreatedBy   string     `json:"created_by,omitempty"` // Admin who created the link
 }
 
+// ConversationShare records a conversation shared to a workspace
+type ConversationShare struct {
+	UID            string    `json:"uid,omitempty"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	GroupNamespace string    `json:"group_namespace,omitempty"`
+	GroupName      string    `json:"group_name,omitempty"`
+	SharedBy       string    `json:"shared_by,omitempty"` // User ID of the sharer (empty for legacy shares)
+	SharedAt       time.Time `json:"shared_at,omitempty"`
+}
+
+// ConversationTurn is one persisted exchange of a conversation
+type ConversationTurn struct {
+	UID            string    `json:"uid,omitempty"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	UserID         string    `json:"turn_user,omitempty"`
+	Namespace      string    `json:"namespace,omitempty"`
+	Timestamp      time.Time `json:"turn_at,omitempty"`
+	UserQuery      string    `json:"turn_user_query,omitempty"`
+	Response       string    `json:"turn_response,omitempty"`
+	LatencyMs      int64     `json:"turn_latency_ms,omitempty"`
+}
+
 // TranscriptEvent represents an ingested conversation event
 type TranscriptEvent struct {
 	ID                string            `json:"id,omitempty"`
@@ -327,6 +444,14 @@ type ExtractedEntity struct {
 	Tags        []string            `json:"tags,omitempty"`
 	Attributes  map[string]string   `json:"attributes,omitempty"`
 	Relations   []ExtractedRelation `json:"relations,omitempty"`
+
+	// Sources names the documents the entity was extracted from (batch ingestion);
+	// each becomes a Document node linked by a derived_from edge
+	Sources []string `json:"sources,omitempty"`
+
+	// Temporal scope detected by the extractor (nil when the fact is open-ended)
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
 }
 
 // ExtractedRelation represents a relationship extracted from conversation
@@ -337,6 +462,17 @@ type ExtractedRelation struct {
 	Properties map[string]string `json:"properties,omitempty"`
 }
 
+// ExtractedRelationship is a relationship between two named entities, as the
+// wisdom layer's summarization returns it. Either end may also name a user.
+type ExtractedRelationship struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Type EdgeType `json:"type"`
+	// NeedsReview is set when Type was inferred from a phrase with low
+	// confidence; the edge is written with a needs_review facet
+	NeedsReview bool `json:"needs_review,omitempty"`
+}
+
 // DocumentChunk represents a chunk of a document with its vector embedding
 type DocumentChunk struct {
 	Text       string    `json:"text"`
@@ -349,11 +485,17 @@ type DocumentChunk struct {
 type ConsultationRequest struct {
 	UserID          string   `json:"user_id,omitempty"`
 	Namespace       string   `json:"namespace,omitempty"` // NEW: Context isolation
+	Namespaces      []string `json:"namespaces,omitempty"`     // Extra namespaces to consult alongside Namespace
+	IncludeGroups   bool     `json:"include_groups,omitempty"` // Also consult every group the user belongs to
 	Query           string   `json:"query,omitempty"`
 	Context         string   `json:"context,omitempty"`
 	MaxResults      int      `json:"max_results,omitempty"`
 	IncludeInsights bool     `json:"include_insights,omitempty"`
 	TopicFilters    []string `json:"topic_filters,omitempty"`
+	BriefStyle      string   `json:"brief_style,omitempty"`     // Synthesis style: concise, detailed, bullet or narrative
+	BriefMaxWords   int      `json:"brief_max_words,omitempty"` // Word cap on the synthesized brief
+	BudgetMs        int      `json:"budget_ms,omitempty"`       // Time the consultation may take; the kernel default when 0
+	Rerank          bool     `json:"rerank,omitempty"`          // Have the LLM score each fact's relevance and reorder by it before synthesis
 }
 
 // ConsultationResponse represents the Memory Kernel's response to a query
@@ -365,6 +507,49 @@ type ConsultationResponse struct {
 	Patterns         []Pattern `json:"patterns,omitempty"`
 	ProactiveAlerts  []string  `json:"proactive_alerts,omitempty"`
 	Confidence       float64   `json:"confidence,omitempty"`
+
+	// CollapsedDuplicates counts near-identical facts merged out of RelevantFacts
+	CollapsedDuplicates int `json:"collapsed_duplicates,omitempty"`
+
+	// Reranked is true when RelevantFacts are ordered by LLM relevance
+	// scores rather than by retrieval rank
+	Reranked bool `json:"reranked,omitempty"`
+
+	// DegradedModes lists the subsystems that failed while answering (see the
+	// DegradedMode constants); when set, results may be incomplete
+	DegradedModes []string `json:"degraded_modes,omitempty"`
+
+	// RetrievalStrategy is how the facts were retrieved (see the
+	// RetrievalStrategy constants); empty when they came from the hot cache
+	// or the speculative cache
+	RetrievalStrategy string `json:"retrieval_strategy,omitempty"`
+
+	// SynthesisAvailable is true when an LLM wrote SynthesizedBrief; false
+	// when the brief lists the facts by template because no LLM was available
+	SynthesisAvailable bool `json:"synthesis_available"`
+}
+
+// Subsystems a consultation can lose without failing
+const (
+	DegradedModeVector    = "vector"    // Semantic search (embedding or Qdrant)
+	DegradedModeHotCache  = "hot_cache" // Recent-message cache
+	DegradedModeSynthesis = "synthesis" // AI brief; a fallback brief was used
+	DegradedModeBudget    = "budget"    // Time budget ran out; what was gathered by then was returned
+	DegradedModeRerank    = "rerank"    // LLM reranking; facts kept their retrieval order
+)
+
+// Retrieval strategies a consultation picks from the query
+const (
+	RetrievalStrategyHybrid     = "hybrid"     // Similarity, activation and recency together
+	RetrievalStrategyRelational = "relational" // Edges out from the user ("who is my manager")
+	RetrievalStrategySemantic   = "semantic"   // Keyword and similarity ("what do I know about X")
+	RetrievalStrategyRecent     = "recent"     // Newest first ("summarize my week")
+)
+
+// SimilarNode is a node returned by a "related memories" search with its similarity score
+type SimilarNode struct {
+	Node  Node    `json:"node"`
+	Score float32 `json:"score"`
 }
 
 // ActivationConfig configures the dynamic prioritization algorithm
@@ -383,15 +568,41 @@ type ActivationConfig struct {
 
 	// CoreIdentityThreshold is the threshold for promoting to core identity
 	CoreIdentityThreshold float64 `json:"core_identity_threshold,omitempty"`
+
+	// TraversalMinActivation is the activation below which spreading
+	// activation stops, when a traversal doesn't set one (0 = the default)
+	TraversalMinActivation float64 `json:"traversal_min_activation,omitempty"`
 }
 
 // DefaultActivationConfig returns sensible defaults for activation
 func DefaultActivationConfig() ActivationConfig {
 	return ActivationConfig{
-		DecayRate:             0.005, // 0.5% decay per day (~12% per week, gentle)
-		BoostPerAccess:        0.01,  // 1% boost per access (reduced for gradual strengthening)
-		MinActivation:         0.01,  // 1% minimum
-		MaxActivation:         0.85,  // 85% maximum (reduced)
-		CoreIdentityThreshold: 0.8,   // 80% for core identity
+		DecayRate:              0.005, // 0.5% decay per day (~12% per week, gentle)
+		BoostPerAccess:         0.01,  // 1% boost per access (reduced for gradual strengthening)
+		MinActivation:          0.01,  // 1% minimum
+		MaxActivation:          0.85,  // 85% maximum (reduced)
+		CoreIdentityThreshold:  0.8,   // 80% for core identity
+		TraversalMinActivation: DefaultTraversalMinActivation,
+	}
+}
+
+// Validate checks the config's bounds: 0 < BoostPerAccess, 0 < MaxActivation <= 1,
+// 0 <= DecayRate < 1, 0 <= MinActivation < MaxActivation,
+// 0 < CoreIdentityThreshold <= 1 and 0 <= TraversalMinActivation < 1
+func (c ActivationConfig) Validate() error {
+	switch {
+	case c.BoostPerAccess <= 0:
+		return fmt.Errorf("boost_per_access must be greater than 0")
+	case c.MaxActivation <= 0 || c.MaxActivation > 1:
+		return fmt.Errorf("max_activation must be in (0, 1]")
+	case c.DecayRate < 0 || c.DecayRate >= 1:
+		return fmt.Errorf("decay_rate must be in [0, 1)")
+	case c.MinActivation < 0 || c.MinActivation >= c.MaxActivation:
+		return fmt.Errorf("min_activation must be in [0, max_activation)")
+	case c.CoreIdentityThreshold <= 0 || c.CoreIdentityThreshold > 1:
+		return fmt.Errorf("core_identity_threshold must be in (0, 1]")
+	case c.TraversalMinActivation < 0 || c.TraversalMinActivation >= 1:
+		return fmt.Errorf("traversal_min_activation must be in [0, 1)")
 	}
+	return nil
 }
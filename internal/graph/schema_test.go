@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetTypeMultiType(t *testing.T) {
+	tests := []struct {
+		dtypes []string
+		want   NodeType
+	}{
+		{[]string{"pdf", "Document"}, NodeTypeDocument},
+		{[]string{"Document", "pdf"}, NodeTypeDocument},
+		{[]string{"Entity", "User"}, NodeTypeUser},
+		{[]string{"Fact", "Insight"}, NodeTypeInsight},
+		{[]string{"text", "markdown"}, "markdown"},
+		{[]string{"Entity"}, NodeTypeEntity},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		n := &Node{DType: tt.dtypes}
+		if got := n.GetType(); got != tt.want {
+			t.Errorf("GetType(%v) = %q, want %q", tt.dtypes, got, tt.want)
+		}
+	}
+}
+
+func TestHasType(t *testing.T) {
+	n := &Node{DType: []string{"pdf", "Document"}}
+	if !n.HasType(NodeTypeDocument) || !n.HasType("pdf") {
+		t.Errorf("HasType misses one of %v", n.DType)
+	}
+	if n.HasType(NodeTypeEntity) {
+		t.Error("HasType(Entity) = true for a document")
+	}
+}
+
+// TestNodeTypesRegistered keeps the precedence list covering every node type
+func TestNodeTypesRegistered(t *testing.T) {
+	for _, nt := range []NodeType{
+		NodeTypeUser, NodeTypeEntity, NodeTypeEvent, NodeTypeInsight, NodeTypePattern,
+		NodeTypePreference, NodeTypeFact, NodeTypeRule, NodeTypeGroup,
+		NodeTypeConversation, NodeTypeDocument,
+	} {
+		if _, ok := nodeTypeRank[nt]; !ok {
+			t.Errorf("%s missing from nodeTypePrecedence", nt)
+		}
+	}
+	if len(NodeTypes()) != len(nodeTypeRank) {
+		t.Error("nodeTypePrecedence lists a type twice")
+	}
+}
+
+func TestIsValidAt(t *testing.T) {
+	at := func(s string) *time.Time {
+		ts, _ := time.Parse(time.RFC3339, s)
+		return &ts
+	}
+	now := *at("2020-06-01T00:00:00Z")
+	tests := []struct {
+		name string
+		node Node
+		want bool
+	}{
+		{"no window", Node{}, true},
+		{"inside the window", Node{ValidFrom: at("2018-01-01T00:00:00Z"), ValidUntil: at("2021-01-01T00:00:00Z")}, true},
+		{"before it starts", Node{ValidFrom: at("2020-06-02T00:00:00Z")}, false},
+		{"on its start", Node{ValidFrom: at("2020-06-01T00:00:00Z")}, true},
+		{"on its exclusive end", Node{ValidUntil: at("2020-06-01T00:00:00Z")}, false},
+		{"after it ends", Node{ValidUntil: at("2019-01-01T00:00:00Z")}, false},
+		{"superseded with no end", Node{Status: FactStatusSuperseded}, false},
+		{"superseded with a later end", Node{Status: FactStatusSuperseded, ValidUntil: at("2021-01-01T00:00:00Z")}, true},
+		{"historical within its window", Node{Status: FactStatusHistorical, ValidUntil: at("2021-01-01T00:00:00Z")}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.node.IsValidAt(now); got != tt.want {
+			t.Errorf("%s: IsValidAt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
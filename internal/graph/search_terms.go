@@ -0,0 +1,180 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+	"go.uber.org/zap"
+)
+
+// searchTermsPage is how many nodes the search terms backfill reads and
+// writes per transaction
+const searchTermsPage = 500
+
+// searchTermsSchema redeclares the node types with search_terms so
+// type-based deletes remove it; each keeps every field it already had
+const searchTermsSchema = `
+	type User {
+		name
+		description
+		search_terms
+		attributes
+		has_attribute
+		created_at
+		updated_at
+		last_accessed
+		activation
+		access_count
+	}
+
+	type Entity {
+		name
+		description
+		search_terms
+		attributes
+		has_attribute
+		created_at
+		updated_at
+		last_accessed
+		activation
+		access_count
+		entity_type
+		tags
+	}
+
+	type Event {
+		name
+		description
+		search_terms
+		attributes
+		created_at
+		updated_at
+		occurred_at
+		sentiment
+	}
+
+	type Insight {
+		name
+		description
+		search_terms
+		insight_type
+		summary
+		action_suggestion
+		source_nodes
+		created_at
+		confidence
+	}
+
+	type Pattern {
+		name
+		description
+		search_terms
+		pattern_type
+		trigger_nodes
+		frequency
+		confidence_score
+		predicted_action
+		created_at
+	}
+
+	type Fact {
+		name
+		description
+		search_terms
+		fact_value
+		created_at
+		valid_from
+		valid_until
+		status
+	}
+`
+
+// backfillSearchTerms indexes the nodes written before search_terms existed
+func backfillSearchTerms(ctx context.Context, c *Client) error {
+	indexed, err := migrateSearchTerms(ctx, func() accessTxn { return c.dgraph().NewTxn() })
+	if err != nil {
+		return err
+	}
+	c.logger.Info("Search terms backfilled", zap.Int("nodes", indexed))
+	return nil
+}
+
+// migrateSearchTerms pages through the named nodes without search_terms and
+// writes them from the name and description, or the summary for nodes that
+// have one, as the writers do. It returns how many nodes it indexed.
+func migrateSearchTerms(ctx context.Context, newTxn func() accessTxn) (int, error) {
+	indexed := 0
+	after := ""
+	for {
+		count, next, err := migrateSearchTermsPage(ctx, newTxn(), after)
+		if err != nil {
+			return indexed, err
+		}
+		indexed += count
+		if next == "" {
+			return indexed, nil
+		}
+		after = next
+	}
+}
+
+// migrateSearchTermsPage indexes one page of nodes inside txn. Pages move by
+// uid, since a node whose text normalizes to nothing stays unindexed; when
+// the page was full, next is its last uid.
+func migrateSearchTermsPage(ctx context.Context, txn accessTxn, after string) (indexed int, next string, err error) {
+	defer txn.Discard(ctx)
+
+	page := ""
+	if after != "" {
+		page = ", after: " + after
+	}
+	resp, err := txn.QueryWithVars(ctx, fmt.Sprintf(`{
+		nodes(func: has(name), first: %d%s) @filter(NOT has(search_terms)) {
+			uid
+			name
+			description
+			summary
+		}
+	}`, searchTermsPage, page), nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read nodes without search terms: %w", err)
+	}
+	var result struct {
+		Nodes []struct {
+			UID         string `json:"uid"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Summary     string `json:"summary"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(resp.Json, &result); err != nil {
+		return 0, "", fmt.Errorf("failed to unmarshal nodes without search terms: %w", err)
+	}
+	if len(result.Nodes) == searchTermsPage {
+		next = result.Nodes[len(result.Nodes)-1].UID
+	}
+
+	var set strings.Builder
+	for _, node := range result.Nodes {
+		text := node.Description
+		if node.Summary != "" {
+			text = node.Summary
+		}
+		before := set.Len()
+		writeSearchTermsNquad(&set, "<"+node.UID+">", node.Name, text)
+		if set.Len() > before {
+			indexed++
+		}
+	}
+	if indexed == 0 {
+		return 0, next, nil
+	}
+
+	if _, err := txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(set.String()), CommitNow: true}); err != nil {
+		return 0, "", fmt.Errorf("failed to write search terms: %w", err)
+	}
+	return indexed, next, nil
+}
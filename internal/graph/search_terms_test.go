@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+type fakeTermNode struct {
+	UID         string `json:"uid"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+	SearchTerms string `json:"search_terms,omitempty"`
+}
+
+// fakeTermStore answers migrateSearchTerms from nodes, kept in uid order as
+// DGraph pages them
+type fakeTermStore struct {
+	nodes   []*fakeTermNode
+	commits int
+}
+
+func (s *fakeTermStore) newTxn() accessTxn { return &fakeTermTxn{s: s} }
+
+type fakeTermTxn struct{ s *fakeTermStore }
+
+func (t *fakeTermTxn) QueryWithVars(ctx context.Context, q string, vars map[string]string) (*api.Response, error) {
+	after := ""
+	if m := fakeAfterPattern.FindStringSubmatch(q); m != nil {
+		after = m[1]
+	}
+	var out []*fakeTermNode
+	for _, n := range t.s.nodes {
+		if len(out) == searchTermsPage {
+			break
+		}
+		if n.SearchTerms == "" && (after == "" || n.UID > after) {
+			out = append(out, n)
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"nodes": out})
+	return &api.Response{Json: data}, nil
+}
+
+func (t *fakeTermTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	for _, line := range strings.Split(strings.TrimSpace(string(mu.SetNquads)), "\n") {
+		m := fakeNquad.FindStringSubmatch(line)
+		if m == nil || m[2] != "search_terms" {
+			continue
+		}
+		for _, n := range t.s.nodes {
+			if n.UID == m[1] {
+				n.SearchTerms = m[3]
+			}
+		}
+	}
+	t.s.commits++
+	return &api.Response{}, nil
+}
+
+func (t *fakeTermTxn) Discard(ctx context.Context) error { return nil }
+
+func TestMigrateSearchTerms(t *testing.T) {
+	store := &fakeTermStore{}
+	for i := 1; i <= searchTermsPage+10; i++ {
+		store.nodes = append(store.nodes, &fakeTermNode{UID: fmt.Sprintf("0x%04x", i), Name: "Running shoes"})
+	}
+	store.nodes = append(store.nodes,
+		&fakeTermNode{UID: "0x1000", Name: "the", Description: "a"}, // no terms to index
+		&fakeTermNode{UID: "0x1001", Name: "Weekly review", Description: "ignored", Summary: "Planning habits"},
+		&fakeTermNode{UID: "0x1002", Name: "Kept", SearchTerms: "kept"},
+	)
+
+	n, err := migrateSearchTerms(context.Background(), store.newTxn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := searchTermsPage + 11; n != want {
+		t.Errorf("indexed %d nodes, want %d", n, want)
+	}
+
+	for _, node := range store.nodes {
+		switch node.UID {
+		case "0x1000":
+			if node.SearchTerms != "" {
+				t.Errorf("%s got search terms %q from stop words", node.UID, node.SearchTerms)
+			}
+		case "0x1001":
+			if !strings.Contains(node.SearchTerms, "plan") || strings.Contains(node.SearchTerms, "ignor") {
+				t.Errorf("%s search terms = %q, want them from the summary", node.UID, node.SearchTerms)
+			}
+		case "0x1002":
+			if node.SearchTerms != "kept" {
+				t.Errorf("%s search terms rewritten to %q", node.UID, node.SearchTerms)
+			}
+		default:
+			if node.SearchTerms == "" {
+				t.Errorf("%s was not indexed", node.UID)
+			}
+		}
+	}
+
+	// A second run finds nothing left to do
+	if n, err := migrateSearchTerms(context.Background(), store.newTxn); err != nil || n != 0 {
+		t.Errorf("rerun indexed %d nodes (err %v), want 0", n, err)
+	}
+}
+
+// TestSearchTermsDeclaredOnTypes checks that the latest declaration of every
+// type holding search_terms keeps the fields of the one it replaces
+func TestSearchTermsDeclaredOnTypes(t *testing.T) {
+	typeDecl := regexp.MustCompile(`type (\w+) \{([^}]*)\}`)
+	declared := make(map[string]map[string]bool)
+	for _, m := range migrations {
+		for _, decl := range typeDecl.FindAllStringSubmatch(m.Schema, -1) {
+			fields := make(map[string]bool)
+			for _, f := range strings.Fields(decl[2]) {
+				fields[f] = true
+			}
+			for f := range declared[decl[1]] {
+				if !fields[f] {
+					t.Errorf("migration %d drops %s from type %s", m.Version, f, decl[1])
+				}
+			}
+			declared[decl[1]] = fields
+		}
+	}
+
+	for _, dtype := range []NodeType{NodeTypeUser, NodeTypeEntity, NodeTypeEvent, NodeTypeInsight, NodeTypePattern, NodeTypeFact} {
+		if !declared[string(dtype)]["search_terms"] {
+			t.Errorf("type %s does not declare search_terms", dtype)
+		}
+	}
+}
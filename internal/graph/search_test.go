@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// fakeQuerier records the queries it is sent and answers each with reply
+type fakeQuerier struct {
+	queries []string
+	vars    []map[string]string
+	reply   string
+}
+
+func (q *fakeQuerier) QueryWithVars(ctx context.Context, query string, vars map[string]string) (*api.Response, error) {
+	q.queries = append(q.queries, query)
+	q.vars = append(q.vars, vars)
+	return &api.Response{Json: []byte(q.reply)}, nil
+}
+
+func TestSearchNodesWildcardListsNamespace(t *testing.T) {
+	for _, queryStr := range []string{"", "*", " * "} {
+		q := &fakeQuerier{reply: `{"nodes": [{"uid": "0x1", "name": "Alice"}, {"uid": "0x2", "name": "Bob"}]}`}
+		nodes, err := searchNodes(context.Background(), q, queryStr, "user_a")
+		if err != nil {
+			t.Fatalf("searchNodes(%q): %v", queryStr, err)
+		}
+		if len(nodes) != 2 {
+			t.Errorf("searchNodes(%q) = %d nodes, want 2", queryStr, len(nodes))
+		}
+
+		query := q.queries[0]
+		if !strings.Contains(query, "has(name)") || !strings.Contains(query, "eq(namespace, $namespace)") {
+			t.Errorf("searchNodes(%q) did not list the namespace:\n%s", queryStr, query)
+		}
+		if strings.Contains(query, "anyoftext") {
+			t.Errorf("searchNodes(%q) ran a fulltext search", queryStr)
+		}
+		if !strings.Contains(query, "first: 1000") || q.vars[0]["$namespace"] != "user_a" {
+			t.Errorf("searchNodes(%q) listing unbounded or unscoped: vars %v", queryStr, q.vars[0])
+		}
+	}
+}
+
+func TestSearchNodesKeywordMergesMatches(t *testing.T) {
+	q := &fakeQuerier{reply: `{
+		"nodes": [{"uid": "0x1", "name": "Coffee"}],
+		"nodes_desc": [{"uid": "0x1", "name": "Coffee"}, {"uid": "0x3", "name": "Morning"}]
+	}`}
+	nodes, err := searchNodes(context.Background(), q, "coffee", "user_a")
+	if err != nil {
+		t.Fatalf("searchNodes: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].UID != "0x1" || nodes[1].UID != "0x3" {
+		t.Errorf("searchNodes = %+v, want 0x1 then 0x3 once each", nodes)
+	}
+	if !strings.Contains(q.queries[0], "anyoftext") || q.vars[0]["$term"] != "coffee" {
+		t.Errorf("keyword search not run as fulltext: vars %v", q.vars[0])
+	}
+}
@@ -0,0 +1,33 @@
+package graph
+
+import "context"
+
+// Store is the part of the graph client that consultation and the MCP memory
+// tools use. *Client is the DGraph-backed Store; MemStore is an in-memory one
+// for tests.
+type Store interface {
+	CreateNode(ctx context.Context, node *Node) (string, error)
+	GetNode(ctx context.Context, uid string) (*Node, error)
+	GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]Node, error)
+	UpdateDescription(ctx context.Context, uid string, description string) error
+	SetAttributes(ctx context.Context, uid string, attrs map[string]string) error
+	CreateEdge(ctx context.Context, fromUID, toUID string, edgeType EdgeType, status EdgeStatus) error
+
+	FindNodeByName(ctx context.Context, namespace string, name string, nodeType NodeType) (*Node, error)
+	FindByAttributes(ctx context.Context, namespace string, attrs map[string]string, name string, limit int) ([]Node, error)
+	SearchNodes(ctx context.Context, queryStr, namespace string) ([]Node, error)
+	ListNodes(ctx context.Context, opts ListNodesOpts) ([]Node, error)
+
+	ExpandFromNode(ctx context.Context, opts ExpandOpts) (*ExpandResult, error)
+	SpreadActivation(ctx context.Context, opts SpreadActivationOpts) ([]ActivatedNode, error)
+	SpreadActivationPage(ctx context.Context, opts SpreadActivationOpts) (*ActivationPage, error)
+	IncrementAccessCounts(ctx context.Context, uids []string, config ActivationConfig) error
+
+	IsWorkspaceMember(ctx context.Context, workspaceNS, userID string) (bool, error)
+	ListUserGroups(ctx context.Context, userID string) ([]Group, error)
+
+	// Query runs raw DQL. MemStore cannot interpret DQL; see MemStore.QueryFunc.
+	Query(ctx context.Context, query string, vars map[string]string) ([]byte, error)
+}
+
+var _ Store = (*Client)(nil)
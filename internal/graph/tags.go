@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// tagMutator is the part of a DGraph transaction a tag change uses
+type tagMutator interface {
+	Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error)
+	Discard(ctx context.Context) error
+}
+
+// AddTagsBulk adds tags to every node in uids in a single mutation. Tags a
+// node already has are left as they are.
+func (c *Client) AddTagsBulk(ctx context.Context, uids []string, tags []string) error {
+	return mutateTags(ctx, c.dgraph().NewTxn(), uids, tags, false)
+}
+
+// RemoveTags removes tags from a node
+func (c *Client) RemoveTags(ctx context.Context, uid string, tags []string) error {
+	return c.RemoveTagsBulk(ctx, []string{uid}, tags)
+}
+
+// RemoveTagsBulk removes tags from every node in uids in a single mutation,
+// deleting just those values of the tags list. Tags a node doesn't have are
+// ignored.
+func (c *Client) RemoveTagsBulk(ctx context.Context, uids []string, tags []string) error {
+	return mutateTags(ctx, c.dgraph().NewTxn(), uids, tags, true)
+}
+
+// mutateTags sets, or deletes when del is set, one tags triple per node and
+// tag, committing them in txn
+func mutateTags(ctx context.Context, txn tagMutator, uids []string, tags []string, del bool) error {
+	defer txn.Discard(ctx)
+
+	nquads, err := tagNquads(uids, tags)
+	if err != nil || nquads == nil {
+		return err
+	}
+	mu := &api.Mutation{CommitNow: true}
+	if del {
+		mu.DelNquads = nquads
+	} else {
+		mu.SetNquads = nquads
+	}
+
+	if _, err := txn.Mutate(ctx, mu); err != nil {
+		if del {
+			return fmt.Errorf("failed to remove tags: %w", err)
+		}
+		return fmt.Errorf("failed to add tags: %w", err)
+	}
+	return nil
+}
+
+// tagNquads returns the tags triples for every node and tag, skipping blank
+// tags and repeats, or nil if there are none
+func tagNquads(uids []string, tags []string) ([]byte, error) {
+	var values []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			values = append(values, escapeRDFString(tag))
+		}
+	}
+
+	var nquads strings.Builder
+	seen = make(map[string]bool, len(uids))
+	for _, uid := range uids {
+		if seen[uid] {
+			continue
+		}
+		if !uidPattern.MatchString(uid) {
+			return nil, fmt.Errorf("invalid uid %q", uid)
+		}
+		seen[uid] = true
+		for _, value := range values {
+			fmt.Fprintf(&nquads, "<%s> <tags> %s .\n", uid, value)
+		}
+	}
+	if nquads.Len() == 0 {
+		return nil, nil
+	}
+	return []byte(nquads.String()), nil
+}
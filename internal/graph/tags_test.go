@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v240/protos/api"
+)
+
+// fakeTagStore holds each node's tags and applies tags triples to them
+type fakeTagStore struct {
+	tags      map[string]map[string]bool
+	mutations int
+}
+
+type fakeTagTxn struct{ s *fakeTagStore }
+
+var tagTriple = regexp.MustCompile(`^<(0x[0-9a-f]+)> <tags> ("(?:[^"\\]|\\.)*") \.$`)
+
+func (t fakeTagTxn) Mutate(ctx context.Context, mu *api.Mutation) (*api.Response, error) {
+	t.s.mutations++
+	apply := func(nquads []byte, set bool) {
+		for _, line := range strings.Split(strings.TrimSpace(string(nquads)), "\n") {
+			m := tagTriple.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			tag, _ := strconv.Unquote(m[2])
+			if t.s.tags[m[1]] == nil {
+				t.s.tags[m[1]] = map[string]bool{}
+			}
+			if set {
+				t.s.tags[m[1]][tag] = true
+			} else {
+				delete(t.s.tags[m[1]], tag)
+			}
+		}
+	}
+	apply(mu.SetNquads, true)
+	apply(mu.DelNquads, false)
+	return &api.Response{}, nil
+}
+
+func (t fakeTagTxn) Discard(ctx context.Context) error { return nil }
+
+func (s *fakeTagStore) tagsOf(uid string) []string {
+	var tags []string
+	for tag := range s.tags[uid] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func TestTagsAddedAndRemovedAcrossNodes(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeTagStore{tags: map[string]map[string]bool{"0x1": {"work": true}}}
+	uids := []string{"0x1", "0x2", "0x3", "0x2"}
+
+	if err := mutateTags(ctx, fakeTagTxn{store}, uids, []string{"report-q3", " draft ", "report-q3"}, false); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := mutateTags(ctx, fakeTagTxn{store}, []string{"0x1", "0x2"}, []string{"draft", "missing"}, true); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if store.mutations != 2 {
+		t.Errorf("%d mutations, want one per bulk call", store.mutations)
+	}
+
+	for uid, want := range map[string][]string{
+		"0x1": {"report-q3", "work"},
+		"0x2": {"report-q3"},
+		"0x3": {"draft", "report-q3"},
+	} {
+		if got := store.tagsOf(uid); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s tags = %q, want %q", uid, got, want)
+		}
+	}
+}
+
+func TestTagMutationRejectsBadInput(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeTagStore{tags: map[string]map[string]bool{}}
+
+	if err := mutateTags(ctx, fakeTagTxn{store}, []string{"0x1", "0x1> <name> \"x"}, []string{"a"}, false); err == nil {
+		t.Error("a malformed uid was interpolated into the mutation")
+	}
+	if err := mutateTags(ctx, fakeTagTxn{store}, []string{"0x1"}, []string{" "}, false); err != nil || store.mutations != 0 {
+		t.Errorf("blank tags: err = %v after %d mutations, want a no-op", err, store.mutations)
+	}
+}
@@ -3,10 +3,15 @@ package graph
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -23,7 +28,8 @@ type SpreadActivationOpts struct {
 	DecayFactor   float64 // 0.0-1.0, how much activation is retained per hop (0.5 = halve)
 	MaxHops       int     // Maximum traversal depth
 	MinActivation float64 // Stop when activation falls below this threshold
-	MaxResults    int     // Limit returned nodes
+	MaxResults    int     // Limit returned nodes (page size when paging)
+	Cursor        string  // Opaque cursor from a previous page's NextCursor (empty = first page)
 }
 
 // ActivatedNode represents a node with computed activation from traversal
@@ -33,12 +39,31 @@ type ActivatedNode struct {
 	Hops       int     `json:"hops"`       // Distance from start node
 }
 
+// ActivationPage is one page of spreading activation results.
+// Nodes are ordered by activation (descending) then UID (ascending), so
+// repeated traversals over an unchanged graph page deterministically.
+type ActivationPage struct {
+	Nodes      []ActivatedNode `json:"nodes"`
+	NextCursor string          `json:"next_cursor,omitempty"` // Empty when there are no more results
+	TotalCount int             `json:"total_count"`           // Total activated nodes across all pages
+}
+
+// ErrInvalidCursor marks a Cursor that no previous page produced
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DefaultTraversalMinActivation is the MinActivation of traversals in
+// namespaces that don't configure one
+const DefaultTraversalMinActivation = 0.05
+
+// maxSpreadHops caps MaxHops; activation has decayed to nothing well before
+const maxSpreadHops = 10
+
 // DefaultSpreadActivationOpts returns sensible defaults
 func DefaultSpreadActivationOpts() SpreadActivationOpts {
 	return SpreadActivationOpts{
 		DecayFactor:   0.7,
 		MaxHops:       3,
-		MinActivation: 0.05,
+		MinActivation: DefaultTraversalMinActivation,
 		MaxResults:    50,
 	}
 }
@@ -49,6 +74,29 @@ func DefaultSpreadActivationOpts() SpreadActivationOpts {
 // Includes cycle detection to prevent infinite loops in cyclic graphs.
 // SECURITY: Requires namespace to prevent cross-tenant data access
 func (c *Client) SpreadActivation(ctx context.Context, opts SpreadActivationOpts) ([]ActivatedNode, error) {
+	page, err := c.SpreadActivationPage(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return page.Nodes, nil
+}
+
+// SpreadActivationPage performs spreading activation and returns a single page
+// of results. Pass the returned NextCursor back in opts.Cursor to fetch the
+// next page of lower-activation neighbors.
+func (c *Client) SpreadActivationPage(ctx context.Context, opts SpreadActivationOpts) (*ActivationPage, error) {
+	return spreadActivationPage(ctx, opts, c.GetNode, c.getNeighborUIDs, c.logger)
+}
+
+// spreadActivationPage is SpreadActivationPage over any way of fetching a node
+// and its weighted neighbors within a namespace
+func spreadActivationPage(
+	ctx context.Context,
+	opts SpreadActivationOpts,
+	getNode func(ctx context.Context, uid string) (*Node, error),
+	getNeighbors func(ctx context.Context, uid, namespace string) ([]WeightedNeighbor, error),
+	logger *zap.Logger,
+) (*ActivationPage, error) {
 	if opts.StartUID == "" {
 		return nil, fmt.Errorf("StartUID is required")
 	}
@@ -64,15 +112,34 @@ func (c *Client) SpreadActivation(ctx context.Context, opts SpreadActivationOpts
 		return nil, fmt.Errorf("invalid namespace format")
 	}
 
+	// Reject a bad cursor before paying for the traversal
+	var boundary *ActivatedNode
+	if opts.Cursor != "" {
+		cursorActivation, cursorUID, err := decodeActivationCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		boundary = &ActivatedNode{Node: Node{UID: cursorUID}, Activation: cursorActivation}
+	}
+
 	if opts.DecayFactor <= 0 || opts.DecayFactor > 1 {
 		opts.DecayFactor = 0.5
 	}
 	if opts.MaxHops <= 0 {
 		opts.MaxHops = 3
 	}
+	if opts.MaxHops > maxSpreadHops {
+		opts.MaxHops = maxSpreadHops
+	}
 	if opts.MaxResults <= 0 {
 		opts.MaxResults = 50
 	}
+	if opts.MinActivation < 0 {
+		opts.MinActivation = 0
+	}
+	if opts.MinActivation > 1 {
+		opts.MinActivation = 1 // The start node alone
+	}
 
 	// SECURITY: Add bounds to prevent memory exhaustion
 	const (
@@ -104,7 +171,7 @@ func (c *Client) SpreadActivation(ctx context.Context, opts SpreadActivationOpts
 
 		// BOUNDS CHECK: Stop if we've visited too many nodes
 		if len(visited) >= maxVisitedNodes {
-			c.logger.Warn("SpreadActivation reached max visited nodes limit",
+			logger.Warn("SpreadActivation reached max visited nodes limit",
 				zap.Int("limit", maxVisitedNodes))
 			break
 		}
@@ -122,7 +189,7 @@ func (c *Client) SpreadActivation(ctx context.Context, opts SpreadActivationOpts
 		}
 
 		// Fetch the node
-		node, err := c.GetNode(ctx, current.uid)
+		node, err := getNode(ctx, current.uid)
 		if err != nil || node == nil {
 			continue
 		}
@@ -145,9 +212,9 @@ func (c *Client) SpreadActivation(ctx context.Context, opts SpreadActivationOpts
 		}
 
 		// Find neighbors via edges (pass namespace to prevent cross-tenant spreading)
-		neighbors, err := c.getNeighborUIDs(ctx, current.uid, opts.Namespace)
+		neighbors, err := getNeighbors(ctx, current.uid, opts.Namespace)
 		if err != nil {
-			c.logger.Warn("Failed to get neighbors",
+			logger.Warn("Failed to get neighbors",
 				zap.String("uid", current.uid),
 				zap.Error(err))
 			continue
@@ -188,21 +255,66 @@ func (c *Client) SpreadActivation(ctx context.Context, opts SpreadActivationOpts
 		}
 	}
 
-	// Convert to slice and sort by activation (descending)
+	// Convert to slice and sort by activation (descending), breaking ties by UID
+	// so that pages are stable across calls
 	result := make([]ActivatedNode, 0, len(visited))
 	for _, an := range visited {
 		result = append(result, *an)
 	}
 	sort.Slice(result, func(i, j int) bool {
-		return result[i].Activation > result[j].Activation
+		return activationLess(result[i], result[j])
 	})
 
+	page := &ActivationPage{TotalCount: len(result)}
+
+	// Skip everything up to and including the cursor position
+	if boundary != nil {
+		start := sort.Search(len(result), func(i int) bool {
+			return activationLess(*boundary, result[i])
+		})
+		result = result[start:]
+	}
+
 	// Limit results
 	if len(result) > opts.MaxResults {
 		result = result[:opts.MaxResults]
+		page.NextCursor = encodeActivationCursor(result[len(result)-1])
 	}
+	page.Nodes = result
 
-	return result, nil
+	return page, nil
+}
+
+// activationLess orders activated nodes by activation (descending) then UID (ascending)
+func activationLess(a, b ActivatedNode) bool {
+	if a.Activation != b.Activation {
+		return a.Activation > b.Activation
+	}
+	return a.Node.UID < b.Node.UID
+}
+
+// encodeActivationCursor builds an opaque cursor pointing just past the given node
+func encodeActivationCursor(last ActivatedNode) string {
+	raw := strconv.FormatFloat(last.Activation, 'g', -1, 64) + "|" + last.Node.UID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeActivationCursor parses a cursor produced by encodeActivationCursor;
+// anything else fails with ErrInvalidCursor
+func decodeActivationCursor(cursor string) (float64, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return 0, "", ErrInvalidCursor
+	}
+	activation, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || activation < 0 || math.IsNaN(activation) || math.IsInf(activation, 0) {
+		return 0, "", ErrInvalidCursor
+	}
+	return activation, parts[1], nil
 }
 
 // WeightedNeighbor represents a connected node with edge weight
@@ -606,23 +718,93 @@ func (c *Client) QueryWithTemporalDecay(ctx context.Context, opts TemporalQueryO
 // ExpandOpts configures multi-hop expansion
 type ExpandOpts struct {
 	StartUID   string   // Starting node UID
+	Namespace  string   // Limit to namespace (empty = no namespace filter)
 	EdgeTypes  []string // Edge types to follow (empty = all)
 	MaxHops    int      // Maximum depth
 	MaxResults int      // Limit total results
+
+	// Timeout bounds the traversal (0 = only the context's deadline). When
+	// either expires after the start node was found, the hops gathered so far
+	// are returned with Truncated set instead of an error.
+	Timeout time.Duration
+}
+
+// DefaultExpandTimeout is the Timeout API handlers give path and neighborhood
+// expansions, so a huge graph degrades to a partial result
+const DefaultExpandTimeout = 10 * time.Second
+
+// ExpandEdge is an edge traversed during expansion
+type ExpandEdge struct {
+	FromUID   string  `json:"from_uid"`
+	ToUID     string  `json:"to_uid"`
+	Predicate string  `json:"predicate"`
+	Weight    float64 `json:"weight"`
 }
 
 // ExpandResult contains nodes at each hop level
 type ExpandResult struct {
 	StartNode  Node           `json:"start_node"`
 	ByHop      map[int][]Node `json:"by_hop"` // Hop number -> nodes at that level
+	Edges      []ExpandEdge   `json:"edges,omitempty"`
 	TotalNodes int            `json:"total_nodes"`
+	Truncated  bool           `json:"truncated,omitempty"` // A deadline stopped expansion before MaxHops
+}
+
+// expandQueryFunc fetches nodes and their neighbors along predicates; it is
+// Client.expandQuery outside of tests
+type expandQueryFunc func(ctx context.Context, uids, predicates []string, namespace string) ([]json.RawMessage, error)
+
+// expandPredicates are the edges followed when ExpandOpts.EdgeTypes is empty:
+// every registered edge type, plus has_attribute
+var expandPredicates = func() []string {
+	preds := make([]string, 0, len(edgeTypeRegistry)+1)
+	for _, info := range edgeTypeRegistry {
+		preds = append(preds, info.Predicate)
+	}
+	return append(preds, "has_attribute")
+}()
+
+// withReverseEdges adds the reverse ("~pred") of every registered predicate
+// in predicates, so expansion also follows edges pointing at a node
+func withReverseEdges(predicates []string) []string {
+	out := append([]string(nil), predicates...)
+	for _, pred := range predicates {
+		if info, ok := edgeTypesByKey[pred]; ok && info.Predicate == pred {
+			out = append(out, info.Reverse)
+		}
+	}
+	return out
 }
 
-// ExpandFromNode performs multi-hop graph expansion from a starting node
+// expandNodeFields are the node predicates fetched during expansion
+const expandNodeFields = `uid
+			name
+			description
+			namespace
+			activation
+			dgraph.type`
+
+var (
+	// uidPattern matches a DGraph UID literal
+	uidPattern = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	// predicatePattern matches predicate names safe to interpolate into a query
+	predicatePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+)
+
+// ExpandFromNode performs multi-hop graph expansion from a starting node,
+// breadth first, returning the nodes grouped by hop and the edges between them
 func (c *Client) ExpandFromNode(ctx context.Context, opts ExpandOpts) (*ExpandResult, error) {
+	return expandFromNode(ctx, opts, c.expandQuery)
+}
+
+// expandFromNode is ExpandFromNode over any query function
+func expandFromNode(ctx context.Context, opts ExpandOpts, query expandQueryFunc) (*ExpandResult, error) {
 	if opts.StartUID == "" {
 		return nil, fmt.Errorf("StartUID is required")
 	}
+	if !uidPattern.MatchString(opts.StartUID) {
+		return nil, fmt.Errorf("invalid StartUID")
+	}
 	if opts.MaxHops <= 0 {
 		opts.MaxHops = 2
 	}
@@ -630,40 +812,234 @@ func (c *Client) ExpandFromNode(ctx context.Context, opts ExpandOpts) (*ExpandRe
 		opts.MaxResults = 100
 	}
 
-	// Build recursive expansion query
-	query := fmt.Sprintf(`query Expand {
-		node(func: uid(%s)) @recurse(depth: %d) {
-			uid
-			name
-			description
-			dgraph.type
-			related_to
-			has_attribute
+	predicates := expandPredicates
+	if len(opts.EdgeTypes) > 0 {
+		predicates = make([]string, 0, len(opts.EdgeTypes))
+		for _, t := range opts.EdgeTypes {
+			pred := edgeTypeToPredicateName(EdgeType(t))
+			if !predicatePattern.MatchString(pred) {
+				return nil, fmt.Errorf("invalid edge type %q", t)
+			}
+			predicates = append(predicates, pred)
+		}
+	}
+	predicates = withReverseEdges(predicates)
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	startNodes, err := query(ctx, []string{opts.StartUID}, nil, opts.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(startNodes) == 0 {
+		return nil, fmt.Errorf("start node not found")
+	}
+	var start Node
+	if err := json.Unmarshal(startNodes[0], &start); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+	if start.UID == "" || (opts.Namespace != "" && start.Namespace != opts.Namespace) {
+		return nil, fmt.Errorf("start node not found")
+	}
+
+	result := &ExpandResult{
+		StartNode: start,
+		ByHop:     map[int][]Node{0: {start}},
+	}
+	visited := map[string]bool{start.UID: true}
+	seenEdges := make(map[string]bool)
+	frontier := []string{start.UID}
+
+	for hop := 1; hop <= opts.MaxHops && len(frontier) > 0 && result.TotalNodes < opts.MaxResults; hop++ {
+		parents, err := query(ctx, frontier, predicates, opts.Namespace)
+		if err != nil {
+			// Out of time: the hops already gathered are still a valid
+			// (if smaller) neighborhood
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				result.Truncated = true
+				break
+			}
+			return nil, err
+		}
+
+		var next []string
+		for _, raw := range parents {
+			var parent map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &parent); err != nil {
+				continue
+			}
+			var parentUID string
+			json.Unmarshal(parent["uid"], &parentUID)
+
+			for _, pred := range predicates {
+				forward := strings.TrimPrefix(pred, "~")
+				for _, child := range decodeEdgeTargets(parent[pred]) {
+					var node Node
+					if b, err := json.Marshal(child); err != nil || json.Unmarshal(b, &node) != nil || node.UID == "" {
+						continue
+					}
+					if opts.Namespace != "" && node.Namespace != opts.Namespace {
+						continue
+					}
+
+					if !visited[node.UID] {
+						if result.TotalNodes >= opts.MaxResults {
+							continue
+						}
+						visited[node.UID] = true
+						result.ByHop[hop] = append(result.ByHop[hop], node)
+						result.TotalNodes++
+						next = append(next, node.UID)
+					}
+
+					// A reverse edge is reported the way it was written
+					from, to := parentUID, node.UID
+					if forward != pred {
+						from, to = to, from
+					}
+					edgeKey := from + "|" + forward + "|" + to
+					if seenEdges[edgeKey] {
+						continue
+					}
+					seenEdges[edgeKey] = true
+					weight := 0.5 // Default, matching CreateEdges
+					json.Unmarshal(child[pred+"|weight"], &weight)
+					result.Edges = append(result.Edges, ExpandEdge{
+						FromUID:   from,
+						ToUID:     to,
+						Predicate: forward,
+						Weight:    weight,
+					})
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return result, nil
+}
+
+// expandQuery fetches the given nodes and, for each predicate, their neighbors
+// (with edge weights), optionally restricted to a namespace
+func (c *Client) expandQuery(ctx context.Context, uids, predicates []string, namespace string) ([]json.RawMessage, error) {
+	for _, uid := range uids {
+		if !uidPattern.MatchString(uid) {
+			return nil, fmt.Errorf("invalid uid %q", uid)
+		}
+	}
+
+	filter := ""
+	if namespace != "" {
+		filter = " @filter(eq(namespace, $namespace))"
+	}
+	var edges strings.Builder
+	for _, pred := range predicates {
+		fmt.Fprintf(&edges, "\n\t\t\t%s @facets(weight)%s {\n\t\t\t%s\n\t\t\t}", pred, filter, expandNodeFields)
+	}
+
+	query := fmt.Sprintf(`query Expand($uids: string, $namespace: string) {
+		node(func: uid($uids)) {
+			%s%s
 		}
-	}`, opts.StartUID, opts.MaxHops+1)
+	}`, expandNodeFields, edges.String())
 
-	resp, err := c.Query(ctx, query, nil)
+	vars := map[string]string{
+		"$uids":      "[" + strings.Join(uids, ", ") + "]",
+		"$namespace": namespace,
+	}
+	resp, err := c.Query(ctx, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("failed to expand: %w", err)
 	}
 
 	var result struct {
-		Node []Node `json:"node"`
+		Node []json.RawMessage `json:"node"`
 	}
 	if err := json.Unmarshal(resp, &result); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal: %w", err)
 	}
+	return result.Node, nil
+}
 
-	if len(result.Node) == 0 {
-		return nil, fmt.Errorf("start node not found")
+// decodeEdgeTargets reads the targets of an edge predicate, which DGraph
+// returns as a list, or as a single object for a uid (not [uid]) predicate
+func decodeEdgeTargets(raw json.RawMessage) []map[string]json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	var targets []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &targets); err == nil {
+		return targets
 	}
+	var single map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &single); err != nil || single == nil {
+		return nil
+	}
+	return []map[string]json.RawMessage{single}
+}
 
-	// For now, return flat result (TODO: implement hop-level grouping)
-	return &ExpandResult{
-		StartNode:  result.Node[0],
-		ByHop:      map[int][]Node{0: result.Node},
-		TotalNodes: len(result.Node),
-	}, nil
+// GetEdgesBetween returns the edges whose endpoints are both in uids. Every
+// such edge leaves a node in uids, so only forward predicates are read.
+func (c *Client) GetEdgesBetween(ctx context.Context, uids []string) ([]ExpandEdge, error) {
+	if len(uids) < 2 {
+		return nil, nil
+	}
+	for _, uid := range uids {
+		if !uidPattern.MatchString(uid) {
+			return nil, fmt.Errorf("invalid uid %q", uid)
+		}
+	}
+
+	var edges strings.Builder
+	for _, pred := range expandPredicates {
+		fmt.Fprintf(&edges, "\n\t\t\t%s @facets(weight) @filter(uid($uids)) { uid }", pred)
+	}
+	query := fmt.Sprintf(`query EdgesBetween($uids: string) {
+		node(func: uid($uids)) {
+			uid%s
+		}
+	}`, edges.String())
+
+	resp, err := c.Query(ctx, query, map[string]string{"$uids": "[" + strings.Join(uids, ", ") + "]"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query edges: %w", err)
+	}
+
+	var result struct {
+		Node []map[string]json.RawMessage `json:"node"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	var out []ExpandEdge
+	for _, node := range result.Node {
+		var from string
+		json.Unmarshal(node["uid"], &from)
+		for _, pred := range expandPredicates {
+			for _, target := range decodeEdgeTargets(node[pred]) {
+				var to string
+				json.Unmarshal(target["uid"], &to)
+				if to == "" || to == from {
+					continue
+				}
+				weight := 0.5
+				json.Unmarshal(target[pred+"|weight"], &weight)
+				out = append(out, ExpandEdge{FromUID: from, ToUID: to, Predicate: pred, Weight: weight})
+			}
+		}
+	}
+	return out, nil
+}
+
+// CommunityOf returns the community (department, team, ...) recorded in a
+// node's description, or "" when there is none
+func CommunityOf(node *Node) string {
+	return extractCommunity(node)
 }
 
 // GetSampleNodes returns sample nodes from the graph for visualization
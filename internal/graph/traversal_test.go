@@ -0,0 +1,181 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// wideGraph serves expansion queries over a tree where every node has fanout
+// related_to children, taking hopDelay per query
+func wideGraph(fanout int, hopDelay time.Duration) expandQueryFunc {
+	next := 1
+	return func(ctx context.Context, uids, predicates []string, namespace string) ([]json.RawMessage, error) {
+		select {
+		case <-time.After(hopDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var nodes []json.RawMessage
+		for _, uid := range uids {
+			node := map[string]interface{}{"uid": uid, "name": "node " + uid}
+			if len(predicates) > 0 {
+				children := make([]map[string]interface{}, fanout)
+				for i := range children {
+					child := fmt.Sprintf("0x%x", next)
+					next++
+					children[i] = map[string]interface{}{"uid": child, "name": "node " + child}
+				}
+				node["related_to"] = children
+			}
+			raw, _ := json.Marshal(node)
+			nodes = append(nodes, raw)
+		}
+		return nodes, nil
+	}
+}
+
+func TestExpandFromNodePartialOnDeadline(t *testing.T) {
+	opts := ExpandOpts{
+		StartUID:   "0x0",
+		EdgeTypes:  []string{"related_to"},
+		MaxHops:    10,
+		MaxResults: 1 << 20,
+		Timeout:    150 * time.Millisecond,
+	}
+	result, err := expandFromNode(context.Background(), opts, wideGraph(20, 40*time.Millisecond))
+	if err != nil {
+		t.Fatalf("expected a partial result, got %v", err)
+	}
+	if !result.Truncated {
+		t.Error("Truncated = false, want true")
+	}
+	if len(result.ByHop[1]) != 20 {
+		t.Errorf("hop 1 has %d nodes, want the 20 gathered before the deadline", len(result.ByHop[1]))
+	}
+	if len(result.ByHop) >= 10 {
+		t.Errorf("expansion reached %d hops despite the deadline", len(result.ByHop))
+	}
+
+	// With time to spare the same traversal completes
+	opts.MaxHops, opts.Timeout = 2, time.Second
+	result, err = expandFromNode(context.Background(), opts, wideGraph(20, time.Millisecond))
+	if err != nil || result.Truncated || result.TotalNodes != 20+400 {
+		t.Errorf("full expansion = %d nodes, truncated %v, err %v", result.TotalNodes, result.Truncated, err)
+	}
+}
+
+func TestExpandFromNodeCancelIsAnError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	query := wideGraph(5, 0)
+	calls := 0
+	_, err := expandFromNode(ctx, ExpandOpts{StartUID: "0x0", MaxHops: 3}, func(ctx context.Context, uids, predicates []string, namespace string) ([]json.RawMessage, error) {
+		if calls++; calls > 1 {
+			cancel()
+			return nil, ctx.Err()
+		}
+		return query(ctx, uids, predicates, namespace)
+	})
+	if err == nil {
+		t.Error("a cancelled expansion returned a result; only deadlines degrade to partial results")
+	}
+}
+
+// TestExpandFromNodeEdgeShapes follows single-valued edges, which DGraph
+// returns as an object, and edges pointing at the node being expanded
+func TestExpandFromNodeEdgeShapes(t *testing.T) {
+	graph := map[string]string{
+		"0x1": `{"uid": "0x1", "name": "Project", "~works_on": [{"uid": "0x2", "name": "Alice", "~works_on|weight": 0.9}]}`,
+		"0x2": `{"uid": "0x2", "name": "Alice", "has_manager": {"uid": "0x3", "name": "Bob"}, "works_on": [{"uid": "0x1", "name": "Project"}]}`,
+		"0x3": `{"uid": "0x3", "name": "Bob"}`,
+	}
+	query := func(ctx context.Context, uids, predicates []string, namespace string) ([]json.RawMessage, error) {
+		var nodes []json.RawMessage
+		for _, uid := range uids {
+			nodes = append(nodes, json.RawMessage(graph[uid]))
+		}
+		return nodes, nil
+	}
+
+	result, err := expandFromNode(context.Background(), ExpandOpts{StartUID: "0x1", MaxHops: 2}, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalNodes != 2 {
+		t.Errorf("expanded to %d nodes, want Alice and Bob", result.TotalNodes)
+	}
+	want := []ExpandEdge{
+		{FromUID: "0x2", ToUID: "0x1", Predicate: "works_on", Weight: 0.9},
+		{FromUID: "0x2", ToUID: "0x3", Predicate: "has_manager", Weight: 0.5},
+	}
+	if len(result.Edges) != len(want) {
+		t.Fatalf("edges = %+v, want %+v", result.Edges, want)
+	}
+	for i, e := range want {
+		if result.Edges[i] != e {
+			t.Errorf("edge %d = %+v, want %+v", i, result.Edges[i], e)
+		}
+	}
+}
+
+func TestSpreadActivationPagesWithCursor(t *testing.T) {
+	store := NewMemStore()
+	ctx := context.Background()
+
+	// A star: every leaf ties on activation, so pages split ties by UID
+	hub, _ := store.CreateNode(ctx, &Node{Name: "Hub", Namespace: "user_alice"})
+	for i := 0; i < 7; i++ {
+		leaf, _ := store.CreateNode(ctx, &Node{Name: fmt.Sprintf("Leaf %d", i), Namespace: "user_alice"})
+		store.CreateEdge(ctx, hub, leaf, EdgeTypeKnows, EdgeStatusCurrent)
+	}
+
+	opts := SpreadActivationOpts{StartUID: hub, Namespace: "user_alice", MaxHops: 1, MaxResults: 3}
+	seen := make(map[string]bool)
+	var pages int
+	for {
+		page, err := store.SpreadActivationPage(ctx, opts)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		if page.TotalCount != 8 {
+			t.Errorf("page %d: TotalCount = %d, want 8", pages, page.TotalCount)
+		}
+		for _, n := range page.Nodes {
+			if seen[n.Node.UID] {
+				t.Errorf("node %s returned on two pages", n.Node.UID)
+			}
+			seen[n.Node.UID] = true
+		}
+		if pages++; page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	if pages != 3 || len(seen) != 8 {
+		t.Errorf("paged %d nodes over %d pages, want 8 over 3", len(seen), pages)
+	}
+}
+
+func TestSpreadActivationRejectsInvalidCursor(t *testing.T) {
+	store := NewMemStore()
+	ctx := context.Background()
+	hub, _ := store.CreateNode(ctx, &Node{Name: "Hub", Namespace: "user_alice"})
+
+	for _, cursor := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("0.5")),
+		base64.RawURLEncoding.EncodeToString([]byte("0.5|")),
+		base64.RawURLEncoding.EncodeToString([]byte("high|0x1")),
+		base64.RawURLEncoding.EncodeToString([]byte("NaN|0x1")),
+		base64.RawURLEncoding.EncodeToString([]byte("-1|0x1")),
+	} {
+		_, err := store.SpreadActivationPage(ctx, SpreadActivationOpts{StartUID: hub, Namespace: "user_alice", Cursor: cursor})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("cursor %q: err = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultUserRelationDepth follows relations two hops out from the user, far
+// enough for "who is my manager's manager"
+const DefaultUserRelationDepth = 2
+
+// userRelationPredicates are the edges followed out from the user node
+var userRelationPredicates = []string{
+	"knows", "has_manager", "colleague", "works_at", "works_on",
+	"friend_of", "family_member", "partner_is",
+}
+
+// RelatedNode is a node reached from the user, with the predicates followed
+// to reach it (["has_manager", "has_manager"] for the manager's manager)
+type RelatedNode struct {
+	Node Node     `json:"node"`
+	Path []string `json:"path"`
+}
+
+// GetUserRelatedNodes retrieves nodes connected to the user via relationship
+// predicates, up to depth hops out, never leaving namespace. Returns nothing
+// when the user has no node in the namespace yet.
+func (q *QueryBuilder) GetUserRelatedNodes(ctx context.Context, namespace, userID string, depth, limit int) ([]RelatedNode, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	userNode, err := q.client.FindNodeByName(ctx, namespace, userID, NodeTypeUser)
+	if err != nil || userNode == nil {
+		// User node not found - this is expected for new users
+		// Return empty rather than error to allow fallback search
+		return nil, nil
+	}
+	return userRelatedNodes(ctx, q.client.ExpandFromNode, userNode.UID, namespace, depth, limit)
+}
+
+// userRelatedNodes expands from the user node and rebuilds the path to each
+// node from the edge that first reached it
+func userRelatedNodes(ctx context.Context, expand func(context.Context, ExpandOpts) (*ExpandResult, error), userUID, namespace string, depth, limit int) ([]RelatedNode, error) {
+	if depth <= 0 {
+		depth = DefaultUserRelationDepth
+	}
+	result, err := expand(ctx, ExpandOpts{
+		StartUID:   userUID,
+		Namespace:  namespace,
+		MaxHops:    depth,
+		EdgeTypes:  userRelationPredicates,
+		MaxResults: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Expansion records the discovering edge before any other edge into a node
+	via := make(map[string]ExpandEdge)
+	for _, e := range result.Edges {
+		if _, ok := via[e.ToUID]; !ok && e.ToUID != userUID {
+			via[e.ToUID] = e
+		}
+	}
+
+	var related []RelatedNode
+	for hop := 1; hop <= depth; hop++ {
+		for _, node := range result.ByHop[hop] {
+			path := make([]string, hop)
+			for uid, i := node.UID, hop-1; i >= 0; i-- {
+				e := via[uid]
+				path[i] = e.Predicate
+				uid = e.FromUID
+			}
+			related = append(related, RelatedNode{Node: node, Path: path})
+		}
+	}
+	return related, nil
+}
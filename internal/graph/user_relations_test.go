@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// relationGraph serves expansion queries over a fixed set of nodes and edges
+// without applying the namespace filter, as a misbehaving store would
+func relationGraph(nodes map[string]Node, edges map[string]map[string][]string) func(context.Context, ExpandOpts) (*ExpandResult, error) {
+	query := func(ctx context.Context, uids, predicates []string, namespace string) ([]json.RawMessage, error) {
+		var out []json.RawMessage
+		for _, uid := range uids {
+			n, ok := nodes[uid]
+			if !ok {
+				continue
+			}
+			node := map[string]interface{}{"uid": n.UID, "name": n.Name, "namespace": n.Namespace}
+			for _, pred := range predicates {
+				var children []map[string]interface{}
+				for _, to := range edges[uid][pred] {
+					c := nodes[to]
+					children = append(children, map[string]interface{}{"uid": c.UID, "name": c.Name, "namespace": c.Namespace})
+				}
+				if children != nil {
+					node[pred] = children
+				}
+			}
+			raw, _ := json.Marshal(node)
+			out = append(out, raw)
+		}
+		return out, nil
+	}
+	return func(ctx context.Context, opts ExpandOpts) (*ExpandResult, error) {
+		return expandFromNode(ctx, opts, query)
+	}
+}
+
+func TestUserRelatedNodesTwoHopsWithinNamespace(t *testing.T) {
+	nodes := map[string]Node{
+		"0x1": {UID: "0x1", Name: "alice", Namespace: "user_alice"},
+		"0x2": {UID: "0x2", Name: "Bob", Namespace: "user_alice"},
+		"0x3": {UID: "0x3", Name: "Carol", Namespace: "user_alice"},
+		"0x4": {UID: "0x4", Name: "Mallory", Namespace: "user_eve"},
+		"0x5": {UID: "0x5", Name: "Dave", Namespace: "user_alice"},
+	}
+	edges := map[string]map[string][]string{
+		"0x1": {"has_manager": {"0x2"}, "knows": {"0x4"}},
+		"0x2": {"has_manager": {"0x3"}},
+		"0x4": {"knows": {"0x5"}}, // Only reachable through the foreign node
+	}
+
+	related, err := userRelatedNodes(context.Background(), relationGraph(nodes, edges), "0x1", "user_alice", 2, 10)
+	if err != nil {
+		t.Fatalf("userRelatedNodes: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for _, r := range related {
+		got[r.Node.Name] = r.Path
+	}
+	want := map[string][]string{
+		"Bob":   {"has_manager"},
+		"Carol": {"has_manager", "has_manager"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("related = %v, want %v", got, want)
+	}
+
+	related, _ = userRelatedNodes(context.Background(), relationGraph(nodes, edges), "0x1", "user_alice", 1, 10)
+	if len(related) != 1 || related[0].Node.Name != "Bob" {
+		t.Errorf("depth 1 reached %v, want only Bob", related)
+	}
+}
@@ -0,0 +1,30 @@
+package ingester
+
+import (
+	"context"
+
+	"github.com/reflective-memory-kernel/internal/ai/router"
+)
+
+// routerEmbedder embeds chunk texts through the LLM router, one request per
+// batch, at the provider's own dimension; the vector tree is built to match
+type routerEmbedder struct {
+	router *router.Router
+}
+
+// EmbedBatch implements vectorindex.Embedder
+func (e *routerEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	resp, err := e.router.Embed(ctx, &router.EmbedRequest{Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([][]float64, len(resp.Embeddings))
+	for i, embedding := range resp.Embeddings {
+		vector := make([]float64, len(embedding))
+		for j, v := range embedding {
+			vector[j] = float64(v)
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
@@ -6,7 +6,10 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -98,6 +101,8 @@ type Config struct {
 	MaxLLMCalls    int
 	MaxVisionCalls int
 	LLMProvider    router.Provider
+	// UploadDir is the only directory IngestFile will read from
+	UploadDir      string
 }
 
 // DefaultConfig returns default ingester configuration
@@ -108,9 +113,18 @@ func DefaultConfig() *Config {
 		MaxLLMCalls:    10,
 		MaxVisionCalls: 5,
 		LLMProvider:    router.ProviderNVIDIA,
+		UploadDir:      defaultUploadDir(),
 	}
 }
 
+// defaultUploadDir matches the agent's staging dir unless UPLOAD_DIR overrides it
+func defaultUploadDir() string {
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "rmk-uploads")
+}
+
 // Service handles document ingestion
 type Service struct {
 	config       *Config
@@ -139,16 +153,28 @@ func New(cfg *Config, router *router.Router, logger *zap.Logger) *Service {
 		RespectSentence:  true,
 	}
 
+	// Dimension 0: each tree takes the dimension its embeddings come back with
+	vectorIndex := vectorindex.NewIndexBuilder(10, 0, logger)
+	if router != nil {
+		vectorIndex.WithEmbedder(&routerEmbedder{router: router})
+	}
+
 	return &Service{
 		config:      cfg,
 		router:     router,
 		chunker:     chunking.New(chunkerConfig),
-		vectorIndex: vectorindex.NewIndexBuilder(10, 1536, logger),
+		vectorIndex: vectorIndex,
 		validator:   validation.DefaultConfig(),
 		logger:     logger,
 	}
 }
 
+// SetEmbedder replaces the embedder chunks are embedded with before the
+// vector tree is built; nil disables embedding
+func (s *Service) SetEmbedder(embedder vectorindex.Embedder) {
+	s.vectorIndex.WithEmbedder(embedder)
+}
+
 // IngestText ingests plain text
 func (s *Service) IngestText(ctx context.Context, text string, filename string) (*IngestionResult, error) {
 	start := time.Now()
@@ -177,6 +203,43 @@ func (s *Service) IngestBase64Content(ctx context.Context, contentB64, docType,
 		return nil, fmt.Errorf("failed to decode base64: %w", err)
 	}
 
+	return s.ingestContent(ctx, content, docType, filename, start)
+}
+
+// ErrFileUnavailable is returned by IngestFile for a path it can't read: one
+// outside the upload dir, or missing because the agent staged it elsewhere
+var ErrFileUnavailable = errors.New("uploaded file is not available")
+
+// IngestFile ingests a document staged on disk by the agent's upload handler.
+// The path must lie inside the configured upload dir.
+func (s *Service) IngestFile(ctx context.Context, path, docType, filename string) (*IngestionResult, error) {
+	start := time.Now()
+
+	uploadDir, err := filepath.Abs(s.config.UploadDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upload dir: %w", err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file path: %w", err)
+	}
+	if rel, err := filepath.Rel(uploadDir, absPath); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("%w: file path is outside the upload directory", ErrFileUnavailable)
+	}
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFileUnavailable, err)
+	}
+	if filename == "" {
+		filename = filepath.Base(absPath)
+	}
+
+	return s.ingestContent(ctx, content, docType, filename, start)
+}
+
+// ingestContent routes decoded document bytes by type
+func (s *Service) ingestContent(ctx context.Context, content []byte, docType, filename string, start time.Time) (*IngestionResult, error) {
 	if docType == "pdf" || strings.HasSuffix(filename, ".pdf") {
 		// For PDF, we'd extract text and images
 		// For now, treat as text since we don't have PDF parser
@@ -291,6 +354,11 @@ func (s *Service) processDocument(ctx context.Context, pages []PageInfo, images
 
 	// Build vector tree if we have embeddings
 	var vectorTree map[string]*vectorindex.VectorNode
+	if s.vectorIndex.Embedder != nil {
+		if err := s.GenerateEmbeddings(ctx, chunks); err != nil {
+			s.logger.Warn("Chunk embedding failed, skipping vector tree", zap.Error(err))
+		}
+	}
 	if s.hasEmbeddings(chunks) {
 		inputChunks := make([]vectorindex.Chunk, len(chunks))
 		for i, chunk := range chunks {
@@ -571,10 +639,22 @@ func (s *Service) hasEmbeddings(chunks []Chunk) bool {
 	return false
 }
 
-// GenerateEmbeddings generates embeddings for all chunks
+// GenerateEmbeddings embeds every chunk that lacks an embedding, in batches.
+// On error no chunk is changed.
 func (s *Service) GenerateEmbeddings(ctx context.Context, chunks []Chunk) error {
-	// This would call an embedding service
-	// For now, this is a placeholder
+	inputChunks := make([]vectorindex.Chunk, len(chunks))
+	for i, chunk := range chunks {
+		inputChunks[i] = vectorindex.Chunk{
+			Text:      chunk.Text,
+			Embedding: chunk.Embedding,
+		}
+	}
+	if err := s.vectorIndex.EmbedChunks(ctx, inputChunks); err != nil {
+		return err
+	}
+	for i := range chunks {
+		chunks[i].Embedding = inputChunks[i].Embedding
+	}
 	return nil
 }
 
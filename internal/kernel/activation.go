@@ -0,0 +1,30 @@
+package kernel
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// GetActivationConfig returns the activation boost/decay parameters in effect
+// for a namespace: its override if one was set, otherwise the kernel defaults
+func (k *Kernel) GetActivationConfig(ctx context.Context, namespace string) graph.ActivationConfig {
+	return k.activationConfigs.Get(ctx, namespace)
+}
+
+// SetActivationConfig overrides a namespace's activation parameters. Access
+// boosts in this process pick the change up immediately, other kernel
+// processes within 30 seconds.
+func (k *Kernel) SetActivationConfig(ctx context.Context, namespace string, config graph.ActivationConfig) error {
+	if err := k.activationConfigs.Set(ctx, namespace, config); err != nil {
+		return err
+	}
+	k.logger.Info("Activation config updated",
+		zap.String("namespace", namespace),
+		zap.Float64("boost_per_access", config.BoostPerAccess),
+		zap.Float64("max_activation", config.MaxActivation),
+		zap.Float64("decay_rate", config.DecayRate))
+	return nil
+}
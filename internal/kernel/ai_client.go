@@ -0,0 +1,26 @@
+package kernel
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultAIServiceTimeout bounds a single consultation call to the AI service;
+// the synthesis breaker takes over once the service keeps failing
+const DefaultAIServiceTimeout = 10 * time.Second
+
+// aiServiceMaxIdleConns is how many idle connections to the AI service are
+// kept for reuse, about the number of consultations expected in flight. The
+// default transport keeps two, so concurrent consultations mostly redial.
+const aiServiceMaxIdleConns = 64
+
+// newAIServiceClient returns the HTTP client consultations share for calls to
+// the AI service, keeping connections open between them instead of dialing
+// (and handshaking) on every consult
+func newAIServiceClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = aiServiceMaxIdleConns
+	transport.MaxIdleConnsPerHost = aiServiceMaxIdleConns
+	transport.IdleConnTimeout = 90 * time.Second
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
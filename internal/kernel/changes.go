@@ -0,0 +1,109 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// maxChangesPerKind caps the node and edge changes returned by RecentChanges
+	maxChangesPerKind = 500
+	// DefaultChangesWindow is how far back callers look when no since is given
+	DefaultChangesWindow = 7 * 24 * time.Hour
+)
+
+// ParseSince accepts an RFC3339 timestamp or a YYYY-MM-DD date (UTC midnight)
+func ParseSince(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", raw)
+}
+
+// ChangeDay groups one UTC day of changes: nodes by type, edges by predicate
+type ChangeDay struct {
+	Date  string                        `json:"date"` // YYYY-MM-DD
+	Nodes map[string][]graph.NodeChange `json:"nodes,omitempty"`
+	Edges map[string][]graph.EdgeChange `json:"edges,omitempty"`
+}
+
+// ChangeSummary is what the memory learned in a namespace since a point in time
+type ChangeSummary struct {
+	Namespace    string      `json:"namespace"`
+	Since        time.Time   `json:"since"`
+	Days         []ChangeDay `json:"days"` // Newest first
+	NodesCreated int         `json:"nodes_created"`
+	NodesUpdated int         `json:"nodes_updated"`
+	EdgesAdded   int         `json:"edges_added"`
+	Truncated    bool        `json:"truncated,omitempty"`
+}
+
+// RecentChanges returns the nodes created or updated and the edges added in a
+// namespace since the given time, grouped by day and type
+func (k *Kernel) RecentChanges(ctx context.Context, namespace string, since time.Time) (*ChangeSummary, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+
+	nodes, nodesTruncated, err := k.graphClient.NodeChangesSince(ctx, namespace, since, maxChangesPerKind)
+	if err != nil {
+		return nil, err
+	}
+	edges, edgesTruncated, err := k.graphClient.EdgeChangesSince(ctx, namespace, since, maxChangesPerKind)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := summarizeChanges(nodes, edges)
+	summary.Namespace = namespace
+	summary.Since = since
+	summary.Truncated = nodesTruncated || edgesTruncated
+	return summary, nil
+}
+
+// summarizeChanges counts node and edge changes and groups them by UTC day,
+// newest day first
+func summarizeChanges(nodes []graph.NodeChange, edges []graph.EdgeChange) *ChangeSummary {
+	summary := &ChangeSummary{EdgesAdded: len(edges)}
+
+	days := make(map[string]*ChangeDay)
+	day := func(t time.Time) *ChangeDay {
+		date := t.UTC().Format("2006-01-02")
+		d, ok := days[date]
+		if !ok {
+			d = &ChangeDay{
+				Date:  date,
+				Nodes: make(map[string][]graph.NodeChange),
+				Edges: make(map[string][]graph.EdgeChange),
+			}
+			days[date] = d
+		}
+		return d
+	}
+
+	for _, n := range nodes {
+		if n.Change == graph.ChangeCreated {
+			summary.NodesCreated++
+		} else {
+			summary.NodesUpdated++
+		}
+		d := day(n.At)
+		d.Nodes[n.Type] = append(d.Nodes[n.Type], n)
+	}
+	for _, e := range edges {
+		d := day(e.At)
+		d.Edges[e.Predicate] = append(d.Edges[e.Predicate], e)
+	}
+
+	summary.Days = make([]ChangeDay, 0, len(days))
+	for _, d := range days {
+		summary.Days = append(summary.Days, *d)
+	}
+	sort.Slice(summary.Days, func(i, j int) bool { return summary.Days[i].Date > summary.Days[j].Date })
+
+	return summary
+}
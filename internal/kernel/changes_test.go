@@ -0,0 +1,73 @@
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func TestParseSince(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want time.Time
+	}{
+		{"2026-03-01T10:30:00Z", time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"2026-03-01T10:30:00+02:00", time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		got, err := ParseSince(tc.raw)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", tc.raw, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "yesterday", "2026-13-01", "01/03/2026", "2026-03-01 10:30"} {
+		if _, err := ParseSince(bad); err == nil {
+			t.Errorf("ParseSince(%q) accepted", bad)
+		}
+	}
+}
+
+func TestSummarizeChangesGroupsByUTCDay(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	nodes := []graph.NodeChange{
+		{UID: "0x1", Type: "Entity", Change: graph.ChangeCreated, At: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{UID: "0x2", Type: "Fact", Change: graph.ChangeUpdated, At: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)},
+		// 00:30 in Berlin is still the previous day in UTC
+		{UID: "0x3", Type: "Entity", Change: graph.ChangeCreated, At: time.Date(2026, 3, 2, 0, 30, 0, 0, berlin)},
+		{UID: "0x4", Type: "Entity", Change: graph.ChangeCreated, At: time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)},
+	}
+	edges := []graph.EdgeChange{
+		{FromUID: "0x1", Predicate: "works_at", ToUID: "0x4", At: time.Date(2026, 3, 2, 9, 1, 0, 0, time.UTC)},
+		{FromUID: "0x3", Predicate: "knows", ToUID: "0x1", At: time.Date(2026, 2, 27, 12, 0, 0, 0, time.UTC)},
+	}
+
+	summary := summarizeChanges(nodes, edges)
+	if summary.NodesCreated != 3 || summary.NodesUpdated != 1 || summary.EdgesAdded != 2 {
+		t.Errorf("counts = %d created, %d updated, %d edges; want 3, 1, 2",
+			summary.NodesCreated, summary.NodesUpdated, summary.EdgesAdded)
+	}
+
+	var dates []string
+	for _, d := range summary.Days {
+		dates = append(dates, d.Date)
+	}
+	if len(dates) != 3 || dates[0] != "2026-03-02" || dates[1] != "2026-03-01" || dates[2] != "2026-02-27" {
+		t.Fatalf("days = %v, want 2026-03-02, 2026-03-01, 2026-02-27", dates)
+	}
+
+	latest := summary.Days[0]
+	if len(latest.Nodes["Entity"]) != 2 || len(latest.Nodes["Fact"]) != 1 || len(latest.Edges["works_at"]) != 1 {
+		t.Errorf("2026-03-02 = %+v, want 2 entities, 1 fact and 1 works_at edge", latest)
+	}
+	if len(summary.Days[1].Nodes["Entity"]) != 1 || summary.Days[1].Nodes["Entity"][0].UID != "0x3" {
+		t.Errorf("2026-03-01 = %+v, want the node created just after midnight in Berlin", summary.Days[1])
+	}
+	if len(summary.Days[2].Edges["knows"]) != 1 || len(summary.Days[2].Nodes) != 0 {
+		t.Errorf("2026-02-27 = %+v, want only the knows edge", summary.Days[2])
+	}
+
+	if empty := summarizeChanges(nil, nil); len(empty.Days) != 0 || empty.Days == nil {
+		t.Errorf("no changes = %+v, want an empty day list", empty.Days)
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,14 +20,18 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/reflective-memory-kernel/internal/ai/local"
+	"github.com/reflective-memory-kernel/internal/ai/synthesis"
 	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/memory"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"github.com/reflective-memory-kernel/internal/policy"
+	"github.com/reflective-memory-kernel/internal/reflection"
+	"github.com/reflective-memory-kernel/internal/textnorm"
 )
 
 // ConsultationHandler handles consultation requests from the Front-End Agent
 type ConsultationHandler struct {
-	graphClient   *graph.Client
+	graphClient   graph.Store
 	queryBuilder  *graph.QueryBuilder
 	redisClient   *redis.Client
 	aiServicesURL string
@@ -40,8 +46,63 @@ type ConsultationHandler struct {
 
 	// Policy Manager
 	policyManager *policy.PolicyManager
+
+	// Response cache for repeated consultations (nil = disabled)
+	cache *ConsultationCache
+
+	// Per-namespace activation parameters for reconsolidation boosts
+	activationConfigs *reflection.ActivationConfigStore
+
+	// Memories younger than this rank higher while they settle (0 = no boost)
+	recencyBoostWindow time.Duration
+
+	// Stop words and stemming applied to keyword searches
+	normalizer *textnorm.Normalizer
+
+	// Facts a template brief lists before counting the rest
+	briefMaxFacts int
+
+	// Time a consultation may take when its request sets none (0 = unbounded)
+	budget time.Duration
+
+	// Shared by calls to the AI service, so they reuse connections
+	aiClient *http.Client
+
+	// Synthesis fails fast through the breaker while the AI service is down
+	synthesisBreaker   *CircuitBreaker
+	synthesisFallbacks atomic.Int64
+
+	// Intent classification and reranking each have their own breaker, so a
+	// failing endpoint only stops its own calls and a failing synthesis
+	// never stops them
+	intentBreaker *CircuitBreaker
+	rerankBreaker *CircuitBreaker
+
+	// Set once the AI service turns out to have no intent router
+	noIntentRouter atomic.Bool
+
+	// LLM rerank budget and score cache; noReranker is set once the AI
+	// service turns out to have no /rerank
+	reranker   *reranker
+	noReranker atomic.Bool
+
+	// Semantic retrieval availability; a Qdrant outage degrades, never fails
+	vectorStats vectorSearchStats
 }
 
+const (
+	// DefaultRecencyBoostWindow is how long freshly ingested memories get a
+	// ranking bonus, so they surface before reflection has raised their activation
+	DefaultRecencyBoostWindow = 15 * time.Minute
+
+	// recencyBoostMax is the bonus a memory gets the moment it is ingested;
+	// it falls linearly to nothing over the boost window
+	recencyBoostMax = 0.3
+
+	// pinnedBoost is the bonus a memory the user pinned always gets
+	pinnedBoost = 0.2
+)
+
 // Speculative cache validation constants
 const (
 	MaxSpeculativeQueries = 100  // Maximum speculative queries per user per hour
@@ -70,7 +131,7 @@ func truncateString(s string, maxLen int) string {
 
 // NewConsultationHandler creates a new consultation handler
 func NewConsultationHandler(
-	graphClient *graph.Client,
+	graphClient graph.Store,
 	queryBuilder *graph.QueryBuilder,
 	redisClient *redis.Client,
 	vectorIndex *VectorIndex,
@@ -90,13 +151,87 @@ func NewConsultationHandler(
 		vectorIndex:   vectorIndex,
 		hotCache:      hotCache,
 		policyManager: policyManager,
+		normalizer:    textnorm.Default,
+		briefMaxFacts: synthesis.DefaultMaxFacts,
+		aiClient:      newAIServiceClient(DefaultAIServiceTimeout),
+
+		synthesisBreaker: NewCircuitBreaker(logger.Named("synthesis_breaker")),
+		intentBreaker:    NewCircuitBreaker(logger.Named("intent_breaker")),
+		rerankBreaker:    NewCircuitBreaker(logger.Named("rerank_breaker")),
+		reranker:         newReranker(DefaultRerankMaxCandidates, DefaultRerankCandidatesPerMinute),
 	}
 }
 
-// Handle processes a consultation request and returns a synthesized response
-// SIMPLIFIED: Directly queries user's knowledge and formats it without external AI call
+// SetCache configures the response cache used by Handle
+func (h *ConsultationHandler) SetCache(cache *ConsultationCache) {
+	h.cache = cache
+}
+
+// SetActivationConfigs configures the per-namespace activation parameters
+func (h *ConsultationHandler) SetActivationConfigs(configs *reflection.ActivationConfigStore) {
+	h.activationConfigs = configs
+}
+
+// SetRecencyBoost configures how long freshly ingested memories are ranked up
+// (non-positive disables the boost)
+func (h *ConsultationHandler) SetRecencyBoost(window time.Duration) {
+	h.recencyBoostWindow = window
+}
+
+// SetBudget configures how long a consultation may take when its request
+// sets no budget (non-positive leaves it unbounded)
+func (h *ConsultationHandler) SetBudget(budget time.Duration) {
+	h.budget = budget
+}
+
+// SetAIServiceTimeout configures how long a single call to the AI service may
+// take (non-positive keeps DefaultAIServiceTimeout)
+func (h *ConsultationHandler) SetAIServiceTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		h.aiClient.Timeout = timeout
+	}
+}
+
+// SetBriefMaxFacts configures how many facts a brief written without the AI
+// service lists, matching the AI service's SYNTHESIS_MAX_FACTS (non-positive
+// keeps synthesis.DefaultMaxFacts)
+func (h *ConsultationHandler) SetBriefMaxFacts(n int) {
+	if n > 0 {
+		h.briefMaxFacts = n
+	}
+}
+
+// SetStopWords adds words that keyword searches ignore on top of
+// textnorm.DefaultStopWords
+func (h *ConsultationHandler) SetStopWords(words []string) {
+	h.normalizer = textnorm.New(words...)
+}
+
+// recencyBonus is the fused-score bonus for a memory created at createdAt:
+// recencyBoostMax when brand new, fading linearly to 0 at the end of window
+func recencyBonus(createdAt, now time.Time, window time.Duration) float64 {
+	if window <= 0 || createdAt.IsZero() {
+		return 0
+	}
+	age := now.Sub(createdAt)
+	if age < 0 {
+		age = 0 // Clock skew between services
+	}
+	if age >= window {
+		return 0
+	}
+	return recencyBoostMax * (1 - float64(age)/float64(window))
+}
+
+// Handle processes a consultation request and returns a synthesized response.
+// The brief comes from the AI service when one is configured; if it is down the
+// consultation still succeeds with a fallback brief built from the raw facts.
+// Subsystems that failed along the way are listed in DegradedModes.
+// Each step gets a share of the request's time budget; once it runs out, the
+// facts gathered so far are answered with and DegradedModes says so.
 func (h *ConsultationHandler) Handle(ctx context.Context, req *graph.ConsultationRequest) (*graph.ConsultationResponse, error) {
 	startTime := time.Now()
+	budget := newConsultBudget(h.budgetFor(req.BudgetMs), startTime)
 	h.logger.Info("=== CONSULTATION START ===",
 		zap.String("user_id", req.UserID),
 		zap.String("query", req.Query))
@@ -108,11 +243,11 @@ func (h *ConsultationHandler) Handle(ctx context.Context, req *graph.Consultatio
 	// Step 0: Determine Namespace
 	namespace := req.Namespace
 	if namespace == "" {
-		namespace = fmt.Sprintf("user_%s", req.UserID)
+		namespace = nsutil.ForUser(req.UserID)
 	}
 
 	// PERMISSION CHECK: For group namespaces, verify user is a member
-	if strings.HasPrefix(namespace, "group_") {
+	if nsutil.IsGroup(namespace) {
 		isMember, err := h.graphClient.IsWorkspaceMember(ctx, namespace, req.UserID)
 		if err != nil {
 			h.logger.Error("Failed to check workspace membership", zap.Error(err))
@@ -127,15 +262,25 @@ func (h *ConsultationHandler) Handle(ctx context.Context, req *graph.Consultatio
 		h.logger.Debug("Workspace access verified", zap.String("namespace", namespace))
 	}
 
+	// Additional namespaces (other workspaces the user can read) for cross-workspace recall
+	namespaces := []string{namespace}
+	if len(req.Namespaces) > 0 || req.IncludeGroups {
+		namespaces = h.consultNamespaces(ctx, req, namespace)
+	}
+
 	// STEP 0: Check Hot Cache (most recent messages - instant retrieval)
 	// Hot cache contains the last 50 messages per user, providing O(1) access
 	var facts []graph.Node
 	var err error
 	var hotCacheHit bool
+	degraded := &degradedModes{}
 
 	if h.hotCache != nil {
 		hotCacheResults, hcErr := h.hotCache.Search(req.UserID, namespace, req.Query, 5, 0.6)
-		if hcErr == nil && len(hotCacheResults) > 0 {
+		if hcErr != nil {
+			h.logger.Warn("Hot cache search failed", zap.Error(hcErr))
+			degraded.add(graph.DegradedModeHotCache)
+		} else if len(hotCacheResults) > 0 {
 			h.logger.Info("Hot cache hit - recent messages found",
 				zap.Int("results", len(hotCacheResults)),
 				zap.Float32("similarity", hotCacheResults[0].Similarity))
@@ -155,33 +300,75 @@ func (h *ConsultationHandler) Handle(ctx context.Context, req *graph.Consultatio
 		}
 	}
 
+	// STEP 0.25: Check the response cache. Only full retrievals are cached:
+	// hot-cache answers follow the live conversation, and speculative or too-short
+	// (partial) queries aren't worth keeping. Multi-namespace answers aren't cached
+	// because invalidation is per namespace.
+	cacheable := !hotCacheHit && len(namespaces) == 1 && len(strings.TrimSpace(req.Query)) >= SpeculativeQueryMinLen
+	if cacheable {
+		if cached, ok := h.cache.Get(ctx, namespace, req); ok {
+			cached.RequestID = response.RequestID
+			h.logger.Info("=== CONSULTATION COMPLETE (cached) ===",
+				zap.Int("facts", len(cached.RelevantFacts)),
+				zap.Duration("latency", time.Since(startTime)))
+			h.reconsolidate(cached.RelevantFacts)
+			return cached, nil
+		}
+	}
+
 	// STEP 0.5: Check Speculative Cache (Time Travel) if hot cache miss
-	if !hotCacheHit {
-		cachedFacts, cacheErr := h.checkSpeculationCache(ctx, req.UserID, req.Query)
+	retrievalCtx, cancelRetrieval := budget.step(ctx, retrievalBudgetShare)
+	if !hotCacheHit && len(namespaces) > 1 {
+		// STEP 1 (multi-namespace): merge facts from every authorized namespace.
+		// The speculative cache is per user, not per namespace set, so skip it.
+		plan := h.planRetrieval(retrievalCtx, req.Query)
+		response.RetrievalStrategy = plan.Strategy
+		facts, err = h.getKnowledgeAcross(retrievalCtx, namespaces, req.UserID, req.Query, plan, degraded)
+		if err != nil {
+			h.logger.Warn("Failed to get knowledge from some namespaces", zap.Error(err))
+		}
+	} else if !hotCacheHit {
+		cachedFacts, cacheErr := h.checkSpeculationCache(retrievalCtx, req.UserID, req.Query)
 		if cacheErr == nil && cachedFacts != nil {
 			h.logger.Info("Hit speculative cache (Time Travel successful)", zap.Int("facts", len(cachedFacts)))
 			facts = cachedFacts
+			cacheable = false
 		} else {
-			// STEP 1: Get facts matching the query terms (Cache Miss)
-				facts, err = h.getUserKnowledge(ctx, namespace, req.UserID, req.Query)
+			// STEP 1: Get facts matching the query terms (Cache Miss), along
+			// the retrieval paths the query calls for
+			plan := h.planRetrieval(retrievalCtx, req.Query)
+			response.RetrievalStrategy = plan.Strategy
+			facts, err = h.getUserKnowledge(retrievalCtx, namespace, req.UserID, req.Query, plan, degraded)
 			if err != nil {
 				h.logger.Warn("Failed to get user knowledge", zap.Error(err))
+				cacheable = false // Don't pin a partial answer
 			}
 		}
 	}
+	if budget.ranOut(ctx, retrievalCtx) {
+		h.logger.Warn("Consultation budget ran out during retrieval, answering with what was found",
+			zap.Int("facts", len(facts)))
+		degraded.add(graph.DegradedModeBudget)
+	}
+	cancelRetrieval()
+
 	// STEP 1.5: Policy Enforcement (Filter Facts)
 	// Even if we found the facts, we must verify the user is allowed to see them.
 	// This enforces ABAC (Clearance) and RBAC (Policies) at the data retrieval layer.
 	var allowedFacts []graph.Node
 	if h.policyManager != nil {
+		policyCtx, cancelPolicy := budget.step(ctx, policyBudgetShare)
+
 		// CRITICAL: Load policies from DGraph before evaluation
 		// Without this, the engine has no policies to check against!
-		if err := h.policyManager.LoadPolicies(ctx, namespace); err != nil {
-			h.logger.Warn("Failed to load policies from store", zap.Error(err))
+		for _, ns := range namespaces {
+			if err := h.policyManager.LoadPolicies(policyCtx, ns); err != nil {
+				h.logger.Warn("Failed to load policies from store", zap.String("namespace", ns), zap.Error(err))
+			}
 		}
 
 		// Build UserContext (fetch groups, clearance, etc.)
-		userCtx, err := h.buildUserContext(ctx, req.UserID)
+		userCtx, err := h.buildUserContext(policyCtx, req.UserID)
 		if err != nil {
 			// Don't fail the entire consultation - use default context and proceed
 			// User is authenticated (token verified), just missing DGraph metadata
@@ -195,8 +382,12 @@ func (h *ConsultationHandler) Handle(ctx context.Context, req *graph.Consultatio
 		}
 
 		for _, fact := range facts {
+			// Facts not evaluated before the budget ran out are withheld, never passed unchecked
+			if policyCtx.Err() != nil {
+				break
+			}
 			// Evaluate "READ" action on this resource
-			effect, err := h.policyManager.Evaluate(ctx, userCtx, &fact, policy.ActionRead)
+			effect, err := h.policyManager.Evaluate(policyCtx, userCtx, &fact, policy.ActionRead)
 			if err != nil {
 				h.logger.Warn("Policy evaluation error", zap.Error(err), zap.String("node", fact.UID))
 				continue // Skip on error
@@ -212,128 +403,258 @@ func (h *ConsultationHandler) Handle(ctx context.Context, req *graph.Consultatio
 			}
 		}
 		facts = allowedFacts // Update facts with filtered list
+		if budget.ranOut(ctx, policyCtx) {
+			h.logger.Warn("Consultation budget ran out during policy checks, answering with the facts cleared so far",
+				zap.Int("facts", len(facts)))
+			degraded.add(graph.DegradedModeBudget)
+		}
+		cancelPolicy()
+	}
+
+	// STEP 1.75: Collapse near-duplicate facts so the brief doesn't repeat itself
+	facts, response.CollapsedDuplicates = h.collapseNearDuplicates(facts)
+
+	// STEP 1.78: Reorder by LLM relevance scores when the request asks for it
+	if req.Rerank && h.aiServicesURL != "" && len(facts) > 1 {
+		rerankCtx, cancelRerank := budget.step(ctx, rerankBudgetShare)
+		reranked, err := h.rerankFacts(rerankCtx, req.Query, facts)
+		if err != nil {
+			h.logger.Warn("Rerank unavailable, keeping retrieval order", zap.Error(err))
+			degraded.add(graph.DegradedModeRerank)
+		} else {
+			facts = reranked
+			response.Reranked = true
+		}
+		if budget.ranOut(ctx, rerankCtx) {
+			degraded.add(graph.DegradedModeBudget)
+		}
+		cancelRerank()
 	}
 
 	response.RelevantFacts = facts
 
 	h.logger.Info("Retrieved user knowledge (after policy filter)",
-		zap.String("namespace", namespace),
-		zap.Int("facts_count", len(facts)))
-
-	// STEP 2: Format facts directly into a brief (no external AI call)
-	var brief strings.Builder
-	if len(facts) > 0 {
-		brief.WriteString("Based on what you've told me:\n")
-		for i, fact := range facts {
-			if i >= 10 {
-				brief.WriteString(fmt.Sprintf("... and %d more items.\n", len(facts)-10))
-				break
-			}
-			nodeType := fact.GetType()
-			brief.WriteString(fmt.Sprintf("- %s", fact.Name))
-			if fact.Description != "" {
-				brief.WriteString(fmt.Sprintf(": %s", fact.Description))
-			}
-			if len(fact.Tags) > 0 {
-				brief.WriteString(fmt.Sprintf(" [%s]", strings.Join(fact.Tags, ", ")))
-			}
-			brief.WriteString(fmt.Sprintf(" (%s)\n", nodeType))
+		zap.Strings("namespaces", namespaces),
+		zap.Int("facts_count", len(facts)),
+		zap.Int("collapsed_duplicates", response.CollapsedDuplicates))
+
+	// STEP 1.8: Relevant insights (filtered by confidence and query relevance)
+	if req.IncludeInsights {
+		insightsCtx, cancelInsights := budget.step(ctx, insightsBudgetShare)
+		insights, err := h.getRelevantInsights(insightsCtx, req)
+		if err != nil {
+			h.logger.Warn("Failed to get insights", zap.Error(err))
+		}
+		if budget.ranOut(ctx, insightsCtx) {
+			degraded.add(graph.DegradedModeBudget)
+		}
+		cancelInsights()
+		response.Insights = insights
+	}
+
+	// STEP 2: Synthesize the brief with whatever budget is left
+	synthesisCtx, cancelSynthesis := budget.rest(ctx)
+	defer cancelSynthesis()
+	if h.aiServicesURL != "" && (len(facts) > 0 || len(response.Insights) > 0) && synthesisCtx.Err() != nil {
+		degraded.add(graph.DegradedModeBudget)
+		response.SynthesizedBrief = h.createFallbackBrief(response)
+		response.Confidence = 0.5
+	} else if h.aiServicesURL != "" && (len(facts) > 0 || len(response.Insights) > 0) {
+		brief, confidence, available, err := h.synthesize(synthesisCtx, req, response)
+		if budget.ranOut(ctx, synthesisCtx) {
+			degraded.add(graph.DegradedModeBudget)
+		}
+		if err != nil {
+			// Degrade rather than fail: chat should stay responsive when the SLM is down
+			h.synthesisFallbacks.Add(1)
+			h.logger.Warn("AI synthesis unavailable, using fallback brief",
+				zap.Error(err),
+				zap.Int64("fallbacks", h.synthesisFallbacks.Load()))
+			degraded.add(graph.DegradedModeSynthesis)
+			response.SynthesizedBrief = h.createFallbackBrief(response)
+			response.Confidence = 0.5
+			cacheable = false // Don't pin a degraded answer
+		} else {
+			response.SynthesizedBrief = brief
+			response.Confidence = confidence
+			response.SynthesisAvailable = available
 		}
-		response.Confidence = 0.9
 	} else {
-		brief.WriteString("I don't have any stored information about you yet.")
-		response.Confidence = 0.3
+		response.SynthesizedBrief, response.Confidence = formatBrief(facts, response.Insights, h.briefMaxFacts)
 	}
 
-	response.SynthesizedBrief = brief.String()
+	response.DegradedModes = degraded.list()
+	if len(response.DegradedModes) > 0 {
+		cacheable = false // Don't pin a degraded answer
+	}
 
+	h.logger.Debug("Consultation brief", zap.String("brief", response.SynthesizedBrief))
 	h.logger.Info("=== CONSULTATION COMPLETE ===",
-		zap.String("brief", response.SynthesizedBrief),
 		zap.Int("facts", len(facts)),
+		zap.Strings("degraded_modes", response.DegradedModes),
 		zap.Duration("latency", time.Since(startTime)))
 
+	if cacheable {
+		h.cache.Put(ctx, namespace, req, response)
+	}
+
 	// STEP 3: Async Activation Boost (Active Synthesis / Memory Reconsolidation)
-	// We boost the activation of nodes that were actually used (retrieved).
-	// This implements "Memory Reconsolidation" - recalling a memory strengthens it.
-	if len(response.RelevantFacts) > 0 {
-		// Limit to top 20 to avoid performance spikes
-		limit := 20
-		if len(response.RelevantFacts) < limit {
-			limit = len(response.RelevantFacts)
+	h.reconsolidate(response.RelevantFacts)
+
+	return response, nil
+}
+
+// reconsolidate asynchronously boosts the activation of nodes that were actually
+// used (retrieved). This implements "Memory Reconsolidation" - recalling a
+// memory strengthens it.
+func (h *ConsultationHandler) reconsolidate(facts []graph.Node) {
+	// Limit to top 20 to avoid performance spikes; synthetic facts (hot cache)
+	// have no UID and nothing to boost
+	var nodes []graph.Node
+	for _, fact := range facts {
+		if len(nodes) == 20 {
+			break
 		}
+		if fact.UID != "" {
+			nodes = append(nodes, fact)
+		}
+	}
+	if len(nodes) == 0 || h.graphClient == nil {
+		return
+	}
 
-		factsToBoost := make([]graph.Node, limit)
-		copy(factsToBoost, response.RelevantFacts[:limit])
+	go func() {
+		// Create a detached context with timeout
+		boostCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
 
-		go func(nodes []graph.Node) {
-			// Create a detached context with timeout
-			boostCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer cancel()
+		var boost []graph.Node
+		for _, node := range nodes {
+			config := h.activationConfigs.Get(boostCtx, node.Namespace)
+			// Don't boost if it's already maxed out (optimization)
+			if node.Activation >= config.MaxActivation {
+				continue
+			}
+			boost = append(boost, node)
+		}
 
-			// Use the kernel's activation config logic (or default for now)
-			config := graph.DefaultActivationConfig()
+		if err := h.boostAccessed(boostCtx, boost); err != nil {
+			h.logger.Debug("Failed to reconsolidate memory (boost)",
+				zap.Int("nodes", len(boost)),
+				zap.Error(err))
+		}
+	}()
+}
 
-			for _, node := range nodes {
-				// Don't boost if it's already maxed out (optimization)
-				if node.Activation >= config.MaxActivation {
-					continue
-				}
+// boostAccessed bumps the access count and activation of nodes with one read
+// and one write per namespace, under that namespace's activation parameters
+func (h *ConsultationHandler) boostAccessed(ctx context.Context, nodes []graph.Node) error {
+	byNamespace := make(map[string][]string)
+	for _, node := range nodes {
+		byNamespace[node.Namespace] = append(byNamespace[node.Namespace], node.UID)
+	}
 
-				if err := h.graphClient.IncrementAccessCount(boostCtx, node.UID, config); err != nil {
-					h.logger.Debug("Failed to reconsolidate memory (boost)",
-						zap.String("uid", node.UID),
-						zap.Error(err))
-				}
-			}
-		}(factsToBoost)
+	var errs []error
+	for namespace, uids := range byNamespace {
+		config := h.activationConfigs.Get(ctx, namespace)
+		if err := h.graphClient.IncrementAccessCounts(ctx, uids, config); err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", namespace, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyTemporalValidity annotates facts that are not valid at now with their validity
+// window and drops archived nodes and superseded facts that have no recorded end date
+func applyTemporalValidity(nodes []graph.Node, now time.Time) []graph.Node {
+	kept := nodes[:0]
+	for _, node := range nodes {
+		// Pruned nodes are retired from retrieval
+		if node.Status == graph.NodeStatusArchived {
+			continue
+		}
+		if node.IsValidAt(now) {
+			kept = append(kept, node)
+			continue
+		}
+		switch {
+		case node.ValidUntil != nil && !now.Before(*node.ValidUntil):
+			node.Description = strings.TrimSpace(fmt.Sprintf("%s [no longer current: valid until %s]",
+				node.Description, node.ValidUntil.Format("2006-01-02")))
+			node.Tags = append(append([]string(nil), node.Tags...), graph.FactStatusHistorical)
+		case node.ValidFrom != nil && now.Before(*node.ValidFrom):
+			node.Description = strings.TrimSpace(fmt.Sprintf("%s [not yet current: valid from %s]",
+				node.Description, node.ValidFrom.Format("2006-01-02")))
+		default:
+			continue
+		}
+		kept = append(kept, node)
 	}
+	return kept
+}
 
-	return response, nil
+// getUserKnowledge retrieves a namespace's facts for a query. When neither the
+// hybrid retrieval nor a fulltext match finds anything relevant, the query is
+// broadened once through query expansion before giving up.
+func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, userID, queryText string, plan retrievalPlan, degraded *degradedModes) ([]graph.Node, error) {
+	return h.withQueryExpansion(ctx, queryText, func(text string) ([]graph.Node, error) {
+		return h.lookupKnowledge(ctx, namespace, userID, text, plan, degraded)
+	})
+}
+
+// isKnowledgeNode reports whether a retrieved node is a fact worth returning
+// from namespace, rather than a user, group, conversation or batch record
+func isKnowledgeNode(node graph.Node, namespace string) bool {
+	// SECURITY: Namespace check FIRST (defense-in-depth)
+	// This ensures nodes from other namespaces are filtered out before any other processing
+	if node.Namespace != namespace {
+		return false
+	}
+	if node.Name == "" {
+		return false
+	}
+	// Skip User and Group nodes by checking dgraph.type (not name!)
+	nodeType := node.GetType()
+	if nodeType == graph.NodeTypeUser || nodeType == graph.NodeTypeGroup {
+		return false
+	}
+	// Skip Conversation_ nodes (these are conversation metadata, not facts)
+	if len(node.Name) > 13 && node.Name[:13] == "Conversation_" {
+		return false
+	}
+	// Skip user_xxx IDs (user identifiers, not knowledge)
+	if len(node.Name) > 5 && node.Name[:5] == "user_" {
+		return false
+	}
+	// Skip UUID-like names (8-4-4-4-12 pattern or just long hex strings)
+	if isUUIDLike(node.Name) {
+		return false
+	}
+	// Skip generic "Batch Summary" nodes - return the actual entities
+	if node.Name == "Batch Summary" {
+		return false
+	}
+	return true
 }
 
-// getUserKnowledge retrieves stored facts using Hybrid RAG approach:
+// retrieveKnowledge retrieves stored facts using Hybrid RAG approach:
 // 1. Vector search for semantically similar nodes (NEW - Hybrid RAG)
 // 2. High activation nodes (frequently accessed)
 // 3. Recent nodes (newly added)
-// This ensures semantic relevance, importance, AND freshness are all considered
-func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, userID, queryText string) ([]graph.Node, error) {
-	h.logger.Info("Fetching knowledge with Hybrid RAG approach", zap.String("query", queryText))
+// This ensures semantic relevance, importance, AND freshness are all considered.
+// The plan picks which of these run, and adds edge traversal from the user's
+// node or a keyword match where the query calls for them.
+func (h *ConsultationHandler) retrieveKnowledge(ctx context.Context, namespace, userID, queryText string, plan retrievalPlan, degraded *degradedModes) ([]graph.Node, error) {
+	h.logger.Info("Fetching knowledge with Hybrid RAG approach",
+		zap.String("query", queryText),
+		zap.String("strategy", plan.Strategy))
 
 	seen := make(map[string]bool)
 	var merged []graph.Node
 
 	// Helper to check if node should be included
 	isValidNode := func(node graph.Node) bool {
-		// SECURITY: Namespace check FIRST (defense-in-depth)
-		// This ensures nodes from other namespaces are filtered out before any other processing
-		if node.Namespace != namespace {
-			return false
-		}
-		if node.Name == "" {
-			return false
-		}
-		// Skip User and Group nodes by checking dgraph.type (not name!)
-		nodeType := node.GetType()
-		if nodeType == graph.NodeTypeUser || nodeType == graph.NodeTypeGroup {
-			return false
-		}
-		// Skip Conversation_ nodes (these are conversation metadata, not facts)
-		if len(node.Name) > 13 && node.Name[:13] == "Conversation_" {
-			return false
-		}
-		// Skip user_xxx IDs (user identifiers, not knowledge)
-		if len(node.Name) > 5 && node.Name[:5] == "user_" {
-			return false
-		}
-		// Skip UUID-like names (8-4-4-4-12 pattern or just long hex strings)
-		if isUUIDLike(node.Name) {
-			return false
-		}
-		// Skip generic "Batch Summary" nodes - return the actual entities
-		if node.Name == "Batch Summary" {
-			return false
-		}
-		return true
+		return isKnowledgeNode(node, namespace)
 	}
 
 	// STEP 1: Vector search for semantically similar nodes (Hybrid RAG)
@@ -342,14 +663,19 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 		return nil, fmt.Errorf("namespace cannot be empty for knowledge retrieval")
 	}
 
-	if h.embedder != nil && h.vectorIndex != nil {
+	if plan.Vector && h.embedder != nil && h.vectorIndex != nil {
 		queryVec, err := h.embedder.Embed(queryText)
 		if err != nil {
 			h.logger.Warn("Failed to embed query for vector search", zap.Error(err))
+			degraded.add(graph.DegradedModeVector)
 		} else if len(queryVec) > 0 {
-			uids, scores, payloads, err := h.vectorIndex.Search(ctx, namespace, userID, queryVec, 20)
+			searchCtx, cancel := context.WithTimeout(ctx, vectorSearchTimeout)
+			uids, scores, payloads, err := h.vectorIndex.Search(searchCtx, namespace, userID, queryVec, 20)
+			cancel()
+			h.vectorStats.record(err)
 			if err != nil {
-				h.logger.Warn("Vector search failed", zap.Error(err))
+				h.logger.Warn("Vector search failed, continuing with graph retrieval", zap.Error(err))
+				degraded.add(graph.DegradedModeVector)
 			} else if len(uids) > 0 {
 				h.logger.Info("Vector search found candidates",
 					zap.Int("count", len(uids)),
@@ -376,6 +702,9 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 						}
 
 						// Add metadata if available
+						if created, ok := payload["created_at"].(string); ok {
+							snippetNode.CreatedAt, _ = time.Parse(time.RFC3339, created)
+						}
 						if page, ok := payload["page_number"].(float64); ok {
 							snippetNode.Attributes = map[string]string{
 								"page": fmt.Sprintf("%.0f", page),
@@ -394,7 +723,7 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 
 				// Fetch full node data for Entity matches
 				if len(entityUIDs) > 0 {
-					vectorNodes, err := h.graphClient.GetNodesByUIDs(ctx, entityUIDs)
+					vectorNodes, err := h.graphClient.GetNodesByUIDs(ctx, entityUIDs, graph.NodeFieldsConsultation)
 					if err != nil {
 						h.logger.Warn("Failed to fetch vector search results", zap.Error(err))
 					} else {
@@ -466,19 +795,52 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 			zap.Int("total_nodes", len(merged)))
 	}
 
-	// STEP 2: Get nodes by activation and recency (existing logic)
-	query := `query HybridKnowledge($namespace: string) {
-		by_activation(func: has(name), first: 50, orderdesc: activation) @filter(eq(namespace, $namespace)) {
-			uid
-			dgraph.type
-			name
-			description
-			namespace
-			tags
-			activation
-			created_at
+	// STEP 1.6: Keyword matches on names, descriptions and tags
+	if plan.FullText {
+		for _, node := range h.searchFullText(ctx, namespace, queryText) {
+			if !seen[node.UID] {
+				seen[node.UID] = true
+				merged = append(merged, node)
+			}
 		}
-		by_recency(func: has(name), first: 50, orderdesc: created_at) @filter(eq(namespace, $namespace)) {
+	}
+
+	if h.graphClient == nil {
+		return merged, nil
+	}
+
+	// STEP 1.7: Relationships of the user ("who is my manager"), walked from
+	// the user's own node rather than from whatever the query text matched
+	if plan.Relations {
+		for _, node := range h.userRelations(ctx, namespace, userID) {
+			if !seen[node.UID] && isValidNode(node) {
+				seen[node.UID] = true
+				merged = append(merged, node)
+			}
+		}
+	}
+
+	// STEP 2: Get nodes by activation and recency (existing logic). Pinned
+	// nodes are read from the namespace, so other users' pins never fill the page.
+	var blocks []string
+	if plan.Activation {
+		blocks = append(blocks, "by_activation(func: has(name), first: 50, orderdesc: activation) @filter(eq(namespace, $namespace))")
+		blocks = append(blocks, "pinned(func: eq(namespace, $namespace), first: 50) @filter(eq(pinned, true))")
+	}
+	if plan.Recency {
+		blocks = append(blocks, "by_recency(func: has(name), first: 50, orderdesc: created_at) @filter(eq(namespace, $namespace))")
+	}
+	var result struct {
+		ByActivation []graph.Node `json:"by_activation"`
+		ByRecency    []graph.Node `json:"by_recency"`
+		Pinned       []graph.Node `json:"pinned"`
+	}
+	if len(blocks) > 0 {
+		var query strings.Builder
+		query.WriteString("query HybridKnowledge($namespace: string) {")
+		for _, block := range blocks {
+			fmt.Fprintf(&query, `
+		%s {
 			uid
 			dgraph.type
 			name
@@ -487,22 +849,23 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 			tags
 			activation
 			created_at
+			valid_from
+			valid_until
+			status
+			pinned
+		}`, block)
 		}
-	}`
+		query.WriteString("\n\t}")
 
-	resp, err := h.graphClient.Query(ctx, query, map[string]string{"$namespace": namespace})
-	if err != nil {
-		h.logger.Error("Query failed", zap.Error(err))
-		return merged, err // Return vector results if we have them
-	}
-
-	var result struct {
-		ByActivation []graph.Node `json:"by_activation"`
-		ByRecency    []graph.Node `json:"by_recency"`
-	}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		h.logger.Error("Failed to unmarshal nodes", zap.Error(err))
-		return merged, err
+		resp, err := h.graphClient.Query(ctx, query.String(), map[string]string{"$namespace": namespace})
+		if err != nil {
+			h.logger.Error("Query failed", zap.Error(err))
+			return merged, err // Return vector results if we have them
+		}
+		if err := json.Unmarshal(resp, &result); err != nil {
+			h.logger.Error("Failed to unmarshal nodes", zap.Error(err))
+			return merged, err
+		}
 	}
 
 	// Add high-activation nodes (after vector results)
@@ -513,6 +876,14 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 		}
 	}
 
+	// Pinned nodes are candidates however low their activation
+	for _, node := range result.Pinned {
+		if !seen[node.UID] && isValidNode(node) {
+			seen[node.UID] = true
+			merged = append(merged, node)
+		}
+	}
+
 	// Add recent nodes that weren't already included
 	for _, node := range result.ByRecency {
 		if !seen[node.UID] && isValidNode(node) {
@@ -521,6 +892,11 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 		}
 	}
 
+	// Facts outside their validity window are kept as history but labelled,
+	// so "where did I live?" isn't answered with a past address
+	now := time.Now()
+	merged = applyTemporalValidity(merged, now)
+
 	vectorCount := 0
 	if h.embedder != nil && h.vectorIndex != nil {
 		for _, node := range merged {
@@ -541,16 +917,17 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 	// Combine vector similarity scores and graph activation scores
 	// Use weighted formula: final_score = 0.6 * vector_similarity + 0.4 * graph_activation
 	// This balances semantic relevance with knowledge graph importance
+	// Memories ingested within the recency window get a fading bonus on top,
+	// since reflection hasn't had a chance to raise their activation yet, and
+	// pinned memories a fixed one
 	type fusedNode struct {
 		node  graph.Node
 		score float64
 	}
 
 	var fused []fusedNode
-	const (
-		vectorWeight = 0.6 // Weight for semantic similarity
-		graphWeight  = 0.4 // Weight for graph activation
-	)
+	vectorWeight := plan.VectorWeight // Weight for semantic similarity
+	graphWeight := 1 - vectorWeight   // Weight for graph activation
 
 	for _, node := range merged {
 		// Get vector similarity from Confidence field (set during vector search)
@@ -570,6 +947,10 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 
 		// Calculate fused score
 		fusedScore := vectorWeight*vectorScore + graphWeight*graphScore
+		fusedScore += recencyBonus(node.CreatedAt, now, h.recencyBoostWindow)
+		if node.Pinned {
+			fusedScore += pinnedBoost
+		}
 
 		fused = append(fused, fusedNode{
 			node:  node,
@@ -577,8 +958,12 @@ func (h *ConsultationHandler) getUserKnowledge(ctx context.Context, namespace, u
 		})
 	}
 
-	// Sort by fused score (descending)
+	// Sort by fused score (descending), or newest first when the query asks
+	// what happened lately
 	sort.Slice(fused, func(i, j int) bool {
+		if plan.NewestFirst && !fused[i].node.CreatedAt.Equal(fused[j].node.CreatedAt) {
+			return fused[i].node.CreatedAt.After(fused[j].node.CreatedAt)
+		}
 		return fused[i].score > fused[j].score
 	})
 
@@ -652,49 +1037,14 @@ func getString(m map[string]interface{}, key string) string {
 	return ""
 }
 
-// cleanQuery removes common stop words to focus search on keywords
+// cleanQuery removes stop words and stems what is left to focus search on
+// keywords, normalized the way node names and descriptions are indexed
 func (h *ConsultationHandler) cleanQuery(query string) string {
-	stopWords := map[string]bool{
-		"what": true, "is": true, "my": true, "the": true, "a": true, "an": true,
-		"of": true, "for": true, "in": true, "on": true, "at": true, "to": true,
-		"do": true, "does": true, "did": true, "can": true, "could": true,
-		"who": true, "where": true, "when": true, "why": true, "how": true,
-		"tell": true, "me": true, "about": true, "know": true,
-	}
-
-	words := strings.Fields(strings.ToLower(query))
-	var keywords []string
-	for _, w := range words {
-		// Strip punctuation
-		w = strings.Trim(w, "?!.,\"'")
-		if !stopWords[w] && len(w) > 1 {
-			keywords = append(keywords, w)
-		}
-	}
-	return strings.Join(keywords, " ")
-}
-
-// checkCache checks Redis for a cached response
-func (h *ConsultationHandler) checkCache(ctx context.Context, req *graph.ConsultationRequest) (string, error) {
-	// Create a cache key from the query
-	key := fmt.Sprintf("consultation:%s:%s", req.UserID, hashQuery(req.Query))
-
-	cached, err := h.redisClient.Get(ctx, key).Result()
-	if err != nil {
-		return "", err
-	}
-
-	return cached, nil
-}
-
-// hashQuery creates a simple hash of a query for caching
-func hashQuery(query string) string {
-	// Simple hash - in production, use a proper hash function
-	h := 0
-	for _, c := range query {
-		h = 31*h + int(c)
-	}
-	return fmt.Sprintf("%x", h&0x7fffffff)
+	cleaned := h.normalizer.Normalize(query)
+	h.logger.Debug("Cleaned search query",
+		zap.String("query", query),
+		zap.String("cleaned", cleaned))
+	return cleaned
 }
 
 // findRelevantFacts searches the knowledge graph for facts relevant to the query
@@ -712,7 +1062,7 @@ func (h *ConsultationHandler) findRelevantFacts(ctx context.Context, req *graph.
 	// Search by text
 	namespace := req.Namespace
 	if namespace == "" {
-		namespace = fmt.Sprintf("user_%s", req.UserID)
+		namespace = nsutil.ForUser(req.UserID)
 	}
 	nodes, err := h.queryBuilder.SearchByText(ctx, namespace, req.Query, maxResults)
 	if err != nil {
@@ -722,7 +1072,7 @@ func (h *ConsultationHandler) findRelevantFacts(ctx context.Context, req *graph.
 
 	// CRITICAL: Also get nodes connected to the User via relationship edges
 	// This is essential for queries like "Who is my manager?" where text search won't match "Bob"
-	userRelated, err := h.queryBuilder.GetUserRelatedNodes(ctx, req.UserID, maxResults)
+	userRelated, err := h.queryBuilder.GetUserRelatedNodes(ctx, namespace, req.UserID, graph.DefaultUserRelationDepth, maxResults)
 	if err != nil {
 		h.logger.Warn("Failed to get user related nodes", zap.Error(err), zap.String("user_id", req.UserID))
 	} else {
@@ -732,8 +1082,14 @@ func (h *ConsultationHandler) findRelevantFacts(ctx context.Context, req *graph.
 		for _, n := range nodes {
 			seen[n.UID] = true
 		}
-		for _, n := range userRelated {
+		for _, r := range userRelated {
+			n := r.Node
 			if !seen[n.UID] {
+				// The path tells synthesis a manager's manager from a manager
+				if n.Attributes == nil {
+					n.Attributes = make(map[string]string, 1)
+				}
+				n.Attributes["relation_to_user"] = strings.Join(r.Path, " > ")
 				nodes = append(nodes, n)
 				seen[n.UID] = true
 			}
@@ -767,7 +1123,7 @@ func (h *ConsultationHandler) findRelevantFacts(ctx context.Context, req *graph.
 		} else {
 			// Filter out User nodes, keep only Entity/Fact/Event nodes
 			for _, n := range allNodes {
-				if n.GetType() != graph.NodeTypeUser && n.Name != "" {
+				if !n.HasType(graph.NodeTypeUser) && n.Name != "" {
 					nodes = append(nodes, n)
 				}
 			}
@@ -791,28 +1147,11 @@ func (h *ConsultationHandler) findRelevantFacts(ctx context.Context, req *graph.
 	return nodes, nil
 }
 
-// getRelevantInsights retrieves insights that may be relevant to the query
-func (h *ConsultationHandler) getRelevantInsights(ctx context.Context, req *graph.ConsultationRequest) ([]graph.Insight, error) {
-	// Get recent insights
-	namespace := req.Namespace
-	if namespace == "" {
-		namespace = fmt.Sprintf("user_%s", req.UserID)
-	}
-	insights, err := h.queryBuilder.GetInsights(ctx, namespace, 5)
-	if err != nil {
-		return nil, err
-	}
-
-	// TODO: Filter insights by relevance to the query
-	// For now, return all recent insights
-	return insights, nil
-}
-
 // checkPatterns checks for patterns that might be relevant (proactive assistance)
 func (h *ConsultationHandler) checkPatterns(ctx context.Context, req *graph.ConsultationRequest) ([]graph.Pattern, []string) {
 	namespace := req.Namespace
 	if namespace == "" {
-		namespace = fmt.Sprintf("user_%s", req.UserID)
+		namespace = nsutil.ForUser(req.UserID)
 	}
 	patterns, err := h.queryBuilder.GetPatterns(ctx, namespace, 0.7, 5)
 	if err != nil {
@@ -832,14 +1171,96 @@ func (h *ConsultationHandler) checkPatterns(ctx context.Context, req *graph.Cons
 	return patterns, alerts
 }
 
-// synthesizeBrief calls the AI service to create a synthesized brief
-func (h *ConsultationHandler) synthesizeBrief(ctx context.Context, req *graph.ConsultationRequest, data *graph.ConsultationResponse) (string, float64, error) {
+// formatBrief lists facts and insights directly, for when there is no AI
+// service to synthesize from them. Facts past maxFacts are counted, not listed.
+func formatBrief(facts []graph.Node, insights []graph.Insight, maxFacts int) (string, float64) {
+	var brief strings.Builder
+	confidence := 0.3
+	if len(facts) > 0 {
+		brief.WriteString("Based on what you've told me:\n")
+		for i, fact := range facts {
+			if i >= maxFacts {
+				brief.WriteString(fmt.Sprintf("... and %d more items.\n", len(facts)-maxFacts))
+				break
+			}
+			nodeType := fact.GetType()
+			brief.WriteString(fmt.Sprintf("- %s", fact.Name))
+			if fact.Description != "" {
+				brief.WriteString(fmt.Sprintf(": %s", fact.Description))
+			}
+			if len(fact.Tags) > 0 {
+				brief.WriteString(fmt.Sprintf(" [%s]", strings.Join(fact.Tags, ", ")))
+			}
+			brief.WriteString(fmt.Sprintf(" (%s)\n", nodeType))
+		}
+		confidence = 0.9
+	} else if len(insights) > 0 {
+		confidence = 0.6
+	} else {
+		brief.WriteString("I don't have any stored information about you yet.")
+	}
+	if len(insights) > 0 {
+		brief.WriteString("Connections I've noticed:\n")
+		for _, insight := range insights {
+			brief.WriteString(fmt.Sprintf("- %s", insightText(insight)))
+			if insight.ActionSuggestion != "" {
+				brief.WriteString(fmt.Sprintf(" (suggestion: %s)", insight.ActionSuggestion))
+			}
+			brief.WriteString("\n")
+		}
+	}
+	return brief.String(), confidence
+}
+
+// synthesize runs synthesizeBrief through the circuit breaker, so a down AI
+// service fails fast instead of costing every consultation a timeout
+func (h *ConsultationHandler) synthesize(ctx context.Context, req *graph.ConsultationRequest, data *graph.ConsultationResponse) (string, float64, bool, error) {
+	var brief string
+	var confidence float64
+	var available bool
+	var callerErr error
+	err := h.synthesisBreaker.Execute(func() error {
+		callCtx, cancel := context.WithTimeout(ctx, h.aiClient.Timeout)
+		defer cancel()
+		var err error
+		brief, confidence, available, err = h.synthesizeBrief(callCtx, req, data)
+		if err == nil && strings.TrimSpace(brief) == "" {
+			err = fmt.Errorf("synthesis service returned an empty brief")
+		}
+		if err != nil && ctx.Err() != nil {
+			// The caller's deadline ran out; that's no sign the AI service is down
+			callerErr = err
+			return nil
+		}
+		return err
+	})
+	if callerErr != nil {
+		return "", 0, false, callerErr
+	}
+	return brief, confidence, available, err
+}
+
+// SynthesisStats reports how often consultations fell back to the raw-fact
+// brief, and the state of the synthesis circuit breaker
+func (h *ConsultationHandler) SynthesisStats() map[string]interface{} {
+	return map[string]interface{}{
+		"fallbacks": h.synthesisFallbacks.Load(),
+		"breaker":   h.synthesisBreaker.GetStats(),
+	}
+}
+
+// synthesizeBrief calls the AI service to create a synthesized brief, and
+// reports whether an LLM wrote it (an AI service with no LLM provider lists
+// the facts by template instead)
+func (h *ConsultationHandler) synthesizeBrief(ctx context.Context, req *graph.ConsultationRequest, data *graph.ConsultationResponse) (string, float64, bool, error) {
 	type SynthesisRequest struct {
 		Query           string          `json:"query"`
 		Context         string          `json:"context,omitempty"`
 		Facts           []graph.Node    `json:"facts"`
 		Insights        []graph.Insight `json:"insights"`
 		ProactiveAlerts []string        `json:"proactive_alerts"`
+		Style           string          `json:"style,omitempty"`
+		MaxWords        int             `json:"max_words,omitempty"`
 	}
 
 	synthesisReq := SynthesisRequest{
@@ -848,41 +1269,44 @@ func (h *ConsultationHandler) synthesizeBrief(ctx context.Context, req *graph.Co
 		Facts:           data.RelevantFacts,
 		Insights:        data.Insights,
 		ProactiveAlerts: data.ProactiveAlerts,
+		Style:           req.BriefStyle,
+		MaxWords:        req.BriefMaxWords,
 	}
 
 	jsonData, err := json.Marshal(synthesisReq)
 	if err != nil {
-		return "", 0, err
+		return "", 0, false, err
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST",
 		h.aiServicesURL+"/synthesize",
 		bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", 0, err
+		return "", 0, false, err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(httpReq)
+	resp, err := h.aiClient.Do(httpReq)
 	if err != nil {
-		return "", 0, err
+		return "", 0, false, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", 0, fmt.Errorf("synthesis service returned status %d", resp.StatusCode)
+		return "", 0, false, fmt.Errorf("synthesis service returned status %d", resp.StatusCode)
 	}
 
 	var result struct {
-		Brief      string  `json:"brief"`
-		Confidence float64 `json:"confidence"`
+		Brief              string  `json:"brief"`
+		Confidence         float64 `json:"confidence"`
+		SynthesisAvailable *bool   `json:"synthesis_available"` // Unset by AI services that predate it
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", 0, err
+		return "", 0, false, err
 	}
 
-	return result.Brief, result.Confidence, nil
+	available := result.SynthesisAvailable == nil || *result.SynthesisAvailable
+	return result.Brief, result.Confidence, available, nil
 }
 
 // createFallbackBrief creates a simple brief from raw facts when AI synthesis fails
@@ -911,14 +1335,6 @@ func (h *ConsultationHandler) createFallbackBrief(data *graph.ConsultationRespon
 	return brief
 }
 
-// cacheResponse caches the synthesized response in Redis
-func (h *ConsultationHandler) cacheResponse(ctx context.Context, req *graph.ConsultationRequest, resp *graph.ConsultationResponse) error {
-	key := fmt.Sprintf("consultation:%s:%s", req.UserID, hashQuery(req.Query))
-
-	// Cache for 5 minutes
-	return h.redisClient.Set(ctx, key, resp.SynthesizedBrief, 5*time.Minute).Err()
-}
-
 // isQueryRelevant checks if a node is semantically relevant to the query
 func isQueryRelevant(nodeName string, query string) bool {
 	queryLower := strings.ToLower(query)
@@ -946,8 +1362,7 @@ func isQueryRelevant(nodeName string, query string) bool {
 
 // updateAccessedNodes boosts activation only for query-relevant nodes
 func (h *ConsultationHandler) updateAccessedNodes(ctx context.Context, query string, resp *graph.ConsultationResponse) {
-	config := graph.DefaultActivationConfig()
-
+	var relevant []graph.Node
 	for _, node := range resp.RelevantFacts {
 		// Nil check to prevent panics
 		if node.UID == "" {
@@ -959,16 +1374,17 @@ func (h *ConsultationHandler) updateAccessedNodes(ctx context.Context, query str
 		if node.Name != "" && !isQueryRelevant(node.Name, query) {
 			continue
 		}
+		relevant = append(relevant, node)
+	}
 
-		if err := h.graphClient.IncrementAccessCount(ctx, node.UID, config); err != nil {
-			h.logger.Warn("Failed to update node activation",
-				zap.String("uid", node.UID),
-				zap.Error(err))
-		} else {
-			h.logger.Debug("Boosted relevant node",
-				zap.String("name", node.Name),
-				zap.String("query", query))
-		}
+	if err := h.boostAccessed(ctx, relevant); err != nil {
+		h.logger.Warn("Failed to update node activation",
+			zap.Int("nodes", len(relevant)),
+			zap.Error(err))
+	} else if len(relevant) > 0 {
+		h.logger.Debug("Boosted relevant nodes",
+			zap.Int("nodes", len(relevant)),
+			zap.String("query", query))
 	}
 }
 
@@ -983,7 +1399,7 @@ func (h *ConsultationHandler) Speculate(ctx context.Context, req *graph.Consulta
 
 	namespace := req.Namespace
 	if namespace == "" {
-		namespace = fmt.Sprintf("user_%s", req.UserID)
+		namespace = nsutil.ForUser(req.UserID)
 	}
 
 	// Just perform text search for speed (Hot Path)
@@ -1123,7 +1539,7 @@ func (h *ConsultationHandler) buildUserContext(ctx context.Context, userID strin
 	for _, g := range graphGroups {
 		// Policy engine expects group UID without "group_" prefix
 		// Namespace format is "group_<UUID>", so extract the UUID part
-		groupUID := strings.TrimPrefix(g.Namespace, "group_")
+		_, groupUID := nsutil.Parse(g.Namespace)
 		groups = append(groups, groupUID)
 	}
 
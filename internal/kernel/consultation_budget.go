@@ -0,0 +1,67 @@
+package kernel
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultConsultationBudget bounds a consultation whose request sets no
+// budget, well inside the agent's HTTP timeout
+const DefaultConsultationBudget = 20 * time.Second
+
+// Shares of a consultation's budget each step may spend. Synthesis gets
+// whatever the earlier steps leave.
+const (
+	retrievalBudgetShare = 0.6
+	policyBudgetShare    = 0.15
+	rerankBudgetShare    = 0.1
+	insightsBudgetShare  = 0.1
+)
+
+// consultBudget is the time a consultation may take, handed out to its steps
+type consultBudget struct {
+	total    time.Duration // 0 = unbounded
+	deadline time.Time
+}
+
+func newConsultBudget(total time.Duration, start time.Time) consultBudget {
+	if total <= 0 {
+		return consultBudget{}
+	}
+	return consultBudget{total: total, deadline: start.Add(total)}
+}
+
+// step bounds ctx to share of the total budget, and never past the
+// consultation's deadline
+func (b consultBudget) step(ctx context.Context, share float64) (context.Context, context.CancelFunc) {
+	if b.total <= 0 {
+		return context.WithCancel(ctx)
+	}
+	stepDeadline := time.Now().Add(time.Duration(share * float64(b.total)))
+	if stepDeadline.After(b.deadline) {
+		stepDeadline = b.deadline
+	}
+	return context.WithDeadline(ctx, stepDeadline)
+}
+
+// rest bounds ctx to the time the budget has left
+func (b consultBudget) rest(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.total <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, b.deadline)
+}
+
+// ranOut reports whether stepCtx ended because the budget did, rather than
+// because the caller's own context was cancelled
+func (b consultBudget) ranOut(parent, stepCtx context.Context) bool {
+	return b.total > 0 && stepCtx.Err() == context.DeadlineExceeded && parent.Err() == nil
+}
+
+// budgetFor returns the budget a request asks for, or the handler's default
+func (h *ConsultationHandler) budgetFor(budgetMs int) time.Duration {
+	if budgetMs > 0 {
+		return time.Duration(budgetMs) * time.Millisecond
+	}
+	return h.budget
+}
@@ -0,0 +1,156 @@
+package kernel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// DefaultConsultationCacheTTL is how long a cached consultation stays fresh
+	DefaultConsultationCacheTTL = 5 * time.Minute
+
+	consultationCachePrefix = "consultation:"
+	consultationGenPrefix   = "consultation_gen:"
+	// consultationCacheTimeout bounds Redis calls so a slow cache never stalls a consult
+	consultationCacheTimeout = 200 * time.Millisecond
+)
+
+// ConsultationCache caches consultation responses in Redis, keyed by namespace,
+// user and a hash of the request. Each namespace has a generation counter that
+// is part of the key; ingestion bumps it, so every cached answer for the
+// namespace is invalidated at once and old entries simply age out.
+// A nil *ConsultationCache is valid and caches nothing.
+type ConsultationCache struct {
+	redis  *redis.Client
+	ttl    time.Duration
+	logger *zap.Logger
+}
+
+// NewConsultationCache returns a cache with the given TTL, or nil (caching
+// disabled) when there is no Redis client or ttl <= 0
+func NewConsultationCache(redisClient *redis.Client, ttl time.Duration, logger *zap.Logger) *ConsultationCache {
+	if redisClient == nil || ttl <= 0 {
+		return nil
+	}
+	return &ConsultationCache{redis: redisClient, ttl: ttl, logger: logger}
+}
+
+// cachedConsultation is the stored part of a response; RequestID is per call
+type cachedConsultation struct {
+	SynthesizedBrief    string          `json:"brief"`
+	RelevantFacts       []graph.Node    `json:"facts,omitempty"`
+	Insights            []graph.Insight `json:"insights,omitempty"`
+	Confidence          float64         `json:"confidence"`
+	CollapsedDuplicates int             `json:"collapsed,omitempty"`
+}
+
+// Get returns the cached response for req in namespace, if still fresh
+func (c *ConsultationCache) Get(ctx context.Context, namespace string, req *graph.ConsultationRequest) (*graph.ConsultationResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, consultationCacheTimeout)
+	defer cancel()
+
+	key, err := c.key(ctx, namespace, req)
+	if err != nil {
+		return nil, false
+	}
+	raw, err := c.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			c.logger.Debug("Consultation cache read failed", zap.Error(err))
+		}
+		return nil, false
+	}
+
+	var cached cachedConsultation
+	if err := json.Unmarshal(raw, &cached); err != nil {
+		return nil, false
+	}
+	return &graph.ConsultationResponse{
+		SynthesizedBrief:    cached.SynthesizedBrief,
+		RelevantFacts:       cached.RelevantFacts,
+		Insights:            cached.Insights,
+		Confidence:          cached.Confidence,
+		CollapsedDuplicates: cached.CollapsedDuplicates,
+	}, true
+}
+
+// Put stores resp as the answer to req in namespace
+func (c *ConsultationCache) Put(ctx context.Context, namespace string, req *graph.ConsultationRequest, resp *graph.ConsultationResponse) {
+	if c == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, consultationCacheTimeout)
+	defer cancel()
+
+	key, err := c.key(ctx, namespace, req)
+	if err != nil {
+		return
+	}
+	raw, err := json.Marshal(cachedConsultation{
+		SynthesizedBrief:    resp.SynthesizedBrief,
+		RelevantFacts:       resp.RelevantFacts,
+		Insights:            resp.Insights,
+		Confidence:          resp.Confidence,
+		CollapsedDuplicates: resp.CollapsedDuplicates,
+	})
+	if err != nil {
+		return
+	}
+	if err := c.redis.Set(ctx, key, raw, c.ttl).Err(); err != nil {
+		c.logger.Debug("Consultation cache write failed", zap.Error(err))
+	}
+}
+
+// Invalidate drops every cached consultation for namespace by bumping its generation
+func (c *ConsultationCache) Invalidate(ctx context.Context, namespace string) {
+	if c == nil || namespace == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, consultationCacheTimeout)
+	defer cancel()
+
+	if err := c.redis.Incr(ctx, consultationGenPrefix+namespace).Err(); err != nil {
+		c.logger.Warn("Consultation cache invalidation failed",
+			zap.String("namespace", namespace),
+			zap.Error(err))
+	}
+}
+
+// key builds consultation:<namespace>:<generation>:<sha256(user, request)>.
+// Everything that shapes the answer is hashed, and the user is included
+// because policy filtering makes answers per-user.
+func (c *ConsultationCache) key(ctx context.Context, namespace string, req *graph.ConsultationRequest) (string, error) {
+	gen, err := c.redis.Get(ctx, consultationGenPrefix+namespace).Result()
+	if err == redis.Nil {
+		gen = "0"
+	} else if err != nil {
+		return "", err
+	}
+
+	shape, _ := json.Marshal(struct {
+		UserID          string   `json:"u"`
+		Query           string   `json:"q"`
+		Context         string   `json:"c"`
+		MaxResults      int      `json:"m"`
+		IncludeInsights bool     `json:"i"`
+		TopicFilters    []string `json:"t"`
+		BriefStyle      string   `json:"s"`
+		BriefMaxWords   int      `json:"w"`
+		Rerank          bool     `json:"r"`
+	}{req.UserID, strings.TrimSpace(req.Query), req.Context, req.MaxResults, req.IncludeInsights,
+		req.TopicFilters, req.BriefStyle, req.BriefMaxWords, req.Rerank})
+	sum := sha256.Sum256(shape)
+	return consultationCachePrefix + namespace + ":" + gen + ":" + hex.EncodeToString(sum[:]), nil
+}
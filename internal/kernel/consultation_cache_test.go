@@ -0,0 +1,100 @@
+package kernel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+// countingEmbedder returns a fixed vector and counts Embed calls, one per retrieval
+type countingEmbedder struct {
+	calls atomic.Int32
+}
+
+func (e *countingEmbedder) Embed(text string) ([]float32, error) {
+	e.calls.Add(1)
+	return []float32{0.1, 0.2, 0.3}, nil
+}
+
+func (e *countingEmbedder) Close() error { return nil }
+
+func TestConsultationCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	cache := NewConsultationCache(fakeredis.New(t), DefaultConsultationCacheTTL, zaptest.NewLogger(t))
+
+	const namespace = "user_alice"
+	req := &graph.ConsultationRequest{UserID: "alice", Query: "what is my favourite colour"}
+	resp := &graph.ConsultationResponse{SynthesizedBrief: "Teal", Confidence: 0.9}
+
+	if _, ok := cache.Get(ctx, namespace, req); ok {
+		t.Fatal("unexpected hit on an empty cache")
+	}
+	cache.Put(ctx, namespace, req, resp)
+
+	got, ok := cache.Get(ctx, namespace, req)
+	if !ok || got.SynthesizedBrief != "Teal" {
+		t.Fatalf("Get after Put = %+v, %v; want the stored brief", got, ok)
+	}
+	if _, ok := cache.Get(ctx, namespace, &graph.ConsultationRequest{UserID: "bob", Query: req.Query}); ok {
+		t.Error("another user's identical query must not share the cached answer")
+	}
+
+	cache.Invalidate(ctx, namespace)
+	if _, ok := cache.Get(ctx, namespace, req); ok {
+		t.Error("expected a miss after the namespace was invalidated")
+	}
+}
+
+func TestHandleRepeatedConsultHitsCache(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	redisClient := fakeredis.New(t)
+
+	const namespace = "user_alice"
+	vectorIndex := NewVectorIndex(newFakeQdrant(t).URL, DefaultCollectionName, logger)
+	if err := vectorIndex.Store(ctx, namespace, "chunk_doc_0", []float32{0.1, 0.2, 0.3},
+		map[string]interface{}{"text": "Alice's favourite colour is teal"}); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	embedder := &countingEmbedder{}
+	h := NewConsultationHandler(nil, nil, redisClient, vectorIndex, embedder, nil, nil, "", logger)
+	h.SetCache(NewConsultationCache(redisClient, DefaultConsultationCacheTTL, logger))
+
+	req := &graph.ConsultationRequest{UserID: "alice", Namespace: namespace, Query: "what is my favourite colour"}
+	first, err := h.Handle(ctx, req)
+	if err != nil {
+		t.Fatalf("first Handle: %v", err)
+	}
+	if len(first.RelevantFacts) == 0 {
+		t.Fatal("first consult retrieved no facts")
+	}
+	retrievals := embedder.calls.Load()
+
+	second, err := h.Handle(ctx, req)
+	if err != nil {
+		t.Fatalf("second Handle: %v", err)
+	}
+	if embedder.calls.Load() != retrievals {
+		t.Error("second identical consult re-ran retrieval instead of hitting the cache")
+	}
+	if second.SynthesizedBrief != first.SynthesizedBrief {
+		t.Errorf("cached brief = %q, want %q", second.SynthesizedBrief, first.SynthesizedBrief)
+	}
+	if second.RequestID == first.RequestID {
+		t.Error("cached response should get a fresh request ID")
+	}
+
+	h.cache.Invalidate(ctx, namespace)
+	if _, err := h.Handle(ctx, req); err != nil {
+		t.Fatalf("third Handle: %v", err)
+	}
+	if embedder.calls.Load() == retrievals {
+		t.Error("consult after invalidation should re-run retrieval")
+	}
+}
@@ -0,0 +1,117 @@
+package kernel
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/embedding"
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// nearDuplicateThreshold is the cosine similarity above which two facts are one
+	nearDuplicateThreshold = 0.92
+	// maxDedupFacts bounds how many facts are embedded per consultation
+	maxDedupFacts = 30
+	// consultationEmbedConcurrency bounds the embedding calls a consultation
+	// has in flight at once
+	consultationEmbedConcurrency = 4
+)
+
+// embedTexts embeds texts concurrently, at most consultationEmbedConcurrency
+// at a time. A text whose embedding fails gets a nil vector.
+func (h *ConsultationHandler) embedTexts(texts []string) [][]float32 {
+	vecs := make([][]float32, len(texts))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, consultationEmbedConcurrency)
+
+	for i, text := range texts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			v, err := h.embedder.Embed(text)
+			if err != nil {
+				h.logger.Debug("Consultation embedding failed", zap.Error(err))
+				return
+			}
+			vecs[i] = v
+		}()
+	}
+	wg.Wait()
+	return vecs
+}
+
+// factText is the text a fact is compared (and embedded) by
+func factText(n graph.Node) string {
+	if n.Description == "" {
+		return n.Name
+	}
+	return n.Name + ": " + n.Description
+}
+
+// collapseNearDuplicates merges facts that say the same thing, keeping the
+// highest-activation representative in the position of the first occurrence.
+// Returns the deduplicated facts and how many were collapsed.
+func (h *ConsultationHandler) collapseNearDuplicates(facts []graph.Node) ([]graph.Node, int) {
+	if len(facts) < 2 {
+		return facts, 0
+	}
+
+	type cluster struct {
+		rep graph.Node
+		vec []float32
+		key string
+	}
+	var clusters []cluster
+	collapsed := 0
+
+	// Beyond the budget, keep facts as-is rather than embedding them
+	head, tail := facts, []graph.Node(nil)
+	if len(facts) > maxDedupFacts {
+		head, tail = facts[:maxDedupFacts], facts[maxDedupFacts:]
+	}
+	texts := make([]string, len(head))
+	for i, fact := range head {
+		texts[i] = factText(fact)
+	}
+	// Facts whose embedding fails are matched exactly only
+	vecs := make([][]float32, len(head))
+	if h.embedder != nil {
+		vecs = h.embedTexts(texts)
+	}
+
+	for i, fact := range head {
+		key := strings.ToLower(strings.Join(strings.Fields(texts[i]), " "))
+		vec := vecs[i]
+
+		match := -1
+		for j := range clusters {
+			if clusters[j].key == key ||
+				(vec != nil && clusters[j].vec != nil &&
+					embedding.CosineSimilarity(vec, clusters[j].vec) >= nearDuplicateThreshold) {
+				match = j
+				break
+			}
+		}
+
+		if match < 0 {
+			clusters = append(clusters, cluster{rep: fact, vec: vec, key: key})
+			continue
+		}
+		collapsed++
+		if fact.Activation > clusters[match].rep.Activation {
+			clusters[match].rep = fact
+		}
+	}
+
+	deduped := make([]graph.Node, 0, len(clusters)+len(tail))
+	for _, c := range clusters {
+		deduped = append(deduped, c.rep)
+	}
+	return append(deduped, tail...), collapsed
+}
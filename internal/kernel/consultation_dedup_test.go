@@ -0,0 +1,98 @@
+package kernel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// slowEmbedder embeds text as a one-hot vector on the first matching topic,
+// taking delay per call and recording how many calls overlap. Text matching no
+// topic fails to embed.
+type slowEmbedder struct {
+	topics []string
+	delay  time.Duration
+
+	mu                  sync.Mutex
+	calls, inFlight, hi int
+}
+
+func (e *slowEmbedder) Embed(text string) ([]float32, error) {
+	e.mu.Lock()
+	e.calls++
+	e.inFlight++
+	e.hi = max(e.hi, e.inFlight)
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.inFlight--
+		e.mu.Unlock()
+	}()
+
+	time.Sleep(e.delay)
+	vec := make([]float32, len(e.topics))
+	for i, topic := range e.topics {
+		if strings.Contains(strings.ToLower(text), topic) {
+			vec[i] = 1
+			return vec, nil
+		}
+	}
+	return nil, errors.New("no topic")
+}
+
+func (e *slowEmbedder) Close() error { return nil }
+
+func TestCollapseNearDuplicatesEmbedsConcurrently(t *testing.T) {
+	embedder := &slowEmbedder{topics: []string{"teal", "berlin", "tennis"}, delay: 20 * time.Millisecond}
+	h := &ConsultationHandler{logger: zaptest.NewLogger(t), embedder: embedder}
+
+	facts := []graph.Node{
+		{UID: "0x1", Name: "Favourite colour", Description: "teal", Activation: 0.4},
+		{UID: "0x2", Name: "Lives in", Description: "Berlin"},
+		{UID: "0x3", Name: "Likes", Description: "the colour teal", Activation: 0.9},
+		{UID: "0x4", Name: "Hobby", Description: "tennis"},
+		{UID: "0x5", Name: "Pet", Description: "a cat"}, // Fails to embed
+		{UID: "0x6", Name: "pet:  A CAT"},               // Same text as 0x5
+		{UID: "0x7", Name: "City", Description: "Berlin, Germany"},
+		{UID: "0x8", Name: "Plays", Description: "Tennis on Sundays"},
+	}
+	deduped, collapsed := h.collapseNearDuplicates(facts)
+
+	var uids []string
+	for _, n := range deduped {
+		uids = append(uids, n.UID)
+	}
+	if got := strings.Join(uids, ","); got != "0x3,0x2,0x4,0x5" || collapsed != 4 {
+		t.Errorf("deduped = %s (%d collapsed), want 0x3,0x2,0x4,0x5 (4 collapsed)", got, collapsed)
+	}
+	if embedder.calls != len(facts) {
+		t.Errorf("embedded %d facts, want %d", embedder.calls, len(facts))
+	}
+	if embedder.hi < 2 || embedder.hi > consultationEmbedConcurrency {
+		t.Errorf("%d embeddings in flight at once, want 2-%d", embedder.hi, consultationEmbedConcurrency)
+	}
+}
+
+func TestCollapseNearDuplicatesLeavesFactsBeyondBudget(t *testing.T) {
+	embedder := &slowEmbedder{topics: []string{"teal"}}
+	h := &ConsultationHandler{logger: zaptest.NewLogger(t), embedder: embedder}
+
+	facts := make([]graph.Node, maxDedupFacts+5)
+	for i := range facts {
+		facts[i] = graph.Node{UID: fmt.Sprintf("0x%x", i+1), Name: "Colour", Description: "teal"}
+	}
+	deduped, collapsed := h.collapseNearDuplicates(facts)
+	if len(deduped) != 6 || collapsed != maxDedupFacts-1 {
+		t.Errorf("kept %d facts (%d collapsed), want 6 (%d collapsed)", len(deduped), collapsed, maxDedupFacts-1)
+	}
+	if embedder.calls != maxDedupFacts {
+		t.Errorf("embedded %d facts, want %d", embedder.calls, maxDedupFacts)
+	}
+}
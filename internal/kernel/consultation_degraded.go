@@ -0,0 +1,91 @@
+package kernel
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// vectorSearchTimeout bounds semantic retrieval in a consultation. A hung
+// Qdrant must cost the caller this much at most, not the index's full HTTP
+// timeout; the graph sources answer without it.
+const vectorSearchTimeout = 3 * time.Second
+
+// degradedModes collects the subsystems that failed during one consultation.
+// Namespaces are searched concurrently, so it is safe for concurrent use; a
+// nil collector ignores reports.
+type degradedModes struct {
+	mu    sync.Mutex
+	modes map[string]bool
+}
+
+func (d *degradedModes) add(mode string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.modes == nil {
+		d.modes = make(map[string]bool)
+	}
+	d.modes[mode] = true
+}
+
+// list returns the reported modes sorted, or nil when nothing failed
+func (d *degradedModes) list() []string {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.modes) == 0 {
+		return nil
+	}
+	modes := make([]string, 0, len(d.modes))
+	for mode := range d.modes {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// vectorSearchStats tracks whether semantic retrieval is reachable
+type vectorSearchStats struct {
+	searches      atomic.Int64
+	failures      atomic.Int64
+	lastOK        atomic.Bool
+	lastFailureAt atomic.Int64 // Unix nanoseconds, 0 if never
+}
+
+func (s *vectorSearchStats) record(err error) {
+	s.searches.Add(1)
+	if err != nil {
+		s.failures.Add(1)
+		s.lastFailureAt.Store(time.Now().UnixNano())
+		s.lastOK.Store(false)
+		return
+	}
+	s.lastOK.Store(true)
+}
+
+// VectorSearchStats reports how often semantic retrieval failed during
+// consultations and whether the latest attempt succeeded
+func (h *ConsultationHandler) VectorSearchStats() map[string]interface{} {
+	searches := h.vectorStats.searches.Load()
+	failures := h.vectorStats.failures.Load()
+	availability := 1.0
+	if searches > 0 {
+		availability = float64(searches-failures) / float64(searches)
+	}
+	stats := map[string]interface{}{
+		"searches":     searches,
+		"failures":     failures,
+		"availability": availability,
+		"available":    searches == 0 || h.vectorStats.lastOK.Load(),
+	}
+	if at := h.vectorStats.lastFailureAt.Load(); at > 0 {
+		stats["last_failure_at"] = time.Unix(0, at).UTC()
+	}
+	return stats
+}
@@ -0,0 +1,189 @@
+package kernel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// expandQueryTimeout bounds the single /expand-query call a missed
+	// consultation makes before giving up
+	expandQueryTimeout = 5 * time.Second
+	// fullTextFallbackLimit caps the fulltext matches taken per lookup
+	fullTextFallbackLimit = 10
+	// expansionMinRelevance is the relevance a retrieved fact needs for the
+	// query to count as answered: the vector score of an excerpt, or the
+	// share of the query's keywords in a fact's name and description
+	expansionMinRelevance = 0.5
+)
+
+// withQueryExpansion runs lookup for the query and, if none of what it finds
+// is relevant to the query (a populated namespace always returns its most
+// active and recent facts), asks the AI service to broaden the query into
+// search terms and entity names and runs lookup once more with those. Facts
+// found through the expansion come first. There is only ever one expansion
+// round, so an unanswerable query costs at most one extra AI call and
+// retrieval.
+func (h *ConsultationHandler) withQueryExpansion(ctx context.Context, queryText string, lookup func(text string) ([]graph.Node, error)) ([]graph.Node, error) {
+	nodes, err := lookup(queryText)
+	if err != nil {
+		return nodes, err
+	}
+	relevance, judged := h.topRelevance(queryText, nodes)
+	if len(nodes) > 0 && (!judged || relevance >= expansionMinRelevance) {
+		return nodes, nil
+	}
+
+	terms, err := h.expandQuery(ctx, queryText)
+	if err != nil {
+		h.logger.Warn("Query expansion failed", zap.Error(err))
+		return nodes, nil
+	}
+	expanded := strings.Join(terms, " ")
+	if expanded == "" || strings.EqualFold(expanded, queryText) {
+		return nodes, nil
+	}
+
+	h.logger.Info("Nothing relevant matched, retrying with expanded query",
+		zap.String("query", queryText),
+		zap.String("expanded", expanded),
+		zap.Int("candidates", len(nodes)),
+		zap.Float64("top_relevance", relevance))
+	found, err := lookup(expanded)
+	if err != nil {
+		return nodes, err
+	}
+
+	seen := make(map[string]bool, len(found))
+	for _, node := range found {
+		seen[node.UID] = true
+	}
+	for _, node := range nodes {
+		if node.UID == "" || !seen[node.UID] {
+			found = append(found, node)
+		}
+	}
+	return found, nil
+}
+
+// topRelevance returns how relevant the best of nodes is to the query: an
+// excerpt's vector score, or the share of the query's keywords a fact's name
+// and description contain. judged is false when the query has no keywords
+// to compare against.
+func (h *ConsultationHandler) topRelevance(queryText string, nodes []graph.Node) (top float64, judged bool) {
+	keywords := h.normalizer.Terms(queryText)
+	if len(keywords) == 0 {
+		return 0, false
+	}
+	for _, node := range nodes {
+		if slices.Contains(node.Tags, "vector-result") {
+			top = max(top, node.Confidence)
+		}
+		terms := make(map[string]bool)
+		for _, term := range h.normalizer.Terms(node.Name + " " + node.Description) {
+			terms[term] = true
+		}
+		matched := 0
+		for _, keyword := range keywords {
+			if terms[keyword] {
+				matched++
+			}
+		}
+		top = max(top, float64(matched)/float64(len(keywords)))
+	}
+	return top, true
+}
+
+// lookupKnowledge is one retrieval round: the hybrid retrieval, then a
+// fulltext match on names and descriptions if that found nothing
+func (h *ConsultationHandler) lookupKnowledge(ctx context.Context, namespace, userID, queryText string, plan retrievalPlan, degraded *degradedModes) ([]graph.Node, error) {
+	nodes, err := h.retrieveKnowledge(ctx, namespace, userID, queryText, plan, degraded)
+	if len(nodes) > 0 || err != nil {
+		return nodes, err
+	}
+	return h.searchFullText(ctx, namespace, queryText), nil
+}
+
+// searchFullText matches the query's keywords against node names,
+// descriptions and tags in the namespace
+func (h *ConsultationHandler) searchFullText(ctx context.Context, namespace, queryText string) []graph.Node {
+	keywords := h.cleanQuery(queryText)
+	if h.queryBuilder == nil || keywords == "" {
+		return nil
+	}
+	matches, err := h.queryBuilder.SearchByTerms(ctx, namespace, keywords, fullTextFallbackLimit)
+	if err != nil {
+		h.logger.Warn("Fulltext fallback failed", zap.Error(err))
+		return nil
+	}
+
+	var nodes []graph.Node
+	for _, node := range matches {
+		if node.Namespace == "" {
+			node.Namespace = namespace // SearchByText already filters on it
+		}
+		if isKnowledgeNode(node, namespace) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// expandQuery asks the AI service for search terms and entity names that a
+// differently phrased memory might be stored under
+func (h *ConsultationHandler) expandQuery(ctx context.Context, queryText string) ([]string, error) {
+	if h.aiServicesURL == "" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, expandQueryTimeout)
+	defer cancel()
+
+	jsonData, err := json.Marshal(map[string]string{"query": queryText})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", h.aiServicesURL+"/expand-query", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.aiClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("query expansion returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		SearchTerms []string `json:"search_terms"`
+		EntityNames []string `json:"entity_names"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range append(result.EntityNames, result.SearchTerms...) {
+		term = strings.TrimSpace(term)
+		if term != "" && !seen[strings.ToLower(term)] {
+			seen[strings.ToLower(term)] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms, nil
+}
@@ -0,0 +1,132 @@
+package kernel
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/embedding"
+	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+const (
+	// insightCandidates is how many recent insights are fetched before filtering
+	insightCandidates = 20
+	// defaultMaxInsights is returned when the request sets no MaxResults
+	defaultMaxInsights = 5
+	// minInsightConfidence drops speculative insights from the brief
+	minInsightConfidence = 0.5
+	// minInsightRelevance is the query similarity below which an insight is off-topic
+	minInsightRelevance = 0.3
+)
+
+// insightText is the text an insight is shown (and embedded) by
+func insightText(insight graph.Insight) string {
+	switch {
+	case insight.Summary != "":
+		return insight.Summary
+	case insight.Description != "":
+		return insight.Description
+	default:
+		return insight.Name
+	}
+}
+
+// insightSourceKey identifies an insight by the set of nodes it was synthesized from
+func insightSourceKey(insight graph.Insight) string {
+	if len(insight.SourceNodeUIDs) == 0 {
+		return "uid:" + insight.UID
+	}
+	uids := append([]string(nil), insight.SourceNodeUIDs...)
+	sort.Strings(uids)
+	return strings.Join(uids, ",")
+}
+
+// getRelevantInsights retrieves recent insights, drops low-confidence and
+// off-topic ones, keeps one insight per set of source nodes and returns at most
+// MaxResults sorted by relevance x confidence. Without an embedder every
+// insight counts as fully relevant.
+func (h *ConsultationHandler) getRelevantInsights(ctx context.Context, req *graph.ConsultationRequest) ([]graph.Insight, error) {
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = nsutil.ForUser(req.UserID)
+	}
+	insights, err := h.queryBuilder.GetInsights(ctx, namespace, insightCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	maxResults := req.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultMaxInsights
+	}
+
+	var confident []graph.Insight
+	for _, insight := range insights {
+		if insight.Confidence >= minInsightConfidence {
+			confident = append(confident, insight)
+		}
+	}
+
+	// The query and the insights are embedded together; without a query
+	// vector every insight is ranked by confidence only
+	var queryVec []float32
+	var insightVecs [][]float32
+	if h.embedder != nil && req.Query != "" && len(confident) > 0 {
+		texts := make([]string, 0, len(confident)+1)
+		texts = append(texts, req.Query)
+		for _, insight := range confident {
+			texts = append(texts, insightText(insight))
+		}
+		vecs := h.embedTexts(texts)
+		queryVec, insightVecs = vecs[0], vecs[1:]
+		if queryVec == nil {
+			h.logger.Debug("Insight relevance embedding failed, ranking by confidence only")
+		}
+	}
+
+	type scored struct {
+		insight graph.Insight
+		score   float64
+	}
+	best := make(map[string]int) // source key -> index in ranked
+	var ranked []scored
+	for i, insight := range confident {
+		relevance := 1.0
+		if queryVec != nil && insightVecs[i] != nil {
+			relevance = float64(embedding.CosineSimilarity(queryVec, insightVecs[i]))
+			if relevance < minInsightRelevance {
+				continue
+			}
+		}
+
+		candidate := scored{insight: insight, score: relevance * insight.Confidence}
+		key := insightSourceKey(insight)
+		if i, ok := best[key]; ok {
+			if candidate.score > ranked[i].score {
+				ranked[i] = candidate
+			}
+			continue
+		}
+		best[key] = len(ranked)
+		ranked = append(ranked, candidate)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	if len(ranked) > maxResults {
+		ranked = ranked[:maxResults]
+	}
+
+	result := make([]graph.Insight, len(ranked))
+	for i, r := range ranked {
+		result[i] = r.insight
+	}
+
+	h.logger.Debug("Filtered insights",
+		zap.Int("candidates", len(insights)),
+		zap.Int("returned", len(result)))
+	return result, nil
+}
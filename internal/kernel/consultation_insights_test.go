@@ -0,0 +1,52 @@
+package kernel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func TestRelevantInsightsFilteredAndRanked(t *testing.T) {
+	store := graph.NewMemStore()
+	store.QueryFunc = func(q string, vars map[string]string) ([]byte, error) {
+		return []byte(`{"insights": [
+			{"uid": "0x1", "summary": "Teal suits the new office", "confidence": 0.6, "source_nodes": [{"uid": "0xa"}, {"uid": "0xb"}]},
+			{"uid": "0x2", "summary": "Berlin rent is rising", "confidence": 0.9},
+			{"uid": "0x3", "summary": "Teal and the office again", "confidence": 0.8, "source_nodes": [{"uid": "0xb"}, {"uid": "0xa"}]},
+			{"uid": "0x4", "summary": "Teal is trending", "confidence": 0.3},
+			{"uid": "0x5", "summary": "Teal mugs are on sale", "confidence": 0.7},
+			{"uid": "0x6", "summary": "Something unembeddable", "confidence": 0.5}
+		]}`), nil
+	}
+	embedder := &slowEmbedder{topics: []string{"teal", "berlin"}, delay: 20 * time.Millisecond}
+	h := NewConsultationHandler(store, graph.NewQueryBuilder(store), nil, nil, embedder, nil, nil, "", zaptest.NewLogger(t))
+
+	insights, err := h.getRelevantInsights(context.Background(), &graph.ConsultationRequest{
+		Namespace: "user_alice", Query: "favourite teal colour", MaxResults: 5,
+	})
+	if err != nil {
+		t.Fatalf("getRelevantInsights: %v", err)
+	}
+
+	// 0x2 is off-topic, 0x4 below the confidence floor, 0x1 a lower-scored
+	// insight on the same sources as 0x3 and 0x6 unembeddable, so fully relevant
+	var uids []string
+	for _, insight := range insights {
+		uids = append(uids, insight.UID)
+	}
+	if got := strings.Join(uids, ","); got != "0x3,0x5,0x6" {
+		t.Errorf("insights = %s, want 0x3,0x5,0x6", got)
+	}
+	// The query and the five confident insights
+	if embedder.calls != 6 {
+		t.Errorf("embedded %d texts, want 6", embedder.calls)
+	}
+	if embedder.hi < 2 || embedder.hi > consultationEmbedConcurrency {
+		t.Errorf("%d embeddings in flight at once, want 2-%d", embedder.hi, consultationEmbedConcurrency)
+	}
+}
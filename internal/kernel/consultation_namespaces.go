@@ -0,0 +1,143 @@
+package kernel
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+)
+
+const (
+	// maxConsultNamespaces caps how many namespaces one consultation fans out to
+	maxConsultNamespaces = 10
+	// maxMergedFacts is how many facts survive the cross-namespace rerank
+	maxMergedFacts = 50
+)
+
+// consultNamespaces returns primary followed by the extra namespaces the
+// request asks for (req.Namespaces, plus every group the user belongs to when
+// req.IncludeGroups is set). Extras the user isn't authorized for are dropped
+// rather than failing the consultation: group namespaces need workspace
+// membership and the only personal namespace allowed is the user's own.
+func (h *ConsultationHandler) consultNamespaces(ctx context.Context, req *graph.ConsultationRequest, primary string) []string {
+	candidates := append([]string(nil), req.Namespaces...)
+	if req.IncludeGroups && h.graphClient != nil {
+		groups, err := h.graphClient.ListUserGroups(ctx, req.UserID)
+		if err != nil {
+			h.logger.Warn("Failed to list user groups for consultation", zap.Error(err))
+		}
+		for _, group := range groups {
+			candidates = append(candidates, group.Namespace)
+		}
+	}
+
+	namespaces := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, ns := range candidates {
+		if ns == "" || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		if len(namespaces) == maxConsultNamespaces {
+			h.logger.Warn("Too many consultation namespaces, ignoring the rest",
+				zap.Int("max", maxConsultNamespaces))
+			break
+		}
+		if !h.canConsult(ctx, ns, req.UserID) {
+			h.logger.Warn("Skipping namespace the user can't read",
+				zap.String("user", req.UserID),
+				zap.String("namespace", ns))
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces
+}
+
+// canConsult reports whether userID may read namespace
+func (h *ConsultationHandler) canConsult(ctx context.Context, namespace, userID string) bool {
+	if nsutil.IsGroup(namespace) {
+		if h.graphClient == nil {
+			return false
+		}
+		isMember, err := h.graphClient.IsWorkspaceMember(ctx, namespace, userID)
+		if err != nil {
+			h.logger.Warn("Failed to check workspace membership", zap.Error(err))
+			return false
+		}
+		return isMember
+	}
+	return namespace == nsutil.ForUser(userID)
+}
+
+// getKnowledgeAcross retrieves knowledge from each namespace concurrently and
+// merges it into one list ranked by fused score, so a strong group fact can
+// outrank a weak private one. Every fact carries its source namespace. The
+// first retrieval error is returned alongside whatever was found. As for a
+// single namespace, a query nothing relevant matches is expanded once and retried.
+func (h *ConsultationHandler) getKnowledgeAcross(ctx context.Context, namespaces []string, userID, queryText string, plan retrievalPlan, degraded *degradedModes) ([]graph.Node, error) {
+	return h.withQueryExpansion(ctx, queryText, func(text string) ([]graph.Node, error) {
+		return h.lookupKnowledgeAcross(ctx, namespaces, userID, text, plan, degraded)
+	})
+}
+
+// lookupKnowledgeAcross is one retrieval round over every namespace
+func (h *ConsultationHandler) lookupKnowledgeAcross(ctx context.Context, namespaces []string, userID, queryText string, plan retrievalPlan, degraded *degradedModes) ([]graph.Node, error) {
+	results := make([][]graph.Node, len(namespaces))
+	errs := make([]error, len(namespaces))
+	var wg sync.WaitGroup
+	for i, ns := range namespaces {
+		wg.Add(1)
+		go func(i int, ns string) {
+			defer wg.Done()
+			results[i], errs[i] = h.lookupKnowledge(ctx, ns, userID, queryText, plan, degraded)
+			for j := range results[i] {
+				if results[i][j].Namespace == "" {
+					results[i][j].Namespace = ns
+				}
+			}
+		}(i, ns)
+	}
+	wg.Wait()
+
+	var merged []graph.Node
+	var firstErr error
+	seen := make(map[string]bool)
+	for i, facts := range results {
+		if errs[i] != nil {
+			h.logger.Warn("Failed to get knowledge for namespace",
+				zap.String("namespace", namespaces[i]),
+				zap.Error(errs[i]))
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+		}
+		for _, fact := range facts {
+			if fact.UID != "" {
+				if seen[fact.UID] {
+					continue
+				}
+				seen[fact.UID] = true
+			}
+			merged = append(merged, fact)
+		}
+	}
+
+	// Stable, so ties keep the primary namespace first
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Activation > merged[j].Activation
+	})
+	if len(merged) > maxMergedFacts {
+		merged = merged[:maxMergedFacts]
+	}
+
+	h.logger.Info("Merged knowledge across namespaces",
+		zap.Strings("namespaces", namespaces),
+		zap.Int("facts", len(merged)))
+
+	return merged, firstErr
+}
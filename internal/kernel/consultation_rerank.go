@@ -0,0 +1,286 @@
+package kernel
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// DefaultRerankMaxCandidates is how many of a consultation's top facts
+	// are scored when it asks for reranking; the rest follow them unscored
+	DefaultRerankMaxCandidates = 20
+
+	// DefaultRerankCandidatesPerMinute caps the facts sent to the LLM for
+	// scoring each minute, across all consultations. Cached scores are free.
+	DefaultRerankCandidatesPerMinute = 600
+
+	// rerankScoreTTL is how long a fact's score for a query is reused
+	rerankScoreTTL = 5 * time.Minute
+
+	// maxRerankScores bounds the score cache; expired scores are swept once
+	// it fills, and new ones dropped while it stays full
+	maxRerankScores = 10000
+)
+
+// errRerankBudget is returned when scoring a consultation's facts would go
+// over the minute's rerank budget
+var errRerankBudget = errors.New("rerank budget exhausted")
+
+// reranker holds the LLM rerank cost budget and the scores already paid for.
+// It is shared by all consultations.
+type reranker struct {
+	mu            sync.Mutex
+	maxCandidates int
+	perMinute     int // 0 = reranking disabled
+	windowStart   time.Time
+	spent         int
+	scores        map[string]rerankScore
+}
+
+type rerankScore struct {
+	score   float64
+	expires time.Time
+}
+
+func newReranker(maxCandidates, perMinute int) *reranker {
+	return &reranker{
+		maxCandidates: maxCandidates,
+		perMinute:     perMinute,
+		scores:        make(map[string]rerankScore),
+	}
+}
+
+// SetRerankBudget configures how many facts a reranked consultation scores
+// (non-positive keeps DefaultRerankMaxCandidates) and how many facts may be
+// scored per minute in all (negative disables reranking, 0 keeps
+// DefaultRerankCandidatesPerMinute)
+func (h *ConsultationHandler) SetRerankBudget(maxCandidates, perMinute int) {
+	if maxCandidates <= 0 {
+		maxCandidates = DefaultRerankMaxCandidates
+	}
+	if perMinute == 0 {
+		perMinute = DefaultRerankCandidatesPerMinute
+	}
+	if perMinute < 0 {
+		perMinute = 0
+	}
+	h.reranker.mu.Lock()
+	defer h.reranker.mu.Unlock()
+	h.reranker.maxCandidates = maxCandidates
+	h.reranker.perMinute = perMinute
+}
+
+// reserve takes n facts from the minute's budget, or nothing when they
+// don't fit
+func (r *reranker) reserve(n int, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.Sub(r.windowStart) >= time.Minute {
+		r.windowStart = now
+		r.spent = 0
+	}
+	if r.spent+n > r.perMinute {
+		return false
+	}
+	r.spent += n
+	return true
+}
+
+func (r *reranker) cached(key string, now time.Time) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.scores[key]
+	if !ok || now.After(s.expires) {
+		return 0, false
+	}
+	return s.score, true
+}
+
+func (r *reranker) store(key string, score float64, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.scores) >= maxRerankScores {
+		for k, s := range r.scores {
+			if now.After(s.expires) {
+				delete(r.scores, k)
+			}
+		}
+		if len(r.scores) >= maxRerankScores {
+			return
+		}
+	}
+	r.scores[key] = rerankScore{score: score, expires: now.Add(rerankScoreTTL)}
+}
+
+// rerankKey identifies fact's score for the query hashed to queryHash. Facts
+// without a UID (hot-cache answers) are told apart by their text.
+func rerankKey(queryHash string, fact graph.Node) string {
+	id := fact.UID
+	if id == "" {
+		sum := sha256.Sum256([]byte(factText(fact)))
+		id = hex.EncodeToString(sum[:8])
+	}
+	return queryHash + ":" + id
+}
+
+// rerankFacts reorders facts by how relevant the LLM judges each to query,
+// highest first; ties keep their retrieval order. Only the top
+// maxCandidates facts are scored and the rest follow them as they were.
+// Facts without a cached score are scored in one call to the AI service,
+// if the minute's budget covers them. On error facts are returned unchanged.
+func (h *ConsultationHandler) rerankFacts(ctx context.Context, query string, facts []graph.Node) ([]graph.Node, error) {
+	if len(facts) < 2 {
+		return facts, nil
+	}
+
+	h.reranker.mu.Lock()
+	maxCandidates := h.reranker.maxCandidates
+	h.reranker.mu.Unlock()
+	n := min(len(facts), maxCandidates)
+
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(query))))
+	queryHash := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	scores := make([]float64, n)
+	var pending []int
+	for i, fact := range facts[:n] {
+		if score, ok := h.reranker.cached(rerankKey(queryHash, fact), now); ok {
+			scores[i] = score
+		} else {
+			pending = append(pending, i)
+		}
+	}
+
+	if len(pending) > 0 {
+		if !h.reranker.reserve(len(pending), now) {
+			return facts, errRerankBudget
+		}
+		texts := make([]string, len(pending))
+		for j, i := range pending {
+			texts[j] = factText(facts[i])
+		}
+		fetched, err := h.scoreRelevance(ctx, query, texts)
+		if err != nil {
+			return facts, err
+		}
+		missing := 0
+		for j, i := range pending {
+			score, ok := fetched[j]
+			if !ok {
+				missing++
+				continue
+			}
+			scores[i] = score
+			h.reranker.store(rerankKey(queryHash, facts[i]), score, now)
+		}
+		if missing > 0 {
+			return facts, fmt.Errorf("rerank left %d of %d facts unscored", missing, len(pending))
+		}
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	reranked := make([]graph.Node, 0, len(facts))
+	for _, i := range order {
+		reranked = append(reranked, facts[i])
+	}
+	return append(reranked, facts[n:]...), nil
+}
+
+// scoreRelevance asks the AI service how relevant each text is to query, on
+// a 0-1 scale, through the rerank breaker. Texts the service didn't score
+// are missing from the result.
+func (h *ConsultationHandler) scoreRelevance(ctx context.Context, query string, texts []string) (map[int]float64, error) {
+	if h.noReranker.Load() {
+		return nil, fmt.Errorf("AI service has no reranker")
+	}
+
+	var scores map[int]float64
+	var callerErr error
+	err := h.rerankBreaker.Execute(func() error {
+		callCtx, cancel := context.WithTimeout(ctx, h.aiClient.Timeout)
+		defer cancel()
+		var err error
+		scores, err = h.requestRerank(callCtx, query, texts)
+		if err != nil && ctx.Err() != nil {
+			// The caller's deadline ran out; that's no sign the AI service is down
+			callerErr = err
+			return nil
+		}
+		return err
+	})
+	if callerErr != nil {
+		return nil, callerErr
+	}
+	return scores, err
+}
+
+// requestRerank calls the AI service's /rerank. Candidates are identified by
+// their index in texts. An AI service without /rerank is not asked again.
+func (h *ConsultationHandler) requestRerank(ctx context.Context, query string, texts []string) (map[int]float64, error) {
+	type candidate struct {
+		ID   string `json:"id"`
+		Text string `json:"text"`
+	}
+	candidates := make([]candidate, len(texts))
+	for i, text := range texts {
+		candidates[i] = candidate{ID: strconv.Itoa(i), Text: text}
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{"query": query, "candidates": candidates})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", h.aiServicesURL+"/rerank", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.aiClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		h.noReranker.Store(true)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank returned status %d", resp.StatusCode)
+	}
+	var result struct {
+		Scores map[string]float64 `json:"scores"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	scores := make(map[int]float64, len(result.Scores))
+	for id, score := range result.Scores {
+		i, err := strconv.Atoi(id)
+		if err != nil || i < 0 || i >= len(texts) {
+			continue
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
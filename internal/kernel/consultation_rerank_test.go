@@ -0,0 +1,154 @@
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+// TestRerankPromotesRelevantFact checks that a fact vector similarity ranked
+// second comes first once the reranker scores it higher, and that a repeated
+// query reuses the cached scores
+func TestRerankPromotesRelevantFact(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	const namespace = "user_alice"
+
+	// Qdrant stand-in ranking the office excerpt above the colour one
+	qdrant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": []map[string]interface{}{
+			{"id": 1, "score": 0.9, "payload": map[string]interface{}{
+				"uid": "chunk_office", "namespace": namespace, "text": "Alice's office colour scheme is grey",
+			}},
+			{"id": 2, "score": 0.6, "payload": map[string]interface{}{
+				"uid": "chunk_colour", "namespace": namespace, "text": "Alice's favourite colour is teal",
+			}},
+		}})
+	}))
+	defer qdrant.Close()
+
+	var rerankCalls atomic.Int32
+	aiService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rerank":
+			rerankCalls.Add(1)
+			var req struct {
+				Candidates []struct {
+					ID   string `json:"id"`
+					Text string `json:"text"`
+				} `json:"candidates"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			scores := map[string]float64{}
+			for _, c := range req.Candidates {
+				scores[c.ID] = 0.1
+				if strings.Contains(c.Text, "favourite colour") {
+					scores[c.ID] = 0.95
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"scores": scores})
+		case "/synthesize":
+			json.NewEncoder(w).Encode(map[string]interface{}{"brief": "Your favourite colour is teal.", "confidence": 0.9})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer aiService.Close()
+
+	vectorIndex := NewVectorIndex(qdrant.URL, DefaultCollectionName, logger)
+	h := NewConsultationHandler(nil, nil, fakeredis.New(t), vectorIndex, keywordEmbedder{keyword: "favourite"}, nil, nil, aiService.URL, logger)
+	req := &graph.ConsultationRequest{UserID: "alice", Namespace: namespace, Query: "what is my favourite colour"}
+
+	resp, err := h.Handle(ctx, req)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if len(resp.RelevantFacts) != 2 || resp.RelevantFacts[0].UID != "chunk_office" {
+		t.Fatalf("facts = %v, want the office excerpt first by similarity", resp.RelevantFacts)
+	}
+	if resp.Reranked || rerankCalls.Load() != 0 {
+		t.Fatal("reranked without the request asking for it")
+	}
+
+	req.Rerank = true
+	for i := 0; i < 2; i++ {
+		resp, err = h.Handle(ctx, req)
+		if err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		if !resp.Reranked || len(resp.RelevantFacts) != 2 || resp.RelevantFacts[0].UID != "chunk_colour" {
+			t.Fatalf("consult %d: facts = %v (reranked %v), want the colour excerpt promoted",
+				i, resp.RelevantFacts, resp.Reranked)
+		}
+	}
+	if n := rerankCalls.Load(); n != 1 {
+		t.Errorf("rerank called %d times, want 1 with the second consult served from cache", n)
+	}
+
+	// A budget too small for the facts leaves them in retrieval order
+	h = NewConsultationHandler(nil, nil, fakeredis.New(t), vectorIndex, keywordEmbedder{keyword: "favourite"}, nil, nil, aiService.URL, logger)
+	h.SetRerankBudget(DefaultRerankMaxCandidates, 1)
+	resp, err = h.Handle(ctx, req)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if resp.Reranked || resp.RelevantFacts[0].UID != "chunk_office" {
+		t.Errorf("facts = %v (reranked %v), want retrieval order once the budget is spent", resp.RelevantFacts, resp.Reranked)
+	}
+	if len(resp.DegradedModes) != 1 || resp.DegradedModes[0] != graph.DegradedModeRerank {
+		t.Errorf("degraded modes = %v, want [%s]", resp.DegradedModes, graph.DegradedModeRerank)
+	}
+	if n := rerankCalls.Load(); n != 1 {
+		t.Errorf("rerank called %d times; an over-budget consult must not call it", n)
+	}
+}
+
+// TestRerankOutageLeavesSynthesisBreaker checks that a failing /rerank opens
+// only the rerank breaker, and an open synthesis breaker doesn't stop reranking
+func TestRerankOutageLeavesSynthesisBreaker(t *testing.T) {
+	var failRerank atomic.Bool
+	failRerank.Store(true)
+	aiService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failRerank.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"scores": map[string]float64{"0": 0.9}})
+	}))
+	defer aiService.Close()
+
+	ctx := context.Background()
+	h := NewConsultationHandler(nil, nil, nil, nil, nil, nil, nil, aiService.URL, zaptest.NewLogger(t))
+	for i := 0; i < 5; i++ {
+		if _, err := h.scoreRelevance(ctx, "favourite colour", []string{"teal"}); err == nil {
+			t.Fatal("scoreRelevance succeeded against a failing reranker")
+		}
+	}
+	if state := h.rerankBreaker.GetState(); state != CircuitOpen {
+		t.Errorf("rerank breaker = %v, want open", state)
+	}
+	if state := h.synthesisBreaker.GetState(); state != CircuitClosed {
+		t.Errorf("synthesis breaker = %v after rerank failures, want closed", state)
+	}
+
+	// The other way round: synthesis down, reranking still asked
+	h = NewConsultationHandler(nil, nil, nil, nil, nil, nil, nil, aiService.URL, zaptest.NewLogger(t))
+	for i := 0; i < 5; i++ {
+		h.synthesisBreaker.Execute(func() error { return errors.New("synthesis down") })
+	}
+	failRerank.Store(false)
+	scores, err := h.scoreRelevance(ctx, "favourite colour", []string{"teal"})
+	if err != nil || scores[0] != 0.9 {
+		t.Errorf("scoreRelevance with synthesis down = %v, %v; want the reranker's score", scores, err)
+	}
+}
@@ -0,0 +1,450 @@
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/ai/synthesis"
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+// TestHandleDegradesWhenSynthesisDown checks that a failing AI service yields
+// the fallback brief instead of an error, and that the breaker stops calling it
+func TestHandleDegradesWhenSynthesisDown(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	var synthCalls atomic.Int32
+	aiService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/synthesize" {
+			synthCalls.Add(1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer aiService.Close()
+
+	const namespace = "user_alice"
+	vectorIndex := NewVectorIndex(newFakeQdrant(t).URL, DefaultCollectionName, logger)
+	if err := vectorIndex.Store(ctx, namespace, "chunk_doc_0", []float32{0.1, 0.2, 0.3},
+		map[string]interface{}{"text": "Alice's favourite colour is teal"}); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	h := NewConsultationHandler(nil, nil, fakeredis.New(t), vectorIndex, &countingEmbedder{}, nil, nil, aiService.URL, logger)
+	req := &graph.ConsultationRequest{UserID: "alice", Namespace: namespace, Query: "what is my favourite colour"}
+
+	const consults = 8
+	for i := 0; i < consults; i++ {
+		resp, err := h.Handle(ctx, req)
+		if err != nil {
+			t.Fatalf("Handle %d: %v", i, err)
+		}
+		if !strings.HasPrefix(resp.SynthesizedBrief, "Based on what I know:") {
+			t.Fatalf("Handle %d brief = %q, want the fallback brief", i, resp.SynthesizedBrief)
+		}
+	}
+
+	if got := h.SynthesisStats()["fallbacks"]; got != int64(consults) {
+		t.Errorf("fallbacks = %v, want %d", got, consults)
+	}
+	if calls := synthCalls.Load(); calls >= consults {
+		t.Errorf("AI service called %d times; the open breaker should have skipped some", calls)
+	}
+	if state := h.synthesisBreaker.GetState(); state != CircuitOpen {
+		t.Errorf("breaker state = %v, want open", state)
+	}
+}
+
+// TestHandleDegradesWhenQdrantDown checks that an unreachable vector store
+// degrades a consultation instead of failing it, and is reported to the caller
+func TestHandleDegradesWhenQdrantDown(t *testing.T) {
+	qdrant := httptest.NewServer(http.NotFoundHandler())
+	qdrant.Close() // Connections are refused from here on
+
+	logger := zaptest.NewLogger(t)
+	vectorIndex := NewVectorIndex(qdrant.URL, DefaultCollectionName, logger)
+	h := NewConsultationHandler(nil, nil, fakeredis.New(t), vectorIndex, &countingEmbedder{}, nil, nil, "", logger)
+
+	resp, err := h.Handle(context.Background(), &graph.ConsultationRequest{
+		UserID: "alice", Namespace: "user_alice", Query: "what is my favourite colour",
+	})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if len(resp.DegradedModes) != 1 || resp.DegradedModes[0] != graph.DegradedModeVector {
+		t.Errorf("degraded modes = %v, want [%s]", resp.DegradedModes, graph.DegradedModeVector)
+	}
+
+	stats := h.VectorSearchStats()
+	if stats["failures"] != int64(1) || stats["available"] != false {
+		t.Errorf("vector stats = %v, want one failure and unavailable", stats)
+	}
+}
+
+func TestConsultNamespacesDropsUnauthorized(t *testing.T) {
+	h := NewConsultationHandler(nil, nil, nil, nil, nil, nil, nil, "", zaptest.NewLogger(t))
+	req := &graph.ConsultationRequest{
+		UserID: "alice",
+		Namespaces: []string{
+			"user_alice", // the primary again
+			"user_bob",   // someone else's private memory
+			"group_eng",  // membership can't be verified without a graph
+			"",
+		},
+	}
+
+	got := h.consultNamespaces(context.Background(), req, "user_alice")
+	if len(got) != 1 || got[0] != "user_alice" {
+		t.Errorf("consultNamespaces = %v, want only the primary namespace", got)
+	}
+}
+
+// TestConsultFindsJustIngestedChunk ingests a document chunk and consults
+// straight away: the chunk must be searchable without waiting for Qdrant
+func TestConsultFindsJustIngestedChunk(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	vectorIndex := NewVectorIndex(newFakeQdrant(t).URL, DefaultCollectionName, logger)
+
+	const namespace = "user_alice"
+	pipeline := &IngestionPipeline{vectorIndex: vectorIndex, logger: logger}
+	chunks := []graph.DocumentChunk{{Text: "The launch moved to March 3rd", Embedding: []float32{0.1, 0.2, 0.3}}}
+	if err := pipeline.PersistChunks(ctx, namespace, "doc1", chunks); err != nil {
+		t.Fatalf("PersistChunks: %v", err)
+	}
+
+	h := NewConsultationHandler(nil, nil, fakeredis.New(t), vectorIndex, &countingEmbedder{}, nil, nil, "", logger)
+	h.SetRecencyBoost(DefaultRecencyBoostWindow)
+	resp, err := h.Handle(ctx, &graph.ConsultationRequest{UserID: "alice", Namespace: namespace, Query: "when is the launch"})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	for _, fact := range resp.RelevantFacts {
+		if fact.Description == chunks[0].Text {
+			if fact.CreatedAt.IsZero() {
+				t.Error("snippet lost its ingestion time")
+			}
+			return
+		}
+	}
+	t.Errorf("just-ingested chunk missing from facts %v", resp.RelevantFacts)
+}
+
+func TestRecencyBonusRanksFreshMemoriesUp(t *testing.T) {
+	now := time.Now()
+	window := 10 * time.Minute
+
+	fresh := 0.4*0.5 + recencyBonus(now.Add(-time.Minute), now, window)
+	settled := 0.4*0.9 + recencyBonus(now.Add(-time.Hour), now, window)
+	if fresh <= settled {
+		t.Errorf("fresh memory scored %.2f, below the settled one's %.2f", fresh, settled)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		createdAt time.Time
+		window    time.Duration
+		want      float64
+	}{
+		{"just ingested", now, window, recencyBoostMax},
+		{"half way", now.Add(-window / 2), window, recencyBoostMax / 2},
+		{"outside window", now.Add(-window), window, 0},
+		{"unknown age", time.Time{}, window, 0},
+		{"disabled", now, -1, 0},
+	} {
+		if got := recencyBonus(tc.createdAt, now, tc.window); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: bonus = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// keywordEmbedder embeds text as a one-hot vector: axis 0 when it mentions
+// the keyword, axis 1 otherwise
+type keywordEmbedder struct{ keyword string }
+
+func (e keywordEmbedder) Embed(text string) ([]float32, error) {
+	if strings.Contains(strings.ToLower(text), e.keyword) {
+		return []float32{1, 0}, nil
+	}
+	return []float32{0, 1}, nil
+}
+
+func (e keywordEmbedder) Close() error { return nil }
+
+// TestParaphrasedQueryFoundAfterExpansion checks that a query whose retrieval
+// finds only unrelated facts is broadened through /expand-query exactly once
+func TestParaphrasedQueryFoundAfterExpansion(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	const namespace = "user_alice"
+
+	// Qdrant stand-in that only returns the stored excerpt for vectors near it
+	qdrant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Vector []float32 `json:"vector"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		hits := []map[string]interface{}{}
+		if len(req.Vector) > 0 && req.Vector[0] > 0.5 {
+			hits = append(hits, map[string]interface{}{"id": 1, "score": 0.9, "payload": map[string]interface{}{
+				"uid": "chunk_doc1_0", "namespace": namespace, "text": "The launch moved to March 3rd",
+			}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": hits})
+	}))
+	defer qdrant.Close()
+
+	var expansions atomic.Int32
+	aiService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/expand-query" {
+			http.NotFound(w, r)
+			return
+		}
+		expansions.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"search_terms": []string{"launch", "date"},
+			"entity_names": []string{},
+		})
+	}))
+	defer aiService.Close()
+
+	// The namespace's most active facts come back for every query
+	store := graph.NewMemStore()
+	store.QueryFunc = func(q string, vars map[string]string) ([]byte, error) {
+		if !strings.Contains(q, "HybridKnowledge") {
+			return []byte(`{}`), nil
+		}
+		return []byte(`{"by_activation": [
+			{"uid": "0x1", "name": "Coffee", "description": "Alice drinks oat milk flat whites", "namespace": "user_alice", "activation": 0.9},
+			{"uid": "0x2", "name": "Gym", "description": "Alice trains on Tuesdays", "namespace": "user_alice", "activation": 0.8}
+		]}`), nil
+	}
+
+	vectorIndex := NewVectorIndex(qdrant.URL, DefaultCollectionName, logger)
+	h := NewConsultationHandler(store, nil, nil, vectorIndex, keywordEmbedder{keyword: "launch"}, nil, nil, aiService.URL, logger)
+
+	facts, err := h.getUserKnowledge(ctx, namespace, "alice", "when does the rocket go up", planFor(graph.RetrievalStrategyHybrid), &degradedModes{})
+	if err != nil {
+		t.Fatalf("getUserKnowledge: %v", err)
+	}
+	if len(facts) != 3 || facts[0].Description != "The launch moved to March 3rd" {
+		t.Errorf("facts = %v, want the launch excerpt found through expansion ahead of the active facts", facts)
+	}
+	if n := expansions.Load(); n != 1 {
+		t.Errorf("expand-query called %d times, want 1", n)
+	}
+
+	// A direct hit needs no expansion
+	if _, err := h.getUserKnowledge(ctx, namespace, "alice", "launch date?", planFor(graph.RetrievalStrategyHybrid), &degradedModes{}); err != nil {
+		t.Fatalf("getUserKnowledge: %v", err)
+	}
+	if n := expansions.Load(); n != 1 {
+		t.Errorf("expand-query called for a query that matched directly (%d calls)", n)
+	}
+}
+
+// TestHandleAnswersWithinBudget checks that a consultation whose synthesis
+// would outlast its budget returns the facts it found, with a fallback brief,
+// instead of waiting on the AI service
+func TestHandleAnswersWithinBudget(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+
+	aiService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/synthesize" {
+			http.NotFound(w, r)
+			return
+		}
+		io.Copy(io.Discard, r.Body) // Lets the server notice the client hanging up
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer aiService.Close()
+
+	const namespace = "user_alice"
+	vectorIndex := NewVectorIndex(newFakeQdrant(t).URL, DefaultCollectionName, logger)
+	if err := vectorIndex.Store(ctx, namespace, "chunk_doc_0", []float32{0.1, 0.2, 0.3},
+		map[string]interface{}{"text": "Alice's favourite colour is teal"}); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	h := NewConsultationHandler(nil, nil, fakeredis.New(t), vectorIndex, &countingEmbedder{}, nil, nil, aiService.URL, logger)
+	req := &graph.ConsultationRequest{
+		UserID: "alice", Namespace: namespace, Query: "what is my favourite colour", BudgetMs: 300,
+	}
+
+	start := time.Now()
+	resp, err := h.Handle(ctx, req)
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Handle took %v with a 300ms budget", elapsed)
+	}
+	if len(resp.RelevantFacts) == 0 {
+		t.Error("no facts returned; want the ones retrieved before the budget ran out")
+	}
+	if !strings.HasPrefix(resp.SynthesizedBrief, "Based on what I know:") {
+		t.Errorf("brief = %q, want the fallback brief", resp.SynthesizedBrief)
+	}
+	var budgetNoted bool
+	for _, mode := range resp.DegradedModes {
+		budgetNoted = budgetNoted || mode == graph.DegradedModeBudget
+	}
+	if !budgetNoted {
+		t.Errorf("degraded modes = %v, want %s", resp.DegradedModes, graph.DegradedModeBudget)
+	}
+	if state := h.synthesisBreaker.GetState(); state != CircuitClosed {
+		t.Errorf("breaker state = %v; a spent budget is no sign the AI service is down", state)
+	}
+}
+
+// TestAIServiceConnectionsReused checks that consultations share one pooled
+// connection to the AI service rather than dialing for every call
+func TestAIServiceConnectionsReused(t *testing.T) {
+	var dials atomic.Int32
+	aiService := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		json.NewEncoder(w).Encode(map[string]interface{}{"brief": "Bob is your manager.", "confidence": 0.9})
+	}))
+	aiService.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			dials.Add(1)
+		}
+	}
+	aiService.Start()
+	defer aiService.Close()
+
+	h := NewConsultationHandler(nil, nil, nil, nil, nil, nil, nil, aiService.URL, zaptest.NewLogger(t))
+	h.SetAIServiceTimeout(2 * time.Second)
+	for i := 0; i < 5; i++ {
+		brief, _, _, err := h.synthesizeBrief(context.Background(), &graph.ConsultationRequest{Query: "who is my manager"}, &graph.ConsultationResponse{})
+		if err != nil || brief != "Bob is your manager." {
+			t.Fatalf("synthesizeBrief = %q, %v", brief, err)
+		}
+	}
+	if n := dials.Load(); n != 1 {
+		t.Errorf("%d connections opened for 5 syntheses, want 1", n)
+	}
+	if h.aiClient.Timeout != 2*time.Second {
+		t.Errorf("timeout = %v, want 2s", h.aiClient.Timeout)
+	}
+}
+
+// TestPinnedCandidatesReadFromNamespace checks that pinned nodes are looked
+// up within the caller's namespace rather than across every pinned node
+func TestPinnedCandidatesReadFromNamespace(t *testing.T) {
+	store := graph.NewMemStore()
+	var query string
+	store.QueryFunc = func(q string, vars map[string]string) ([]byte, error) {
+		query = q
+		return []byte(`{"pinned": [{"uid": "0x9", "name": "Passport number", "namespace": "user_alice", "pinned": true}]}`), nil
+	}
+	h := NewConsultationHandler(store, nil, nil, nil, nil, nil, nil, "", zaptest.NewLogger(t))
+
+	nodes, err := h.retrieveKnowledge(context.Background(), "user_alice", "alice", "passport", retrievalPlan{Activation: true}, &degradedModes{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, "pinned(func: eq(namespace, $namespace), first: 50) @filter(eq(pinned, true))") {
+		t.Errorf("pinned block is not rooted at the namespace:\n%s", query)
+	}
+	if len(nodes) != 1 || nodes[0].UID != "0x9" {
+		t.Errorf("candidates = %+v, want the pinned node", nodes)
+	}
+}
+
+func TestFormatBriefCapsFacts(t *testing.T) {
+	facts := make([]graph.Node, 5)
+	for i := range facts {
+		facts[i] = graph.Node{Name: fmt.Sprintf("Fact %d", i+1)}
+	}
+
+	brief, _ := formatBrief(facts, nil, 3)
+	if !strings.Contains(brief, "Fact 3") || strings.Contains(brief, "Fact 4") ||
+		!strings.Contains(brief, "... and 2 more items.") {
+		t.Errorf("brief capped at 3 = %q", brief)
+	}
+	if brief, _ := formatBrief(facts, nil, 5); strings.Contains(brief, "more items") {
+		t.Errorf("brief capped at 5 counted overflow: %q", brief)
+	}
+
+	h := NewConsultationHandler(graph.NewMemStore(), nil, nil, nil, nil, nil, nil, "", zaptest.NewLogger(t))
+	if h.briefMaxFacts != synthesis.DefaultMaxFacts {
+		t.Errorf("default cap = %d, want the AI service's %d", h.briefMaxFacts, synthesis.DefaultMaxFacts)
+	}
+	h.SetBriefMaxFacts(0)
+	h.SetBriefMaxFacts(4)
+	if h.briefMaxFacts != 4 {
+		t.Errorf("cap = %d after SetBriefMaxFacts(4)", h.briefMaxFacts)
+	}
+}
+
+func TestApplyTemporalValidity(t *testing.T) {
+	at := func(s string) *time.Time {
+		ts, _ := time.Parse(time.RFC3339, s)
+		return &ts
+	}
+	now := *at("2024-03-01T00:00:00Z")
+	tests := []struct {
+		name     string
+		node     graph.Node
+		kept     bool
+		desc     string
+		historic bool
+	}{
+		{"current", graph.Node{Description: "Berlin"}, true, "Berlin", false},
+		{"ended", graph.Node{Description: "Berlin", ValidUntil: at("2021-01-01T00:00:00Z")}, true,
+			"Berlin [no longer current: valid until 2021-01-01]", true},
+		{"ends now", graph.Node{Description: "Berlin", ValidUntil: at("2024-03-01T00:00:00Z")}, true,
+			"Berlin [no longer current: valid until 2024-03-01]", true},
+		{"not started", graph.Node{Description: "Lisbon", ValidFrom: at("2025-01-01T00:00:00Z")}, true,
+			"Lisbon [not yet current: valid from 2025-01-01]", false},
+		{"superseded with no end", graph.Node{Description: "Paris", Status: graph.FactStatusSuperseded}, false, "", false},
+		{"archived", graph.Node{Description: "Rome", Status: graph.NodeStatusArchived}, false, "", false},
+	}
+	for _, tt := range tests {
+		got := applyTemporalValidity([]graph.Node{tt.node}, now)
+		if !tt.kept {
+			if len(got) != 0 {
+				t.Errorf("%s: kept %v, want it dropped", tt.name, got)
+			}
+			continue
+		}
+		if len(got) != 1 {
+			t.Errorf("%s: dropped, want it kept", tt.name)
+			continue
+		}
+		if got[0].Description != tt.desc {
+			t.Errorf("%s: description = %q, want %q", tt.name, got[0].Description, tt.desc)
+		}
+		historic := len(got[0].Tags) > 0 && got[0].Tags[len(got[0].Tags)-1] == graph.FactStatusHistorical
+		if historic != tt.historic {
+			t.Errorf("%s: tags = %v, want historical %v", tt.name, got[0].Tags, tt.historic)
+		}
+	}
+
+	// Tagging a fact as historical leaves slices sharing its tags untouched
+	shared := make([]string, 1, 2)
+	shared[0] = "home"
+	other := append(shared, "office")
+	applyTemporalValidity([]graph.Node{{Tags: shared, ValidUntil: at("2021-01-01T00:00:00Z")}}, now)
+	if other[1] != "office" {
+		t.Errorf("applyTemporalValidity wrote into a shared tags array: %v", other)
+	}
+}
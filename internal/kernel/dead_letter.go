@@ -0,0 +1,205 @@
+// Package kernel provides the dead-letter queue for direct (zero-copy) ingestion.
+// Events that fail IngestDirect are persisted to Redis and retried with backoff
+// instead of being dropped.
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// deadLetterSchedule is a sorted set of entry IDs scored by next retry (unix seconds)
+	deadLetterSchedule = "ingest:dlq"
+	// deadLetterEntries is a hash of entry ID -> DeadLetterEntry JSON
+	deadLetterEntries = "ingest:dlq:entries"
+
+	deadLetterPollInterval = 10 * time.Second
+	deadLetterBaseDelay    = 30 * time.Second
+	deadLetterMaxDelay     = time.Hour
+	deadLetterBatchSize    = 50
+)
+
+// DeadLetterEntry is a failed ingestion event awaiting retry
+type DeadLetterEntry struct {
+	ID        string                 `json:"id"`
+	Event     *graph.TranscriptEvent `json:"event"`
+	Attempts  int                    `json:"attempts"`
+	LastError string                 `json:"last_error"`
+	FailedAt  time.Time              `json:"failed_at"`
+}
+
+// DeadLetterQueue persists failed transcript events in Redis and retries them
+type DeadLetterQueue struct {
+	redis  *redis.Client
+	ingest func(ctx context.Context, event *graph.TranscriptEvent) error
+	logger *zap.Logger
+
+	// retryMu serializes retry passes so the background loop and a manual
+	// retry never ingest the same entry twice
+	retryMu sync.Mutex
+}
+
+// NewDeadLetterQueue creates a dead-letter queue that retries through ingest
+func NewDeadLetterQueue(redisClient *redis.Client, ingest func(ctx context.Context, event *graph.TranscriptEvent) error, logger *zap.Logger) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		redis:  redisClient,
+		ingest: ingest,
+		logger: logger,
+	}
+}
+
+// Add persists a failed event; its first retry is scheduled after the base delay
+func (q *DeadLetterQueue) Add(ctx context.Context, event *graph.TranscriptEvent, cause error) error {
+	entry := DeadLetterEntry{
+		ID:        uuid.New().String(),
+		Event:     event,
+		Attempts:  1,
+		LastError: cause.Error(),
+		FailedAt:  time.Now(),
+	}
+	return q.save(ctx, &entry, time.Now().Add(deadLetterBaseDelay))
+}
+
+// Pending returns the number of events waiting in the queue
+func (q *DeadLetterQueue) Pending(ctx context.Context) (int64, error) {
+	return q.redis.ZCard(ctx, deadLetterSchedule).Result()
+}
+
+// Run retries due entries until ctx is cancelled
+func (q *DeadLetterQueue) Run(ctx context.Context) {
+	ticker := time.NewTicker(deadLetterPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := q.retry(ctx, false); err != nil && ctx.Err() == nil {
+				q.logger.Warn("Dead-letter retry pass failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RetryAll immediately retries every queued event regardless of schedule.
+// Returns the number of events ingested successfully.
+func (q *DeadLetterQueue) RetryAll(ctx context.Context) (int, error) {
+	return q.retry(ctx, true)
+}
+
+// retry re-ingests due entries (or all when force is set)
+func (q *DeadLetterQueue) retry(ctx context.Context, force bool) (int, error) {
+	q.retryMu.Lock()
+	defer q.retryMu.Unlock()
+
+	var ids []string
+	var err error
+	if force {
+		// Snapshot so entries rescheduled during this pass aren't retried twice
+		ids, err = q.redis.ZRange(ctx, deadLetterSchedule, 0, -1).Result()
+	} else {
+		ids, err = q.redis.ZRangeByScore(ctx, deadLetterSchedule, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(time.Now().Unix(), 10),
+			Count: deadLetterBatchSize,
+		}).Result()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read dead-letter schedule: %w", err)
+	}
+
+	succeeded := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return succeeded, ctx.Err()
+		}
+		ok, err := q.retryOne(ctx, id)
+		if err != nil {
+			return succeeded, err
+		}
+		if ok {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
+
+// retryOne re-ingests one entry; on failure it is rescheduled with exponential backoff
+func (q *DeadLetterQueue) retryOne(ctx context.Context, id string) (bool, error) {
+	raw, err := q.redis.HGet(ctx, deadLetterEntries, id).Result()
+	if err == redis.Nil {
+		// Orphaned schedule entry
+		q.redis.ZRem(ctx, deadLetterSchedule, id)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load dead-letter entry: %w", err)
+	}
+
+	var entry DeadLetterEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil || entry.Event == nil {
+		q.logger.Error("Dropping unreadable dead-letter entry", zap.String("id", id), zap.Error(err))
+		q.remove(ctx, id)
+		return false, nil
+	}
+
+	if ingestErr := q.ingest(ctx, entry.Event); ingestErr != nil {
+		delay := deadLetterBaseDelay << uint(entry.Attempts)
+		if delay <= 0 || delay > deadLetterMaxDelay {
+			delay = deadLetterMaxDelay
+		}
+		entry.Attempts++
+		entry.LastError = ingestErr.Error()
+		q.logger.Warn("Dead-letter retry failed",
+			zap.String("id", id),
+			zap.Int("attempts", entry.Attempts),
+			zap.Duration("next_retry_in", delay),
+			zap.Error(ingestErr))
+		return false, q.save(ctx, &entry, time.Now().Add(delay))
+	}
+
+	q.remove(ctx, id)
+	q.logger.Info("Dead-lettered event ingested",
+		zap.String("id", id),
+		zap.String("conversation_id", entry.Event.ConversationID),
+		zap.Int("attempts", entry.Attempts+1))
+	return true, nil
+}
+
+// save writes the entry and schedules its next attempt
+func (q *DeadLetterQueue) save(ctx context.Context, entry *DeadLetterEntry, next time.Time) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter entry: %w", err)
+	}
+
+	pipe := q.redis.TxPipeline()
+	pipe.HSet(ctx, deadLetterEntries, entry.ID, data)
+	pipe.ZAdd(ctx, deadLetterSchedule, redis.Z{Score: float64(next.Unix()), Member: entry.ID})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to persist dead-letter entry: %w", err)
+	}
+	return nil
+}
+
+// remove deletes an entry and its schedule
+func (q *DeadLetterQueue) remove(ctx context.Context, id string) {
+	pipe := q.redis.TxPipeline()
+	pipe.ZRem(ctx, deadLetterSchedule, id)
+	pipe.HDel(ctx, deadLetterEntries, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.logger.Warn("Failed to remove dead-letter entry", zap.String("id", id), zap.Error(err))
+	}
+}
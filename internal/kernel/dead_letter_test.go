@@ -0,0 +1,193 @@
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+// recordingIngest records the conversations it ingests and fails those in failing
+type recordingIngest struct {
+	failing  map[string]bool
+	ingested []string
+}
+
+func (r *recordingIngest) ingest(_ context.Context, event *graph.TranscriptEvent) error {
+	if r.failing[event.ConversationID] {
+		return errors.New("graph unavailable")
+	}
+	r.ingested = append(r.ingested, event.ConversationID)
+	return nil
+}
+
+// newTestDeadLetterQueue returns a queue over a fresh fake Redis
+func newTestDeadLetterQueue(t *testing.T) (*DeadLetterQueue, *redis.Client, *recordingIngest) {
+	rdb := fakeredis.New(t)
+	rec := &recordingIngest{failing: make(map[string]bool)}
+	return NewDeadLetterQueue(rdb, rec.ingest, zaptest.NewLogger(t)), rdb, rec
+}
+
+// saveEntry queues an event for conversation id with attempts made, due at next
+func saveEntry(t *testing.T, q *DeadLetterQueue, id string, attempts int, next time.Time) {
+	t.Helper()
+	entry := &DeadLetterEntry{
+		ID:       id,
+		Event:    &graph.TranscriptEvent{ConversationID: id},
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	}
+	if err := q.save(context.Background(), entry, next); err != nil {
+		t.Fatalf("save(%s): %v", id, err)
+	}
+}
+
+// assertGone fails unless id is absent from both the schedule and the entries
+func assertGone(t *testing.T, rdb *redis.Client, id string) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := rdb.ZScore(ctx, deadLetterSchedule, id).Result(); err != redis.Nil {
+		t.Errorf("%s still scheduled (err %v)", id, err)
+	}
+	if _, err := rdb.HGet(ctx, deadLetterEntries, id).Result(); err != redis.Nil {
+		t.Errorf("%s entry still stored (err %v)", id, err)
+	}
+}
+
+func TestDeadLetterRetriesDueEntriesInOrder(t *testing.T) {
+	ctx := context.Background()
+	q, rdb, rec := newTestDeadLetterQueue(t)
+
+	now := time.Now()
+	saveEntry(t, q, "later", 1, now.Add(-10*time.Second))
+	saveEntry(t, q, "future", 1, now.Add(time.Hour))
+	saveEntry(t, q, "earliest", 1, now.Add(-time.Minute))
+
+	n, err := q.retry(ctx, false)
+	if err != nil || n != 2 {
+		t.Fatalf("retry = %d, %v; want 2 ingested", n, err)
+	}
+	if len(rec.ingested) != 2 || rec.ingested[0] != "earliest" || rec.ingested[1] != "later" {
+		t.Errorf("ingested %v, want [earliest later]", rec.ingested)
+	}
+	assertGone(t, rdb, "earliest")
+	assertGone(t, rdb, "later")
+	if n, _ := q.Pending(ctx); n != 1 {
+		t.Errorf("Pending = %d, want the future entry kept", n)
+	}
+	if n, _ := rdb.HLen(ctx, deadLetterEntries).Result(); n != 1 {
+		t.Errorf("%d entries stored, want 1", n)
+	}
+}
+
+func TestDeadLetterBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{"first retry", 1, 2 * deadLetterBaseDelay},
+		{"third retry", 3, 8 * deadLetterBaseDelay},
+		{"capped", 7, deadLetterMaxDelay},
+		{"shift overflow", 70, deadLetterMaxDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			q, rdb, rec := newTestDeadLetterQueue(t)
+			rec.failing["conv"] = true
+			saveEntry(t, q, "conv", tt.attempts, time.Now().Add(-time.Second))
+
+			before := time.Now()
+			if n, err := q.retry(ctx, false); err != nil || n != 0 {
+				t.Fatalf("retry = %d, %v; want 0 ingested", n, err)
+			}
+
+			raw, err := rdb.HGet(ctx, deadLetterEntries, "conv").Result()
+			if err != nil {
+				t.Fatalf("entry dropped after a failed retry: %v", err)
+			}
+			var entry DeadLetterEntry
+			if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+				t.Fatalf("unmarshal entry: %v", err)
+			}
+			if entry.Attempts != tt.attempts+1 {
+				t.Errorf("Attempts = %d, want %d", entry.Attempts, tt.attempts+1)
+			}
+			if entry.LastError != "graph unavailable" {
+				t.Errorf("LastError = %q, want the ingest error", entry.LastError)
+			}
+
+			score, err := rdb.ZScore(ctx, deadLetterSchedule, "conv").Result()
+			if err != nil {
+				t.Fatalf("entry unscheduled after a failed retry: %v", err)
+			}
+			delay := time.Unix(int64(score), 0).Sub(before)
+			if delay < tt.want-2*time.Second || delay > tt.want+time.Second {
+				t.Errorf("next retry in %v, want %v", delay, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeadLetterRetryAll(t *testing.T) {
+	ctx := context.Background()
+	q, rdb, rec := newTestDeadLetterQueue(t)
+	rec.failing["broken"] = true
+
+	// Neither entry is due; RetryAll ignores the schedule
+	saveEntry(t, q, "ok", 1, time.Now().Add(time.Hour))
+	saveEntry(t, q, "broken", 1, time.Now().Add(2*time.Hour))
+
+	n, err := q.RetryAll(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("RetryAll = %d, %v; want 1 ingested", n, err)
+	}
+	if len(rec.ingested) != 1 || rec.ingested[0] != "ok" {
+		t.Errorf("ingested %v, want [ok]", rec.ingested)
+	}
+	assertGone(t, rdb, "ok")
+	if n, _ := q.Pending(ctx); n != 1 {
+		t.Errorf("Pending = %d, want the failing entry kept", n)
+	}
+
+	delete(rec.failing, "broken")
+	if n, err := q.RetryAll(ctx); err != nil || n != 1 {
+		t.Fatalf("second RetryAll = %d, %v; want 1 ingested", n, err)
+	}
+	assertGone(t, rdb, "broken")
+	if n, _ := q.Pending(ctx); n != 0 {
+		t.Errorf("Pending = %d, want 0", n)
+	}
+}
+
+func TestDeadLetterDropsOrphanedAndUnreadableEntries(t *testing.T) {
+	ctx := context.Background()
+	q, rdb, rec := newTestDeadLetterQueue(t)
+
+	due := float64(time.Now().Add(-time.Second).Unix())
+	// Scheduled with no stored entry
+	rdb.ZAdd(ctx, deadLetterSchedule, redis.Z{Score: due, Member: "orphan"})
+	// Stored entries that can't be retried
+	for id, raw := range map[string]string{"garbled": "{not json", "no-event": `{"id":"no-event"}`} {
+		rdb.HSet(ctx, deadLetterEntries, id, raw)
+		rdb.ZAdd(ctx, deadLetterSchedule, redis.Z{Score: due, Member: id})
+	}
+
+	if n, err := q.RetryAll(ctx); err != nil || n != 0 {
+		t.Fatalf("RetryAll = %d, %v; want 0 ingested", n, err)
+	}
+	if len(rec.ingested) != 0 {
+		t.Errorf("ingested %v, want nothing", rec.ingested)
+	}
+	for _, id := range []string{"orphan", "garbled", "no-event"} {
+		assertGone(t, rdb, id)
+	}
+}
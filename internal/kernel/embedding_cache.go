@@ -0,0 +1,143 @@
+// Package kernel provides a Redis-backed cache in front of the embedder so
+// identical texts (repeated queries, recurring node summaries) are embedded once.
+package kernel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/ai/local"
+)
+
+const (
+	// DefaultEmbeddingCacheTTL is how long cached vectors live in Redis
+	DefaultEmbeddingCacheTTL = 7 * 24 * time.Hour
+
+	embeddingCachePrefix = "emb:"
+	// embeddingCacheTimeout bounds Redis calls so a slow cache never stalls embedding
+	embeddingCacheTimeout = 200 * time.Millisecond
+)
+
+// CachedEmbedder wraps an embedder with a Redis cache keyed by model+sha256(text).
+// Vectors are stored under the model that produced them, so a fallback
+// embedder serving another model never fills the configured model's entries.
+type CachedEmbedder struct {
+	embedder local.LocalEmbedder
+	redis    *redis.Client
+	model    string
+	ttl      time.Duration
+	logger   *zap.Logger
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// NewCachedEmbedder wraps embedder; model, the model it is expected to serve,
+// scopes lookups so switching models never returns stale vectors from another
+// embedding space
+func NewCachedEmbedder(embedder local.LocalEmbedder, redisClient *redis.Client, model string, ttl time.Duration, logger *zap.Logger) *CachedEmbedder {
+	if ttl <= 0 {
+		ttl = DefaultEmbeddingCacheTTL
+	}
+	return &CachedEmbedder{
+		embedder: embedder,
+		redis:    redisClient,
+		model:    model,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// Embed returns the cached vector for text, computing and storing it on a miss
+func (c *CachedEmbedder) Embed(text string) ([]float32, error) {
+	key := c.key(c.model, text)
+
+	ctx, cancel := context.WithTimeout(context.Background(), embeddingCacheTimeout)
+	raw, err := c.redis.Get(ctx, key).Bytes()
+	cancel()
+	if err == nil {
+		if vec, ok := decodeEmbedding(raw); ok {
+			c.hits.Add(1)
+			return vec, nil
+		}
+	} else if err != redis.Nil {
+		c.errors.Add(1)
+		c.logger.Debug("Embedding cache read failed", zap.Error(err))
+	}
+	c.misses.Add(1)
+
+	vec, model, err := local.EmbedWithModel(c.embedder, text)
+	if err != nil {
+		return nil, err
+	}
+	if model != "" && !local.SameEmbeddingModel(model, c.model) {
+		c.logger.Debug("Caching embedding under the model that produced it",
+			zap.String("model", model), zap.String("expected", c.model))
+		key = c.key(model, text)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), embeddingCacheTimeout)
+	defer cancel()
+	if err := c.redis.Set(ctx, key, encodeEmbedding(vec), c.ttl).Err(); err != nil {
+		c.errors.Add(1)
+		c.logger.Debug("Embedding cache write failed", zap.Error(err))
+	}
+	return vec, nil
+}
+
+// Close closes the wrapped embedder
+func (c *CachedEmbedder) Close() error {
+	return c.embedder.Close()
+}
+
+// Stats returns cache hit/miss counters and the hit rate
+func (c *CachedEmbedder) Stats() map[string]interface{} {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	hitRate := 0.0
+	if total := hits + misses; total > 0 {
+		hitRate = float64(hits) / float64(total)
+	}
+	return map[string]interface{}{
+		"hits":     hits,
+		"misses":   misses,
+		"errors":   c.errors.Load(),
+		"hit_rate": hitRate,
+		"model":    c.model,
+	}
+}
+
+// key builds the cache key for model's vector of text
+func (c *CachedEmbedder) key(model, text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return embeddingCachePrefix + local.EmbeddingModelID(model) + ":" + hex.EncodeToString(sum[:])
+}
+
+// encodeEmbedding packs a vector as little-endian float32s
+func encodeEmbedding(vec []float32) []byte {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// decodeEmbedding unpacks a vector written by encodeEmbedding
+func decodeEmbedding(buf []byte) ([]float32, bool) {
+	if len(buf) == 0 || len(buf)%4 != 0 {
+		return nil, false
+	}
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vec, true
+}
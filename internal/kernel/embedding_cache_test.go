@@ -0,0 +1,77 @@
+package kernel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+func TestEmbeddingEncoding(t *testing.T) {
+	vec := []float32{0, 1, -0.5, math.MaxFloat32, float32(math.Inf(-1)), 1e-9}
+	got, ok := decodeEmbedding(encodeEmbedding(vec))
+	if !ok || !reflect.DeepEqual(got, vec) {
+		t.Errorf("round trip = %v, %v; want %v", got, ok, vec)
+	}
+	if len(encodeEmbedding(vec)) != 4*len(vec) {
+		t.Errorf("encoded %d bytes, want 4 per dimension", len(encodeEmbedding(vec)))
+	}
+
+	for _, bad := range [][]byte{nil, {}, {1, 2, 3}, make([]byte, 9)} {
+		if _, ok := decodeEmbedding(bad); ok {
+			t.Errorf("decodeEmbedding(%v) accepted a corrupt entry", bad)
+		}
+	}
+}
+
+// modelEmbedder returns a fixed vector and says which model made it
+type modelEmbedder struct {
+	model string
+	vec   []float32
+	calls int
+}
+
+func (e *modelEmbedder) Embed(text string) ([]float32, error) {
+	vec, _, err := e.EmbedWithModel(text)
+	return vec, err
+}
+
+func (e *modelEmbedder) EmbedWithModel(text string) ([]float32, string, error) {
+	e.calls++
+	return e.vec, e.model, nil
+}
+
+func (e *modelEmbedder) Close() error { return nil }
+
+func TestCachedEmbedderKeysByProducingModel(t *testing.T) {
+	rdb := fakeredis.New(t)
+	logger := zaptest.NewLogger(t)
+
+	// A fallback serving another model must not fill the configured model's entry
+	fallback := &modelEmbedder{model: "text-embedding-3-small", vec: []float32{0.9, 0.1}}
+	cache := NewCachedEmbedder(fallback, rdb, "nomic-embed-text", 0, logger)
+	cache.Embed("hello")
+	cache.Embed("hello")
+	if fallback.calls != 2 {
+		t.Errorf("fallback embedded %d times, want a miss each time", fallback.calls)
+	}
+
+	primary := &modelEmbedder{model: "nomic-embed-text:latest", vec: []float32{0.1, 0.9}}
+	cache = NewCachedEmbedder(primary, rdb, "nomic-embed-text", 0, logger)
+	vec, err := cache.Embed("hello")
+	if err != nil || !reflect.DeepEqual(vec, primary.vec) {
+		t.Fatalf("Embed = %v, %v; want the configured model's vector", vec, err)
+	}
+	if vec, _ := cache.Embed("hello"); !reflect.DeepEqual(vec, primary.vec) || primary.calls != 1 {
+		t.Errorf("second Embed = %v after %d calls, want a cache hit", vec, primary.calls)
+	}
+
+	// The other model's vector stays reachable under its own name
+	other := NewCachedEmbedder(&modelEmbedder{model: "text-embedding-3-small"}, rdb, "text-embedding-3-small", 0, logger)
+	if vec, _ := other.Embed("hello"); !reflect.DeepEqual(vec, fallback.vec) {
+		t.Errorf("other model's entry = %v, want %v", vec, fallback.vec)
+	}
+}
@@ -0,0 +1,137 @@
+// Package events publishes typed memory events to NATS for external consumers.
+// Analytics pipelines, webhooks and other reactive integrations can subscribe
+// to the well-known subjects below without coupling to kernel internals.
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// Type identifies the kind of memory event
+type Type string
+
+const (
+	TypeNodeCreated         Type = "node.created"
+	TypeEdgeCreated         Type = "edge.created"
+	TypeInsightGenerated    Type = "insight.generated"
+	TypeReflectionCompleted Type = "reflection.completed"
+)
+
+// SubjectPrefix is the NATS subject prefix for all memory events.
+// Subscribe to "rmk.events.>" to receive every event type.
+const SubjectPrefix = "rmk.events."
+
+// Subject returns the well-known NATS subject for an event type
+func Subject(t Type) string {
+	return SubjectPrefix + string(t)
+}
+
+// Event is the payload published for every memory event
+type Event struct {
+	ID        string                 `json:"id"`
+	Type      Type                   `json:"type"`
+	Namespace string                 `json:"namespace,omitempty"`
+	UID       string                 `json:"uid,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// Publisher emits memory events on NATS.
+// A nil *Publisher is valid and every method is a no-op, so callers never
+// need to check whether event publishing is enabled.
+type Publisher struct {
+	conn   *nats.Conn
+	logger *zap.Logger
+}
+
+// NewPublisher creates a publisher on an existing NATS connection.
+// Returns nil (a no-op publisher) when conn is nil.
+func NewPublisher(conn *nats.Conn, logger *zap.Logger) *Publisher {
+	if conn == nil {
+		return nil
+	}
+	return &Publisher{
+		conn:   conn,
+		logger: logger,
+	}
+}
+
+// Publish sends an event. Failures are logged and never returned so that
+// event emission cannot break ingestion or reflection.
+func (p *Publisher) Publish(evt Event) {
+	if p == nil || p.conn == nil {
+		return
+	}
+	if evt.ID == "" {
+		evt.ID = uuid.New().String()
+	}
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(evt)
+	if err != nil {
+		p.logger.Warn("Failed to marshal memory event", zap.String("type", string(evt.Type)), zap.Error(err))
+		return
+	}
+	if err := p.conn.Publish(Subject(evt.Type), data); err != nil {
+		p.logger.Warn("Failed to publish memory event", zap.String("type", string(evt.Type)), zap.Error(err))
+	}
+}
+
+// NodeCreated publishes a node.created event
+func (p *Publisher) NodeCreated(namespace, uid, name, nodeType string) {
+	p.Publish(Event{
+		Type:      TypeNodeCreated,
+		Namespace: namespace,
+		UID:       uid,
+		Data: map[string]interface{}{
+			"name":      name,
+			"node_type": nodeType,
+		},
+	})
+}
+
+// EdgeCreated publishes an edge.created event
+func (p *Publisher) EdgeCreated(namespace, fromUID, toUID, edgeType string) {
+	p.Publish(Event{
+		Type:      TypeEdgeCreated,
+		Namespace: namespace,
+		UID:       fromUID,
+		Data: map[string]interface{}{
+			"from_uid":  fromUID,
+			"to_uid":    toUID,
+			"edge_type": edgeType,
+		},
+	})
+}
+
+// InsightGenerated publishes an insight.generated event
+func (p *Publisher) InsightGenerated(namespace, uid, insightType string, sourceUIDs []string) {
+	p.Publish(Event{
+		Type:      TypeInsightGenerated,
+		Namespace: namespace,
+		UID:       uid,
+		Data: map[string]interface{}{
+			"insight_type": insightType,
+			"source_uids":  sourceUIDs,
+		},
+	})
+}
+
+// ReflectionCompleted publishes a reflection.completed event
+func (p *Publisher) ReflectionCompleted(cycle int64, duration time.Duration, errorCount int) {
+	p.Publish(Event{
+		Type: TypeReflectionCompleted,
+		Data: map[string]interface{}{
+			"cycle":       cycle,
+			"duration_ms": duration.Milliseconds(),
+			"errors":      errorCount,
+		},
+	})
+}
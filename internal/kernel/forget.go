@@ -0,0 +1,146 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/memory"
+)
+
+// minForgetTermLen skips terms so short they would match unrelated text
+const minForgetTermLen = 3
+
+// ForgetHook is run after a node is forgotten so caches outside the kernel
+// (e.g. the Pre-Cortex semantic cache) can drop anything derived from it
+type ForgetHook func(ctx context.Context, namespace string, node *graph.Node)
+
+// ForgetResult reports what Forget removed
+type ForgetResult struct {
+	UID            string `json:"uid"`
+	VectorRemoved  bool   `json:"vector_removed"`
+	ChunksRemoved  int    `json:"chunks_removed"`
+	HotCachePurged int    `json:"hot_cache_purged"`
+}
+
+// forgetGraph is the part of the graph client Forget uses
+type forgetGraph interface {
+	GetNode(ctx context.Context, uid string) (*graph.Node, error)
+	DeleteNode(ctx context.Context, uid, namespace string) error
+}
+
+// OnForget registers a hook run by Forget after the node is deleted
+func (k *Kernel) OnForget(hook ForgetHook) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.forgetHooks = append(k.forgetHooks, hook)
+}
+
+// Forget honors a deletion request: it deletes the node from the graph, removes
+// its vector from Qdrant and any pending reindex of it, removes document chunks
+// and purges hot-cache messages mentioning it and runs the registered hooks so
+// no other layer can resurface the forgotten fact.
+func (k *Kernel) Forget(ctx context.Context, namespace, uid string) (*ForgetResult, error) {
+	node, err := k.forgetGraph.GetNode(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("node not found: %w", err)
+	}
+	if node.Namespace != namespace {
+		return nil, fmt.Errorf("namespace mismatch: cannot forget node from different namespace")
+	}
+
+	if err := k.forgetGraph.DeleteNode(ctx, uid, namespace); err != nil {
+		return nil, err
+	}
+
+	result := k.purgeForgotten(ctx, namespace, node)
+	k.logger.Info("Node forgotten",
+		zap.String("uid", uid),
+		zap.String("namespace", namespace),
+		zap.Bool("vector_removed", result.VectorRemoved),
+		zap.Int("chunks_removed", result.ChunksRemoved),
+		zap.Int("hot_cache_purged", result.HotCachePurged))
+	return result, nil
+}
+
+// purgeForgotten removes a deleted node from the pending-index queue, the
+// vector index, the document chunks quoting it, the hot cache and any hooked
+// caches. Failures are logged: the graph deletion already happened.
+func (k *Kernel) purgeForgotten(ctx context.Context, namespace string, node *graph.Node) *ForgetResult {
+	result := &ForgetResult{UID: node.UID}
+
+	// Dropped first, so a reindex can't restore the vector removed below
+	if k.indexQueue != nil {
+		if err := k.indexQueue.Drop(ctx, namespace, node.UID); err != nil {
+			k.logger.Warn("Failed to drop forgotten node from the index queue",
+				zap.String("uid", node.UID),
+				zap.Error(err))
+		}
+	}
+
+	terms := ForgetTerms(node)
+	if k.vectorIndex != nil {
+		if err := k.vectorIndex.Delete(ctx, namespace, node.UID); err != nil {
+			k.logger.Warn("Failed to remove forgotten node's vector",
+				zap.String("uid", node.UID),
+				zap.Error(err))
+		} else {
+			result.VectorRemoved = true
+		}
+
+		// Chunks are retrieved as excerpts, so one quoting the fact would resurface it
+		if len(terms) > 0 {
+			removed, err := k.vectorIndex.DeleteChunks(ctx, namespace, func(text string) bool {
+				return MentionsAny(text, terms)
+			})
+			if err != nil {
+				k.logger.Warn("Failed to remove document chunks mentioning forgotten node",
+					zap.String("uid", node.UID),
+					zap.Error(err))
+			}
+			result.ChunksRemoved = removed
+		}
+	}
+
+	if k.hotCache != nil {
+		result.HotCachePurged = k.hotCache.Purge(namespace, func(msg memory.CachedMessage) bool {
+			return MentionsAny(msg.Query, terms) || MentionsAny(msg.Response, terms)
+		})
+	}
+
+	// Cached consultations may quote the forgotten fact
+	k.consultationCache.Invalidate(ctx, namespace)
+
+	k.mu.RLock()
+	hooks := append([]ForgetHook(nil), k.forgetHooks...)
+	k.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(ctx, namespace, node)
+	}
+	return result
+}
+
+// ForgetTerms returns the texts that identify a node in cached conversation text
+func ForgetTerms(node *graph.Node) []string {
+	var terms []string
+	for _, t := range []string{node.Name, node.Description, node.SourceText} {
+		if t = strings.TrimSpace(t); len(t) >= minForgetTermLen {
+			terms = append(terms, strings.ToLower(t))
+		}
+	}
+	return terms
+}
+
+// MentionsAny reports whether text contains any of the (lowercase) terms
+func MentionsAny(text string, terms []string) bool {
+	lower := strings.ToLower(text)
+	for _, t := range terms {
+		if strings.Contains(lower, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// fakeQdrant is an in-memory stand-in for the Qdrant collections and points API
+type fakeQdrant struct {
+	mu          sync.Mutex
+	collections map[string]int                   // name -> vector size
+	points      map[int64]map[string]interface{} // id -> payload
+	vectors     map[int64][]float32              // id -> vector
+}
+
+// fakeQdrantQuery is the filter of a search or scroll request
+type fakeQdrantQuery struct {
+	Vector []float32 `json:"vector"`
+	Limit  int       `json:"limit"`
+	Filter struct {
+		Must []struct {
+			Key   string `json:"key"`
+			Match struct {
+				Value interface{} `json:"value"`
+			} `json:"match"`
+		} `json:"must"`
+	} `json:"filter"`
+}
+
+// matches reports whether payload satisfies every must condition
+func (q fakeQdrantQuery) matches(payload map[string]interface{}) bool {
+	for _, cond := range q.Filter.Must {
+		if payload[cond.Key] != cond.Match.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// newFakeQdrant serves the Qdrant endpoints VectorIndex uses from memory. Like
+// Qdrant, an upsert without ?wait=true is acknowledged before it is indexed;
+// the fake never indexes it, so a search can't find points stored that way.
+// Search ranks points by dot product with the query, ties broken by id.
+func newFakeQdrant(t *testing.T) *httptest.Server {
+	q := &fakeQdrant{
+		collections: make(map[string]int),
+		points:      make(map[int64]map[string]interface{}),
+		vectors:     make(map[int64][]float32),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		collection, isCollection := strings.CutPrefix(r.URL.Path, "/collections/")
+		isCollection = isCollection && !strings.Contains(collection, "/")
+
+		switch {
+		case r.Method == "GET" && isCollection:
+			size, ok := q.collections[collection]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"result": map[string]interface{}{"config": map[string]interface{}{
+					"params": map[string]interface{}{"vectors": map[string]interface{}{"size": size}},
+				}},
+			})
+			return
+		case r.Method == "PUT" && isCollection:
+			var req struct {
+				Vectors struct {
+					Size int `json:"size"`
+				} `json:"vectors"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			q.collections[collection] = req.Vectors.Size
+		case r.Method == "PUT" && strings.HasSuffix(r.URL.Path, "/points"):
+			var req struct {
+				Points []struct {
+					ID      int64                  `json:"id"`
+					Vector  []float32              `json:"vector"`
+					Payload map[string]interface{} `json:"payload"`
+				} `json:"points"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			if r.URL.Query().Get("wait") != "true" {
+				break
+			}
+			for _, p := range req.Points {
+				q.points[p.ID] = p.Payload
+				q.vectors[p.ID] = p.Vector
+			}
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/points"):
+			var req struct {
+				IDs []int64 `json:"ids"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			points := []map[string]interface{}{}
+			for _, id := range req.IDs {
+				if payload, ok := q.points[id]; ok {
+					points = append(points, map[string]interface{}{"id": id, "vector": q.vectors[id], "payload": payload})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": points})
+			return
+		case strings.HasSuffix(r.URL.Path, "/points/delete"):
+			var req struct {
+				Points []int64 `json:"points"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			for _, id := range req.Points {
+				delete(q.points, id)
+				delete(q.vectors, id)
+			}
+		case strings.HasSuffix(r.URL.Path, "/points/scroll"):
+			var req fakeQdrantQuery
+			json.NewDecoder(r.Body).Decode(&req)
+			points := []map[string]interface{}{}
+			for id, payload := range q.points {
+				if req.matches(payload) {
+					points = append(points, map[string]interface{}{"id": id, "payload": payload})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"result": map[string]interface{}{"points": points, "next_page_offset": nil},
+			})
+			return
+		case strings.HasSuffix(r.URL.Path, "/points/search"):
+			var req fakeQdrantQuery
+			json.NewDecoder(r.Body).Decode(&req)
+			var ids []int64
+			scores := make(map[int64]float32)
+			for id, payload := range q.points {
+				if req.matches(payload) {
+					ids = append(ids, id)
+					for i := range min(len(req.Vector), len(q.vectors[id])) {
+						scores[id] += req.Vector[i] * q.vectors[id][i]
+					}
+				}
+			}
+			sort.Slice(ids, func(i, j int) bool {
+				if scores[ids[i]] != scores[ids[j]] {
+					return scores[ids[i]] > scores[ids[j]]
+				}
+				return ids[i] < ids[j]
+			})
+			if req.Limit > 0 && len(ids) > req.Limit {
+				ids = ids[:req.Limit]
+			}
+			hits := []map[string]interface{}{}
+			for _, id := range ids {
+				hits = append(hits, map[string]interface{}{"id": id, "score": scores[id], "payload": q.points[id]})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": hits})
+			return
+		}
+		w.Write([]byte(`{"result":true}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// fakeForgetGraph holds graph nodes in memory for Forget and FindSimilar
+type fakeForgetGraph struct {
+	nodes map[string]*graph.Node
+}
+
+func (g *fakeForgetGraph) GetNode(_ context.Context, uid string) (*graph.Node, error) {
+	node := g.nodes[uid]
+	if node == nil {
+		return nil, fmt.Errorf("%w: %s", graph.ErrNodeNotFound, uid)
+	}
+	return node, nil
+}
+
+func (g *fakeForgetGraph) GetNodesByUIDs(_ context.Context, uids []string, _ []string) ([]graph.Node, error) {
+	var nodes []graph.Node
+	for _, uid := range uids {
+		if node := g.nodes[uid]; node != nil {
+			nodes = append(nodes, *node)
+		}
+	}
+	return nodes, nil
+}
+
+func (g *fakeForgetGraph) DeleteNode(_ context.Context, uid, namespace string) error {
+	if node := g.nodes[uid]; node == nil || node.Namespace != namespace {
+		return fmt.Errorf("node %s not found in %s", uid, namespace)
+	}
+	delete(g.nodes, uid)
+	return nil
+}
+
+func TestForgetRemovesNodeFromVectorSearch(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	qdrant := newFakeQdrant(t)
+
+	const namespace = "user_alice"
+	forgotten := &graph.Node{UID: "0x1", Name: "Secret project", Namespace: namespace}
+	k := &Kernel{
+		logger:      logger,
+		vectorIndex: NewVectorIndex(qdrant.URL, DefaultCollectionName, logger),
+		forgetGraph: &fakeForgetGraph{nodes: map[string]*graph.Node{"0x1": forgotten}},
+	}
+	vec := []float32{0.1, 0.2, 0.3}
+	for _, uid := range []string{"0x1", "0x2"} {
+		if err := k.vectorIndex.Store(ctx, namespace, uid, vec, nil); err != nil {
+			t.Fatalf("Store(%s): %v", uid, err)
+		}
+	}
+	chunks := []struct {
+		namespace, uid, text string
+	}{
+		{namespace, "chunk_doc1_0", "Notes on the secret project roadmap"},
+		{namespace, "chunk_doc1_1", "Lunch menu for Friday"},
+		{"user_bob", "chunk_doc2_0", "Bob's copy of the Secret Project plan"},
+	}
+	for _, c := range chunks {
+		payload := map[string]interface{}{"text": c.text, "type": "chunk", "source_id": "doc"}
+		if err := k.vectorIndex.Store(ctx, c.namespace, c.uid, vec, payload); err != nil {
+			t.Fatalf("Store(%s): %v", c.uid, err)
+		}
+	}
+
+	var hooked *graph.Node
+	k.OnForget(func(_ context.Context, ns string, node *graph.Node) {
+		if ns == namespace {
+			hooked = node
+		}
+	})
+
+	result, err := k.Forget(ctx, namespace, "0x1")
+	if err != nil {
+		t.Fatalf("Forget: %v", err)
+	}
+	if !result.VectorRemoved {
+		t.Error("expected vector to be removed")
+	}
+	if result.ChunksRemoved != 1 {
+		t.Errorf("ChunksRemoved = %d, want 1", result.ChunksRemoved)
+	}
+	if hooked != forgotten {
+		t.Error("forget hook was not run with the forgotten node")
+	}
+
+	uids, _, _, err := k.vectorIndex.Search(ctx, namespace, "", vec, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	remaining := make(map[string]bool)
+	for _, uid := range uids {
+		if uid == "0x1" {
+			t.Fatal("forgotten node still returned by vector search")
+		}
+		remaining[uid] = true
+	}
+	if len(remaining) != 2 || !remaining["0x2"] || !remaining["chunk_doc1_1"] {
+		t.Errorf("search returned %v, want the remaining node and the unrelated chunk", uids)
+	}
+
+	bobs, _, _, err := k.vectorIndex.Search(ctx, "user_bob", "", vec, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(bobs) != 1 || bobs[0] != "chunk_doc2_0" {
+		t.Errorf("another namespace's chunk was removed: %v", bobs)
+	}
+
+	if _, err := k.Forget(ctx, namespace, "0x1"); err == nil {
+		t.Error("forgetting an already forgotten node succeeded")
+	}
+}
+
+func TestForgetTermsMatchCachedText(t *testing.T) {
+	terms := ForgetTerms(&graph.Node{Name: "Acme Corp", Description: "", SourceText: "I work at Acme Corp"})
+	if !MentionsAny("Where does she work? At ACME CORP.", terms) {
+		t.Error("expected case-insensitive match on node name")
+	}
+	if MentionsAny("Nothing relevant here", terms) {
+		t.Error("unexpected match")
+	}
+	if got := ForgetTerms(&graph.Node{Name: "ab"}); len(got) != 0 {
+		t.Errorf("short terms should be skipped, got %v", got)
+	}
+}
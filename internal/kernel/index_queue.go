@@ -0,0 +1,250 @@
+// Package kernel provides the pending-index queue for vector upserts.
+// A node whose embedding can't be written to Qdrant during ingestion is
+// persisted to Redis and re-indexed with backoff, so it becomes searchable
+// once the vector store is back instead of never.
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// indexPendingSchedule is a sorted set of entry IDs scored by next retry (unix seconds)
+	indexPendingSchedule = "index:pending"
+	// indexPendingEntries is a hash of entry ID -> IndexPendingEntry JSON
+	indexPendingEntries = "index:pending:entries"
+
+	indexPendingPollInterval = 15 * time.Second
+	indexPendingBaseDelay    = 15 * time.Second
+	indexPendingMaxDelay     = 30 * time.Minute
+	indexPendingBatchSize    = 100
+)
+
+// IndexPendingEntry is a vector upsert awaiting retry
+type IndexPendingEntry struct {
+	Namespace string                 `json:"namespace"`
+	UID       string                 `json:"uid"`
+	Embedding []float32              `json:"embedding"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Attempts  int                    `json:"attempts"`
+	LastError string                 `json:"last_error"`
+	FailedAt  time.Time              `json:"failed_at"`
+}
+
+// id keys an entry by node, so a node that fails again replaces its older entry
+func (e *IndexPendingEntry) id() string {
+	return e.Namespace + ":" + e.UID
+}
+
+// vectorUpserter writes one embedding; VectorIndex is the usual one
+type vectorUpserter interface {
+	Store(ctx context.Context, namespace, uid string, embedding []float32, metadata map[string]interface{}) error
+}
+
+// IndexQueue stores embeddings through the vector index and parks the ones
+// that fail in Redis for a background retry. The ingest that produced them
+// still succeeds.
+type IndexQueue struct {
+	redis  *redis.Client
+	index  vectorUpserter
+	logger *zap.Logger
+
+	// retryMu serializes retry passes so the background loop and a manual
+	// reindex never upsert the same entry twice
+	retryMu sync.Mutex
+}
+
+// NewIndexQueue creates a pending-index queue writing through index
+func NewIndexQueue(redisClient *redis.Client, index vectorUpserter, logger *zap.Logger) *IndexQueue {
+	return &IndexQueue{
+		redis:  redisClient,
+		index:  index,
+		logger: logger,
+	}
+}
+
+// Store upserts an embedding, queueing it for retry if the vector store fails.
+// It only returns an error when the embedding could be neither stored nor queued.
+func (q *IndexQueue) Store(ctx context.Context, namespace, uid string, embedding []float32, metadata map[string]interface{}) error {
+	err := q.index.Store(ctx, namespace, uid, embedding, metadata)
+	if err == nil {
+		return nil
+	}
+
+	entry := IndexPendingEntry{
+		Namespace: namespace,
+		UID:       uid,
+		Embedding: embedding,
+		Metadata:  metadata,
+		Attempts:  1,
+		LastError: err.Error(),
+		FailedAt:  time.Now(),
+	}
+	// Detach from the request so a cancelled ingest can't lose the entry
+	if qErr := q.save(context.WithoutCancel(ctx), &entry, time.Now().Add(indexPendingBaseDelay)); qErr != nil {
+		return fmt.Errorf("vector upsert failed (%v) and could not be queued: %w", err, qErr)
+	}
+	q.logger.Warn("Vector upsert failed, queued for reindexing",
+		zap.String("namespace", namespace),
+		zap.String("uid", uid),
+		zap.Error(err))
+	return nil
+}
+
+// Drop removes a node's pending entry, if any, so a deleted node is never
+// re-indexed. It waits for a retry pass in progress, which could otherwise
+// store the node again after the caller removed its vector.
+func (q *IndexQueue) Drop(ctx context.Context, namespace, uid string) error {
+	q.retryMu.Lock()
+	defer q.retryMu.Unlock()
+
+	entry := IndexPendingEntry{Namespace: namespace, UID: uid}
+	pipe := q.redis.TxPipeline()
+	pipe.ZRem(ctx, indexPendingSchedule, entry.id())
+	pipe.HDel(ctx, indexPendingEntries, entry.id())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to drop pending-index entry: %w", err)
+	}
+	return nil
+}
+
+// Pending returns the number of nodes waiting to be indexed
+func (q *IndexQueue) Pending(ctx context.Context) (int64, error) {
+	return q.redis.ZCard(ctx, indexPendingSchedule).Result()
+}
+
+// Run retries due entries until ctx is cancelled
+func (q *IndexQueue) Run(ctx context.Context) {
+	ticker := time.NewTicker(indexPendingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := q.retry(ctx, false); err != nil && ctx.Err() == nil {
+				q.logger.Warn("Reindex pass failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ReindexAll immediately retries every pending node regardless of schedule.
+// Returns the number of nodes indexed successfully.
+func (q *IndexQueue) ReindexAll(ctx context.Context) (int, error) {
+	return q.retry(ctx, true)
+}
+
+// retry re-upserts due entries (or all when force is set)
+func (q *IndexQueue) retry(ctx context.Context, force bool) (int, error) {
+	q.retryMu.Lock()
+	defer q.retryMu.Unlock()
+
+	var ids []string
+	var err error
+	if force {
+		// Snapshot so entries rescheduled during this pass aren't retried twice
+		ids, err = q.redis.ZRange(ctx, indexPendingSchedule, 0, -1).Result()
+	} else {
+		ids, err = q.redis.ZRangeByScore(ctx, indexPendingSchedule, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(time.Now().Unix(), 10),
+			Count: indexPendingBatchSize,
+		}).Result()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pending-index schedule: %w", err)
+	}
+
+	indexed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return indexed, ctx.Err()
+		}
+		ok, err := q.retryOne(ctx, id)
+		if err != nil {
+			return indexed, err
+		}
+		if ok {
+			indexed++
+		}
+	}
+	return indexed, nil
+}
+
+// retryOne re-upserts one entry; on failure it is rescheduled with exponential backoff
+func (q *IndexQueue) retryOne(ctx context.Context, id string) (bool, error) {
+	raw, err := q.redis.HGet(ctx, indexPendingEntries, id).Result()
+	if err == redis.Nil {
+		// Orphaned schedule entry
+		q.redis.ZRem(ctx, indexPendingSchedule, id)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load pending-index entry: %w", err)
+	}
+
+	var entry IndexPendingEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil || len(entry.Embedding) == 0 {
+		q.logger.Error("Dropping unreadable pending-index entry", zap.String("id", id), zap.Error(err))
+		q.remove(ctx, id)
+		return false, nil
+	}
+
+	if storeErr := q.index.Store(ctx, entry.Namespace, entry.UID, entry.Embedding, entry.Metadata); storeErr != nil {
+		delay := indexPendingBaseDelay << uint(entry.Attempts)
+		if delay <= 0 || delay > indexPendingMaxDelay {
+			delay = indexPendingMaxDelay
+		}
+		entry.Attempts++
+		entry.LastError = storeErr.Error()
+		q.logger.Warn("Reindex failed",
+			zap.String("id", id),
+			zap.Int("attempts", entry.Attempts),
+			zap.Duration("next_retry_in", delay),
+			zap.Error(storeErr))
+		return false, q.save(ctx, &entry, time.Now().Add(delay))
+	}
+
+	q.remove(ctx, id)
+	q.logger.Info("Pending node indexed",
+		zap.String("namespace", entry.Namespace),
+		zap.String("uid", entry.UID),
+		zap.Int("attempts", entry.Attempts+1))
+	return true, nil
+}
+
+// save writes the entry and schedules its next attempt
+func (q *IndexQueue) save(ctx context.Context, entry *IndexPendingEntry, next time.Time) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending-index entry: %w", err)
+	}
+
+	pipe := q.redis.TxPipeline()
+	pipe.HSet(ctx, indexPendingEntries, entry.id(), data)
+	pipe.ZAdd(ctx, indexPendingSchedule, redis.Z{Score: float64(next.Unix()), Member: entry.id()})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to persist pending-index entry: %w", err)
+	}
+	return nil
+}
+
+// remove deletes an entry and its schedule
+func (q *IndexQueue) remove(ctx context.Context, id string) {
+	pipe := q.redis.TxPipeline()
+	pipe.ZRem(ctx, indexPendingSchedule, id)
+	pipe.HDel(ctx, indexPendingEntries, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		q.logger.Warn("Failed to remove pending-index entry", zap.String("id", id), zap.Error(err))
+	}
+}
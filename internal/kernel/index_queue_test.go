@@ -0,0 +1,105 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+// flakyIndex fails every Store while down and records the ones that succeed
+type flakyIndex struct {
+	mu     sync.Mutex
+	down   bool
+	stored map[string]int // namespace:uid -> successful stores
+}
+
+func (f *flakyIndex) Store(ctx context.Context, namespace, uid string, embedding []float32, metadata map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("qdrant unavailable")
+	}
+	if f.stored == nil {
+		f.stored = make(map[string]int)
+	}
+	f.stored[namespace+":"+uid]++
+	return nil
+}
+
+func TestIndexQueueRetriesFailedStores(t *testing.T) {
+	ctx := context.Background()
+	index := &flakyIndex{down: true}
+	q := NewIndexQueue(fakeredis.New(t), index, zaptest.NewLogger(t))
+
+	for _, uid := range []string{"0x1", "0x2"} {
+		if err := q.Store(ctx, "user_alice", uid, []float32{0.1, 0.2}, nil); err != nil {
+			t.Fatalf("Store(%s) = %v, want the failure queued", uid, err)
+		}
+	}
+	// A node failing again replaces its entry
+	q.Store(ctx, "user_alice", "0x1", []float32{0.3, 0.4}, nil)
+	if n, err := q.Pending(ctx); err != nil || n != 2 {
+		t.Fatalf("Pending = %d, %v; want 2", n, err)
+	}
+
+	if n, err := q.ReindexAll(ctx); err != nil || n != 0 {
+		t.Errorf("ReindexAll while down = %d, %v; want 0 indexed", n, err)
+	}
+	if n, _ := q.Pending(ctx); n != 2 {
+		t.Errorf("Pending after failed retry = %d, want both kept", n)
+	}
+
+	index.down = false
+	if n, err := q.ReindexAll(ctx); err != nil || n != 2 {
+		t.Errorf("ReindexAll = %d, %v; want 2 indexed", n, err)
+	}
+	if n, _ := q.Pending(ctx); n != 0 {
+		t.Errorf("Pending after reindex = %d, want 0", n)
+	}
+	if index.stored["user_alice:0x1"] != 1 || index.stored["user_alice:0x2"] != 1 {
+		t.Errorf("stored %v, want each node once", index.stored)
+	}
+}
+
+func TestIndexQueueDrop(t *testing.T) {
+	ctx := context.Background()
+	index := &flakyIndex{down: true}
+	q := NewIndexQueue(fakeredis.New(t), index, zaptest.NewLogger(t))
+	q.Store(ctx, "user_alice", "0x1", []float32{0.1}, nil)
+	q.Store(ctx, "user_bob", "0x1", []float32{0.1}, nil)
+
+	if err := q.Drop(ctx, "user_alice", "0x1"); err != nil {
+		t.Fatalf("Drop: %v", err)
+	}
+	// Dropping a node with no entry is not an error
+	if err := q.Drop(ctx, "user_alice", "0x9"); err != nil {
+		t.Fatalf("Drop of an unqueued node: %v", err)
+	}
+
+	index.down = false
+	if n, err := q.ReindexAll(ctx); err != nil || n != 1 {
+		t.Errorf("ReindexAll = %d, %v; want only the other namespace's entry", n, err)
+	}
+	if index.stored["user_alice:0x1"] != 0 {
+		t.Error("dropped node was indexed again")
+	}
+}
+
+func TestPurgeForgottenDropsPendingIndex(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	index := &flakyIndex{down: true}
+	k := &Kernel{logger: logger, indexQueue: NewIndexQueue(fakeredis.New(t), index, logger)}
+	k.indexQueue.Store(ctx, "user_alice", "0x1", []float32{0.1}, nil)
+
+	k.purgeForgotten(ctx, "user_alice", &graph.Node{UID: "0x1", Namespace: "user_alice"})
+	if n, _ := k.indexQueue.Pending(ctx); n != 0 {
+		t.Errorf("Pending = %d after forgetting the node, want 0", n)
+	}
+}
@@ -21,7 +21,10 @@ import (
 	"github.com/reflective-memory-kernel/internal/ai/local"
 	"github.com/reflective-memory-kernel/internal/graph"
 	"github.com/reflective-memory-kernel/internal/jsonx"
+	"github.com/reflective-memory-kernel/internal/kernel/events"
 	"github.com/reflective-memory-kernel/internal/kernel/wisdom"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
+	"github.com/reflective-memory-kernel/internal/reflection"
 )
 
 // IngestionStats holds metrics about ingestion performance
@@ -183,6 +186,7 @@ type IngestionPipeline struct {
 	localEmbedder local.LocalEmbedder
 	wisdomManager *wisdom.WisdomManager
 	vectorIndex   *VectorIndex
+	edgeInferrer  *graph.EdgeTypeInferrer
 
 	batchSize     int
 	flushInterval time.Duration
@@ -198,6 +202,47 @@ type IngestionPipeline struct {
 
 	// Circuit breaker for AI service calls
 	aiCircuitBreaker *CircuitBreaker
+
+	// Memory event publisher (nil = disabled)
+	events *events.Publisher
+
+	// Consultation cache invalidated when a namespace changes (nil = disabled)
+	consultationCache *ConsultationCache
+
+	// Per-namespace activation parameters for boosting re-mentioned nodes
+	activationConfigs *reflection.ActivationConfigStore
+
+	// Queues vector upserts that fail for reindexing (nil = store directly)
+	indexQueue *IndexQueue
+}
+
+// SetEventPublisher configures memory event publishing for persisted nodes and edges
+func (p *IngestionPipeline) SetEventPublisher(publisher *events.Publisher) {
+	p.events = publisher
+}
+
+// SetConsultationCache configures the consultation cache to invalidate on ingestion
+func (p *IngestionPipeline) SetConsultationCache(cache *ConsultationCache) {
+	p.consultationCache = cache
+}
+
+// SetActivationConfigs configures the per-namespace activation parameters
+func (p *IngestionPipeline) SetActivationConfigs(configs *reflection.ActivationConfigStore) {
+	p.activationConfigs = configs
+}
+
+// SetIndexQueue configures the queue that retries failed vector upserts
+func (p *IngestionPipeline) SetIndexQueue(queue *IndexQueue) {
+	p.indexQueue = queue
+}
+
+// storeVector indexes an embedding, through the index queue when one is set
+// so a Qdrant outage delays searchability instead of losing the vector
+func (p *IngestionPipeline) storeVector(ctx context.Context, namespace, uid string, vec []float32, metadata map[string]interface{}) error {
+	if p.indexQueue != nil {
+		return p.indexQueue.Store(ctx, namespace, uid, vec, metadata)
+	}
+	return p.vectorIndex.Store(ctx, namespace, uid, vec, metadata)
 }
 
 // GetStats returns current ingestion statistics
@@ -237,6 +282,7 @@ func NewIngestionPipeline(
 		localEmbedder:    localEmbedder,
 		wisdomManager:    wisdomManager,
 		vectorIndex:      vectorIndex,
+		edgeInferrer:     graph.NewEdgeTypeInferrer(localEmbedder),
 		batchSize:        batchSize,
 		flushInterval:    flushInterval,
 		logger:           logger,
@@ -284,9 +330,9 @@ func (p *IngestionPipeline) Ingest(ctx context.Context, event *graph.TranscriptE
 	// PERMISSION CHECK: For group namespaces, verify user is a member (write access)
 	namespace := event.Namespace
 	if namespace == "" {
-		namespace = fmt.Sprintf("user_%s", event.UserID)
+		namespace = nsutil.ForUser(event.UserID)
 	}
-	if strings.HasPrefix(namespace, "group_") {
+	if nsutil.IsGroup(namespace) {
 		isMember, err := p.graphClient.IsWorkspaceMember(ctx, namespace, event.UserID)
 		if err != nil {
 			p.logger.Error("Failed to check workspace membership for write", zap.Error(err))
@@ -357,7 +403,7 @@ func (p *IngestionPipeline) Ingest(ctx context.Context, event *graph.TranscriptE
 					"timestamp":       event.Timestamp.Format(time.RFC3339),
 				}
 
-				if err := p.vectorIndex.Store(ctx, namespace, chatNodeUID, vec, metadata); err != nil {
+				if err := p.storeVector(ctx, namespace, chatNodeUID, vec, metadata); err != nil {
 					p.logger.Warn("Failed to store embedding in vector index", zap.Error(err))
 				} else {
 					p.logger.Debug("Stored chat embedding in Qdrant with unified UID", zap.String("uid", chatNodeUID))
@@ -372,7 +418,7 @@ func (p *IngestionPipeline) Ingest(ctx context.Context, event *graph.TranscriptE
 					"type":            "chat",
 					"timestamp":       event.Timestamp.Format(time.RFC3339),
 				}
-				if err := p.vectorIndex.Store(ctx, namespace, uid, vec, metadata); err != nil {
+				if err := p.storeVector(ctx, namespace, uid, vec, metadata); err != nil {
 					p.logger.Warn("Failed to store embedding in vector index (fallback)", zap.Error(err))
 				}
 			}
@@ -518,6 +564,17 @@ func (p *IngestionPipeline) extractEntities(ctx context.Context, event *graph.Tr
 	return entities, nil
 }
 
+// factStatus derives the initial status of a time-scoped fact; "" for open-ended facts
+func factStatus(validFrom, validUntil *time.Time) string {
+	if validUntil != nil && !time.Now().Before(*validUntil) {
+		return graph.FactStatusHistorical
+	}
+	if validFrom != nil || validUntil != nil {
+		return graph.FactStatusCurrent
+	}
+	return ""
+}
+
 // isValidEntityName filters out UUIDs and metadata nodes
 func isValidEntityName(name string) bool {
 	if len(name) == 0 || len(name) < 2 {
@@ -538,38 +595,39 @@ func isValidEntityName(name string) bool {
 	return true
 }
 
-// resolveEntityWithAI uses an LLM to decide if a new entity is semantically the same as existing candidates
-func (p *IngestionPipeline) resolveEntityWithAI(ctx context.Context, newEntity string, candidates []string) (string, error) {
-	type ResolutionRequest struct {
+// resolveEntitiesWithAI resolves many entities against their own candidates in one
+// AI service round-trip. Returns entity name -> matched candidate (missing = no match).
+func (p *IngestionPipeline) resolveEntitiesWithAI(ctx context.Context, candidatesByEntity map[string][]string) (map[string]string, error) {
+	type ResolutionItem struct {
 		Entity     string   `json:"entity"`
 		Candidates []string `json:"candidates"`
 	}
 
 	var result struct {
-		Match string `json:"match"` // The matching candidate or empty string
+		Matches map[string]string `json:"matches"`
 	}
 
 	// Wrap AI service call with circuit breaker
 	err := p.aiCircuitBreaker.Execute(func() error {
-		reqBody := ResolutionRequest{
-			Entity:     newEntity,
-			Candidates: candidates,
+		items := make([]ResolutionItem, 0, len(candidatesByEntity))
+		for entity, candidates := range candidatesByEntity {
+			items = append(items, ResolutionItem{Entity: entity, Candidates: candidates})
 		}
 
-		jsonData, err := jsonx.Marshal(reqBody)
+		jsonData, err := jsonx.Marshal(map[string]interface{}{"items": items})
 		if err != nil {
 			return err
 		}
 
 		req, err := http.NewRequestWithContext(ctx, "POST",
-			p.aiServicesURL+"/resolve-entity",
+			p.aiServicesURL+"/resolve-entity-batch",
 			bytes.NewBuffer(jsonData))
 		if err != nil {
 			return err
 		}
 		req.Header.Set("Content-Type", "application/json")
 
-		client := &http.Client{Timeout: 30 * time.Second}
+		client := &http.Client{Timeout: 60 * time.Second}
 		resp, err := client.Do(req)
 		if err != nil {
 			return err
@@ -577,131 +635,131 @@ func (p *IngestionPipeline) resolveEntityWithAI(ctx context.Context, newEntity s
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			// If service not implemented or error, fallback to no match
-			return fmt.Errorf("resolution service returned status %d", resp.StatusCode)
+			return fmt.Errorf("batch resolution service returned status %d", resp.StatusCode)
 		}
 
-		if err := jsonx.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return err
-		}
-
-		return nil
+		return jsonx.NewDecoder(resp.Body).Decode(&result)
 	})
 
 	if err != nil {
-		// Circuit breaker might be open or service failed
-		// Return empty match to fallback to creating new entity
-		p.logger.Debug("AI entity resolution failed or circuit breaker open, falling back to new entity",
+		// Fallback: create new entities
+		p.logger.Debug("AI batch entity resolution failed or circuit breaker open, falling back to new entities",
 			zap.Error(err),
-			zap.String("circuit_state", func() string {
-				state := p.aiCircuitBreaker.GetState()
-				switch state {
-				case CircuitOpen:
-					return "OPEN"
-				case CircuitHalfOpen:
-					return "HALF_OPEN"
-				default:
-					return "CLOSED"
-				}
-			}()))
-		return "", nil // Fallback: create new entity
+			zap.Int("entities", len(candidatesByEntity)))
+		return nil, nil
 	}
 
-	return result.Match, nil
+	return result.Matches, nil
 }
 
-// findSemanticMatch attempts to find an existing node UID that semantically matches the given name
-// Uses distributed locking to prevent race conditions during concurrent deduplication
-func (p *IngestionPipeline) findSemanticMatch(ctx context.Context, namespace, name string) (string, error) {
-	if p.localEmbedder == nil || p.vectorIndex == nil {
-		return "", nil // Feature disabled
-	}
-
-	// CRITICAL: Acquire distributed lock to prevent concurrent deduplication of the same entity
-	// SECURITY: Fail closed when lock system unavailable to prevent race conditions
-	// SECURITY: Adaptive lock with 30s timeout instead of 10s for resilience
-	lockKey := fmt.Sprintf("lock:dedup:%s:%s", namespace, name)
-	lockAcquired, err := p.redisClient.SetNX(ctx, lockKey, "1", 30*time.Second).Result()
-	if err != nil {
-		p.logger.Error("Failed to acquire deduplication lock - aborting for safety",
-			zap.Error(err),
-			zap.String("name", name))
-		return "", fmt.Errorf("deduplication lock unavailable: %w", err)
-	}
-	if !lockAcquired {
-		p.logger.Debug("Deduplication lock busy, returning empty",
-			zap.String("name", name))
-		return "", fmt.Errorf("deduplication in progress for: %s", name)
-	}
-
-	// Ensure lock is released
-	defer func() {
-		if delCmd := p.redisClient.Del(ctx, lockKey); delCmd.Err() != nil {
-			p.logger.Warn("Failed to release deduplication lock",
-				zap.Error(delCmd.Err()),
-				zap.String("lock_key", lockKey))
-		}
-	}()
-
+// semanticCandidates returns vector-search candidates (name -> UID) for a new entity name
+func (p *IngestionPipeline) semanticCandidates(ctx context.Context, namespace, name string) (map[string]string, error) {
 	// 1. Embed the name
 	vec, err := p.localEmbedder.Embed(name)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 2. Search Vector Index for Entity candidates (threshold 0.85)
-	// We only look for "Entity" type nodes to merge with
 	// BACKGROUND OPERATION: Empty userID since this is not a user-initiated search
 	uids, scores, payloads, err := p.vectorIndex.Search(ctx, namespace, "", vec, 5)
 	if err != nil {
-		return "", err
-	}
-
-	if len(uids) == 0 {
-		return "", nil
+		return nil, err
 	}
 
 	// 3. Filter candidates
-	var candidates []string
-	uidToName := make(map[string]string)
-
+	uidByName := make(map[string]string)
 	for i, uid := range uids {
 		if scores[i] < 0.85 {
 			continue // Skip weak matches
 		}
-
-		// Get name from payload if available, or we might need to query DGraph
-		// Assuming payload has "text" or we assume the search result is an entity
 		if text, ok := payloads[i]["text"].(string); ok {
-			candidates = append(candidates, text)
-			uidToName[text] = uid
+			uidByName[text] = uid
+		}
+	}
+	return uidByName, nil
+}
+
+// findSemanticMatches finds existing node UIDs that semantically match the given new names.
+// Candidates are gathered per name via vector search, then judged by the LLM in a single
+// batch call. Uses distributed locking to prevent race conditions during concurrent
+// deduplication; names whose lock is busy are skipped and created as new entities.
+func (p *IngestionPipeline) findSemanticMatches(ctx context.Context, namespace string, names []string) map[string]string {
+	matched := make(map[string]string)
+	if p.localEmbedder == nil || p.vectorIndex == nil || len(names) == 0 {
+		return matched // Feature disabled
+	}
+
+	candidatesByEntity := make(map[string][]string)
+	uidsByEntity := make(map[string]map[string]string)
+
+	for _, name := range names {
+		// CRITICAL: Acquire distributed lock to prevent concurrent deduplication of the same entity
+		// SECURITY: Fail closed when lock system unavailable to prevent race conditions
+		lockKey := fmt.Sprintf("lock:dedup:%s:%s", namespace, name)
+		lockAcquired, err := p.redisClient.SetNX(ctx, lockKey, "1", 30*time.Second).Result()
+		if err != nil {
+			p.logger.Error("Failed to acquire deduplication lock - skipping semantic dedup for safety",
+				zap.Error(err),
+				zap.String("name", name))
+			continue
+		}
+		if !lockAcquired {
+			p.logger.Debug("Deduplication lock busy, skipping", zap.String("name", name))
+			continue
 		}
+
+		// Ensure lock is released once the batch has been judged
+		defer func() {
+			if delCmd := p.redisClient.Del(ctx, lockKey); delCmd.Err() != nil {
+				p.logger.Warn("Failed to release deduplication lock",
+					zap.Error(delCmd.Err()),
+					zap.String("lock_key", lockKey))
+			}
+		}()
+
+		uidByName, err := p.semanticCandidates(ctx, namespace, name)
+		if err != nil {
+			p.logger.Debug("Semantic candidate search failed", zap.String("name", name), zap.Error(err))
+			continue
+		}
+		if len(uidByName) == 0 {
+			continue
+		}
+
+		candidates := make([]string, 0, len(uidByName))
+		for candidate := range uidByName {
+			candidates = append(candidates, candidate)
+		}
+		candidatesByEntity[name] = candidates
+		uidsByEntity[name] = uidByName
 	}
 
-	if len(candidates) == 0 {
-		return "", nil
+	if len(candidatesByEntity) == 0 {
+		return matched
 	}
 
-	// 4. Use LLM to Judge ("The Judge")
-	// If the vector match is extremely high (>0.95), we might skip LLM for speed?
-	// For now, let's enable LLM check for accuracy.
-	matchName, err := p.resolveEntityWithAI(ctx, name, candidates)
+	// 4. Use LLM to Judge ("The Judge") - one round-trip for the whole batch
+	matches, err := p.resolveEntitiesWithAI(ctx, candidatesByEntity)
 	if err != nil {
 		p.logger.Warn("Semantic resolution failed", zap.Error(err))
-		return "", nil
+		return matched
 	}
 
-	if matchName != "" {
-		if uid, ok := uidToName[matchName]; ok {
+	for name, matchName := range matches {
+		if matchName == "" {
+			continue
+		}
+		if uid, ok := uidsByEntity[name][matchName]; ok {
 			p.logger.Info("Semantic Deduplication: Merged entity",
 				zap.String("new", name),
 				zap.String("existing", matchName),
 				zap.String("uid", uid))
-			return uid, nil
+			matched[name] = uid
 		}
 	}
 
-	return "", nil
+	return matched
 }
 
 // basicEntityExtraction provides fallback entity extraction without AI
@@ -741,6 +799,9 @@ func (p *IngestionPipeline) processBatchedEntities(ctx context.Context, namespac
 		for _, r := range e.Relations {
 			uniqueNames[r.TargetName] = true
 		}
+		for _, src := range e.Sources {
+			uniqueNames[src] = true
+		}
 	}
 
 	namesList := make([]string, 0, len(uniqueNames))
@@ -771,6 +832,53 @@ func (p *IngestionPipeline) processBatchedEntities(ctx context.Context, namespac
 		})
 	}
 
+	// Source documents (batch ingestion) get one node each, shared by all their entities
+	seenSources := make(map[string]bool)
+	for _, e := range entities {
+		for _, src := range e.Sources {
+			if _, exists := existingNodes[src]; exists || seenSources[src] {
+				continue
+			}
+			seenSources[src] = true
+			nodesToCreate = append(nodesToCreate, &graph.Node{
+				DType:      []string{string(graph.NodeTypeDocument)},
+				Name:       src,
+				Activation: 0.3,
+				Confidence: 1.0,
+				Namespace:  namesp,
+			})
+		}
+	}
+
+	// Phase 1 Optimization: Semantic Deduplication (The "Judge")
+	// Names not found by exact string match are resolved against vector-search candidates
+	// in a single batched LLM call. This prevents creating duplicate nodes for
+	// "Pizza" vs "pizza" vs "Italian Pie" without one round-trip per entity.
+	missingNames := make([]string, 0)
+	seenMissing := make(map[string]bool)
+	for _, e := range entities {
+		if !isValidEntityName(e.Name) {
+			continue
+		}
+		candidates := []string{e.Name}
+		for _, r := range e.Relations {
+			candidates = append(candidates, r.TargetName)
+		}
+		for _, name := range candidates {
+			if _, exists := existingNodes[name]; !exists && !seenMissing[name] {
+				seenMissing[name] = true
+				missingNames = append(missingNames, name)
+			}
+		}
+	}
+	for name, uid := range p.findSemanticMatches(ctx, namesp, missingNames) {
+		// Found a semantic match! Map this name to the existing UID.
+		existingNodes[name] = &graph.Node{UID: uid, Name: name}
+		p.logger.Info("Semantic Dedup: Merged entity",
+			zap.String("new_name", name),
+			zap.String("merged_uid", uid))
+	}
+
 	// Check Entities and Relations
 	for _, e := range entities {
 		// Filter out junk/metadata nodes
@@ -779,19 +887,6 @@ func (p *IngestionPipeline) processBatchedEntities(ctx context.Context, namespac
 			continue
 		}
 
-		if _, exists := existingNodes[e.Name]; !exists {
-			// Phase 1 Optimization: Semantic Deduplication (The "Judge")
-			// If not found by exact string match, try to find a semantic match using Vector Search + LLM
-			if uid, err := p.findSemanticMatch(ctx, namesp, e.Name); err == nil && uid != "" {
-				// Found a semantic match! Map this name to the existing UID.
-				// This prevents creating a duplicate node for "Pizza" vs "pizza" vs "Italian Pie"
-				existingNodes[e.Name] = &graph.Node{UID: uid, Name: e.Name}
-				p.logger.Info("Semantic Dedup: Merged source entity",
-					zap.String("new_name", e.Name),
-					zap.String("merged_uid", uid))
-			}
-		}
-
 		if _, exists := existingNodes[e.Name]; !exists {
 			// Normalize type
 			dtype := e.Type
@@ -810,20 +905,13 @@ func (p *IngestionPipeline) processBatchedEntities(ctx context.Context, namespac
 				Activation:           0.5, // Start at neutral activation
 				Confidence:           0.8,
 				Namespace:            namesp,
+				ValidFrom:            e.ValidFrom,
+				ValidUntil:           e.ValidUntil,
+				Status:               factStatus(e.ValidFrom, e.ValidUntil),
 			})
 		}
 
 		for _, r := range e.Relations {
-			if _, exists := existingNodes[r.TargetName]; !exists {
-				// Semantic Dedup for Target
-				if uid, err := p.findSemanticMatch(ctx, namesp, r.TargetName); err == nil && uid != "" {
-					existingNodes[r.TargetName] = &graph.Node{UID: uid, Name: r.TargetName}
-					p.logger.Info("Semantic Dedup: Merged target entity",
-						zap.String("new_name", r.TargetName),
-						zap.String("merged_uid", uid))
-				}
-			}
-
 			if _, exists := existingNodes[r.TargetName]; !exists {
 				// Normalize target type
 				dtype := r.TargetType
@@ -842,116 +930,144 @@ func (p *IngestionPipeline) processBatchedEntities(ctx context.Context, namespac
 		}
 	}
 
-	// Execute Batch Create
-	if len(nodesToCreate) > 0 {
-		p.logger.Info("Batch creating nodes", zap.Int("count", len(nodesToCreate)))
-		newUIDs, err := p.graphClient.CreateNodes(ctx, nodesToCreate)
-		if err != nil {
-			return err
+	// 4. BUILD EDGES - endpoints are existing UIDs or names of nodes created in this batch
+	newNames := make(map[string]bool, len(nodesToCreate))
+	for _, node := range nodesToCreate {
+		newNames[node.Name] = true
+	}
+	// ref resolves a node name to an existing UID or a same-batch name
+	ref := func(name string) (uid, batchName string, ok bool) {
+		if node, exists := existingNodes[name]; exists && node != nil {
+			return node.UID, "", true
 		}
-
-		// Merge new UIDs into existingNodes map so we can build edges
-		for name, uid := range newUIDs {
-			// We only need the UID for edge creation
-			existingNodes[name] = &graph.Node{UID: uid, Name: name}
+		if newNames[name] {
+			return "", name, true
 		}
+		return "", "", false
 	}
 
-	// 4. BULK CREATE EDGES
-	edgesToCreate := make([]graph.EdgeInput, 0)
+	edgesToCreate := make([]graph.IngestEdge, 0)
+	addEdge := func(from, to string, edgeType graph.EdgeType, weight float64) *graph.IngestEdge {
+		fromUID, fromName, ok := ref(from)
+		if !ok {
+			return nil
+		}
+		toUID, toName, ok := ref(to)
+		if !ok {
+			return nil
+		}
+		edgesToCreate = append(edgesToCreate, graph.IngestEdge{
+			FromUID:  fromUID,
+			FromName: fromName,
+			ToUID:    toUID,
+			ToName:   toName,
+			Type:     edgeType,
+			Weight:   weight,
+		})
+		return &edgesToCreate[len(edgesToCreate)-1]
+	}
 
-	// Safe access to userUID - check if it exists in map first
-	userNode, userOk := existingNodes[userID]
-	if !userOk || userNode == nil {
+	if _, _, userOk := ref(userID); !userOk {
 		// User node should have been created, but if not, skip edge creation
-		p.logger.Warn("User node not found in existingNodes, skipping edge creation",
+		p.logger.Warn("User node not found, skipping edge creation",
 			zap.String("userID", userID))
-		return nil
-	}
-	userUID := userNode.UID
-
-	// Safe access to convUID - conversation node may not exist
-	var convUID string
-	if convNode, ok := existingNodes[conversationID]; ok && convNode != nil {
-		convUID = convNode.UID
-	}
-
-	for _, e := range entities {
-		entityUID, ok := existingNodes[e.Name]
-		if !ok || entityUID == nil {
-			continue
-		} // Should not happen
-
-		// User -> Entity (KNOWS) - Relation
-		edgesToCreate = append(edgesToCreate, graph.EdgeInput{
-			FromUID: userUID,
-			ToUID:   entityUID.UID,
-			Type:    graph.EdgeTypeKnows,
-			Status:  graph.EdgeStatusCurrent,
-			Weight:  0.3,
-		})
+	} else {
+		_, _, hasConv := ref(conversationID)
 
-		// Entity -> User (CREATED_BY) - Ownership
-		edgesToCreate = append(edgesToCreate, graph.EdgeInput{
-			FromUID: entityUID.UID,
-			ToUID:   userUID,
-			Type:    "created_by",
-			Status:  graph.EdgeStatusCurrent,
-			Weight:  0.2, // Metadata link, low weight
-		})
+		for _, e := range entities {
+			if _, _, ok := ref(e.Name); !ok {
+				continue
+			}
 
-		// Entity -> Conversation (DERIVED_FROM) - Origin
-		if convUID != "" {
-			edgesToCreate = append(edgesToCreate, graph.EdgeInput{
-				FromUID: entityUID.UID,
-				ToUID:   convUID,
-				Type:    "derived_from",
-				Status:  graph.EdgeStatusCurrent,
-				Weight:  0.1, // Provenance link, very low weight
-			})
-		}
+			// User -> Entity (KNOWS) - Relation; ~knows leads back to the owner
+			addEdge(userID, e.Name, graph.EdgeTypeKnows, 0.3)
 
-		// Entity -> Target (Relations)
-		for _, r := range e.Relations {
-			targetUID, ok := existingNodes[r.TargetName]
-			if !ok {
-				continue
+			// Entity -> Conversation (DERIVED_FROM) - Origin, provenance link with very low weight
+			if hasConv {
+				addEdge(e.Name, conversationID, graph.EdgeTypeDerivedFrom, 0.1)
 			}
 
-			// Determine weight based on relationship type
-			weight := 0.5
-			switch r.Type {
-			case graph.EdgeTypePartnerIs, graph.EdgeTypeFamilyMember:
-				weight = 0.95
-			case graph.EdgeTypeFriendOf, graph.EdgeTypeHasManager, graph.EdgeTypeWorksOn:
-				weight = 0.8
-			case graph.EdgeTypeLikes, graph.EdgeTypeDislikes, graph.EdgeTypeIsAllergic:
-				weight = 0.7
-			case graph.EdgeTypeKnows:
-				weight = 0.3
-			default:
-				weight = 0.5
+			// Entity -> Document (DERIVED_FROM) - one edge per source document
+			for _, src := range e.Sources {
+				addEdge(e.Name, src, graph.EdgeTypeDerivedFrom, 0.1)
 			}
 
-			edgesToCreate = append(edgesToCreate, graph.EdgeInput{
-				FromUID: entityUID.UID,
-				ToUID:   targetUID.UID,
-				Type:    r.Type,
-				Status:  graph.EdgeStatusCurrent,
-				Weight:  weight,
-			})
+			// Entity -> Target (Relations)
+			for _, r := range e.Relations {
+				// Extractors don't always stick to the vocabulary
+				match := p.edgeInferrer.Infer(string(r.Type))
+				if match.NeedsReview() {
+					p.logger.Info("Low-confidence relation type mapping",
+						zap.String("entity", e.Name),
+						zap.String("target", r.TargetName),
+						zap.String("type", string(r.Type)),
+						zap.String("mapped_to", string(match.Type)),
+						zap.Float64("confidence", match.Confidence))
+				}
+				edgeType := match.Type
+				from, to := e.Name, r.TargetName
+				if match.Reversed {
+					from, to = to, from
+				}
+
+				// Determine weight based on relationship type
+				weight := 0.5
+				switch edgeType {
+				case graph.EdgeTypePartnerIs, graph.EdgeTypeFamilyMember:
+					weight = 0.95
+				case graph.EdgeTypeFriendOf, graph.EdgeTypeHasManager, graph.EdgeTypeWorksOn:
+					weight = 0.8
+				case graph.EdgeTypeLikes, graph.EdgeTypeDislikes, graph.EdgeTypeIsAllergic:
+					weight = 0.7
+				case graph.EdgeTypeKnows:
+					weight = 0.3
+				default:
+					weight = 0.5
+				}
+				// An uncertain mapping is written marked for review
+				if edge := addEdge(from, to, edgeType, weight); edge != nil {
+					edge.NeedsReview = match.NeedsReview()
+				}
+			}
 		}
 	}
 
-	// Execute Batch edges
-	if len(edgesToCreate) > 0 {
-		p.logger.Info("Batch creating edges", zap.Int("count", len(edgesToCreate)))
-		if err := p.graphClient.CreateEdges(ctx, edgesToCreate); err != nil {
+	// 5. INGEST - nodes and edges in one atomic transaction
+	if len(nodesToCreate) > 0 || len(edgesToCreate) > 0 {
+		p.logger.Info("Ingesting graph batch",
+			zap.Int("nodes", len(nodesToCreate)),
+			zap.Int("edges", len(edgesToCreate)))
+		newUIDs, err := p.graphClient.IngestGraph(ctx, nodesToCreate, edgesToCreate)
+		if err != nil {
 			return err
 		}
+
+		// Merge new UIDs into existingNodes map for the async updates below
+		for name, uid := range newUIDs {
+			existingNodes[name] = &graph.Node{UID: uid, Name: name}
+		}
+
+		for _, node := range nodesToCreate {
+			if uid, ok := newUIDs[node.Name]; ok {
+				p.events.NodeCreated(namesp, uid, node.Name, string(node.GetType()))
+			}
+		}
+		for _, edge := range edgesToCreate {
+			fromUID, toUID := edge.FromUID, edge.ToUID
+			if fromUID == "" {
+				fromUID = newUIDs[edge.FromName]
+			}
+			if toUID == "" {
+				toUID = newUIDs[edge.ToName]
+			}
+			p.events.EdgeCreated(namesp, fromUID, toUID, string(edge.Type))
+		}
 	}
 
-	// 5. ASYNC UPDATES (Fire and forget)
+	// Cached consultations for this namespace no longer reflect the graph
+	p.consultationCache.Invalidate(ctx, namesp)
+
+	// 6. ASYNC UPDATES (Fire and forget)
 	// Update activation/tags for existing nodes that we found in step 2
 	go func() {
 		defer func() {
@@ -962,17 +1078,22 @@ func (p *IngestionPipeline) processBatchedEntities(ctx context.Context, namespac
 		// Create a separate context for async ops
 		asyncCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
+		activationCfg := p.activationConfigs.Get(asyncCtx, namesp)
 
+		var mentioned []string
 		for _, e := range entities {
 			if node, exists := existingNodes[e.Name]; exists {
-				// Boost activation
-				p.graphClient.IncrementAccessCount(asyncCtx, node.UID, graph.DefaultActivationConfig())
+				mentioned = append(mentioned, node.UID)
 				// Add tags
 				if len(e.Tags) > 0 {
 					p.graphClient.AddTags(asyncCtx, node.UID, e.Tags)
 				}
 			}
 		}
+		// Boost activation of every re-mentioned node in one read and one write
+		if err := p.graphClient.IncrementAccessCounts(asyncCtx, mentioned, activationCfg); err != nil {
+			p.logger.Debug("Failed to boost re-mentioned nodes", zap.Int("nodes", len(mentioned)), zap.Error(err))
+		}
 	}()
 
 	return nil
@@ -1000,16 +1121,18 @@ func (p *IngestionPipeline) PersistChunks(ctx context.Context, namespace, docID
 			"chunk_index": chunk.ChunkIndex,
 			"source_id":   docID,
 			"type":        "chunk",
+			"created_at":  time.Now().UTC().Format(time.RFC3339),
 		}
 
-		// Store in Qdrant (using vectorIndex.Store)
-		if err := p.vectorIndex.Store(ctx, namespace, uid, chunk.Embedding, metadata); err != nil {
+		// Store in Qdrant; a failed upsert is queued for reindexing
+		if err := p.storeVector(ctx, namespace, uid, chunk.Embedding, metadata); err != nil {
 			// Log error but continue with other chunks
 			p.logger.Error("Failed to persist chunk",
 				zap.String("uid", uid),
 				zap.Error(err))
 		}
 	}
+	p.consultationCache.Invalidate(ctx, namespace)
 	return nil
 }
 
@@ -1024,7 +1147,7 @@ func (p *IngestionPipeline) cacheRecentContext(ctx context.Context, event *graph
 	// Use Namespace for context key if available, else user ID
 	ns := event.Namespace
 	if ns == "" {
-		ns = fmt.Sprintf("user_%s", event.UserID)
+		ns = nsutil.ForUser(event.UserID)
 	}
 	key := fmt.Sprintf("context:%s:recent", ns)
 
@@ -22,6 +22,9 @@ type WorkflowConfig struct {
 	EventKey      string
 	AppID         string
 	Logger        *zap.Logger
+
+	// QdrantURL locates the vector index so pruned nodes lose their vectors too
+	QdrantURL string
 }
 
 // IngestionInput represents the input for the ingestion workflow
@@ -383,6 +386,49 @@ func NewCronWorkflow(cfg WorkflowConfig, graphClient *graph.Client) (inngestgo.F
 		inngestgo.CronTrigger("0 * * * *") // Every hour
 }
 
+// PruneInput represents input for the prune workflow; nil Opts uses DefaultPruneOpts
+type PruneInput struct {
+	Namespace string     `json:"namespace"`
+	Opts      *PruneOpts `json:"opts,omitempty"`
+}
+
+// pruneWorkflow retires low-value nodes from one namespace as a durable step
+func pruneWorkflow(
+	cfg WorkflowConfig,
+	graphClient *graph.Client,
+	vectorIndex *VectorIndex,
+) func(ctx context.Context, input inngestgo.Input[PruneInput]) (any, error) {
+	return func(ctx context.Context, input inngestgo.Input[PruneInput]) (any, error) {
+		logger := cfg.Logger.With(zap.String("namespace", input.Event.Data.Namespace))
+
+		opts := DefaultPruneOpts()
+		if input.Event.Data.Opts != nil {
+			opts = *input.Event.Data.Opts
+		}
+
+		result, err := step.Run(ctx, "prune-nodes", func(ctx context.Context) (*PruneResult, error) {
+			logger.Info("Pruning low-value nodes",
+				zap.Float64("min_activation", opts.MinActivation),
+				zap.Int("min_age_days", opts.MinAgeDays),
+				zap.Bool("hard_delete", opts.HardDelete))
+			return NewPruner(graphClient, vectorIndex, logger).Prune(ctx, input.Event.Data.Namespace, opts)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("prune failed: %w", err)
+		}
+		return result, nil
+	}
+}
+
+// NewPruneWorkflow creates the on-demand namespace pruning workflow
+func NewPruneWorkflow(cfg WorkflowConfig) (inngestgo.FunctionOpts, inngestgo.Trigger) {
+	return inngestgo.FunctionOpts{
+			ID:   "prune-namespace",
+			Name: "Prune Low-Value Nodes",
+		},
+		inngestgo.EventTrigger("memory.prune.requested", nil)
+}
+
 // WorkflowService wraps the Inngest service for RMK workflows
 type WorkflowService struct {
 	client      inngestgo.Client
@@ -390,6 +436,7 @@ type WorkflowService struct {
 	logger      *zap.Logger
 	graphClient *graph.Client
 	embedder    local.LocalEmbedder
+	vectorIndex *VectorIndex
 	server      *http.Server
 }
 
@@ -412,6 +459,7 @@ func NewWorkflowService(cfg WorkflowConfig, graphClient *graph.Client, embedder
 		logger:      cfg.Logger,
 		graphClient: graphClient,
 		embedder:    embedder,
+		vectorIndex: NewVectorIndex(cfg.QdrantURL, DefaultCollectionName, cfg.Logger),
 	}
 
 	// Register workflows
@@ -448,6 +496,15 @@ func (ws *WorkflowService) registerWorkflows() {
 	} else {
 		ws.logger.Info("Registered maintenance cron workflow")
 	}
+
+	// Namespace prune workflow
+	pruneOpts, pruneTrigger := NewPruneWorkflow(ws.config)
+	_, err = inngestgo.CreateFunction(ws.client, pruneOpts, pruneTrigger, pruneWorkflow(ws.config, ws.graphClient, ws.vectorIndex))
+	if err != nil {
+		ws.logger.Error("Failed to register prune workflow", zap.Error(err))
+	} else {
+		ws.logger.Info("Registered prune workflow")
+	}
 }
 
 // Serve starts the workflow service
@@ -4,7 +4,9 @@ package kernel
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,20 +16,28 @@ import (
 
 	"github.com/reflective-memory-kernel/internal/ai/local"
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/kernel/events"
 	"github.com/reflective-memory-kernel/internal/kernel/wisdom"
 	"github.com/reflective-memory-kernel/internal/memory"
 	"github.com/reflective-memory-kernel/internal/policy"
 	"github.com/reflective-memory-kernel/internal/reflection"
+	"github.com/reflective-memory-kernel/internal/vectorindex"
 )
 
 // Config holds the Memory Kernel configuration
 type Config struct {
 	// DGraph configuration
 	DGraphAddress string
+	// DGraphPoolSize is the number of pooled DGraph connections (0 = graph.DefaultPoolSize)
+	DGraphPoolSize int
 
 	// NATS configuration
 	NATSAddress string
 
+	// EventsEnabled publishes memory events (node.created, insight.generated, ...)
+	// on NATS for external consumers. Disabled by default.
+	EventsEnabled bool
+
 	// Redis configuration
 	RedisAddress  string
 	RedisPassword string
@@ -39,12 +49,61 @@ type Config struct {
 	// Qdrant vector database configuration
 	QdrantURL string
 
+	// RequiredDependencies lists the dependencies ("qdrant", "redis", "nats") Start
+	// waits for before booting; others are probed once and skipped when down.
+	// nil means DefaultRequiredDependencies, an empty list waits for nothing.
+	RequiredDependencies []string
+	// DependencyWaitAttempts / DependencyWaitInterval bound the wait (0 = defaults)
+	DependencyWaitAttempts int
+	DependencyWaitInterval time.Duration
+
+	// Ollama embedding configuration (empty = OLLAMA_URL / OLLAMA_EMBED_MODEL, then defaults)
+	OllamaURL      string
+	EmbeddingModel string
+
+	// EmbeddingCacheTTL is how long embeddings are cached in Redis (0 = default)
+	EmbeddingCacheTTL time.Duration
+
+	// ConsultationCacheTTL is how long consultation responses are cached in Redis
+	// (0 = default, negative disables the cache)
+	ConsultationCacheTTL time.Duration
+
+	// RecencyBoostWindow is how long freshly ingested memories get a ranking
+	// bonus in consultations (0 = default, negative disables the boost)
+	RecencyBoostWindow time.Duration
+
+	// ConsultationBudget bounds how long a consultation may take when its
+	// request sets no budget (0 = default, negative leaves it unbounded)
+	ConsultationBudget time.Duration
+
+	// AIServiceTimeout bounds each call a consultation makes to the AI
+	// service (0 = DefaultAIServiceTimeout)
+	AIServiceTimeout time.Duration
+
+	// BriefMaxFacts is how many facts a brief written without the AI service
+	// lists before counting the rest (0 = synthesis.DefaultMaxFacts)
+	BriefMaxFacts int
+
+	// RerankMaxCandidates caps the facts a consultation asking for reranking
+	// has scored (0 = DefaultRerankMaxCandidates)
+	RerankMaxCandidates int
+	// RerankCandidatesPerMinute caps the facts scored for reranking per
+	// minute (0 = default, negative disables reranking)
+	RerankCandidatesPerMinute int
+
+	// SearchStopWords are ignored by keyword searches on top of the built-in
+	// stop words
+	SearchStopWords []string
+
 	// Reflection configuration
 	ReflectionInterval  time.Duration
 	ActivationDecayRate float64
 	MinReflectionBatch  int
 	MaxReflectionBatch  int
 
+	// MaxInsightEvaluations caps AI insight evaluations per reflection cycle (0 = default)
+	MaxInsightEvaluations int
+
 	// Ingestion configuration
 	IngestionBatchSize     int
 	IngestionFlushInterval time.Duration
@@ -68,6 +127,10 @@ func DefaultConfig() Config {
 		ActivationDecayRate:    0.05, // 5% decay per day
 		MinReflectionBatch:     10,
 		MaxReflectionBatch:     100,
+		MaxInsightEvaluations:  10,
+		EmbeddingCacheTTL:      DefaultEmbeddingCacheTTL,
+		ConsultationCacheTTL:   DefaultConsultationCacheTTL,
+		RecencyBoostWindow:     DefaultRecencyBoostWindow,
 		IngestionBatchSize:     50,
 		IngestionFlushInterval: 5 * time.Second,
 		WisdomBatchSize:        5,
@@ -87,12 +150,25 @@ type Kernel struct {
 	jetStream    nats.JetStreamContext
 	redisClient  *redis.Client
 
+	// Memory event publisher (nil when events are disabled)
+	events *events.Publisher
+
 	// Reflection engine
 	reflectionEngine *reflection.Engine
 
+	// Per-namespace activation boost/decay parameters, tunable at runtime
+	activationConfigs *reflection.ActivationConfigStore
+
 	// Ingestion pipeline
 	ingestionPipeline *IngestionPipeline
 	localEmbedder     local.LocalEmbedder
+	embeddingCache    *CachedEmbedder
+
+	// Dead-letter queue for failed zero-copy ingestion
+	deadLetters *DeadLetterQueue
+
+	// Vector upserts that failed during ingestion, retried in the background
+	indexQueue *IndexQueue
 
 	// Wisdom manager (Cold Path)
 	wisdomManager *wisdom.WisdomManager
@@ -100,6 +176,10 @@ type Kernel struct {
 	// Vector index for Hybrid RAG
 	vectorIndex *VectorIndex
 
+	// Saved document vector trees, sized by the first tree stored
+	treeIndex *VectorIndex
+	treeMu    sync.Mutex
+
 	// Hot Cache for recent messages (Hot Path)
 	hotCache *memory.HotCache
 
@@ -109,6 +189,18 @@ type Kernel struct {
 	// Consultation handler
 	consultationHandler *ConsultationHandler
 
+	// Consultation response cache, invalidated on ingestion (nil when disabled)
+	consultationCache *ConsultationCache
+
+	// Graph reads and deletes of Forget; the graph client outside tests
+	forgetGraph forgetGraph
+
+	// Graph reads of FindSimilar; the graph client outside tests
+	similarGraph similarGraph
+
+	// Hooks run after Forget (external caches)
+	forgetHooks []ForgetHook
+
 	// Control
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -166,9 +258,9 @@ func (k *Kernel) DeleteGroup(ctx context.Context, groupID, userID string) error
 	return k.graphClient.DeleteGroup(ctx, groupID, userID)
 }
 
-// ShareToGroup shares a conversation with a group
-func (k *Kernel) ShareToGroup(ctx context.Context, conversationID, groupID string) error {
-	return k.graphClient.ShareToGroup(ctx, conversationID, groupID)
+// ShareToGroup shares a conversation with a group on behalf of sharedBy
+func (k *Kernel) ShareToGroup(ctx context.Context, conversationID, groupID, sharedBy string) error {
+	return k.graphClient.ShareToGroup(ctx, conversationID, groupID, sharedBy)
 }
 
 // ============================================================================
@@ -242,9 +334,9 @@ func (k *Kernel) RevokeShareLink(ctx context.Context, token, userID string) erro
 	return k.graphClient.RevokeShareLink(ctx, token, userID)
 }
 
-// GetWorkspaceMembers gets all members of a workspace
-func (k *Kernel) GetWorkspaceMembers(ctx context.Context, workspaceNS string) ([]graph.WorkspaceMember, error) {
-	return k.graphClient.GetWorkspaceMembers(ctx, workspaceNS)
+// GetWorkspaceMembers gets a page of a workspace's members and the total count
+func (k *Kernel) GetWorkspaceMembers(ctx context.Context, workspaceNS string, offset, limit int) ([]graph.WorkspaceMember, int, error) {
+	return k.graphClient.GetWorkspaceMembers(ctx, workspaceNS, offset, limit)
 }
 
 // IsWorkspaceMember checks if a user is a member of a workspace
@@ -257,6 +349,11 @@ func (k *Kernel) GetGraphClient() *graph.Client {
 	return k.graphClient
 }
 
+// GetEmbedder returns the kernel's embedder for external use (e.g., Pre-Cortex)
+func (k *Kernel) GetEmbedder() local.LocalEmbedder {
+	return k.localEmbedder
+}
+
 // StoreInHotCache stores a conversation turn in the hot cache for immediate retrieval
 // This is the Hot Path - enables instant context for follow-up questions
 // SECURITY: Namespace is required for isolation between tenants/workspaces
@@ -285,14 +382,33 @@ func (k *Kernel) Start() error {
 		MaxRetries:     10,
 		RetryInterval:  3 * time.Second,
 		RequestTimeout: 30 * time.Second,
+		PoolSize:       k.config.DGraphPoolSize,
 	}
 	graphClient, err := graph.NewClient(k.ctx, graphCfg, k.logger)
 	if err != nil {
 		return err
 	}
 	k.graphClient = graphClient
+	k.forgetGraph = graphClient
+	k.similarGraph = graphClient
 	k.queryBuilder = graph.NewQueryBuilder(graphClient)
 
+	// Initialize Redis client
+	k.redisClient = redis.NewClient(&redis.Options{
+		Addr:     k.config.RedisAddress,
+		Password: k.config.RedisPassword,
+		DB:       k.config.RedisDB,
+	})
+
+	// Vector Index (Qdrant) client; the collection is initialized further down
+	k.vectorIndex = NewVectorIndex(k.config.QdrantURL, DefaultCollectionName, k.logger)
+	k.treeIndex = NewVectorIndex(k.config.QdrantURL, TreeCollectionName, k.logger.Named("tree_index"))
+
+	// Wait for Qdrant, Redis and NATS on cold start (e.g. docker-compose)
+	if err := k.waitForDependencies(); err != nil {
+		return err
+	}
+
 	// Initialize NATS connection with JetStream
 	natsConn, err := nats.Connect(k.config.NATSAddress,
 		nats.RetryOnFailedConnect(true),
@@ -321,20 +437,17 @@ func (k *Kernel) Start() error {
 		k.logger.Warn("Failed to create NATS stream", zap.Error(err))
 	}
 
-	// Initialize Redis client
-	k.redisClient = redis.NewClient(&redis.Options{
-		Addr:     k.config.RedisAddress,
-		Password: k.config.RedisPassword,
-		DB:       k.config.RedisDB,
-	})
-	if err := k.redisClient.Ping(k.ctx).Err(); err != nil {
-		return err
+	// Memory events are opt-in; a nil publisher is a no-op
+	if k.config.EventsEnabled {
+		k.events = events.NewPublisher(natsConn, k.logger.Named("events"))
+		k.logger.Info("Memory event publishing enabled", zap.String("subjects", events.SubjectPrefix+">"))
 	}
 
 	// Initialize reflection engine
 	// Custom activation config
 	activationCfg := graph.DefaultActivationConfig()
 	activationCfg.DecayRate = k.config.ActivationDecayRate
+	k.activationConfigs = reflection.NewActivationConfigStore(k.redisClient, activationCfg, k.logger.Named("activation_config"))
 
 	reflectionCfg := reflection.Config{
 		GraphClient:        k.graphClient,
@@ -342,39 +455,68 @@ func (k *Kernel) Start() error {
 		RedisClient:        k.redisClient,
 		AIServicesURL:      k.config.AIServicesURL,
 		ActivationConfig:   activationCfg,
+		ActivationConfigs:  k.activationConfigs,
 		ReflectionInterval: k.config.ReflectionInterval,
 		MinBatchSize:       k.config.MinReflectionBatch,
 		MaxBatchSize:       k.config.MaxReflectionBatch,
+		Events:             k.events,
+
+		MaxInsightEvaluations: k.config.MaxInsightEvaluations,
 	}
 	k.reflectionEngine = reflection.NewEngine(reflectionCfg, k.logger)
 
-	// Initialize Local AI (Hot Path) - Using Ollama for embeddings
+	// Initialize Local AI (Hot Path) - Ollama embeddings with the AI service as fallback
 	// Must be initialized before WisdomManager for Hybrid RAG
-	ollamaEmbedder := local.NewOllamaEmbedder("", "") // Uses OLLAMA_URL env var
+	ollamaEmbedder := local.NewOllamaEmbedder(k.config.OllamaURL, k.config.EmbeddingModel)
 
-	// Try to ensure the embedding model is available
+	// Pull the embedding model if missing; a still-missing model falls through to remote
 	if err := ollamaEmbedder.EnsureModel(); err != nil {
-		k.logger.Warn("Failed to ensure Ollama embedding model (will retry on first use)", zap.Error(err))
+		k.logger.Warn("Failed to pull Ollama embedding model", zap.String("model", ollamaEmbedder.Model()), zap.Error(err))
+	}
+	remoteEmbedder := local.NewRemoteEmbedder(k.config.AIServicesURL, EmbeddingDimension)
+	embedder := local.NewFallbackEmbedder(ollamaEmbedder, remoteEmbedder, ollamaEmbedder.Model(), EmbeddingDimension, k.logger.Named("embedder"))
+
+	// Fail fast when no embedder works: otherwise vector search is silently disabled
+	if err := embedder.Init(); err != nil {
+		return fmt.Errorf("failed to initialize embedder: %w", err)
 	}
-	k.localEmbedder = ollamaEmbedder
-	k.logger.Info("Ollama embedder initialized (Hot Path enabled)")
+	// Cache embeddings in Redis: repeated queries and summaries skip the model
+	k.embeddingCache = NewCachedEmbedder(embedder, k.redisClient, ollamaEmbedder.Model(), k.config.EmbeddingCacheTTL, k.logger.Named("embedding_cache"))
+	k.localEmbedder = k.embeddingCache
+	k.logger.Info("Embedder initialized (Hot Path enabled)",
+		zap.String("ollama_url", ollamaEmbedder.BaseURL()),
+		zap.String("model", ollamaEmbedder.Model()),
+		zap.Int("dimension", embedder.Dimension()))
 
 	// Initialize Vector Index (Qdrant) for Hybrid RAG
 	// Must be initialized before WisdomManager for embedding storage
-	k.vectorIndex = NewVectorIndex(k.config.QdrantURL, DefaultCollectionName, k.logger)
 	if err := k.vectorIndex.Initialize(k.ctx); err != nil {
 		k.logger.Warn("Failed to initialize Qdrant vector index (will retry on first use)", zap.Error(err))
 	} else {
 		k.logger.Info("Qdrant vector index initialized (Hybrid RAG enabled)")
 	}
 
+	// Embeddings Qdrant rejects are queued and reindexed once it recovers
+	k.indexQueue = NewIndexQueue(k.redisClient, k.vectorIndex, k.logger.Named("index_queue"))
+
 	// Initialize Wisdom Manager (Cold Path) with Hybrid RAG support
 	wisdomCfg := wisdom.Config{
 		BatchSize:     k.config.WisdomBatchSize,
 		FlushInterval: k.config.WisdomFlushInterval,
 		AIServiceURL:  k.config.AIServicesURL,
 	}
-	k.wisdomManager = wisdom.NewManager(wisdomCfg, k.graphClient, k.localEmbedder, k.vectorIndex, k.logger)
+	k.wisdomManager = wisdom.NewManager(wisdomCfg, k.graphClient, k.localEmbedder, k.indexQueue, k.logger)
+	k.wisdomManager.SetEventPublisher(k.events)
+
+	// Consultation response cache (ingestion invalidates a namespace's entries)
+	consultationCacheTTL := k.config.ConsultationCacheTTL
+	if consultationCacheTTL == 0 {
+		consultationCacheTTL = DefaultConsultationCacheTTL
+	}
+	k.consultationCache = NewConsultationCache(k.redisClient, consultationCacheTTL, k.logger.Named("consultation_cache"))
+	if k.consultationCache != nil {
+		k.wisdomManager.SetCacheInvalidator(k.consultationCache)
+	}
 
 	// Initialize ingestion pipeline
 	k.ingestionPipeline = NewIngestionPipeline(
@@ -389,6 +531,13 @@ func (k *Kernel) Start() error {
 		k.config.IngestionFlushInterval,
 		k.logger,
 	)
+	k.ingestionPipeline.SetEventPublisher(k.events)
+	k.ingestionPipeline.SetConsultationCache(k.consultationCache)
+	k.ingestionPipeline.SetActivationConfigs(k.activationConfigs)
+	k.ingestionPipeline.SetIndexQueue(k.indexQueue)
+
+	// Dead-letter queue for zero-copy ingestion failures (retried in the background)
+	k.deadLetters = NewDeadLetterQueue(k.redisClient, k.ingestionPipeline.IngestDirect, k.logger.Named("dlq"))
 
 	// Initialize Policy Manager
 	// Policy enforcement re-enabled after verifying same-namespace access works
@@ -411,12 +560,38 @@ func (k *Kernel) Start() error {
 		k.config.AIServicesURL,
 		k.logger,
 	)
+	k.consultationHandler.SetCache(k.consultationCache)
+	k.consultationHandler.SetActivationConfigs(k.activationConfigs)
+	recencyBoostWindow := k.config.RecencyBoostWindow
+	if recencyBoostWindow == 0 {
+		recencyBoostWindow = DefaultRecencyBoostWindow
+	}
+	k.consultationHandler.SetRecencyBoost(recencyBoostWindow)
+	consultationBudget := k.config.ConsultationBudget
+	if consultationBudget == 0 {
+		consultationBudget = DefaultConsultationBudget
+	}
+	k.consultationHandler.SetBudget(consultationBudget)
+	k.consultationHandler.SetAIServiceTimeout(k.config.AIServiceTimeout)
+	k.consultationHandler.SetRerankBudget(k.config.RerankMaxCandidates, k.config.RerankCandidatesPerMinute)
+	k.consultationHandler.SetBriefMaxFacts(k.config.BriefMaxFacts)
+	if len(k.config.SearchStopWords) > 0 {
+		k.consultationHandler.SetStopWords(k.config.SearchStopWords)
+	}
 
 	// Start background processes
-	k.wg.Add(3)
+	k.wg.Add(5)
 	go k.runIngestionLoop()
 	go k.runReflectionLoop()
 	go k.runDecayLoop()
+	go func() {
+		defer k.wg.Done()
+		k.deadLetters.Run(k.ctx)
+	}()
+	go func() {
+		defer k.wg.Done()
+		k.indexQueue.Run(k.ctx)
+	}()
 
 	k.wisdomManager.Start()
 
@@ -769,6 +944,27 @@ func (k *Kernel) GetStats(ctx context.Context) (map[string]interface{}, error) {
 		}
 	}
 
+	if k.deadLetters != nil {
+		if pending, err := k.deadLetters.Pending(ctx); err == nil {
+			stats["dlq_pending"] = pending
+		}
+	}
+
+	if k.indexQueue != nil {
+		if pending, err := k.indexQueue.Pending(ctx); err == nil {
+			stats["index_pending"] = pending
+		}
+	}
+
+	if k.embeddingCache != nil {
+		stats["embedding_cache"] = k.embeddingCache.Stats()
+	}
+
+	if k.consultationHandler != nil {
+		stats["synthesis"] = k.consultationHandler.SynthesisStats()
+		stats["vector_search"] = k.consultationHandler.VectorSearchStats()
+	}
+
 	return stats, nil
 }
 
@@ -778,7 +974,47 @@ func (k *Kernel) IngestEvent(ctx context.Context, event *graph.TranscriptEvent)
 		return fmt.Errorf("kernel is not running")
 	}
 	// Delegate to pipeline's direct ingest
-	return k.ingestionPipeline.IngestDirect(ctx, event)
+	err := k.ingestionPipeline.IngestDirect(ctx, event)
+	if err != nil && k.deadLetters != nil {
+		// Persist for retry instead of losing the transcript; use the kernel context
+		// so a cancelled request can't prevent the write
+		if dlqErr := k.deadLetters.Add(k.ctx, event, err); dlqErr != nil {
+			k.logger.Error("Failed to dead-letter ingestion event",
+				zap.String("conversation_id", event.ConversationID),
+				zap.Error(dlqErr))
+			return err
+		}
+		return fmt.Errorf("ingestion failed, event queued for retry: %w", err)
+	}
+	return err
+}
+
+// RetryDeadLetters immediately retries every dead-lettered ingestion event.
+// Returns how many were ingested and how many remain queued.
+func (k *Kernel) RetryDeadLetters(ctx context.Context) (int, int64, error) {
+	if k.deadLetters == nil {
+		return 0, 0, fmt.Errorf("dead-letter queue not available")
+	}
+	succeeded, err := k.deadLetters.RetryAll(ctx)
+	pending, pendingErr := k.deadLetters.Pending(ctx)
+	if err == nil {
+		err = pendingErr
+	}
+	return succeeded, pending, err
+}
+
+// ReindexPending immediately retries every node whose vector upsert failed.
+// Returns how many were indexed and how many remain queued.
+func (k *Kernel) ReindexPending(ctx context.Context) (int, int64, error) {
+	if k.indexQueue == nil {
+		return 0, 0, fmt.Errorf("index queue not available")
+	}
+	indexed, err := k.indexQueue.ReindexAll(ctx)
+	pending, pendingErr := k.indexQueue.Pending(ctx)
+	if err == nil {
+		err = pendingErr
+	}
+	return indexed, pending, err
 }
 
 // PersistEntities persists extracted entities to the graph
@@ -786,11 +1022,161 @@ func (k *Kernel) PersistEntities(ctx context.Context, namespace, userID, convers
 	return k.ingestionPipeline.PersistEntities(ctx, namespace, userID, conversationID, entities)
 }
 
+// IngestConversationSummary crystallizes a summarized conversation into a
+// namespace through the Wisdom Layer and returns the summary node's UID
+func (k *Kernel) IngestConversationSummary(ctx context.Context, namespace, userID, conversationID string, batch *graph.BatchSummary) (string, error) {
+	if k.wisdomManager == nil {
+		return "", fmt.Errorf("wisdom layer not available")
+	}
+	return k.wisdomManager.IngestConversation(ctx, namespace, userID, conversationID, batch)
+}
+
 // PersistChunks persists document chunks to Qdrant
 func (k *Kernel) PersistChunks(ctx context.Context, namespace, docID string, chunks []graph.DocumentChunk) error {
 	return k.ingestionPipeline.PersistChunks(ctx, namespace, docID, chunks)
 }
 
+// PersistVectorTree stores a document's vector tree in the tree collection,
+// reusing its embeddings. The collection is created at the dimension of the
+// first tree stored.
+func (k *Kernel) PersistVectorTree(ctx context.Context, namespace string, tree map[string]*vectorindex.VectorNode) (int, error) {
+	if k.treeIndex == nil {
+		return 0, fmt.Errorf("vector tree index is not initialized")
+	}
+
+	k.treeMu.Lock()
+	defer k.treeMu.Unlock()
+	if !k.treeIndex.initialized {
+		for _, node := range tree {
+			k.treeIndex.SetDimension(len(node.Vector))
+			break
+		}
+	}
+	return k.treeIndex.RestoreVectorTree(ctx, namespace, tree)
+}
+
+// PruneNamespace archives (or, with opts.HardDelete, deletes) low-value nodes in a
+// namespace along with their vectors. See DefaultPruneOpts for the conservative defaults.
+func (k *Kernel) PruneNamespace(ctx context.Context, namespace string, opts PruneOpts) (*PruneResult, error) {
+	return NewPruner(k.graphClient, k.vectorIndex, k.logger.Named("prune")).Prune(ctx, namespace, opts)
+}
+
+// ErrSimilarUnavailable is returned by FindSimilar when the vector index or the
+// embedder it needs is not configured
+var ErrSimilarUnavailable = errors.New("similarity search unavailable")
+
+// similarSearchMaxLimit caps how many points FindSimilar reads from Qdrant
+// while looking for topK graph nodes among chunks and chat points
+const similarSearchMaxLimit = 1000
+
+// similarGraph is the part of the graph client FindSimilar uses
+type similarGraph interface {
+	GetNode(ctx context.Context, uid string) (*graph.Node, error)
+	GetNodesByUIDs(ctx context.Context, uids []string, fields []string) ([]graph.Node, error)
+}
+
+// FindSimilar returns the nodes nearest to uid in vector space, excluding the node itself.
+// It uses the node's stored embedding, re-embedding its text if it was never indexed.
+// A uid outside namespace fails with graph.ErrNodeNotFound.
+func (k *Kernel) FindSimilar(ctx context.Context, namespace, uid string, topK int) ([]graph.SimilarNode, error) {
+	if topK <= 0 {
+		topK = 10
+	}
+	if topK > 50 {
+		topK = 50
+	}
+	if k.vectorIndex == nil {
+		return nil, fmt.Errorf("%w: vector index not available", ErrSimilarUnavailable)
+	}
+
+	node, err := k.similarGraph.GetNode(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	// SECURITY: never reveal or search from a node outside the caller's namespace
+	if node.Namespace != namespace {
+		return nil, fmt.Errorf("%w: %s", graph.ErrNodeNotFound, uid)
+	}
+
+	vec, err := k.vectorIndex.GetVector(ctx, namespace, uid)
+	if err != nil {
+		k.logger.Debug("Stored embedding lookup failed, re-embedding", zap.String("uid", uid), zap.Error(err))
+	}
+	if len(vec) == 0 {
+		if k.localEmbedder == nil {
+			return nil, fmt.Errorf("%w: embedder not available", ErrSimilarUnavailable)
+		}
+		text := node.Name
+		if node.Description != "" {
+			text += ": " + node.Description
+		}
+		if vec, err = k.localEmbedder.Embed(text); err != nil {
+			return nil, fmt.Errorf("failed to embed node: %w", err)
+		}
+	}
+
+	// Ask for one extra hit since the node usually matches itself. Chunks and
+	// chat points share the collection, so widen the search until it yields
+	// topK graph nodes or runs out of points.
+	var similar []graph.SimilarNode
+	for limit := topK + 1; ; limit = min(limit*4, similarSearchMaxLimit) {
+		uids, scores, _, err := k.vectorIndex.Search(ctx, namespace, "", vec, limit)
+		if err != nil {
+			return nil, err
+		}
+		if similar, err = k.similarNodes(ctx, namespace, uid, uids, scores, topK); err != nil {
+			return nil, err
+		}
+		if len(similar) == topK || len(uids) < limit || limit == similarSearchMaxLimit {
+			return similar, nil
+		}
+	}
+}
+
+// similarNodes loads the graph nodes among a vector search's hits, in hit
+// order, skipping uid itself, non-graph points and nodes outside namespace
+func (k *Kernel) similarNodes(ctx context.Context, namespace, uid string, uids []string, scores []float32, topK int) ([]graph.SimilarNode, error) {
+	scoreByUID := make(map[string]float32, len(uids))
+	var candidates []string
+	for i, id := range uids {
+		// Skip the node itself and non-graph points (chunks, synthetic chat IDs)
+		if id == uid || !strings.HasPrefix(id, "0x") {
+			continue
+		}
+		if _, seen := scoreByUID[id]; seen {
+			continue
+		}
+		scoreByUID[id] = scores[i]
+		candidates = append(candidates, id)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	nodes, err := k.similarGraph.GetNodesByUIDs(ctx, candidates, graph.NodeFieldsSummary)
+	if err != nil {
+		return nil, err
+	}
+	byUID := make(map[string]graph.Node, len(nodes))
+	for _, n := range nodes {
+		byUID[n.UID] = n
+	}
+
+	// Preserve similarity order from the vector search
+	similar := make([]graph.SimilarNode, 0, topK)
+	for _, id := range candidates {
+		n, ok := byUID[id]
+		if !ok || n.Namespace != namespace {
+			continue
+		}
+		similar = append(similar, graph.SimilarNode{Node: n, Score: scoreByUID[id]})
+		if len(similar) == topK {
+			break
+		}
+	}
+	return similar, nil
+}
+
 // SearchNodes delegates to the graph client to perform a node search
 func (k *Kernel) SearchNodes(ctx context.Context, namespace, query string) ([]graph.Node, error) {
 	return k.graphClient.SearchNodes(ctx, query, namespace)
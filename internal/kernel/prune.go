@@ -0,0 +1,152 @@
+// Package kernel provides maintenance pruning of low-value nodes.
+// Nodes whose activation has decayed to ~0, that have not been touched in a long
+// time and (by default) have no relationship edges are archived, or deleted on
+// explicit request, together with their vectors. Pinned nodes are always kept.
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// PruneOpts selects which nodes are pruned and how
+type PruneOpts struct {
+	// MinActivation: nodes with activation below this qualify
+	MinActivation float64 `json:"min_activation"`
+	// MinAgeDays: nodes created or accessed within this many days are kept
+	MinAgeDays int `json:"min_age_days"`
+	// RequireEdges keeps any node with a relationship edge, so only orphans are pruned
+	RequireEdges bool `json:"require_edges"`
+	// HardDelete deletes nodes instead of archiving them; must be set explicitly
+	HardDelete bool `json:"hard_delete"`
+	// DryRun reports candidates without changing anything
+	DryRun bool `json:"dry_run"`
+	// Limit caps nodes examined per run
+	Limit int `json:"limit"`
+}
+
+// DefaultPruneOpts returns conservative defaults: archive-only, orphans only,
+// activation effectively zero and untouched for 90 days
+func DefaultPruneOpts() PruneOpts {
+	return PruneOpts{
+		MinActivation: 0.01,
+		MinAgeDays:    90,
+		RequireEdges:  true,
+		Limit:         500,
+	}
+}
+
+// PruneResult reports what a prune run did
+type PruneResult struct {
+	Namespace      string   `json:"namespace"`
+	Examined       int      `json:"examined"`
+	Pruned         int      `json:"pruned"`
+	Archived       int      `json:"archived"`
+	Deleted        int      `json:"deleted"`
+	VectorsRemoved int      `json:"vectors_removed"`
+	Failed         int      `json:"failed"`
+	DryRun         bool     `json:"dry_run"`
+	UIDs           []string `json:"uids,omitempty"`
+}
+
+// Pruner retires low-value nodes from a namespace
+type Pruner struct {
+	graphClient  *graph.Client
+	queryBuilder *graph.QueryBuilder
+	vectorIndex  *VectorIndex // Optional; vectors are left in place when nil
+	logger       *zap.Logger
+}
+
+// NewPruner creates a pruner; vectorIndex may be nil
+func NewPruner(graphClient *graph.Client, vectorIndex *VectorIndex, logger *zap.Logger) *Pruner {
+	return &Pruner{
+		graphClient:  graphClient,
+		queryBuilder: graph.NewQueryBuilder(graphClient),
+		vectorIndex:  vectorIndex,
+		logger:       logger,
+	}
+}
+
+// Prune archives (or deletes, when opts.HardDelete) qualifying nodes in namespace
+func (p *Pruner) Prune(ctx context.Context, namespace string, opts PruneOpts) (*PruneResult, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if opts.MinActivation <= 0 || opts.MinAgeDays <= 0 {
+		return nil, fmt.Errorf("min_activation and min_age_days must be positive")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPruneOpts().Limit
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -opts.MinAgeDays)
+	candidates, err := p.queryBuilder.GetPruneCandidates(ctx, namespace, opts.MinActivation, cutoff, opts.Limit, opts.RequireEdges)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find prune candidates: %w", err)
+	}
+
+	result := &PruneResult{
+		Namespace: namespace,
+		Examined:  len(candidates),
+		DryRun:    opts.DryRun,
+	}
+
+	for _, c := range candidates {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if opts.RequireEdges && c.Edges > 0 {
+			continue
+		}
+		if c.Pinned {
+			continue // Pinned nodes are never pruned
+		}
+
+		result.Pruned++
+		result.UIDs = append(result.UIDs, c.UID)
+		if opts.DryRun {
+			continue
+		}
+
+		if opts.HardDelete {
+			err = p.graphClient.DeleteNode(ctx, c.UID, namespace)
+		} else {
+			err = p.graphClient.ArchiveNode(ctx, c.UID)
+		}
+		if err != nil {
+			p.logger.Warn("Failed to prune node", zap.String("uid", c.UID), zap.Error(err))
+			result.Failed++
+			continue
+		}
+		if opts.HardDelete {
+			result.Deleted++
+		} else {
+			result.Archived++
+		}
+
+		// Archived nodes leave the vector index too so they stop surfacing in search
+		if p.vectorIndex != nil {
+			if err := p.vectorIndex.Delete(ctx, namespace, c.UID); err != nil {
+				p.logger.Warn("Failed to remove pruned node vector", zap.String("uid", c.UID), zap.Error(err))
+			} else {
+				result.VectorsRemoved++
+			}
+		}
+	}
+
+	p.logger.Info("Prune completed",
+		zap.String("namespace", namespace),
+		zap.Int("examined", result.Examined),
+		zap.Int("pruned", result.Pruned),
+		zap.Int("archived", result.Archived),
+		zap.Int("deleted", result.Deleted),
+		zap.Int("failed", result.Failed),
+		zap.Bool("dry_run", opts.DryRun))
+
+	return result, nil
+}
@@ -0,0 +1,206 @@
+package kernel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// classifyQueryTimeout bounds the /classify-intent call made for a query the
+// rules can't place; retrieval falls back to the hybrid plan after it
+const classifyQueryTimeout = 2 * time.Second
+
+// retrievalPlan is which retrieval paths a consultation runs and how their
+// results are ranked
+type retrievalPlan struct {
+	Strategy     string
+	Vector       bool    // Vector search, spreading activation from its hits
+	FullText     bool    // Keyword match on names, descriptions and tags
+	Relations    bool    // Edge traversal from the user's node
+	Activation   bool    // The namespace's most activated nodes
+	Recency      bool    // The namespace's most recently created nodes
+	VectorWeight float64 // Share of the fused score from similarity; the rest is activation
+	NewestFirst  bool    // Rank by creation time rather than fused score
+}
+
+// retrievalPlans maps each strategy to its plan. Hybrid runs every general
+// path and is what a query gets when nothing more specific fits.
+var retrievalPlans = map[string]retrievalPlan{
+	graph.RetrievalStrategyHybrid: {
+		Vector: true, Activation: true, Recency: true, VectorWeight: 0.6,
+	},
+	graph.RetrievalStrategyRelational: {
+		Vector: true, Relations: true, VectorWeight: 0.3,
+	},
+	graph.RetrievalStrategySemantic: {
+		Vector: true, FullText: true, VectorWeight: 0.8,
+	},
+	graph.RetrievalStrategyRecent: {
+		Recency: true, NewestFirst: true,
+	},
+}
+
+// planFor returns the plan for strategy, hybrid for unknown ones
+func planFor(strategy string) retrievalPlan {
+	plan, ok := retrievalPlans[strategy]
+	if !ok {
+		strategy, plan = graph.RetrievalStrategyHybrid, retrievalPlans[graph.RetrievalStrategyHybrid]
+	}
+	plan.Strategy = strategy
+	return plan
+}
+
+// Phrases the rules route on, matched against the lowercased query
+var (
+	// relationalPhrases ask who someone is to the user: edge traversal
+	relationalPhrases = []string{
+		"who is my", "who's my", "who are my", "my manager", "my boss", "my partner",
+		"my wife", "my husband", "my friend", "my colleague", "my coworker", "my team",
+		"my family", "my mother", "my father", "my mom", "my dad", "my brother", "my sister",
+		"where do i work", "who do i work", "who do i know", "i report to", "reports to me",
+	}
+	// recentPhrases ask what happened lately: newest memories first
+	recentPhrases = []string{
+		"today", "yesterday", "this week", "last week", "this month", "last month",
+		"recently", "lately", "so far", "summarize my", "summarise my", "recap",
+		"what did i do", "what have i been",
+	}
+	// semanticPhrases ask about a topic: keyword and similarity matches
+	semanticPhrases = []string{
+		"what do i know about", "what do you know about", "tell me about", "anything about",
+		"remind me about", "notes on", "information on", "info on",
+	}
+)
+
+// routeQueryByRules picks a strategy from the query's wording, reporting
+// false when no rule applies
+func routeQueryByRules(queryText string) (string, bool) {
+	q := strings.ToLower(strings.TrimSpace(queryText))
+	for _, rule := range []struct {
+		strategy string
+		phrases  []string
+	}{
+		{graph.RetrievalStrategyRecent, recentPhrases},
+		{graph.RetrievalStrategyRelational, relationalPhrases},
+		{graph.RetrievalStrategySemantic, semanticPhrases},
+	} {
+		for _, phrase := range rule.phrases {
+			if strings.Contains(q, phrase) {
+				return rule.strategy, true
+			}
+		}
+	}
+	return "", false
+}
+
+// planRetrieval chooses the retrieval paths for a query. Wording the rules
+// recognise decides it directly; otherwise the AI service's intent router is
+// asked, and a plain fact lookup is answered by keyword and similarity alone.
+// Anything else, including an intent router that can't be reached, gets the
+// hybrid plan.
+func (h *ConsultationHandler) planRetrieval(ctx context.Context, queryText string) retrievalPlan {
+	if strategy, ok := routeQueryByRules(queryText); ok {
+		return planFor(strategy)
+	}
+	if h.aiServicesURL == "" || h.noIntentRouter.Load() {
+		return planFor(graph.RetrievalStrategyHybrid)
+	}
+
+	var intent string
+	err := h.intentBreaker.Execute(func() error {
+		var err error
+		intent, err = h.classifyIntent(ctx, queryText)
+		return err
+	})
+	if err != nil {
+		h.logger.Debug("Intent classification unavailable, using hybrid retrieval", zap.Error(err))
+		return planFor(graph.RetrievalStrategyHybrid)
+	}
+	if intent == "FACT_RETRIEVAL" {
+		return planFor(graph.RetrievalStrategySemantic)
+	}
+	return planFor(graph.RetrievalStrategyHybrid)
+}
+
+// classifyIntent asks the AI service's intent router (the one the Pre-Cortex
+// uses) what kind of message queryText is. An AI service without one is not
+// asked again.
+func (h *ConsultationHandler) classifyIntent(ctx context.Context, queryText string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, classifyQueryTimeout)
+	defer cancel()
+
+	jsonData, err := json.Marshal(map[string]string{"query": queryText})
+	if err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", h.aiServicesURL+"/classify-intent", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.aiClient.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		h.noIntentRouter.Store(true)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("classify-intent returned status %d", resp.StatusCode)
+	}
+	var result struct {
+		Intent string `json:"intent"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	return result.Intent, nil
+}
+
+// userRelations returns the nodes within two edges of the user's node in
+// namespace, with the activation that reached them, or nil when the namespace
+// has no node for the user
+func (h *ConsultationHandler) userRelations(ctx context.Context, namespace, userID string) []graph.Node {
+	userNode, err := h.graphClient.FindNodeByName(ctx, namespace, userID, graph.NodeTypeUser)
+	if err != nil || userNode == nil {
+		if err != nil {
+			h.logger.Warn("Failed to find user node for relationship traversal", zap.Error(err))
+		}
+		return nil
+	}
+
+	expanded, err := h.graphClient.SpreadActivation(ctx, graph.SpreadActivationOpts{
+		StartUID:      userNode.UID,
+		Namespace:     namespace,
+		DecayFactor:   0.6, // Retain 60% per hop
+		MaxHops:       2,   // "my manager's partner"
+		MinActivation: 0.2,
+		MaxResults:    20,
+	})
+	if err != nil {
+		h.logger.Warn("Relationship traversal failed", zap.Error(err))
+		return nil
+	}
+
+	nodes := make([]graph.Node, 0, len(expanded))
+	for _, an := range expanded {
+		if an.Node.UID == userNode.UID {
+			continue
+		}
+		node := an.Node
+		node.Activation = an.Activation
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
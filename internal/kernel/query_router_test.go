@@ -0,0 +1,113 @@
+package kernel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func TestPlanRetrievalRoutesByQuery(t *testing.T) {
+	var classified []string
+	aiService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/classify-intent" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		classified = append(classified, req.Query)
+		intent := "COMPLEX"
+		if req.Query == "where is the spare key" {
+			intent = "FACT_RETRIEVAL"
+		}
+		json.NewEncoder(w).Encode(map[string]string{"intent": intent})
+	}))
+	defer aiService.Close()
+
+	h := NewConsultationHandler(nil, nil, nil, nil, nil, nil, nil, aiService.URL, zaptest.NewLogger(t))
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"Who is my manager?", graph.RetrievalStrategyRelational},
+		{"What do I know about Kubernetes", graph.RetrievalStrategySemantic},
+		{"summarize my week", graph.RetrievalStrategyRecent},
+		{"where is the spare key", graph.RetrievalStrategySemantic},
+		{"should I take the job offer", graph.RetrievalStrategyHybrid},
+	} {
+		if got := h.planRetrieval(context.Background(), tc.query).Strategy; got != tc.want {
+			t.Errorf("planRetrieval(%q) = %s, want %s", tc.query, got, tc.want)
+		}
+	}
+	if len(classified) != 2 {
+		t.Errorf("intent router asked about %v, want only the two queries the rules can't place", classified)
+	}
+
+	recent := planFor(graph.RetrievalStrategyRecent)
+	if recent.Vector || recent.Activation || !recent.Recency || !recent.NewestFirst {
+		t.Errorf("recent plan = %+v, want recency alone, newest first", recent)
+	}
+
+	// An unreachable intent router leaves the hybrid plan
+	aiService.Close()
+	if got := h.planRetrieval(context.Background(), "should I move").Strategy; got != graph.RetrievalStrategyHybrid {
+		t.Errorf("planRetrieval without an intent router = %s, want hybrid", got)
+	}
+}
+
+// TestIntentRoutingSurvivesSynthesisOutage checks that an open synthesis
+// breaker leaves the intent router in use
+func TestIntentRoutingSurvivesSynthesisOutage(t *testing.T) {
+	aiService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"intent": "FACT_RETRIEVAL"})
+	}))
+	defer aiService.Close()
+
+	h := NewConsultationHandler(nil, nil, nil, nil, nil, nil, nil, aiService.URL, zaptest.NewLogger(t))
+	for i := 0; i < 5; i++ {
+		h.synthesisBreaker.Execute(func() error { return errors.New("synthesis down") })
+	}
+	if state := h.synthesisBreaker.GetState(); state != CircuitOpen {
+		t.Fatalf("synthesis breaker = %v, want open", state)
+	}
+
+	if got := h.planRetrieval(context.Background(), "where is the spare key").Strategy; got != graph.RetrievalStrategySemantic {
+		t.Errorf("planRetrieval with synthesis down = %s, want the router's semantic plan", got)
+	}
+}
+
+func TestUserRelationsStayInNamespace(t *testing.T) {
+	ctx := context.Background()
+	store := graph.NewMemStore()
+	create := func(namespace, name string, nodeType graph.NodeType) string {
+		t.Helper()
+		uid, err := store.CreateNode(ctx, &graph.Node{Namespace: namespace, Name: name, DType: []string{string(nodeType)}})
+		if err != nil {
+			t.Fatalf("CreateNode: %v", err)
+		}
+		return uid
+	}
+	alice := create("user_alice", "alice", graph.NodeTypeUser)
+	bob := create("user_alice", "Bob", graph.NodeTypeEntity)
+	mallory := create("user_eve", "Mallory", graph.NodeTypeEntity)
+	store.CreateEdge(ctx, alice, bob, graph.EdgeTypeHasManager, graph.EdgeStatusCurrent)
+	store.CreateEdge(ctx, alice, mallory, graph.EdgeType("knows"), graph.EdgeStatusCurrent)
+
+	h := NewConsultationHandler(store, nil, nil, nil, nil, nil, nil, "", zaptest.NewLogger(t))
+	got := map[string]bool{}
+	for _, n := range h.userRelations(ctx, "user_alice", "alice") {
+		got[n.Name] = true
+	}
+	if len(got) != 1 || !got["Bob"] {
+		t.Errorf("user relations = %v, want Bob alone", got)
+	}
+}
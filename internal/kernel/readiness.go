@@ -0,0 +1,162 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// Names of the dependencies the kernel can wait for at startup
+const (
+	DependencyQdrant = "qdrant"
+	DependencyRedis  = "redis"
+	DependencyNATS   = "nats"
+)
+
+const (
+	// DefaultDependencyWaitAttempts x DefaultDependencyWaitInterval bounds the
+	// startup wait for a required dependency (~60s)
+	DefaultDependencyWaitAttempts = 30
+	DefaultDependencyWaitInterval = 2 * time.Second
+
+	// dependencyCheckTimeout bounds a single readiness probe
+	dependencyCheckTimeout = 5 * time.Second
+)
+
+// DefaultRequiredDependencies must be ready before the kernel starts. Qdrant is
+// optional by default: without it vector search falls back to graph retrieval.
+var DefaultRequiredDependencies = []string{DependencyRedis, DependencyNATS}
+
+// ParseDependencies parses a comma-separated dependency list such as
+// "redis,nats". An empty string returns nil (use the defaults) and "none"
+// returns an empty list so nothing blocks startup.
+func ParseDependencies(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if strings.EqualFold(s, "none") {
+		return []string{}
+	}
+	var deps []string
+	for _, dep := range strings.Split(s, ",") {
+		if dep = strings.ToLower(strings.TrimSpace(dep)); dep != "" {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// waitForDependency polls check until it succeeds or attempts run out
+func (k *Kernel) waitForDependency(name string, check func(ctx context.Context) error, attempts int, interval time.Duration) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		ctx, cancel := context.WithTimeout(k.ctx, dependencyCheckTimeout)
+		err = check(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		k.logger.Info("Waiting for dependency",
+			zap.String("dependency", name),
+			zap.Int("attempt", i+1),
+			zap.Int("max_attempts", attempts),
+			zap.String("error", err.Error()))
+
+		if i < attempts-1 {
+			select {
+			case <-k.ctx.Done():
+				return k.ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+	}
+	return fmt.Errorf("%s not ready after %d attempts: %w", name, attempts, err)
+}
+
+// waitForDependencies blocks until every required dependency answers its
+// readiness check. Optional dependencies are probed once and skipped when down.
+func (k *Kernel) waitForDependencies() error {
+	required := k.config.RequiredDependencies
+	if required == nil {
+		required = DefaultRequiredDependencies
+	}
+	attempts := k.config.DependencyWaitAttempts
+	if attempts <= 0 {
+		attempts = DefaultDependencyWaitAttempts
+	}
+	interval := k.config.DependencyWaitInterval
+	if interval <= 0 {
+		interval = DefaultDependencyWaitInterval
+	}
+
+	checks := []struct {
+		name  string
+		check func(ctx context.Context) error
+	}{
+		{DependencyQdrant, k.vectorIndex.Ready},
+		{DependencyRedis, func(ctx context.Context) error { return k.redisClient.Ping(ctx).Err() }},
+		{DependencyNATS, natsReady(k.config.NATSAddress)},
+	}
+
+	known := make(map[string]bool, len(checks))
+	for _, c := range checks {
+		known[c.name] = true
+	}
+	isRequired := make(map[string]bool, len(required))
+	for _, name := range required {
+		if !known[name] {
+			k.logger.Warn("Unknown required dependency ignored", zap.String("dependency", name))
+		}
+		isRequired[name] = true
+	}
+
+	var ready, skipped []string
+	for _, c := range checks {
+		if !isRequired[c.name] {
+			ctx, cancel := context.WithTimeout(k.ctx, dependencyCheckTimeout)
+			err := c.check(ctx)
+			cancel()
+			if err != nil {
+				k.logger.Warn("Optional dependency not ready, skipping",
+					zap.String("dependency", c.name),
+					zap.Error(err))
+				skipped = append(skipped, c.name)
+				continue
+			}
+			ready = append(ready, c.name)
+			continue
+		}
+		if err := k.waitForDependency(c.name, c.check, attempts, interval); err != nil {
+			return err
+		}
+		ready = append(ready, c.name)
+	}
+
+	k.logger.Info("Dependency readiness checked",
+		zap.Strings("ready", ready),
+		zap.Strings("skipped", skipped),
+		zap.Strings("required", required))
+	return nil
+}
+
+// natsReady checks that a NATS server accepts connections
+func natsReady(addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		timeout := dependencyCheckTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		conn, err := nats.Connect(addr, nats.Timeout(timeout), nats.NoReconnect())
+		if err != nil {
+			return err
+		}
+		conn.Close()
+		return nil
+	}
+}
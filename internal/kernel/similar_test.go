@@ -0,0 +1,78 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func TestFindSimilarSkipsPastNonGraphPoints(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	qdrant := newFakeQdrant(t)
+
+	const namespace = "user_alice"
+	nodes := map[string]*graph.Node{
+		"0x1": {UID: "0x1", Name: "Origin", Namespace: namespace},
+		"0x2": {UID: "0x2", Name: "Nearest", Namespace: namespace},
+		"0x3": {UID: "0x3", Name: "Second", Namespace: namespace},
+		"0x4": {UID: "0x4", Name: "Third", Namespace: namespace},
+		// Indexed under alice but now owned by bob, so it must be dropped
+		"0x5": {UID: "0x5", Name: "Moved", Namespace: "user_bob"},
+	}
+	k := &Kernel{
+		logger:       logger,
+		vectorIndex:  NewVectorIndex(qdrant.URL, DefaultCollectionName, logger),
+		similarGraph: &fakeForgetGraph{nodes: nodes},
+	}
+
+	vectors := map[string][]float32{
+		"0x1": {1, 0},
+		"0x5": {0.95, 0.05},
+		"0x2": {0.9, 0.1},
+		"0x3": {0.8, 0.2},
+		"0x4": {0.7, 0.3},
+	}
+	for uid, vec := range vectors {
+		if err := k.vectorIndex.Store(ctx, namespace, uid, vec, nil); err != nil {
+			t.Fatalf("Store(%s): %v", uid, err)
+		}
+	}
+	// Chunks closer than every graph node fill the first searches
+	for i := 0; i < 10; i++ {
+		uid := fmt.Sprintf("chunk_doc1_%d", i)
+		payload := map[string]interface{}{"text": uid, "type": "chunk", "source_id": "doc1"}
+		if err := k.vectorIndex.Store(ctx, namespace, uid, []float32{1, 0}, payload); err != nil {
+			t.Fatalf("Store(%s): %v", uid, err)
+		}
+	}
+
+	similar, err := k.FindSimilar(ctx, namespace, "0x1", 2)
+	if err != nil {
+		t.Fatalf("FindSimilar: %v", err)
+	}
+	var got []string
+	for _, s := range similar {
+		got = append(got, s.Node.UID)
+	}
+	if len(got) != 2 || got[0] != "0x2" || got[1] != "0x3" {
+		t.Errorf("FindSimilar = %v, want [0x2 0x3]", got)
+	}
+
+	if _, err := k.FindSimilar(ctx, namespace, "0x5", 2); !errors.Is(err, graph.ErrNodeNotFound) {
+		t.Errorf("FindSimilar on another namespace's node = %v, want ErrNodeNotFound", err)
+	}
+	if _, err := k.FindSimilar(ctx, namespace, "0x9", 2); !errors.Is(err, graph.ErrNodeNotFound) {
+		t.Errorf("FindSimilar on a missing node = %v, want ErrNodeNotFound", err)
+	}
+
+	k.vectorIndex = nil
+	if _, err := k.FindSimilar(ctx, namespace, "0x1", 2); !errors.Is(err, ErrSimilarUnavailable) {
+		t.Errorf("FindSimilar without a vector index = %v, want ErrSimilarUnavailable", err)
+	}
+}
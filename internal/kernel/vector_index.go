@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/vectorindex"
 )
 
 const (
@@ -22,10 +24,15 @@ const (
 	DefaultCollectionName = "rmk_nodes"
 	// CacheCollectionName is the Qdrant collection for semantic cache
 	CacheCollectionName = "rmk_cache"
+	// TreeCollectionName is the Qdrant collection for document vector trees
+	TreeCollectionName = "rmk_trees"
 	// EmbeddingDimension is the dimension of Ollama nomic-embed-text embeddings
 	EmbeddingDimension = 768
 )
 
+// restoreBatchSize is how many tree nodes RestoreVectorTree upserts per request
+const restoreBatchSize = 256
+
 // Input validation limits for vector operations
 const (
 	MaxEmbeddingTextLength = 8000 // Maximum text length for embedding generation
@@ -94,6 +101,23 @@ func NewVectorIndex(qdrantURL, collectionName string, logger *zap.Logger) *Vecto
 	}
 }
 
+// Ready checks that Qdrant is reachable by listing collections
+func (vi *VectorIndex) Ready(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", vi.baseURL+"/collections", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := vi.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("qdrant returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
 // Initialize creates the collection if it doesn't exist
 func (vi *VectorIndex) Initialize(ctx context.Context) error {
 	if vi.initialized {
@@ -108,8 +132,25 @@ func (vi *VectorIndex) Initialize(ctx context.Context) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusOK {
+		// An existing collection keeps the vector size it was created with
+		var info struct {
+			Result struct {
+				Config struct {
+					Params struct {
+						Vectors struct {
+							Size int `json:"size"`
+						} `json:"vectors"`
+					} `json:"params"`
+				} `json:"config"`
+			} `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&info); err == nil && info.Result.Config.Params.Vectors.Size > 0 {
+			vi.dimension = info.Result.Config.Params.Vectors.Size
+		}
 		vi.initialized = true
-		vi.logger.Info("Qdrant collection already exists", zap.String("collection", vi.collectionName))
+		vi.logger.Info("Qdrant collection already exists",
+			zap.String("collection", vi.collectionName),
+			zap.Int("dimension", vi.dimension))
 		return nil
 	}
 
@@ -152,6 +193,7 @@ func (vi *VectorIndex) Initialize(ctx context.Context) error {
 
 // Store saves a node's embedding to Qdrant with metadata
 // The point ID is a hash of namespace+uid for uniqueness
+// Qdrant is asked to wait for indexing, so the point is searchable on return
 func (vi *VectorIndex) Store(ctx context.Context, namespace, uid string, embedding []float32, metadata map[string]interface{}) error {
 	if err := vi.Initialize(ctx); err != nil {
 		return err
@@ -178,7 +220,7 @@ func (vi *VectorIndex) Store(ctx context.Context, namespace, uid string, embeddi
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "PUT",
-		vi.baseURL+"/collections/"+vi.collectionName+"/points",
+		vi.baseURL+"/collections/"+vi.collectionName+"/points?wait=true",
 		bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
@@ -344,6 +386,61 @@ func (vi *VectorIndex) Search(ctx context.Context, namespace, userID string, que
 	return uids, scores, payloads, nil
 }
 
+// GetVector returns the stored embedding for a node, or nil if it isn't indexed
+func (vi *VectorIndex) GetVector(ctx context.Context, namespace, uid string) ([]float32, error) {
+	if err := vi.Initialize(ctx); err != nil {
+		return nil, err
+	}
+
+	retrieveReq := map[string]interface{}{
+		"ids":          []int64{hashToInt(namespace + ":" + uid)},
+		"with_payload": true,
+		"with_vector":  true,
+	}
+
+	jsonData, err := json.Marshal(retrieveReq)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		vi.baseURL+"/collections/"+vi.collectionName+"/points",
+		bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := vi.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve vector: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to retrieve vector (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Result []struct {
+			Vector  []float32              `json:"vector"`
+			Payload map[string]interface{} `json:"payload"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode vector: %w", err)
+	}
+
+	for _, point := range result.Result {
+		// Guard against hash collisions: the payload must name this node
+		if point.Payload["uid"] == uid && point.Payload["namespace"] == namespace {
+			return point.Vector, nil
+		}
+	}
+	return nil, nil
+}
+
 // mergeMaps merges two maps
 func mergeMaps(base, extra map[string]interface{}) map[string]interface{} {
 	if extra == nil {
@@ -386,9 +483,120 @@ func (vi *VectorIndex) Delete(ctx context.Context, namespace, uid string) error
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete vector (status %d): %s", resp.StatusCode, string(body))
+	}
 	return nil
 }
 
+// chunkScrollPage is how many document chunk points DeleteChunks reads per request
+const chunkScrollPage = 256
+
+// DeleteChunks removes the namespace's document chunk vectors whose text
+// match reports true, and returns how many it removed
+func (vi *VectorIndex) DeleteChunks(ctx context.Context, namespace string, match func(text string) bool) (int, error) {
+	if err := vi.Initialize(ctx); err != nil {
+		return 0, err
+	}
+
+	var ids []int64
+	var offset interface{}
+	for {
+		scrollReq := map[string]interface{}{
+			"filter": map[string]interface{}{
+				"must": []map[string]interface{}{
+					{"key": "namespace", "match": map[string]interface{}{"value": namespace}},
+					{"key": "type", "match": map[string]interface{}{"value": "chunk"}},
+				},
+			},
+			"limit":        chunkScrollPage,
+			"with_payload": true,
+			"with_vector":  false,
+		}
+		if offset != nil {
+			scrollReq["offset"] = offset
+		}
+		jsonData, err := json.Marshal(scrollReq)
+		if err != nil {
+			return 0, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST",
+			vi.baseURL+"/collections/"+vi.collectionName+"/points/scroll",
+			bytes.NewBuffer(jsonData))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := vi.httpClient.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scroll chunks: %w", err)
+		}
+		var result struct {
+			Result struct {
+				Points []struct {
+					ID      int64                  `json:"id"`
+					Payload map[string]interface{} `json:"payload"`
+				} `json:"points"`
+				NextPageOffset interface{} `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return 0, fmt.Errorf("failed to scroll chunks (status %d): %s", resp.StatusCode, string(body))
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode chunks: %w", err)
+		}
+
+		for _, point := range result.Result.Points {
+			// The filter is Qdrant's; the namespace is checked again here
+			if point.Payload["namespace"] != namespace {
+				continue
+			}
+			if text, _ := point.Payload["text"].(string); match(text) {
+				ids = append(ids, point.ID)
+			}
+		}
+		if result.Result.NextPageOffset == nil || len(result.Result.Points) == 0 {
+			break
+		}
+		offset = result.Result.NextPageOffset
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{"points": ids})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		vi.baseURL+"/collections/"+vi.collectionName+"/points/delete",
+		bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := vi.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to delete chunks (status %d): %s", resp.StatusCode, string(body))
+	}
+	return len(ids), nil
+}
+
 // Stats returns vector index statistics
 func (vi *VectorIndex) Stats(ctx context.Context) (map[string]interface{}, error) {
 	resp, err := vi.httpClient.Get(vi.baseURL + "/collections/" + vi.collectionName)
@@ -405,6 +613,91 @@ func (vi *VectorIndex) Stats(ctx context.Context) (map[string]interface{}, error
 	return result, nil
 }
 
+// SetDimension sets the vector size Initialize creates the collection with.
+// A collection that already exists keeps its own size, which Initialize reads
+// back in place of this one.
+func (vi *VectorIndex) SetDimension(dim int) {
+	vi.dimension = dim
+}
+
+// RestoreVectorTree upserts every node of a saved vector tree into the
+// collection under namespace, reusing the tree's stored embeddings so nothing
+// is re-embedded. Points are keyed by namespace and node ID, so restoring the
+// same tree again overwrites rather than duplicates. It returns the number of
+// nodes stored.
+func (vi *VectorIndex) RestoreVectorTree(ctx context.Context, namespace string, tree map[string]*vectorindex.VectorNode) (int, error) {
+	if !isValidNamespace(namespace) {
+		return 0, fmt.Errorf("invalid namespace %q", namespace)
+	}
+	if err := vi.Initialize(ctx); err != nil {
+		return 0, err
+	}
+
+	nodes := make([]*vectorindex.VectorNode, 0, len(tree))
+	for _, node := range tree {
+		if len(node.Vector) != vi.dimension {
+			return 0, fmt.Errorf("node %s has %d dimensions, collection %s has %d",
+				node.NodeID, len(node.Vector), vi.collectionName, vi.dimension)
+		}
+		nodes = append(nodes, node)
+	}
+
+	stored := 0
+	for start := 0; start < len(nodes); start += restoreBatchSize {
+		end := min(start+restoreBatchSize, len(nodes))
+		points := make([]map[string]interface{}, 0, end-start)
+		for _, node := range nodes[start:end] {
+			embedding := make([]float32, len(node.Vector))
+			for i, v := range node.Vector {
+				embedding[i] = float32(v)
+			}
+			points = append(points, map[string]interface{}{
+				"id":     hashToInt(namespace + ":" + node.NodeID),
+				"vector": embedding,
+				"payload": map[string]interface{}{
+					"namespace":    namespace,
+					"uid":          node.NodeID,
+					"source":       "vector_tree",
+					"text":         node.Text,
+					"depth":        node.Depth,
+					"children_ids": node.ChildrenIDs,
+					"leaf_count":   node.LeafCount,
+				},
+			})
+		}
+
+		jsonData, err := json.Marshal(map[string]interface{}{"points": points})
+		if err != nil {
+			return stored, err
+		}
+		req, err := http.NewRequestWithContext(ctx, "PUT",
+			vi.baseURL+"/collections/"+vi.collectionName+"/points?wait=true",
+			bytes.NewBuffer(jsonData))
+		if err != nil {
+			return stored, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := vi.httpClient.Do(req)
+		if err != nil {
+			return stored, fmt.Errorf("failed to restore vector tree: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return stored, fmt.Errorf("failed to restore vector tree (status %d): %s", resp.StatusCode, string(body))
+		}
+		resp.Body.Close()
+		stored += len(points)
+	}
+
+	vi.logger.Info("Restored vector tree into Qdrant",
+		zap.String("namespace", namespace),
+		zap.String("collection", vi.collectionName),
+		zap.Int("nodes", stored))
+	return stored, nil
+}
+
 // hashToInt creates a deterministic int64 hash from a string
 // Used for Qdrant point IDs
 func hashToInt(s string) int64 {
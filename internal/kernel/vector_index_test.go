@@ -0,0 +1,87 @@
+package kernel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/reflective-memory-kernel/internal/vectorindex"
+)
+
+func TestRestoreVectorTreeStoresEveryNode(t *testing.T) {
+	ctx := context.Background()
+	qdrant := newFakeQdrant(t)
+
+	chunks := make([]vectorindex.Chunk, 12)
+	for i := range chunks {
+		chunks[i].Text = fmt.Sprintf("chunk %d", i)
+		chunks[i].Embedding = make([]float64, 8)
+		chunks[i].Embedding[i%8] = 1
+		chunks[i].Embedding[(i+1)%8] = float64(i) / 10
+	}
+	builder := vectorindex.NewIndexBuilder(4, 8, nil)
+	data, err := vectorindex.SerializeTree(builder.BuildIndex(chunks))
+	if err != nil {
+		t.Fatalf("SerializeTree: %v", err)
+	}
+	tree, err := vectorindex.LoadTree(data)
+	if err != nil {
+		t.Fatalf("LoadTree: %v", err)
+	}
+
+	// The kernel creates the tree collection at the first tree's dimension
+	k := &Kernel{treeIndex: NewVectorIndex(qdrant.URL, TreeCollectionName, zaptest.NewLogger(t))}
+	if stored, err := k.PersistVectorTree(ctx, "user_alice", tree); err != nil || stored != len(tree) {
+		t.Fatalf("PersistVectorTree = %d, %v; want %d", stored, err, len(tree))
+	}
+
+	// A fresh index on the existing collection takes its size, not SetDimension's,
+	// and rebuilding the same chunks overwrites the points already stored
+	vi := NewVectorIndex(qdrant.URL, TreeCollectionName, zaptest.NewLogger(t))
+	vi.SetDimension(16)
+	if stored, err := vi.RestoreVectorTree(ctx, "user_alice", builder.BuildIndex(chunks)); err != nil || stored != len(tree) {
+		t.Fatalf("RestoreVectorTree = %d, %v; want %d", stored, err, len(tree))
+	}
+	wide := map[string]*vectorindex.VectorNode{"wide": {NodeID: "wide", Vector: make([]float64, 16)}}
+	if _, err := vi.RestoreVectorTree(ctx, "user_alice", wide); err == nil {
+		t.Error("RestoreVectorTree accepted 16-dimensional vectors for an 8-dimensional collection")
+	}
+
+	uids, _, payloads, err := vi.Search(ctx, "user_alice", "alice", make([]float32, 8), 100)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(uids) != len(tree) {
+		t.Fatalf("collection holds %d points, want %d", len(uids), len(tree))
+	}
+	for i, uid := range uids {
+		node := tree[uid]
+		if node == nil {
+			t.Errorf("point %s is not a tree node", uid)
+			continue
+		}
+		var children interface{} // Leaves have none
+		if len(node.ChildrenIDs) > 0 {
+			ids := make([]interface{}, len(node.ChildrenIDs))
+			for j, id := range node.ChildrenIDs {
+				ids[j] = id
+			}
+			children = ids
+		}
+		want := map[string]interface{}{
+			"namespace":    "user_alice",
+			"uid":          node.NodeID,
+			"source":       "vector_tree",
+			"text":         node.Text,
+			"depth":        float64(node.Depth),
+			"children_ids": children,
+			"leaf_count":   float64(node.LeafCount),
+		}
+		if !reflect.DeepEqual(payloads[i], want) {
+			t.Errorf("point %s payload = %v, want %v", uid, payloads[i], want)
+		}
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/kernel/events"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"go.uber.org/zap"
 )
 
@@ -24,6 +27,11 @@ type VectorStorer interface {
 	Store(ctx context.Context, namespace, uid string, embedding []float32, metadata map[string]interface{}) error
 }
 
+// CacheInvalidator drops cached answers for a namespace once new wisdom lands in it
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, namespace string)
+}
+
 // Config holds configuration for the Wisdom Worker
 type Config struct {
 	BatchSize     int
@@ -42,6 +50,12 @@ type WisdomManager struct {
 	embedder     Embedder
 	vectorStorer VectorStorer
 
+	// Memory event publisher (nil = disabled)
+	events *events.Publisher
+
+	// Cache invalidated after each crystallized batch (nil = none)
+	invalidator CacheInvalidator
+
 	// Buffer
 	eventBuffer []graph.TranscriptEvent
 	mu          sync.Mutex
@@ -67,6 +81,17 @@ func NewManager(cfg Config, graphClient *graph.Client, embedder Embedder, vector
 	}
 }
 
+// SetEventPublisher configures memory event publishing for crystallized summaries
+func (wm *WisdomManager) SetEventPublisher(publisher *events.Publisher) {
+	wm.events = publisher
+}
+
+// SetCacheInvalidator configures the cache dropped for a namespace after its
+// wisdom batches are written
+func (wm *WisdomManager) SetCacheInvalidator(invalidator CacheInvalidator) {
+	wm.invalidator = invalidator
+}
+
 // Start starts the background batch processing loop
 func (wm *WisdomManager) Start() {
 	wm.wg.Add(1)
@@ -142,14 +167,14 @@ func (wm *WisdomManager) processBatch(ctx context.Context, batch []graph.Transcr
 	for _, e := range batch {
 		ns := e.Namespace
 		if ns == "" {
-			ns = fmt.Sprintf("user_%s", e.UserID)
+			ns = nsutil.ForUser(e.UserID)
 		}
 		batchesByNS[ns] = append(batchesByNS[ns], e)
 	}
 
 	for ns, events := range batchesByNS {
 		start := time.Now()
-		summary, entities, err := wm.summarizeEvents(ctx, events)
+		summary, entities, relationships, err := wm.summarizeEvents(ctx, events)
 		if err != nil {
 			wm.logger.Error("Summarization failed", zap.String("namespace", ns), zap.Error(err))
 			continue
@@ -174,44 +199,79 @@ func (wm *WisdomManager) processBatch(ctx context.Context, batch []graph.Transcr
 		wm.logger.Info("Wisdom Layer: Summary generated",
 			zap.String("namespace", ns),
 			zap.Int("entities", len(entities)),
+			zap.Int("relationships", len(relationships)),
 			zap.String("summary_snippet", summary[:min(50, len(summary))]),
 			zap.Duration("duration", time.Since(start)))
 
 		// 3. Write Phase (High Density)
-		summaryUID, err := wm.graphClient.IngestWisdomBatch(ctx, ns, summary, entities)
-		if err != nil {
+		if _, err := wm.crystallize(ctx, ns, "", summary, entities, relationships); err != nil {
 			wm.logger.Error("Failed to persist wisdom batch", zap.String("namespace", ns), zap.Error(err))
-			continue
 		}
-		wm.logger.Info("Wisdom Batch crystallized to DGraph", zap.String("namespace", ns), zap.String("uid", summaryUID))
+	}
 
-		// 4. Generate and store embedding for Hybrid RAG
-		if wm.embedder != nil && wm.vectorStorer != nil && summaryUID != "" {
-			embedding, err := wm.embedder.Embed(summary)
-			if err != nil {
-				wm.logger.Warn("Failed to generate embedding for summary", zap.Error(err))
+	return nil
+}
+
+// IngestConversation crystallizes one summarized conversation into namespace
+// and returns the summary node's UID. The summary and the entities it creates
+// are linked to conversationID; userID is the speaker the summarizer calls "User".
+func (wm *WisdomManager) IngestConversation(ctx context.Context, namespace, userID, conversationID string, batch *graph.BatchSummary) (string, error) {
+	if batch == nil || strings.TrimSpace(batch.Summary) == "" {
+		return "", fmt.Errorf("conversation %s has no summary to crystallize", conversationID)
+	}
+	relationships := resolveSpeaker(batch.Relationships, []graph.TranscriptEvent{{UserID: userID}})
+	return wm.crystallize(ctx, namespace, conversationID, batch.Summary, batch.Entities, relationships)
+}
+
+// crystallize writes a summary and its entities to the graph, indexes the
+// summary for Hybrid RAG and invalidates the namespace's cached answers
+func (wm *WisdomManager) crystallize(ctx context.Context, ns, conversationID, summary string, entities []graph.ExtractedEntity, relationships []graph.ExtractedRelationship) (string, error) {
+	summaryUID, err := wm.graphClient.IngestConversationWisdom(ctx, ns, conversationID, summary, entities, relationships)
+	if err != nil {
+		return "", err
+	}
+	summaryName := "Batch Summary"
+	if conversationID != "" {
+		summaryName = "Conversation Summary"
+	}
+	wm.logger.Info("Wisdom Batch crystallized to DGraph",
+		zap.String("namespace", ns),
+		zap.String("uid", summaryUID),
+		zap.String("conversation_id", conversationID))
+	wm.events.NodeCreated(ns, summaryUID, summaryName, string(graph.NodeTypeFact))
+
+	// 4. Generate and store embedding for Hybrid RAG
+	if wm.embedder != nil && wm.vectorStorer != nil && summaryUID != "" {
+		embedding, err := wm.embedder.Embed(summary)
+		if err != nil {
+			wm.logger.Warn("Failed to generate embedding for summary", zap.Error(err))
+		} else {
+			// Store with metadata
+			metadata := map[string]interface{}{
+				"type": "summary",
+				"text": summary,
+			}
+			if conversationID != "" {
+				metadata["source_conversation_id"] = conversationID
+			}
+			if err := wm.vectorStorer.Store(ctx, ns, summaryUID, embedding, metadata); err != nil {
+				wm.logger.Warn("Failed to store embedding in vector index", zap.Error(err))
 			} else {
-				// Store with metadata
-				metadata := map[string]interface{}{
-					"type": "summary",
-					"text": summary,
-				}
-				if err := wm.vectorStorer.Store(ctx, ns, summaryUID, embedding, metadata); err != nil {
-					wm.logger.Warn("Failed to store embedding in vector index", zap.Error(err))
-				} else {
-					wm.logger.Info("Stored summary embedding in Qdrant",
-						zap.String("namespace", ns),
-						zap.String("uid", summaryUID),
-						zap.Int("dims", len(embedding)))
-				}
+				wm.logger.Info("Stored summary embedding in Qdrant",
+					zap.String("namespace", ns),
+					zap.String("uid", summaryUID),
+					zap.Int("dims", len(embedding)))
 			}
 		}
 	}
 
-	return nil
+	if wm.invalidator != nil {
+		wm.invalidator.Invalidate(ctx, ns)
+	}
+	return summaryUID, nil
 }
 
-func (wm *WisdomManager) summarizeEvents(ctx context.Context, events []graph.TranscriptEvent) (string, []graph.ExtractedEntity, error) {
+func (wm *WisdomManager) summarizeEvents(ctx context.Context, events []graph.TranscriptEvent) (string, []graph.ExtractedEntity, []graph.ExtractedRelationship, error) {
 	// Construct Prompt
 	var conversationText bytes.Buffer
 	for _, e := range events {
@@ -231,42 +291,77 @@ func (wm *WisdomManager) summarizeEvents(ctx context.Context, events []graph.Tra
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST",
 		wm.config.AIServiceURL+"/summarize_batch",
 		bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	client := &http.Client{Timeout: 60 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		// Fallback: Local entity extraction from conversation text
 		wm.logger.Warn("Summarize endpoint failed, using local extraction fallback")
-		return wm.localExtractEntities(events)
+		summary, entities, err := wm.localExtractEntities(events)
+		return summary, entities, nil, err
 	}
 
 	// Parse response (Expect standard entity extraction format + summary text)
 	type SummaryResponse struct {
-		Summary  string                  `json:"summary"`
-		Entities []graph.ExtractedEntity `json:"entities"`
+		Summary       string                        `json:"summary"`
+		Entities      []graph.ExtractedEntity       `json:"entities"`
+		Relationships []graph.ExtractedRelationship `json:"relationships"`
 	}
 
 	var res SummaryResponse
 	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
-	return res.Summary, res.Entities, nil
+	return res.Summary, res.Entities, resolveSpeaker(res.Relationships, events), nil
+}
+
+// speakerName is how the summarizer refers to the person talking
+const speakerName = "User"
+
+// resolveSpeaker replaces the summarizer's speaker placeholder with the user
+// who said it, so relationships like "User has_manager Bob" attach to the
+// user's node. When the batch mixes several users the speaker is ambiguous and
+// those relationships are dropped.
+func resolveSpeaker(relationships []graph.ExtractedRelationship, events []graph.TranscriptEvent) []graph.ExtractedRelationship {
+	userID := ""
+	for _, e := range events {
+		if userID != "" && e.UserID != userID {
+			userID = ""
+			break
+		}
+		userID = e.UserID
+	}
+
+	resolved := make([]graph.ExtractedRelationship, 0, len(relationships))
+	for _, r := range relationships {
+		if strings.EqualFold(r.From, speakerName) {
+			r.From = userID
+		}
+		if strings.EqualFold(r.To, speakerName) {
+			r.To = userID
+		}
+		if r.From == "" || r.To == "" {
+			continue
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved
 }
 
 // localExtractEntities extracts entities from conversation events when AI endpoint fails
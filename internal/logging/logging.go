@@ -0,0 +1,66 @@
+// Package logging builds the zap logger every binary starts with, so they all
+// honor the same LOG_FORMAT, LOG_LEVEL and LOG_SAMPLING settings.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config selects how a binary logs
+type Config struct {
+	Format   string // "json" or "console"
+	Level    string // debug, info, warn or error
+	Sampling bool   // Past 100 identical messages a second, keep every 100th
+}
+
+// FromEnv reads LOG_FORMAT (default json), LOG_LEVEL (default info) and
+// LOG_SAMPLING (default true)
+func FromEnv() Config {
+	cfg := Config{Format: "json", Level: "info", Sampling: true}
+	if format := os.Getenv("LOG_FORMAT"); format != "" {
+		cfg.Format = strings.ToLower(format)
+	}
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		cfg.Level = strings.ToLower(level)
+	}
+	if os.Getenv("LOG_SAMPLING") == "false" {
+		cfg.Sampling = false
+	}
+	return cfg
+}
+
+// New builds the logger cfg describes. Output goes to stderr, leaving stdout
+// free for protocols such as MCP over stdio.
+func New(cfg Config) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q", cfg.Level)
+	}
+
+	config := zap.Config{
+		Level:            zap.NewAtomicLevelAt(level),
+		Encoding:         "json",
+		EncoderConfig:    zap.NewProductionEncoderConfig(),
+		OutputPaths:      []string{"stderr"},
+		ErrorOutputPaths: []string{"stderr"},
+	}
+	switch cfg.Format {
+	case "json":
+	case "console":
+		config.Encoding = "console"
+		config.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q (want json or console)", cfg.Format)
+	}
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	if cfg.Sampling {
+		config.Sampling = &zap.SamplingConfig{Initial: 100, Thereafter: 100}
+	}
+
+	return config.Build()
+}
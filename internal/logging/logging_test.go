@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "")
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("LOG_SAMPLING", "")
+	if cfg := FromEnv(); cfg != (Config{Format: "json", Level: "info", Sampling: true}) {
+		t.Errorf("defaults = %+v", cfg)
+	}
+
+	t.Setenv("LOG_FORMAT", "Console")
+	t.Setenv("LOG_LEVEL", "DEBUG")
+	t.Setenv("LOG_SAMPLING", "false")
+	cfg := FromEnv()
+	if cfg != (Config{Format: "console", Level: "debug"}) {
+		t.Errorf("from env = %+v", cfg)
+	}
+	logger, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if !logger.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("debug logging is not enabled")
+	}
+}
+
+func TestNewRejectsUnknownSettings(t *testing.T) {
+	for _, cfg := range []Config{
+		{Format: "xml", Level: "info"},
+		{Format: "json", Level: "loud"},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded", cfg)
+		}
+	}
+}
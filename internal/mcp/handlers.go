@@ -4,13 +4,18 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+
 	"github.com/reflective-memory-kernel/internal/agent"
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/kernel"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"github.com/reflective-memory-kernel/internal/policy"
 	"go.uber.org/zap"
 )
@@ -19,6 +24,10 @@ import (
 type HandlerDependencies struct {
 	Agent  *agent.Agent
 	Logger *zap.Logger
+
+	// Graph, when set, replaces the agent's graph client for the memory,
+	// entity and document tools (e.g. a graph.MemStore in tests)
+	Graph graph.Store
 }
 
 // getGraphClient returns the graph client from agent
@@ -26,6 +35,30 @@ func (d *HandlerDependencies) getGraphClient() *graph.Client {
 	return d.Agent.GetGraphClient()
 }
 
+// getGraphStore returns Graph, or else the agent's graph client (nil if none)
+func (d *HandlerDependencies) getGraphStore() graph.Store {
+	if d.Graph != nil {
+		return d.Graph
+	}
+	if client := d.getGraphClient(); client != nil {
+		return client
+	}
+	return nil
+}
+
+// traversalMinActivation returns the namespace's default minimum activation
+// for graph_traverse
+func (d *HandlerDependencies) traversalMinActivation(ctx context.Context, namespace string) float64 {
+	if d.Agent != nil {
+		if mkClient := d.Agent.GetMKClient(); mkClient != nil {
+			if threshold := mkClient.GetActivationConfig(ctx, namespace).TraversalMinActivation; threshold > 0 {
+				return threshold
+			}
+		}
+	}
+	return graph.DefaultTraversalMinActivation
+}
+
 // getPolicyManager returns the policy manager from agent
 func (d *HandlerDependencies) getPolicyManager() *policy.PolicyManager {
 	return d.Agent.PolicyManager
@@ -37,11 +70,15 @@ func (d *HandlerDependencies) getPolicyManager() *policy.PolicyManager {
 func handleMemoryStore(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	namespace := getString(args, "namespace")
 	content := getString(args, "content")
-	nodeType := getString(args, "node_type")
 	name := getString(args, "name")
 	description := content
 
-	graphClient := deps.getGraphClient()
+	nodeType, err := graph.ParseNodeType(getString(args, "node_type"))
+	if err != nil {
+		return nil, err
+	}
+
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
@@ -56,7 +93,7 @@ func handleMemoryStore(ctx context.Context, deps *HandlerDependencies, args map[
 		Name:        name,
 		Description: description,
 		Namespace:   namespace,
-		DType:       []string{nodeType},
+		DType:       []string{string(nodeType)},
 	}
 
 	// Add optional tags
@@ -76,7 +113,7 @@ func handleMemoryStore(ctx context.Context, deps *HandlerDependencies, args map[
 	deps.Logger.Info("Memory stored via MCP",
 		zap.String("uid", uid),
 		zap.String("namespace", namespace),
-		zap.String("node_type", nodeType))
+		zap.String("node_type", string(nodeType)))
 
 	return map[string]interface{}{
 		"uid":       uid,
@@ -99,7 +136,7 @@ func handleMemorySearch(ctx context.Context, deps *HandlerDependencies, args map
 	}
 
 	results, err := mkClient.Consult(ctx, &graph.ConsultationRequest{
-		UserID:         getNamespaceUserID(namespace),
+		UserID:         getNamespaceUserID(ctx, namespace),
 		Namespace:      namespace,
 		Query:          query,
 		MaxResults:     limit,
@@ -129,23 +166,67 @@ func handleMemorySearch(ctx context.Context, deps *HandlerDependencies, args map
 	}, nil
 }
 
+// handleMemorySimilar finds memories related to an existing memory
+func handleMemorySimilar(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
+	namespace := getString(args, "namespace")
+	uid := getString(args, "uid")
+	limit := getInt(args, "limit", 10)
+
+	// Verify namespace access
+	userID := getNamespaceUserID(ctx, namespace)
+	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionRead); err != nil {
+		return nil, err
+	}
+
+	mkClient := deps.Agent.GetMKClient()
+	if mkClient == nil {
+		return nil, fmt.Errorf("memory kernel client not available")
+	}
+
+	similar, err := mkClient.FindSimilar(ctx, namespace, uid, limit)
+	if err != nil {
+		return nil, fmt.Errorf("similar search failed: %w", err)
+	}
+
+	nodes := make([]map[string]interface{}, 0, len(similar))
+	for _, s := range similar {
+		nodes = append(nodes, map[string]interface{}{
+			"uid":         s.Node.UID,
+			"name":        s.Node.Name,
+			"description": s.Node.Description,
+			"node_type":   s.Node.GetType(),
+			"score":       s.Score,
+		})
+	}
+
+	return map[string]interface{}{
+		"uid":     uid,
+		"results": nodes,
+		"count":   len(nodes),
+	}, nil
+}
+
 // handleMemoryDelete deletes a memory node
 func handleMemoryDelete(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	namespace := getString(args, "namespace")
 	uid := getString(args, "uid")
 
 	// Verify namespace access
-	userID := getNamespaceUserID(namespace)
+	userID := getNamespaceUserID(ctx, namespace)
 	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionDelete); err != nil {
 		return nil, err
 	}
+	if getBool(args, "dry_run", false) {
+		return planDelete(ctx, deps, namespace, uid)
+	}
 
-	graphClient := deps.getGraphClient()
-	if graphClient == nil {
-		return nil, fmt.Errorf("graph client not available")
+	mkClient := deps.Agent.GetMKClient()
+	if mkClient == nil {
+		return nil, fmt.Errorf("memory kernel client not available")
 	}
 
-	err := graphClient.DeleteNode(ctx, uid, namespace)
+	// Forget rather than just delete so the memory can't resurface via vector search or caches
+	result, err := mkClient.Forget(ctx, namespace, uid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete memory: %w", err)
 	}
@@ -155,11 +236,63 @@ func handleMemoryDelete(ctx context.Context, deps *HandlerDependencies, args map
 		zap.String("namespace", namespace))
 
 	return map[string]interface{}{
-		"status":   "deleted",
-		"uid":      uid,
+		"status":           "deleted",
+		"uid":              uid,
+		"vector_removed":   result.VectorRemoved,
+		"hot_cache_purged": result.HotCachePurged,
 	}, nil
 }
 
+// planDelete answers a dry_run delete with what deleting uid would remove;
+// nothing is deleted
+func planDelete(ctx context.Context, deps *HandlerDependencies, namespace, uid string) (interface{}, error) {
+	store := deps.getGraphStore()
+	if store == nil {
+		return nil, fmt.Errorf("graph client not available")
+	}
+
+	plan, err := graph.PlanDelete(ctx, store, namespace, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan deletion: %w", err)
+	}
+	return map[string]interface{}{
+		"status": "dry_run",
+		"uid":    uid,
+		"plan":   plan,
+	}, nil
+}
+
+// handleMemoryChanges reports what the memory learned in a namespace since a point in time
+func handleMemoryChanges(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
+	namespace := getString(args, "namespace")
+
+	// Verify namespace access
+	userID := getNamespaceUserID(ctx, namespace)
+	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionRead); err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-kernel.DefaultChangesWindow)
+	if raw := getString(args, "since", ""); raw != "" {
+		parsed, err := kernel.ParseSince(raw)
+		if err != nil {
+			return nil, fmt.Errorf("since must be RFC3339 or YYYY-MM-DD")
+		}
+		since = parsed
+	}
+
+	mkClient := deps.Agent.GetMKClient()
+	if mkClient == nil {
+		return nil, fmt.Errorf("memory kernel client not available")
+	}
+
+	summary, err := mkClient.RecentChanges(ctx, namespace, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load changes: %w", err)
+	}
+	return summary, nil
+}
+
 // handleMemoryList lists memories in a namespace
 func handleMemoryList(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	namespace := getString(args, "namespace")
@@ -167,33 +300,34 @@ func handleMemoryList(ctx context.Context, deps *HandlerDependencies, args map[s
 	limit := getInt(args, "limit", 50)
 	offset := getInt(args, "offset", 0)
 
-	graphClient := deps.getGraphClient()
+	// One page at most; GET /api/memories streams a whole namespace
+	opts := graph.ListNodesOpts{
+		Namespace: namespace,
+		Offset:    offset,
+		Limit:     min(limit, graph.DefaultNodePageSize),
+	}
+	if nodeType != "" {
+		t, err := graph.ParseNodeType(nodeType)
+		if err != nil {
+			return nil, err
+		}
+		opts.NodeType = t
+	}
+
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
 
-	// Use SearchNodes to find nodes in namespace
-	nodes, err := graphClient.SearchNodes(ctx, "*", namespace)
+	// Fetch just the requested page rather than the whole namespace
+	nodes, err := graphClient.ListNodes(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
-
-	// Filter by node type if specified
-	var filteredNodes []graph.Node
-	if nodeType != "" {
-		filteredNodes = make([]graph.Node, 0)
-		for _, node := range nodes {
-			if string(node.GetType()) == nodeType {
-				filteredNodes = append(filteredNodes, node)
-			}
-		}
-	} else {
-		filteredNodes = nodes
+		return nil, fmt.Errorf("list failed: %w", err)
 	}
 
 	// Convert to map format
-	resultNodes := make([]map[string]interface{}, 0)
-	for _, node := range filteredNodes {
+	resultNodes := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
 		resultNodes = append(resultNodes, map[string]interface{}{
 			"uid":         node.UID,
 			"name":        node.Name,
@@ -205,17 +339,6 @@ func handleMemoryList(ctx context.Context, deps *HandlerDependencies, args map[s
 		})
 	}
 
-	// Apply limit and offset
-	start := offset
-	end := offset + limit
-	if start >= len(resultNodes) {
-		resultNodes = []map[string]interface{}{}
-	} else if end > len(resultNodes) {
-		resultNodes = resultNodes[start:]
-	} else {
-		resultNodes = resultNodes[start:end]
-	}
-
 	return map[string]interface{}{
 		"results": resultNodes,
 		"total":   len(resultNodes),
@@ -224,6 +347,69 @@ func handleMemoryList(ctx context.Context, deps *HandlerDependencies, args map[s
 	}, nil
 }
 
+// handleMemoryRetag adds and removes tags across the listed memories, or
+// every memory in a namespace matching a type and tag filter
+func handleMemoryRetag(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
+	namespace := getString(args, "namespace")
+
+	// Verify namespace access
+	userID := getNamespaceUserID(ctx, namespace)
+	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	req := agent.RetagRequest{
+		Namespace: namespace,
+		UIDs:      getStrings(args, "uids"),
+		Tag:       getString(args, "tag", ""),
+		Add:       getStrings(args, "add"),
+		Remove:    getStrings(args, "remove"),
+	}
+	if nodeType := getString(args, "node_type", ""); nodeType != "" {
+		t, err := graph.ParseNodeType(nodeType)
+		if err != nil {
+			return nil, err
+		}
+		req.Type = t
+	}
+
+	result, err := deps.Agent.RetagNodes(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("retag failed: %w", err)
+	}
+
+	deps.Logger.Info("Memories retagged via MCP",
+		zap.String("namespace", namespace),
+		zap.Int("retagged", result.Retagged))
+	return result, nil
+}
+
+// handleMemoryPin pins a memory so it never decays or gets pruned, or unpins it
+func handleMemoryPin(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
+	namespace := getString(args, "namespace")
+	uid := getString(args, "uid")
+	pinned := getBool(args, "pinned", true)
+
+	// Verify namespace access
+	userID := getNamespaceUserID(ctx, namespace)
+	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionWrite); err != nil {
+		return nil, err
+	}
+
+	if err := deps.Agent.PinNode(ctx, namespace, uid, pinned); err != nil {
+		return nil, fmt.Errorf("pin failed: %w", err)
+	}
+
+	deps.Logger.Info("Memory pin changed via MCP",
+		zap.String("uid", uid),
+		zap.String("namespace", namespace),
+		zap.Bool("pinned", pinned))
+	return map[string]interface{}{
+		"uid":    uid,
+		"pinned": pinned,
+	}, nil
+}
+
 // ========== CHAT TOOL HANDLERS ==========
 
 // handleChatConsult performs a chat consultation
@@ -237,7 +423,7 @@ func handleChatConsult(ctx context.Context, deps *HandlerDependencies, args map[
 		conversationID = generateUUID()
 	}
 
-	response, err := deps.Agent.Chat(ctx, getNamespaceUserID(namespace), conversationID, namespace, message)
+	response, err := deps.Agent.Chat(ctx, getNamespaceUserID(ctx, namespace), conversationID, namespace, message)
 	if err != nil {
 		return nil, fmt.Errorf("chat failed: %w", err)
 	}
@@ -279,26 +465,51 @@ func handleConversationsDelete(ctx context.Context, deps *HandlerDependencies, a
 	conversationID := getString(args, "conversation_id")
 
 	// Verify namespace access
-	userID := getNamespaceUserID(namespace)
+	userID := getNamespaceUserID(ctx, namespace)
 	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionDelete); err != nil {
 		return nil, err
 	}
-
-	// Delete conversation node
-	graphClient := deps.getGraphClient()
-	if graphClient == nil {
-		return nil, fmt.Errorf("graph client not available")
+	if getBool(args, "dry_run", false) {
+		return planDelete(ctx, deps, namespace, conversationID)
 	}
 
-	err := graphClient.DeleteNode(ctx, conversationID, namespace)
+	// Delete the persisted turns along with the in-memory conversation
+	turnsDeleted := 0
+	conv, err := deps.Agent.LoadConversation(ctx, conversationID)
 	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	if conv != nil {
+		if conv.UserID != "" && conv.UserID != userID {
+			return nil, fmt.Errorf("access denied to conversation %s", conversationID)
+		}
+		if turnsDeleted, err = deps.Agent.DeleteConversation(ctx, namespace, conversationID); err != nil {
+			return nil, fmt.Errorf("failed to delete conversation turns: %w", err)
+		}
+	}
+
+	// Forget the conversation node, when the conversation has one
+	mkClient := deps.Agent.GetMKClient()
+	if mkClient == nil {
+		if conv != nil {
+			return conversationDeleted(conversationID, turnsDeleted), nil
+		}
+		return nil, fmt.Errorf("memory kernel client not available")
+	}
+	if _, err := mkClient.Forget(ctx, namespace, conversationID); err != nil && conv == nil {
 		return nil, fmt.Errorf("failed to delete conversation: %w", err)
 	}
 
+	return conversationDeleted(conversationID, turnsDeleted), nil
+}
+
+// conversationDeleted is the conversations_delete result
+func conversationDeleted(conversationID string, turnsDeleted int) map[string]interface{} {
 	return map[string]interface{}{
-		"status":         "deleted",
+		"status":          "deleted",
 		"conversation_id": conversationID,
-	}, nil
+		"turns_deleted":   turnsDeleted,
+	}
 }
 
 // ========== ENTITY TOOL HANDLERS ==========
@@ -307,28 +518,28 @@ func handleConversationsDelete(ctx context.Context, deps *HandlerDependencies, a
 func handleEntityCreate(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	namespace := getString(args, "namespace")
 	name := getString(args, "name")
-	entityType := getString(args, "entity_type")
 	description := getString(args, "description", "")
 
+	entityType, err := graph.ParseEntityType(getString(args, "entity_type"))
+	if err != nil {
+		return nil, err
+	}
+
 	// Build node
 	node := &graph.Node{
 		Name:        name,
 		Description: description,
 		Namespace:   namespace,
 		DType:       []string{"Entity"},
-		Attributes:  map[string]string{"entity_type": entityType},
+		Attributes:  map[string]string{"entity_type": string(entityType)},
 	}
 
 	// Add optional attributes
-	if attrs, ok := args["attributes"].(map[string]interface{}); ok {
-		for k, v := range attrs {
-			if vs, ok := v.(string); ok {
-				node.Attributes[k] = vs
-			}
-		}
+	for k, v := range stringMap(args, "attributes") {
+		node.Attributes[k] = v
 	}
 
-	uid, err := deps.getGraphClient().CreateNode(ctx, node)
+	uid, err := deps.getGraphStore().CreateNode(ctx, node)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create entity: %w", err)
 	}
@@ -339,7 +550,11 @@ func handleEntityCreate(ctx context.Context, deps *HandlerDependencies, args map
 			if r, ok := rel.(map[string]interface{}); ok {
 				relType := getString(r, "type")
 				target := getString(r, "target")
-				if err := deps.getGraphClient().CreateEdge(ctx, uid, target, graph.EdgeType(relType), graph.EdgeStatusCurrent); err != nil {
+				edgeType, err := graph.ParseEdgeType(relType)
+				if err == nil {
+					err = deps.getGraphStore().CreateEdge(ctx, uid, target, edgeType, graph.EdgeStatusCurrent)
+				}
+				if err != nil {
 					deps.Logger.Warn("Failed to create relationship",
 						zap.String("from", uid),
 						zap.String("to", target),
@@ -353,7 +568,7 @@ func handleEntityCreate(ctx context.Context, deps *HandlerDependencies, args map
 	deps.Logger.Info("Entity created via MCP",
 		zap.String("uid", uid),
 		zap.String("name", name),
-		zap.String("entity_type", entityType))
+		zap.String("entity_type", string(entityType)))
 
 	return map[string]interface{}{
 		"uid":    uid,
@@ -369,16 +584,25 @@ func handleEntityUpdate(ctx context.Context, deps *HandlerDependencies, args map
 	description := getString(args, "description", "")
 
 	// Verify namespace access
-	userID := getNamespaceUserID(namespace)
+	userID := getNamespaceUserID(ctx, namespace)
 	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionWrite); err != nil {
 		return nil, err
 	}
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
 
+	// The uid comes from the caller; only nodes of the checked namespace may change
+	node, err := graphClient.GetNode(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load entity: %w", err)
+	}
+	if node == nil || node.Namespace != namespace {
+		return nil, fmt.Errorf("entity %s not found in namespace %s", uid, namespace)
+	}
+
 	// Update description if provided
 	if description != "" {
 		if err := graphClient.UpdateDescription(ctx, uid, description); err != nil {
@@ -386,8 +610,12 @@ func handleEntityUpdate(ctx context.Context, deps *HandlerDependencies, args map
 		}
 	}
 
-	// Note: name and attribute updates would require direct DGraph mutations
-	// For now, we only support description updates
+	// Set attributes if provided; an empty value removes the key
+	if attrs := stringMap(args, "attributes"); len(attrs) > 0 {
+		if err := graphClient.SetAttributes(ctx, uid, attrs); err != nil {
+			return nil, fmt.Errorf("failed to update attributes: %w", err)
+		}
+	}
 
 	return map[string]interface{}{
 		"uid":    uid,
@@ -401,19 +629,23 @@ func handleEntityQuery(ctx context.Context, deps *HandlerDependencies, args map[
 	entityType := getString(args, "entity_type", "")
 	queryStr := getString(args, "query", "")
 	limit := getInt(args, "limit", 50)
+	attrs := stringMap(args, "attributes")
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
 
-	// Use SearchNodes to find entities
-	searchTerm := queryStr
-	if searchTerm == "" {
-		searchTerm = "*" // Match all if no query
+	var nodes []graph.Node
+	var err error
+	if len(attrs) > 0 {
+		// Attribute filters and the query string's name match are both
+		// resolved by the graph, before its limit applies
+		nodes, err = graphClient.FindByAttributes(ctx, namespace, attrs, queryStr, limit)
+	} else {
+		// An empty query lists the namespace's entities
+		nodes, err = graphClient.SearchNodes(ctx, queryStr, namespace)
 	}
-
-	nodes, err := graphClient.SearchNodes(ctx, searchTerm, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
@@ -445,6 +677,7 @@ func handleEntityQuery(ctx context.Context, deps *HandlerDependencies, args map[
 			"description": node.Description,
 			"type":        node.GetType(),
 			"activation":  node.Activation,
+			"attributes":  node.Attributes,
 		})
 	}
 
@@ -460,19 +693,23 @@ func handleRelationshipCreate(ctx context.Context, deps *HandlerDependencies, ar
 	fromUID := getString(args, "from_uid")
 	toUID := getString(args, "to_uid")
 	relType := getString(args, "relationship_type")
+	edgeType, err := graph.ParseEdgeType(relType)
+	if err != nil {
+		return nil, err
+	}
 
 	// Verify namespace access
-	userID := getNamespaceUserID(namespace)
+	userID := getNamespaceUserID(ctx, namespace)
 	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionWrite); err != nil {
 		return nil, err
 	}
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
 
-	err := graphClient.CreateEdge(ctx, fromUID, toUID, graph.EdgeType(relType), graph.EdgeStatusCurrent)
+	err = graphClient.CreateEdge(ctx, fromUID, toUID, edgeType, graph.EdgeStatusCurrent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create relationship: %w", err)
 	}
@@ -481,7 +718,16 @@ func handleRelationshipCreate(ctx context.Context, deps *HandlerDependencies, ar
 		"status":     "created",
 		"from_uid":   fromUID,
 		"to_uid":     toUID,
-		"rel_type":   relType,
+		"rel_type":   string(edgeType),
+	}, nil
+}
+
+// handleRelationshipTypes lists the edge vocabulary
+func handleRelationshipTypes(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
+	types := graph.EdgeTypeVocabulary()
+	return map[string]interface{}{
+		"relationship_types": types,
+		"count":              len(types),
 	}, nil
 }
 
@@ -492,9 +738,9 @@ func handleDocumentIngest(ctx context.Context, deps *HandlerDependencies, args m
 	namespace := getString(args, "namespace")
 	content := getString(args, "content")
 	filename := getString(args, "filename")
-	docType := getString(args, "document_type", "text")
+	contentType := graph.NormalizeContentType(getString(args, "document_type", graph.ContentTypeText))
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
@@ -504,7 +750,8 @@ func handleDocumentIngest(ctx context.Context, deps *HandlerDependencies, args m
 		Name:        filename,
 		Description: content,
 		Namespace:   namespace,
-		DType:       []string{docType, "Document"},
+		DType:       []string{string(graph.NodeTypeDocument)},
+		ContentType: contentType,
 	}
 
 	uid, err := graphClient.CreateNode(ctx, node)
@@ -514,53 +761,52 @@ func handleDocumentIngest(ctx context.Context, deps *HandlerDependencies, args m
 
 	deps.Logger.Info("Document ingested via MCP",
 		zap.String("filename", filename),
+		zap.String("content_type", contentType),
 		zap.String("namespace", namespace))
 
 	return map[string]interface{}{
 		"status":            "created",
 		"document_id":       uid,
 		"filename":          filename,
+		"content_type":      contentType,
 		"entities_extracted": 0,
 	}, nil
 }
 
-// handleDocumentList lists documents
+// handleDocumentList lists documents, optionally only those of one content type
 func handleDocumentList(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	namespace := getString(args, "namespace")
 	limit := getInt(args, "limit", 20)
+	contentType := getString(args, "content_type")
+	if contentType != "" {
+		contentType = graph.NormalizeContentType(contentType)
+	}
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
 
-	// Use SearchNodes to find documents
-	nodes, err := graphClient.SearchNodes(ctx, "*", namespace)
+	documents, err := graphClient.ListNodes(ctx, graph.ListNodesOpts{
+		Namespace:   namespace,
+		NodeType:    graph.NodeTypeDocument,
+		ContentType: contentType,
+		Fields:      []string{"name", "description", "content_type", "created_at"},
+		Limit:       limit,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
-	}
-
-	// Filter for Document type
-	documents := make([]graph.Node, 0)
-	for _, node := range nodes {
-		if node.GetType() == "Document" {
-			documents = append(documents, node)
-		}
-	}
-
-	// Apply limit
-	if len(documents) > limit {
-		documents = documents[:limit]
+		return nil, fmt.Errorf("failed to list documents: %w", err)
 	}
 
 	// Convert to result format
 	resultDocs := make([]map[string]interface{}, 0)
 	for _, doc := range documents {
 		resultDocs = append(resultDocs, map[string]interface{}{
-			"uid":         doc.UID,
-			"name":        doc.Name,
-			"description": doc.Description,
-			"created_at":  doc.CreatedAt,
+			"uid":          doc.UID,
+			"name":         doc.Name,
+			"description":  doc.Description,
+			"content_type": doc.ContentType,
+			"created_at":   doc.CreatedAt,
 		})
 	}
 
@@ -576,17 +822,20 @@ func handleDocumentDelete(ctx context.Context, deps *HandlerDependencies, args m
 	documentID := getString(args, "document_id")
 
 	// Verify namespace access
-	userID := getNamespaceUserID(namespace)
+	userID := getNamespaceUserID(ctx, namespace)
 	if err := checkNamespaceAccess(ctx, deps, userID, namespace, policy.ActionDelete); err != nil {
 		return nil, err
 	}
+	if getBool(args, "dry_run", false) {
+		return planDelete(ctx, deps, namespace, documentID)
+	}
 
-	graphClient := deps.getGraphClient()
-	if graphClient == nil {
-		return nil, fmt.Errorf("graph client not available")
+	mkClient := deps.Agent.GetMKClient()
+	if mkClient == nil {
+		return nil, fmt.Errorf("memory kernel client not available")
 	}
 
-	err := graphClient.DeleteNode(ctx, documentID, namespace)
+	_, err := mkClient.Forget(ctx, namespace, documentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete document: %w", err)
 	}
@@ -677,7 +926,10 @@ func handleGroupMembers(ctx context.Context, deps *HandlerDependencies, args map
 		return nil, fmt.Errorf("graph client not available")
 	}
 
-	members, err := graphClient.GetWorkspaceMembers(ctx, groupID)
+	offset := getInt(args, "offset", 0)
+	limit := getInt(args, "limit", 50)
+
+	members, total, err := graphClient.GetWorkspaceMembers(ctx, groupID, offset, limit)
 	if err != nil {
 		// Fallback: return empty list if GetWorkspaceMembers fails
 		members = []graph.WorkspaceMember{}
@@ -692,17 +944,23 @@ func handleGroupMembers(ctx context.Context, deps *HandlerDependencies, args map
 			userID = member.User.UID
 			username = member.User.Name
 		}
-		resultMembers = append(resultMembers, map[string]interface{}{
+		entry := map[string]interface{}{
 			"user_id":  userID,
 			"username": username,
 			"role":     member.Role,
-		})
+		}
+		if !member.JoinedAt.IsZero() {
+			entry["joined_at"] = member.JoinedAt
+		}
+		resultMembers = append(resultMembers, entry)
 	}
 
 	return map[string]interface{}{
 		"group_id": groupID,
 		"members":  resultMembers,
 		"count":    len(resultMembers),
+		"total":    total,
+		"offset":   offset,
 	}, nil
 }
 
@@ -746,14 +1004,11 @@ func handleGroupShareLink(ctx context.Context, deps *HandlerDependencies, args m
 }
 
 // ========== ADMIN TOOL HANDLERS ==========
+// Admin tools carry ScopeAdmin; the server rejects callers without it before
+// a handler runs.
 
 // handleAdminUsersList lists all users (admin only)
 func handleAdminUsersList(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
-	// Check admin permission
-	if !isAdmin(ctx) {
-		return nil, fmt.Errorf("admin access required")
-	}
-
 	// TODO: Implement user listing via graph client
 	return map[string]interface{}{
 		"users": []interface{}{},
@@ -763,10 +1018,6 @@ func handleAdminUsersList(ctx context.Context, deps *HandlerDependencies, args m
 
 // handleAdminUserUpdate updates a user (admin only)
 func handleAdminUserUpdate(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
-	if !isAdmin(ctx) {
-		return nil, fmt.Errorf("admin access required")
-	}
-
 	username := getString(args, "username")
 	role := getString(args, "role", "user")
 	action := getString(args, "action", "update")
@@ -781,10 +1032,6 @@ func handleAdminUserUpdate(ctx context.Context, deps *HandlerDependencies, args
 
 // handleAdminMetrics returns system metrics (admin only)
 func handleAdminMetrics(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
-	if !isAdmin(ctx) {
-		return nil, fmt.Errorf("admin access required")
-	}
-
 	// Get agent stats
 	stats := deps.Agent.GetStats()
 
@@ -795,10 +1042,6 @@ func handleAdminMetrics(ctx context.Context, deps *HandlerDependencies, args map
 
 // handleAdminPoliciesList lists policies (admin only)
 func handleAdminPoliciesList(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
-	if !isAdmin(ctx) {
-		return nil, fmt.Errorf("admin access required")
-	}
-
 	// TODO: Implement policy listing
 	return map[string]interface{}{
 		"policies": []interface{}{},
@@ -808,10 +1051,6 @@ func handleAdminPoliciesList(ctx context.Context, deps *HandlerDependencies, arg
 
 // handleAdminPoliciesSet creates or updates a policy (admin only)
 func handleAdminPoliciesSet(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
-	if !isAdmin(ctx) {
-		return nil, fmt.Errorf("admin access required")
-	}
-
 	id := getString(args, "id")
 	effect := getString(args, "effect")
 
@@ -854,19 +1093,40 @@ func getInt(args map[string]interface{}, key string, defaultVal int) int {
 	return defaultVal
 }
 
-// getNamespaceUserID extracts user ID from namespace
-func getNamespaceUserID(namespace string) string {
-	if len(namespace) > 5 && namespace[:5] == "user_" {
-		return namespace[5:]
+// stringMap extracts an object argument as string key/values, skipping non-string values
+func stringMap(args map[string]interface{}, key string) map[string]string {
+	obj, ok := args[key].(map[string]interface{})
+	if !ok {
+		return nil
 	}
-	if len(namespace) > 6 && namespace[:6] == "group_" {
-		return namespace[6:]
+	result := make(map[string]string, len(obj))
+	for k, v := range obj {
+		if s, ok := v.(string); ok {
+			result[k] = s
+		}
 	}
-	return namespace
+	return result
+}
+
+// getNamespaceUserID returns the user acting on namespace: the authenticated caller
+// when known, otherwise the owner of a personal namespace. A group namespace never
+// names a user, so without an authenticated caller it yields "".
+func getNamespaceUserID(ctx context.Context, ns string) string {
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	if userID, ok := nsutil.UserID(ns); ok {
+		return userID
+	}
+	return ""
 }
 
 // checkNamespaceAccess verifies user has access to namespace
 func checkNamespaceAccess(ctx context.Context, deps *HandlerDependencies, userID, namespace string, action policy.Action) error {
+	if userID == "" {
+		return fmt.Errorf("access denied to namespace %s: no authenticated user", namespace)
+	}
+
 	// Build user context
 	userCtx := policy.UserContext{
 		UserID:        userID,
@@ -876,6 +1136,15 @@ func checkNamespaceAccess(ctx context.Context, deps *HandlerDependencies, userID
 		Attributes:    map[string]string{},
 	}
 
+	// Group namespaces are reachable only through verified membership
+	if groupID, ok := nsutil.GroupID(namespace); ok {
+		if mkClient := deps.Agent.GetMKClient(); mkClient != nil {
+			if isMember, err := mkClient.IsWorkspaceMember(ctx, namespace, userID); err == nil && isMember {
+				userCtx.Groups = append(userCtx.Groups, groupID)
+			}
+		}
+	}
+
 	// Create a dummy resource for policy check
 	resource := &graph.Node{
 		Namespace: namespace,
@@ -890,14 +1159,6 @@ func checkNamespaceAccess(ctx context.Context, deps *HandlerDependencies, userID
 	return nil
 }
 
-// isAdmin checks if the current user is an admin
-func isAdmin(ctx context.Context) bool {
-	if role, ok := ctx.Value("user_role").(string); ok {
-		return role == "admin"
-	}
-	return false
-}
-
 // generateName creates a name from content
 func generateName(content string) string {
 	if len(content) > 50 {
@@ -929,29 +1190,33 @@ func handleGraphTraverse(ctx context.Context, deps *HandlerDependencies, args ma
 	maxDepth := getInt(args, "max_depth", 3)
 	decayFactor := getFloat(args, "decay_factor", 0.7)
 	limit := getInt(args, "limit", 50)
+	cursor := getString(args, "cursor", "")
+	minActivation := getFloat(args, "min_activation", deps.traversalMinActivation(ctx, namespace))
 
-	graphClient := deps.getGraphClient()
-	if graphClient == nil {
+	store := deps.getGraphStore()
+	if store == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
 
+	// Out-of-range values are clamped by the traversal
 	opts := graph.SpreadActivationOpts{
 		StartUID:      startNode,
 		Namespace:     namespace,
 		MaxHops:       maxDepth,
 		DecayFactor:   decayFactor,
 		MaxResults:    limit,
-		MinActivation: 0.05,
+		MinActivation: minActivation,
+		Cursor:        cursor,
 	}
 
-	results, err := graphClient.SpreadActivation(ctx, opts)
+	page, err := store.SpreadActivationPage(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("traversal failed: %w", err)
 	}
 
 	// Format results
 	nodes := make([]map[string]interface{}, 0)
-	for _, r := range results {
+	for _, r := range page.Nodes {
 		nodes = append(nodes, map[string]interface{}{
 			"uid":        r.Node.UID,
 			"name":       r.Node.Name,
@@ -962,9 +1227,12 @@ func handleGraphTraverse(ctx context.Context, deps *HandlerDependencies, args ma
 	}
 
 	return map[string]interface{}{
-		"start_node": startNode,
-		"results":    nodes,
-		"count":      len(nodes),
+		"start_node":     startNode,
+		"min_activation": minActivation,
+		"results":        nodes,
+		"count":          len(nodes),
+		"total_count":    page.TotalCount,
+		"next_cursor":    page.NextCursor,
 	}, nil
 }
 
@@ -979,31 +1247,30 @@ func handleGraphNeighbors(ctx context.Context, deps *HandlerDependencies, args m
 		return nil, fmt.Errorf("graph client not available")
 	}
 
-	// Use the traversal spread activation with max_depth=1 to get direct neighbors
-	opts := graph.SpreadActivationOpts{
-		StartUID:      nodeID,
-		Namespace:     namespace,
-		MaxHops:       1,
-		DecayFactor:   1.0, // No decay for direct neighbors
-		MaxResults:    limit,
-		MinActivation: 0.0,
-	}
-
-	results, err := graphClient.SpreadActivation(ctx, opts)
+	// Query the edge predicates directly so each neighbor carries the edge
+	// linking it, not just the endpoint
+	results, err := graphClient.GetNeighbors(ctx, namespace, nodeID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get neighbors: %w", err)
 	}
 
-	neighbors := make([]map[string]interface{}, 0)
+	neighbors := make([]map[string]interface{}, 0, len(results))
 	for _, r := range results {
-		if r.Node.UID != nodeID { // Exclude the start node
-			neighbors = append(neighbors, map[string]interface{}{
-				"uid":         r.Node.UID,
-				"name":        r.Node.Name,
-				"type":        r.Node.GetType(),
-				"description": r.Node.Description,
-			})
+		neighbor := map[string]interface{}{
+			"uid":          r.Node.UID,
+			"name":         r.Node.Name,
+			"type":         r.Node.GetType(),
+			"description":  r.Node.Description,
+			"relationship": r.EdgeType,
+			"predicate":    r.Predicate,
+			"direction":    r.Direction,
+			"label":        r.Label,
+			"weight":       r.Weight,
+		}
+		if r.Confidence > 0 {
+			neighbor["confidence"] = r.Confidence
 		}
+		neighbors = append(neighbors, neighbor)
 	}
 
 	return map[string]interface{}{
@@ -1020,7 +1287,7 @@ func handleGraphFindPath(ctx context.Context, deps *HandlerDependencies, args ma
 	target := getString(args, "target")
 	maxHops := getInt(args, "max_hops", 5)
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
@@ -1030,6 +1297,7 @@ func handleGraphFindPath(ctx context.Context, deps *HandlerDependencies, args ma
 		StartUID:   source,
 		MaxHops:    maxHops,
 		MaxResults: 100,
+		Timeout:    graph.DefaultExpandTimeout,
 	}
 
 	result, err := graphClient.ExpandFromNode(ctx, opts)
@@ -1061,11 +1329,14 @@ func handleGraphFindPath(ctx context.Context, deps *HandlerDependencies, args ma
 		}
 	}
 
+	// truncated: the search ran out of time, so a missing target may just
+	// be further out than it got
 	return map[string]interface{}{
-		"source": source,
-		"target": target,
-		"path":   path,
-		"length": len(path),
+		"source":    source,
+		"target":    target,
+		"path":      path,
+		"length":    len(path),
+		"truncated": result.Truncated,
 	}, nil
 }
 
@@ -1125,7 +1396,7 @@ func handleDocumentSummarize(ctx context.Context, deps *HandlerDependencies, arg
 	documentID := getString(args, "document_id")
 	maxLength := getInt(args, "max_length", 200)
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
@@ -1154,65 +1425,100 @@ func handleDocumentSummarize(ctx context.Context, deps *HandlerDependencies, arg
 	}, nil
 }
 
-// handleDocumentExtract extracts entities from a document
+// handleDocumentExtract extracts entities from a document, or from every
+// document of a content type when no document_id is given. With both, the
+// document's entities are returned only if it has that content type.
 func handleDocumentExtract(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	namespace := getString(args, "namespace")
 	documentID := getString(args, "document_id")
 	entityType := getString(args, "entity_type", "")
+	contentType := getString(args, "content_type")
+	if contentType != "" {
+		contentType = graph.NormalizeContentType(contentType)
+	}
+	if documentID == "" && contentType == "" {
+		return nil, fmt.Errorf("document_id or content_type is required")
+	}
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
 
-	// Get the document node
-	docNode, err := graphClient.GetNode(ctx, documentID)
-	if err != nil {
-		return nil, fmt.Errorf("document not found: %w", err)
+	// The documents to read: the one asked for, or the namespace's documents
+	// of the content type
+	root := "eq(namespace, $namespace)"
+	filter := "type(Document)"
+	if contentType != "" {
+		filter += " AND eq(content_type, $content_type)"
 	}
-
-	if docNode.Namespace != namespace {
-		return nil, fmt.Errorf("access denied to document")
+	if documentID != "" {
+		docNode, err := graphClient.GetNode(ctx, documentID)
+		if err != nil {
+			return nil, fmt.Errorf("document not found: %w", err)
+		}
+		if docNode.Namespace != namespace {
+			return nil, fmt.Errorf("access denied to document")
+		}
+		root = "uid(" + docNode.UID + ")"
+		filter += " AND eq(namespace, $namespace)"
 	}
 
 	// Query for related entities
-	query := fmt.Sprintf(`
-		{
-			nodes(func: uid(%s)) {
-				related_to @filter(eq(namespace, "%s")) {
-					uid
-					name
-					description
-					dgraph.type
-					activation
-				}
+	query := fmt.Sprintf(`query DocumentEntities($namespace: string, $content_type: string) {
+		docs(func: %s) @filter(%s) {
+			uid
+			related_to @filter(eq(namespace, $namespace)) {
+				uid
+				name
+				description
+				dgraph.type
+				activation
 			}
 		}
-	`, documentID, namespace)
+	}`, root, filter)
 
-	respBytes, err := graphClient.Query(ctx, query, nil)
+	respBytes, err := graphClient.Query(ctx, query, map[string]string{
+		"$namespace":    namespace,
+		"$content_type": contentType,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
 
-	// Parse and filter results
+	var result struct {
+		Docs []struct {
+			UID       string       `json:"uid"`
+			RelatedTo []graph.Node `json:"related_to"`
+		} `json:"docs"`
+	}
+	if err := json.Unmarshal(respBytes, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse entities: %w", err)
+	}
+
+	// Entities shared by several documents are listed once
 	entities := make([]map[string]interface{}, 0)
-	// The response is a raw JSON byte array, so we need to parse it
-	var result map[string][]map[string]interface{}
-	if err := json.Unmarshal(respBytes, &result); err == nil {
-		if nodes, ok := result["nodes"]; ok && len(nodes) > 0 {
-			if related, ok := nodes[0]["related_to"].([]map[string]interface{}); ok {
-				for _, entity := range related {
-					if entityType == "" || entity["dgraph.type"] == entityType {
-						entities = append(entities, entity)
-					}
-				}
+	seen := make(map[string]bool)
+	for _, doc := range result.Docs {
+		for _, entity := range doc.RelatedTo {
+			if seen[entity.UID] || (entityType != "" && !entity.HasType(graph.NodeType(entityType))) {
+				continue
 			}
+			seen[entity.UID] = true
+			entities = append(entities, map[string]interface{}{
+				"uid":         entity.UID,
+				"name":        entity.Name,
+				"description": entity.Description,
+				"type":        entity.GetType(),
+				"activation":  entity.Activation,
+			})
 		}
 	}
 
 	return map[string]interface{}{
 		"document_id":  documentID,
+		"content_type": contentType,
+		"documents":    len(result.Docs),
 		"entities":     entities,
 		"count":        len(entities),
 		"filter_type":  entityType,
@@ -1224,7 +1530,7 @@ func handleDocumentClassify(ctx context.Context, deps *HandlerDependencies, args
 	namespace := getString(args, "namespace")
 	documentID := getString(args, "document_id")
 
-	graphClient := deps.getGraphClient()
+	graphClient := deps.getGraphStore()
 	if graphClient == nil {
 		return nil, fmt.Errorf("graph client not available")
 	}
@@ -1278,22 +1584,54 @@ func handleDocumentClassify(ctx context.Context, deps *HandlerDependencies, args
 
 // ========== CONVERSATION MANAGEMENT HANDLERS ==========
 
+// loadConversation finds a conversation in the agent's memory or, failing
+// that, in the turns persisted to the graph
+func loadConversation(ctx context.Context, deps *HandlerDependencies, conversationID string) (*agent.Conversation, error) {
+	conv, err := deps.Agent.LoadConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	if conv == nil {
+		return nil, fmt.Errorf("conversation not found")
+	}
+	return conv, nil
+}
+
+// loadOwnConversation loads a conversation of the authenticated caller,
+// refusing anyone else's
+func loadOwnConversation(ctx context.Context, deps *HandlerDependencies, conversationID string) (*agent.Conversation, error) {
+	userID, _ := ctx.Value("user_id").(string)
+	if userID == "" {
+		return nil, fmt.Errorf("user not authenticated")
+	}
+	conv, err := loadConversation(ctx, deps, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conv.UserID != "" && conv.UserID != userID {
+		return nil, fmt.Errorf("access denied to conversation %s", conversationID)
+	}
+	return conv, nil
+}
+
+// shortID returns the first 8 characters of id, for filenames and labels
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
 // handleConversationExport exports conversation history
 func handleConversationExport(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	_ = getString(args, "namespace") // Reserved for validation
 	conversationID := getString(args, "conversation_id")
 	format := getString(args, "format", "json")
 
-	// Get user ID from context for authentication
-	userID := ctx.Value("user_id")
-	if userID == nil {
-		return nil, fmt.Errorf("user not authenticated")
-	}
-
-	// Get conversation from Agent
-	conv := deps.Agent.GetConversation(conversationID)
-	if conv == nil {
-		return nil, fmt.Errorf("conversation not found")
+	// Get the caller's conversation from Agent (memory, or the graph after a restart)
+	conv, err := loadOwnConversation(ctx, deps, conversationID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build export data
@@ -1329,7 +1667,7 @@ func handleConversationExport(ctx context.Context, deps *HandlerDependencies, ar
 		return map[string]interface{}{
 			"format":   "markdown",
 			"content":  sb.String(),
-			"filename": fmt.Sprintf("%s.md", conversationID[:8]),
+			"filename": fmt.Sprintf("%s.md", shortID(conversationID)),
 		}, nil
 	case "text":
 		var sb strings.Builder
@@ -1342,7 +1680,7 @@ func handleConversationExport(ctx context.Context, deps *HandlerDependencies, ar
 		return map[string]interface{}{
 			"format":   "text",
 			"content":  sb.String(),
-			"filename": fmt.Sprintf("%s.txt", conversationID[:8]),
+			"filename": fmt.Sprintf("%s.txt", shortID(conversationID)),
 		}, nil
 	default: // json
 		return map[string]interface{}{
@@ -1358,9 +1696,9 @@ func handleConversationSummarize(ctx context.Context, deps *HandlerDependencies,
 	conversationID := getString(args, "conversation_id")
 	maxPoints := getInt(args, "max_points", 5)
 
-	conv := deps.Agent.GetConversation(conversationID)
-	if conv == nil {
-		return nil, fmt.Errorf("conversation not found")
+	conv, err := loadOwnConversation(ctx, deps, conversationID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Extract key points from user queries
@@ -1396,10 +1734,9 @@ func handleConversationBranch(ctx context.Context, deps *HandlerDependencies, ar
 	_ = getString(args, "message_id", "") // Reserved for future use
 	branchName := getString(args, "branch_name", "")
 
-	// Get source conversation
-	conv := deps.Agent.GetConversation(conversationID)
-	if conv == nil {
-		return nil, fmt.Errorf("source conversation not found")
+	// Get source conversation; only the caller's own can be branched
+	if _, err := loadOwnConversation(ctx, deps, conversationID); err != nil {
+		return nil, fmt.Errorf("source %w", err)
 	}
 
 	// Generate new conversation ID
@@ -1408,7 +1745,7 @@ func handleConversationBranch(ctx context.Context, deps *HandlerDependencies, ar
 	// Copy context up to the branch point
 	// For now, we'll create a simple branch reference
 	if branchName == "" {
-		branchName = fmt.Sprintf("Branch from %s", conversationID[:8])
+		branchName = fmt.Sprintf("Branch from %s", shortID(conversationID))
 	}
 
 	return map[string]interface{}{
@@ -1432,7 +1769,7 @@ func handleUserProfileGet(ctx context.Context, deps *HandlerDependencies, args m
 	userIDStr, _ := userID.(string)
 
 	// Try to get user data from Redis
-	userData, err := deps.Agent.RedisClient.Get(ctx, "user:"+userIDStr).Result()
+	userData, err := deps.Agent.RedisClient.Get(ctx, profileKey(userIDStr)).Result()
 	if err != nil {
 		// Return minimal profile if not found
 		return map[string]interface{}{
@@ -1441,13 +1778,54 @@ func handleUserProfileGet(ctx context.Context, deps *HandlerDependencies, args m
 		}, nil
 	}
 
-	var profile map[string]interface{}
-	json.Unmarshal([]byte(userData), &profile)
+	return decodeProfile(userData)
+}
 
+// maxProfileUpdateAttempts bounds how often a profile update is retried after
+// another update to the same profile slipped in between its read and write
+const maxProfileUpdateAttempts = 3
+
+// errProfileConflict is returned when a profile update loses to a concurrent
+// one, or names a version that is no longer current
+var errProfileConflict = errors.New("concurrent modification of profile")
+
+// profileKey is where a user's profile is stored; "user:" holds their
+// password hash
+func profileKey(userID string) string {
+	return "user_profile:" + userID
+}
+
+// decodeProfile parses a stored profile, refusing anything but a JSON object
+// so a corrupt profile is reported rather than silently replaced
+func decodeProfile(data string) (map[string]interface{}, error) {
+	profile := make(map[string]interface{})
+	if data == "" {
+		return profile, nil
+	}
+	if err := json.Unmarshal([]byte(data), &profile); err != nil || profile == nil {
+		return nil, fmt.Errorf("stored profile is not a JSON object")
+	}
 	return profile, nil
 }
 
-// handleUserProfileUpdate updates user profile
+// mergeProfile deep-merges src into dst: nested objects are merged key by
+// key, anything else replaces what dst held
+func mergeProfile(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				mergeProfile(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// handleUserProfileUpdate merges the given fields into the user's profile.
+// The read-merge-write runs under a Redis WATCH so concurrent updates from two
+// tabs both land; passing the version last read makes the update fail with a
+// conflict instead if the profile has changed since.
 func handleUserProfileUpdate(ctx context.Context, deps *HandlerDependencies, args map[string]interface{}) (interface{}, error) {
 	userID := ctx.Value("user_id")
 	if userID == nil {
@@ -1455,41 +1833,70 @@ func handleUserProfileUpdate(ctx context.Context, deps *HandlerDependencies, arg
 	}
 
 	userIDStr, _ := userID.(string)
+	key := profileKey(userIDStr)
 
-	// Get existing profile
-	existingData, _ := deps.Agent.RedisClient.Get(ctx, "user:"+userIDStr).Result()
-	existing := make(map[string]interface{})
-	if existingData != "" {
-		json.Unmarshal([]byte(existingData), &existing)
-	}
-
-	// Update with new values
 	updates := make(map[string]interface{})
 	if displayName, ok := args["display_name"].(string); ok {
-		existing["display_name"] = displayName
 		updates["display_name"] = displayName
 	}
 	if bio, ok := args["bio"].(string); ok {
-		existing["bio"] = bio
 		updates["bio"] = bio
 	}
 	if prefs, ok := args["preferences"].(map[string]interface{}); ok {
-		if existing["preferences"] == nil {
-			existing["preferences"] = make(map[string]interface{})
+		updates["preferences"] = prefs
+	}
+	expected := int64(-1)
+	if _, ok := args["version"]; ok {
+		expected = int64(getFloat(args, "version", 0))
+	}
+
+	var version int64
+	update := func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Result()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to read profile: %w", err)
 		}
-		for k, v := range prefs {
-			existing["preferences"].(map[string]interface{})[k] = v
+		profile, err := decodeProfile(data)
+		if err != nil {
+			return err
 		}
-		updates["preferences"] = prefs
+
+		version = int64(getFloat(profile, "version", 0))
+		if expected >= 0 && version != expected {
+			return fmt.Errorf("%w: version is %d, not %d", errProfileConflict, version, expected)
+		}
+		mergeProfile(profile, updates)
+		version++
+		profile["version"] = version
+
+		encoded, err := json.Marshal(profile)
+		if err != nil {
+			return fmt.Errorf("failed to encode profile: %w", err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, encoded, 0)
+			return nil
+		})
+		return err
 	}
 
-	// Save back to Redis
-	data, _ := json.Marshal(existing)
-	deps.Agent.RedisClient.Set(ctx, "user:"+userIDStr, data, 0)
+	var err error
+	for attempt := 0; attempt < maxProfileUpdateAttempts; attempt++ {
+		if err = deps.Agent.RedisClient.Watch(ctx, update, key); err != redis.TxFailedErr {
+			break
+		}
+	}
+	if err == redis.TxFailedErr {
+		return nil, fmt.Errorf("%w: gave up after %d attempts", errProfileConflict, maxProfileUpdateAttempts)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	return map[string]interface{}{
 		"user_id":  userIDStr,
 		"updated":  updates,
+		"version":  version,
 		"status":   "success",
 	}, nil
 }
@@ -1504,7 +1911,7 @@ func handleUserPreferencesGet(ctx context.Context, deps *HandlerDependencies, ar
 	userIDStr, _ := userID.(string)
 
 	// Get user profile and extract preferences
-	userData, err := deps.Agent.RedisClient.Get(ctx, "user:"+userIDStr).Result()
+	userData, err := deps.Agent.RedisClient.Get(ctx, profileKey(userIDStr)).Result()
 	if err != nil {
 		// Return default preferences
 		return map[string]interface{}{
@@ -1529,6 +1936,26 @@ func handleUserPreferencesGet(ctx context.Context, deps *HandlerDependencies, ar
 	}, nil
 }
 
+// Helper function to get string list values, skipping non-strings
+func getStrings(args map[string]interface{}, key string) []string {
+	list, _ := args[key].([]interface{})
+	var values []string
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// Helper function to get bool values
+func getBool(args map[string]interface{}, key string, defaultVal bool) bool {
+	if b, ok := args[key].(bool); ok {
+		return b
+	}
+	return defaultVal
+}
+
 // Helper function to get float values
 func getFloat(args map[string]interface{}, key string, defaultVal float64) float64 {
 	if val, ok := args[key]; ok {
@@ -1555,6 +1982,10 @@ func RegisterHandlers() map[string]func(context.Context, *HandlerDependencies, m
 		"memory_search":         handleMemorySearch,
 		"memory_delete":         handleMemoryDelete,
 		"memory_list":           handleMemoryList,
+		"memory_similar":        handleMemorySimilar,
+		"memory_changes":        handleMemoryChanges,
+		"memory_retag":          handleMemoryRetag,
+		"memory_pin":            handleMemoryPin,
 
 		// Chat Tools
 		"chat_consult":          handleChatConsult,
@@ -1566,6 +1997,7 @@ func RegisterHandlers() map[string]func(context.Context, *HandlerDependencies, m
 		"entity_update":        handleEntityUpdate,
 		"entity_query":         handleEntityQuery,
 		"relationship_create":  handleRelationshipCreate,
+		"relationship_types":   handleRelationshipTypes,
 
 		// Document Tools
 		"document_ingest":       handleDocumentIngest,
@@ -0,0 +1,571 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/agent"
+	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/policy"
+	"github.com/reflective-memory-kernel/internal/testutil/fakeredis"
+)
+
+// graphOnlyStore stands in for the graph: it holds turns of conversations the
+// agent has no memory of
+type graphOnlyStore struct {
+	turns map[string][]graph.ConversationTurn
+}
+
+func (s *graphOnlyStore) AppendConversationTurn(ctx context.Context, turn *graph.ConversationTurn) error {
+	s.turns[turn.ConversationID] = append(s.turns[turn.ConversationID], *turn)
+	return nil
+}
+
+func (s *graphOnlyStore) GetConversationTurns(ctx context.Context, conversationID string) ([]graph.ConversationTurn, error) {
+	return s.turns[conversationID], nil
+}
+
+func (s *graphOnlyStore) DeleteConversationTurns(ctx context.Context, namespace, conversationID string) (int, error) {
+	var kept []graph.ConversationTurn
+	for _, turn := range s.turns[conversationID] {
+		if turn.Namespace != namespace {
+			kept = append(kept, turn)
+		}
+	}
+	deleted := len(s.turns[conversationID]) - len(kept)
+	s.turns[conversationID] = kept
+	return deleted, nil
+}
+
+func TestConversationExportFromGraph(t *testing.T) {
+	started := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	store := &graphOnlyStore{turns: map[string][]graph.ConversationTurn{
+		"conv_restart": {
+			{ConversationID: "conv_restart", UserID: "alice", Timestamp: started,
+				UserQuery: "where do I work?", Response: "At Acme.", LatencyMs: 120},
+			{ConversationID: "conv_restart", UserID: "alice", Timestamp: started.Add(time.Minute),
+				UserQuery: "since when?", Response: "Since 2021.", LatencyMs: 80},
+		},
+	}}
+	a := &agent.Agent{}
+	a.SetConversationStore(store)
+	deps := &HandlerDependencies{Agent: a, Logger: zap.NewNop()}
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+
+	export := func(ctx context.Context, format string) (map[string]interface{}, error) {
+		t.Helper()
+		result, err := handleConversationExport(ctx, deps, map[string]interface{}{
+			"conversation_id": "conv_restart",
+			"format":          format,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[string]interface{}), nil
+	}
+
+	md, err := export(ctx, "markdown")
+	if err != nil {
+		t.Fatalf("markdown export: %v", err)
+	}
+	wantMD := "# Conversation: conv_restart\n\nStarted: 2026-03-14 09:30:00\n\n" +
+		"## Turn 1\n**User:** where do I work?\n\n**AI:** At Acme.\n\n" +
+		"## Turn 2\n**User:** since when?\n\n**AI:** Since 2021.\n\n"
+	if md["content"] != wantMD {
+		t.Errorf("markdown content = %q, want %q", md["content"], wantMD)
+	}
+	if md["filename"] != "conv_res.md" {
+		t.Errorf("markdown filename = %v", md["filename"])
+	}
+
+	text, err := export(ctx, "text")
+	if err != nil {
+		t.Fatalf("text export: %v", err)
+	}
+	if content, _ := text["content"].(string); !strings.Contains(content, "User: since when?\nAI: Since 2021.\n") {
+		t.Errorf("text content = %q", content)
+	}
+
+	js, err := export(ctx, "json")
+	if err != nil {
+		t.Fatalf("json export: %v", err)
+	}
+	raw, _ := json.Marshal(js["data"])
+	var data struct {
+		UserID    string `json:"user_id"`
+		TurnCount int    `json:"turn_count"`
+		Turns     []struct {
+			Query   string `json:"query"`
+			Latency int64  `json:"latency"`
+		} `json:"turns"`
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatalf("decode json export: %v", err)
+	}
+	if data.UserID != "alice" || data.TurnCount != 2 || data.Turns[0].Query != "where do I work?" || data.Turns[0].Latency != 120 {
+		t.Errorf("json export = %+v", data)
+	}
+
+	// Another user can't export alice's conversation by guessing its ID
+	bob := context.WithValue(context.Background(), "user_id", "bob")
+	if _, err := export(bob, "json"); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Errorf("bob's export error = %v, want access denied", err)
+	}
+
+	if _, err := handleConversationExport(ctx, deps, map[string]interface{}{"conversation_id": "conv_unknown"}); err == nil ||
+		!strings.Contains(err.Error(), "not found") {
+		t.Errorf("unknown conversation error = %v, want not found", err)
+	}
+}
+
+func TestConversationToolsCheckOwner(t *testing.T) {
+	store := &graphOnlyStore{turns: map[string][]graph.ConversationTurn{
+		"conv_alice": {
+			{ConversationID: "conv_alice", UserID: "alice", Namespace: "user_alice", UserQuery: "where do I work?"},
+			{ConversationID: "conv_alice", UserID: "alice", Namespace: "user_alice", UserQuery: "since when?"},
+		},
+	}}
+	a := &agent.Agent{PolicyManager: &policy.PolicyManager{}}
+	a.SetConversationStore(store)
+	deps := &HandlerDependencies{Agent: a, Logger: zap.NewNop()}
+	alice := context.WithValue(context.Background(), "user_id", "alice")
+	bob := context.WithValue(context.Background(), "user_id", "bob")
+	args := map[string]interface{}{"namespace": "user_bob", "conversation_id": "conv_alice"}
+
+	for name, handle := range map[string]func(context.Context, *HandlerDependencies, map[string]interface{}) (interface{}, error){
+		"summarize": handleConversationSummarize,
+		"branch":    handleConversationBranch,
+		"delete":    handleConversationsDelete,
+	} {
+		if _, err := handle(bob, deps, args); err == nil || !strings.Contains(err.Error(), "access denied") {
+			t.Errorf("bob's %s error = %v, want access denied", name, err)
+		}
+		if _, err := handle(context.Background(), deps, args); err == nil {
+			t.Errorf("unauthenticated %s was allowed", name)
+		}
+	}
+	if len(store.turns["conv_alice"]) != 2 {
+		t.Fatalf("bob deleted alice's turns: %v", store.turns["conv_alice"])
+	}
+
+	summary, err := handleConversationSummarize(alice, deps, args)
+	if err != nil || summary.(map[string]interface{})["turn_count"] != 2 {
+		t.Fatalf("alice's summarize = %v, %v", summary, err)
+	}
+
+	result, err := handleConversationsDelete(alice, deps, map[string]interface{}{"namespace": "user_alice", "conversation_id": "conv_alice"})
+	if err != nil {
+		t.Fatalf("alice's delete: %v", err)
+	}
+	if deleted := result.(map[string]interface{})["turns_deleted"]; deleted != 2 || len(store.turns["conv_alice"]) != 0 {
+		t.Errorf("deleted %v turns, left %v", deleted, store.turns["conv_alice"])
+	}
+	if _, err := handleConversationExport(alice, deps, map[string]interface{}{"conversation_id": "conv_alice"}); err == nil {
+		t.Error("deleted conversation is still held in memory")
+	}
+}
+
+func TestWriteToolsRejectUnknownTypes(t *testing.T) {
+	deps := &HandlerDependencies{Logger: zap.NewNop()}
+	ctx := context.Background()
+
+	_, err := handleMemoryStore(ctx, deps, map[string]interface{}{
+		"namespace": "user_alice", "content": "likes tea", "node_type": "Fakt",
+	})
+	if !errors.Is(err, graph.ErrUnknownNodeType) {
+		t.Errorf("memory_store error = %v, want ErrUnknownNodeType", err)
+	}
+
+	_, err = handleEntityCreate(ctx, deps, map[string]interface{}{
+		"namespace": "user_alice", "name": "Acme", "entity_type": "Compny",
+	})
+	if !errors.Is(err, graph.ErrUnknownEntityType) {
+		t.Errorf("entity_create error = %v, want ErrUnknownEntityType", err)
+	}
+}
+
+func TestEntityToolsOverMemStore(t *testing.T) {
+	store := graph.NewMemStore()
+	deps := &HandlerDependencies{Logger: zap.NewNop(), Graph: store}
+	ctx := context.Background()
+
+	create := func(args map[string]interface{}) string {
+		t.Helper()
+		result, err := handleEntityCreate(ctx, deps, args)
+		if err != nil {
+			t.Fatalf("entity_create: %v", err)
+		}
+		return result.(map[string]interface{})["uid"].(string)
+	}
+	bob := create(map[string]interface{}{"namespace": "user_alice", "name": "Bob", "entity_type": "Person"})
+	alice := create(map[string]interface{}{
+		"namespace": "user_alice", "name": "Alice", "entity_type": "Person",
+		"relationships": []interface{}{map[string]interface{}{"type": "reports_to", "target": bob}},
+	})
+	create(map[string]interface{}{"namespace": "user_alice", "name": "Acme", "entity_type": "Organization"})
+	create(map[string]interface{}{"namespace": "user_eve", "name": "Bobby", "entity_type": "Person"})
+
+	result, err := handleEntityQuery(ctx, deps, map[string]interface{}{
+		"namespace": "user_alice", "attributes": map[string]interface{}{"entity_type": "Person"}, "query": "bob",
+	})
+	if err != nil {
+		t.Fatalf("entity_query: %v", err)
+	}
+	entities := result.(map[string]interface{})["entities"].([]map[string]interface{})
+	if len(entities) != 1 || entities[0]["uid"] != bob {
+		t.Errorf("entity_query found %v, want only Bob", entities)
+	}
+
+	expanded, err := store.ExpandFromNode(ctx, graph.ExpandOpts{StartUID: alice, Namespace: "user_alice", MaxHops: 1})
+	if err != nil {
+		t.Fatalf("ExpandFromNode: %v", err)
+	}
+	if len(expanded.Edges) != 1 || expanded.Edges[0].ToUID != bob || expanded.Edges[0].Predicate != "has_manager" {
+		t.Errorf("Alice's edges = %+v, want has_manager to Bob", expanded.Edges)
+	}
+}
+
+func TestEntityUpdateStaysInNamespace(t *testing.T) {
+	store := graph.NewMemStore()
+	deps := &HandlerDependencies{
+		Agent:  &agent.Agent{PolicyManager: &policy.PolicyManager{}},
+		Logger: zap.NewNop(),
+		Graph:  store,
+	}
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+	eveCtx := context.WithValue(context.Background(), "user_id", "eve")
+
+	uid, err := store.CreateNode(ctx, &graph.Node{
+		Name: "Mallory", Namespace: "user_eve", DType: []string{"Entity"},
+		Attributes: map[string]string{"role": "spy"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = handleEntityUpdate(ctx, deps, map[string]interface{}{
+		"namespace": "user_alice", "uid": uid,
+		"description": "edited", "attributes": map[string]interface{}{"role": "friend"},
+	})
+	if err == nil {
+		t.Fatal("update through another namespace was allowed")
+	}
+	node, _ := store.GetNode(ctx, uid)
+	if node.Description == "edited" || node.Attributes["role"] != "spy" {
+		t.Errorf("node changed through another namespace: %+v", node)
+	}
+
+	if _, err := handleEntityUpdate(eveCtx, deps, map[string]interface{}{
+		"namespace": "user_eve", "uid": uid, "attributes": map[string]interface{}{"role": "friend"},
+	}); err != nil {
+		t.Fatalf("update in the node's namespace: %v", err)
+	}
+	if node, _ := store.GetNode(ctx, uid); node.Attributes["role"] != "friend" {
+		t.Errorf("attributes = %v, want the update", node.Attributes)
+	}
+}
+
+func TestEntityQueryMatchesNameBeforeLimit(t *testing.T) {
+	store := graph.NewMemStore()
+	deps := &HandlerDependencies{Logger: zap.NewNop(), Graph: store}
+	ctx := context.Background()
+
+	for _, name := range []string{"Ann", "Ben", "Cal", "Dee Bob"} {
+		if _, err := handleEntityCreate(ctx, deps, map[string]interface{}{
+			"namespace": "user_alice", "name": name, "entity_type": "Person",
+			"attributes": map[string]interface{}{"team": "core"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := handleEntityQuery(ctx, deps, map[string]interface{}{
+		"namespace": "user_alice", "attributes": map[string]interface{}{"team": "core"}, "query": "bob", "limit": 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities := result.(map[string]interface{})["entities"].([]map[string]interface{})
+	if len(entities) != 1 || entities[0]["name"] != "Dee Bob" {
+		t.Errorf("entity_query found %v, want Dee Bob past the limit", entities)
+	}
+}
+
+func TestDocumentsFilteredByContentType(t *testing.T) {
+	store := graph.NewMemStore()
+	deps := &HandlerDependencies{Logger: zap.NewNop(), Graph: store}
+	ctx := context.Background()
+
+	for _, doc := range []struct{ filename, docType string }{
+		{"handbook.pdf", "PDF"},
+		{"notes.txt", ""},
+		{"invoice.pdf", "pdf"},
+	} {
+		args := map[string]interface{}{"namespace": "user_alice", "content": "...", "filename": doc.filename}
+		if doc.docType != "" {
+			args["document_type"] = doc.docType
+		}
+		if _, err := handleDocumentIngest(ctx, deps, args); err != nil {
+			t.Fatalf("document_ingest %s: %v", doc.filename, err)
+		}
+	}
+	store.CreateNode(ctx, &graph.Node{Name: "Acme", Namespace: "user_alice", DType: []string{"Entity"}})
+
+	list := func(args map[string]interface{}) []map[string]interface{} {
+		t.Helper()
+		args["namespace"] = "user_alice"
+		result, err := handleDocumentList(ctx, deps, args)
+		if err != nil {
+			t.Fatalf("document_list: %v", err)
+		}
+		return result.(map[string]interface{})["documents"].([]map[string]interface{})
+	}
+
+	all := list(map[string]interface{}{})
+	if len(all) != 3 {
+		t.Fatalf("document_list found %v, want the three documents", all)
+	}
+	for _, doc := range all {
+		uid := doc["uid"].(string)
+		node, _ := store.GetNode(ctx, uid)
+		if len(node.DType) != 1 || node.DType[0] != string(graph.NodeTypeDocument) {
+			t.Errorf("%s typed %v, want only Document", doc["name"], node.DType)
+		}
+	}
+
+	pdfs := list(map[string]interface{}{"content_type": "PDF"})
+	if len(pdfs) != 2 || pdfs[0]["name"] != "handbook.pdf" || pdfs[1]["name"] != "invoice.pdf" {
+		t.Errorf("PDFs = %v, want handbook.pdf and invoice.pdf", pdfs)
+	}
+	if texts := list(map[string]interface{}{"content_type": "text"}); len(texts) != 1 || texts[0]["name"] != "notes.txt" {
+		t.Errorf("text documents = %v, want notes.txt", texts)
+	}
+
+	// Extraction by content type reads the namespace's documents, not every
+	// document of that type
+	var query string
+	store.QueryFunc = func(q string, vars map[string]string) ([]byte, error) {
+		query = q
+		return []byte(`{"docs": []}`), nil
+	}
+	if _, err := handleDocumentExtract(ctx, deps, map[string]interface{}{"namespace": "user_alice", "content_type": "pdf"}); err != nil {
+		t.Fatalf("document_extract: %v", err)
+	}
+	if !strings.Contains(query, "docs(func: eq(namespace, $namespace)) @filter(type(Document) AND eq(content_type, $content_type))") {
+		t.Errorf("document_extract query is not rooted at the namespace:\n%s", query)
+	}
+}
+
+func TestConcurrentProfileUpdatesLoseNoWrites(t *testing.T) {
+	rdb := fakeredis.New(t)
+	deps := &HandlerDependencies{Agent: &agent.Agent{RedisClient: rdb}, Logger: zap.NewNop()}
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+	if err := rdb.Set(ctx, "user:alice", "$2a$10$passwordhash", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two tabs' worth of updates, each setting its own nested preference
+	const tabs = 8
+	errs := make([]error, tabs)
+	var wg sync.WaitGroup
+	for i := 0; i < tabs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = handleUserProfileUpdate(ctx, deps, map[string]interface{}{
+				"preferences": map[string]interface{}{
+					"notifications": map[string]interface{}{fmt.Sprintf("tab%d", i): true},
+				},
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	result, err := handleUserProfileGet(ctx, deps, nil)
+	if err != nil {
+		t.Fatalf("user_profile_get: %v", err)
+	}
+	profile := result.(map[string]interface{})
+	notifications, _ := profile["preferences"].(map[string]interface{})["notifications"].(map[string]interface{})
+	landed := 0
+	for i, err := range errs {
+		_, stored := notifications[fmt.Sprintf("tab%d", i)]
+		switch {
+		case err == nil && !stored:
+			t.Errorf("tab%d: update succeeded but was lost", i)
+		case err != nil && !errors.Is(err, errProfileConflict):
+			t.Errorf("tab%d: %v, want success or a conflict", i, err)
+		case err != nil && stored:
+			t.Errorf("tab%d: update reported a conflict but was stored", i)
+		case err == nil:
+			landed++
+		}
+	}
+	if landed == 0 || profile["version"] != float64(landed) {
+		t.Errorf("version = %v after %d successful updates", profile["version"], landed)
+	}
+	if hash, _ := rdb.Get(ctx, "user:alice").Result(); hash != "$2a$10$passwordhash" {
+		t.Errorf("password hash overwritten with %q", hash)
+	}
+}
+
+func TestProfileUpdateConflictsAndMerges(t *testing.T) {
+	rdb := fakeredis.New(t)
+	deps := &HandlerDependencies{Agent: &agent.Agent{RedisClient: rdb}, Logger: zap.NewNop()}
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+	update := func(args map[string]interface{}) error {
+		_, err := handleUserProfileUpdate(ctx, deps, args)
+		return err
+	}
+
+	if err := update(map[string]interface{}{"preferences": map[string]interface{}{
+		"theme": "dark", "editor": map[string]interface{}{"font": "mono", "size": 12.0},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := update(map[string]interface{}{"version": 1.0, "preferences": map[string]interface{}{
+		"editor": map[string]interface{}{"size": 14.0},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tab still holding version 1 is refused
+	err := update(map[string]interface{}{"version": 1.0, "bio": "stale"})
+	if !errors.Is(err, errProfileConflict) || toolErrorKind(err) != ToolErrorConflict {
+		t.Errorf("stale update error = %v, want a conflict", err)
+	}
+
+	stored, _ := rdb.Get(ctx, profileKey("alice")).Result()
+	var profile struct {
+		Bio         string `json:"bio"`
+		Version     int    `json:"version"`
+		Preferences struct {
+			Theme  string                 `json:"theme"`
+			Editor map[string]interface{} `json:"editor"`
+		} `json:"preferences"`
+	}
+	if err := json.Unmarshal([]byte(stored), &profile); err != nil {
+		t.Fatal(err)
+	}
+	if profile.Version != 2 || profile.Bio != "" || profile.Preferences.Theme != "dark" ||
+		profile.Preferences.Editor["font"] != "mono" || profile.Preferences.Editor["size"] != 14.0 {
+		t.Errorf("stored profile = %s", stored)
+	}
+
+	// A corrupt profile is reported, not reset
+	rdb.Set(ctx, profileKey("alice"), "not json", 0)
+	if err := update(map[string]interface{}{"bio": "hi"}); err == nil {
+		t.Error("update merged into a corrupt profile")
+	}
+	if stored, _ := rdb.Get(ctx, profileKey("alice")).Result(); stored != "not json" {
+		t.Errorf("corrupt profile replaced with %q", stored)
+	}
+}
+
+func TestDeleteToolsDryRun(t *testing.T) {
+	store := graph.NewMemStore()
+	deps := &HandlerDependencies{
+		Agent:  &agent.Agent{PolicyManager: &policy.PolicyManager{}},
+		Logger: zap.NewNop(),
+		Graph:  store,
+	}
+	ctx := context.WithValue(context.Background(), "user_id", "alice")
+
+	bob, _ := store.CreateNode(ctx, &graph.Node{Name: "Bob", Namespace: "user_alice", DType: []string{"Entity"}})
+	acme, _ := store.CreateNode(ctx, &graph.Node{Name: "Acme", Namespace: "user_alice", DType: []string{"Entity"}})
+	doc, _ := store.CreateNode(ctx, &graph.Node{Name: "handbook.pdf", Namespace: "user_alice", DType: []string{"Document"}})
+	store.CreateEdge(ctx, bob, acme, graph.EdgeTypeWorksAt, graph.EdgeStatusCurrent)
+	store.CreateEdge(ctx, doc, acme, graph.EdgeTypeRelatedTo, graph.EdgeStatusCurrent)
+	eve, _ := store.CreateNode(ctx, &graph.Node{Name: "Eve", Namespace: "user_eve"})
+
+	for _, c := range []struct {
+		tool   string
+		handle func(context.Context, *HandlerDependencies, map[string]interface{}) (interface{}, error)
+		args   map[string]interface{}
+		want   graph.PlannedEdge
+	}{
+		{"memory_delete", handleMemoryDelete, map[string]interface{}{"uid": bob},
+			graph.PlannedEdge{FromUID: bob, FromName: "Bob", ToUID: acme, ToName: "Acme", Predicate: "works_at"}},
+		{"document_delete", handleDocumentDelete, map[string]interface{}{"document_id": doc},
+			graph.PlannedEdge{FromUID: doc, FromName: "handbook.pdf", ToUID: acme, ToName: "Acme", Predicate: "related_to"}},
+	} {
+		c.args["namespace"] = "user_alice"
+		c.args["dry_run"] = true
+		result, err := c.handle(ctx, deps, c.args)
+		if err != nil {
+			t.Fatalf("%s dry run: %v", c.tool, err)
+		}
+		plan := result.(map[string]interface{})["plan"].(*graph.DeletePlan)
+		if plan.NodeCount != 1 || plan.EdgeCount != 1 || plan.Edges[0] != c.want {
+			t.Errorf("%s plan = %+v, want one node and edge %+v", c.tool, plan, c.want)
+		}
+	}
+
+	for _, uid := range []string{bob, acme, doc} {
+		if _, err := store.GetNode(ctx, uid); err != nil {
+			t.Errorf("dry run deleted %s: %v", uid, err)
+		}
+	}
+	expanded, _ := store.ExpandFromNode(ctx, graph.ExpandOpts{StartUID: bob, MaxHops: 1})
+	if len(expanded.Edges) != 1 {
+		t.Errorf("dry run removed Bob's edges: %+v", expanded.Edges)
+	}
+
+	_, err := handleConversationsDelete(ctx, deps, map[string]interface{}{
+		"namespace": "user_alice", "conversation_id": eve, "dry_run": true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "namespace mismatch") {
+		t.Errorf("planning a node from another namespace: err = %v, want namespace mismatch", err)
+	}
+}
+
+func TestGraphTraverseMinActivation(t *testing.T) {
+	store := graph.NewMemStore()
+	deps := &HandlerDependencies{Logger: zap.NewNop(), Graph: store}
+	ctx := context.Background()
+
+	// A chain: each hop keeps 0.7 (decay) x 0.5 (edge weight) of the activation
+	var chain []string
+	for _, name := range []string{"Alice", "Bob", "Carol", "Dave"} {
+		uid, _ := store.CreateNode(ctx, &graph.Node{Name: name, Namespace: "user_alice"})
+		if len(chain) > 0 {
+			store.CreateEdge(ctx, chain[len(chain)-1], uid, graph.EdgeTypeKnows, graph.EdgeStatusCurrent)
+		}
+		chain = append(chain, uid)
+	}
+
+	reached := func(args map[string]interface{}) []string {
+		t.Helper()
+		args["namespace"] = "user_alice"
+		args["start_node"] = chain[0]
+		result, err := handleGraphTraverse(ctx, deps, args)
+		if err != nil {
+			t.Fatalf("graph_traverse %v: %v", args, err)
+		}
+		var names []string
+		for _, n := range result.(map[string]interface{})["results"].([]map[string]interface{}) {
+			names = append(names, n["name"].(string))
+		}
+		return names
+	}
+
+	// Carol is at 0.1225, Dave at 0.043, under the 0.05 default
+	if got := reached(map[string]interface{}{}); strings.Join(got, ",") != "Alice,Bob,Carol" {
+		t.Errorf("default min_activation reached %v, want Alice,Bob,Carol", got)
+	}
+	if got := reached(map[string]interface{}{"min_activation": 0.2}); strings.Join(got, ",") != "Alice,Bob" {
+		t.Errorf("min_activation 0.2 reached %v, want Alice,Bob", got)
+	}
+	// Nonsensical values are clamped rather than rejected or looping forever
+	got := reached(map[string]interface{}{"min_activation": -1.0, "decay_factor": -0.5, "max_depth": 0})
+	if len(got) != 4 {
+		t.Errorf("clamped traversal reached %v, want the whole chain", got)
+	}
+}
@@ -1,7 +1,11 @@
 // Package mcp defines tool schemas for MCP
 package mcp
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
 
 // ToolSchemas returns all available tool definitions
 func ToolSchemas() []Tool {
@@ -24,7 +28,7 @@ func ToolSchemas() []Tool {
 						},
 						"node_type": map[string]interface{}{
 							"type":        "string",
-							"enum":        []string{"Entity", "Fact", "Event", "Insight", "Pattern"},
+							"enum":        graph.MemoryNodeTypeNames(),
 							"description": "Type of node to create",
 						},
 						"name": map[string]interface{}{
@@ -40,6 +44,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "content", "node_type"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -65,6 +70,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "query"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -80,10 +86,15 @@ func ToolSchemas() []Tool {
 							"type":        "string",
 							"description": "UID of the node to delete",
 						},
+						"dry_run": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Report what would be deleted without deleting it; defaults to false",
+						},
 					},
 					"required": []string{"namespace", "uid"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -111,6 +122,116 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace"},
 				},
 			},
+			Scope: ScopeRead,
+		},
+		{
+			Definition: ToolDefinition{
+				Name:        "memory_similar",
+				Description: "Find memories related to an existing memory by semantic similarity",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"namespace": map[string]interface{}{
+							"type":        "string",
+							"description": "Namespace the memory belongs to",
+						},
+						"uid": map[string]interface{}{
+							"type":        "string",
+							"description": "UID of the memory to find related items for",
+						},
+						"limit": map[string]interface{}{
+							"type":        "integer",
+							"description": "Maximum results to return",
+							"default":     10,
+						},
+					},
+					"required": []string{"namespace", "uid"},
+				},
+			},
+			Scope: ScopeRead,
+		},
+		{
+			Definition: ToolDefinition{
+				Name:        "memory_changes",
+				Description: "Show what the memory learned recently: nodes created/updated and edges added since a time, grouped by day and type",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"namespace": map[string]interface{}{
+							"type": "string",
+						},
+						"since": map[string]interface{}{
+							"type":        "string",
+							"description": "RFC3339 timestamp or YYYY-MM-DD date (default: 7 days ago)",
+						},
+					},
+					"required": []string{"namespace"},
+				},
+			},
+			Scope: ScopeRead,
+		},
+		{
+			Definition: ToolDefinition{
+				Name:        "memory_retag",
+				Description: "Add and remove tags across several memories at once: the listed UIDs, or every memory matching a type and tag",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"namespace": map[string]interface{}{
+							"type": "string",
+						},
+						"uids": map[string]interface{}{
+							"type":        "array",
+							"items":       map[string]string{"type": "string"},
+							"description": "Memories to retag; omit to retag every memory matching node_type and tag",
+						},
+						"node_type": map[string]interface{}{
+							"type":        "string",
+							"description": "Only retag memories of this type",
+						},
+						"tag": map[string]interface{}{
+							"type":        "string",
+							"description": "Only retag memories already having this tag",
+						},
+						"add": map[string]interface{}{
+							"type":        "array",
+							"items":       map[string]string{"type": "string"},
+							"description": "Tags to add",
+						},
+						"remove": map[string]interface{}{
+							"type":        "array",
+							"items":       map[string]string{"type": "string"},
+							"description": "Tags to remove",
+						},
+					},
+					"required": []string{"namespace"},
+				},
+			},
+			Scope: ScopeWrite,
+		},
+		{
+			Definition: ToolDefinition{
+				Name:        "memory_pin",
+				Description: "Pin a memory so it never fades or gets pruned and ranks higher in recall (e.g. allergies, core preferences), or unpin it",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"namespace": map[string]interface{}{
+							"type": "string",
+						},
+						"uid": map[string]interface{}{
+							"type":        "string",
+							"description": "UID of the memory to pin",
+						},
+						"pinned": map[string]interface{}{
+							"type":        "boolean",
+							"description": "false to unpin; defaults to true",
+						},
+					},
+					"required": []string{"namespace", "uid"},
+				},
+			},
+			Scope: ScopeWrite,
 		},
 
 		// ========== CHAT TOOLS ==========
@@ -137,6 +258,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "message"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -156,6 +278,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -170,10 +293,15 @@ func ToolSchemas() []Tool {
 						"conversation_id": map[string]interface{}{
 							"type": "string",
 						},
+						"dry_run": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Report what would be deleted without deleting it; defaults to false",
+						},
 					},
 					"required": []string{"namespace", "conversation_id"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 
 		// ========== ENTITY TOOLS ==========
@@ -193,7 +321,8 @@ func ToolSchemas() []Tool {
 						},
 						"entity_type": map[string]interface{}{
 							"type":        "string",
-							"description": "Type of entity (Person, Organization, Location, Concept, etc.)",
+							"enum":        graph.EntityTypeNames(),
+							"description": "Type of entity",
 						},
 						"description": map[string]interface{}{
 							"type":        "string",
@@ -207,7 +336,7 @@ func ToolSchemas() []Tool {
 								"properties": map[string]interface{}{
 									"type": map[string]interface{}{
 										"type": "string",
-										"enum": []string{"KNOWS", "LIKES", "WORKS_AT", "WORKS_ON", "FRIEND_OF", "RELATED_TO", "PART_OF"},
+										"enum": graph.EdgeTypeNames(),
 									},
 									"target": map[string]interface{}{
 										"type": "string",
@@ -223,6 +352,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "name", "entity_type"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -244,12 +374,14 @@ func ToolSchemas() []Tool {
 							"type": "string",
 						},
 						"attributes": map[string]interface{}{
-							"type": "object",
+							"type":        "object",
+							"description": "Attribute key/values to set; an empty value removes the key",
 						},
 					},
 					"required": []string{"namespace", "uid"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -269,6 +401,10 @@ func ToolSchemas() []Tool {
 							"type":        "string",
 							"description": "DGraph query string",
 						},
+						"attributes": map[string]interface{}{
+							"type":        "object",
+							"description": "Only return entities whose attributes match every key/value exactly",
+						},
 						"limit": map[string]interface{}{
 							"type":        "integer",
 							"default":     50,
@@ -277,6 +413,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -295,13 +432,26 @@ func ToolSchemas() []Tool {
 							"type": "string",
 						},
 						"relationship_type": map[string]interface{}{
-							"type": "string",
-							"enum": []string{"KNOWS", "LIKES", "WORKS_AT", "WORKS_ON", "FRIEND_OF", "RELATED_TO", "PART_OF"},
+							"type":        "string",
+							"enum":        graph.EdgeTypeNames(),
+							"description": "Edge type; see relationship_types for what each means and its reverse",
 						},
 					},
 					"required": []string{"namespace", "from_uid", "to_uid", "relationship_type"},
 				},
 			},
+			Scope: ScopeWrite,
+		},
+		{
+			Definition: ToolDefinition{
+				Name:        "relationship_types",
+				Description: "List the relationship types relationship_create accepts, with their predicates and reverse directions",
+				InputSchema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{},
+				},
+			},
+			Scope: ScopeRead,
 		},
 
 		// ========== DOCUMENT TOOLS ==========
@@ -326,12 +476,13 @@ func ToolSchemas() []Tool {
 						"document_type": map[string]interface{}{
 							"type":        "string",
 							"default":     "text",
-							"description": "Type of document (text, markdown, json, etc.)",
+							"description": "Content type of the document (text, pdf, markdown, json, etc.), stored as its content_type",
 						},
 					},
 					"required": []string{"namespace", "content", "filename"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -347,10 +498,15 @@ func ToolSchemas() []Tool {
 							"type":        "integer",
 							"default":     20,
 						},
+						"content_type": map[string]interface{}{
+							"type":        "string",
+							"description": "Only documents of this content type, e.g. \"pdf\" (optional)",
+						},
 					},
 					"required": []string{"namespace"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -365,10 +521,15 @@ func ToolSchemas() []Tool {
 						"document_id": map[string]interface{}{
 							"type": "string",
 						},
+						"dry_run": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Report what would be deleted without deleting it; defaults to false",
+						},
 					},
 					"required": []string{"namespace", "document_id"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 
 		// ========== GROUP TOOLS ==========
@@ -391,6 +552,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"name"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -401,6 +563,7 @@ func ToolSchemas() []Tool {
 					"properties":    map[string]interface{}{},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -424,21 +587,31 @@ func ToolSchemas() []Tool {
 					"required": []string{"group_id", "username", "role"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
 				Name:        "group_members",
-				Description: "List all members of a group",
+				Description: "List members of a group, admins first then by join time",
 				InputSchema: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
 						"group_id": map[string]interface{}{
 							"type": "string",
 						},
+						"limit": map[string]interface{}{
+							"type":    "integer",
+							"default": 50,
+						},
+						"offset": map[string]interface{}{
+							"type":    "integer",
+							"default": 0,
+						},
 					},
 					"required": []string{"group_id"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -464,6 +637,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"group_id"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 
 		// ========== ADMIN TOOLS ==========
@@ -476,6 +650,7 @@ func ToolSchemas() []Tool {
 					"properties": map[string]interface{}{},
 				},
 			},
+			Scope: ScopeAdmin,
 		},
 		{
 			Definition: ToolDefinition{
@@ -500,6 +675,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"username"},
 				},
 			},
+			Scope: ScopeAdmin,
 		},
 		{
 			Definition: ToolDefinition{
@@ -510,6 +686,7 @@ func ToolSchemas() []Tool {
 					"properties": map[string]interface{}{},
 				},
 			},
+			Scope: ScopeAdmin,
 		},
 		{
 			Definition: ToolDefinition{
@@ -520,6 +697,7 @@ func ToolSchemas() []Tool {
 					"properties": map[string]interface{}{},
 				},
 			},
+			Scope: ScopeAdmin,
 		},
 		{
 			Definition: ToolDefinition{
@@ -554,6 +732,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"id", "effect", "subjects", "resources", "actions"},
 				},
 			},
+			Scope: ScopeAdmin,
 		},
 
 		// ========== GRAPH OPERATION TOOLS ==========
@@ -587,15 +766,24 @@ func ToolSchemas() []Tool {
 							"description": "Maximum results to return (default: 50)",
 							"default":     50,
 						},
+						"min_activation": map[string]interface{}{
+							"type":        "number",
+							"description": "Skip nodes activated less than this (0.0-1.0, default: the namespace's, normally 0.05)",
+						},
+						"cursor": map[string]interface{}{
+							"type":        "string",
+							"description": "Pagination cursor (next_cursor from a previous call)",
+						},
 					},
 					"required": []string{"namespace", "start_node"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
 				Name:        "graph_neighbors",
-				Description: "Get direct neighbors of a node with relationship types",
+				Description: "Get direct neighbors of a node with the relationship type, direction and weight of each linking edge",
 				InputSchema: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
@@ -616,6 +804,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "node_id"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -645,6 +834,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "source", "target"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -666,6 +856,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 
 		// ========== DOCUMENT ANALYSIS TOOLS ==========
@@ -693,11 +884,12 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "document_id"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
 				Name:        "document_extract",
-				Description: "Extract entities from a document stored in the knowledge graph",
+				Description: "Extract entities from a document stored in the knowledge graph, or from all documents of a content type",
 				InputSchema: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
@@ -707,16 +899,21 @@ func ToolSchemas() []Tool {
 						},
 						"document_id": map[string]interface{}{
 							"type":        "string",
-							"description": "Document node UID",
+							"description": "Document node UID (optional with content_type)",
+						},
+						"content_type": map[string]interface{}{
+							"type":        "string",
+							"description": "Only documents of this content type, e.g. \"pdf\" (optional)",
 						},
 						"entity_type": map[string]interface{}{
 							"type":        "string",
 							"description": "Filter by entity type (optional)",
 						},
 					},
-					"required": []string{"namespace", "document_id"},
+					"required": []string{"namespace"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -742,6 +939,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "document_id"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 
 		// ========== CONVERSATION MANAGEMENT TOOLS ==========
@@ -770,6 +968,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "conversation_id"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -795,6 +994,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "conversation_id"},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -823,6 +1023,7 @@ func ToolSchemas() []Tool {
 					"required": []string{"namespace", "conversation_id"},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 
 		// ========== USER SETTINGS TOOLS ==========
@@ -835,6 +1036,7 @@ func ToolSchemas() []Tool {
 					"properties": map[string]interface{}{},
 				},
 			},
+			Scope: ScopeRead,
 		},
 		{
 			Definition: ToolDefinition{
@@ -853,11 +1055,16 @@ func ToolSchemas() []Tool {
 						},
 						"preferences": map[string]interface{}{
 							"type":        "object",
-							"description": "User preferences as key-value pairs",
+							"description": "User preferences as key-value pairs, merged into the stored ones",
+						},
+						"version": map[string]interface{}{
+							"type":        "integer",
+							"description": "Profile version last read; the update fails with a conflict if the profile has changed since",
 						},
 					},
 				},
 			},
+			Scope: ScopeWrite,
 		},
 		{
 			Definition: ToolDefinition{
@@ -868,6 +1075,7 @@ func ToolSchemas() []Tool {
 					"properties": map[string]interface{}{},
 				},
 			},
+			Scope: ScopeRead,
 		},
 	}
 }
@@ -0,0 +1,104 @@
+// Package mcp implements tool authorization scopes for the MCP server
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Scope is a capability a caller needs to use a tool. Scopes are independent:
+// a read-only client gets just ScopeRead, an operator gets all three.
+type Scope string
+
+const (
+	// ScopeRead covers tools that only look at memory
+	ScopeRead Scope = "read"
+	// ScopeWrite covers tools that store, change or delete memory
+	ScopeWrite Scope = "write"
+	// ScopeAdmin covers the admin_* tools
+	ScopeAdmin Scope = "admin"
+)
+
+// DefaultScopes are granted when ServerConfig.Scopes is unset: everything but admin
+var DefaultScopes = []Scope{ScopeRead, ScopeWrite}
+
+// ScopeSet is the set of scopes a caller holds
+type ScopeSet map[Scope]bool
+
+// NewScopeSet builds a ScopeSet from scopes
+func NewScopeSet(scopes ...Scope) ScopeSet {
+	set := make(ScopeSet, len(scopes))
+	for _, scope := range scopes {
+		set[scope] = true
+	}
+	return set
+}
+
+// Allows reports whether the set grants scope
+func (s ScopeSet) Allows(scope Scope) bool {
+	return s[scope]
+}
+
+// String lists the scopes comma-separated, sorted
+func (s ScopeSet) String() string {
+	names := make([]string, 0, len(s))
+	for scope := range s {
+		names = append(names, string(scope))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// ParseScopes parses a comma-separated scope list such as "read,write"
+func ParseScopes(s string) ([]Scope, error) {
+	var scopes []Scope
+	for _, part := range strings.Split(s, ",") {
+		switch scope := Scope(strings.TrimSpace(part)); scope {
+		case "":
+			continue
+		case ScopeRead, ScopeWrite, ScopeAdmin:
+			scopes = append(scopes, scope)
+		default:
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return scopes, nil
+}
+
+// ParseAPIKeys parses "key1=read,write;key2=read" into each key's scopes
+func ParseAPIKeys(s string) (map[string][]Scope, error) {
+	keys := make(map[string][]Scope)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, list, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("API key entry must be key=scopes")
+		}
+		scopes, err := ParseScopes(list)
+		if err != nil {
+			return nil, err
+		}
+		keys[key] = scopes
+	}
+	return keys, nil
+}
+
+type scopesKey struct{}
+
+// WithScopes returns a context carrying the caller's scopes. Transports set it
+// once they have established who is calling.
+func WithScopes(ctx context.Context, scopes ScopeSet) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// ScopesFromContext returns the scopes set by WithScopes, if any
+func ScopesFromContext(ctx context.Context) (ScopeSet, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).(ScopeSet)
+	return scopes, ok
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/reflective-memory-kernel/internal/agent"
 	"go.uber.org/zap"
@@ -17,6 +18,9 @@ type Server struct {
 	handlers map[string]ToolHandler
 	tools    []Tool
 	serverInfo ServerInfo
+
+	// scopes are granted to callers whose transport established none
+	scopes ScopeSet
 }
 
 // ServerInfo contains server metadata
@@ -56,6 +60,10 @@ type ServerConfig struct {
 	Agent  *agent.Agent
 	Name   string
 	Version string
+
+	// Scopes are granted to callers the transport didn't authenticate (stdio,
+	// or HTTP without API keys). Defaults to DefaultScopes.
+	Scopes []Scope
 }
 
 // NewServer creates a new MCP server
@@ -68,12 +76,17 @@ func NewServer(config ServerConfig) *Server {
 	if version == "" {
 		version = "1.0.0"
 	}
+	scopes := config.Scopes
+	if scopes == nil {
+		scopes = DefaultScopes
+	}
 
 	s := &Server{
 		logger: config.Logger,
 		agent:  config.Agent,
 		handlers: make(map[string]ToolHandler),
 		tools: ToolSchemas(),
+		scopes: NewScopeSet(scopes...),
 		serverInfo: ServerInfo{
 			Name:     name,
 			Version:  version,
@@ -229,11 +242,23 @@ func (s *Server) handleInitialize(ctx context.Context, req MCPRequest) (interfac
 	return response, nil
 }
 
-// handleListTools handles the tools/list request
+// callerScopes returns the scopes the transport established for this call,
+// falling back to the server's configured scopes
+func (s *Server) callerScopes(ctx context.Context) ScopeSet {
+	if scopes, ok := ScopesFromContext(ctx); ok {
+		return scopes
+	}
+	return s.scopes
+}
+
+// handleListTools handles the tools/list request, listing only the tools the caller may call
 func (s *Server) handleListTools(ctx context.Context, req MCPRequest) (interface{}, error) {
+	scopes := s.callerScopes(ctx)
 	tools := make([]ToolDefinition, 0, len(s.tools))
 	for _, tool := range s.tools {
-		tools = append(tools, tool.Definition)
+		if scopes.Allows(tool.Scope) {
+			tools = append(tools, tool.Definition)
+		}
 	}
 
 	s.logger.Debug("Tools listed", zap.Int("count", len(tools)))
@@ -285,6 +310,23 @@ func (s *Server) handleToolCall(ctx context.Context, req MCPRequest) (interface{
 		}
 	}
 
+	// Authorize against the tool's scope; tools without a schema need admin
+	required := ScopeAdmin
+	if tool := s.GetTool(params.Name); tool != nil {
+		required = tool.Scope
+	}
+	if scopes := s.callerScopes(ctx); !scopes.Allows(required) {
+		s.logger.Warn("Tool call denied by scope",
+			zap.String("tool", params.Name),
+			zap.String("required", string(required)),
+			zap.String("scopes", scopes.String()))
+		return CallToolResponse{
+			Content:  []interface{}{ToolContent{Type: "text", Text: fmt.Sprintf("access denied: %s requires the %s scope", params.Name, required)}},
+			IsError:  true,
+			Metadata: map[string]interface{}{"errorKind": ToolErrorAccessDenied},
+		}, nil
+	}
+
 	// Prepare arguments
 	args := params.Arguments
 	if args == nil {
@@ -302,26 +344,61 @@ func (s *Server) handleToolCall(ctx context.Context, req MCPRequest) (interface{
 		if mcpErr, ok := err.(*MCPErrorObj); ok {
 			return nil, mcpErr
 		}
-		return nil, &MCPErrorObj{
-			Code:    -32603,
-			Message: fmt.Sprintf("tool execution failed: %v", err),
-		}
+		// Tool failures are results, not protocol errors: the spec reports them
+		// with isError so the model can see what went wrong and react
+		kind := toolErrorKind(err)
+		s.logger.Warn("Tool call failed",
+			zap.String("tool", params.Name),
+			zap.String("kind", kind),
+			zap.Error(err))
+		return CallToolResponse{
+			Content:  []interface{}{ToolContent{Type: "text", Text: err.Error()}},
+			IsError:  true,
+			Metadata: map[string]interface{}{"errorKind": kind},
+		}, nil
 	}
 
 	// Format response as MCP content
 	response := CallToolResponse{
-		Content: []interface{}{
-			map[string]interface{}{
-				"type": "text",
-				"text": formatResult(result),
-			},
-		},
+		Content: []interface{}{ToolContent{Type: "text", Text: formatResult(result)}},
 		IsError: false,
 	}
 
 	return response, nil
 }
 
+// Tool error kinds reported in a failed CallToolResponse's _meta.errorKind
+const (
+	ToolErrorAccessDenied    = "access_denied"
+	ToolErrorUnauthenticated = "unauthenticated"
+	ToolErrorNotFound        = "not_found"
+	ToolErrorInvalidArgument = "invalid_argument"
+	ToolErrorUnavailable     = "unavailable"
+	ToolErrorConflict        = "conflict"
+	ToolErrorInternal        = "internal"
+)
+
+// toolErrorKind classifies a handler error by the phrasing handlers use
+func toolErrorKind(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "not authenticated"), strings.Contains(msg, "no authenticated user"):
+		return ToolErrorUnauthenticated
+	case strings.Contains(msg, "access denied"), strings.Contains(msg, "access required"):
+		return ToolErrorAccessDenied
+	case strings.Contains(msg, "not found"):
+		return ToolErrorNotFound
+	case strings.Contains(msg, "not available"):
+		return ToolErrorUnavailable
+	case strings.Contains(msg, "concurrent modification"):
+		return ToolErrorConflict
+	case strings.Contains(msg, "required"), strings.Contains(msg, "must be"), strings.Contains(msg, "invalid"):
+		return ToolErrorInvalidArgument
+	default:
+		return ToolErrorInternal
+	}
+}
+
 // formatResult formats a result for display
 func formatResult(result interface{}) string {
 	data, err := json.MarshalIndent(result, "", "  ")
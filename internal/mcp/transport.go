@@ -8,7 +8,9 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -68,7 +70,7 @@ func (t *StdioTransport) Serve(ctx context.Context, handler RequestHandler) erro
 		t.logger.Debug("Received MCP request", zap.String("method", req.Method), zap.Any("id", req.ID))
 
 		resp, err := handler.HandleRequest(ctx, req)
-		if err != nil {
+		if err != nil && resp.Error == nil {
 			resp = MCPResponse{
 				JSONRPC: "2.0",
 				ID:      req.ID,
@@ -96,6 +98,9 @@ type HTTPTransport struct {
 	addr   string
 	server *http.Server
 	logger *zap.Logger
+
+	// apiKeys maps each accepted API key to its scopes; empty = no authentication
+	apiKeys map[string]ScopeSet
 }
 
 // NewHTTPTransport creates a new HTTP transport
@@ -106,20 +111,65 @@ func NewHTTPTransport(addr string, logger *zap.Logger) *HTTPTransport {
 	}
 }
 
-// Serve starts the HTTP transport
-func (t *HTTPTransport) Serve(ctx context.Context, handler RequestHandler) error {
+// SetAPIKeys requires every request to present one of keys, as
+// "Authorization: Bearer <key>" or "X-API-Key: <key>", and scopes the call to
+// that key's scopes
+func (t *HTTPTransport) SetAPIKeys(keys map[string][]Scope) {
+	t.apiKeys = make(map[string]ScopeSet, len(keys))
+	for key, scopes := range keys {
+		t.apiKeys[key] = NewScopeSet(scopes...)
+	}
+}
+
+// authenticate returns the context to serve r with, or false if r lacks a valid API key
+func (t *HTTPTransport) authenticate(r *http.Request) (context.Context, bool) {
+	if len(t.apiKeys) == 0 {
+		return r.Context(), true
+	}
+	key := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = strings.TrimSpace(bearer)
+	}
+	scopes, ok := t.apiKeys[key]
+	if key == "" || !ok {
+		return nil, false
+	}
+	return WithScopes(r.Context(), scopes), true
+}
+
+// Handler returns the /mcp endpoint. Every reply is a JSON-RPC envelope:
+// protocol failures (bad JSON, unknown method or tool) carry a JSON-RPC error
+// with HTTP 200, and tool failures come back as a result with isError set.
+func (t *HTTPTransport) Handler(handler RequestHandler) http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ctx, ok := t.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 
 		var req MCPRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request", http.StatusBadRequest)
+			json.NewEncoder(w).Encode(MCPResponse{
+				JSONRPC: "2.0",
+				Error: &MCPError{
+					Code:    -32700,
+					Message: "parse error: " + err.Error(),
+				},
+			})
 			return
 		}
 
-		resp, err := handler.HandleRequest(r.Context(), req)
-		if err != nil {
+		resp, err := handler.HandleRequest(ctx, req)
+		if err != nil && resp.Error == nil {
 			resp = MCPResponse{
 				JSONRPC: "2.0",
 				ID:      req.ID,
@@ -130,8 +180,20 @@ func (t *HTTPTransport) Serve(ctx context.Context, handler RequestHandler) error
 			}
 		}
 
+		// Notifications get no response body
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
 		json.NewEncoder(w).Encode(resp)
 	})
+	return mux
+}
+
+// Serve starts the HTTP transport
+func (t *HTTPTransport) Serve(ctx context.Context, handler RequestHandler) error {
+	mux := t.Handler(handler)
 
 	t.server = &http.Server{
 		Addr:    t.addr,
@@ -150,7 +212,7 @@ func (t *HTTPTransport) Serve(ctx context.Context, handler RequestHandler) error
 	<-ctx.Done()
 	t.logger.Info("MCP HTTP transport shutting down")
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	return t.server.Shutdown(shutdownCtx)
 }
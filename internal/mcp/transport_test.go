@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// newTestServer returns a Server whose tools are replaced by stubs: "lookup" is
+// read-scoped, "purge" admin-scoped and the rest write-scoped
+func newTestServer(scopes ...Scope) *Server {
+	s := NewServer(ServerConfig{Logger: zap.NewNop(), Scopes: scopes})
+	s.handlers = map[string]ToolHandler{
+		"echo": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"echo": args["text"]}, nil
+		},
+		"denied": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("access denied to namespace %s", "group_x")
+		},
+		"missing": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("document not found: %s", "doc_1")
+		},
+		"broken": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("failed to store memory: %w", context.DeadlineExceeded)
+		},
+		"lookup": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "found", nil
+		},
+		"purge": func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "purged", nil
+		},
+	}
+	s.tools = nil
+	for name := range s.handlers {
+		scope := ScopeWrite
+		switch name {
+		case "lookup":
+			scope = ScopeRead
+		case "purge":
+			scope = ScopeAdmin
+		}
+		s.tools = append(s.tools, Tool{Definition: ToolDefinition{Name: name}, Scope: scope})
+	}
+	return s
+}
+
+// newTestHTTPServer serves newTestServer with the default scopes over HTTP
+func newTestHTTPServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(NewHTTPTransport("", zap.NewNop()).Handler(newTestServer()))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// rpcEnvelope is a JSON-RPC response with the result decoded as a tool result
+type rpcEnvelope struct {
+	JSONRPC string    `json:"jsonrpc"`
+	ID      float64   `json:"id"`
+	Error   *MCPError `json:"error"`
+	Result  *struct {
+		Content []map[string]interface{} `json:"content"`
+		IsError bool                     `json:"isError"`
+		Meta    map[string]interface{}   `json:"_meta"`
+	} `json:"result"`
+}
+
+func postMCP(t *testing.T, url, body string) (*http.Response, rpcEnvelope) {
+	t.Helper()
+	resp, err := http.Post(url+"/mcp", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /mcp: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var env rpcEnvelope
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return resp, env
+}
+
+func callTool(name string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":%q,"arguments":{"text":"hi"}}}`, name)
+}
+
+func TestHTTPToolCallEnvelope(t *testing.T) {
+	ts := newTestHTTPServer(t)
+
+	tests := []struct {
+		tool     string
+		isError  bool
+		kind     string
+		contains string
+	}{
+		{tool: "echo", contains: `"echo": "hi"`},
+		{tool: "denied", isError: true, kind: ToolErrorAccessDenied, contains: "access denied"},
+		{tool: "missing", isError: true, kind: ToolErrorNotFound, contains: "doc_1"},
+		{tool: "broken", isError: true, kind: ToolErrorInternal, contains: "failed to store memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			resp, env := postMCP(t, ts.URL, callTool(tt.tool))
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if env.JSONRPC != "2.0" || env.ID != 7 {
+				t.Errorf("envelope jsonrpc=%q id=%v, want 2.0 and 7", env.JSONRPC, env.ID)
+			}
+			if env.Error != nil {
+				t.Fatalf("tool result reported as JSON-RPC error: %+v", env.Error)
+			}
+			if env.Result == nil || len(env.Result.Content) != 1 {
+				t.Fatalf("result = %+v, want exactly one content item", env.Result)
+			}
+			content := env.Result.Content[0]
+			if content["type"] != "text" {
+				t.Errorf("content type = %v, want text", content["type"])
+			}
+			if text, _ := content["text"].(string); !strings.Contains(text, tt.contains) {
+				t.Errorf("content text = %q, want it to contain %q", text, tt.contains)
+			}
+			if env.Result.IsError != tt.isError {
+				t.Errorf("isError = %v, want %v", env.Result.IsError, tt.isError)
+			}
+			if tt.isError && env.Result.Meta["errorKind"] != tt.kind {
+				t.Errorf("errorKind = %v, want %s", env.Result.Meta["errorKind"], tt.kind)
+			}
+		})
+	}
+}
+
+func TestHTTPProtocolErrors(t *testing.T) {
+	ts := newTestHTTPServer(t)
+
+	tests := []struct {
+		name, body string
+		code       int
+	}{
+		{"unknown tool", callTool("nope"), -32601},
+		{"unknown method", `{"jsonrpc":"2.0","id":1,"method":"nope"}`, -32601},
+		{"missing params", `{"jsonrpc":"2.0","id":1,"method":"tools/call"}`, -32602},
+		{"malformed JSON", `{"jsonrpc":`, -32700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, env := postMCP(t, ts.URL, tt.body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if env.Error == nil || env.Error.Code != tt.code {
+				t.Errorf("error = %+v, want code %d", env.Error, tt.code)
+			}
+			if env.Result != nil {
+				t.Errorf("protocol error also carried a result: %+v", env.Result)
+			}
+		})
+	}
+}
+
+func TestHTTPNotificationAndMethod(t *testing.T) {
+	ts := newTestHTTPServer(t)
+
+	resp, _ := postMCP(t, ts.URL, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification status = %d, want 202", resp.StatusCode)
+	}
+
+	get, err := http.Get(ts.URL + "/mcp")
+	if err != nil {
+		t.Fatalf("GET /mcp: %v", err)
+	}
+	get.Body.Close()
+	if get.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", get.StatusCode)
+	}
+}
+
+// listedTools returns the tool names tools/list shows for ctx
+func listedTools(t *testing.T, s *Server, ctx context.Context) map[string]bool {
+	t.Helper()
+	resp, err := s.HandleRequest(ctx, MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	if err != nil {
+		t.Fatalf("tools/list: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, def := range resp.Result.(ListToolsResponse).Tools {
+		names[def.Name] = true
+	}
+	return names
+}
+
+func TestScopesFilterListAndCall(t *testing.T) {
+	s := newTestServer(ScopeRead)
+	ctx := context.Background()
+
+	listed := listedTools(t, s, ctx)
+	if !listed["lookup"] || listed["echo"] || listed["purge"] {
+		t.Errorf("read-only tools/list = %v, want only lookup", listed)
+	}
+
+	call := func(ctx context.Context, name string) CallToolResponse {
+		t.Helper()
+		resp, err := s.HandleRequest(ctx, MCPRequest{JSONRPC: "2.0", ID: 2, Method: "tools/call",
+			Params: map[string]interface{}{"name": name}})
+		if err != nil {
+			t.Fatalf("tools/call %s: %v", name, err)
+		}
+		return resp.Result.(CallToolResponse)
+	}
+	if res := call(ctx, "lookup"); res.IsError {
+		t.Errorf("read-only caller denied a read tool: %+v", res)
+	}
+	if res := call(ctx, "echo"); !res.IsError || res.Metadata["errorKind"] != ToolErrorAccessDenied {
+		t.Errorf("read-only caller ran a write tool: %+v", res)
+	}
+
+	// Scopes from the transport override the server's
+	admin := WithScopes(ctx, NewScopeSet(ScopeAdmin))
+	if res := call(admin, "purge"); res.IsError {
+		t.Errorf("admin caller denied an admin tool: %+v", res)
+	}
+	if listed := listedTools(t, s, admin); !listed["purge"] || listed["lookup"] {
+		t.Errorf("admin-only tools/list = %v, want only purge", listed)
+	}
+}
+
+func TestDefaultScopesExcludeAdmin(t *testing.T) {
+	listed := listedTools(t, NewServer(ServerConfig{Logger: zap.NewNop()}), context.Background())
+	for _, tool := range ToolSchemas() {
+		if want := tool.Scope != ScopeAdmin; listed[tool.Definition.Name] != want {
+			t.Errorf("default tools/list shows %s = %v, want %v", tool.Definition.Name, !want, want)
+		}
+	}
+}
+
+func TestHTTPAPIKeyScopes(t *testing.T) {
+	transport := NewHTTPTransport("", zap.NewNop())
+	transport.SetAPIKeys(map[string][]Scope{"reader": {ScopeRead}, "operator": {ScopeRead, ScopeWrite, ScopeAdmin}})
+	ts := httptest.NewServer(transport.Handler(newTestServer(ScopeRead, ScopeWrite, ScopeAdmin)))
+	defer ts.Close()
+
+	post := func(key, body string) (*http.Response, rpcEnvelope) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /mcp: %v", err)
+		}
+		defer resp.Body.Close()
+		var env rpcEnvelope
+		if resp.StatusCode == http.StatusOK {
+			json.NewDecoder(resp.Body).Decode(&env)
+		}
+		return resp, env
+	}
+
+	for _, key := range []string{"", "wrong"} {
+		if resp, _ := post(key, callTool("lookup")); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("key %q: status = %d, want 401", key, resp.StatusCode)
+		}
+	}
+	if _, env := post("reader", callTool("purge")); env.Result == nil || !env.Result.IsError {
+		t.Errorf("reader key ran an admin tool: %+v", env.Result)
+	}
+	if _, env := post("operator", callTool("purge")); env.Result == nil || env.Result.IsError {
+		t.Errorf("operator key denied an admin tool: %+v", env.Result)
+	}
+}
@@ -76,8 +76,9 @@ type ToolContent struct {
 // ToolHandler handles tool execution
 type ToolHandler func(ctx context.Context, args map[string]interface{}) (interface{}, error)
 
-// Tool wraps a ToolDefinition with its handler
+// Tool wraps a ToolDefinition with its handler and the scope a caller needs to use it
 type Tool struct {
 	Definition ToolDefinition
 	Handler    ToolHandler
+	Scope      Scope
 }
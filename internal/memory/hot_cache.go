@@ -267,6 +267,38 @@ func (hc *HotCache) GetRecent(userID string, n int) []CachedMessage {
 	return allMessages
 }
 
+// Purge removes every message in namespace (across all users) for which match
+// returns true, keeping the remaining messages in order. Returns how many were removed.
+func (hc *HotCache) Purge(namespace string, match func(CachedMessage) bool) int {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	removed := 0
+	for _, nsMap := range hc.userMessages {
+		rb, ok := nsMap[namespace]
+		if !ok {
+			continue
+		}
+		messages := rb.all() // Newest first
+		kept := newRingBuffer(rb.capacity)
+		for i := len(messages) - 1; i >= 0; i-- {
+			if match(messages[i]) {
+				removed++
+				continue
+			}
+			kept.push(messages[i])
+		}
+		nsMap[namespace] = kept
+	}
+
+	if removed > 0 {
+		hc.logger.Info("Purged messages from hot cache",
+			zap.String("namespace", namespace),
+			zap.Int("removed", removed))
+	}
+	return removed
+}
+
 // Stats returns cache statistics.
 func (hc *HotCache) Stats() map[string]interface{} {
 	hc.mu.RLock()
@@ -60,9 +60,15 @@ func (p *Processor) ProcessBatch(ctx context.Context, points []DataPoint) (*Batc
 
 	// Step 2: Build nodes from cognify results
 	var allNodes []*graph.Node
-	var allEdges []graph.EdgeInput
+	var allRelations []ExtractedRelation
 
 	for i, cr := range cognifyResults {
+		if cr.Status == "error" || cr.Status == "cancelled" {
+			p.logger.Warn("cognify item not extracted",
+				zap.String("source_id", cr.SourceID),
+				zap.String("status", cr.Status),
+				zap.String("error", cr.Error))
+		}
 		if len(cr.Entities) == 0 {
 			result.SkippedCount++
 			continue
@@ -71,10 +77,7 @@ func (p *Processor) ProcessBatch(ctx context.Context, points []DataPoint) (*Batc
 		// Convert entities to graph nodes
 		nodes := p.entitiesToNodes(cr.Entities, points[i])
 		allNodes = append(allNodes, nodes...)
-
-		// Build edges from relations
-		edges := p.relationsToEdges(cr.Relations, points[i])
-		allEdges = append(allEdges, edges...)
+		allRelations = append(allRelations, cr.Relations...)
 
 		result.ProcessedCount++
 	}
@@ -87,65 +90,34 @@ func (p *Processor) ProcessBatch(ctx context.Context, points []DataPoint) (*Batc
 		}
 	}
 
-	// Step 4: Batch upsert to DGraph
+	// Step 4: Infer relationships from entity attributes
 	if len(allNodes) > 0 {
-		uidMap, err := p.graphClient.CreateNodes(ctx, allNodes)
-		if err != nil {
-			return nil, fmt.Errorf("graph upsert failed: %w", err)
-		}
-		result.NodesCreated = int64(len(uidMap))
-
-		// Step 5: Infer relationships from entity attributes
 		inferrer := NewRelationInferrer(p.config.Namespace)
 		inferredEdges := inferrer.InferFromNodes(allNodes)
 		p.logger.Info("Inferred relationships from attributes",
 			zap.Int("inferred_edges", len(inferredEdges)))
-
-		// Step 6: Resolve entity names to UIDs for edges
-		// First, build a name->UID lookup from created nodes + existing
-		nameToUID := make(map[string]string)
-		for name, uid := range uidMap {
-			nameToUID[name] = uid
-		}
-
-		// Also fetch existing nodes that might be edge targets (skills, managers)
-		var targetNames []string
 		for _, ie := range inferredEdges {
-			targetNames = append(targetNames, ie.FromName, ie.ToName)
-		}
-		if len(targetNames) > 0 {
-			existing, err := p.graphClient.GetNodesByNames(ctx, p.config.Namespace, targetNames)
-			if err == nil {
-				for name, node := range existing {
-					if node != nil {
-						nameToUID[name] = node.UID
-					}
-				}
-			}
+			allRelations = append(allRelations, ExtractedRelation{
+				FromName: ie.FromName,
+				ToName:   ie.ToName,
+				Type:     string(ie.EdgeType),
+			})
 		}
+	}
 
-		// Convert inferred edges to graph.EdgeInput with resolved UIDs
-		for _, ie := range inferredEdges {
-			fromUID := nameToUID[ie.FromName]
-			toUID := nameToUID[ie.ToName]
-			if fromUID != "" && toUID != "" {
-				allEdges = append(allEdges, graph.EdgeInput{
-					FromUID: fromUID,
-					ToUID:   toUID,
-					Type:    ie.EdgeType,
-					Status:  graph.EdgeStatusCurrent,
-				})
-			}
-		}
+	// Step 5: Resolve relation endpoints to batch nodes or existing UIDs
+	edges := p.relationsToEdges(ctx, allNodes, allRelations)
 
-		// Create edges after nodes exist
-		if len(allEdges) > 0 {
-			if err := p.graphClient.CreateEdges(ctx, allEdges); err != nil {
-				p.logger.Warn("edge creation warning", zap.Error(err))
-			} else {
-				result.EdgesCreated = int64(len(allEdges))
-				p.logger.Info("Created relationship edges", zap.Int64("edges", result.EdgesCreated))
-			}
+	// Step 6: Create nodes and edges in one atomic transaction
+	if len(allNodes) > 0 || len(edges) > 0 {
+		uidMap, err := p.graphClient.IngestGraph(ctx, allNodes, edges)
+		if err != nil {
+			return nil, fmt.Errorf("graph upsert failed: %w", err)
+		}
+		result.NodesCreated = int64(len(uidMap))
+		result.EdgesCreated = int64(len(edges))
+		if len(edges) > 0 {
+			p.logger.Info("Created relationship edges", zap.Int64("edges", result.EdgesCreated))
 		}
 	}
 
@@ -179,6 +151,8 @@ type CognifyItem struct {
 // CognifyResult is the response from AI service
 type CognifyResult struct {
 	SourceID  string              `json:"source_id"`
+	Status    string              `json:"status"` // "ok", "fallback", "error" or "cancelled"
+	Error     string              `json:"error,omitempty"`
 	Entities  []ExtractedEntity   `json:"entities"`
 	Relations []ExtractedRelation `json:"relations"`
 }
@@ -285,17 +259,79 @@ func (p *Processor) entitiesToNodes(entities []ExtractedEntity, source DataPoint
 	return nodes
 }
 
-// relationsToEdges converts extracted relations to graph edges
-func (p *Processor) relationsToEdges(relations []ExtractedRelation, source DataPoint) []graph.EdgeInput {
-	edges := make([]graph.EdgeInput, 0, len(relations))
+// relationsToEdges resolves relation endpoints: names of nodes in the batch are
+// kept for IngestGraph to wire up, other names are looked up in the graph.
+// Relations whose endpoints can't be found are dropped.
+func (p *Processor) relationsToEdges(ctx context.Context, nodes []*graph.Node, relations []ExtractedRelation) []graph.IngestEdge {
+	if len(relations) == 0 {
+		return nil
+	}
+
+	inBatch := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		inBatch[n.Name] = true
+	}
 
+	var lookup []string
 	for _, r := range relations {
-		edge := graph.EdgeInput{
-			Type:   graph.EdgeType(r.Type),
-			Status: graph.EdgeStatusCurrent,
+		for _, name := range []string{r.FromName, r.ToName} {
+			if !inBatch[name] {
+				lookup = append(lookup, name)
+			}
+		}
+	}
+	existing := map[string]*graph.Node{}
+	if len(lookup) > 0 {
+		found, err := p.graphClient.GetNodesByNames(ctx, p.config.Namespace, lookup)
+		if err != nil {
+			p.logger.Warn("edge endpoint lookup failed", zap.Error(err))
+		} else {
+			existing = found
+		}
+	}
+
+	// ref returns the UID of an existing node or the name of a batch node
+	ref := func(name string) (uid, batchName string, ok bool) {
+		if inBatch[name] {
+			return "", name, true
+		}
+		if node := existing[name]; node != nil {
+			return node.UID, "", true
+		}
+		return "", "", false
+	}
+
+	edges := make([]graph.IngestEdge, 0, len(relations))
+	for _, r := range relations {
+		match := graph.InferEdgeType(r.Type)
+		if match.NeedsReview() {
+			p.logger.Info("low-confidence relation type mapping",
+				zap.String("from", r.FromName),
+				zap.String("to", r.ToName),
+				zap.String("type", r.Type),
+				zap.String("mapped_to", string(match.Type)),
+				zap.Float64("confidence", match.Confidence))
+		}
+		from, to := r.FromName, r.ToName
+		if match.Reversed {
+			from, to = to, from
+		}
+		fromUID, fromName, ok := ref(from)
+		if !ok {
+			continue
+		}
+		toUID, toName, ok := ref(to)
+		if !ok {
+			continue
 		}
-		// Note: FromUID and ToUID will be resolved after node creation
-		edges = append(edges, edge)
+		edges = append(edges, graph.IngestEdge{
+			FromUID:     fromUID,
+			FromName:    fromName,
+			ToUID:       toUID,
+			ToName:      toName,
+			Type:        match.Type,
+			NeedsReview: match.NeedsReview(),
+		})
 	}
 
 	return edges
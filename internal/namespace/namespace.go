@@ -0,0 +1,74 @@
+// Package namespace defines the memory namespace naming conventions.
+// Personal memory lives in "user_<userID>" and shared group memory in
+// "group_<groupID>"; everything that builds or inspects a namespace goes
+// through this package so the two are never confused.
+package namespace
+
+import "strings"
+
+// Kind identifies who owns a namespace
+type Kind string
+
+const (
+	KindUnknown Kind = ""
+	KindUser    Kind = "user"
+	KindGroup   Kind = "group"
+)
+
+const (
+	UserPrefix  = "user_"
+	GroupPrefix = "group_"
+)
+
+// Parse splits ns into its kind and owner id. Matching is exact and
+// case-sensitive: a bare prefix with no id, or any other string, is
+// KindUnknown and the input is returned unchanged as the id.
+func Parse(ns string) (Kind, string) {
+	if id, ok := strings.CutPrefix(ns, UserPrefix); ok && id != "" {
+		return KindUser, id
+	}
+	if id, ok := strings.CutPrefix(ns, GroupPrefix); ok && id != "" {
+		return KindGroup, id
+	}
+	return KindUnknown, ns
+}
+
+// ForUser returns the personal namespace of userID
+func ForUser(userID string) string {
+	return UserPrefix + userID
+}
+
+// ForGroup returns the namespace of groupID
+func ForGroup(groupID string) string {
+	return GroupPrefix + groupID
+}
+
+// IsUser reports whether ns is a personal namespace
+func IsUser(ns string) bool {
+	kind, _ := Parse(ns)
+	return kind == KindUser
+}
+
+// IsGroup reports whether ns is a group namespace
+func IsGroup(ns string) bool {
+	kind, _ := Parse(ns)
+	return kind == KindGroup
+}
+
+// UserID returns the owner of a personal namespace, or false for any other namespace
+func UserID(ns string) (string, bool) {
+	kind, id := Parse(ns)
+	if kind != KindUser {
+		return "", false
+	}
+	return id, true
+}
+
+// GroupID returns the group of a group namespace, or false for any other namespace
+func GroupID(ns string) (string, bool) {
+	kind, id := Parse(ns)
+	if kind != KindGroup {
+		return "", false
+	}
+	return id, true
+}
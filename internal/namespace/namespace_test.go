@@ -0,0 +1,66 @@
+package namespace
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ns   string
+		kind Kind
+		id   string
+	}{
+		{"user_alice", KindUser, "alice"},
+		{"group_0x1a", KindGroup, "0x1a"},
+		// The prefix only applies once; the remainder is the id verbatim
+		{"user_group_x", KindUser, "group_x"},
+		{"group_user_x", KindGroup, "user_x"},
+		{"user_user_bob", KindUser, "user_bob"},
+		// Bare prefixes carry no owner
+		{"user_", KindUnknown, "user_"},
+		{"group_", KindUnknown, "group_"},
+		// Raw ids, wrong case and stray whitespace are not namespaces
+		{"alice", KindUnknown, "alice"},
+		{"USER_alice", KindUnknown, "USER_alice"},
+		{" user_alice", KindUnknown, " user_alice"},
+		{"groups_x", KindUnknown, "groups_x"},
+		{"", KindUnknown, ""},
+	}
+
+	for _, tt := range tests {
+		kind, id := Parse(tt.ns)
+		if kind != tt.kind || id != tt.id {
+			t.Errorf("Parse(%q) = (%q, %q), want (%q, %q)", tt.ns, kind, id, tt.kind, tt.id)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, id := range []string{"alice", "0x2f", "group_x", "user_y"} {
+		if kind, got := Parse(ForUser(id)); kind != KindUser || got != id {
+			t.Errorf("Parse(ForUser(%q)) = (%q, %q)", id, kind, got)
+		}
+		if kind, got := Parse(ForGroup(id)); kind != KindGroup || got != id {
+			t.Errorf("Parse(ForGroup(%q)) = (%q, %q)", id, kind, got)
+		}
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	if IsGroup("user_group_x") {
+		t.Error("user namespace reported as group")
+	}
+	if !IsGroup("group_x") || IsUser("group_x") {
+		t.Error("group namespace misclassified")
+	}
+	if IsUser("user_") || IsGroup("group_") {
+		t.Error("bare prefix classified as a namespace")
+	}
+	if _, ok := UserID("group_alice"); ok {
+		t.Error("UserID accepted a group namespace")
+	}
+	if id, ok := UserID("user_alice"); !ok || id != "alice" {
+		t.Errorf("UserID(user_alice) = (%q, %v)", id, ok)
+	}
+	if id, ok := GroupID("group_0x1"); !ok || id != "0x1" {
+		t.Errorf("GroupID(group_0x1) = (%q, %v)", id, ok)
+	}
+}
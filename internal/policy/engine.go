@@ -8,6 +8,7 @@ import (
 	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
 	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"go.uber.org/zap"
 )
 
@@ -140,7 +141,7 @@ func (pm *PolicyManager) Evaluate(ctx context.Context, user UserContext, resourc
 	if !pm.enabled {
 		// SECURE: When policy system is disabled, only allow same-namespace access
 		if resource != nil && resource.Namespace != "" {
-			expectedNamespace := fmt.Sprintf("user_%s", user.UserID)
+			expectedNamespace := nsutil.ForUser(user.UserID)
 			if resource.Namespace == expectedNamespace {
 				return EffectAllow, nil
 			}
@@ -205,14 +206,14 @@ func (e *DefaultEngine) Evaluate(ctx context.Context, user UserContext, resource
 	// SECURITY FIX: Direct ownership grants access for user's own namespace (unless explicitly denied above)
 	if resource.Namespace != "" {
 		// Check direct ownership - user can access their own namespace (unless denied above)
-		if resource.Namespace == fmt.Sprintf("user_%s", user.UserID) {
+		if resource.Namespace == nsutil.ForUser(user.UserID) {
 			return EffectAllow, nil
 		}
 
 		// Check group membership
 		hasGroupAccess := false
 		for _, group := range user.Groups {
-			if resource.Namespace == fmt.Sprintf("group_%s", group) {
+			if resource.Namespace == nsutil.ForGroup(group) {
 				hasGroupAccess = true
 				break
 			}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
@@ -43,9 +44,20 @@ type SemanticCache struct {
 	embedder     Embedder
 	threshold    float64
 
+	// entries tracks stored query/response pairs per namespace (key -> entry)
+	// so entries mentioning a forgotten node can be found and invalidated
+	entriesMu sync.Mutex
+	entries   map[string]map[string]cacheEntry
+
 	logger *zap.Logger
 }
 
+// cacheEntry is a tracked query/response pair
+type cacheEntry struct {
+	query    string
+	response string
+}
+
 // NewSemanticCache creates a new multi-layer semantic cache
 func NewSemanticCache(cacheManager *cache.Manager, vectorIndex *kernel.VectorIndex, embedder Embedder, threshold float64, logger *zap.Logger) *SemanticCache {
 	if threshold <= 0 || threshold > 1 {
@@ -70,6 +82,7 @@ func NewSemanticCache(cacheManager *cache.Manager, vectorIndex *kernel.VectorInd
 		vectorIndex:  vectorIndex,
 		embedder:     embedder,
 		threshold:    threshold,
+		entries:      make(map[string]map[string]cacheEntry),
 		logger:       logger,
 	}
 
@@ -199,6 +212,8 @@ func (sc *SemanticCache) Store(ctx context.Context, namespace, query, response s
 	// Store in L2 cache manager (backward compatibility)
 	sc.cacheManager.SetWithTTL(key, response, int64(len(response)), CacheTTL)
 
+	sc.track(namespace, key, query, response)
+
 	// If we have an embedder and vector index, store vector for semantic matching
 	// Run in background with separate context to avoid parent cancellation
 	if sc.embedder != nil && sc.vectorIndex != nil {
@@ -403,6 +418,65 @@ func (sc *SemanticCache) InvalidateSpecific(ctx context.Context, namespace, quer
 	return nil
 }
 
+// track records a stored entry, evicting an arbitrary one when the namespace is full
+func (sc *SemanticCache) track(namespace, key, query, response string) {
+	sc.entriesMu.Lock()
+	defer sc.entriesMu.Unlock()
+
+	nsEntries, ok := sc.entries[namespace]
+	if !ok {
+		nsEntries = make(map[string]cacheEntry)
+		sc.entries[namespace] = nsEntries
+	}
+	if _, exists := nsEntries[key]; !exists && len(nsEntries) >= MaxCachedVectors {
+		for k := range nsEntries {
+			delete(nsEntries, k)
+			break
+		}
+	}
+	nsEntries[key] = cacheEntry{query: query, response: response}
+}
+
+// InvalidateReferencing removes every tracked entry in namespace whose query or
+// response contains one of terms (lowercase) from all layers. Returns how many were removed.
+func (sc *SemanticCache) InvalidateReferencing(ctx context.Context, namespace string, terms []string) int {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	sc.entriesMu.Lock()
+	var matched []cacheEntry
+	var keys []string
+	for key, entry := range sc.entries[namespace] {
+		if kernel.MentionsAny(entry.query, terms) || kernel.MentionsAny(entry.response, terms) {
+			matched = append(matched, entry)
+			keys = append(keys, key)
+			delete(sc.entries[namespace], key)
+		}
+	}
+	sc.entriesMu.Unlock()
+
+	for i, key := range keys {
+		if sc.l1ExactMatch != nil {
+			sc.l1ExactMatch.Del(key)
+		}
+		sc.cacheManager.Delete(key)
+		if sc.vectorIndex != nil {
+			uid := fmt.Sprintf("sc_%s", hashQuery(matched[i].query))
+			if err := sc.vectorIndex.Delete(ctx, namespace, uid); err != nil {
+				sc.logger.Warn("Semantic cache: failed to delete cached vector", zap.Error(err))
+			}
+		}
+	}
+
+	if len(keys) > 0 {
+		sc.logger.Info("Semantic cache: invalidated entries referencing forgotten node",
+			zap.String("namespace", namespace),
+			zap.Int("entries", len(keys)))
+	}
+	return len(keys)
+}
+
 // isValidNamespaceName validates namespace format for semantic cache
 // SECURITY: Ensures namespace follows expected pattern to prevent injection
 func isValidNamespaceName(ns string) bool {
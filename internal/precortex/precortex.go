@@ -198,6 +198,12 @@ func (pc *PreCortex) SaveToCache(ctx context.Context, namespace, query, response
 	}
 }
 
+// ForgetNode invalidates semantic cache entries that mention a forgotten node.
+// It matches kernel.ForgetHook so it can be registered with Kernel.OnForget.
+func (pc *PreCortex) ForgetNode(ctx context.Context, namespace string, node *graph.Node) {
+	pc.semanticCache.InvalidateReferencing(ctx, namespace, kernel.ForgetTerms(node))
+}
+
 // Stats returns Pre-Cortex statistics
 func (pc *PreCortex) Stats() (total, cached, reflex, llm int64, hitRate float64) {
 	pc.mu.RLock()
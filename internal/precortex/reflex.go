@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	nsutil "github.com/reflective-memory-kernel/internal/namespace"
 	"go.uber.org/zap"
 )
 
@@ -170,7 +171,7 @@ func (re *ReflexEngine) executeDGraphQuery(ctx context.Context, namespace, userI
 			}
 		}`
 		resp, err := re.graphClient.Query(ctx, q, map[string]string{
-			"$ns": fmt.Sprintf("user_%s", userID),
+			"$ns": nsutil.ForUser(userID),
 		})
 		if err != nil {
 			return result, err
@@ -0,0 +1,127 @@
+package reflection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// activationConfigTTL is how long a namespace's config is served from memory
+// before Redis is consulted again, so changes reach every process within it
+const activationConfigTTL = 30 * time.Second
+
+func activationConfigKey(namespace string) string {
+	return "activation_config:" + namespace
+}
+
+// ActivationConfigStore holds per-namespace activation parameters in Redis so
+// operators can tune boost and decay without a redeploy. Namespaces without an
+// override use the defaults the kernel was started with. A nil store always
+// returns graph.DefaultActivationConfig().
+type ActivationConfigStore struct {
+	redisClient *redis.Client
+	defaults    graph.ActivationConfig
+	logger      *zap.Logger
+
+	mu     sync.RWMutex
+	cached map[string]cachedActivationConfig
+}
+
+type cachedActivationConfig struct {
+	config    graph.ActivationConfig
+	fetchedAt time.Time
+}
+
+// NewActivationConfigStore creates a store falling back to defaults. Without
+// Redis, overrides only live in this process.
+func NewActivationConfigStore(redisClient *redis.Client, defaults graph.ActivationConfig, logger *zap.Logger) *ActivationConfigStore {
+	return &ActivationConfigStore{
+		redisClient: redisClient,
+		defaults:    defaults,
+		logger:      logger,
+		cached:      make(map[string]cachedActivationConfig),
+	}
+}
+
+// Defaults returns the config used by namespaces without an override
+func (s *ActivationConfigStore) Defaults() graph.ActivationConfig {
+	if s == nil {
+		return graph.DefaultActivationConfig()
+	}
+	return s.defaults
+}
+
+// Get returns the activation config for a namespace. Lookup failures fall back
+// to the defaults; activation tuning is never worth failing a request over.
+func (s *ActivationConfigStore) Get(ctx context.Context, namespace string) graph.ActivationConfig {
+	if s == nil {
+		return graph.DefaultActivationConfig()
+	}
+
+	s.mu.RLock()
+	entry, ok := s.cached[namespace]
+	s.mu.RUnlock()
+	if ok && (s.redisClient == nil || time.Since(entry.fetchedAt) < activationConfigTTL) {
+		return entry.config
+	}
+	if s.redisClient == nil {
+		return s.defaults
+	}
+
+	config := s.defaults
+	data, err := s.redisClient.Get(ctx, activationConfigKey(namespace)).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+	case err != nil:
+		s.logger.Debug("Activation config lookup failed, using defaults",
+			zap.String("namespace", namespace), zap.Error(err))
+		return s.defaults
+	default:
+		var stored graph.ActivationConfig
+		if err := json.Unmarshal(data, &stored); err != nil || stored.Validate() != nil {
+			s.logger.Warn("Ignoring invalid stored activation config", zap.String("namespace", namespace))
+		} else {
+			config = stored
+		}
+	}
+
+	s.mu.Lock()
+	s.cached[namespace] = cachedActivationConfig{config: config, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return config
+}
+
+// Set validates and stores a namespace's activation config
+func (s *ActivationConfigStore) Set(ctx context.Context, namespace string, config graph.ActivationConfig) error {
+	if s == nil {
+		return errors.New("activation config store not available")
+	}
+	if namespace == "" {
+		return errors.New("namespace is required")
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if s.redisClient != nil {
+		data, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		if err := s.redisClient.Set(ctx, activationConfigKey(namespace), data, 0).Err(); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.cached[namespace] = cachedActivationConfig{config: config, fetchedAt: time.Now()}
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,53 @@
+package reflection
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func TestActivationConfigStoreOverrides(t *testing.T) {
+	ctx := context.Background()
+	defaults := graph.DefaultActivationConfig()
+	store := NewActivationConfigStore(nil, defaults, zap.NewNop())
+
+	tuned := defaults
+	tuned.BoostPerAccess = 0.05
+	tuned.MaxActivation = 1
+	if err := store.Set(ctx, "user_alice", tuned); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := store.Get(ctx, "user_alice"); got != tuned {
+		t.Errorf("user_alice config = %+v, want %+v", got, tuned)
+	}
+	if got := store.Get(ctx, "user_bob"); got != defaults {
+		t.Errorf("namespace without override = %+v, want defaults", got)
+	}
+
+	for name, mutate := range map[string]func(*graph.ActivationConfig){
+		"zero boost":       func(c *graph.ActivationConfig) { c.BoostPerAccess = 0 },
+		"max above 1":      func(c *graph.ActivationConfig) { c.MaxActivation = 1.5 },
+		"zero max":         func(c *graph.ActivationConfig) { c.MaxActivation = 0 },
+		"min above max":    func(c *graph.ActivationConfig) { c.MinActivation = 0.9 },
+		"full decay":       func(c *graph.ActivationConfig) { c.DecayRate = 1 },
+		"negative decay":   func(c *graph.ActivationConfig) { c.DecayRate = -0.1 },
+		"no core identity": func(c *graph.ActivationConfig) { c.CoreIdentityThreshold = 0 },
+	} {
+		invalid := defaults
+		mutate(&invalid)
+		if err := store.Set(ctx, "user_alice", invalid); err == nil {
+			t.Errorf("%s: Set accepted %+v", name, invalid)
+		}
+	}
+	if got := store.Get(ctx, "user_alice"); got != tuned {
+		t.Errorf("rejected updates changed the config to %+v", got)
+	}
+
+	var unset *ActivationConfigStore
+	if got := unset.Get(ctx, "user_alice"); got != defaults {
+		t.Errorf("nil store = %+v, want defaults", got)
+	}
+}
@@ -54,8 +54,15 @@ func (m *AnticipationModule) Run(ctx context.Context) error {
 		m.logger.Warn("Failed to detect sequence patterns", zap.Error(err))
 	}
 
-	// Step 3: Update or create pattern nodes
+	// Step 3: Mine recurring topics from Event/Fact nodes in the graph
+	timelinePatterns, err := m.mineTimelinePatterns(ctx)
+	if err != nil {
+		m.logger.Warn("Failed to mine timeline patterns", zap.Error(err))
+	}
+
+	// Step 4: Update or create pattern nodes
 	allPatterns := append(temporalPatterns, sequencePatterns...)
+	allPatterns = append(allPatterns, timelinePatterns...)
 	for _, pattern := range allPatterns {
 		if err := m.persistPattern(ctx, pattern); err != nil {
 			m.logger.Error("Failed to persist pattern", zap.Error(err))
@@ -64,7 +71,8 @@ func (m *AnticipationModule) Run(ctx context.Context) error {
 
 	m.logger.Info("Pattern detection completed",
 		zap.Int("temporal_patterns", len(temporalPatterns)),
-		zap.Int("sequence_patterns", len(sequencePatterns)))
+		zap.Int("sequence_patterns", len(sequencePatterns)),
+		zap.Int("timeline_patterns", len(timelinePatterns)))
 
 	return nil
 }
@@ -269,35 +277,14 @@ func (m *AnticipationModule) detectSequencePatterns(ctx context.Context) ([]grap
 	return patterns, nil
 }
 
-// persistPattern saves a pattern to the graph
+// persistPattern saves a pattern to the graph, refreshing it if it already exists
 func (m *AnticipationModule) persistPattern(ctx context.Context, pattern graph.Pattern) error {
-	// Check if pattern already exists
-	existingNode, err := m.graphClient.FindNodeByName(ctx, pattern.Namespace, pattern.Name, graph.NodeTypePattern)
-	if err != nil {
-		return err
-	}
-
-	if existingNode != nil {
-		// Update existing pattern - increment frequency
-		return m.graphClient.IncrementAccessCount(ctx, existingNode.UID, graph.DefaultActivationConfig())
-	}
-
-	// Create new pattern
-	node := &graph.Node{
-		DType:       []string{string(graph.NodeTypePattern)},
-		Name:        pattern.Name,
-		Namespace:   pattern.Namespace,
-		Description: pattern.PredictedAction,
-		Activation:  pattern.ConfidenceScore,
-		Confidence:  pattern.ConfidenceScore,
-	}
-
-	uid, err := m.graphClient.CreateNode(ctx, node)
+	uid, err := m.graphClient.UpsertPattern(ctx, &pattern)
 	if err != nil {
 		return err
 	}
 
-	m.logger.Debug("Created pattern node",
+	m.logger.Debug("Persisted pattern node",
 		zap.String("uid", uid),
 		zap.String("name", pattern.Name),
 		zap.Float64("confidence", pattern.ConfidenceScore))
@@ -2,13 +2,16 @@
 package reflection
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/reflective-memory-kernel/internal/ai/curation"
 	"github.com/reflective-memory-kernel/internal/graph"
 )
 
@@ -91,17 +94,52 @@ func (m *CurationModule) resolveContradiction(ctx context.Context, conflict grap
 		return fmt.Errorf("failed to get node2: %w", err)
 	}
 
-	winningUID := m.determineWinner(node1, node2)
-	losingUID := conflict.NodeUID1
-	if losingUID == winningUID {
-		losingUID = conflict.NodeUID2
+	var winningUID string
+	verdict, err := m.curateWithAI(ctx, node1, node2)
+	switch {
+	case err != nil:
+		m.logger.Debug("AI curation unavailable, using temporal heuristic", zap.Error(err))
+		winningUID = m.determineWinner(node1, node2)
+	case verdict.Decision == curation.DecisionBothValid:
+		// Complementary facts (or an abstention): keep both, archive nothing
+		m.logger.Info("Kept both facts",
+			zap.String("node1", node1.UID),
+			zap.String("node2", node2.UID),
+			zap.Float64("confidence", verdict.Confidence),
+			zap.String("reason", verdict.Reason))
+		return nil
+	case verdict.WinnerIndex == 1:
+		winningUID = node1.UID
+	case verdict.WinnerIndex == 2:
+		winningUID = node2.UID
+	default:
+		winningUID = m.determineWinner(node1, node2)
 	}
 
+	winner, loser := node1, node2
+	if winningUID != node1.UID {
+		winner, loser = node2, node1
+	}
+	losingUID := loser.UID
+
 	// Create supersedes edge
 	if err := m.graphClient.CreateEdge(ctx, winningUID, losingUID, graph.EdgeTypeSupersedes, graph.EdgeStatusCurrent); err != nil {
 		m.logger.Warn("Failed to create supersedes edge", zap.Error(err))
 	}
 
+	// The loser stopped being true when the winning fact was learned
+	// (unless it already carried an earlier end date)
+	validUntil := winner.CreatedAt
+	if validUntil.IsZero() {
+		validUntil = time.Now()
+	}
+	if loser.ValidUntil != nil && loser.ValidUntil.Before(validUntil) {
+		validUntil = *loser.ValidUntil
+	}
+	if err := m.graphClient.SetFactValidity(ctx, losingUID, validUntil, graph.FactStatusSuperseded); err != nil {
+		m.logger.Warn("Failed to close validity of superseded fact", zap.Error(err))
+	}
+
 	m.logger.Info("Resolved contradiction",
 		zap.String("winner", winningUID),
 		zap.String("archived", losingUID))
@@ -109,6 +147,56 @@ func (m *CurationModule) resolveContradiction(ctx context.Context, conflict grap
 	return nil
 }
 
+// curationVerdict is the ai-service /curate response
+type curationVerdict struct {
+	Decision    string  `json:"decision"`
+	WinnerIndex int     `json:"winner_index"`
+	Confidence  float64 `json:"confidence"`
+	Reason      string  `json:"reason"`
+}
+
+// curateWithAI asks the AI service whether two facts contradict and which one to keep
+func (m *CurationModule) curateWithAI(ctx context.Context, node1, node2 *graph.Node) (*curationVerdict, error) {
+	if m.aiServicesURL == "" {
+		return nil, fmt.Errorf("AI services URL not configured")
+	}
+
+	jsonData, err := json.Marshal(map[string]string{
+		"node1_name":        node1.Name,
+		"node1_description": node1.Description,
+		"node1_created_at":  node1.CreatedAt.Format(time.RFC3339),
+		"node2_name":        node2.Name,
+		"node2_description": node2.Description,
+		"node2_created_at":  node2.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.aiServicesURL+"/curate", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("curation service returned status %d", resp.StatusCode)
+	}
+
+	var verdict curationVerdict
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return nil, err
+	}
+	return &verdict, nil
+}
+
 // determineWinner uses temporal logic to determine which fact is correct
 func (m *CurationModule) determineWinner(node1, node2 *graph.Node) string {
 	// Prefer more recent nodes
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/kernel/events"
 )
 
 // Config holds configuration for the reflection engine
@@ -21,9 +22,18 @@ type Config struct {
 	AIServicesURL    string
 	ActivationConfig graph.ActivationConfig
 
+	// ActivationConfigs overrides ActivationConfig per namespace (nil = no overrides)
+	ActivationConfigs *ActivationConfigStore
+
 	ReflectionInterval time.Duration
 	MinBatchSize       int
 	MaxBatchSize       int
+
+	// Events publishes insight and reflection events (nil = disabled)
+	Events *events.Publisher
+
+	// MaxInsightEvaluations caps AI pair evaluations per cycle (0 = DefaultMaxInsightEvaluations)
+	MaxInsightEvaluations int
 }
 
 // Engine orchestrates all reflection modules
@@ -52,9 +62,14 @@ func NewEngine(cfg Config, logger *zap.Logger) *Engine {
 
 	// Initialize modules
 	e.synthesis = NewSynthesisModule(cfg.GraphClient, cfg.QueryBuilder, cfg.AIServicesURL, logger)
+	e.synthesis.events = cfg.Events
+	if cfg.MaxInsightEvaluations > 0 {
+		e.synthesis.maxEvaluations = cfg.MaxInsightEvaluations
+	}
 	e.anticipation = NewAnticipationModule(cfg.GraphClient, cfg.QueryBuilder, cfg.RedisClient, logger)
 	e.curation = NewCurationModule(cfg.GraphClient, cfg.QueryBuilder, cfg.AIServicesURL, logger)
 	e.prioritization = NewPrioritizationModule(cfg.GraphClient, cfg.QueryBuilder, cfg.RedisClient, cfg.ActivationConfig, logger)
+	e.prioritization.configs = cfg.ActivationConfigs
 
 	return e
 }
@@ -132,6 +147,8 @@ func (e *Engine) RunCycle(ctx context.Context) error {
 		zap.Duration("duration", duration),
 		zap.Int("errors", len(errors)))
 
+	e.config.Events.ReflectionCompleted(cycleNum, duration, len(errors))
+
 	if len(errors) > 0 {
 		return errors[0] // Return first error
 	}
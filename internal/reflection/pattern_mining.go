@@ -0,0 +1,197 @@
+// Package reflection provides pattern mining over the memory timeline.
+// It is the producer for the Pattern nodes read by consultation's checkPatterns.
+package reflection
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+const (
+	// patternMiningWindow is how far back Event/Fact nodes are analyzed
+	patternMiningWindow = 8 * 7 * 24 * time.Hour
+	// patternMiningNodeLimit caps nodes loaded per cycle; the newest are kept
+	patternMiningNodeLimit = 5000
+	// minPatternOccurrences is the number of distinct days a topic must recur on
+	minPatternOccurrences = 3
+	// minWeekdayShare is the share of occurrences on one weekday for a weekly pattern
+	minWeekdayShare = 0.6
+	// maxPeriodicCV is the max coefficient of variation of gaps for a periodic pattern
+	maxPeriodicCV = 0.25
+	// maxTriggerNodes caps trigger_nodes links per pattern
+	maxTriggerNodes = 20
+)
+
+// timelineOccurrence is one dated mention of a topic
+type timelineOccurrence struct {
+	uid string
+	at  time.Time
+}
+
+// mineTimelinePatterns detects recurring topics in each namespace's Event/Fact timeline
+func (m *AnticipationModule) mineTimelinePatterns(ctx context.Context) ([]graph.Pattern, error) {
+	nodes, err := m.queryBuilder.GetTimelineNodes(ctx, time.Now().Add(-patternMiningWindow), patternMiningNodeLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load timeline: %w", err)
+	}
+
+	// namespace -> topic -> occurrences (never mix namespaces)
+	groups := make(map[string]map[string][]timelineOccurrence)
+	for _, n := range nodes {
+		at := n.When()
+		if n.Namespace == "" || at.IsZero() {
+			continue
+		}
+		byTopic := groups[n.Namespace]
+		if byTopic == nil {
+			byTopic = make(map[string][]timelineOccurrence)
+			groups[n.Namespace] = byTopic
+		}
+		for _, topic := range timelineTopics(n.Node) {
+			byTopic[topic] = append(byTopic[topic], timelineOccurrence{uid: n.UID, at: at})
+		}
+	}
+
+	var patterns []graph.Pattern
+	for namespace, byTopic := range groups {
+		for topic, occurrences := range byTopic {
+			if p := detectRecurrence(namespace, topic, occurrences); p != nil {
+				patterns = append(patterns, *p)
+			}
+		}
+	}
+
+	m.logger.Debug("Timeline pattern mining completed",
+		zap.Int("nodes", len(nodes)),
+		zap.Int("namespaces", len(groups)),
+		zap.Int("patterns", len(patterns)))
+
+	return patterns, nil
+}
+
+// timelineTopics returns the keys a node is grouped under: its name and its plain tags
+func timelineTopics(n graph.Node) []string {
+	seen := make(map[string]bool)
+	var topics []string
+	add := func(s string) {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" || seen[s] {
+			return
+		}
+		seen[s] = true
+		topics = append(topics, s)
+	}
+
+	add(n.Name)
+	for _, tag := range n.Tags {
+		// Skip namespaced system tags such as "sensitivity:high"
+		if !strings.Contains(tag, ":") {
+			add(tag)
+		}
+	}
+	return topics
+}
+
+// detectRecurrence returns a weekly or periodic pattern for a topic, or nil.
+// Occurrences are collapsed to distinct days so one long conversation isn't a habit.
+// Days and weekdays are taken in UTC, whatever offset each time was stored with.
+func detectRecurrence(namespace, topic string, occurrences []timelineOccurrence) *graph.Pattern {
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].at.Before(occurrences[j].at) })
+
+	var days []time.Time
+	var triggers []string
+	seenDay := make(map[string]bool)
+	seenUID := make(map[string]bool)
+	for _, o := range occurrences {
+		at := o.at.UTC()
+		day := at.Format("2006-01-02")
+		if !seenDay[day] {
+			seenDay[day] = true
+			days = append(days, at)
+		}
+		if !seenUID[o.uid] && len(triggers) < maxTriggerNodes {
+			seenUID[o.uid] = true
+			triggers = append(triggers, o.uid)
+		}
+	}
+
+	if len(days) < minPatternOccurrences {
+		return nil
+	}
+
+	// More observations -> more trust, saturating at 5 distinct days
+	support := math.Min(1.0, float64(len(days))/5.0)
+
+	// Weekly: most occurrences land on the same weekday
+	var byWeekday [7]int
+	for _, d := range days {
+		byWeekday[d.Weekday()]++
+	}
+	topDay, topCount := time.Sunday, 0
+	for wd, count := range byWeekday {
+		if count > topCount {
+			topDay, topCount = time.Weekday(wd), count
+		}
+	}
+	share := float64(topCount) / float64(len(days))
+	if topCount >= minPatternOccurrences && share >= minWeekdayShare {
+		return &graph.Pattern{
+			Node: graph.Node{
+				DType:     []string{string(graph.NodeTypePattern)},
+				Name:      fmt.Sprintf("Pattern: %s on %ss", topic, topDay),
+				Namespace: namespace,
+			},
+			PatternType:     "weekly",
+			TriggerNodes:    triggers,
+			Frequency:       topCount,
+			ConfidenceScore: share * support,
+			PredictedAction: fmt.Sprintf("User tends to bring up %s on %ss; have related context ready", topic, topDay),
+		}
+	}
+
+	// Periodic: gaps between occurrences are roughly constant
+	gaps := make([]float64, 0, len(days)-1)
+	for i := 1; i < len(days); i++ {
+		gaps = append(gaps, days[i].Sub(days[i-1]).Hours())
+	}
+	var mean float64
+	for _, g := range gaps {
+		mean += g
+	}
+	mean /= float64(len(gaps))
+	if mean < 24 {
+		return nil
+	}
+	var variance float64
+	for _, g := range gaps {
+		variance += (g - mean) * (g - mean)
+	}
+	cv := math.Sqrt(variance/float64(len(gaps))) / mean
+	if cv > maxPeriodicCV {
+		return nil
+	}
+
+	periodDays := int(math.Round(mean / 24))
+	next := days[len(days)-1].Add(time.Duration(mean * float64(time.Hour)))
+	return &graph.Pattern{
+		Node: graph.Node{
+			DType:     []string{string(graph.NodeTypePattern)},
+			Name:      fmt.Sprintf("Pattern: %s (periodic)", topic),
+			Namespace: namespace,
+		},
+		PatternType:     "periodic",
+		TriggerNodes:    triggers,
+		Frequency:       len(days),
+		ConfidenceScore: (1 - cv) * support,
+		PredictedAction: fmt.Sprintf("User returns to %s about every %d days; next expected around %s",
+			topic, periodDays, next.Format("2006-01-02")),
+	}
+}
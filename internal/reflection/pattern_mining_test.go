@@ -0,0 +1,60 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectRecurrenceWeeklyAcrossOffsets(t *testing.T) {
+	eastern := time.FixedZone("EST", -5*60*60)
+	occurrences := []timelineOccurrence{
+		{uid: "0x1", at: time.Date(2026, 1, 6, 1, 0, 0, 0, time.UTC)},
+		// Tuesday 01:00 UTC, stored with the offset it was said in
+		{uid: "0x2", at: time.Date(2026, 1, 12, 20, 0, 0, 0, eastern)},
+		{uid: "0x2", at: time.Date(2026, 1, 12, 20, 30, 0, 0, eastern)},
+		{uid: "0x3", at: time.Date(2026, 1, 20, 1, 0, 0, 0, time.UTC)},
+	}
+
+	p := detectRecurrence("user_alice", "gym", occurrences)
+	if p == nil || p.PatternType != "weekly" {
+		t.Fatalf("pattern = %+v, want weekly", p)
+	}
+	if p.Name != "Pattern: gym on Tuesdays" || p.Frequency != 3 {
+		t.Errorf("pattern = %q with frequency %d, want Tuesdays 3 times", p.Name, p.Frequency)
+	}
+	if !reflect.DeepEqual(p.TriggerNodes, []string{"0x1", "0x2", "0x3"}) {
+		t.Errorf("trigger nodes = %v, want each node once", p.TriggerNodes)
+	}
+}
+
+func TestDetectRecurrencePeriodic(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	var occurrences []timelineOccurrence
+	for i := 3; i >= 0; i-- { // newest first, as the timeline is read
+		occurrences = append(occurrences, timelineOccurrence{uid: fmt.Sprintf("0x%d", i+1), at: start.AddDate(0, 0, 10*i)})
+	}
+
+	p := detectRecurrence("user_alice", "haircut", occurrences)
+	if p == nil || p.PatternType != "periodic" {
+		t.Fatalf("pattern = %+v, want periodic", p)
+	}
+	if !strings.Contains(p.PredictedAction, "every 10 days") || !strings.Contains(p.PredictedAction, "2026-02-10") {
+		t.Errorf("predicted action = %q, want every 10 days, next on 2026-02-10", p.PredictedAction)
+	}
+}
+
+func TestDetectRecurrenceNeedsDistinctDays(t *testing.T) {
+	day := time.Date(2026, 1, 6, 9, 0, 0, 0, time.UTC)
+	occurrences := []timelineOccurrence{
+		{uid: "0x1", at: day},
+		{uid: "0x2", at: day.Add(time.Hour)},
+		{uid: "0x3", at: day.Add(2 * time.Hour)},
+		{uid: "0x4", at: day.AddDate(0, 0, 7)},
+	}
+	if p := detectRecurrence("user_alice", "standup", occurrences); p != nil {
+		t.Errorf("pattern = %+v from two distinct days, want none", p)
+	}
+}
@@ -22,6 +22,9 @@ type PrioritizationModule struct {
 	redisClient  *redis.Client
 	config       graph.ActivationConfig
 	logger       *zap.Logger
+
+	// Per-namespace overrides of config for boost and decay (nil = config everywhere)
+	configs *ActivationConfigStore
 }
 
 // NewPrioritizationModule creates a new prioritization module
@@ -41,6 +44,14 @@ func NewPrioritizationModule(
 	}
 }
 
+// configFor returns the activation config for a node's namespace
+func (m *PrioritizationModule) configFor(ctx context.Context, namespace string) graph.ActivationConfig {
+	if m.configs == nil {
+		return m.config
+	}
+	return m.configs.Get(ctx, namespace)
+}
+
 // Run executes the prioritization module
 func (m *PrioritizationModule) Run(ctx context.Context) error {
 	m.logger.Debug("Dynamic Prioritization: Starting activation update")
@@ -75,15 +86,18 @@ func (m *PrioritizationModule) Run(ctx context.Context) error {
 
 // ApplyDecay applies activation decay to all nodes based on time since last access
 // Uses distributed locking to prevent race conditions during concurrent updates
+// Pinned nodes are left alone
 func (m *PrioritizationModule) ApplyDecay(ctx context.Context) error {
 	m.logger.Debug("Applying activation decay")
 
-	// Get all nodes with activation > minimum
+	// Get all unpinned nodes with activation > minimum
 	query := `{
-		nodes(func: gt(activation, 0.01)) {
+		nodes(func: gt(activation, 0.01)) @filter(NOT eq(pinned, true)) {
 			uid
 			activation
 			last_accessed
+			namespace
+			pinned
 		}
 	}`
 
@@ -97,6 +111,8 @@ func (m *PrioritizationModule) ApplyDecay(ctx context.Context) error {
 			UID          string    `json:"uid"`
 			Activation   float64   `json:"activation"`
 			LastAccessed time.Time `json:"last_accessed"`
+			Namespace    string    `json:"namespace"`
+			Pinned       bool      `json:"pinned"`
 		} `json:"nodes"`
 	}
 	if err := json.Unmarshal(resp, &result); err != nil {
@@ -106,20 +122,11 @@ func (m *PrioritizationModule) ApplyDecay(ctx context.Context) error {
 	decayed := 0
 	now := time.Now()
 	for _, node := range result.Nodes {
-		daysSinceAccess := now.Sub(node.LastAccessed).Hours() / 24
-		// Apply decay to nodes not accessed in last 1 day
-		if daysSinceAccess < 1.0 {
+		newActivation, ok := decayedActivation(node.Activation, node.LastAccessed, now, node.Pinned, m.configFor(ctx, node.Namespace))
+		if !ok {
 			continue
 		}
 
-		// Exponential decay: newActivation = activation * (1 - decayRate)^days
-		decayFactor := math.Pow(1-m.config.DecayRate, daysSinceAccess)
-		newActivation := node.Activation * decayFactor
-
-		if newActivation < m.config.MinActivation {
-			newActivation = m.config.MinActivation
-		}
-
 		// Use distributed lock to prevent race conditions
 		// SECURITY: Adaptive lock with 30s timeout instead of fixed 5s
 		// This prevents lock expiration during high load scenarios
@@ -165,6 +172,28 @@ func (m *PrioritizationModule) ApplyDecay(ctx context.Context) error {
 	return nil
 }
 
+// decayedActivation returns the activation a node decays to by now, and false
+// when it should be left as it is: pinned, or accessed within the last day
+func decayedActivation(activation float64, lastAccessed, now time.Time, pinned bool, config graph.ActivationConfig) (float64, bool) {
+	if pinned {
+		return activation, false
+	}
+	daysSinceAccess := now.Sub(lastAccessed).Hours() / 24
+	// Apply decay to nodes not accessed in last 1 day
+	if daysSinceAccess < 1.0 {
+		return activation, false
+	}
+
+	// Exponential decay: newActivation = activation * (1 - decayRate)^days
+	decayFactor := math.Pow(1-config.DecayRate, daysSinceAccess)
+	newActivation := activation * decayFactor
+
+	if newActivation < config.MinActivation {
+		newActivation = config.MinActivation
+	}
+	return newActivation, true
+}
+
 // getHighFrequencyNodes returns nodes with high access counts
 func (m *PrioritizationModule) getHighFrequencyNodes(ctx context.Context) ([]graph.Node, error) {
 	query := `{
@@ -224,9 +253,10 @@ func (m *PrioritizationModule) boostActivationUnsafe(ctx context.Context, uid st
 		return err
 	}
 
-	newActivation := node.Activation + m.config.BoostPerAccess
-	if newActivation > m.config.MaxActivation {
-		newActivation = m.config.MaxActivation
+	config := m.configFor(ctx, node.Namespace)
+	newActivation := node.Activation + config.BoostPerAccess
+	if newActivation > config.MaxActivation {
+		newActivation = config.MaxActivation
 	}
 
 	return m.graphClient.UpdateNodeActivation(ctx, uid, newActivation)
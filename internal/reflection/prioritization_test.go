@@ -0,0 +1,39 @@
+package reflection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+// TestDecaySparesPinnedNodes runs a month of daily decay passes over a pinned
+// and an unpinned node that are never accessed
+func TestDecaySparesPinnedNodes(t *testing.T) {
+	config := graph.DefaultActivationConfig()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type node struct {
+		activation   float64
+		lastAccessed time.Time
+		pinned       bool
+	}
+	pinned := &node{activation: 0.8, lastAccessed: start, pinned: true}
+	unpinned := &node{activation: 0.8, lastAccessed: start}
+
+	for day := 1; day <= 30; day++ {
+		now := start.Add(time.Duration(day) * 24 * time.Hour)
+		for _, n := range []*node{pinned, unpinned} {
+			if activation, ok := decayedActivation(n.activation, n.lastAccessed, now, n.pinned, config); ok {
+				n.activation, n.lastAccessed = activation, now // As UpdateNodeActivation does
+			}
+		}
+	}
+
+	if pinned.activation != 0.8 {
+		t.Errorf("pinned activation = %v, want 0.8 after a month", pinned.activation)
+	}
+	if unpinned.activation >= 0.8 {
+		t.Errorf("unpinned activation = %v, want it to have decayed from 0.8", unpinned.activation)
+	}
+}
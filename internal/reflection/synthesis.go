@@ -8,20 +8,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/reflective-memory-kernel/internal/graph"
+	"github.com/reflective-memory-kernel/internal/kernel/events"
 )
 
+// DefaultMaxInsightEvaluations bounds AI pair evaluations per reflection cycle
+const DefaultMaxInsightEvaluations = 10
+
+// rejectedPairTTL is how long a pair the AI found no insight in is left out
+// of later cycles, so the budget moves on to pairs not yet evaluated
+const rejectedPairTTL = 24 * time.Hour
+
 // SynthesisModule discovers new insights by connecting disparate facts
 type SynthesisModule struct {
 	graphClient   *graph.Client
 	queryBuilder  *graph.QueryBuilder
 	aiServicesURL string
 	logger        *zap.Logger
+	events        *events.Publisher
+
+	// maxEvaluations caps /synthesize-insight calls per Run (cost control)
+	maxEvaluations int
+
+	mu sync.Mutex
+	// rejected maps pairKey to when a pair without an insight may be evaluated again
+	rejected map[string]time.Time
+	// cycle rotates which namespace leads the budget each Run
+	cycle int
 }
 
 // NewSynthesisModule creates a new synthesis module
@@ -32,10 +51,12 @@ func NewSynthesisModule(
 	logger *zap.Logger,
 ) *SynthesisModule {
 	return &SynthesisModule{
-		graphClient:   graphClient,
-		queryBuilder:  queryBuilder,
-		aiServicesURL: aiServicesURL,
-		logger:        logger,
+		graphClient:    graphClient,
+		queryBuilder:   queryBuilder,
+		aiServicesURL:  aiServicesURL,
+		logger:         logger,
+		maxEvaluations: DefaultMaxInsightEvaluations,
+		rejected:       make(map[string]time.Time),
 	}
 }
 
@@ -43,35 +64,69 @@ func NewSynthesisModule(
 func (m *SynthesisModule) Run(ctx context.Context) error {
 	m.logger.Debug("Active Synthesis: Starting insight discovery")
 
-	// Step 1: Get high-activation nodes (core knowledge)
-	coreNodes, err := m.queryBuilder.GetHighActivationNodes(ctx, "", 0.6, 20)
+	// Step 1: Get high-activation nodes (core knowledge) across namespaces
+	coreNodes, err := m.queryBuilder.GetInsightCandidates(ctx, 0.6, 100)
 	if err != nil {
 		return fmt.Errorf("failed to get core nodes: %w", err)
 	}
 
-	if len(coreNodes) < 2 {
-		m.logger.Debug("Not enough nodes for synthesis")
-		return nil
+	// SECURITY: Only ever pair nodes from the same namespace
+	byNamespace := make(map[string][]graph.Node)
+	var namespaces []string
+	for _, node := range coreNodes {
+		if node.Namespace == "" {
+			continue
+		}
+		if _, ok := byNamespace[node.Namespace]; !ok {
+			namespaces = append(namespaces, node.Namespace)
+		}
+		byNamespace[node.Namespace] = append(byNamespace[node.Namespace], node)
 	}
 
-	// Step 2: Find potential connections between disparate nodes
-	potentialConnections, err := m.findPotentialConnections(ctx, coreNodes)
-	if err != nil {
-		m.logger.Warn("Failed to find potential connections", zap.Error(err))
+	// Step 2: Find potential connections between disparate nodes, within
+	// budget. Each cycle a different namespace leads, and each is offered an
+	// even share, so the first namespaces can't use the whole budget.
+	m.mu.Lock()
+	start := m.cycle
+	m.cycle++
+	m.mu.Unlock()
+	var potentialConnections []PotentialConnection
+	spreadBudget(namespaces, m.maxEvaluations, start, func(ns string, maxPairs int) int {
+		nodes := byNamespace[ns]
+		if len(nodes) < 2 {
+			return 0
+		}
+		connections, err := m.findPotentialConnections(ctx, nodes, maxPairs)
+		if err != nil {
+			m.logger.Warn("Failed to find potential connections", zap.String("namespace", ns), zap.Error(err))
+			return 0
+		}
+		potentialConnections = append(potentialConnections, connections...)
+		return len(connections)
+	})
+
+	if len(potentialConnections) == 0 {
+		m.logger.Debug("Not enough nodes for synthesis")
+		return nil
 	}
 
 	// Step 3: Use AI to evaluate and create insights
+	created := 0
 	for _, connection := range potentialConnections {
 		insight, err := m.evaluateConnection(ctx, connection)
 		if err != nil {
 			m.logger.Warn("Failed to evaluate connection", zap.Error(err))
 			continue
 		}
+		if insight == nil {
+			m.reject(connection.Node1, connection.Node2, time.Now())
+		}
 
 		if insight != nil {
 			if err := m.createInsight(ctx, insight); err != nil {
 				m.logger.Error("Failed to create insight", zap.Error(err))
 			} else {
+				created++
 				m.logger.Info("Created new insight",
 					zap.String("type", insight.InsightType),
 					zap.String("summary", insight.Summary))
@@ -79,9 +134,63 @@ func (m *SynthesisModule) Run(ctx context.Context) error {
 		}
 	}
 
+	m.logger.Info("Synthesis completed",
+		zap.Int("pairs_evaluated", len(potentialConnections)),
+		zap.Int("insights_created", created))
+
 	return nil
 }
 
+// insightName is the deterministic name of the insight linking two nodes
+func insightName(node1, node2 graph.Node) string {
+	return fmt.Sprintf("Insight: %s <-> %s", node1.Name, node2.Name)
+}
+
+// spreadBudget hands budget out across namespaces, starting at index start
+// (wrapping) so each cycle leads with a different one. Each namespace is
+// offered an even share of what is left among those still to come, so what
+// one doesn't use passes on. use evaluates up to maxPairs pairs in a
+// namespace and returns how many it took.
+func spreadBudget(namespaces []string, budget, start int, use func(ns string, maxPairs int) int) {
+	for k := range namespaces {
+		if budget <= 0 {
+			return
+		}
+		left := len(namespaces) - k
+		share := (budget + left - 1) / left
+		budget -= min(use(namespaces[(start+k)%len(namespaces)], share), share)
+	}
+}
+
+// pairKey identifies a pair of nodes in either order
+func pairKey(node1, node2 graph.Node) string {
+	if node2.UID < node1.UID {
+		node1, node2 = node2, node1
+	}
+	return node1.Namespace + "|" + node1.UID + "|" + node2.UID
+}
+
+// reject remembers that the AI found no insight in a pair, and forgets
+// rejections that have expired
+func (m *SynthesisModule) reject(node1, node2 graph.Node, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, until := range m.rejected {
+		if !now.Before(until) {
+			delete(m.rejected, key)
+		}
+	}
+	m.rejected[pairKey(node1, node2)] = now.Add(rejectedPairTTL)
+}
+
+// isRejected reports whether a pair was found to have no insight recently
+func (m *SynthesisModule) isRejected(node1, node2 graph.Node, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	until, ok := m.rejected[pairKey(node1, node2)]
+	return ok && now.Before(until)
+}
+
 // PotentialConnection represents a potential connection between nodes
 type PotentialConnection struct {
 	Node1         graph.Node
@@ -91,9 +200,12 @@ type PotentialConnection struct {
 	SharedContext []string
 }
 
-// findPotentialConnections finds nodes that might be connected but aren't yet
+// findPotentialConnections finds nodes that might be connected but aren't yet.
+// Nodes must share a namespace. Returns at most maxPairs pairs, skipping pairs
+// that already have an insight (looked up for every pair in one query) and
+// pairs recently found to have none.
 // OPTIMIZATION: Batch path queries and cache results to avoid N+1 query problem
-func (m *SynthesisModule) findPotentialConnections(ctx context.Context, nodes []graph.Node) ([]PotentialConnection, error) {
+func (m *SynthesisModule) findPotentialConnections(ctx context.Context, nodes []graph.Node, maxPairs int) ([]PotentialConnection, error) {
 	var connections []PotentialConnection
 
 	// OPTIMIZATION: Limit pairs to check to reduce query count
@@ -104,63 +216,89 @@ func (m *SynthesisModule) findPotentialConnections(ctx context.Context, nodes []
 		nodes = nodes[:maxNodesToCheck]
 	}
 
-	// OPTIMIZATION: Cache path results to avoid duplicate queries
-	// Map of "uid1-uid2" -> path length
-	pathCache := make(map[string]int)
-
-	// Check pairs of nodes for potential connections
+	now := time.Now()
+	var pairs [][2]int
+	var names []string
 	for i := 0; i < len(nodes); i++ {
 		for j := i + 1; j < len(nodes); j++ {
-			// Generate cache key (sorted UIDs for consistency)
-			cacheKey := nodes[i].UID + "-" + nodes[j].UID
-			if nodes[j].UID < nodes[i].UID {
-				cacheKey = nodes[j].UID + "-" + nodes[i].UID
-			}
-
-			// Check cache first
-			if pathLength, cached := pathCache[cacheKey]; cached {
-				connection := PotentialConnection{
-					Node1:      nodes[i],
-					Node2:      nodes[j],
-					PathExists: pathLength > 0,
-					PathLength: pathLength,
-				}
-				connections = append(connections, connection)
+			if m.isRejected(nodes[i], nodes[j], now) {
 				continue
 			}
+			pairs = append(pairs, [2]int{i, j})
+			names = append(names, insightName(nodes[i], nodes[j]), insightName(nodes[j], nodes[i]))
+		}
+	}
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	existing, err := m.graphClient.ExistingNames(ctx, nodes[0].Namespace, names, graph.NodeTypeInsight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing insights: %w", err)
+	}
 
-			// Check if path exists between nodes
-			namespace := nodes[i].Namespace
-			if namespace == "" {
-				namespace = nodes[j].Namespace
-			}
-			pathData, err := m.queryBuilder.FindPathBetweenNodes(ctx, nodes[i].UID, nodes[j].UID, namespace)
-			if err != nil {
-				continue
-			}
+	// OPTIMIZATION: Cache path results to avoid duplicate queries
+	// Map of "uid1-uid2" -> path length
+	pathCache := make(map[string]int)
 
-			var pathResult struct {
-				PathNodes []graph.Node `json:"path_nodes"`
-			}
-			if err := json.Unmarshal(pathData, &pathResult); err != nil {
-				continue
-			}
+	// Check pairs of nodes for potential connections
+	for _, pair := range pairs {
+		i, j := pair[0], pair[1]
+		if len(connections) >= maxPairs {
+			return connections, nil
+		}
+		if existing[insightName(nodes[i], nodes[j])] || existing[insightName(nodes[j], nodes[i])] {
+			continue
+		}
 
-			pathLength := len(pathResult.PathNodes)
-			pathCache[cacheKey] = pathLength // Cache for reuse
+		// Generate cache key (sorted UIDs for consistency)
+		cacheKey := nodes[i].UID + "-" + nodes[j].UID
+		if nodes[j].UID < nodes[i].UID {
+			cacheKey = nodes[j].UID + "-" + nodes[i].UID
+		}
 
+		// Check cache first
+		if pathLength, cached := pathCache[cacheKey]; cached {
 			connection := PotentialConnection{
 				Node1:      nodes[i],
 				Node2:      nodes[j],
 				PathExists: pathLength > 0,
 				PathLength: pathLength,
 			}
-
-			// We're interested in both connected and unconnected pairs
-			// Connected pairs might reveal emergent properties
-			// Unconnected pairs might need a new connection
 			connections = append(connections, connection)
+			continue
+		}
+
+		// Check if path exists between nodes
+		namespace := nodes[i].Namespace
+		if namespace == "" {
+			namespace = nodes[j].Namespace
+		}
+		pathData, err := m.queryBuilder.FindPathBetweenNodes(ctx, nodes[i].UID, nodes[j].UID, namespace)
+		if err != nil {
+			continue
+		}
+
+		var pathResult struct {
+			PathNodes []graph.Node `json:"path_nodes"`
 		}
+		if err := json.Unmarshal(pathData, &pathResult); err != nil {
+			continue
+		}
+
+		pathLength := len(pathResult.PathNodes)
+		pathCache[cacheKey] = pathLength // Cache for reuse
+
+		connection := PotentialConnection{
+			Node1:      nodes[i],
+			Node2:      nodes[j],
+			PathExists: pathLength > 0,
+			PathLength: pathLength,
+		}
+
+		// We're interested in both connected and unconnected pairs
+		// Connected pairs might reveal emergent properties
+		// Unconnected pairs might need a new connection
+		connections = append(connections, connection)
 	}
 
 	m.logger.Debug("Synthesis path query optimization",
@@ -237,9 +375,10 @@ func (m *SynthesisModule) evaluateConnection(ctx context.Context, conn Potential
 
 	insight := &graph.Insight{
 		Node: graph.Node{
-			UID:   uuid.New().String(),
-			DType: []string{string(graph.NodeTypeInsight)},
-			Name:  fmt.Sprintf("Insight: %s <-> %s", conn.Node1.Name, conn.Node2.Name),
+			UID:       uuid.New().String(),
+			DType:     []string{string(graph.NodeTypeInsight)},
+			Name:      insightName(conn.Node1, conn.Node2),
+			Namespace: conn.Node1.Namespace,
 		},
 		InsightType:      result.InsightType,
 		SourceNodeUIDs:   []string{conn.Node1.UID, conn.Node2.UID},
@@ -253,28 +392,14 @@ func (m *SynthesisModule) evaluateConnection(ctx context.Context, conn Potential
 
 // createInsight creates an insight node in the graph
 func (m *SynthesisModule) createInsight(ctx context.Context, insight *graph.Insight) error {
-	node := &graph.Node{
-		DType:       []string{string(graph.NodeTypeInsight)},
-		Name:        insight.Name,
-		Description: insight.Summary,
-		Activation:  0.8, // New insights start with high activation
-		Confidence:  insight.Confidence,
-	}
+	insight.Activation = 0.8 // New insights start with high activation
 
-	uid, err := m.graphClient.CreateNode(ctx, node)
+	// Persists summary/type/action and source_nodes so GetInsights can surface it
+	uid, err := m.graphClient.CreateInsight(ctx, insight)
 	if err != nil {
 		return err
 	}
-
-	// Link insight to source nodes
-	for _, sourceUID := range insight.SourceNodeUIDs {
-		if err := m.graphClient.CreateEdge(ctx, uid, sourceUID, graph.EdgeTypeSynthesized, graph.EdgeStatusCurrent); err != nil {
-			m.logger.Warn("Failed to link insight to source",
-				zap.String("insight", uid),
-				zap.String("source", sourceUID),
-				zap.Error(err))
-		}
-	}
+	m.events.InsightGenerated(insight.Namespace, uid, insight.InsightType, insight.SourceNodeUIDs)
 
 	return nil
 }
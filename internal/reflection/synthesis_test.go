@@ -0,0 +1,66 @@
+package reflection
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/reflective-memory-kernel/internal/graph"
+)
+
+func TestSpreadBudgetReachesEveryNamespace(t *testing.T) {
+	namespaces := []string{"user_a", "user_b", "user_c", "user_d"}
+	pairs := map[string]int{"user_a": 45, "user_b": 1, "user_c": 45, "user_d": 45}
+
+	taken := make(map[string]int)
+	var order []string
+	spreadBudget(namespaces, 10, 1, func(ns string, maxPairs int) int {
+		order = append(order, ns)
+		taken[ns] = min(pairs[ns], maxPairs)
+		return taken[ns]
+	})
+
+	if want := []string{"user_b", "user_c", "user_d", "user_a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("namespaces offered in order %v, want %v", order, want)
+	}
+	// user_b's unused share passes on to the rest
+	if want := map[string]int{"user_a": 3, "user_b": 1, "user_c": 3, "user_d": 3}; !reflect.DeepEqual(taken, want) {
+		t.Errorf("pairs taken = %v, want %v", taken, want)
+	}
+
+	// A budget smaller than the namespaces goes to the ones leading this cycle
+	order = nil
+	spreadBudget(namespaces, 2, 3, func(ns string, maxPairs int) int {
+		order = append(order, ns)
+		return maxPairs
+	})
+	if want := []string{"user_d", "user_a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("namespaces offered in order %v, want %v", order, want)
+	}
+}
+
+func TestRejectedPairsExpire(t *testing.T) {
+	m := NewSynthesisModule(nil, nil, "", zap.NewNop())
+	alice := graph.Node{UID: "0x1", Namespace: "user_alice"}
+	bob := graph.Node{UID: "0x2", Namespace: "user_alice"}
+	carol := graph.Node{UID: "0x3", Namespace: "user_alice"}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m.reject(alice, bob, now)
+	if !m.isRejected(bob, alice, now.Add(time.Hour)) {
+		t.Error("pair not rejected in the other order")
+	}
+	if m.isRejected(alice, carol, now) {
+		t.Error("unrelated pair rejected")
+	}
+	if m.isRejected(alice, bob, now.Add(rejectedPairTTL)) {
+		t.Error("rejection outlived its TTL")
+	}
+
+	m.reject(alice, carol, now.Add(rejectedPairTTL))
+	if len(m.rejected) != 1 {
+		t.Errorf("%d rejections kept, want the expired one dropped", len(m.rejected))
+	}
+}
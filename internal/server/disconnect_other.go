@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd)
+
+package server
+
+import "context"
+
+// watchDisconnect is a no-op on platforms without non-blocking socket peeks;
+// requests are still bounded by their deadline.
+func watchDisconnect(fd int, cancel context.CancelFunc) (stop func()) {
+	return func() {}
+}
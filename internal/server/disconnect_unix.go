@@ -0,0 +1,40 @@
+//go:build linux || darwin || freebsd
+
+package server
+
+import (
+	"context"
+	"syscall"
+	"time"
+)
+
+// watchDisconnect cancels the request context when the peer closes its end of
+// the socket. Handlers run on the event loop, so gnet cannot deliver OnClose
+// while one is blocked; instead we peek the fd from a side goroutine.
+// Returns a stop function that must be called before the handler returns.
+func watchDisconnect(fd int, cancel context.CancelFunc) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+		buf := make([]byte, 1)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				n, _, err := syscall.Recvfrom(fd, buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+				if n == 0 && err == nil {
+					// Orderly shutdown from the peer (EOF)
+					cancel()
+					return
+				}
+				if err != nil && err != syscall.EAGAIN && err != syscall.EWOULDBLOCK && err != syscall.EINTR {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"net"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
 
@@ -36,11 +35,12 @@ type Engine struct {
 	server         gnet.Engine
 	shutdownCtx    context.Context
 	shutdownCancel context.CancelFunc
-	shutdownWG     sync.WaitGroup
+	draining       atomic.Bool // set by Shutdown; rejects new connections and requests
 
 	// Statistics
 	activeConns    atomic.Int64
 	totalReq       atomic.Int64
+	inflight       atomic.Int64 // requests currently inside a handler
 
 	// TLS configuration
 	tlsConfig      *tls.Config
@@ -92,6 +92,15 @@ type Options struct {
 
 	// Static file route prefix
 	StaticPrefix string
+
+	// Cancel the request context when the client disconnects mid-request
+	CancelOnDisconnect bool
+
+	// Largest request body, with per-path overrides in BodyLimits (0 = no
+	// limit beyond ParseJSON's default). A body over the limit is refused
+	// with 413 from its Content-Length, before the rest of it is read.
+	MaxBodyBytes int64
+	BodyLimits   map[string]int64
 }
 
 // DefaultOptions returns default options for the engine
@@ -139,8 +148,18 @@ func New(addr string, opts *Options) *Engine {
 	}
 }
 
+// OnBoot captures the running gnet engine so Shutdown can stop it
+func (e *Engine) OnBoot(eng gnet.Engine) gnet.Action {
+	e.server = eng
+	return gnet.None
+}
+
 // OnOpen handles connection open events
 func (e *Engine) OnOpen(c gnet.Conn) ([]byte, gnet.Action) {
+	// Stop accepting new connections while draining
+	if e.draining.Load() {
+		return nil, gnet.Close
+	}
 	e.activeConns.Add(1)
 	e.logger.Debug("connection opened",
 		zap.String("remote", c.RemoteAddr().String()),
@@ -160,8 +179,34 @@ func (e *Engine) OnClose(c gnet.Conn, err error) gnet.Action {
 
 // OnTraffic handles incoming data on a connection
 func (e *Engine) OnTraffic(c gnet.Conn) gnet.Action {
+	// Reject new requests on existing keep-alive connections while draining
+	if e.draining.Load() {
+		return e.writeErrorResponse(c, 503, "Service Unavailable")
+	}
+
+	// Wait until the whole body has arrived, refusing one over the path's
+	// limit from its headers so the rest of it is never read
+	if head, _ := c.Peek(-1); len(head) > 0 {
+		if req, err := ParseRequest(head, nil); err == nil {
+			ready, err := e.bodyReady(req)
+			if err != nil {
+				c.Discard(-1)
+				e.logger.Warn("request body too large",
+					zap.String("remote", c.RemoteAddr().String()),
+					zap.String("path", req.Path),
+					zap.Error(err))
+				return e.writeErrorResponse(c, 413, "Request Entity Too Large")
+			}
+			if !ready {
+				return gnet.None
+			}
+		}
+	}
+
 	// Increment request counter (approximate)
 	e.totalReq.Add(1)
+	e.inflight.Add(1)
+	defer e.inflight.Add(-1)
 
 	// Read all available data
 	buf, _ := c.Next(-1)
@@ -177,6 +222,18 @@ func (e *Engine) OnTraffic(c gnet.Conn) gnet.Action {
 
 	// Attach connection to request
 	req.conn = c
+	if limit := e.bodyLimit(req.Path); limit > 0 {
+		req.SetMaxBodyBytes(limit)
+	}
+
+	// Request-scoped context: cancelled on shutdown timeout or (optionally) client disconnect
+	ctx, cancel := context.WithCancel(e.shutdownCtx)
+	defer cancel()
+	req.ctx = ctx
+	if e.options.CancelOnDisconnect {
+		stop := watchDisconnect(c.Fd(), cancel)
+		defer stop()
+	}
 
 	// Store connection state
 	if state, ok := c.Context().(*connState); ok {
@@ -211,6 +268,25 @@ type connState struct {
 	Headers     map[string]string
 }
 
+// bodyLimit returns the largest body accepted on path, 0 for no limit
+func (e *Engine) bodyLimit(path string) int64 {
+	if limit, ok := e.options.BodyLimits[path]; ok {
+		return limit
+	}
+	return e.options.MaxBodyBytes
+}
+
+// bodyReady reports whether req holds the whole body its Content-Length
+// declares. A body over the path's limit fails with ErrBodyTooLarge as soon
+// as the headers declare it.
+func (e *Engine) bodyReady(req *Request) (bool, error) {
+	length := max(req.ContentLength(), int64(len(req.Body)))
+	if limit := e.bodyLimit(req.Path); limit > 0 && length > limit {
+		return false, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrBodyTooLarge, length, limit)
+	}
+	return int64(len(req.Body)) >= length, nil
+}
+
 // routeRequest routes the request through middleware and handlers
 func (e *Engine) routeRequest(req *Request) *Response {
 	// Build middleware chain
@@ -235,6 +311,14 @@ func (e *Engine) writeResponse(c gnet.Conn, resp *Response) gnet.Action {
 		return gnet.Close
 	}
 
+	// A streamed response has already been written by its StreamWriter
+	if resp.headersWritten {
+		if !resp.KeepAlive {
+			return gnet.Close
+		}
+		return gnet.None
+	}
+
 	// Build response bytes
 	data := resp.Build()
 
@@ -343,26 +427,47 @@ func (e *Engine) Start() error {
 	return nil
 }
 
-// Shutdown gracefully shuts down the server
+// Shutdown gracefully shuts down the server.
+// New connections and requests are rejected immediately; in-flight handlers
+// are allowed to finish until ctx expires, after which request contexts are
+// cancelled so long-running upstream calls can abort. The gnet engine is
+// stopped once draining completes.
 func (e *Engine) Shutdown(ctx context.Context) error {
-	e.logger.Info("shutting down server...")
+	e.draining.Store(true)
+	pending := e.inflight.Load()
+	e.logger.Info("shutting down server, draining in-flight requests",
+		zap.Int64("in_flight", pending))
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	var drainErr error
+	for e.inflight.Load() > 0 && drainErr == nil {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			drainErr = ctx.Err()
+		}
+	}
+
+	remaining := e.inflight.Load()
+	if drainErr != nil {
+		e.logger.Warn("shutdown timeout exceeded, cancelling in-flight requests",
+			zap.Int64("drained", pending-remaining),
+			zap.Int64("cancelled", remaining))
+	} else {
+		e.logger.Info("in-flight requests drained", zap.Int64("drained", pending))
+	}
 	e.shutdownCancel()
 
-	// Wait for connections to drain or timeout
-	done := make(chan struct{})
-	go func() {
-		e.shutdownWG.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		e.logger.Info("all connections closed gracefully")
-		return nil
-	case <-ctx.Done():
-		e.logger.Warn("shutdown timeout exceeded, forcing close")
-		return ctx.Err()
+	// Stop the event loops. Use a fresh deadline because ctx may already be done.
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.server.Stop(stopCtx); err != nil {
+		e.logger.Warn("failed to stop gnet engine", zap.Error(err))
 	}
+
+	return drainErr
 }
 
 // Stats returns server statistics
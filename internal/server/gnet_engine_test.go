@@ -0,0 +1,47 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBodyReadyEnforcesPathBodyLimit(t *testing.T) {
+	e := New(":0", &Options{MaxBodyBytes: 64, BodyLimits: map[string]int64{"/extract-vision": 1024}})
+
+	request := func(path string, declared int, body string) *Request {
+		raw := fmt.Sprintf("POST %s HTTP/1.1\r\nHost: ai\r\nContent-Length: %d\r\n\r\n%s", path, declared, body)
+		req, err := ParseRequest([]byte(raw), nil)
+		if err != nil {
+			t.Fatalf("ParseRequest: %v", err)
+		}
+		return req
+	}
+	big := strings.Repeat("A", 200)
+	for _, tc := range []struct {
+		name     string
+		req      *Request
+		ready    bool
+		tooLarge bool
+	}{
+		{"small body", request("/extract", 14, `{"text": "hi"}`), true, false},
+		{"body still arriving", request("/extract", 14, `{"text"`), false, false},
+		// Refused from the headers, before any of the body arrives
+		{"declared over the limit", request("/extract", 200, ""), false, true},
+		{"sent over the limit", request("/extract", 10, big), false, true},
+		{"path override", request("/extract-vision", 200, big), true, false},
+		{"over the path override", request("/extract-vision", 2000, big), false, true},
+	} {
+		ready, err := e.bodyReady(tc.req)
+		if ready != tc.ready || errors.Is(err, ErrBodyTooLarge) != tc.tooLarge {
+			t.Errorf("%s: ready = %v, err = %v; want ready %v, too large %v", tc.name, ready, err, tc.ready, tc.tooLarge)
+		}
+	}
+
+	// Without limits only the declared length matters
+	unlimited := New(":0", nil)
+	if ready, err := unlimited.bodyReady(request("/extract", 200, big)); !ready || err != nil {
+		t.Errorf("no limit: ready = %v, err = %v", ready, err)
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -369,11 +370,33 @@ func MetricsHandler(getMetrics func() string) HandlerFunc {
 	}
 }
 
-// ParseJSON parses the request body as JSON
+// DefaultMaxBodyBytes is the largest body ParseJSON accepts when no limit
+// was set for the request
+const DefaultMaxBodyBytes = 4 << 20
+
+// ErrBodyTooLarge is returned by ParseJSON for a body over the request's
+// limit; handlers should answer it with 413 (see ParseJSONStatus). The engine
+// refuses such bodies before reading them when Options sets a limit.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ParseJSON parses the request body as JSON, refusing bodies over the
+// request's MaxBodyBytes before decoding anything
 func ParseJSON(req *Request, v interface{}) error {
+	if limit := req.MaxBodyBytes(); int64(len(req.Body)) > limit {
+		return fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrBodyTooLarge, len(req.Body), limit)
+	}
 	return json.Unmarshal(req.Body, v)
 }
 
+// ParseJSONStatus is the status to answer a ParseJSON error with: 413 for a
+// body over the limit, 400 for anything else
+func ParseJSONStatus(err error) int {
+	if errors.Is(err, ErrBodyTooLarge) {
+		return 413
+	}
+	return 400
+}
+
 // WriteJSON writes JSON to the response
 func WriteJSON(resp *Response, v interface{}) error {
 	data, err := JSONMarshal(v)
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJSONEnforcesBodyLimit(t *testing.T) {
+	body := []byte(`{"image": "` + strings.Repeat("A", 200) + `"}`)
+	for _, tc := range []struct {
+		limit int64
+		body  []byte
+		want  int
+	}{
+		{64, []byte(`{"text": "hi"}`), 200},
+		{64, body, 413},
+		{1024, body, 200},
+		{64, []byte(`{"text":`), 400},
+	} {
+		req := &Request{Body: tc.body}
+		req.SetMaxBodyBytes(tc.limit)
+		status := 200
+		if err := ParseJSON(req, &map[string]string{}); err != nil {
+			status = ParseJSONStatus(err)
+		}
+		if status != tc.want {
+			t.Errorf("%d bytes with a %d byte limit: status %d, want %d", len(tc.body), tc.limit, status, tc.want)
+		}
+	}
+
+	// Without a limit set the default applies
+	big := &Request{Body: make([]byte, DefaultMaxBodyBytes+1)}
+	if err := ParseJSON(big, &map[string]string{}); ParseJSONStatus(err) != 413 {
+		t.Errorf("body over DefaultMaxBodyBytes: err = %v, want ErrBodyTooLarge", err)
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -44,6 +45,15 @@ type Request struct {
 	// Connection
 	conn gnet.Conn
 
+	// Request-scoped context (see Context)
+	ctx context.Context
+
+	// Largest body ParseJSON accepts (see MaxBodyBytes)
+	maxBodyBytes int64
+
+	// Headers middleware set ahead of a streamed response (see SetResponseHeader)
+	responseHeaders map[string]string
+
 	// Connection state
 	State *connState
 
@@ -57,6 +67,35 @@ type Request struct {
 	Timestamp time.Time
 }
 
+// Context returns the request context. It is cancelled if the server shuts
+// down before the handler finishes draining, when the client disconnects
+// (with Options.CancelOnDisconnect), or when a middleware deadline expires.
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// SetContext replaces the request context, e.g. to attach a deadline in middleware
+func (r *Request) SetContext(ctx context.Context) {
+	r.ctx = ctx
+}
+
+// MaxBodyBytes returns the largest body ParseJSON accepts for this request,
+// DefaultMaxBodyBytes unless the engine's body limits set another
+func (r *Request) MaxBodyBytes() int64 {
+	if r.maxBodyBytes <= 0 {
+		return DefaultMaxBodyBytes
+	}
+	return r.maxBodyBytes
+}
+
+// SetMaxBodyBytes sets the largest body ParseJSON accepts, e.g. per path in the engine
+func (r *Request) SetMaxBodyBytes(n int64) {
+	r.maxBodyBytes = n
+}
+
 // BasicAuth represents HTTP basic authentication credentials
 type BasicAuth struct {
 	Username string
@@ -232,6 +271,16 @@ func (r *Request) SetHeader(key, value string) {
 	r.Headers[strings.ToLower(key)] = value
 }
 
+// SetResponseHeader sets a header for the response before the handler runs.
+// A streamed response writes its headers before the handler returns, so
+// middleware that adds headers afterwards must also set them here to reach it.
+func (r *Request) SetResponseHeader(key, value string) {
+	if r.responseHeaders == nil {
+		r.responseHeaders = make(map[string]string)
+	}
+	r.responseHeaders[key] = value
+}
+
 // Cookie returns a cookie value
 func (r *Request) Cookie(name string) (*http.Cookie, bool) {
 	c, ok := r.Cookies[name]
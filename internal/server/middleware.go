@@ -3,6 +3,7 @@ package server
 import (
 	"compress/flate"
 	"compress/gzip"
+	"context"
 	"io"
 	"net"
 	"strconv"
@@ -70,13 +71,6 @@ func CORS(opts *CORSOptions) MiddlewareFunc {
 				return CORSResponse(opts, req)
 			}
 
-			// Add CORS headers to all responses
-			resp := next(req)
-
-			if resp.Headers == nil {
-				resp.Headers = make(map[string]string)
-			}
-
 			origin := req.Header("Origin")
 			allowedOrigin := "*"
 			if len(opts.AllowedOrigins) > 0 && opts.AllowedOrigins[0] != "*" {
@@ -90,12 +84,26 @@ func CORS(opts *CORSOptions) MiddlewareFunc {
 				allowedOrigin = origin
 			}
 
-			resp.Headers["Access-Control-Allow-Origin"] = allowedOrigin
+			headers := map[string]string{"Access-Control-Allow-Origin": allowedOrigin}
 			if opts.AllowCredentials {
-				resp.Headers["Access-Control-Allow-Credentials"] = "true"
+				headers["Access-Control-Allow-Credentials"] = "true"
 			}
 			if len(opts.ExposedHeaders) > 0 {
-				resp.Headers["Access-Control-Expose-Headers"] = strings.Join(opts.ExposedHeaders, ", ")
+				headers["Access-Control-Expose-Headers"] = strings.Join(opts.ExposedHeaders, ", ")
+			}
+			// Set ahead too, for a handler that streams its response
+			for k, v := range headers {
+				req.SetResponseHeader(k, v)
+			}
+
+			// Add CORS headers to all responses
+			resp := next(req)
+
+			if resp.Headers == nil {
+				resp.Headers = make(map[string]string)
+			}
+			for k, v := range headers {
+				resp.Headers[k] = v
 			}
 
 			return resp
@@ -144,6 +152,35 @@ func Timeout(timeout time.Duration) MiddlewareFunc {
 	}
 }
 
+// PathTimeout attaches a deadline to each request's context, using the
+// per-path override when present and defaultTimeout otherwise. Handlers must
+// pass req.Context() to upstream calls for the deadline to take effect.
+// A 5xx response produced after the deadline is reported as 504, unless it
+// was already streamed.
+func PathTimeout(defaultTimeout time.Duration, overrides map[string]time.Duration) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(req *Request) *Response {
+			timeout := defaultTimeout
+			if t, ok := overrides[req.Path]; ok {
+				timeout = t
+			}
+			if timeout <= 0 {
+				return next(req)
+			}
+
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+			req.SetContext(ctx)
+
+			resp := next(req)
+			if ctx.Err() == context.DeadlineExceeded && (resp == nil || resp.StatusCode >= 500 && !resp.headersWritten) {
+				return ErrorResponse(504, "Request timeout")
+			}
+			return resp
+		}
+	}
+}
+
 // RateLimiter is a simple rate limiter middleware
 type RateLimiter struct {
 	visitors map[string]*visitor
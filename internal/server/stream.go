@@ -0,0 +1,112 @@
+package server
+
+import (
+	"io"
+	"strconv"
+)
+
+// StreamWriter sends a response body in pieces as the handler produces it,
+// framed with chunked transfer encoding, rather than building it whole in a
+// Response. Use it only from the goroutine running the handler, and return
+// Close's response from the handler.
+type StreamWriter struct {
+	resp *Response
+	w    io.Writer // nil collects the body in resp
+	err  error
+}
+
+// Stream starts a streamed response, writing the status line and headers to
+// the connection at once. Middleware cannot change a streamed response's
+// status or headers after the handler returns; headers it set beforehand with
+// SetResponseHeader are included, under the handler's own. Without a
+// connection, as in tests, the body is collected in the response instead.
+func (r *Request) Stream(status int, headers map[string]string) *StreamWriter {
+	var w io.Writer
+	if r.conn != nil {
+		w = r.conn
+	}
+	if len(r.responseHeaders) > 0 {
+		merged := make(map[string]string, len(r.responseHeaders)+len(headers))
+		for k, v := range r.responseHeaders {
+			merged[k] = v
+		}
+		for k, v := range headers {
+			merged[k] = v
+		}
+		headers = merged
+	}
+	return newStreamWriter(w, status, headers)
+}
+
+// newStreamWriter starts a streamed response written to w
+func newStreamWriter(w io.Writer, status int, headers map[string]string) *StreamWriter {
+	resp := &Response{
+		StatusCode: status,
+		Headers:    make(map[string]string, len(headers)+1),
+		KeepAlive:  true,
+	}
+	for k, v := range headers {
+		resp.Headers[k] = v
+	}
+
+	sw := &StreamWriter{resp: resp, w: w}
+	if w == nil {
+		return sw
+	}
+	resp.Headers["Transfer-Encoding"] = "chunked"
+	_, sw.err = w.Write(resp.Build())
+	resp.headersWritten = true
+	if sw.err != nil {
+		resp.KeepAlive = false
+	}
+	return sw
+}
+
+// Write sends p as one chunk. After a failed write, every later write
+// returns the same error.
+func (sw *StreamWriter) Write(p []byte) (int, error) {
+	if sw.err != nil {
+		return 0, sw.err
+	}
+	if len(p) == 0 {
+		// An empty chunk would end the body
+		return 0, nil
+	}
+	if sw.w == nil {
+		return sw.resp.Write(p)
+	}
+
+	chunk := make([]byte, 0, len(p)+16)
+	chunk = strconv.AppendInt(chunk, int64(len(p)), 16)
+	chunk = append(chunk, "\r\n"...)
+	chunk = append(chunk, p...)
+	chunk = append(chunk, "\r\n"...)
+	if _, err := sw.w.Write(chunk); err != nil {
+		sw.err = err
+		sw.resp.KeepAlive = false
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString sends s as one chunk
+func (sw *StreamWriter) WriteString(s string) (int, error) {
+	return sw.Write([]byte(s))
+}
+
+// Err returns the error that stopped the stream, if any
+func (sw *StreamWriter) Err() error {
+	return sw.err
+}
+
+// Close ends the body and returns the response for the handler to return.
+// The engine writes nothing more for a response whose headers were streamed.
+func (sw *StreamWriter) Close() *Response {
+	if sw.w != nil && sw.err == nil {
+		if _, err := io.WriteString(sw.w, "0\r\n\r\n"); err != nil {
+			sw.err = err
+			sw.resp.KeepAlive = false
+		}
+	}
+	return sw.resp
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestStreamWriterChunksBody(t *testing.T) {
+	var conn bytes.Buffer
+	sw := newStreamWriter(&conn, 200, map[string]string{"Content-Type": "text/event-stream"})
+	if conn.Len() == 0 {
+		t.Fatal("headers not written before the body")
+	}
+	for _, piece := range []string{"data: one\n\n", "", "data: two\n\n"} {
+		if _, err := sw.WriteString(piece); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp := sw.Close()
+	if !resp.headersWritten || !resp.KeepAlive {
+		t.Errorf("response = %+v, want it marked as streamed", resp)
+	}
+
+	parsed, err := http.ReadResponse(bufio.NewReader(&conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(parsed.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.StatusCode != 200 || parsed.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("status %d, Content-Type %q", parsed.StatusCode, parsed.Header.Get("Content-Type"))
+	}
+	if string(body) != "data: one\n\ndata: two\n\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestStreamWithoutConnectionCollectsBody(t *testing.T) {
+	sw := (&Request{}).Stream(200, map[string]string{"Content-Type": "text/event-stream"})
+	sw.WriteString("data: one\n\n")
+	resp := sw.Close()
+	if resp.headersWritten || string(resp.Body) != "data: one\n\n" {
+		t.Errorf("response = %+v, want the body collected", resp)
+	}
+}
+
+func TestStreamKeepsMiddlewareHeaders(t *testing.T) {
+	// The headers a streamed response goes out with, before middleware
+	// sees the returned response
+	var written map[string]string
+	handler := CORS(&CORSOptions{AllowedOrigins: []string{"https://app.example"}})(func(req *Request) *Response {
+		req.SetResponseHeader("Content-Type", "text/plain")
+		resp := req.Stream(200, map[string]string{"Content-Type": "text/event-stream"}).Close()
+		written = make(map[string]string, len(resp.Headers))
+		for k, v := range resp.Headers {
+			written[k] = v
+		}
+		return resp
+	})
+	handler(&Request{Headers: map[string]string{"origin": "https://app.example"}})
+
+	if written["Access-Control-Allow-Origin"] != "https://app.example" {
+		t.Errorf("streamed headers %v lack the CORS headers", written)
+	}
+	if written["Content-Type"] != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want the handler's own", written["Content-Type"])
+	}
+}
@@ -0,0 +1,344 @@
+// Package fakeredis serves the subset of Redis the repo's tests need from
+// memory over RESP2: strings, hashes, sets, sorted sets and WATCH/MULTI/EXEC
+// transactions. It stands in for a real server in unit tests.
+package fakeredis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// server is the keyspace shared by every connection. revision counts writes
+// per key so EXEC can fail when a watched key changed, as in Redis.
+type server struct {
+	mu       sync.Mutex
+	strings  map[string]string
+	hashes   map[string]map[string]string
+	sets     map[string]map[string]bool
+	zsets    map[string]map[string]float64
+	revision map[string]int
+}
+
+// New starts a fake Redis server for the length of the test and returns a
+// client for it
+func New(t testing.TB) *redis.Client {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &server{
+		strings:  make(map[string]string),
+		hashes:   make(map[string]map[string]string),
+		sets:     make(map[string]map[string]bool),
+		zsets:    make(map[string]map[string]float64),
+		revision: make(map[string]int),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: ln.Addr().String(), DisableIndentity: true})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// serve answers one connection's commands, keeping its transaction state
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	watched := map[string]int{}
+	var queued [][]string // commands of an open MULTI
+	inMulti := false
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case inMulti && cmd != "EXEC" && cmd != "DISCARD":
+			queued = append(queued, args)
+			reply = "+QUEUED\r\n"
+		case cmd == "WATCH":
+			for _, key := range args[1:] {
+				watched[key] = s.revision[key]
+			}
+			reply = "+OK\r\n"
+		case cmd == "UNWATCH":
+			watched = map[string]int{}
+			reply = "+OK\r\n"
+		case cmd == "MULTI":
+			inMulti, queued = true, nil
+			reply = "+OK\r\n"
+		case cmd == "DISCARD":
+			inMulti, queued, watched = false, nil, map[string]int{}
+			reply = "+OK\r\n"
+		case cmd == "EXEC":
+			reply = s.exec(watched, queued)
+			inMulti, queued, watched = false, nil, map[string]int{}
+		default:
+			reply = s.apply(args)
+		}
+		s.mu.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// exec runs a transaction's queued commands, or none when a watched key was
+// written after the WATCH
+func (s *server) exec(watched map[string]int, queued [][]string) string {
+	for key, rev := range watched {
+		if s.revision[key] != rev {
+			return "*-1\r\n"
+		}
+	}
+	reply := fmt.Sprintf("*%d\r\n", len(queued))
+	for _, args := range queued {
+		reply += s.apply(args)
+	}
+	return reply
+}
+
+// apply runs one command against the keyspace and returns its RESP reply
+func (s *server) apply(args []string) string {
+	cmd := strings.ToUpper(args[0])
+	switch cmd {
+	case "SET", "DEL", "INCR", "HSET", "HDEL", "SADD", "SREM", "ZADD", "ZREM":
+		for _, key := range writtenKeys(cmd, args) {
+			s.revision[key]++
+		}
+	}
+
+	switch cmd {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		v, ok := s.strings[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(v)
+	case "SET":
+		// Expiry options are accepted and ignored
+		s.strings[args[1]] = args[2]
+		return "+OK\r\n"
+	case "DEL":
+		removed := 0
+		for _, key := range args[1:] {
+			_, str := s.strings[key]
+			_, hash := s.hashes[key]
+			_, set := s.sets[key]
+			_, zset := s.zsets[key]
+			if str || hash || set || zset {
+				removed++
+			}
+			delete(s.strings, key)
+			delete(s.hashes, key)
+			delete(s.sets, key)
+			delete(s.zsets, key)
+		}
+		return integer(removed)
+	case "INCR":
+		n, _ := strconv.Atoi(s.strings[args[1]])
+		n++
+		s.strings[args[1]] = strconv.Itoa(n)
+		return integer(n)
+	case "HSET":
+		h := s.hashes[args[1]]
+		if h == nil {
+			h = make(map[string]string)
+			s.hashes[args[1]] = h
+		}
+		added := 0
+		for i := 2; i+1 < len(args); i += 2 {
+			if _, ok := h[args[i]]; !ok {
+				added++
+			}
+			h[args[i]] = args[i+1]
+		}
+		return integer(added)
+	case "HGET":
+		v, ok := s.hashes[args[1]][args[2]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(v)
+	case "HDEL":
+		removed := 0
+		for _, field := range args[2:] {
+			if _, ok := s.hashes[args[1]][field]; ok {
+				delete(s.hashes[args[1]], field)
+				removed++
+			}
+		}
+		return integer(removed)
+	case "HLEN":
+		return integer(len(s.hashes[args[1]]))
+	case "SADD", "SREM":
+		set := s.sets[args[1]]
+		if set == nil {
+			set = make(map[string]bool)
+			s.sets[args[1]] = set
+		}
+		add := cmd == "SADD"
+		changed := 0
+		for _, member := range args[2:] {
+			if set[member] != add {
+				changed++
+			}
+			if add {
+				set[member] = true
+			} else {
+				delete(set, member)
+			}
+		}
+		return integer(changed)
+	case "SMEMBERS":
+		members := make([]string, 0, len(s.sets[args[1]]))
+		for member := range s.sets[args[1]] {
+			members = append(members, member)
+		}
+		sort.Strings(members)
+		return array(members)
+	case "ZADD":
+		z := s.zsets[args[1]]
+		if z == nil {
+			z = make(map[string]float64)
+			s.zsets[args[1]] = z
+		}
+		added := 0
+		for i := 2; i+1 < len(args); i += 2 {
+			score, _ := strconv.ParseFloat(args[i], 64)
+			if _, ok := z[args[i+1]]; !ok {
+				added++
+			}
+			z[args[i+1]] = score
+		}
+		return integer(added)
+	case "ZREM":
+		removed := 0
+		for _, member := range args[2:] {
+			if _, ok := s.zsets[args[1]][member]; ok {
+				delete(s.zsets[args[1]], member)
+				removed++
+			}
+		}
+		return integer(removed)
+	case "ZCARD":
+		return integer(len(s.zsets[args[1]]))
+	case "ZSCORE":
+		score, ok := s.zsets[args[1]][args[2]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(strconv.FormatFloat(score, 'f', -1, 64))
+	case "ZRANGE":
+		// Only the whole set, as ZRANGE key 0 -1
+		return array(zrange(s.zsets[args[1]], math.Inf(-1), math.Inf(1)))
+	case "ZRANGEBYSCORE":
+		members := zrange(s.zsets[args[1]], parseScore(args[2]), parseScore(args[3]))
+		if len(args) == 7 && strings.EqualFold(args[4], "LIMIT") {
+			if count, _ := strconv.Atoi(args[6]); count >= 0 && count < len(members) {
+				members = members[:count]
+			}
+		}
+		return array(members)
+	default:
+		// Including HELLO, so the client falls back to a plain RESP2 handshake
+		return "-ERR unknown command\r\n"
+	}
+}
+
+// writtenKeys returns the keys a write command changes
+func writtenKeys(cmd string, args []string) []string {
+	if cmd == "DEL" {
+		return args[1:]
+	}
+	return args[1:2]
+}
+
+// zrange returns the members of z scored within [min, max], lowest first
+func zrange(z map[string]float64, min, max float64) []string {
+	var members []string
+	for m, score := range z {
+		if score >= min && score <= max {
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if z[members[i]] != z[members[j]] {
+			return z[members[i]] < z[members[j]]
+		}
+		return members[i] < members[j]
+	})
+	return members
+}
+
+// parseScore parses a ZRANGEBYSCORE bound, including -inf and +inf
+func parseScore(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimPrefix(s, "+"), 64)
+	return f
+}
+
+func bulk(v string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v) }
+
+func integer(n int) string { return fmt.Sprintf(":%d\r\n", n) }
+
+func array(vs []string) string {
+	reply := fmt.Sprintf("*%d\r\n", len(vs))
+	for _, v := range vs {
+		reply += bulk(v)
+	}
+	return reply
+}
+
+// readCommand reads one array-of-bulk-strings command
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
@@ -0,0 +1,72 @@
+// Package textnorm normalizes text for keyword search: it lowercases, splits
+// on anything that isn't a letter or digit, drops stop words and reduces each
+// word to its Porter stem. Node names and descriptions are indexed through the
+// same normalization queries go through, so "running shoes" finds a node
+// described as "runs in new shoe".
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/blevesearch/go-porterstemmer"
+)
+
+// DefaultStopWords are dropped from every query and every indexed text
+var DefaultStopWords = []string{
+	"what", "is", "my", "the", "a", "an",
+	"of", "for", "in", "on", "at", "to",
+	"do", "does", "did", "can", "could",
+	"who", "where", "when", "why", "how",
+	"tell", "me", "about", "know",
+}
+
+// Default normalizes with DefaultStopWords only; it is what indexing uses
+var Default = New()
+
+// Normalizer turns text into normalized search terms
+type Normalizer struct {
+	stopWords map[string]bool
+}
+
+// New returns a Normalizer dropping DefaultStopWords plus extraStopWords.
+// Extra stop words only ever widen the default list: a word indexing keeps
+// may be dropped from queries, but never the other way round, so changing
+// them never calls for a reindex.
+func New(extraStopWords ...string) *Normalizer {
+	n := &Normalizer{stopWords: make(map[string]bool, len(DefaultStopWords)+len(extraStopWords))}
+	for _, words := range [][]string{DefaultStopWords, extraStopWords} {
+		for _, w := range words {
+			if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+				n.stopWords[w] = true
+			}
+		}
+	}
+	return n
+}
+
+// Terms returns the distinct stemmed keywords of text, in order
+func (n *Normalizer) Terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(words))
+	var terms []string
+	for _, w := range words {
+		if len(w) <= 1 || n.stopWords[w] {
+			continue
+		}
+		term := porterstemmer.StemString(w)
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Normalize returns the terms of text joined by spaces
+func (n *Normalizer) Normalize(text string) string {
+	return strings.Join(n.Terms(text), " ")
+}
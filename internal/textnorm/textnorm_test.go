@@ -0,0 +1,31 @@
+package textnorm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTermsStemAndDropStopWords(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want []string
+	}{
+		{"What is my favourite running route?", []string{"favourit", "run", "rout"}},
+		{"Cats, cat or CATS!", []string{"cat", "or"}},
+		{"a b c", nil},
+	} {
+		if got := Default.Terms(tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestExtraStopWordsOnlyWidenTheDefaults(t *testing.T) {
+	n := New("Route", " ")
+	if got := n.Normalize("what is my running route"); got != "run" {
+		t.Errorf("Normalize = %q, want %q", got, "run")
+	}
+	if got := Default.Normalize("running route"); got != "run rout" {
+		t.Errorf("Default dropped an extra stop word: %q", got)
+	}
+}
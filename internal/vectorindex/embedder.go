@@ -0,0 +1,101 @@
+package vectorindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// DefaultEmbedBatchSize is how many chunk texts are sent to the embedder per call
+const DefaultEmbedBatchSize = 64
+
+// ErrNoEmbedder is returned when chunks need embedding but the builder has no embedder
+var ErrNoEmbedder = errors.New("vector index has no embedder")
+
+// Embedder turns texts into vectors, one per text and in the same order
+type Embedder interface {
+	EmbedBatch(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float64, error)
+
+// EmbedBatch calls f
+func (f EmbedderFunc) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	return f(ctx, texts)
+}
+
+// WithEmbedder sets the embedder used for chunks that arrive without an
+// embedding and returns the builder
+func (b *IndexBuilder) WithEmbedder(embedder Embedder) *IndexBuilder {
+	b.Embedder = embedder
+	return b
+}
+
+// EmbedChunks fills in the embedding of every chunk that lacks one, sending
+// their texts to the embedder BatchSize at a time rather than one request per
+// chunk. Chunks that already carry an embedding are left alone. Without a
+// fixed Dim, every vector must match the first one seen.
+func (b *IndexBuilder) EmbedChunks(ctx context.Context, chunks []Chunk) error {
+	dim := b.Dim
+	var pending []int
+	for i := range chunks {
+		if len(chunks[i].Embedding) == 0 {
+			pending = append(pending, i)
+		} else if dim == 0 {
+			dim = len(chunks[i].Embedding)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if b.Embedder == nil {
+		return ErrNoEmbedder
+	}
+
+	batchSize := b.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultEmbedBatchSize
+	}
+
+	for start := 0; start < len(pending); start += batchSize {
+		end := min(start+batchSize, len(pending))
+		texts := make([]string, 0, end-start)
+		for _, idx := range pending[start:end] {
+			texts = append(texts, chunks[idx].Text)
+		}
+
+		vectors, err := b.Embedder.EmbedBatch(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunks %d-%d: %w", start, end-1, err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("embedder returned %d vectors for %d chunks", len(vectors), len(texts))
+		}
+		for i, vector := range vectors {
+			if dim == 0 {
+				dim = len(vector)
+			}
+			if len(vector) != dim {
+				return fmt.Errorf("embedder returned %d dimensions, index expects %d", len(vector), dim)
+			}
+			chunks[pending[start+i]].Embedding = vector
+		}
+	}
+
+	b.Logger.Debug("Embedded chunks",
+		zap.Int("chunks", len(pending)),
+		zap.Int("batch_size", batchSize))
+	return nil
+}
+
+// BuildIndexWithEmbeddings embeds the chunks that lack an embedding in
+// batches, then builds the tree from them
+func (b *IndexBuilder) BuildIndexWithEmbeddings(ctx context.Context, chunks []Chunk) (map[string]*VectorNode, error) {
+	if err := b.EmbedChunks(ctx, chunks); err != nil {
+		return nil, err
+	}
+	return b.BuildIndex(chunks), nil
+}
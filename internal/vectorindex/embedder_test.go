@@ -0,0 +1,137 @@
+package vectorindex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// countingEmbedder returns a dim-sized vector per text and records each call's size
+type countingEmbedder struct {
+	dim   int
+	calls []int
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	e.calls = append(e.calls, len(texts))
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = make([]float64, e.dim)
+		vectors[i][(len(e.calls)+i)%e.dim] = 1
+	}
+	return vectors, nil
+}
+
+func TestBuildIndexWithEmbeddingsBatchesMissingEmbeddings(t *testing.T) {
+	chunks := make([]Chunk, 100)
+	for i := range chunks {
+		chunks[i].Text = fmt.Sprintf("chunk %d", i)
+	}
+	chunks[7].Embedding = []float64{0, 0, 0, 1, 0, 0, 0, 0}
+
+	embedder := &countingEmbedder{dim: 8}
+	b := NewIndexBuilder(10, 8, nil).WithEmbedder(embedder)
+	b.BatchSize = 40
+
+	tree, err := b.BuildIndexWithEmbeddings(context.Background(), chunks)
+	if err != nil {
+		t.Fatalf("BuildIndexWithEmbeddings: %v", err)
+	}
+	if fmt.Sprint(embedder.calls) != "[40 40 19]" {
+		t.Errorf("embedder calls = %v, want [40 40 19]", embedder.calls)
+	}
+	if chunks[7].Embedding[3] != 1 {
+		t.Errorf("existing embedding was replaced: %v", chunks[7].Embedding)
+	}
+	if stats := b.GetTreeStats(tree); stats["leaf_count"] != 100 {
+		t.Errorf("tree leaf_count = %v, want 100", stats["leaf_count"])
+	}
+
+	// Vectors the index can't use are rejected
+	chunks[0].Embedding = nil
+	if err := NewIndexBuilder(10, 16, nil).WithEmbedder(embedder).EmbedChunks(context.Background(), chunks); err == nil {
+		t.Error("EmbedChunks accepted 8-dimensional vectors for a 16-dimensional index")
+	}
+	if err := NewIndexBuilder(10, 8, nil).EmbedChunks(context.Background(), chunks); err != ErrNoEmbedder {
+		t.Errorf("EmbedChunks without an embedder = %v, want ErrNoEmbedder", err)
+	}
+}
+
+// TestBuildIndexTakesEmbedderDimension builds a tree without a fixed
+// dimension, as the ingester does, from whatever size the embedder returns
+func TestBuildIndexTakesEmbedderDimension(t *testing.T) {
+	chunks := make([]Chunk, 30)
+	for i := range chunks {
+		chunks[i].Text = fmt.Sprintf("chunk %d", i)
+	}
+	b := NewIndexBuilder(10, 0, nil).WithEmbedder(&countingEmbedder{dim: 768})
+
+	tree, err := b.BuildIndexWithEmbeddings(context.Background(), chunks)
+	if err != nil {
+		t.Fatalf("BuildIndexWithEmbeddings: %v", err)
+	}
+	for id, node := range tree {
+		if len(node.Vector) != 768 {
+			t.Fatalf("node %s has %d dimensions, want 768", id, len(node.Vector))
+		}
+	}
+	if b.Dim != 0 {
+		t.Errorf("building a tree fixed the builder's dimension at %d", b.Dim)
+	}
+
+	// A batch disagreeing with the embeddings already present is rejected
+	chunks[0].Embedding = nil
+	chunks[1].Embedding = make([]float64, 16)
+	if err := b.EmbedChunks(context.Background(), chunks); err == nil {
+		t.Error("EmbedChunks mixed 768- and 16-dimensional vectors")
+	}
+}
+
+// slowEmbedder answers after a fixed round trip, however many texts it is sent
+type slowEmbedder struct {
+	dim       int
+	roundTrip time.Duration
+	calls     int
+}
+
+func (e *slowEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	e.calls++
+	time.Sleep(e.roundTrip)
+	vectors := make([][]float64, len(texts))
+	for i := range texts {
+		vectors[i] = make([]float64, e.dim)
+		vectors[i][(e.calls*31+i)%e.dim] = 1
+	}
+	return vectors, nil
+}
+
+// BenchmarkBuildIndexWithEmbeddings builds the tree for a 100-chunk document,
+// embedding one request per chunk and then in batches, with a 2ms round trip
+// per embedding request
+func BenchmarkBuildIndexWithEmbeddings(b *testing.B) {
+	for _, bc := range []struct {
+		name      string
+		batchSize int
+	}{
+		{"per_chunk", 1},
+		{"batched", DefaultEmbedBatchSize},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			embedder := &slowEmbedder{dim: 256, roundTrip: 2 * time.Millisecond}
+			builder := NewIndexBuilder(10, 256, nil).WithEmbedder(embedder)
+			builder.BatchSize = bc.batchSize
+
+			for i := 0; i < b.N; i++ {
+				chunks := make([]Chunk, 100)
+				for j := range chunks {
+					chunks[j].Text = fmt.Sprintf("chunk %d", j)
+				}
+				if _, err := builder.BuildIndexWithEmbeddings(context.Background(), chunks); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(embedder.calls)/float64(b.N), "embed_calls/op")
+		})
+	}
+}
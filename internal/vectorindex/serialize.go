@@ -0,0 +1,107 @@
+package vectorindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// TreeFormat names the saved vector tree format
+const TreeFormat = "rmk-vector-tree"
+
+// TreeFormatVersion is the saved tree version SerializeTree writes. LoadTree
+// reads this version and any earlier one.
+const TreeFormatVersion = 1
+
+// SavedTree is the portable form of a vector tree. Nodes are ordered leaves
+// first, then by node ID, so saving the same tree twice gives the same bytes.
+type SavedTree struct {
+	Format  string        `json:"format"`
+	Version int           `json:"version"`
+	Dim     int           `json:"dim"`
+	RootIDs []string      `json:"root_ids"`
+	Nodes   []*VectorNode `json:"nodes"`
+}
+
+// SerializeTree encodes a tree built by BuildIndex in the versioned saved
+// tree format
+func SerializeTree(tree map[string]*VectorNode) ([]byte, error) {
+	saved := SavedTree{
+		Format:  TreeFormat,
+		Version: TreeFormatVersion,
+		RootIDs: []string{},
+		Nodes:   make([]*VectorNode, 0, len(tree)),
+	}
+
+	maxDepth := 0
+	for id, node := range tree {
+		if node == nil || node.NodeID != id {
+			return nil, fmt.Errorf("tree entry %q does not hold its node", id)
+		}
+		if saved.Dim == 0 {
+			saved.Dim = len(node.Vector)
+		} else if len(node.Vector) != saved.Dim {
+			return nil, fmt.Errorf("node %s has %d dimensions, tree has %d", id, len(node.Vector), saved.Dim)
+		}
+		if node.Depth > maxDepth {
+			maxDepth = node.Depth
+		}
+		saved.Nodes = append(saved.Nodes, node)
+	}
+	sort.Slice(saved.Nodes, func(i, j int) bool {
+		if saved.Nodes[i].Depth != saved.Nodes[j].Depth {
+			return saved.Nodes[i].Depth < saved.Nodes[j].Depth
+		}
+		return saved.Nodes[i].NodeID < saved.Nodes[j].NodeID
+	})
+	for _, node := range saved.Nodes {
+		if node.Depth == maxDepth {
+			saved.RootIDs = append(saved.RootIDs, node.NodeID)
+		}
+	}
+
+	return json.Marshal(saved)
+}
+
+// LoadTree decodes a tree written by SerializeTree, checking that its
+// vectors share one dimension and that every child it names is present
+func LoadTree(data []byte) (map[string]*VectorNode, error) {
+	var saved SavedTree
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal vector tree: %w", err)
+	}
+	if saved.Format != TreeFormat {
+		return nil, fmt.Errorf("not a saved vector tree (format %q)", saved.Format)
+	}
+	if saved.Version < 1 || saved.Version > TreeFormatVersion {
+		return nil, fmt.Errorf("unsupported vector tree version %d (supported up to %d)", saved.Version, TreeFormatVersion)
+	}
+
+	tree := make(map[string]*VectorNode, len(saved.Nodes))
+	for _, node := range saved.Nodes {
+		if node == nil || node.NodeID == "" {
+			return nil, fmt.Errorf("vector tree has a node without an id")
+		}
+		if len(node.Vector) != saved.Dim {
+			return nil, fmt.Errorf("node %s has %d dimensions, tree has %d", node.NodeID, len(node.Vector), saved.Dim)
+		}
+		if _, dup := tree[node.NodeID]; dup {
+			return nil, fmt.Errorf("vector tree repeats node %s", node.NodeID)
+		}
+		tree[node.NodeID] = node
+	}
+	for _, node := range tree {
+		for _, childID := range node.ChildrenIDs {
+			if _, ok := tree[childID]; !ok {
+				return nil, fmt.Errorf("node %s names missing child %s", node.NodeID, childID)
+			}
+		}
+	}
+	for _, rootID := range saved.RootIDs {
+		if _, ok := tree[rootID]; !ok {
+			return nil, fmt.Errorf("vector tree names missing root %s", rootID)
+		}
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,56 @@
+package vectorindex
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSerializeTreeRoundTrip(t *testing.T) {
+	chunks := make([]Chunk, 35)
+	for i := range chunks {
+		chunks[i].Text = strings.Repeat("x", i+1)
+		chunks[i].Embedding = make([]float64, 16)
+		chunks[i].Embedding[i%16] = 1
+		chunks[i].Embedding[(i*7)%16] += 0.1234567890123 * float64(i/16+1)
+	}
+	b := NewIndexBuilder(4, 16, nil)
+	tree := b.BuildIndex(chunks)
+	if !reflect.DeepEqual(b.BuildIndex(chunks), tree) {
+		t.Fatal("building the same chunks twice gave different trees")
+	}
+
+	data, err := SerializeTree(tree)
+	if err != nil {
+		t.Fatalf("SerializeTree: %v", err)
+	}
+	loaded, err := LoadTree(data)
+	if err != nil {
+		t.Fatalf("LoadTree: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, tree) {
+		t.Fatal("loaded tree differs from the one saved")
+	}
+	if again, _ := SerializeTree(loaded); !bytes.Equal(again, data) {
+		t.Error("saving the loaded tree gave different bytes")
+	}
+
+	query := chunks[14].Embedding
+	want, got := b.Search(tree, query, 3), b.Search(loaded, query, 3)
+	if len(got) != 3 || got[0].Text != chunks[14].Text || !reflect.DeepEqual(got, want) {
+		t.Errorf("search of loaded tree = %v, want %v", got, want)
+	}
+
+	for name, bad := range map[string]string{
+		"newer version": `{"format": "rmk-vector-tree", "version": 2, "dim": 0, "nodes": []}`,
+		"other format":  `{"format": "something-else", "version": 1}`,
+		"missing child": `{"format": "rmk-vector-tree", "version": 1, "dim": 1, "nodes": [{"node_id": "a", "vector": [1], "children_ids": ["b"]}]}`,
+		"mixed dims":    `{"format": "rmk-vector-tree", "version": 1, "dim": 2, "nodes": [{"node_id": "a", "vector": [1]}]}`,
+		"not json":      `vector tree`,
+	} {
+		if _, err := LoadTree([]byte(bad)); err == nil {
+			t.Errorf("LoadTree accepted %s", name)
+		}
+	}
+}
@@ -2,8 +2,10 @@
 package vectorindex
 
 import (
+	"fmt"
 	"math"
 	"sort"
+	"strings"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -46,8 +48,13 @@ type Chunk struct {
 // IndexBuilder builds a Hierarchical Vector Tree from flat chunks
 type IndexBuilder struct {
 	BranchingFactor int
-	Dim             int
+	Dim             int // 0 builds each tree at the dimension of its chunk embeddings
 	Logger          *zap.Logger
+
+	// Embedder embeds chunks that arrive without an embedding (optional)
+	Embedder Embedder
+	// BatchSize is how many texts go to Embedder per call (DefaultEmbedBatchSize if <= 0)
+	BatchSize int
 }
 
 // NewIndexBuilder creates a new vector index builder
@@ -63,17 +70,23 @@ func NewIndexBuilder(branchingFactor, dim int, logger *zap.Logger) *IndexBuilder
 	}
 }
 
-// BuildIndex creates a hierarchical vector tree from chunks
+// BuildIndex creates a hierarchical vector tree from chunks. Node IDs are
+// derived from the chunks, so the same chunks always build the same tree.
 func (b *IndexBuilder) BuildIndex(chunks []Chunk) map[string]*VectorNode {
 	if len(chunks) == 0 {
 		return make(map[string]*VectorNode)
 	}
+	if b.Dim == 0 && len(chunks[0].Embedding) > 0 {
+		sized := *b
+		sized.Dim = len(chunks[0].Embedding)
+		return sized.BuildIndex(chunks)
+	}
 
 	// 1. Create Leaf Nodes
 	leaves := make([]*VectorNode, 0, len(chunks))
-	for _, chunk := range chunks {
+	for i, chunk := range chunks {
 		node := &VectorNode{
-			NodeID: uuid.New().String(),
+			NodeID: nodeID(fmt.Sprintf("leaf:%d:%s", i, chunk.Text)),
 			Vector: chunk.Embedding,
 			Text:   chunk.Text,
 			Depth:  0,
@@ -111,14 +124,13 @@ func (b *IndexBuilder) BuildIndex(chunks []Chunk) map[string]*VectorNode {
 func (b *IndexBuilder) processLayer(nodes []*VectorNode) []*VectorNode {
 	numNodes := len(nodes)
 
-	// Determine number of clusters
-	numClusters := numNodes / b.BranchingFactor
-	if numClusters <= 0 {
-		numClusters = 1
-	}
-	if numNodes%b.BranchingFactor != 0 {
-		numClusters++
+	// Determine number of clusters: fewer than the nodes, so every layer
+	// shrinks and the build reaches a single root
+	branching := b.BranchingFactor
+	if branching < 2 {
+		branching = 2
 	}
+	numClusters := (numNodes + branching - 1) / branching
 
 	// Perform K-means clustering
 	clusterAssignments := b.kMeansCluster(nodes, numClusters)
@@ -130,9 +142,17 @@ func (b *IndexBuilder) processLayer(nodes []*VectorNode) []*VectorNode {
 		clusterGroups[clusterID] = append(clusterGroups[clusterID], node)
 	}
 
-	// Create parent nodes for each cluster
+	// Create parent nodes for each cluster, in cluster order so the layer
+	// comes out the same on every build
+	clusterIDs := make([]int, 0, len(clusterGroups))
+	for clusterID := range clusterGroups {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+	sort.Ints(clusterIDs)
+
 	parents := make([]*VectorNode, 0, len(clusterGroups))
-	for clusterID, group := range clusterGroups {
+	for _, clusterID := range clusterIDs {
+		group := clusterGroups[clusterID]
 		if len(group) == 0 {
 			continue
 		}
@@ -156,7 +176,7 @@ func (b *IndexBuilder) processLayer(nodes []*VectorNode) []*VectorNode {
 		}
 
 		parent := &VectorNode{
-			NodeID:      uuid.New().String(),
+			NodeID:      nodeID(fmt.Sprintf("node:%d:%s", group[0].Depth+1, strings.Join(childrenIDs, ","))),
 			Vector:      parentVector,
 			ChildrenIDs: childrenIDs,
 			Depth:       group[0].Depth + 1,
@@ -170,6 +190,12 @@ func (b *IndexBuilder) processLayer(nodes []*VectorNode) []*VectorNode {
 	return parents
 }
 
+// nodeID derives a node's ID from what identifies it, so building the same
+// chunks again gives the same IDs
+func nodeID(key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(key)).String()
+}
+
 // kMeansCluster performs K-means clustering on nodes
 func (b *IndexBuilder) kMeansCluster(nodes []*VectorNode, k int) []int {
 	n := len(nodes)
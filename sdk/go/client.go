@@ -2,12 +2,17 @@
 package rmk
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -66,6 +71,16 @@ func (c *Client) Login(ctx context.Context, username, password string) (*AuthRes
 	return &resp, nil
 }
 
+// Me returns the signed-in user's profile, workspaces, pending invitations
+// and quota in one call
+func (c *Client) Me(ctx context.Context) (*Me, error) {
+	var resp Me
+	if err := c.get(ctx, "/api/me", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // Logout clears the authentication token
 func (c *Client) Logout(ctx context.Context) error {
 	err := c.post(ctx, "/api/logout", nil, nil)
@@ -164,6 +179,25 @@ func (c *Client) RelationshipCreate(ctx context.Context, req *RelationshipCreate
 	return &resp, nil
 }
 
+// RelationshipTypes lists the relationship types RelationshipCreate accepts
+func (c *Client) RelationshipTypes(ctx context.Context) (*RelationshipTypesResponse, error) {
+	var resp RelationshipTypesResponse
+	if err := c.toolCall(ctx, "relationship_types", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SchemaTypes lists the node, entity and relationship types the server
+// accepts, for validating requests before sending them
+func (c *Client) SchemaTypes(ctx context.Context) (*SchemaTypesResponse, error) {
+	var resp SchemaTypesResponse
+	if err := c.get(ctx, "/api/schema/types", &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // DocumentIngest ingests a document
 func (c *Client) DocumentIngest(ctx context.Context, req *DocumentIngestRequest) (*DocumentIngestResponse, error) {
 	var resp DocumentIngestResponse
@@ -286,6 +320,153 @@ func (c *Client) ToolsList(ctx context.Context) (*ToolsListResponse, error) {
 	return &resp, nil
 }
 
+// ListFacts returns an iterator over a namespace's facts, oldest first,
+// fetched from the fact feed a page at a time:
+//
+//	it := client.ListFacts(ctx, &rmk.ListFactsRequest{Namespace: ns})
+//	defer it.Close()
+//	for it.Next() {
+//		fact := it.Fact()
+//	}
+//	if err := it.Err(); err != nil {
+//		// it.Cursor() resumes where the feed stopped
+//	}
+func (c *Client) ListFacts(ctx context.Context, req *ListFactsRequest) *FactIterator {
+	return &FactIterator{c: c, ctx: ctx, req: *req, cursor: req.Cursor}
+}
+
+// maxFactLine bounds one fact feed record
+const maxFactLine = 4 << 20
+
+// FactIterator walks a namespace's fact feed; see Client.ListFacts
+type FactIterator struct {
+	c      *Client
+	ctx    context.Context
+	req    ListFactsRequest
+	body   io.ReadCloser
+	lines  *bufio.Scanner
+	fact   Fact
+	cursor string
+	done   bool
+	err    error
+}
+
+// Next advances to the next fact, fetching the next page when the current
+// one runs out. It returns false at the end of the feed or on error.
+func (it *FactIterator) Next() bool {
+	for !it.done && it.err == nil {
+		if it.lines == nil {
+			if it.err = it.fetch(); it.err != nil {
+				return false
+			}
+		}
+		if !it.lines.Scan() {
+			it.err = it.lines.Err()
+			if it.err == nil {
+				it.err = io.ErrUnexpectedEOF
+			}
+			it.closeBody()
+			return false
+		}
+
+		var line struct {
+			Fact
+			End        bool   `json:"end"`
+			NextCursor string `json:"next_cursor"`
+			Error      string `json:"error"`
+		}
+		if err := json.Unmarshal(it.lines.Bytes(), &line); err != nil {
+			it.err = fmt.Errorf("failed to parse fact: %w", err)
+			it.closeBody()
+			return false
+		}
+		if !line.End {
+			it.fact = line.Fact
+			it.cursor = line.Cursor
+			return true
+		}
+
+		// End of a page: follow its cursor, which may skip facts we weren't shown
+		it.closeBody()
+		if line.NextCursor != "" {
+			it.cursor = line.NextCursor
+		}
+		if line.Error != "" {
+			it.err = errors.New("fact feed failed: " + line.Error)
+		} else if line.NextCursor == "" {
+			it.done = true
+		}
+	}
+	return false
+}
+
+// Fact returns the fact Next advanced to
+func (it *FactIterator) Fact() Fact {
+	return it.fact
+}
+
+// Cursor is where the feed resumes after the facts read so far; pass it as
+// ListFactsRequest.Cursor to carry on after an error
+func (it *FactIterator) Cursor() string {
+	return it.cursor
+}
+
+// Err returns the error that stopped Next, if any
+func (it *FactIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration, releasing the page being read
+func (it *FactIterator) Close() error {
+	it.done = true
+	it.closeBody()
+	return nil
+}
+
+// fetch requests the page after the cursor
+func (it *FactIterator) fetch() error {
+	query := url.Values{}
+	if it.cursor != "" {
+		query.Set("cursor", it.cursor)
+	}
+	if it.req.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(it.req.PageSize))
+	}
+	path := "/api/namespaces/" + url.PathEscape(it.req.Namespace) + "/facts"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(it.ctx, "GET", it.c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if it.c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+it.c.token)
+	}
+
+	httpResp, err := it.c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		defer httpResp.Body.Close()
+		return parseAPIError(httpResp)
+	}
+
+	it.body = httpResp.Body
+	it.lines = bufio.NewScanner(httpResp.Body)
+	it.lines.Buffer(make([]byte, 64<<10), maxFactLine)
+	return nil
+}
+
+func (it *FactIterator) closeBody() {
+	if it.body != nil {
+		it.body.Close()
+	}
+	it.body, it.lines = nil, nil
+}
+
 // toolCall calls an MCP tool
 func (c *Client) toolCall(ctx context.Context, name string, args, resp interface{}) error {
 	var arguments map[string]interface{}
@@ -351,8 +532,7 @@ func (c *Client) post(ctx context.Context, path string, body, resp interface{})
 	defer httpResp.Body.Close()
 
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
-		data, _ := io.ReadAll(httpResp.Body)
-		return fmt.Errorf("HTTP %d: %s", httpResp.StatusCode, string(data))
+		return parseAPIError(httpResp)
 	}
 
 	if resp != nil {
@@ -380,9 +560,18 @@ func (c *Client) get(ctx context.Context, path string, resp interface{}) error {
 	defer httpResp.Body.Close()
 
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
-		data, _ := io.ReadAll(httpResp.Body)
-		return fmt.Errorf("HTTP %d: %s", httpResp.StatusCode, string(data))
+		return parseAPIError(httpResp)
 	}
 
 	return json.NewDecoder(httpResp.Body).Decode(resp)
 }
+
+// parseAPIError decodes the server's JSON error envelope, falling back to the raw body
+func parseAPIError(httpResp *http.Response) error {
+	data, _ := io.ReadAll(httpResp.Body)
+	apiErr := &APIError{StatusCode: httpResp.StatusCode}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
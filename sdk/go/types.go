@@ -1,6 +1,11 @@
 // Package rmk provides types for the RMK Go SDK
 package rmk
 
+import (
+	"fmt"
+	"time"
+)
+
 // NodeType is the type of a memory node
 type NodeType string
 
@@ -10,6 +15,8 @@ const (
 	NodeTypeEvent    NodeType = "Event"
 	NodeTypeInsight  NodeType = "Insight"
 	NodeTypePattern  NodeType = "Pattern"
+	NodeTypePreference NodeType = "Preference"
+	NodeTypeRule       NodeType = "Rule"
 )
 
 // RelationshipType is the type of relationship between entities
@@ -66,6 +73,63 @@ type AuthResponse struct {
 	Groups   []string `json:"groups,omitempty"`
 }
 
+// ========== USER TYPES ==========
+
+// Me is the signed-in user's context from GET /api/me
+type Me struct {
+	Profile     MeProfile      `json:"profile"`
+	Workspaces  []MeWorkspace  `json:"workspaces"`
+	Invitations []MeInvitation `json:"invitations"`
+	Quota       *MeQuota       `json:"quota,omitempty"`       // Nil when rate limiting is off
+	Unavailable []string       `json:"unavailable,omitempty"` // Parts that failed to load
+}
+
+// MeProfile is the account behind the token and its profile settings
+type MeProfile struct {
+	Username    string                 `json:"username"`
+	Role        string                 `json:"role"`
+	Namespace   string                 `json:"namespace"`
+	CreatedAt   string                 `json:"created_at,omitempty"`
+	DisplayName string                 `json:"display_name,omitempty"`
+	Bio         string                 `json:"bio,omitempty"`
+	Preferences map[string]interface{} `json:"preferences,omitempty"`
+}
+
+// MeWorkspace is a workspace the user belongs to
+type MeWorkspace struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Role        string `json:"role"` // "admin" or "member"
+	MemberCount int    `json:"member_count"`
+}
+
+// MeInvitation is a pending invitation to a workspace
+type MeInvitation struct {
+	UID           string    `json:"uid"`
+	WorkspaceID   string    `json:"workspace_id"`
+	WorkspaceName string    `json:"workspace_name,omitempty"`
+	InviteeUserID string    `json:"invitee_user_id"`
+	Role          string    `json:"role"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedBy     string    `json:"created_by"`
+}
+
+// MeQuota is the user's request allowance per rate limit window
+type MeQuota struct {
+	Tier    string                   `json:"tier"`
+	Windows map[string]MeQuotaWindow `json:"windows"` // "minute", "hour", "day"
+}
+
+// MeQuotaWindow is the usage of one rate limit window
+type MeQuotaWindow struct {
+	Limit     int       `json:"limit"`
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
 // ========== MEMORY TYPES ==========
 
 // MemoryStoreRequest is a memory store request
@@ -130,6 +194,36 @@ type MemoryListResponse struct {
 	Limit   int           `json:"limit"`
 }
 
+// ListFactsRequest selects a namespace's fact feed
+type ListFactsRequest struct {
+	Namespace string
+	Cursor    string // Resume after this fact's Cursor ("" = from the start)
+	PageSize  int    // Facts read per request (0 = server default, at most 10000)
+}
+
+// Fact is one record of a namespace's fact feed, with its activation, tags
+// and attributes
+type Fact struct {
+	UID         string            `json:"uid"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Type        NodeType          `json:"type"`
+	ContentType string            `json:"content_type,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Activation  float64           `json:"activation"`
+	AccessCount int64             `json:"access_count"`
+	Confidence  float64           `json:"confidence,omitempty"`
+	Pinned      bool              `json:"pinned,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	ValidFrom   *time.Time        `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time        `json:"valid_until,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Namespace   string            `json:"namespace"`
+	Cursor      string            `json:"cursor"` // Resumes the feed just after this fact
+}
+
 // ========== CHAT TYPES ==========
 
 // ChatConsultRequest is a chat consult request
@@ -251,6 +345,29 @@ type RelationshipCreateResponse struct {
 	RelType  string `json:"rel_type"`
 }
 
+// RelationshipTypeInfo describes one relationship type and its reverse direction
+type RelationshipTypeInfo struct {
+	Type         RelationshipType `json:"type"`
+	Predicate    string           `json:"predicate"`
+	Reverse      string           `json:"reverse"`
+	Label        string           `json:"label"`
+	ReverseLabel string           `json:"reverse_label"`
+	Functional   bool             `json:"functional"`
+}
+
+// SchemaTypesResponse lists the node, entity and relationship types the server accepts
+type SchemaTypesResponse struct {
+	NodeTypes         []string `json:"node_types"`
+	EntityTypes       []string `json:"entity_types"`
+	RelationshipTypes []string `json:"relationship_types"`
+}
+
+// RelationshipTypesResponse is a relationship types response
+type RelationshipTypesResponse struct {
+	RelationshipTypes []RelationshipTypeInfo `json:"relationship_types"`
+	Count             int                    `json:"count"`
+}
+
 // ========== DOCUMENT TYPES ==========
 
 // DocumentIngestRequest is a document ingest request
@@ -266,13 +383,15 @@ type DocumentIngestResponse struct {
 	Status            string `json:"status"`
 	Filename          string `json:"filename"`
 	DocumentID        string `json:"document_id"`
+	ContentType       string `json:"content_type,omitempty"`
 	EntitiesExtracted int    `json:"entities_extracted"`
 }
 
 // DocumentListRequest is a document list request
 type DocumentListRequest struct {
-	Namespace string `json:"namespace"`
-	Limit     int    `json:"limit,omitempty"`
+	Namespace   string `json:"namespace"`
+	Limit       int    `json:"limit,omitempty"`
+	ContentType string `json:"content_type,omitempty"` // Only documents of this content type, e.g. "pdf"
 }
 
 // Document is a document
@@ -280,6 +399,7 @@ type Document struct {
 	UID         string `json:"uid"`
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
 	CreatedAt   string `json:"created_at,omitempty"`
 }
 
@@ -473,3 +593,20 @@ type Tool struct {
 type ToolsListResponse struct {
 	Tools []Tool `json:"tools"`
 }
+
+// APIError is returned for non-2xx responses. Code is the server's
+// machine-readable error code (e.g. "unauthorized", "forbidden", "rate_limited").
+type APIError struct {
+	StatusCode int         `json:"-"`
+	Message    string      `json:"error"`
+	Code       string      `json:"code"`
+	Details    interface{} `json:"details,omitempty"`
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("HTTP %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
@@ -709,6 +709,8 @@ class NodeType:
     EVENT = "Event"
     INSIGHT = "Insight"
     PATTERN = "Pattern"
+    PREFERENCE = "Preference"
+    RULE = "Rule"
 
     def __init__(self, value: str):
         self.value = value
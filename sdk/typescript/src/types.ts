@@ -27,7 +27,7 @@ export interface AuthResponse {
 /**
  * Node types for memory storage
  */
-export type NodeType = "Entity" | "Fact" | "Event" | "Insight" | "Pattern";
+export type NodeType = "Entity" | "Fact" | "Event" | "Insight" | "Pattern" | "Preference" | "Rule";
 
 /**
  * Relationship types between entities